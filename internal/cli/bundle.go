@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Manage offline bundles for air-gapped environments",
+}
+
+var bundleBuildCmd = &cobra.Command{
+	Use:     "build",
+	Short:   "Build a self-contained offline bundle",
+	Example: "gemara-mcp bundle build --output ./gemara-bundle --catalog https://example.com/osps.yaml",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config := tool.NewConfig()
+		if err := applyHTTPFlags(cmd, config); err != nil {
+			return err
+		}
+		if err := applyRegistryFlags(cmd, config); err != nil {
+			return err
+		}
+		output, _ := cmd.Flags().GetString("output")
+		lexiconURL, _ := cmd.Flags().GetString("lexicon-url")
+		templateIndex, _ := cmd.Flags().GetString("template-index")
+		catalogs, _ := cmd.Flags().GetStringArray("catalog")
+
+		manifest, err := tool.BuildBundle(tool.WithConfig(cmd.Context(), config), tool.BundleOptions{
+			Output:           output,
+			LexiconURL:       lexiconURL,
+			TemplateIndexURL: templateIndex,
+			CatalogURLs:      catalogs,
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "Bundle written to %s (schema %s, %d template(s), %d catalog(s))\n",
+			output, manifest.SchemaVersion, manifest.Templates, len(manifest.Catalogs))
+		fmt.Fprintf(cmd.OutOrStdout(), "Serve it with: gemara-mcp serve --bundle %s\n", output)
+		return nil
+	},
+}
+
+func init() {
+	bundleBuildCmd.Flags().StringP("output", "o", "gemara-bundle", "Directory to write the bundle to (must be empty)")
+	bundleBuildCmd.Flags().String("lexicon-url", tool.DefaultLexiconURL, "Lexicon to include (empty to skip)")
+	bundleBuildCmd.Flags().String("template-index", tool.DefaultTemplateIndexURL, "Template index to include (empty to skip)")
+	bundleBuildCmd.Flags().StringArray("catalog", nil, "URL of a community catalog to include (repeatable)")
+	addHTTPFlags(bundleBuildCmd)
+	addRegistryFlags(bundleBuildCmd)
+	bundleCmd.AddCommand(bundleBuildCmd)
+}
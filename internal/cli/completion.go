@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion <bash|zsh|fish>",
+	Short: "Generate a shell completion script",
+	Long: "Generate a completion script for gemara-mcp. Source it from the shell's startup file, or " +
+		"install it where the shell loads completions.",
+	Example: "source <(gemara-mcp completion bash)\n" +
+		"gemara-mcp completion zsh > \"${fpath[1]}/_gemara-mcp\"\n" +
+		"gemara-mcp completion fish > ~/.config/fish/completions/gemara-mcp.fish",
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: []string{"bash", "zsh", "fish"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root, out := cmd.Root(), cmd.OutOrStdout()
+		switch args[0] {
+		case "bash":
+			return root.GenBashCompletionV2(out, true)
+		case "zsh":
+			return root.GenZshCompletion(out)
+		default:
+			return root.GenFishCompletion(out, true)
+		}
+	},
+}
+
+const (
+	docsFormatMan      = "man"
+	docsFormatMarkdown = "markdown"
+)
+
+var gendocsCmd = &cobra.Command{
+	Use:     "gendocs",
+	Short:   "Generate man pages or Markdown reference for every command",
+	Example: "gemara-mcp gendocs --format man --dir docs/man",
+	Hidden:  true,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		dir, _ := cmd.Flags().GetString("dir")
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create docs directory: %w", err)
+		}
+
+		root := cmd.Root()
+		// Generated pages should not change with the day they are built
+		root.DisableAutoGenTag = true
+		switch format {
+		case docsFormatMan:
+			return doc.GenManTree(root, &doc.GenManHeader{
+				Title:   "GEMARA-MCP",
+				Section: "1",
+				Source:  "Gemara MCP " + GetVersion(),
+				Manual:  "Gemara MCP Manual",
+			}, dir)
+		case docsFormatMarkdown:
+			return doc.GenMarkdownTree(root, dir)
+		default:
+			return fmt.Errorf("unsupported format %q: must be %q or %q", format, docsFormatMan, docsFormatMarkdown)
+		}
+	},
+}
+
+func init() {
+	gendocsCmd.Flags().String("format", docsFormatMan, "Documentation format (man or markdown)")
+	gendocsCmd.Flags().String("dir", "docs/man", "Directory to write one page per command to")
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// addConfigFlag registers the flag naming a configuration file.
+func addConfigFlag(cmd *cobra.Command) {
+	cmd.Flags().String("config", "", "YAML file of flag values keyed by flag name (e.g., lexicon-url); flags on the command line take precedence")
+}
+
+// applyConfigFile sets flags that were not given on the command line from the
+// file named by --config. Lists set repeatable flags and mappings set
+// key=value flags.
+func applyConfigFile(cmd *cobra.Command) error {
+	path, _ := cmd.Flags().GetString("config")
+	if path == "" {
+		return nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(raw, &config); err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	for name, value := range flattenConfig(cmd.Flags(), config) {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil || name == "config" {
+			return fmt.Errorf("config %s: unknown setting %q", path, name)
+		}
+		if flag.Changed {
+			continue
+		}
+		if err := setFlag(flag, value); err != nil {
+			return fmt.Errorf("config %s: invalid %s: %w", path, name, err)
+		}
+	}
+	return nil
+}
+
+// flattenConfig names settings after their flags: a dotted key such as
+// tools.allow, or a mapping nested under a key that names no flag, such as
+// allow under tools, sets the flag tools-allow.
+func flattenConfig(flags *pflag.FlagSet, config map[string]interface{}) map[string]interface{} {
+	flat := make(map[string]interface{}, len(config))
+	for name, value := range config {
+		name = strings.ReplaceAll(name, ".", "-")
+		nested, ok := value.(map[string]interface{})
+		if !ok || flags.Lookup(name) != nil {
+			flat[name] = value
+			continue
+		}
+		for key, v := range flattenConfig(flags, nested) {
+			flat[name+"-"+key] = v
+		}
+	}
+	return flat
+}
+
+// setFlag assigns a decoded YAML value to a flag.
+func setFlag(flag *pflag.Flag, value interface{}) error {
+	switch v := value.(type) {
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			return slice.Replace(items)
+		}
+		for _, item := range items {
+			if err := flag.Value.Set(item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := flag.Value.Set(fmt.Sprintf("%s=%v", k, v[k])); err != nil {
+				return err
+			}
+		}
+	case nil:
+		return nil
+	default:
+		if err := flag.Value.Set(fmt.Sprint(v)); err != nil {
+			return err
+		}
+	}
+	flag.Changed = true
+	return nil
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+var conformanceCmd = &cobra.Command{
+	Use:     "conformance <directory>",
+	Short:   "Check a directory of Gemara artifacts for conformance",
+	Example: "gemara-mcp conformance ./artifacts --format json",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format != formatText && format != formatJSON {
+			return fmt.Errorf("unsupported format %q: must be %q or %q", format, formatText, formatJSON)
+		}
+
+		config := tool.NewConfig()
+		applySOPSFlags(cmd, config)
+		if err := applyHTTPFlags(cmd, config); err != nil {
+			return err
+		}
+		if err := applyRegistryFlags(cmd, config); err != nil {
+			return err
+		}
+
+		_, report, err := tool.RunConformanceSuite(tool.WithConfig(cmd.Context(), config), nil, tool.InputRunConformanceSuite{
+			Directory: args[0],
+		})
+		if err != nil {
+			return err
+		}
+
+		if format == formatJSON {
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				return err
+			}
+		} else {
+			writeConformanceText(cmd.OutOrStdout(), report)
+		}
+
+		if !report.Conformant {
+			return fmt.Errorf("%d of %d artifact(s) are not conformant", report.Failed, report.Total)
+		}
+		return nil
+	},
+}
+
+func init() {
+	conformanceCmd.Flags().String("format", formatText, "Output format (text or json)")
+	addSOPSFlags(conformanceCmd)
+	addHTTPFlags(conformanceCmd)
+	addRegistryFlags(conformanceCmd)
+}
+
+func writeConformanceText(w io.Writer, report tool.OutputRunConformanceSuite) {
+	fmt.Fprintf(w, "Conformance report for %s (schema %s)\n\n", report.Directory, report.ModuleVersion)
+	for _, r := range report.Results {
+		status := "PASS"
+		if !r.Conformant {
+			status = "FAIL"
+		}
+		fmt.Fprintf(w, "%s  %s %s\n", status, r.Path, r.Definition)
+		for _, e := range r.SchemaErrors {
+			fmt.Fprintf(w, "      schema: %s\n", e)
+		}
+		for _, f := range r.Findings {
+			fmt.Fprintf(w, "      %s [%s] %s: %s\n", f.Severity, f.RuleID, f.Path, f.Message)
+		}
+	}
+	fmt.Fprintf(w, "\n%d artifact(s): %d passed, %d failed\n", report.Total, report.Passed, report.Failed)
+}
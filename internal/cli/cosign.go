@@ -0,0 +1,20 @@
+package cli
+
+import (
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+// addCosignFlags registers the flags that configure artifact signing.
+func addCosignFlags(cmd *cobra.Command) {
+	cmd.Flags().String("cosign-binary", "cosign", "Path to the cosign executable used to sign and verify artifacts")
+	cmd.Flags().String("cosign-key", "", "Private key (file or KMS URI) used to sign artifacts; keyless Sigstore signing when empty")
+	cmd.Flags().String("cosign-public-key", "", "Public key used to verify signatures; keyless verification when empty")
+}
+
+// applyCosignFlags copies the cosign flags into config.
+func applyCosignFlags(cmd *cobra.Command, config *tool.Config) {
+	config.Cosign.Binary, _ = cmd.Flags().GetString("cosign-binary")
+	config.Cosign.Key, _ = cmd.Flags().GetString("cosign-key")
+	config.Cosign.PublicKey, _ = cmd.Flags().GetString("cosign-public-key")
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the registry, lexicon, proxy, and cache configuration",
+	Long: "Check that the CUE registry publishes the Gemara module, every lexicon source can be read, the " +
+		"proxy accepts connections, the module cache and --cache-storage are writable, and the schema " +
+		"compiles, using the same flags as serve. Each failure is printed with a suggested fix.",
+	Example: "gemara-mcp doctor\ngemara-mcp doctor --cue-registry registry.example.com/cue-mirror --format json",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format != formatText && format != formatJSON {
+			return fmt.Errorf("unsupported format %q: must be %q or %q", format, formatText, formatJSON)
+		}
+		if err := applyLexiconFlags(cmd); err != nil {
+			return err
+		}
+
+		// Flags that cannot be applied are reported with the other checks
+		config := tool.NewConfig()
+		var report tool.DoctorReport
+		for _, step := range []struct {
+			name        string
+			apply       func(*cobra.Command, *tool.Config) error
+			remediation string
+		}{
+			{"http", applyHTTPFlags, "Fix --ca-bundle, --client-cert, and --client-key, or the retry flags"},
+			{"registry", applyRegistryFlags, "Fix --cue-registry (same syntax as CUE_REGISTRY) or --registry-docker-config"},
+			{"cache storage", applyCacheStorageFlag, "Fix --cache-storage (a directory, file://, sqlite://, or s3://bucket/prefix)"},
+		} {
+			if err := step.apply(cmd, config); err != nil {
+				report.Checks = append(report.Checks, tool.DoctorCheck{
+					Name:        step.name,
+					Status:      tool.DoctorFail,
+					Detail:      err.Error(),
+					Remediation: step.remediation,
+				})
+			}
+		}
+
+		diagnosis := tool.Doctor(tool.WithConfig(cmd.Context(), config))
+		report.Checks = append(report.Checks, diagnosis.Checks...)
+		report.Passed = diagnosis.Passed && len(report.Checks) == len(diagnosis.Checks)
+
+		if format == formatJSON {
+			if err := writeJSON(cmd.OutOrStdout(), report); err != nil {
+				return err
+			}
+		} else {
+			writeDoctorText(cmd.OutOrStdout(), report)
+		}
+		if !report.Passed {
+			return fmt.Errorf("one or more checks failed")
+		}
+		return nil
+	},
+}
+
+func init() {
+	doctorCmd.Flags().String("format", formatText, "Output format (text or json)")
+	doctorCmd.Flags().String("lexicon-url", tool.DefaultLexiconURL, "URL of the base lexicon (https://, or file:// to a lexicon or gemara checkout; empty to check only overlays)")
+	doctorCmd.Flags().StringArray("lexicon-overlay", nil, "URL of a lexicon layered over the base (repeatable)")
+	doctorCmd.Flags().String("lexicon-conflict", tool.LexiconConflictOverride, "How to resolve a term defined by several lexicons ("+strings.Join(tool.LexiconConflictRules(), ", ")+")")
+	doctorCmd.Flags().String("cache-storage", "", "Cache storage to check (directory, file://, sqlite://, or s3://bucket/prefix)")
+	addHTTPFlags(doctorCmd)
+	addRegistryFlags(doctorCmd)
+}
+
+func writeDoctorText(w io.Writer, report tool.DoctorReport) {
+	for _, c := range report.Checks {
+		fmt.Fprintf(w, "[%-4s] %-13s %s\n", c.Status, c.Name, c.Detail)
+		if c.Remediation != "" && c.Status != tool.DoctorOK {
+			fmt.Fprintf(w, "       %-13s fix: %s\n", "", c.Remediation)
+		}
+	}
+	failed := 0
+	for _, c := range report.Checks {
+		if c.Status == tool.DoctorFail {
+			failed++
+		}
+	}
+	fmt.Fprintf(w, "\n%d check(s), %d failed\n", len(report.Checks), failed)
+}
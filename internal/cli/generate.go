@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate synthetic artifacts for load testing",
+}
+
+var generateCatalogCmd = &cobra.Command{
+	Use:     "catalog",
+	Short:   "Generate a schema-valid synthetic ControlCatalog of configurable size",
+	Example: "gemara-mcp generate catalog --controls 5000 --mappings 6 --output catalog-5000.yaml",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		var opts tool.SyntheticCatalogOptions
+		opts.Controls, _ = cmd.Flags().GetInt("controls")
+		opts.Mappings, _ = cmd.Flags().GetInt("mappings")
+		opts.Requirements, _ = cmd.Flags().GetInt("requirements")
+		opts.Families, _ = cmd.Flags().GetInt("families")
+		opts.Seed, _ = cmd.Flags().GetUint64("seed")
+
+		content, stats, err := tool.GenerateSyntheticCatalog(opts)
+		if err != nil {
+			return err
+		}
+
+		if output == "" || output == "-" {
+			_, err := fmt.Fprint(cmd.OutOrStdout(), content)
+			return err
+		}
+		if err := os.WriteFile(output, []byte(content), 0o600); err != nil {
+			return fmt.Errorf("failed to write catalog: %w", err)
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Catalog written to %s (%d control(s), %d mapping(s), %d requirement(s), %d bytes)\n",
+			output, stats.Controls, stats.Mappings, stats.Requirements, stats.Bytes)
+		return nil
+	},
+}
+
+func init() {
+	generateCatalogCmd.Flags().Int("controls", 100, "Number of controls")
+	generateCatalogCmd.Flags().Int("mappings", 3, "Guideline mapping entries per control")
+	generateCatalogCmd.Flags().Int("requirements", 2, "Assessment requirements per control")
+	generateCatalogCmd.Flags().Int("families", 0, "Number of control families (0 for one per 25 controls)")
+	generateCatalogCmd.Flags().Uint64("seed", 1, "Seed for reproducible output")
+	generateCatalogCmd.Flags().StringP("output", "o", "", "File to write the catalog to (default: stdout)")
+	generateCmd.AddCommand(generateCatalogCmd)
+}
@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+// addGitHubFlags registers the flags that configure GitHub API access.
+func addGitHubFlags(cmd *cobra.Command) {
+	cmd.Flags().String("github-api-url", tool.DefaultGitHubAPIURL, "GitHub REST API root used to fetch repository artifacts (e.g., https://github.example.com/api/v3)")
+}
+
+// applyGitHubFlags copies the GitHub flags into config. The token is read from
+// GITHUB_TOKEN or GH_TOKEN so it stays out of process listings.
+func applyGitHubFlags(cmd *cobra.Command, config *tool.Config) {
+	config.GitHub.APIURL, _ = cmd.Flags().GetString("github-api-url")
+	config.GitHub.Token = os.Getenv("GITHUB_TOKEN")
+	if config.GitHub.Token == "" {
+		config.GitHub.Token = os.Getenv("GH_TOKEN")
+	}
+}
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+// addHTTPFlags registers the flags that configure outbound HTTP.
+func addHTTPFlags(cmd *cobra.Command) {
+	cmd.Flags().String("ca-bundle", "", "PEM file of additional certificate authorities to trust for outbound HTTPS (e.g., a TLS-intercepting proxy)")
+	cmd.Flags().String("client-cert", "", "PEM client certificate for outbound mutual TLS")
+	cmd.Flags().String("client-key", "", "PEM private key for --client-cert")
+	cmd.Flags().Duration("http-timeout", tool.DefaultHTTPConfig().Timeout, "Timeout for each attempt of an outbound HTTP request")
+	cmd.Flags().Int("http-retries", tool.DefaultRetries, "Times to retry outbound GET requests that fail with a network error, timeout, 429, or 5xx")
+	cmd.Flags().Duration("http-retry-backoff", tool.DefaultRetryBackoff, "Delay before the first retry; doubled on each later retry, with jitter")
+	cmd.Flags().Duration("http-retry-max-backoff", tool.DefaultRetryMaxBackoff, "Longest delay between retries")
+}
+
+// applyHTTPFlags copies the HTTP flags into config and rebuilds the shared
+// transport.
+func applyHTTPFlags(cmd *cobra.Command, config *tool.Config) error {
+	config.HTTP.CABundle, _ = cmd.Flags().GetString("ca-bundle")
+	config.HTTP.ClientCert, _ = cmd.Flags().GetString("client-cert")
+	config.HTTP.ClientKey, _ = cmd.Flags().GetString("client-key")
+	config.HTTP.Timeout, _ = cmd.Flags().GetDuration("http-timeout")
+	config.HTTP.Retries, _ = cmd.Flags().GetInt("http-retries")
+	config.HTTP.RetryBackoff, _ = cmd.Flags().GetDuration("http-retry-backoff")
+	config.HTTP.RetryMaxBackoff, _ = cmd.Flags().GetDuration("http-retry-max-backoff")
+	return tool.ConfigureHTTP(config.HTTP)
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+var installCmd = &cobra.Command{
+	Use:   "install <" + strings.Join(tool.MCPClients(), "|") + ">",
+	Short: "Add gemara-mcp to an MCP client's configuration",
+	Long: "Write or patch the MCP configuration of a client so it starts this binary with 'serve'. Other " +
+		"servers and settings in the file are kept, and an existing entry with the same name is replaced. " +
+		"VS Code is configured per workspace (.vscode/mcp.json); the other clients are configured for the " +
+		"user unless --project is set.",
+	Example: "gemara-mcp install claude\n" +
+		"gemara-mcp install cursor --project --workspace-root . --env GITHUB_TOKEN=${GITHUB_TOKEN}\n" +
+		"gemara-mcp install vscode --print",
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: tool.MCPClients(),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client := args[0]
+		mode, _ := cmd.Flags().GetString("mode")
+		if _, err := tool.LookupMode(mode); err != nil {
+			return err
+		}
+
+		server, err := installServerCommand(cmd, mode)
+		if err != nil {
+			return err
+		}
+		name, _ := cmd.Flags().GetString("name")
+		if name == "" {
+			return fmt.Errorf("name must not be empty")
+		}
+
+		if printOnly, _ := cmd.Flags().GetBool("print"); printOnly {
+			patched, _, err := tool.PatchClientConfig(nil, client, name, server)
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(patched)
+			return err
+		}
+
+		path, _ := cmd.Flags().GetString("config-file")
+		if path == "" {
+			project, _ := cmd.Flags().GetBool("project")
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			if path, err = tool.ClientConfigPath(client, project, cwd); err != nil {
+				return err
+			}
+		}
+		replaced, err := tool.InstallClientConfig(path, client, name, server)
+		if err != nil {
+			return err
+		}
+		action := "Added"
+		if replaced {
+			action = "Updated"
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s %s in %s; restart %s to load it\n", action, name, path, client)
+		return nil
+	},
+}
+
+func init() {
+	installCmd.Flags().String("name", "gemara-mcp", "Name of the server entry in the client configuration")
+	installCmd.Flags().String("mode", "advisory", "Mode the server runs in")
+	installCmd.Flags().String("binary", "", "gemara-mcp binary the client starts (default: this binary)")
+	installCmd.Flags().String("workspace-root", "", "Directory passed as serve --workspace-root, made absolute (default: none, so the server reads no files)")
+	installCmd.Flags().StringArray("arg", nil, "Additional serve argument, such as --lexicon-overlay=file:///org/lexicon.yaml (repeatable)")
+	installCmd.Flags().StringToString("env", nil, "Environment variables set for the server (e.g., GITHUB_TOKEN=...)")
+	installCmd.Flags().Bool("project", false, "Configure the client for the current project instead of the user")
+	installCmd.Flags().String("config-file", "", "Client configuration file to patch instead of the client's default")
+	installCmd.Flags().Bool("print", false, "Print the server entry instead of writing the configuration")
+}
+
+// installServerCommand builds the command the client runs to start the server.
+func installServerCommand(cmd *cobra.Command, mode string) (tool.ServerCommand, error) {
+	binary, _ := cmd.Flags().GetString("binary")
+	if binary == "" {
+		executable, err := os.Executable()
+		if err != nil {
+			return tool.ServerCommand{}, fmt.Errorf("failed to locate this binary; pass --binary: %w", err)
+		}
+		binary = executable
+	}
+	// Clients start the server from an unspecified directory, so paths must be absolute
+	binary, err := filepath.Abs(binary)
+	if err != nil {
+		return tool.ServerCommand{}, fmt.Errorf("invalid binary path: %w", err)
+	}
+
+	server := tool.ServerCommand{Command: binary, Args: []string{"serve", "--mode", mode}}
+	if root, _ := cmd.Flags().GetString("workspace-root"); root != "" {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return tool.ServerCommand{}, fmt.Errorf("invalid workspace root: %w", err)
+		}
+		server.Args = append(server.Args, "--workspace-root", abs)
+	}
+	extra, _ := cmd.Flags().GetStringArray("arg")
+	server.Args = append(server.Args, extra...)
+	server.Env, _ = cmd.Flags().GetStringToString("env")
+	return server, nil
+}
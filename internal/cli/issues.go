@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+// addIssueTrackerFlags registers the flags that configure where findings are filed.
+func addIssueTrackerFlags(cmd *cobra.Command) {
+	cmd.Flags().String("issue-tracker", "", "Issue tracker for create_findings_issues: github or jira (the tool is disabled when empty)")
+	cmd.Flags().String("issue-repo", "", "GitHub repository (owner/name) to file finding issues in")
+	cmd.Flags().String("jira-url", "", "Jira site URL to file finding issues in (e.g., https://example.atlassian.net)")
+	cmd.Flags().String("jira-project", "", "Key of the Jira project to file finding issues in")
+	cmd.Flags().String("jira-issue-type", tool.DefaultJiraIssueType, "Type of the Jira issues filed for findings")
+}
+
+// applyIssueTrackerFlags copies the issue tracker flags into config. Jira
+// credentials are read from JIRA_USER and JIRA_API_TOKEN so they stay out of
+// process listings; GitHub uses the GitHub token.
+func applyIssueTrackerFlags(cmd *cobra.Command, config *tool.Config) error {
+	config.IssueTracker.Tracker, _ = cmd.Flags().GetString("issue-tracker")
+	config.IssueTracker.Repo, _ = cmd.Flags().GetString("issue-repo")
+	config.IssueTracker.JiraURL, _ = cmd.Flags().GetString("jira-url")
+	config.IssueTracker.JiraProject, _ = cmd.Flags().GetString("jira-project")
+	config.IssueTracker.JiraIssueType, _ = cmd.Flags().GetString("jira-issue-type")
+	config.IssueTracker.JiraUser = os.Getenv("JIRA_USER")
+	config.IssueTracker.JiraToken = os.Getenv("JIRA_API_TOKEN")
+	return config.IssueTracker.Validate()
+}
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+const (
+	formatTable = "table"
+	// maxTableDefinition is the longest definition shown in a table row.
+	maxTableDefinition = 100
+)
+
+var lexiconCmd = &cobra.Command{
+	Use:   "lexicon",
+	Short: "Look up Gemara terms in the lexicon",
+}
+
+var lexiconGetCmd = &cobra.Command{
+	Use:     "get [term]",
+	Short:   "List the lexicon, or show the definition of one term",
+	Example: "gemara-mcp lexicon get\ngemara-mcp lexicon get \"Control Catalog\" --format json",
+	Args:    cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lexicon, err := readLexicon(cmd)
+		if err != nil {
+			return err
+		}
+		format, _ := cmd.Flags().GetString("format")
+		if len(args) == 0 {
+			return writeLexiconEntries(cmd.OutOrStdout(), format, lexicon.Entries)
+		}
+
+		entry, ok := tool.LookupTerm(lexicon.Entries, args[0])
+		if !ok {
+			var similar []string
+			for _, e := range tool.SearchLexicon(lexicon.Entries, args[0]) {
+				if len(similar) == 3 {
+					break
+				}
+				similar = append(similar, e.Term)
+			}
+			if len(similar) > 0 {
+				return fmt.Errorf("term %q is not in the lexicon (similar: %s)", args[0], strings.Join(similar, ", "))
+			}
+			return fmt.Errorf("term %q is not in the lexicon", args[0])
+		}
+		if format == formatJSON {
+			return writeJSON(cmd.OutOrStdout(), entry)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "%s\n\n%s\n", entry.Term, strings.TrimSpace(entry.Definition))
+		if len(entry.References) > 0 {
+			fmt.Fprintf(cmd.OutOrStdout(), "\nReferences:\n")
+			for _, r := range entry.References {
+				fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", r)
+			}
+		}
+		return nil
+	},
+}
+
+var lexiconSearchCmd = &cobra.Command{
+	Use:     "search <term>",
+	Short:   "Find lexicon terms whose name or definition mentions a term",
+	Example: "gemara-mcp lexicon search evaluation",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lexicon, err := readLexicon(cmd)
+		if err != nil {
+			return err
+		}
+		format, _ := cmd.Flags().GetString("format")
+		matches := tool.SearchLexicon(lexicon.Entries, args[0])
+		if len(matches) == 0 && format != formatJSON {
+			return fmt.Errorf("no lexicon term mentions %q", args[0])
+		}
+		return writeLexiconEntries(cmd.OutOrStdout(), format, matches)
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{lexiconGetCmd, lexiconSearchCmd} {
+		c.Flags().String("format", formatTable, "Output format (table or json)")
+		c.Flags().Bool("refresh", false, "Fetch the lexicon again instead of using a cached copy")
+		c.Flags().String("lexicon-url", tool.DefaultLexiconURL, "URL of the base lexicon (https://, or file:// to a lexicon or gemara checkout; empty to read only overlays)")
+		c.Flags().StringArray("lexicon-overlay", nil, "URL of a lexicon layered over the base (repeatable)")
+		c.Flags().String("lexicon-conflict", tool.LexiconConflictOverride, "How to resolve a term defined by several lexicons ("+strings.Join(tool.LexiconConflictRules(), ", ")+")")
+		c.Flags().String("cache-storage", "", "Read and persist the fetched lexicon as the server does (directory, file://, sqlite://, or s3://bucket/prefix)")
+		addHTTPFlags(c)
+	}
+	lexiconCmd.AddCommand(lexiconGetCmd, lexiconSearchCmd)
+}
+
+// readLexicon reads the lexicon configured by the command's flags through the
+// server's fetch and cache path.
+func readLexicon(cmd *cobra.Command) (tool.OutputGetLexicon, error) {
+	format, _ := cmd.Flags().GetString("format")
+	if format != formatTable && format != formatJSON {
+		return tool.OutputGetLexicon{}, fmt.Errorf("unsupported format %q: must be %q or %q", format, formatTable, formatJSON)
+	}
+	if err := applyLexiconFlags(cmd); err != nil {
+		return tool.OutputGetLexicon{}, err
+	}
+	config := tool.NewConfig()
+	if err := applyHTTPFlags(cmd, config); err != nil {
+		return tool.OutputGetLexicon{}, err
+	}
+	if err := applyCacheStorageFlag(cmd, config); err != nil {
+		return tool.OutputGetLexicon{}, err
+	}
+
+	refresh, _ := cmd.Flags().GetBool("refresh")
+	lexicon, err := tool.DefaultLexicon.Read(tool.WithConfig(cmd.Context(), config), refresh)
+	if err != nil {
+		return tool.OutputGetLexicon{}, err
+	}
+	if lexicon.Stale {
+		fmt.Fprintf(os.Stderr, "lexicon source unavailable; showing a stale copy from %s\n", lexicon.Source)
+	}
+	return lexicon, nil
+}
+
+func writeLexiconEntries(w io.Writer, format string, entries []tool.LexiconEntry) error {
+	if format == formatJSON {
+		if entries == nil {
+			entries = []tool.LexiconEntry{}
+		}
+		return writeJSON(w, entries)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TERM\tDEFINITION")
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\n", e.Term, tableDefinition(e.Definition))
+	}
+	return tw.Flush()
+}
+
+// tableDefinition shortens a definition to the first line of a table row.
+func tableDefinition(definition string) string {
+	definition = strings.Join(strings.Fields(definition), " ")
+	if runes := []rune(definition); len(runes) > maxTableDefinition {
+		definition = strings.TrimSpace(string(runes[:maxTableDefinition-3])) + "..."
+	}
+	return definition
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
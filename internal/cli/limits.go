@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+// addLimitFlags registers the flags that guard artifact validation against adversarial input.
+func addLimitFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("max-artifact-depth", tool.DefaultMaxArtifactDepth, "Deepest nesting of mappings and sequences accepted in a validated artifact")
+	cmd.Flags().Int("max-alias-expansion", tool.DefaultMaxAliasExpansion, "Most nodes the YAML aliases of a validated artifact may expand to")
+	cmd.Flags().Duration("validation-timeout", tool.DefaultValidationTimeout, "Longest CUE evaluation of a validated artifact (0 disables)")
+}
+
+// applyLimitFlags copies the validation limits into config.
+func applyLimitFlags(cmd *cobra.Command, config *tool.Config) error {
+	depth, _ := cmd.Flags().GetInt("max-artifact-depth")
+	if depth <= 0 {
+		return fmt.Errorf("max-artifact-depth must be positive")
+	}
+	aliases, _ := cmd.Flags().GetInt("max-alias-expansion")
+	if aliases < 0 {
+		return fmt.Errorf("max-alias-expansion must not be negative")
+	}
+	timeout, _ := cmd.Flags().GetDuration("validation-timeout")
+	if timeout < 0 {
+		return fmt.Errorf("validation-timeout must not be negative")
+	}
+	config.MaxArtifactDepth = depth
+	config.MaxAliasExpansion = aliases
+	config.ValidationTimeout = timeout
+	return nil
+}
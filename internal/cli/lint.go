@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+const formatSARIF = "sarif"
+
+var lintCmd = &cobra.Command{
+	Use:   "lint <path>...",
+	Short: "Lint Gemara artifact files and directories",
+	Example: "gemara-mcp lint catalogs/ policy.yaml --severity-threshold warning\n" +
+		"gemara-mcp lint . --format sarif > gemara.sarif",
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format != formatText && format != formatJSON && format != formatSARIF {
+			return fmt.Errorf("unsupported format %q: must be %q, %q, or %q", format, formatText, formatJSON, formatSARIF)
+		}
+		rules, _ := cmd.Flags().GetStringSlice("rules")
+		threshold, _ := cmd.Flags().GetString("severity-threshold")
+
+		config := tool.NewConfig()
+		config.Version = GetVersion()
+		applySOPSFlags(cmd, config)
+		report, err := tool.LintFiles(tool.WithConfig(cmd.Context(), config), args, rules, threshold)
+		if err != nil {
+			return err
+		}
+
+		switch format {
+		case formatJSON:
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				return err
+			}
+		case formatSARIF:
+			sarif, err := report.SARIF(config.Version)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), string(sarif))
+		default:
+			writeLintText(cmd.OutOrStdout(), report)
+		}
+
+		if !report.Passed {
+			return fmt.Errorf("%d finding(s) at or above %s", report.Failing, report.Threshold)
+		}
+		return nil
+	},
+}
+
+func init() {
+	lintCmd.Flags().String("format", formatText, "Output format (text, json, or sarif)")
+	lintCmd.Flags().StringSlice("rules", nil, "Only run these rule IDs (default: all rules)")
+	lintCmd.Flags().String("severity-threshold", "error", "Least severe finding that fails the run ("+strings.Join(tool.LintSeverities(), ", ")+")")
+	addSOPSFlags(lintCmd)
+}
+
+func writeLintText(w io.Writer, report tool.LintReport) {
+	for _, f := range report.Findings {
+		location := f.File
+		if f.Line > 0 {
+			location = fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		fmt.Fprintf(w, "%s: %s [%s] %s: %s\n", location, f.Severity, f.RuleID, f.Path, f.Message)
+		if f.Suggestion != "" {
+			fmt.Fprintf(w, "      %s\n", f.Suggestion)
+		}
+	}
+	fmt.Fprintf(w, "\n%d file(s): %d error(s), %d warning(s), %d info finding(s)\n",
+		report.Files, report.Summary["error"], report.Summary["warning"], report.Summary["info"])
+}
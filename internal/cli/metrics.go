@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+// addMetricsFlags registers the flags that configure the metrics endpoint.
+func addMetricsFlags(cmd *cobra.Command) {
+	cmd.Flags().String("metrics-address", "", "Address (e.g., :9464) to serve the compliance status of the workspace evaluation logs, or of every tenant's with --tenants, on at "+tool.MetricsPath+" for Prometheus or OpenTelemetry scrapers (empty disables)")
+}
+
+// serveMetrics starts the metrics endpoint when one is configured and stops it
+// when ctx is cancelled. With tenants, the workspace of every tenant is
+// reported. The address is bound before returning so that a
+// port in use fails the command.
+func serveMetrics(ctx context.Context, cmd *cobra.Command, config *tool.Config, tenants []*tool.Tenant) error {
+	address, _ := cmd.Flags().GetString("metrics-address")
+	if address == "" {
+		return nil
+	}
+	if config.WorkspaceRoot == "" && tenants == nil {
+		return fmt.Errorf("metrics-address requires a workspace root")
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to serve metrics: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(tool.MetricsPath, tool.MetricsHandler(config, tenants))
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "metrics endpoint stopped: %v\n", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	return nil
+}
@@ -0,0 +1,16 @@
+package cli
+
+import (
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+// addORASFlags registers the flags that configure OCI registry distribution.
+func addORASFlags(cmd *cobra.Command) {
+	cmd.Flags().String("oras-registry-config", "", "Registry credentials file, in the Docker configuration format, used to push and pull artifacts (defaults to the Docker credentials)")
+}
+
+// applyORASFlags copies the OCI registry flags into config.
+func applyORASFlags(cmd *cobra.Command, config *tool.Config) {
+	config.ORAS.RegistryConfig, _ = cmd.Flags().GetString("oras-registry-config")
+}
@@ -0,0 +1,20 @@
+package cli
+
+import (
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+// addPrivacyFlags registers the flags that aggregate the posture and metrics
+// exports for sharing.
+func addPrivacyFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("export-min-cohort", 0, "Fewest evaluation logs a catalog must be evaluated by to appear in gemara://posture and the metrics; enables aggregate-only exports (0 exports every catalog)")
+	cmd.Flags().Float64("export-epsilon", 0, "Privacy budget of the Laplace noise added to the counts of gemara://posture and the metrics; smaller adds more noise and enables aggregate-only exports (0 adds none)")
+}
+
+// applyPrivacyFlags copies the export privacy flags into config.
+func applyPrivacyFlags(cmd *cobra.Command, config *tool.Config) error {
+	config.Privacy.MinCohort, _ = cmd.Flags().GetInt("export-min-cohort")
+	config.Privacy.Epsilon, _ = cmd.Flags().GetFloat64("export-epsilon")
+	return config.Privacy.Validate()
+}
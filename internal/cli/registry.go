@@ -0,0 +1,19 @@
+package cli
+
+import (
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+// addRegistryFlags registers the flags that configure the CUE registry.
+func addRegistryFlags(cmd *cobra.Command) {
+	cmd.Flags().String("cue-registry", "", "CUE registry to resolve the Gemara module from, such as an internal OCI mirror (same syntax as CUE_REGISTRY, which is used when empty)")
+	cmd.Flags().String("registry-docker-config", "", "Directory holding a Docker config.json with credentials for the CUE registry (defaults to DOCKER_CONFIG or ~/.docker)")
+}
+
+// applyRegistryFlags copies the registry flags into config.
+func applyRegistryFlags(cmd *cobra.Command, config *tool.Config) error {
+	config.Registry.Registry, _ = cmd.Flags().GetString("cue-registry")
+	config.Registry.DockerConfig, _ = cmd.Flags().GetString("registry-docker-config")
+	return config.Registry.Validate()
+}
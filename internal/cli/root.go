@@ -1,7 +1,12 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/gemaraproj/gemara-mcp/internal/tool"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -11,41 +16,319 @@ import (
 // New creates the root command
 func New() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:          "gemara-mcp[command]",
+		Use:          "gemara-mcp [command]",
 		SilenceUsage: true,
+		// completionCmd replaces cobra's default, limited to the shells we support
+		CompletionOptions: cobra.CompletionOptions{DisableDefaultCmd: true},
 	}
 	cmd.AddCommand(
 		serveCmd,
+		bundleCmd,
+		completionCmd,
+		conformanceCmd,
+		doctorCmd,
+		gendocsCmd,
+		generateCmd,
+		indexCmd,
+		installCmd,
+		lexiconCmd,
+		lintCmd,
+		schemaCmd,
+		selfUpdateCmd,
+		toolsCmd,
 		versionCmd,
+		warmupCmd,
 	)
 	return cmd
 }
 
-var versionCmd = &cobra.Command{
-	Use:   "version",
-	Short: "Print version information",
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("Gemara MCP Server %s\n", GetVersion())
-	},
-}
-
 var serveCmd = &cobra.Command{
 	Use:     "serve",
 	Short:   "Start the Gemara MCP server",
 	Example: "gemara-mcp serve",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		advisory := tool.AdvisoryMode{}
+		if err := applyConfigFile(cmd); err != nil {
+			return err
+		}
+		if err := applyLexiconFlags(cmd); err != nil {
+			return err
+		}
+		modeName, _ := cmd.Flags().GetString("mode")
+		if _, err := tool.LookupMode(modeName); err != nil {
+			return err
+		}
+		config := tool.NewConfig()
+		config.TemplateIndexURL, _ = cmd.Flags().GetString("template-index")
+		config.Version = GetVersion()
+		audience, _ := cmd.Flags().GetString("audience")
+		if err := tool.ValidateAudience(audience); err != nil {
+			return err
+		}
+		config.Audience = audience
+		logLevel, _ := cmd.Flags().GetString("log-level")
+		if err := tool.ValidateEventLevel(logLevel); err != nil {
+			return err
+		}
+		config.EventLevel = mcp.LoggingLevel(logLevel)
+		toolTimeout, _ := cmd.Flags().GetDuration("tool-timeout")
+		if toolTimeout < 0 {
+			return fmt.Errorf("tool-timeout must not be negative")
+		}
+		config.ToolTimeout = toolTimeout
+		config.DryRun, _ = cmd.Flags().GetBool("dry-run")
+		config.RequireApproval, _ = cmd.Flags().GetBool("require-approval")
+		applySOPSFlags(cmd, config)
+		applyGitHubFlags(cmd, config)
+		applyCosignFlags(cmd, config)
+		applyORASFlags(cmd, config)
+		if err := applyHTTPFlags(cmd, config); err != nil {
+			return err
+		}
+		if err := applyRegistryFlags(cmd, config); err != nil {
+			return err
+		}
+		if err := applyIssueTrackerFlags(cmd, config); err != nil {
+			return err
+		}
+		if err := applyWebhookFlags(cmd, config); err != nil {
+			return err
+		}
+		if err := applyPrivacyFlags(cmd, config); err != nil {
+			return err
+		}
+		if err := applyWorkspaceFlags(cmd, config); err != nil {
+			return err
+		}
+		tenants, err := applyTenantFlags(cmd)
+		if err != nil {
+			return err
+		}
+		// The search index defaults to a file under the workspace root
+		if err := applySearchFlags(cmd, config); err != nil {
+			return err
+		}
+		if err := applyLimitFlags(cmd, config); err != nil {
+			return err
+		}
+		// Storage is opened after the HTTP configuration so object stores use it
+		if err := applyCacheStorageFlag(cmd, config); err != nil {
+			return err
+		}
+		slaSpec, _ := cmd.Flags().GetStringToString("finding-sla")
+		sla, err := tool.ParseFindingSLA(slaSpec)
+		if err != nil {
+			return err
+		}
+		config.FindingSLA = sla
+		if path, _ := cmd.Flags().GetString("federation"); path != "" {
+			catalogs, err := tool.LoadFederation(path)
+			if err != nil {
+				return err
+			}
+			config.Federation = catalogs
+		}
+		if bundle, _ := cmd.Flags().GetString("bundle"); bundle != "" {
+			if _, err := tool.UseBundle(config, bundle); err != nil {
+				return err
+			}
+		}
+		// Work started outside of requests uses the same configuration
+		ctx := tool.WithConfig(cmd.Context(), config)
+
+		// Preloading is best effort; whatever fails is loaded again by the first call
+		if preload, _ := cmd.Flags().GetBool("preload"); preload {
+			report, err := tool.Warmup(ctx)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "preload incomplete: %v\n", err)
+			} else {
+				printWarmupReport(os.Stderr, report)
+			}
+		}
 
-		server := mcp.NewServer(&mcp.Implementation{
-			Name:    "gemara-mcp",
-			Title:   "Gemara MCP",
-			Version: GetVersion(),
-		}, &mcp.ServerOptions{
-			Instructions: advisory.Description(),
-		})
+		// Tools are filtered after the settings that decide which tools are
+		// offered, and checked against every mode that is served
+		advisory := tool.AdvisoryMode{Config: config}
+		served := []tool.Mode{advisory}
+		if tenants != nil {
+			served = served[:0]
+			for _, t := range tenants {
+				tenantMode := advisory
+				tenantMode.Tenant = t
+				served = append(served, tenantMode)
+			}
+		}
+		advisory.Filter, err = applyToolFilterFlags(cmd, served...)
+		if err != nil {
+			return err
+		}
+		options := &mcp.ServerOptions{
+			Instructions:      advisory.Instructions(),
+			CompletionHandler: tool.HandleCompletion,
+		}
+		watchInterval, _ := cmd.Flags().GetDuration("watch-interval")
+		watch := (config.WorkspaceRoot != "" || tenants != nil) && watchInterval > 0
+		refreshInterval, _ := cmd.Flags().GetDuration("refresh-interval")
+		if refreshInterval < 0 {
+			return fmt.Errorf("refresh-interval must not be negative")
+		}
+		if watch || refreshInterval > 0 {
+			options.SubscribeHandler = tool.SubscribeResource
+			options.UnsubscribeHandler = tool.UnsubscribeResource
+		}
 
-		advisory.Register(server)
+		drainer := &tool.RequestDrainer{}
+		newServer := func(advisory tool.AdvisoryMode) *mcp.Server {
+			serverOptions := *options
+			serverOptions.Instructions = advisory.Instructions()
+			server := mcp.NewServer(&mcp.Implementation{
+				Name:    "gemara-mcp",
+				Title:   "Gemara MCP",
+				Version: GetVersion(),
+			}, &serverOptions)
+			advisory.Register(server)
+			server.AddReceivingMiddleware(drainer.Middleware)
+			if refreshInterval > 0 {
+				go tool.NewRefresher(server, advisory.Lexicon).Run(ctx, refreshInterval)
+			}
+			// A tenant's server watches the workspace of the tenant
+			if watch {
+				go tool.NewWorkspaceWatcher(server, advisory.Tenant).Run(ctx, watchInterval)
+			}
+			return server
+		}
+		shutdownTimeout, _ := cmd.Flags().GetDuration("shutdown-timeout")
+		if err := serveMetrics(ctx, cmd, config, tenants); err != nil {
+			return err
+		}
 
-		return server.Run(cmd.Context(), &mcp.StdioTransport{})
+		if tenants != nil {
+			header, _ := cmd.Flags().GetString("tenant-header")
+			handler := tool.TenantHandler(tenants, header, func(t *tool.Tenant) *mcp.Server {
+				tenantMode := advisory
+				tenantMode.Tenant = t
+				return newServer(tenantMode)
+			})
+			return serveTenants(ctx, cmd, handler, drainer, shutdownTimeout)
+		}
+
+		server := newServer(advisory)
+		tool.BroadcastEvents(server)
+		return serve(ctx, server, &mcp.StdioTransport{}, drainer, shutdownTimeout)
 	},
 }
+
+// serve runs the server until the session ends or ctx is cancelled. On
+// cancellation, in-flight requests get up to timeout to finish before the
+// transport is closed.
+func serve(ctx context.Context, server *mcp.Server, transport mcp.Transport, drainer *tool.RequestDrainer, timeout time.Duration) error {
+	// The session outlives ctx so that requests can drain after a signal
+	session, err := server.Connect(context.WithoutCancel(ctx), transport, nil)
+	if err != nil {
+		return err
+	}
+
+	ended := make(chan error, 1)
+	go func() {
+		ended <- session.Wait()
+	}()
+
+	select {
+	case err := <-ended:
+		return err
+	case <-ctx.Done():
+	}
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := drainer.Drain(drainCtx); err != nil {
+		fmt.Fprintf(os.Stderr, "shutdown timeout of %s exceeded; abandoning in-flight requests\n", timeout)
+	}
+	if err := tool.WaitWebhooks(drainCtx); err != nil {
+		fmt.Fprintf(os.Stderr, "shutdown timeout of %s exceeded; abandoning webhook deliveries\n", timeout)
+	}
+
+	if err := session.Close(); err != nil {
+		return err
+	}
+	<-ended
+	return nil
+}
+
+// applyLexiconFlags configures the lexicon sources and how they are layered.
+func applyLexiconFlags(cmd *cobra.Command) error {
+	conflict, _ := cmd.Flags().GetString("lexicon-conflict")
+	if err := tool.ValidateLexiconConflict(conflict); err != nil {
+		return err
+	}
+	base, _ := cmd.Flags().GetString("lexicon-url")
+	overlays, _ := cmd.Flags().GetStringArray("lexicon-overlay")
+
+	var sources []string
+	if base != "" {
+		sources = append(sources, base)
+	}
+	sources = append(sources, overlays...)
+	if len(sources) == 0 {
+		return fmt.Errorf("at least one of --lexicon-url or --lexicon-overlay is required")
+	}
+	tool.DefaultLexicon.Configure(sources, conflict)
+	return nil
+}
+
+// applyWorkspaceFlags configures where and how much artifact content may be read by URI.
+func applyWorkspaceFlags(cmd *cobra.Command, config *tool.Config) error {
+	root, _ := cmd.Flags().GetString("workspace-root")
+	if root != "" {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return fmt.Errorf("invalid workspace root: %w", err)
+		}
+		root = abs
+	}
+	config.WorkspaceRoot = root
+
+	maxSize, _ := cmd.Flags().GetInt64("max-artifact-size")
+	if maxSize <= 0 {
+		return fmt.Errorf("max-artifact-size must be positive")
+	}
+	config.MaxArtifactSize = maxSize
+	return nil
+}
+
+func init() {
+	addConfigFlag(serveCmd)
+	serveCmd.Flags().String("mode", "advisory", "Mode whose tools and resources are served (see 'gemara-mcp tools list')")
+	serveCmd.Flags().String("lexicon-url", tool.DefaultLexiconURL, "URL of the base lexicon (https://, or file:// to a lexicon or gemara checkout; empty to serve only overlays)")
+	serveCmd.Flags().StringArray("lexicon-overlay", nil, "URL of a lexicon layered over the base, such as org-specific terms (repeatable; later overlays take precedence)")
+	serveCmd.Flags().String("lexicon-conflict", tool.LexiconConflictOverride, "How to resolve a term defined by several lexicons ("+strings.Join(tool.LexiconConflictRules(), ", ")+")")
+	serveCmd.Flags().String("template-index", tool.DefaultTemplateIndexURL, "URL of the artifact template index (https:// or file://)")
+	serveCmd.Flags().String("audience", tool.AudienceAgent, "Tool result rendering: agent (terse JSON) or human (annotated text)")
+	serveCmd.Flags().String("log-level", "info", "Least severe server event sent to clients as MCP logging notifications ("+strings.Join(tool.EventLevels(), ", ")+")")
+	addSOPSFlags(serveCmd)
+	addHTTPFlags(serveCmd)
+	addRegistryFlags(serveCmd)
+	addGitHubFlags(serveCmd)
+	addIssueTrackerFlags(serveCmd)
+	addWebhookFlags(serveCmd)
+	addMetricsFlags(serveCmd)
+	addPrivacyFlags(serveCmd)
+	addTenantFlags(serveCmd)
+	addSearchFlags(serveCmd)
+	addCosignFlags(serveCmd)
+	addORASFlags(serveCmd)
+	addToolFilterFlags(serveCmd)
+	serveCmd.Flags().String("workspace-root", "", "Directory that file:// artifact URIs and workspace tools and resources must resolve within (unset disables file access)")
+	serveCmd.Flags().Duration("watch-interval", tool.DefaultWatchInterval, "How often to re-scan the workspace root, or every tenant's with --tenants, for changed artifacts that no file system event reported; changes are otherwise found as they happen and notified to subscribed clients (0 disables watching)")
+	serveCmd.Flags().Duration("refresh-interval", tool.DefaultRefreshInterval, "How often to re-fetch the lexicon, schema module, and subscribed federated catalogs that are about to expire (0 disables)")
+	serveCmd.Flags().StringToString("finding-sla", nil, "Remediation window per finding severity (e.g., critical=7d,high=30d)")
+	serveCmd.Flags().Bool("dry-run", false, "Make tools that write files or external systems report the changes they would make instead of making them")
+	serveCmd.Flags().Bool("require-approval", false, "Ask the user to approve, through MCP elicitation, the changes of tools that write files or external systems before they are made")
+	serveCmd.Flags().Duration("tool-timeout", tool.DefaultToolTimeout, "Longest a tool call may run before it fails, not counting time waiting for --require-approval (0 disables)")
+	serveCmd.Flags().Duration("shutdown-timeout", 10*time.Second, "How long to wait for in-flight requests to finish on shutdown")
+	serveCmd.Flags().String("federation", "", "YAML file declaring federated catalogs composed from several sources")
+	serveCmd.Flags().String("cache-storage", "", "Persist fetched lexicons, templates, catalogs, the reported posture, and the control search index across restarts (directory, file://, sqlite://, or s3://bucket/prefix)")
+	serveCmd.Flags().Bool("preload", false, "Resolve the schema and fetch the lexicon before accepting requests, as 'gemara-mcp warmup' does")
+	serveCmd.Flags().String("bundle", "", "Serve entirely from an offline bundle built with 'gemara-mcp bundle build'")
+	serveCmd.Flags().Int64("max-artifact-size", tool.DefaultMaxArtifactSize, "Largest artifact, in bytes, passed inline or read by URI")
+	addLimitFlags(serveCmd)
+}
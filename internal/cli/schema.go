@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+const formatJSONSchema = "jsonschema"
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Inspect and export the Gemara schema definitions",
+}
+
+var schemaListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List the definitions of the Gemara schema",
+	Example: "gemara-mcp schema list --format json",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format != formatText && format != formatJSON {
+			return fmt.Errorf("unsupported format %q: must be %q or %q", format, formatText, formatJSON)
+		}
+		ctx, err := applySchemaFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		summary, err := tool.ListSchemaDefinitions(ctx)
+		if err != nil {
+			return err
+		}
+		warnSchemaFallback(summary)
+		if format == formatJSON {
+			return writeJSON(cmd.OutOrStdout(), summary)
+		}
+		for _, d := range summary.Definitions {
+			fmt.Fprintln(cmd.OutOrStdout(), d)
+		}
+		return nil
+	},
+}
+
+var schemaShowCmd = &cobra.Command{
+	Use:     "show <definition>",
+	Short:   "Print the CUE source or JSON Schema of a definition",
+	Example: "gemara-mcp schema show ControlCatalog\ngemara-mcp schema show '#Policy' --format jsonschema",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		ctx, err := applySchemaFlags(cmd)
+		if err != nil {
+			return err
+		}
+		switch format {
+		case "cue":
+			source, err := tool.DefinitionSource(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(cmd.OutOrStdout(), source)
+			return nil
+		case formatJSONSchema:
+			_, output, err := tool.GetDefinitionSchema(ctx, nil, tool.InputGetDefinitionSchema{Definition: args[0]})
+			if err != nil {
+				return err
+			}
+			return writeJSON(cmd.OutOrStdout(), output.JSONSchema)
+		default:
+			return fmt.Errorf("unsupported format %q: must be %q or %q", format, "cue", formatJSONSchema)
+		}
+	},
+}
+
+var schemaExportCmd = &cobra.Command{
+	Use:   "export [definition]...",
+	Short: "Export definitions as JSON Schema for IDEs and validators",
+	Long: "Export Gemara definitions as JSON Schema (draft 2020-12). A single definition is written to " +
+		"stdout unless --output-dir is set; with several definitions, or none to export every " +
+		"definition, each is written to <output-dir>/<Definition>.json.",
+	Example: "gemara-mcp schema export ControlCatalog --format jsonschema > control-catalog.json\n" +
+		"gemara-mcp schema export --format jsonschema --output-dir schemas/",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format != formatJSONSchema {
+			return fmt.Errorf("unsupported format %q: must be %q", format, formatJSONSchema)
+		}
+		outputDir, _ := cmd.Flags().GetString("output-dir")
+		if outputDir == "" && len(args) != 1 {
+			return fmt.Errorf("--output-dir is required to export more than one definition")
+		}
+		ctx, err := applySchemaFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		definitions := args
+		if len(definitions) == 0 {
+			summary, err := tool.ListSchemaDefinitions(ctx)
+			if err != nil {
+				return err
+			}
+			warnSchemaFallback(summary)
+			definitions = summary.Definitions
+		}
+
+		if outputDir != "" {
+			if err := os.MkdirAll(outputDir, 0o755); err != nil {
+				return fmt.Errorf("failed to create output directory: %w", err)
+			}
+		}
+		for _, d := range definitions {
+			_, output, err := tool.GetDefinitionSchema(ctx, nil, tool.InputGetDefinitionSchema{Definition: d})
+			if err != nil {
+				return err
+			}
+			if outputDir == "" {
+				return writeJSON(cmd.OutOrStdout(), output.JSONSchema)
+			}
+
+			path := filepath.Join(outputDir, strings.TrimPrefix(output.Definition, "#")+".json")
+			f, err := os.Create(path)
+			if err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+			err = writeJSON(f, output.JSONSchema)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "wrote %s\n", path)
+		}
+		return nil
+	},
+}
+
+func init() {
+	schemaListCmd.Flags().String("format", formatText, "Output format (text or json)")
+	schemaShowCmd.Flags().String("format", "cue", "Output format (cue or jsonschema)")
+	schemaExportCmd.Flags().String("format", formatJSONSchema, "Export format (jsonschema)")
+	schemaExportCmd.Flags().String("output-dir", "", "Directory to write one <Definition>.json file per definition to")
+	for _, c := range []*cobra.Command{schemaListCmd, schemaShowCmd, schemaExportCmd} {
+		addHTTPFlags(c)
+		addRegistryFlags(c)
+	}
+	schemaCmd.AddCommand(schemaListCmd, schemaShowCmd, schemaExportCmd)
+}
+
+// applySchemaFlags configures how the Gemara module is resolved from the
+// registry, and returns a context that resolves it so.
+func applySchemaFlags(cmd *cobra.Command) (context.Context, error) {
+	config := tool.NewConfig()
+	if err := applyHTTPFlags(cmd, config); err != nil {
+		return nil, err
+	}
+	if err := applyRegistryFlags(cmd, config); err != nil {
+		return nil, err
+	}
+	return tool.WithConfig(cmd.Context(), config), nil
+}
+
+// warnSchemaFallback notes on stderr when the embedded schema was loaded
+// because the registry could not be reached.
+func warnSchemaFallback(summary tool.SchemaSummary) {
+	if summary.Fallback {
+		fmt.Fprintf(os.Stderr, "CUE registry unavailable; using the embedded Gemara schema %s\n", summary.ModuleVersion)
+	}
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+var indexCmd = &cobra.Command{
+	Use:     "index <directory>",
+	Short:   "Build the semantic search index of the controls in a directory of catalogs",
+	Example: "gemara-mcp index ./artifacts --embedding-provider hashing",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := filepath.Abs(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid directory: %w", err)
+		}
+		config := tool.NewConfig()
+		applySOPSFlags(cmd, config)
+		if err := applyHTTPFlags(cmd, config); err != nil {
+			return err
+		}
+		if err := applySearchFlags(cmd, config); err != nil {
+			return err
+		}
+		if config.SemanticSearch.Provider == "" {
+			return fmt.Errorf("--embedding-provider is required")
+		}
+		if config.SemanticSearch.IndexPath == "" {
+			config.SemanticSearch.IndexPath = filepath.Join(dir, ".gemara", tool.DefaultSearchIndexName)
+		}
+
+		previous, err := tool.LoadControlIndex(config.SemanticSearch.IndexPath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		index, err := tool.BuildControlIndex(tool.WithConfig(cmd.Context(), config), dir, previous)
+		if err != nil {
+			return err
+		}
+		if err := tool.SaveControlIndex(config.SemanticSearch.IndexPath, index); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Indexed %d control(s) from %d catalog file(s) into %s\n",
+			len(index.Entries), len(index.Sources), config.SemanticSearch.IndexPath)
+		return nil
+	},
+}
+
+func init() {
+	addSOPSFlags(indexCmd)
+	addHTTPFlags(indexCmd)
+	addSearchFlags(indexCmd)
+}
+
+// addSearchFlags registers the flags that configure semantic control search.
+func addSearchFlags(cmd *cobra.Command) {
+	cmd.Flags().String("embedding-provider", "", "Embedding provider for search_controls: hashing (bag-of-words, in process) or openai (the tool is disabled when empty)")
+	cmd.Flags().String("embedding-model", tool.DefaultEmbeddingModel, "Embedding model requested from the openai provider")
+	cmd.Flags().String("embedding-api-url", tool.DefaultEmbeddingAPIURL, "API root of the openai provider; any OpenAI-compatible server, such as a local Ollama, works")
+	cmd.Flags().String("search-index", "", "Control search index file: index writes it (default: .gemara/"+tool.DefaultSearchIndexName+" under the indexed directory), and serve starts from it without writing it")
+}
+
+// applySearchFlags copies the semantic search flags into config. The API key
+// is read from EMBEDDING_API_KEY, or OPENAI_API_KEY, so it stays out of
+// process listings.
+func applySearchFlags(cmd *cobra.Command, config *tool.Config) error {
+	config.SemanticSearch.Provider, _ = cmd.Flags().GetString("embedding-provider")
+	config.SemanticSearch.Model, _ = cmd.Flags().GetString("embedding-model")
+	config.SemanticSearch.APIURL, _ = cmd.Flags().GetString("embedding-api-url")
+	config.SemanticSearch.APIKey = os.Getenv("EMBEDDING_API_KEY")
+	if config.SemanticSearch.APIKey == "" {
+		config.SemanticSearch.APIKey = os.Getenv("OPENAI_API_KEY")
+	}
+	config.SemanticSearch.IndexPath, _ = cmd.Flags().GetString("search-index")
+	return config.SemanticSearch.Validate()
+}
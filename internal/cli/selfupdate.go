@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Replace this binary with the latest gemara-mcp release",
+	Long: "Download a gemara-mcp release for this platform from GitHub, verify the cosign signature of its " +
+		"checksums.txt (keyless, from the repository's release workflow, unless --cosign-public-key is set) " +
+		"and the binary's sha256 digest against it, and replace the running binary. Requires the cosign " +
+		"executable unless --skip-signature is set.",
+	Example: "gemara-mcp self-update --check\ngemara-mcp self-update --version v0.3.0",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config := tool.NewConfig()
+		applyGitHubFlags(cmd, config)
+		applyCosignFlags(cmd, config)
+		if err := applyHTTPFlags(cmd, config); err != nil {
+			return err
+		}
+
+		opts := tool.SelfUpdateOptions{Current: Version}
+		opts.Repo, _ = cmd.Flags().GetString("repo")
+		opts.Version, _ = cmd.Flags().GetString("version")
+		opts.Check, _ = cmd.Flags().GetBool("check")
+		opts.Force, _ = cmd.Flags().GetBool("force")
+		opts.SkipSignature, _ = cmd.Flags().GetBool("skip-signature")
+		// A pinned version is installed even when it is not newer
+		opts.Force = opts.Force || opts.Version != ""
+
+		result, err := tool.SelfUpdate(tool.WithConfig(cmd.Context(), config), opts)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		switch {
+		case result.Updated:
+			verified := "checksum verified"
+			if result.Verified {
+				verified = "signature and checksum verified"
+			}
+			fmt.Fprintf(out, "Updated %s from %s to %s (%s)\n", result.Path, result.Current, result.Release, verified)
+		case result.Newer:
+			fmt.Fprintf(out, "gemara-mcp %s is available (running %s); run 'gemara-mcp self-update' to install it\n", result.Release, result.Current)
+		default:
+			fmt.Fprintf(out, "gemara-mcp %s is up to date with release %s\n", result.Current, result.Release)
+		}
+		return nil
+	},
+}
+
+func init() {
+	selfUpdateCmd.Flags().String("repo", tool.DefaultReleaseRepo, "GitHub repository (owner/name) to install releases from")
+	selfUpdateCmd.Flags().String("version", "", "Release tag to install instead of the latest release, including older releases")
+	selfUpdateCmd.Flags().Bool("check", false, "Report whether a newer release is available without installing it")
+	selfUpdateCmd.Flags().Bool("force", false, "Reinstall the latest release even when it is not newer")
+	selfUpdateCmd.Flags().Bool("skip-signature", false, "Trust the release checksums without verifying their cosign signature")
+	addGitHubFlags(selfUpdateCmd)
+	// Only verification applies, so the signing key flag is not offered
+	selfUpdateCmd.Flags().String("cosign-binary", "cosign", "Path to the cosign executable used to verify the release signature")
+	selfUpdateCmd.Flags().String("cosign-public-key", "", "Public key the release checksums are signed with; keyless verification against the release workflow when empty")
+	addHTTPFlags(selfUpdateCmd)
+}
@@ -0,0 +1,22 @@
+package cli
+
+import (
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+// addSOPSFlags registers the flags that configure SOPS-encrypted artifact files.
+func addSOPSFlags(cmd *cobra.Command) {
+	cmd.Flags().String("sops-binary", "sops", "Path to the sops executable used for encrypted artifacts")
+	cmd.Flags().String("sops-age-key-file", "", "age identity file used to decrypt SOPS-encrypted artifacts")
+	cmd.Flags().StringSlice("sops-age-recipients", nil, "age public keys used when re-encrypting artifacts")
+	cmd.Flags().StringSlice("sops-kms", nil, "AWS KMS key ARNs used when re-encrypting artifacts")
+}
+
+// applySOPSFlags copies the SOPS flags into config.
+func applySOPSFlags(cmd *cobra.Command, config *tool.Config) {
+	config.SOPS.Binary, _ = cmd.Flags().GetString("sops-binary")
+	config.SOPS.AgeKeyFile, _ = cmd.Flags().GetString("sops-age-key-file")
+	config.SOPS.AgeRecipients, _ = cmd.Flags().GetStringSlice("sops-age-recipients")
+	config.SOPS.KMSKeys, _ = cmd.Flags().GetStringSlice("sops-kms")
+}
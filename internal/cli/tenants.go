@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+// addTenantFlags registers the flags that configure shared HTTP deployments.
+func addTenantFlags(cmd *cobra.Command) {
+	cmd.Flags().String("http-address", "", "Address (e.g., :8080) to serve MCP over streamable HTTP at "+tool.MCPPath+" to the tenants of --tenants instead of over stdio")
+	cmd.Flags().String("tenants", "", "YAML file declaring the tenants of a shared deployment, each with its own workspace root, bearer tokens, and rate limit")
+	cmd.Flags().String("tenant-header", "", "Request header (e.g., X-Gemara-Tenant) an authenticating proxy sets to the tenant ID; requests without a bearer token are identified by it")
+}
+
+// applyTenantFlags loads the tenants of a shared deployment, or returns nil
+// when the server serves a single workspace over stdio.
+func applyTenantFlags(cmd *cobra.Command) ([]*tool.Tenant, error) {
+	address, _ := cmd.Flags().GetString("http-address")
+	path, _ := cmd.Flags().GetString("tenants")
+	switch {
+	case address == "" && path == "":
+		return nil, nil
+	case address == "":
+		return nil, fmt.Errorf("tenants requires an http-address")
+	case path == "":
+		return nil, fmt.Errorf("http-address requires tenants")
+	}
+	return tool.LoadTenants(path)
+}
+
+// serveTenants serves handler over HTTP until ctx is cancelled. In-flight
+// requests then get up to timeout to finish before the listener and the
+// open streams are closed.
+func serveTenants(ctx context.Context, cmd *cobra.Command, handler http.Handler, drainer *tool.RequestDrainer, timeout time.Duration) error {
+	address, _ := cmd.Flags().GetString("http-address")
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to serve tenants: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(tool.MCPPath, handler)
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := drainer.Drain(drainCtx); err != nil {
+		fmt.Fprintf(os.Stderr, "shutdown timeout of %s exceeded; abandoning in-flight requests\n", timeout)
+	}
+	if err := tool.WaitWebhooks(drainCtx); err != nil {
+		fmt.Fprintf(os.Stderr, "shutdown timeout of %s exceeded; abandoning webhook deliveries\n", timeout)
+	}
+
+	// Streams stay open until closed, so the server is not shut down gracefully
+	if err := server.Close(); err != nil {
+		return err
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+var toolsCmd = &cobra.Command{
+	Use:   "tools",
+	Short: "Inspect the tools exposed by the server",
+}
+
+var toolsListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List registered tools grouped by mode",
+	Example: "gemara-mcp tools list --mode advisory --format json",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		modeName, _ := cmd.Flags().GetString("mode")
+		format, _ := cmd.Flags().GetString("format")
+
+		modes := tool.Modes()
+		if modeName != "" {
+			mode, err := tool.LookupMode(modeName)
+			if err != nil {
+				return err
+			}
+			modes = []tool.Mode{mode}
+		}
+
+		switch format {
+		case formatJSON:
+			return writeToolsJSON(cmd.OutOrStdout(), modes)
+		case formatText:
+			return writeToolsText(cmd.OutOrStdout(), modes)
+		default:
+			return fmt.Errorf("unsupported format %q: must be %q or %q", format, formatText, formatJSON)
+		}
+	},
+}
+
+var toolsInstructionsCmd = &cobra.Command{
+	Use:     "instructions",
+	Short:   "Print the instructions the server sends to clients on initialization",
+	Example: "gemara-mcp tools instructions --mode advisory",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		modeName, _ := cmd.Flags().GetString("mode")
+		mode, err := tool.LookupMode(modeName)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(cmd.OutOrStdout(), mode.Instructions())
+		return nil
+	},
+}
+
+func init() {
+	toolsInstructionsCmd.Flags().String("mode", "advisory", "Mode whose instructions to print")
+	toolsCmd.AddCommand(toolsInstructionsCmd)
+	toolsListCmd.Flags().String("mode", "", "Only list tools for the given mode")
+	toolsListCmd.Flags().String("format", formatText, "Output format (text or json)")
+	toolsCmd.AddCommand(toolsListCmd)
+}
+
+// modeTools is the JSON representation of the tools registered by a mode.
+type modeTools struct {
+	Mode        string     `json:"mode"`
+	Description string     `json:"description"`
+	Tools       []toolInfo `json:"tools"`
+}
+
+// toolInfo is the JSON representation of a single tool.
+type toolInfo struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	InputSchema interface{} `json:"input_schema"`
+}
+
+func writeToolsJSON(w io.Writer, modes []tool.Mode) error {
+	var out []modeTools
+	for _, m := range modes {
+		group := modeTools{Mode: m.Name(), Description: m.Description()}
+		for _, t := range m.Tools() {
+			group.Tools = append(group.Tools, toolInfo{
+				Name:        t.Name,
+				Description: t.Description,
+				InputSchema: t.InputSchema,
+			})
+		}
+		out = append(out, group)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func writeToolsText(w io.Writer, modes []tool.Mode) error {
+	for i, m := range modes {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "Mode: %s\n  %s\n", m.Name(), m.Description())
+		for _, t := range m.Tools() {
+			fmt.Fprintf(w, "\n  %s\n    %s\n", t.Name, t.Description)
+			writeSchemaParams(w, t.InputSchema)
+		}
+	}
+	return nil
+}
+
+// writeSchemaParams prints the top-level properties of a tool input schema.
+func writeSchemaParams(w io.Writer, schema interface{}) {
+	s, ok := schema.(map[string]interface{})
+	if !ok {
+		return
+	}
+	props, _ := s["properties"].(map[string]interface{})
+	if len(props) == 0 {
+		return
+	}
+
+	required := map[string]bool{}
+	if req, ok := s["required"].([]string); ok {
+		for _, name := range req {
+			required[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "    Parameters:")
+	for _, name := range names {
+		prop, _ := props[name].(map[string]interface{})
+		typ, _ := prop["type"].(string)
+		desc, _ := prop["description"].(string)
+		flag := ""
+		if required[name] {
+			flag = ", required"
+		}
+		fmt.Fprintf(w, "      %s (%s%s)", name, typ, flag)
+		if desc != "" {
+			fmt.Fprintf(w, ": %s", desc)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// addToolFilterFlags registers the flags that limit the tools a server exposes.
+func addToolFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("tools-allow", nil, "Only register these tools (names or patterns such as generate_*; tools.allow in a config file)")
+	cmd.Flags().StringSlice("tools-deny", nil, "Never register these tools (names or patterns; tools.deny in a config file)")
+}
+
+// applyToolFilterFlags builds the filter of the tools the served modes
+// register. Every allow and deny entry must match a tool of one of modes,
+// which must be configured as they are served, before their filter is set:
+// the tools offered depend on the configuration and the tenant.
+func applyToolFilterFlags(cmd *cobra.Command, modes ...tool.Mode) (tool.ToolFilter, error) {
+	allow, _ := cmd.Flags().GetStringSlice("tools-allow")
+	deny, _ := cmd.Flags().GetStringSlice("tools-deny")
+	if len(allow) == 0 && len(deny) == 0 {
+		return tool.ToolFilter{}, nil
+	}
+	var tools []*mcp.Tool
+	seen := map[string]bool{}
+	for _, mode := range modes {
+		for _, t := range mode.Tools() {
+			if !seen[t.Name] {
+				seen[t.Name] = true
+				tools = append(tools, t)
+			}
+		}
+	}
+	return tool.NewToolFilter(allow, deny, tools)
+}
@@ -1,15 +1,129 @@
 package cli
 
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
 // Version information
 // These can be set via ldflags during build:
 // -X github.com/gemaraproj/gemara-mcp/internal/cli.Version=...
 // -X github.com/gemaraproj/gemara-mcp/internal/cli.Build=...
+// -X github.com/gemaraproj/gemara-mcp/internal/cli.Commit=...
+// -X github.com/gemaraproj/gemara-mcp/internal/cli.BuildDate=...
 var (
-	Version = "0.1.0"
-	Build   = "dev"
+	Version   = "0.1.0"
+	Build     = "dev"
+	Commit    = ""
+	BuildDate = ""
 )
 
+// protocolVersions are the MCP protocol versions the go-sdk negotiates with
+// clients, newest first. Update them when the SDK is upgraded.
+var protocolVersions = []string{"2025-11-25", "2025-06-18", "2025-03-26", "2024-11-05"}
+
 // GetVersion returns the version string
 func GetVersion() string {
 	return Version + "-" + Build
 }
+
+// BuildInfo is the provenance of the running binary reported by the version command.
+type BuildInfo struct {
+	Version          string   `json:"version"`
+	Commit           string   `json:"commit,omitempty"`
+	BuildDate        string   `json:"build_date,omitempty"`
+	GoVersion        string   `json:"go_version"`
+	Platform         string   `json:"platform"`
+	SchemaModule     string   `json:"schema_module"`
+	SchemaVersion    string   `json:"schema_version,omitempty"`
+	SchemaFallback   bool     `json:"schema_fallback,omitempty"`
+	SchemaError      string   `json:"schema_error,omitempty"`
+	ProtocolVersions []string `json:"protocol_versions"`
+}
+
+var versionCmd = &cobra.Command{
+	Use:     "version",
+	Short:   "Print version information",
+	Example: "gemara-mcp version\ngemara-mcp version --format json",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format != formatText && format != formatJSON {
+			return fmt.Errorf("unsupported format %q: must be %q or %q", format, formatText, formatJSON)
+		}
+
+		info := buildInfo()
+		// The schema may be unreachable; the rest of the report is still useful
+		if ctx, err := applySchemaFlags(cmd); err != nil {
+			info.SchemaError = err.Error()
+		} else if summary, err := tool.ListSchemaDefinitions(ctx); err != nil {
+			info.SchemaError = err.Error()
+		} else {
+			info.SchemaVersion = summary.ModuleVersion
+			info.SchemaFallback = summary.Fallback
+		}
+
+		if format == formatJSON {
+			return writeJSON(cmd.OutOrStdout(), info)
+		}
+		writeBuildInfo(cmd.OutOrStdout(), info)
+		return nil
+	},
+}
+
+func init() {
+	versionCmd.Flags().String("format", formatText, "Output format (text or json)")
+	addHTTPFlags(versionCmd)
+	addRegistryFlags(versionCmd)
+}
+
+// buildInfo describes the binary, taking the commit and build date from the Go
+// build information when they were not set via ldflags.
+func buildInfo() BuildInfo {
+	info := BuildInfo{
+		Version:          GetVersion(),
+		Commit:           Commit,
+		BuildDate:        BuildDate,
+		GoVersion:        runtime.Version(),
+		Platform:         runtime.GOOS + "/" + runtime.GOARCH,
+		SchemaModule:     tool.GemaraModule,
+		ProtocolVersions: protocolVersions,
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = s.Value
+			}
+		}
+	}
+	return info
+}
+
+func writeBuildInfo(w io.Writer, info BuildInfo) {
+	fmt.Fprintf(w, "Gemara MCP Server %s\n", info.Version)
+	if info.Commit != "" {
+		fmt.Fprintf(w, "  Commit:        %s\n", info.Commit)
+	}
+	if info.BuildDate != "" {
+		fmt.Fprintf(w, "  Built:         %s\n", info.BuildDate)
+	}
+	fmt.Fprintf(w, "  Go:            %s %s\n", info.GoVersion, info.Platform)
+	switch {
+	case info.SchemaError != "":
+		fmt.Fprintf(w, "  Schema:        %s (unavailable: %s)\n", info.SchemaModule, info.SchemaError)
+	case info.SchemaFallback:
+		fmt.Fprintf(w, "  Schema:        %s@%s (embedded fallback)\n", info.SchemaModule, info.SchemaVersion)
+	default:
+		fmt.Fprintf(w, "  Schema:        %s@%s\n", info.SchemaModule, info.SchemaVersion)
+	}
+	fmt.Fprintf(w, "  MCP protocols: %s\n", strings.Join(info.ProtocolVersions, ", "))
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+var warmupCmd = &cobra.Command{
+	Use:   "warmup",
+	Short: "Resolve the schema and fetch the lexicon ahead of a session",
+	Long: "Resolve the Gemara CUE module, compile the schema, and fetch the lexicon so they are cached " +
+		"before an agent session starts. The module is kept in the local CUE module cache, and with " +
+		"--cache-storage the fetched lexicon is persisted for later server processes.",
+	Example: "gemara-mcp warmup --cache-storage ~/.cache/gemara-mcp",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := applyLexiconFlags(cmd); err != nil {
+			return err
+		}
+		config := tool.NewConfig()
+		if err := applyHTTPFlags(cmd, config); err != nil {
+			return err
+		}
+		if err := applyRegistryFlags(cmd, config); err != nil {
+			return err
+		}
+		if err := applyCacheStorageFlag(cmd, config); err != nil {
+			return err
+		}
+
+		report, err := tool.Warmup(tool.WithConfig(cmd.Context(), config))
+		if err != nil {
+			return err
+		}
+		printWarmupReport(cmd.OutOrStdout(), report)
+		return nil
+	},
+}
+
+// applyCacheStorageFlag opens the storage named by --cache-storage, if any,
+// as the storage of config.
+func applyCacheStorageFlag(cmd *cobra.Command, config *tool.Config) error {
+	location, _ := cmd.Flags().GetString("cache-storage")
+	if location == "" {
+		return nil
+	}
+	storage, err := tool.OpenStorage(location)
+	if err != nil {
+		return err
+	}
+	config.Storage = storage
+	return nil
+}
+
+// printWarmupReport describes what was loaded and how long it took.
+func printWarmupReport(w io.Writer, report tool.WarmupReport) {
+	fallback := ""
+	if report.SchemaFallback {
+		fallback = " (embedded fallback: registry unavailable)"
+	}
+	fmt.Fprintf(w, "Gemara schema %s ready in %s%s\n", report.SchemaVersion, report.SchemaDuration.Round(time.Millisecond), fallback)
+	stale := ""
+	if report.LexiconStale {
+		stale = " (stale: upstream unavailable)"
+	}
+	fmt.Fprintf(w, "Lexicon with %d terms from %s ready in %s%s\n",
+		report.LexiconEntries, report.LexiconSource, report.LexiconDuration.Round(time.Millisecond), stale)
+}
+
+func init() {
+	warmupCmd.Flags().String("lexicon-url", tool.DefaultLexiconURL, "URL of the base lexicon (https://, or file:// to a lexicon or gemara checkout; empty to fetch only overlays)")
+	warmupCmd.Flags().StringArray("lexicon-overlay", nil, "URL of a lexicon layered over the base (repeatable)")
+	warmupCmd.Flags().String("lexicon-conflict", tool.LexiconConflictOverride, "How to resolve a term defined by several lexicons ("+strings.Join(tool.LexiconConflictRules(), ", ")+")")
+	warmupCmd.Flags().String("cache-storage", "", "Persist the fetched lexicon for later server processes (directory, file://, sqlite://, or s3://bucket/prefix)")
+	addHTTPFlags(warmupCmd)
+	addRegistryFlags(warmupCmd)
+}
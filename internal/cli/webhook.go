@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"os"
+	"strings"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/spf13/cobra"
+)
+
+// addWebhookFlags registers the flags that configure webhook delivery.
+func addWebhookFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("webhook-url", nil, "URL to POST validation and posture events to as JSON (repeatable)")
+	cmd.Flags().StringSlice("webhook-event", nil, "Webhook events to send (default all: "+strings.Join(tool.WebhookEvents, ", ")+")")
+	cmd.Flags().Duration("webhook-timeout", tool.DefaultWebhookTimeout, "Timeout of each webhook delivery attempt")
+}
+
+// applyWebhookFlags copies the webhook flags into config. The signing secret
+// is read from GEMARA_WEBHOOK_SECRET so it stays out of process listings.
+func applyWebhookFlags(cmd *cobra.Command, config *tool.Config) error {
+	config.Webhooks.URLs, _ = cmd.Flags().GetStringArray("webhook-url")
+	config.Webhooks.Events, _ = cmd.Flags().GetStringSlice("webhook-event")
+	config.Webhooks.Timeout, _ = cmd.Flags().GetDuration("webhook-timeout")
+	config.Webhooks.Secret = os.Getenv("GEMARA_WEBHOOK_SECRET")
+	return config.Webhooks.Validate()
+}
@@ -0,0 +1,332 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	anonymizePseudonymize = "pseudonymize"
+	anonymizeRedact       = "redact"
+	anonymizeScrub        = "scrub"
+
+	redactedText = "Redacted for sharing."
+)
+
+var (
+	urlPattern   = regexp.MustCompile(`https?://[^\s"'<>)\]]+`)
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+)
+
+// redactionProfile selects which artifact fields are anonymized. Fields are
+// matched by key (e.g., "name") or by parent and key (e.g., "author.id").
+type redactionProfile struct {
+	// Pseudonymize replaces values with stable pseudonyms, so equal values,
+	// such as an id and the references to it, stay equal.
+	Pseudonymize []string
+	// Redact replaces free text with a placeholder.
+	Redact []string
+	// Scrub replaces URLs and email addresses inside all other text.
+	Scrub bool
+}
+
+// redactionProfiles are the profiles accepted by AnonymizeArtifact.
+var redactionProfiles = map[string]redactionProfile{
+	"standard": {
+		Pseudonymize: []string{"name", "author.id", "contact", "contacts", "email", "url", "owner", "organization"},
+		Scrub:        true,
+	},
+	"strict": {
+		Pseudonymize: []string{
+			"name", "author.id", "contact", "contacts", "email", "url", "owner", "organization",
+			"metadata.id", "reference-id",
+		},
+		Redact: []string{"title", "description", "remarks", "objective", "rationale", "recommendation", "notes"},
+		Scrub:  true,
+	},
+}
+
+// redactionProfileNames returns the profile names in sorted order.
+func redactionProfileNames() []string {
+	names := make([]string, 0, len(redactionProfiles))
+	for name := range redactionProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MetadataAnonymizeArtifact describes the AnonymizeArtifact tool.
+var MetadataAnonymizeArtifact = &mcp.Tool{
+	Name: "anonymize_artifact",
+	Description: "Strip or pseudonymize organization-identifying fields (names, URLs, contacts) in a Gemara artifact according " +
+		"to a redaction profile, keeping it schema-valid, so failing artifacts can be shared in bug reports and forums.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"artifact_content"},
+		"properties": map[string]interface{}{
+			"artifact_content": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML content of the Gemara artifact to anonymize",
+			},
+			"definition": map[string]interface{}{
+				"type":        "string",
+				"description": "CUE definition name of the artifact (e.g., '#ControlCatalog'); detected from the content when omitted",
+			},
+			"profile": map[string]interface{}{
+				"type":        "string",
+				"enum":        redactionProfileNames(),
+				"description": "Redaction profile: standard (identities and links) or strict (also ids, titles, and descriptions); default: standard",
+			},
+			"fields": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Additional fields to pseudonymize, by key or parent.key (e.g., 'vendor' or 'metadata.version')",
+			},
+		},
+	},
+}
+
+// InputAnonymizeArtifact is the input for the AnonymizeArtifact tool.
+type InputAnonymizeArtifact struct {
+	ArtifactContent string   `json:"artifact_content"`
+	Definition      string   `json:"definition,omitempty"`
+	Profile         string   `json:"profile,omitempty"`
+	Fields          []string `json:"fields,omitempty"`
+}
+
+// AnonymizedField records a field that was anonymized. Original values are
+// never reported, so the output is safe to share.
+type AnonymizedField struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+}
+
+// OutputAnonymizeArtifact is the output for the AnonymizeArtifact tool.
+type OutputAnonymizeArtifact struct {
+	Definition        string            `json:"definition"`
+	Profile           string            `json:"profile"`
+	AnonymizedContent string            `json:"anonymized_content"`
+	Changes           []AnonymizedField `json:"changes"`
+	// Kept lists matching fields left unchanged because the schema does not
+	// accept a replacement; review them before sharing.
+	Kept    []string `json:"kept,omitempty"`
+	Valid   bool     `json:"valid"`
+	Errors  []string `json:"errors,omitempty"`
+	Message string   `json:"message"`
+}
+
+// AnonymizeArtifact removes organization-identifying content from an artifact.
+func AnonymizeArtifact(ctx context.Context, _ *mcp.CallToolRequest, input InputAnonymizeArtifact) (*mcp.CallToolResult, OutputAnonymizeArtifact, error) {
+	if input.ArtifactContent == "" {
+		return nil, OutputAnonymizeArtifact{}, fmt.Errorf("artifact_content is required")
+	}
+
+	profileName := input.Profile
+	if profileName == "" {
+		profileName = "standard"
+	}
+	profile, ok := redactionProfiles[profileName]
+	if !ok {
+		return nil, OutputAnonymizeArtifact{}, fmt.Errorf("unknown profile %q (available: %s)", profileName, strings.Join(redactionProfileNames(), ", "))
+	}
+	profile.Pseudonymize = append(slices.Clone(profile.Pseudonymize), input.Fields...)
+
+	doc, err := parseArtifact(ctx, input.ArtifactContent)
+	if err != nil {
+		return nil, OutputAnonymizeArtifact{}, err
+	}
+	definition := input.Definition
+	if definition == "" {
+		definition = artifactKind(doc)
+		if definition == "" {
+			return nil, OutputAnonymizeArtifact{}, fmt.Errorf("unable to determine the artifact definition; supply definition")
+		}
+	}
+	definition = normalizeDefinition(definition)
+
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputAnonymizeArtifact{}, err
+	}
+	entrypoint, err := schema.lookupDefinition(definition)
+	if err != nil {
+		return nil, OutputAnonymizeArtifact{}, err
+	}
+
+	var tree interface{}
+	if err := yaml.UnmarshalWithOptions([]byte(input.ArtifactContent), &tree, yaml.UseOrderedMap()); err != nil {
+		return nil, OutputAnonymizeArtifact{}, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	a := &anonymizer{profile: profile, pseudonyms: map[string]string{}, changes: []AnonymizedField{}}
+	anonymized := a.anonymizeValue(tree, entrypoint, "", "$")
+
+	out, err := yaml.MarshalWithOptions(anonymized, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return nil, OutputAnonymizeArtifact{}, fmt.Errorf("failed to encode anonymized artifact: %w", err)
+	}
+
+	validation, err := validateAgainstSchema(schema, definition, string(out))
+	if err != nil {
+		return nil, OutputAnonymizeArtifact{}, err
+	}
+
+	output := OutputAnonymizeArtifact{
+		Definition:        definition,
+		Profile:           profileName,
+		AnonymizedContent: string(out),
+		Changes:           a.changes,
+		Kept:              a.kept,
+		Valid:             validation.Valid,
+		Errors:            validation.Errors,
+		Message:           fmt.Sprintf("Anonymized %d field(s) with the %s profile; %s", len(a.changes), profileName, strings.ToLower(validation.Message)),
+	}
+	if len(a.kept) > 0 {
+		output.Message += fmt.Sprintf("; %d field(s) kept to stay schema-valid, review before sharing", len(a.kept))
+	}
+	return nil, output, nil
+}
+
+// anonymizer walks an ordered YAML tree alongside its schema, replacing
+// identifying values with ones the schema still accepts.
+type anonymizer struct {
+	profile    redactionProfile
+	pseudonyms map[string]string
+	changes    []AnonymizedField
+	kept       []string
+}
+
+func (a *anonymizer) anonymizeValue(value interface{}, schema cue.Value, parent, path string) interface{} {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		for i, item := range v {
+			key := fmt.Sprint(item.Key)
+			fieldSchema, _ := schemaAt(schema, []pathSegment{{Key: key}})
+			fieldPath := childPath(path, key)
+
+			action := a.fieldAction(parent, key)
+			if action == "" || (action == anonymizeRedact && isCompound(item.Value)) {
+				v[i].Value = a.anonymizeValue(item.Value, fieldSchema, key, fieldPath)
+				continue
+			}
+			v[i].Value = a.replaceField(item.Value, fieldSchema, action, key, fieldPath)
+		}
+		return v
+	case []interface{}:
+		elem, _ := schemaAt(schema, []pathSegment{{IsIndex: true}})
+		for i := range v {
+			v[i] = a.anonymizeValue(v[i], elem, parent, fmt.Sprintf("%s[%d]", path, i))
+		}
+		return v
+	case string:
+		if !a.profile.Scrub {
+			return v
+		}
+		scrubbed := a.scrub(v)
+		if scrubbed != v {
+			return a.accept(v, scrubbed, schema, anonymizeScrub, path)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// fieldAction returns how the profile treats a field.
+func (a *anonymizer) fieldAction(parent, key string) string {
+	matches := func(patterns []string) bool {
+		return slices.Contains(patterns, key) || slices.Contains(patterns, parent+"."+key)
+	}
+	switch {
+	case matches(a.profile.Pseudonymize):
+		return anonymizePseudonymize
+	case matches(a.profile.Redact):
+		return anonymizeRedact
+	}
+	return ""
+}
+
+// replaceField anonymizes every scalar of a matched field, including list items
+// such as a list of contacts.
+func (a *anonymizer) replaceField(value interface{}, schema cue.Value, action, key, path string) interface{} {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		for i, item := range v {
+			itemKey := fmt.Sprint(item.Key)
+			fieldSchema, _ := schemaAt(schema, []pathSegment{{Key: itemKey}})
+			v[i].Value = a.replaceField(item.Value, fieldSchema, action, itemKey, childPath(path, itemKey))
+		}
+		return v
+	case []interface{}:
+		elem, _ := schemaAt(schema, []pathSegment{{IsIndex: true}})
+		for i := range v {
+			v[i] = a.replaceField(v[i], elem, action, key, fmt.Sprintf("%s[%d]", path, i))
+		}
+		return v
+	case string:
+		replacement := redactedText
+		if action == anonymizePseudonymize {
+			replacement = a.pseudonym(key, v)
+		}
+		return a.accept(v, replacement, schema, action, path)
+	default:
+		return v
+	}
+}
+
+// accept returns the replacement when the schema allows it, and otherwise keeps
+// the original and flags it for review.
+func (a *anonymizer) accept(original, replacement string, schema cue.Value, action, path string) string {
+	if original == replacement {
+		return original
+	}
+	if schema.Exists() {
+		if err := schema.Unify(schema.Context().Encode(replacement)).Validate(cue.Concrete(true)); err != nil {
+			a.kept = append(a.kept, path)
+			return original
+		}
+	}
+	a.changes = append(a.changes, AnonymizedField{Path: path, Action: action})
+	return replacement
+}
+
+// pseudonym returns a stable pseudonym for a value, shaped like the original
+// so that URLs and email addresses remain well formed.
+func (a *anonymizer) pseudonym(key, value string) string {
+	if p, ok := a.pseudonyms[value]; ok {
+		return p
+	}
+	n := len(a.pseudonyms) + 1
+	var p string
+	switch {
+	case urlPattern.MatchString(value) && urlPattern.FindString(value) == value:
+		p = fmt.Sprintf("https://example.com/anon-%d", n)
+	case emailPattern.MatchString(value) && emailPattern.FindString(value) == value:
+		p = fmt.Sprintf("anon-%d@example.com", n)
+	default:
+		p = fmt.Sprintf("%s-%d", strings.ToLower(strings.ReplaceAll(key, "-", "")), n)
+	}
+	a.pseudonyms[value] = p
+	return p
+}
+
+// scrub replaces URLs and email addresses embedded in free text.
+func (a *anonymizer) scrub(text string) string {
+	text = urlPattern.ReplaceAllStringFunc(text, func(u string) string {
+		return a.pseudonym("url", u)
+	})
+	return emailPattern.ReplaceAllStringFunc(text, func(e string) string {
+		return a.pseudonym("email", e)
+	})
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const anonymizeTestPolicy = `metadata:
+  id: ACME-POL
+  description: |
+    Policy owned by the ACME security team. Questions go to security@acme.example
+    or https://wiki.acme.example/policies.
+  author:
+    id: acme-security
+    name: ACME Security
+    type: Human
+title: ACME Cloud Policy
+imports:
+  catalogs:
+    - reference-id: ACME-CATALOG
+adherence:
+  assessment-plans:
+    - id: AP-01
+      requirement-id: ACME.C01.TR01
+      frequency: daily
+      evaluation-methods:
+        - type: automated
+`
+
+func TestAnonymizeArtifact(t *testing.T) {
+	useTestSchema(t)
+
+	tests := []struct {
+		name           string
+		input          InputAnonymizeArtifact
+		wantErr        bool
+		errContains    string
+		validateOutput func(t *testing.T, output OutputAnonymizeArtifact)
+	}{
+		{
+			name:        "missing artifact_content",
+			input:       InputAnonymizeArtifact{},
+			wantErr:     true,
+			errContains: "artifact_content is required",
+		},
+		{
+			name:        "unknown profile",
+			input:       InputAnonymizeArtifact{ArtifactContent: anonymizeTestPolicy, Profile: "paranoid"},
+			wantErr:     true,
+			errContains: "unknown profile",
+		},
+		{
+			name:  "standard profile",
+			input: InputAnonymizeArtifact{ArtifactContent: anonymizeTestPolicy},
+			validateOutput: func(t *testing.T, output OutputAnonymizeArtifact) {
+				assert.Equal(t, "standard", output.Profile)
+				assert.True(t, output.Valid, "anonymized artifact should stay valid: %v", output.Errors)
+				assert.NotContains(t, output.AnonymizedContent, "ACME Security", "names should be replaced")
+				assert.NotContains(t, output.AnonymizedContent, "acme-security", "actor ids should be replaced")
+				assert.NotContains(t, output.AnonymizedContent, "security@acme.example", "emails in text should be scrubbed")
+				assert.NotContains(t, output.AnonymizedContent, "wiki.acme.example", "URLs in text should be scrubbed")
+				assert.Contains(t, output.AnonymizedContent, "id: ACME-POL", "artifact ids are kept by the standard profile")
+				assert.Contains(t, output.AnonymizedContent, "Policy owned by the ACME security team", "text around links is kept")
+				assert.Contains(t, output.Changes, AnonymizedField{Path: "$.metadata.author.name", Action: anonymizePseudonymize})
+				assert.Contains(t, output.Changes, AnonymizedField{Path: "$.metadata.description", Action: anonymizeScrub})
+			},
+		},
+		{
+			name:  "strict profile",
+			input: InputAnonymizeArtifact{ArtifactContent: anonymizeTestPolicy, Profile: "strict"},
+			validateOutput: func(t *testing.T, output OutputAnonymizeArtifact) {
+				doc, err := parseArtifact(context.Background(), output.AnonymizedContent)
+				require.NoError(t, err, "anonymized content should parse")
+				metadata := doc["metadata"].(map[string]interface{})
+				assert.NotEqual(t, "ACME-POL", metadata["id"], "artifact id should be pseudonymized")
+				assert.Equal(t, redactedText, metadata["description"], "descriptions should be redacted")
+				assert.Equal(t, redactedText, doc["title"], "titles should be redacted")
+				assert.NotContains(t, output.AnonymizedContent, "ACME-CATALOG", "references should be pseudonymized")
+				assert.Contains(t, output.AnonymizedContent, "ACME.C01.TR01", "requirement ids are not identifying fields")
+				assert.True(t, output.Valid, "anonymized artifact should stay valid: %v", output.Errors)
+			},
+		},
+		{
+			name:  "replacements the schema rejects are kept for review",
+			input: InputAnonymizeArtifact{ArtifactContent: anonymizeTestPolicy, Fields: []string{"author.type"}},
+			validateOutput: func(t *testing.T, output OutputAnonymizeArtifact) {
+				assert.True(t, output.Valid, "artifact should stay valid: %v", output.Errors)
+				assert.Equal(t, []string{"$.metadata.author.type"}, output.Kept)
+				assert.Contains(t, output.AnonymizedContent, "type: Human")
+				assert.Contains(t, output.Message, "review before sharing")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := AnonymizeArtifact(context.Background(), nil, tt.input)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, tt.errContains)
+				return
+			}
+			require.NoError(t, err, "should not return error")
+			tt.validateOutput(t, output)
+		})
+	}
+}
+
+func TestAnonymizeArtifactPseudonyms(t *testing.T) {
+	useTestSchema(t)
+	content, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err, "should read test catalog")
+
+	_, first, err := AnonymizeArtifact(context.Background(), nil, InputAnonymizeArtifact{ArtifactContent: string(content)})
+	require.NoError(t, err, "should anonymize catalog")
+	_, second, err := AnonymizeArtifact(context.Background(), nil, InputAnonymizeArtifact{ArtifactContent: string(content)})
+	require.NoError(t, err, "should anonymize catalog")
+	assert.Equal(t, first.AnonymizedContent, second.AnonymizedContent, "output should be deterministic")
+	assert.NotContains(t, first.AnonymizedContent, "name: FINOS\n", "author name should be replaced")
+}
+
+func TestAnonymizerPseudonym(t *testing.T) {
+	a := &anonymizer{pseudonyms: map[string]string{}}
+	assert.Equal(t, "referenceid-1", a.pseudonym("reference-id", "ACME-CATALOG"))
+	assert.Equal(t, "referenceid-1", a.pseudonym("reference-id", "ACME-CATALOG"), "equal values should share a pseudonym")
+	assert.Equal(t, "https://example.com/anon-2", a.pseudonym("url", "https://acme.example/catalog"))
+	assert.Equal(t, "anon-3@example.com", a.pseudonym("contact", "grc@acme.example"))
+	assert.Equal(t, "See https://example.com/anon-2 or anon-3@example.com.", a.scrub("See https://acme.example/catalog or grc@acme.example."))
+}
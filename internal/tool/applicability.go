@@ -0,0 +1,333 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MetadataFilterCatalogByApplicability describes the FilterCatalogByApplicability tool.
+var MetadataFilterCatalogByApplicability = &mcp.Tool{
+	Name: "filter_catalog_by_applicability",
+	Description: "Filter a ControlCatalog down to the controls that apply in a given context: the technology stack, " +
+		"deployment model, and data classification are matched to the catalog's applicability categories, assessment " +
+		"requirements for no matched category are removed, and controls left without requirements are excluded with " +
+		"the reason. Returns the filtered catalog, validated against the schema. Nothing is written to disk.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": func() map[string]interface{} {
+			properties := artifactSourceProperties("filter")
+			properties["technology_stack"] = map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Technologies in use, such as 'kubernetes' or 'postgresql'",
+			}
+			properties["deployment_model"] = map[string]interface{}{
+				"type":        "string",
+				"description": "How the system is deployed, such as 'saas', 'public cloud', or 'on-premises'",
+			}
+			properties["data_classification"] = map[string]interface{}{
+				"type":        "string",
+				"description": "Classification of the data handled, such as 'TLP:Amber' or 'confidential'",
+			}
+			properties["attributes"] = map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Other context terms, or applicability category IDs to include directly",
+			}
+			properties["id"] = map[string]interface{}{
+				"type":        "string",
+				"description": "metadata.id of the filtered catalog (default: <catalog id>-filtered)",
+			}
+			return properties
+		}(),
+	},
+}
+
+// InputFilterCatalogByApplicability is the input for the FilterCatalogByApplicability tool.
+type InputFilterCatalogByApplicability struct {
+	ArtifactContent    string   `json:"artifact_content,omitempty"`
+	ArtifactURI        string   `json:"artifact_uri,omitempty"`
+	TechnologyStack    []string `json:"technology_stack,omitempty"`
+	DeploymentModel    string   `json:"deployment_model,omitempty"`
+	DataClassification string   `json:"data_classification,omitempty"`
+	Attributes         []string `json:"attributes,omitempty"`
+	ID                 string   `json:"id,omitempty"`
+}
+
+// ApplicabilityMatch is an applicability category matched by a context value.
+type ApplicabilityMatch struct {
+	Category string `json:"category"`
+	Title    string `json:"title,omitempty"`
+	// Context names the context field and value that matched, such as
+	// "deployment_model: saas".
+	Context string `json:"context"`
+}
+
+// ApplicabilityExclusion is a control left out of a filtered catalog.
+type ApplicabilityExclusion struct {
+	Control string `json:"control"`
+	// Applicability lists the categories the control's requirements apply to.
+	Applicability []string `json:"applicability"`
+	Reason        string   `json:"reason"`
+}
+
+// OutputFilterCatalogByApplicability is the output for the FilterCatalogByApplicability tool.
+type OutputFilterCatalogByApplicability struct {
+	FilteredContent string               `json:"filtered_content"`
+	Matched         []ApplicabilityMatch `json:"matched"`
+	// Unmatched lists the context values that match no applicability category.
+	Unmatched []string                 `json:"unmatched"`
+	Included  []string                 `json:"included"`
+	Excluded  []ApplicabilityExclusion `json:"excluded"`
+	// RemovedRequirements are the assessment requirements of included
+	// controls that apply to no matched category.
+	RemovedRequirements []string `json:"removed_requirements,omitempty"`
+	Valid               bool     `json:"valid"`
+	Errors              []string `json:"errors,omitempty"`
+	Message             string   `json:"message"`
+}
+
+// applicabilityCategory is an applicability category a context value can match.
+type applicabilityCategory struct {
+	id     string
+	title  string
+	tokens []string
+}
+
+// FilterCatalogByApplicability keeps the controls of a catalog that apply in a context.
+func FilterCatalogByApplicability(ctx context.Context, _ *mcp.CallToolRequest, input InputFilterCatalogByApplicability) (*mcp.CallToolResult, OutputFilterCatalogByApplicability, error) {
+	content, err := artifactInputContent(ctx, input.ArtifactContent, input.ArtifactURI)
+	if err != nil {
+		return nil, OutputFilterCatalogByApplicability{}, err
+	}
+	parsed, err := parseArtifact(ctx, content)
+	if err != nil {
+		return nil, OutputFilterCatalogByApplicability{}, err
+	}
+	if artifactKind(parsed) != "ControlCatalog" {
+		return nil, OutputFilterCatalogByApplicability{}, fmt.Errorf("filtering by applicability requires a ControlCatalog")
+	}
+	var doc yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions([]byte(content), &doc, yaml.UseOrderedMap()); err != nil {
+		return nil, OutputFilterCatalogByApplicability{}, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	var values []string
+	for _, v := range input.TechnologyStack {
+		values = append(values, "technology_stack: "+v)
+	}
+	if input.DeploymentModel != "" {
+		values = append(values, "deployment_model: "+input.DeploymentModel)
+	}
+	if input.DataClassification != "" {
+		values = append(values, "data_classification: "+input.DataClassification)
+	}
+	for _, v := range input.Attributes {
+		values = append(values, "attributes: "+v)
+	}
+	if len(values) == 0 {
+		return nil, OutputFilterCatalogByApplicability{}, fmt.Errorf("technology_stack, deployment_model, data_classification, or attributes is required")
+	}
+
+	controls := mapValueList(doc, "controls")
+	categories := applicabilityCategories(doc, controls)
+	output := OutputFilterCatalogByApplicability{
+		Matched:   []ApplicabilityMatch{},
+		Unmatched: []string{},
+		Included:  []string{},
+		Excluded:  []ApplicabilityExclusion{},
+	}
+	var scope []string
+	for _, value := range values {
+		term := value[strings.Index(value, ": ")+2:]
+		matched := false
+		for _, category := range categories {
+			if !matchesApplicability(term, category) {
+				continue
+			}
+			matched = true
+			output.Matched = append(output.Matched, ApplicabilityMatch{Category: category.id, Title: category.title, Context: value})
+			if !slices.Contains(scope, category.id) {
+				scope = append(scope, category.id)
+			}
+		}
+		if !matched {
+			output.Unmatched = append(output.Unmatched, value)
+		}
+	}
+	if len(scope) == 0 {
+		ids := make([]string, len(categories))
+		for i, c := range categories {
+			ids[i] = c.id
+		}
+		return nil, OutputFilterCatalogByApplicability{}, fmt.Errorf("no applicability category matches the context; the catalog defines: %s", strings.Join(ids, ", "))
+	}
+	matchedBy := map[string][]string{}
+	for _, m := range output.Matched {
+		matchedBy[m.Category] = append(matchedBy[m.Category], m.Context)
+	}
+
+	kept := []interface{}{}
+	usedFamilies := map[string]bool{}
+	for _, c := range controls {
+		control, ok := c.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+		id := orderedID(control)
+		filtered, removed := scopeRequirements(control, scope)
+		if requirements, _ := mapValue(filtered, "assessment-requirements").([]interface{}); len(requirements) == 0 {
+			applies := controlApplicability(control)
+			output.Excluded = append(output.Excluded, ApplicabilityExclusion{
+				Control:       id,
+				Applicability: applies,
+				Reason:        applicabilityReason(applies, scope, matchedBy),
+			})
+			continue
+		}
+		output.RemovedRequirements = append(output.RemovedRequirements, removed...)
+		kept = append(kept, filtered)
+		usedFamilies[fmt.Sprint(mapValue(control, "family"))] = true
+		output.Included = append(output.Included, id)
+	}
+	if len(kept) == 0 {
+		return nil, OutputFilterCatalogByApplicability{}, fmt.Errorf("no controls apply to the matched categories: %s", strings.Join(scope, ", "))
+	}
+
+	doc = setMapValue(doc, "controls", kept)
+	if families := mapValueList(doc, "families"); families != nil {
+		keptFamilies := []interface{}{}
+		for _, f := range families {
+			if usedFamilies[orderedID(f)] {
+				keptFamilies = append(keptFamilies, f)
+			}
+		}
+		doc = setMapValue(doc, "families", keptFamilies)
+	}
+	catalogID := metadataID(parsed)
+	filteredID := input.ID
+	if filteredID == "" {
+		filteredID = catalogID + "-filtered"
+	}
+	if m, ok := mapValue(doc, "metadata").(yaml.MapSlice); ok {
+		doc = setMapValue(doc, "metadata", setMapValue(m, "id", filteredID))
+	}
+
+	out, err := yaml.MarshalWithOptions(doc, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return nil, OutputFilterCatalogByApplicability{}, fmt.Errorf("failed to encode filtered catalog: %w", err)
+	}
+	output.FilteredContent = string(out)
+
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputFilterCatalogByApplicability{}, err
+	}
+	validation, err := validateAgainstSchema(schema, "#ControlCatalog", output.FilteredContent)
+	if err != nil {
+		return nil, OutputFilterCatalogByApplicability{}, err
+	}
+	output.Valid = validation.Valid
+	output.Errors = validation.Errors
+	output.Message = fmt.Sprintf("Filtered %s into %s for %s: %d control(s) apply, %d excluded; %s",
+		catalogID, filteredID, strings.Join(scope, ", "), len(output.Included), len(output.Excluded), strings.ToLower(validation.Message))
+	if len(output.Unmatched) > 0 {
+		output.Message += fmt.Sprintf("; %d context value(s) match no category: %s", len(output.Unmatched), strings.Join(output.Unmatched, ", "))
+	}
+	return nil, output, nil
+}
+
+// applicabilityCategories lists the categories declared in the catalog
+// metadata, followed by any category only named by a requirement.
+func applicabilityCategories(doc yaml.MapSlice, controls []interface{}) []applicabilityCategory {
+	var categories []applicabilityCategory
+	seen := map[string]bool{}
+	add := func(id, title string) {
+		if id == "" || seen[id] {
+			return
+		}
+		seen[id] = true
+		categories = append(categories, applicabilityCategory{
+			id:     id,
+			title:  title,
+			tokens: append(applicabilityTokens(id), applicabilityTokens(title)...),
+		})
+	}
+
+	metadata, _ := mapValue(doc, "metadata").(yaml.MapSlice)
+	for _, c := range mapValueList(metadata, "applicability-categories") {
+		m, _ := c.(yaml.MapSlice)
+		title, _ := mapValue(m, "title").(string)
+		add(orderedID(c), title)
+	}
+	for _, c := range controls {
+		control, _ := c.(yaml.MapSlice)
+		for _, id := range controlApplicability(control) {
+			add(id, "")
+		}
+	}
+	return categories
+}
+
+// matchesApplicability reports whether every word of a context term appears
+// in the id or title of a category, so "amber" and "TLP:Amber" both match
+// the category tlp_amber.
+func matchesApplicability(term string, category applicabilityCategory) bool {
+	tokens := applicabilityTokens(term)
+	if len(tokens) == 0 {
+		return false
+	}
+	for _, t := range tokens {
+		if !slices.Contains(category.tokens, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// applicabilityTokens splits s into lower-case words and numbers.
+func applicabilityTokens(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// controlApplicability returns the sorted categories the assessment
+// requirements of a control apply to.
+func controlApplicability(control yaml.MapSlice) []string {
+	set := map[string]bool{}
+	for _, r := range mapValueList(control, "assessment-requirements") {
+		m, _ := r.(yaml.MapSlice)
+		for _, a := range mapValueList(m, "applicability") {
+			set[fmt.Sprint(a)] = true
+		}
+	}
+	applies := make([]string, 0, len(set))
+	for id := range set {
+		applies = append(applies, id)
+	}
+	sort.Strings(applies)
+	return applies
+}
+
+// applicabilityReason explains why a control applies to none of the scope.
+func applicabilityReason(applies, scope []string, matchedBy map[string][]string) string {
+	if len(applies) == 0 {
+		return "the control has no assessment requirements"
+	}
+	var context []string
+	for _, id := range scope {
+		context = append(context, fmt.Sprintf("%s (%s)", id, strings.Join(matchedBy[id], "; ")))
+	}
+	return fmt.Sprintf("its assessment requirements apply only to %s, and the context matches %s",
+		strings.Join(applies, ", "), strings.Join(context, ", "))
+}
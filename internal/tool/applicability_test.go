@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const contextCatalog = `metadata:
+  id: ORG
+  description: Organization controls
+  author:
+    id: sec
+    name: Security
+    type: Human
+  applicability-categories:
+    - id: k8s
+      title: Kubernetes
+      description: Workloads on Kubernetes.
+    - id: saas
+      title: Software as a Service
+      description: Hosted by a SaaS provider.
+    - id: confidential
+      title: Confidential Data
+      description: Data restricted to the organization.
+title: Organization Controls
+families:
+  - id: platform
+    title: Platform
+    description: Platform.
+  - id: data
+    title: Data
+    description: Data.
+controls:
+  - id: PL.C01
+    family: platform
+    title: Restrict pod privileges
+    objective: Pods run without privileges.
+    assessment-requirements:
+      - id: PL.C01.TR01
+        text: Privileged pods are rejected.
+        applicability: [k8s]
+  - id: PL.C02
+    family: platform
+    title: Review vendor reports
+    objective: Vendor reports are reviewed.
+    assessment-requirements:
+      - id: PL.C02.TR01
+        text: SOC 2 reports are reviewed yearly.
+        applicability: [saas]
+  - id: DA.C01
+    family: data
+    title: Encrypt data
+    objective: Data is encrypted.
+    assessment-requirements:
+      - id: DA.C01.TR01
+        text: Volumes are encrypted.
+        applicability: [k8s, confidential]
+      - id: DA.C01.TR02
+        text: Exports are encrypted.
+        applicability: [saas]
+`
+
+func TestFilterCatalogByApplicability(t *testing.T) {
+	useTestSchema(t)
+	ctx := context.Background()
+
+	_, output, err := FilterCatalogByApplicability(ctx, nil, InputFilterCatalogByApplicability{
+		ArtifactContent:    contextCatalog,
+		TechnologyStack:    []string{"Kubernetes", "PostgreSQL"},
+		DataClassification: "confidential",
+	})
+	require.NoError(t, err)
+	assert.True(t, output.Valid, "filtered catalog should validate: %v", output.Errors)
+	assert.Equal(t, []ApplicabilityMatch{
+		{Category: "k8s", Title: "Kubernetes", Context: "technology_stack: Kubernetes"},
+		{Category: "confidential", Title: "Confidential Data", Context: "data_classification: confidential"},
+	}, output.Matched)
+	assert.Equal(t, []string{"technology_stack: PostgreSQL"}, output.Unmatched)
+	assert.Equal(t, []string{"PL.C01", "DA.C01"}, output.Included)
+	require.Len(t, output.Excluded, 1)
+	assert.Equal(t, "PL.C02", output.Excluded[0].Control)
+	assert.Equal(t, []string{"saas"}, output.Excluded[0].Applicability)
+	assert.Contains(t, output.Excluded[0].Reason, "apply only to saas")
+	assert.Contains(t, output.Excluded[0].Reason, "k8s (technology_stack: Kubernetes)")
+	assert.Equal(t, []string{"DA.C01.TR02"}, output.RemovedRequirements)
+	assert.Contains(t, output.FilteredContent, "id: ORG-filtered")
+	assert.NotContains(t, output.FilteredContent, "PL.C02")
+	assert.Contains(t, output.Message, "match no category: technology_stack: PostgreSQL")
+
+	// Context terms match category titles word by word
+	catalog, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err)
+	_, output, err = FilterCatalogByApplicability(ctx, nil, InputFilterCatalogByApplicability{
+		ArtifactContent:    string(catalog),
+		DataClassification: "TLP:Clear",
+		ID:                 "CCC-PUBLIC",
+	})
+	require.NoError(t, err)
+	assert.True(t, output.Valid, "filtered catalog should validate: %v", output.Errors)
+	assert.Equal(t, "tlp_clear", output.Matched[0].Category)
+	assert.NotContains(t, output.Included, "CCC.C08", "CCC.C08 does not apply to TLP:Clear data")
+	assert.Contains(t, output.FilteredContent, "id: CCC-PUBLIC")
+}
+
+func TestFilterCatalogByApplicabilityErrors(t *testing.T) {
+	useTestSchema(t)
+
+	tests := []struct {
+		name        string
+		input       InputFilterCatalogByApplicability
+		errContains string
+	}{
+		{
+			name:        "no context",
+			input:       InputFilterCatalogByApplicability{ArtifactContent: contextCatalog},
+			errContains: "technology_stack, deployment_model, data_classification, or attributes is required",
+		},
+		{
+			name:        "no matching category",
+			input:       InputFilterCatalogByApplicability{ArtifactContent: contextCatalog, DeploymentModel: "on-premises"},
+			errContains: "the catalog defines: k8s, saas, confidential",
+		},
+		{
+			name:        "not a catalog",
+			input:       InputFilterCatalogByApplicability{ArtifactContent: "metadata:\n  id: X\ncategories: []\n", Attributes: []string{"k8s"}},
+			errContains: "requires a ControlCatalog",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := FilterCatalogByApplicability(context.Background(), nil, tt.input)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxApprovalMessage bounds the approval request shown to the user; longer
+// diffs are cut, and the full changes can be reviewed with dry_run.
+const maxApprovalMessage = 16 * 1024
+
+// eventApprovalDenied is the server event reported when the user does not
+// approve a write tool's changes.
+const eventApprovalDenied = "approval_denied"
+
+// approveChanges asks the user who sent req to approve changes, described by
+// summary, and returns an error unless they do. It does nothing unless
+// the configuration of ctx sets RequireApproval. The tool timeout is paused
+// while the user decides; cancelling the call ends the wait.
+func approveChanges(ctx context.Context, req *mcp.CallToolRequest, summary string, changes []PlannedChange) error {
+	if !serverConfig(ctx).RequireApproval || len(changes) == 0 {
+		return nil
+	}
+	if !elicitationSupported(req) {
+		return fmt.Errorf("the server requires approval of changes, but the client does not support elicitation; call again with dry_run to review them")
+	}
+
+	resume := pauseToolTimeout(ctx)
+	result, err := req.Session.Elicit(ctx, &mcp.ElicitParams{
+		Message: approvalMessage(summary, changes),
+		RequestedSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"approve": map[string]interface{}{
+					"type":        "boolean",
+					"title":       "Approve",
+					"description": "Make these changes",
+				},
+			},
+			// Not required, as clients validate declined answers against
+			// the schema too
+		},
+	})
+	resume()
+	if err != nil {
+		return fmt.Errorf("failed to ask for approval: %w", err)
+	}
+	if approved, _ := result.Content["approve"].(bool); result.Action != "accept" || !approved {
+		emitEvent(ctx, "info", eventApprovalDenied, "changes were not approved", map[string]interface{}{
+			"summary": summary,
+			"action":  result.Action,
+		})
+		return fmt.Errorf("changes were not approved (%s); nothing was changed", result.Action)
+	}
+	return nil
+}
+
+// approvalMessage describes changes for the user to approve.
+func approvalMessage(summary string, changes []PlannedChange) string {
+	var b strings.Builder
+	b.WriteString(summary + "\n")
+	for _, c := range changes {
+		fmt.Fprintf(&b, "\n%s %s", c.Action, c.Target)
+		if c.Detail != "" {
+			b.WriteString(": " + c.Detail)
+		}
+		b.WriteString("\n")
+		b.WriteString(c.Diff)
+	}
+	b.WriteString("\nApprove these changes?")
+	message := b.String()
+	if len(message) > maxApprovalMessage {
+		message = message[:maxApprovalMessage] + "\n... (truncated; review the full changes with dry_run)\n\nApprove these changes?"
+	}
+	return message
+}
@@ -0,0 +1,158 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// callSign calls sign_gemara_artifact, on a server with config, through a
+// client session whose elicitation requests are answered by elicit, or that
+// does not support elicitation when elicit is nil.
+func callSign(t *testing.T, config *Config, input InputSignGemaraArtifact, elicit func(*mcp.ElicitRequest) *mcp.ElicitResult) (OutputSignGemaraArtifact, string) {
+	t.Helper()
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server"}, nil)
+	server.AddReceivingMiddleware(configMiddleware(config))
+	mcp.AddTool(server, MetadataSignGemaraArtifact, timeoutHandler(MetadataSignGemaraArtifact.Name, SignGemaraArtifact))
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err, "server should connect")
+
+	options := &mcp.ClientOptions{}
+	if elicit != nil {
+		options.ElicitationHandler = func(_ context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			return elicit(req), nil
+		}
+	}
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, options).Connect(ctx, clientTransport, nil)
+	require.NoError(t, err, "client should connect")
+	t.Cleanup(func() { _ = session.Close() })
+
+	arguments, err := json.Marshal(input)
+	require.NoError(t, err, "should marshal input")
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: MetadataSignGemaraArtifact.Name, Arguments: json.RawMessage(arguments)})
+	require.NoError(t, err, "should call the tool")
+	if result.IsError {
+		return OutputSignGemaraArtifact{}, result.Content[0].(*mcp.TextContent).Text
+	}
+
+	var output OutputSignGemaraArtifact
+	structured, err := json.Marshal(result.StructuredContent)
+	require.NoError(t, err, "should marshal structured content")
+	require.NoError(t, json.Unmarshal(structured, &output), "should decode output")
+	return output, ""
+}
+
+func TestApproveChanges(t *testing.T) {
+	answer := func(action string, approve bool) func(*mcp.ElicitRequest) *mcp.ElicitResult {
+		return func(*mcp.ElicitRequest) *mcp.ElicitResult {
+			return &mcp.ElicitResult{Action: action, Content: map[string]any{"approve": approve}}
+		}
+	}
+
+	tests := []struct {
+		name        string
+		input       InputSignGemaraArtifact
+		elicit      func(*mcp.ElicitRequest) *mcp.ElicitResult
+		toolTimeout time.Duration
+		wantElicit  bool
+		wantSigned  bool
+		errContains string
+	}{
+		{
+			name:       "approved",
+			input:      InputSignGemaraArtifact{ArtifactContent: "title: Signed\n"},
+			elicit:     answer("accept", true),
+			wantElicit: true,
+			wantSigned: true,
+		},
+		{
+			name:  "approval outlasts the tool timeout",
+			input: InputSignGemaraArtifact{ArtifactContent: "title: Signed\n"},
+			elicit: func(req *mcp.ElicitRequest) *mcp.ElicitResult {
+				time.Sleep(300 * time.Millisecond)
+				return answer("accept", true)(req)
+			},
+			toolTimeout: 100 * time.Millisecond,
+			wantElicit:  true,
+			wantSigned:  true,
+		},
+		{
+			name:        "declined",
+			input:       InputSignGemaraArtifact{ArtifactContent: "title: Signed\n"},
+			elicit:      answer("decline", false),
+			wantElicit:  true,
+			errContains: "changes were not approved (decline)",
+		},
+		{
+			name:        "accepted without approving",
+			input:       InputSignGemaraArtifact{ArtifactContent: "title: Signed\n"},
+			elicit:      answer("accept", false),
+			wantElicit:  true,
+			errContains: "changes were not approved (accept)",
+		},
+		{
+			name:        "client without elicitation",
+			input:       InputSignGemaraArtifact{ArtifactContent: "title: Signed\n"},
+			errContains: "client does not support elicitation",
+		},
+		{
+			name:   "dry run is not approved",
+			input:  InputSignGemaraArtifact{ArtifactContent: "title: Signed\n", DryRun: true},
+			elicit: answer("decline", false),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := NewConfig()
+			config.Cosign = fakeCosign(t, CosignConfig{})
+			config.RequireApproval = true
+			if tt.toolTimeout != 0 {
+				config.ToolTimeout = tt.toolTimeout
+			}
+			var message string
+			elicit := tt.elicit
+			if elicit != nil {
+				elicit = func(req *mcp.ElicitRequest) *mcp.ElicitResult {
+					message = req.Params.Message
+					return tt.elicit(req)
+				}
+			}
+
+			output, errText := callSign(t, config, tt.input, elicit)
+			if tt.wantElicit {
+				assert.Contains(t, message, "sign "+contentDigest([]byte(tt.input.ArtifactContent)))
+				assert.Contains(t, message, "transparency log", "keyless signing should be called out")
+			} else {
+				assert.Empty(t, message, "the user should not be asked")
+			}
+			if tt.errContains != "" {
+				assert.Contains(t, errText, tt.errContains)
+				return
+			}
+			require.Empty(t, errText)
+			assert.Equal(t, tt.wantSigned, output.Bundle != "")
+		})
+	}
+}
+
+func TestApprovalMessage(t *testing.T) {
+	message := approvalMessage("create_findings_issues will update 1 issue(s).", []PlannedChange{
+		{Action: "update", Target: "#7", Detail: "[Failed] FINOS-CCC CCC.C06", Diff: "-old\n+new\n"},
+	})
+	assert.Equal(t, "create_findings_issues will update 1 issue(s).\n\nupdate #7: [Failed] FINOS-CCC CCC.C06\n-old\n+new\n\nApprove these changes?", message)
+
+	long := approvalMessage("summary", []PlannedChange{{Action: "create", Target: "issue", Diff: lineDiff("", string(make([]byte, 2*maxApprovalMessage)))}})
+	assert.Contains(t, long, "truncated")
+	assert.Less(t, len(long), maxApprovalMessage+200)
+}
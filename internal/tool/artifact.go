@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// ArtifactInput is a named Gemara artifact passed inline to a tool.
+type ArtifactInput struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// artifactInputSchema is the JSON schema for a list of ArtifactInput values.
+var artifactInputSchema = map[string]interface{}{
+	"type":        "array",
+	"description": "Gemara artifacts to analyze, each with a name and YAML content",
+	"items": map[string]interface{}{
+		"type":     "object",
+		"required": []string{"content"},
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{
+				"type":        "string",
+				"description": "Name used to identify the artifact in results (e.g., its file name)",
+			},
+			"content": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML content of the Gemara artifact",
+			},
+		},
+	},
+}
+
+// parseArtifact decodes YAML artifact content into a generic document tree,
+// rejecting content over the artifact limits of the configuration of ctx
+// before it is decoded.
+func parseArtifact(ctx context.Context, content string) (map[string]interface{}, error) {
+	if err := checkArtifactLimits(ctx, formatYAML, content); err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("artifact is empty")
+	}
+	return doc, nil
+}
+
+// artifactKind guesses the Gemara definition of a parsed artifact from its
+// top-level fields. It returns an empty string when the shape is unknown.
+func artifactKind(doc map[string]interface{}) string {
+	switch {
+	case doc["controls"] != nil:
+		return "ControlCatalog"
+	case doc["evaluations"] != nil:
+		return "EvaluationLog"
+	case doc["plans"] != nil:
+		return "EvaluationPlan"
+	case doc["adherence"] != nil || doc["imports"] != nil:
+		return "Policy"
+	case doc["guidelines"] != nil || doc["categories"] != nil:
+		return "GuidanceDocument"
+	case doc["threats"] != nil:
+		return "ThreatCatalog"
+	}
+	return ""
+}
+
+// walkArtifact calls fn for every node in the document tree. Paths use the
+// YAML path syntax understood by goccy/go-yaml (e.g., $.controls[0].id).
+func walkArtifact(node interface{}, path string, fn func(path string, value interface{})) {
+	fn(path, node)
+	switch v := node.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			walkArtifact(v[k], childPath(path, k), fn)
+		}
+	case []interface{}:
+		for i, item := range v {
+			walkArtifact(item, fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	}
+}
+
+// childPath appends a mapping key to a YAML path, quoting it when needed.
+func childPath(path, key string) string {
+	if strings.ContainsAny(key, ".[]' ") {
+		return fmt.Sprintf("%s.'%s'", path, key)
+	}
+	return path + "." + key
+}
+
+// pathSegment is one step of a YAML path: a mapping key or a sequence index.
+type pathSegment struct {
+	Key     string
+	Index   int
+	IsIndex bool
+}
+
+// parseArtifactPath splits a YAML path such as $.controls[0].'guideline-mappings'
+// into its segments. The leading $ is optional.
+func parseArtifactPath(path string) ([]pathSegment, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	var segments []pathSegment
+	for rest != "" {
+		switch {
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: unclosed [", path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid path %q: bad index %q", path, rest[1:end])
+			}
+			segments = append(segments, pathSegment{Index: index, IsIndex: true})
+			rest = rest[end+1:]
+		case rest[0] == '.' || len(segments) == 0:
+			rest = strings.TrimPrefix(rest, ".")
+			var key string
+			if strings.HasPrefix(rest, "'") {
+				end := strings.IndexByte(rest[1:], '\'')
+				if end < 0 {
+					return nil, fmt.Errorf("invalid path %q: unclosed quote", path)
+				}
+				key, rest = rest[1:end+1], rest[end+2:]
+			} else {
+				end := strings.IndexAny(rest, ".[")
+				if end < 0 {
+					end = len(rest)
+				}
+				key, rest = rest[:end], rest[end:]
+			}
+			if key == "" {
+				return nil, fmt.Errorf("invalid path %q: empty key", path)
+			}
+			segments = append(segments, pathSegment{Key: key})
+		default:
+			return nil, fmt.Errorf("invalid path %q: unexpected %q", path, rest[:1])
+		}
+	}
+	return segments, nil
+}
+
+// lastPathKey returns the final mapping key of a YAML path, if any.
+func lastPathKey(path string) string {
+	path = strings.TrimRight(path, "]0123456789[")
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return strings.Trim(path[i+1:], "'")
+	}
+	return ""
+}
+
+// artifactName returns the display name of the i-th inline artifact.
+func artifactName(a ArtifactInput, i int) string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return fmt.Sprintf("artifact[%d]", i)
+}
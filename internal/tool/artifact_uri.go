@@ -0,0 +1,191 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultMaxArtifactSize is the default limit on artifacts read by URI.
+const DefaultMaxArtifactSize int64 = 10 << 20
+
+// artifactHTTPClient fetches https:// artifact URIs.
+var artifactHTTPClient = newHTTPClient()
+
+// artifactMediaTypes are the response content types accepted for https:// artifacts.
+var artifactMediaTypes = map[string]bool{
+	"application/yaml":   true,
+	"application/x-yaml": true,
+	"text/yaml":          true,
+	"text/x-yaml":        true,
+	"application/json":   true,
+	"text/plain":         true,
+}
+
+// readArtifactURI reads artifact content from a file://, https://, or gemara:// URI.
+func readArtifactURI(ctx context.Context, rawURI string) ([]byte, error) {
+	u, err := url.Parse(rawURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifact_uri %q: %w", rawURI, err)
+	}
+
+	switch u.Scheme {
+	case "file":
+		return readWorkspaceFile(ctx, u.Path)
+	case "https":
+		return fetchArtifact(ctx, u.String())
+	case "gemara":
+		return readGemaraArtifact(ctx, rawURI)
+	default:
+		return nil, fmt.Errorf("unsupported artifact_uri scheme %q: must be file, https, or gemara", u.Scheme)
+	}
+}
+
+// readWorkspaceFile reads a file that must resolve, after following symlinks, within the workspace root.
+func readWorkspaceFile(ctx context.Context, path string) ([]byte, error) {
+	resolved, err := resolveWorkspacePath(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+	if limit := serverConfig(ctx).MaxArtifactSize; info.Size() > limit {
+		return nil, fmt.Errorf("%s is %d bytes, exceeding the %d byte limit", path, info.Size(), limit)
+	}
+
+	return readArtifactFile(ctx, resolved)
+}
+
+// resolveWorkspacePath returns the absolute path of a file after following
+// symlinks, rejecting files outside the workspace root of ctx.
+func resolveWorkspacePath(ctx context.Context, path string) (string, error) {
+	root := workspaceRoot(ctx)
+	if root == "" {
+		return "", fmt.Errorf("file artifact URIs are disabled: no workspace root is configured")
+	}
+	return resolveWithin(root, path)
+}
+
+// resolveWithin returns the absolute path of a file or directory after
+// following symlinks, rejecting paths outside root.
+func resolveWithin(root, path string) (string, error) {
+	root, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", fmt.Errorf("invalid workspace root: %w", err)
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("invalid workspace root: %w", err)
+	}
+
+	resolved, err := filepath.EvalSymlinks(filepath.FromSlash(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	resolved, err = filepath.Abs(resolved)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the workspace root", path)
+	}
+	return resolved, nil
+}
+
+// fetchArtifact downloads an https:// artifact, enforcing the size limit and content type.
+func fetchArtifact(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/yaml, text/yaml, application/json, text/plain")
+
+	resp, err := artifactHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	if err := checkArtifactContentType(resp.Header.Get("Content-Type")); err != nil {
+		return nil, err
+	}
+	limit := serverConfig(ctx).MaxArtifactSize
+	if resp.ContentLength > limit {
+		return nil, fmt.Errorf("%s is %d bytes, exceeding the %d byte limit", rawURL, resp.ContentLength, limit)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%s exceeds the %d byte limit", rawURL, limit)
+	}
+	return body, nil
+}
+
+// checkArtifactContentType rejects responses that are not YAML, JSON, or plain text.
+// A missing content type is accepted.
+func checkArtifactContentType(contentType string) error {
+	if contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("invalid content type %q: %w", contentType, err)
+	}
+	if !artifactMediaTypes[mediaType] {
+		return fmt.Errorf("unsupported content type %q: expected YAML, JSON, or plain text", mediaType)
+	}
+	return nil
+}
+
+// readGemaraArtifact reads an artifact served by this server, such as a bundled example.
+func readGemaraArtifact(ctx context.Context, uri string) ([]byte, error) {
+	if strings.HasPrefix(uri, catalogResourcePrefix) {
+		return readBundleCatalog(uri)
+	}
+	if strings.HasPrefix(uri, federatedResourcePrefix) {
+		return readFederatedCatalog(ctx, uri)
+	}
+	if strings.HasPrefix(uri, stagedResourcePrefix) {
+		return readStagedArtifact(ctx, uri)
+	}
+	if !strings.HasPrefix(uri, examplesResourcePrefix) {
+		return nil, fmt.Errorf("unsupported gemara URI %q: only %s resources are artifacts", uri, examplesResourceURITemplate)
+	}
+
+	definition, n, err := parseExampleURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	files, err := exampleFiles(definition)
+	if err != nil || n < 1 || n > len(files) {
+		return nil, fmt.Errorf("example %s not found", uri)
+	}
+
+	content, err := examplesFS.ReadFile(files[n-1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read example: %w", err)
+	}
+	return content, nil
+}
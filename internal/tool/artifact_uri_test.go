@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadArtifactURI(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	writeTestFile(t, root, "catalog.yaml", "title: Catalog\n")
+	writeTestFile(t, root, "large.yaml", strings.Repeat("a", 64))
+	writeTestFile(t, outside, "secret.yaml", "title: Secret\n")
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.yaml"), filepath.Join(root, "link.yaml")), "should create symlink")
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/catalog.yaml":
+			w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+			_, _ = w.Write([]byte("title: Remote\n"))
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte("<html></html>"))
+		case "/large.yaml":
+			w.Header().Set("Content-Type", "text/plain")
+			_, _ = w.Write([]byte(strings.Repeat("a", 64)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	originalClient := artifactHTTPClient
+	t.Cleanup(func() { artifactHTTPClient = originalClient })
+	artifactHTTPClient = server.Client()
+	ctx := withTestConfig(func(c *Config) {
+		c.WorkspaceRoot = root
+		c.MaxArtifactSize = 32
+	})
+
+	tests := []struct {
+		name        string
+		uri         string
+		want        string
+		errContains string
+	}{
+		{name: "file within root", uri: "file://" + filepath.Join(root, "catalog.yaml"), want: "title: Catalog\n"},
+		{name: "file outside root", uri: "file://" + filepath.Join(outside, "secret.yaml"), errContains: "outside the workspace root"},
+		{name: "relative escape", uri: "file://" + root + "/../" + filepath.Base(outside) + "/secret.yaml", errContains: "outside the workspace root"},
+		{name: "symlink escape", uri: "file://" + filepath.Join(root, "link.yaml"), errContains: "outside the workspace root"},
+		{name: "file too large", uri: "file://" + filepath.Join(root, "large.yaml"), errContains: "byte limit"},
+		{name: "missing file", uri: "file://" + filepath.Join(root, "missing.yaml"), errContains: "failed to read"},
+		{name: "https", uri: server.URL + "/catalog.yaml", want: "title: Remote\n"},
+		{name: "https wrong content type", uri: server.URL + "/page.html", errContains: "unsupported content type"},
+		{name: "https too large", uri: server.URL + "/large.yaml", errContains: "byte limit"},
+		{name: "https not found", uri: server.URL + "/missing.yaml", errContains: "unexpected status code"},
+		{name: "http rejected", uri: "http://example.com/catalog.yaml", errContains: "unsupported artifact_uri scheme"},
+		{name: "gemara example", uri: "gemara://examples/ControlCatalog/1", want: readExample(t, "ControlCatalog", 1)},
+		{name: "gemara example missing", uri: "gemara://examples/ControlCatalog/99", errContains: "not found"},
+		{name: "gemara non-artifact", uri: "gemara://lexicon", errContains: "unsupported gemara URI"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := readArtifactURI(ctx, tt.uri)
+			if tt.errContains != "" {
+				require.Error(t, err, "should return error")
+				assert.Contains(t, err.Error(), tt.errContains, "error should contain expected message")
+				return
+			}
+			require.NoError(t, err, "should not return error")
+			assert.Equal(t, tt.want, string(content), "content should match")
+		})
+	}
+}
+
+func TestReadArtifactURIWithoutWorkspaceRoot(t *testing.T) {
+	_, err := readArtifactURI(context.Background(), "file:///etc/hostname")
+	assert.ErrorContains(t, err, "file artifact URIs are disabled")
+}
+
+func TestValidateGemaraArtifactInputSource(t *testing.T) {
+	_, _, err := ValidateGemaraArtifact(context.Background(), nil, InputValidateGemaraArtifact{
+		ArtifactContent: "title: Inline\n",
+		ArtifactURI:     "gemara://examples/ControlCatalog/1",
+		Definition:      "#ControlCatalog",
+	})
+	assert.ErrorContains(t, err, "mutually exclusive")
+}
@@ -0,0 +1,251 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	inTotoStatementType = "https://in-toto.io/Statement/v1"
+	// inTotoPayloadType is the DSSE payload type of an in-toto statement.
+	inTotoPayloadType = "application/vnd.in-toto+json"
+	// evaluationLogPredicateType identifies statements whose predicate is a
+	// Gemara #EvaluationLog.
+	evaluationLogPredicateType = "https://gemara.openssf.org/attestation/evaluation-log/v1"
+)
+
+// MetadataWrapAsAttestation describes the WrapAsAttestation tool.
+var MetadataWrapAsAttestation = &mcp.Tool{
+	Name: "wrap_as_attestation",
+	Description: "Package an EvaluationLog as an in-toto v1 statement whose predicate is the log, with predicate type " +
+		evaluationLogPredicateType + ", so evaluation results can flow through SLSA-style attestation pipelines. " +
+		"The subjects are the artifacts that were evaluated, by name and digest; without them the log attests itself. " +
+		"With sign, the statement is also signed with cosign, keyless through Sigstore or with the server's configured " +
+		"key, and returned as a DSSE envelope with its Sigstore bundle. The log must be valid against the schema.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": func() map[string]interface{} {
+			properties := artifactSourceProperties("wrap")
+			properties["subjects"] = map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type":     "object",
+					"required": []string{"name", "digest"},
+					"properties": map[string]interface{}{
+						"name": map[string]interface{}{
+							"type":        "string",
+							"description": "Name of the evaluated artifact, such as an image reference or file name",
+						},
+						"digest": map[string]interface{}{
+							"type":        "string",
+							"description": "Digest of the artifact as <algorithm>:<hex> (e.g., sha256:...)",
+						},
+					},
+				},
+				"description": "Artifacts the evaluation applies to (default: the evaluation log itself)",
+			}
+			properties["sign"] = map[string]interface{}{
+				"type":        "boolean",
+				"description": "Sign the statement with cosign and return a DSSE envelope",
+			}
+			properties["dry_run"] = dryRunProperty
+			return properties
+		}(),
+	},
+}
+
+// InputWrapAsAttestation is the input for the WrapAsAttestation tool.
+type InputWrapAsAttestation struct {
+	ArtifactContent string               `json:"artifact_content,omitempty"`
+	ArtifactURI     string               `json:"artifact_uri,omitempty"`
+	Subjects        []AttestationSubject `json:"subjects,omitempty"`
+	Sign            bool                 `json:"sign,omitempty"`
+	DryRun          bool                 `json:"dry_run,omitempty"`
+}
+
+// AttestationSubject is an artifact an attestation is about.
+type AttestationSubject struct {
+	Name   string `json:"name"`
+	Digest string `json:"digest"`
+}
+
+// OutputWrapAsAttestation is the output for the WrapAsAttestation tool.
+type OutputWrapAsAttestation struct {
+	// Statement is the in-toto statement JSON.
+	Statement     string               `json:"statement"`
+	PredicateType string               `json:"predicate_type"`
+	Subjects      []AttestationSubject `json:"subjects"`
+	// Envelope is the DSSE envelope of the signed statement.
+	Envelope string `json:"envelope,omitempty"`
+	// Bundle is the Sigstore bundle of the envelope signature, with the
+	// signing certificate and transparency log entry.
+	Bundle string `json:"bundle,omitempty"`
+	Mode   string `json:"mode,omitempty"`
+	DryRun bool   `json:"dry_run,omitempty"`
+	// Changes lists the changes made, or in a dry run, those that would be.
+	Changes []PlannedChange `json:"changes,omitempty"`
+	Message string          `json:"message"`
+}
+
+// inTotoStatement is an in-toto v1 statement.
+type inTotoStatement struct {
+	Type          string                 `json:"_type"`
+	Subject       []inTotoSubject        `json:"subject"`
+	PredicateType string                 `json:"predicateType"`
+	Predicate     map[string]interface{} `json:"predicate"`
+}
+
+type inTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// dsseEnvelope is a DSSE envelope with base64 payload and signatures.
+type dsseEnvelope struct {
+	PayloadType string          `json:"payloadType"`
+	Payload     string          `json:"payload"`
+	Signatures  []dsseSignature `json:"signatures"`
+}
+
+type dsseSignature struct {
+	Sig string `json:"sig"`
+}
+
+// WrapAsAttestation packages an evaluation log as an in-toto statement, signed on request.
+func WrapAsAttestation(ctx context.Context, req *mcp.CallToolRequest, input InputWrapAsAttestation) (*mcp.CallToolResult, OutputWrapAsAttestation, error) {
+	content, err := artifactSource(ctx, input.ArtifactContent, input.ArtifactURI)
+	if err != nil {
+		return nil, OutputWrapAsAttestation{}, err
+	}
+	doc, err := parseArtifact(ctx, string(content))
+	if err != nil {
+		return nil, OutputWrapAsAttestation{}, err
+	}
+	if kind := artifactKind(doc); kind != "EvaluationLog" {
+		if kind == "" {
+			kind = "unknown"
+		}
+		return nil, OutputWrapAsAttestation{}, fmt.Errorf("expected an EvaluationLog, got %s", kind)
+	}
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputWrapAsAttestation{}, err
+	}
+	validation, err := validateAgainstSchema(schema, "#EvaluationLog", string(content))
+	if err != nil {
+		return nil, OutputWrapAsAttestation{}, err
+	}
+	if !validation.Valid {
+		return nil, OutputWrapAsAttestation{}, fmt.Errorf("only valid evaluation logs can be attested: %s", strings.Join(validation.Errors, "; "))
+	}
+
+	subjects := input.Subjects
+	if len(subjects) == 0 {
+		subjects = []AttestationSubject{{Name: metadataID(doc), Digest: contentDigest(content)}}
+	}
+	statement := inTotoStatement{Type: inTotoStatementType, PredicateType: evaluationLogPredicateType, Predicate: doc}
+	for i, s := range subjects {
+		algorithm, value, ok := strings.Cut(s.Digest, ":")
+		if _, err := hex.DecodeString(value); s.Name == "" || !ok || algorithm == "" || value == "" || err != nil {
+			return nil, OutputWrapAsAttestation{}, fmt.Errorf("subjects[%d]: name and a digest of the form <algorithm>:<hex> are required", i)
+		}
+		statement.Subject = append(statement.Subject, inTotoSubject{Name: s.Name, Digest: map[string]string{algorithm: strings.ToLower(value)}})
+	}
+	payload, err := json.MarshalIndent(statement, "", "  ")
+	if err != nil {
+		return nil, OutputWrapAsAttestation{}, fmt.Errorf("failed to encode statement: %w", err)
+	}
+
+	output := OutputWrapAsAttestation{Statement: string(payload), PredicateType: evaluationLogPredicateType, Subjects: subjects}
+	output.Message = fmt.Sprintf("Wrapped evaluation log %s as an in-toto statement about %d subject(s)", metadataID(doc), len(subjects))
+	if !input.Sign {
+		return nil, output, nil
+	}
+
+	output.Mode = signingModeKeyless
+	if serverConfig(ctx).Cosign.Key != "" {
+		output.Mode = signingModeKey
+	}
+	output.Changes = []PlannedChange{{Action: "sign", Target: contentDigest(payload), Detail: output.Mode}}
+	if dryRun(ctx, input.DryRun) {
+		output.DryRun = true
+		output.Message += fmt.Sprintf("; dry run: would sign it (%s)", output.Mode)
+		return nil, output, nil
+	}
+	summary := "wrap_as_attestation will sign the statement with cosign."
+	if output.Mode == signingModeKeyless {
+		summary += " Keyless signing publishes the signer's identity in the public Sigstore transparency log."
+	}
+	if err := approveChanges(ctx, req, summary, output.Changes); err != nil {
+		return nil, OutputWrapAsAttestation{}, err
+	}
+
+	envelope, bundle, err := signStatement(ctx, payload)
+	if err != nil {
+		return nil, OutputWrapAsAttestation{}, err
+	}
+	output.Envelope = envelope
+	output.Bundle = bundle
+	output.Message += fmt.Sprintf(" and signed it (%s); publish the envelope with the bundle", output.Mode)
+	return nil, output, nil
+}
+
+// signStatement signs the DSSE pre-authentication encoding of a statement
+// with cosign and returns the envelope and the Sigstore bundle.
+func signStatement(ctx context.Context, payload []byte) (string, string, error) {
+	dir, err := os.MkdirTemp("", "gemara-attest-*")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to stage statement for signing: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	pae := filepath.Join(dir, "statement.pae")
+	signature := filepath.Join(dir, "statement.sig")
+	bundle := filepath.Join(dir, "statement.sigstore.json")
+	if err := os.WriteFile(pae, dssePAE(inTotoPayloadType, payload), 0o600); err != nil {
+		return "", "", fmt.Errorf("failed to stage statement for signing: %w", err)
+	}
+
+	args := []string{"sign-blob", "--yes", "--output-signature", signature, "--bundle", bundle}
+	cosign := serverConfig(ctx).Cosign
+	if cosign.Key != "" {
+		args = append(args, "--key", cosign.Key)
+	}
+	if _, err := cosign.run(ctx, append(args, "--", pae)...); err != nil {
+		return "", "", fmt.Errorf("failed to sign statement: %w", err)
+	}
+	sig, err := os.ReadFile(signature)
+	if err != nil {
+		return "", "", fmt.Errorf("cosign did not write a signature: %w", err)
+	}
+	signed, err := os.ReadFile(bundle)
+	if err != nil {
+		return "", "", fmt.Errorf("cosign did not write a bundle: %w", err)
+	}
+
+	envelope, err := json.MarshalIndent(dsseEnvelope{
+		PayloadType: inTotoPayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		// cosign writes the signature base64-encoded
+		Signatures: []dsseSignature{{Sig: strings.TrimSpace(string(sig))}},
+	}, "", "  ")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode envelope: %w", err)
+	}
+	return string(envelope), string(signed), nil
+}
+
+// dssePAE returns the DSSE pre-authentication encoding that is signed in
+// place of the payload.
+func dssePAE(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapAsAttestation(t *testing.T) {
+	useTestSchema(t)
+	log, err := os.ReadFile(filepath.Join("test-data", "evaluation-log.yaml"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		config       CosignConfig
+		input        InputWrapAsAttestation
+		wantErr      string
+		wantSubjects map[string]map[string]string
+		wantMode     string
+		wantArgs     string
+	}{
+		{
+			name:         "log attests itself",
+			input:        InputWrapAsAttestation{ArtifactContent: string(log)},
+			wantSubjects: map[string]map[string]string{"EVAL-2025-01": {"sha256": contentDigest(log)[len("sha256:"):]}},
+		},
+		{
+			name: "evaluated subjects",
+			input: InputWrapAsAttestation{ArtifactContent: string(log), Subjects: []AttestationSubject{
+				{Name: "ghcr.io/acme/storage", Digest: "sha256:AB12"},
+			}},
+			wantSubjects: map[string]map[string]string{"ghcr.io/acme/storage": {"sha256": "ab12"}},
+		},
+		{
+			name:         "keyless signing",
+			input:        InputWrapAsAttestation{ArtifactContent: string(log), Sign: true},
+			wantSubjects: map[string]map[string]string{"EVAL-2025-01": {"sha256": contentDigest(log)[len("sha256:"):]}},
+			wantMode:     signingModeKeyless,
+			wantArgs:     "--output-signature",
+		},
+		{
+			name:         "key signing dry run",
+			config:       CosignConfig{Key: "cosign.key"},
+			input:        InputWrapAsAttestation{ArtifactContent: string(log), Sign: true, DryRun: true},
+			wantSubjects: map[string]map[string]string{"EVAL-2025-01": {"sha256": contentDigest(log)[len("sha256:"):]}},
+			wantMode:     signingModeKey,
+		},
+		{
+			name: "malformed digest",
+			input: InputWrapAsAttestation{ArtifactContent: string(log), Subjects: []AttestationSubject{
+				{Name: "image", Digest: "latest"},
+			}},
+			wantErr: "subjects[0]",
+		},
+		{
+			name:    "invalid log",
+			input:   InputWrapAsAttestation{ArtifactContent: "metadata:\n  id: X\nevaluations:\n  - name: missing fields\n"},
+			wantErr: "only valid evaluation logs can be attested",
+		},
+		{
+			name:    "not an evaluation log",
+			input:   InputWrapAsAttestation{ArtifactContent: baselineCatalog},
+			wantErr: "expected an EvaluationLog",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := withTestConfig(func(c *Config) { c.Cosign = fakeCosign(t, tt.config) })
+			_, output, err := WrapAsAttestation(ctx, nil, tt.input)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var statement inTotoStatement
+			require.NoError(t, json.Unmarshal([]byte(output.Statement), &statement))
+			assert.Equal(t, inTotoStatementType, statement.Type)
+			assert.Equal(t, evaluationLogPredicateType, statement.PredicateType)
+			assert.Equal(t, "EVAL-2025-01", metadataID(statement.Predicate), "the predicate should be the log")
+			subjects := map[string]map[string]string{}
+			for _, s := range statement.Subject {
+				subjects[s.Name] = s.Digest
+			}
+			assert.Equal(t, tt.wantSubjects, subjects)
+
+			assert.Equal(t, tt.wantMode, output.Mode)
+			if tt.wantArgs == "" {
+				assert.Empty(t, output.Envelope, "should not sign")
+				return
+			}
+			assert.Contains(t, output.Bundle, tt.wantArgs)
+			var envelope dsseEnvelope
+			require.NoError(t, json.Unmarshal([]byte(output.Envelope), &envelope))
+			assert.Equal(t, inTotoPayloadType, envelope.PayloadType)
+			payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+			require.NoError(t, err)
+			assert.Equal(t, output.Statement, string(payload))
+			require.Len(t, envelope.Signatures, 1)
+			assert.Equal(t, "c2lnbmF0dXJl", envelope.Signatures[0].Sig)
+		})
+	}
+}
+
+func TestDSSEPAE(t *testing.T) {
+	// Test vector from the DSSE specification
+	assert.Equal(t, "DSSEv1 29 http://example.com/HelloWorld 11 hello world",
+		string(dssePAE("http://example.com/HelloWorld", []byte("hello world"))))
+}
@@ -0,0 +1,425 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Kinds of baseline findings.
+const (
+	BaselineMissing  = "missing"
+	BaselineWeakened = "weakened"
+	BaselineChanged  = "changed"
+	BaselineExtra    = "extra"
+)
+
+// frequencyOrder ranks assessment frequencies from most to least frequent.
+var frequencyOrder = []string{"continuous", "hourly", "daily", "weekly", "biweekly", "monthly", "quarterly", "semiannually", "annually"}
+
+// frequencyAliases maps other spellings onto frequencyOrder.
+var frequencyAliases = map[string]string{
+	"continuously": "continuous", "semi-annually": "semiannually", "yearly": "annually",
+	"bi-weekly": "biweekly", "fortnightly": "biweekly",
+}
+
+// requirementParameter matches a number with a unit in requirement text, such
+// as "90 days" or "12-character".
+var requirementParameter = regexp.MustCompile(`(?i)\b(\d+(?:\.\d+)?)[\s-]*(seconds?|minutes?|hours?|days?|weeks?|months?|years?|attempts?|characters?|bits?|bytes?|rounds?)\b`)
+
+// parameterWeakerWhenHigher lists the units for which a higher value is a
+// weaker requirement, such as a longer rotation period; for the other units,
+// such as key bits, a lower value is weaker.
+var parameterWeakerWhenHigher = map[string]bool{
+	"second": true, "minute": true, "hour": true, "day": true, "week": true, "month": true, "year": true, "attempt": true,
+}
+
+// MetadataCompareToBaseline describes the CompareToBaseline tool.
+var MetadataCompareToBaseline = &mcp.Tool{
+	Name: "compare_to_baseline",
+	Description: "Check a project's ControlCatalog or Policy against an org-wide baseline ControlCatalog or Policy and " +
+		"report what falls short of it: missing controls, requirements, imports, or assessment plans; weakened " +
+		"parameters such as longer periods, fewer key bits, narrower applicability, less frequent or manual-only " +
+		"assessment; and extra controls the baseline does not have. A Policy compared to a catalog baseline must plan " +
+		"an assessment for every baseline requirement.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"project_content": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML content of the project's ControlCatalog or Policy",
+			},
+			"project_uri": map[string]interface{}{
+				"type":        "string",
+				"description": "URI of the project's artifact instead of inline content: file:// (within the workspace root) or https://",
+			},
+			"baseline_content": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML content of the baseline ControlCatalog or Policy",
+			},
+			"baseline_uri": map[string]interface{}{
+				"type":        "string",
+				"description": "URI of the baseline instead of inline content: file:// (within the workspace root) or https://",
+			},
+		},
+	},
+}
+
+// InputCompareToBaseline is the input for the CompareToBaseline tool.
+type InputCompareToBaseline struct {
+	ProjectContent  string `json:"project_content,omitempty"`
+	ProjectURI      string `json:"project_uri,omitempty"`
+	BaselineContent string `json:"baseline_content,omitempty"`
+	BaselineURI     string `json:"baseline_uri,omitempty"`
+}
+
+// BaselineFinding is a difference between a project artifact and its baseline.
+type BaselineFinding struct {
+	Kind string `json:"kind"`
+	// Entity is control, requirement, import, or assessment-plan.
+	Entity   string `json:"entity"`
+	ID       string `json:"id"`
+	Field    string `json:"field,omitempty"`
+	Baseline string `json:"baseline,omitempty"`
+	Project  string `json:"project,omitempty"`
+	Message  string `json:"message"`
+}
+
+// OutputCompareToBaseline is the output for the CompareToBaseline tool.
+type OutputCompareToBaseline struct {
+	ProjectKind  string            `json:"project_kind"`
+	BaselineKind string            `json:"baseline_kind"`
+	Findings     []BaselineFinding `json:"findings"`
+	Missing      int               `json:"missing"`
+	Weakened     int               `json:"weakened"`
+	Changed      int               `json:"changed"`
+	Extra        int               `json:"extra"`
+	// MeetsBaseline is set when nothing is missing or weakened.
+	MeetsBaseline bool   `json:"meets_baseline"`
+	Message       string `json:"message"`
+}
+
+// CompareToBaseline reports how a project artifact falls short of a baseline.
+func CompareToBaseline(ctx context.Context, _ *mcp.CallToolRequest, input InputCompareToBaseline) (*mcp.CallToolResult, OutputCompareToBaseline, error) {
+	projectContent, err := artifactInputContent(ctx, input.ProjectContent, input.ProjectURI)
+	if err != nil {
+		return nil, OutputCompareToBaseline{}, fmt.Errorf("project: %w", err)
+	}
+	baselineContent, err := artifactInputContent(ctx, input.BaselineContent, input.BaselineURI)
+	if err != nil {
+		return nil, OutputCompareToBaseline{}, fmt.Errorf("baseline: %w", err)
+	}
+	project, err := parseArtifact(ctx, projectContent)
+	if err != nil {
+		return nil, OutputCompareToBaseline{}, fmt.Errorf("project: %w", err)
+	}
+	baseline, err := parseArtifact(ctx, baselineContent)
+	if err != nil {
+		return nil, OutputCompareToBaseline{}, fmt.Errorf("baseline: %w", err)
+	}
+
+	output := OutputCompareToBaseline{ProjectKind: artifactKind(project), BaselineKind: artifactKind(baseline)}
+	switch {
+	case output.ProjectKind == "ControlCatalog" && output.BaselineKind == "ControlCatalog":
+		output.Findings = compareCatalogs(project, baseline)
+	case output.ProjectKind == "Policy" && output.BaselineKind == "Policy":
+		output.Findings = comparePolicies(project, baseline)
+	case output.ProjectKind == "Policy" && output.BaselineKind == "ControlCatalog":
+		output.Findings = comparePolicyToCatalog(project, baseline)
+	default:
+		return nil, OutputCompareToBaseline{}, fmt.Errorf("cannot compare a %s to a %s baseline; compare a ControlCatalog or Policy to a ControlCatalog, or a Policy to a Policy",
+			orUnknownKind(output.ProjectKind), orUnknownKind(output.BaselineKind))
+	}
+
+	for _, f := range output.Findings {
+		switch f.Kind {
+		case BaselineMissing:
+			output.Missing++
+		case BaselineWeakened:
+			output.Weakened++
+		case BaselineChanged:
+			output.Changed++
+		case BaselineExtra:
+			output.Extra++
+		}
+	}
+	output.MeetsBaseline = output.Missing == 0 && output.Weakened == 0
+	verdict := "meets"
+	if !output.MeetsBaseline {
+		verdict = "does not meet"
+	}
+	output.Message = fmt.Sprintf("The %s %s the baseline: %d missing, %d weakened, %d changed, %d extra",
+		output.ProjectKind, verdict, output.Missing, output.Weakened, output.Changed, output.Extra)
+	return nil, output, nil
+}
+
+func orUnknownKind(kind string) string {
+	if kind == "" {
+		return "artifact of unknown kind"
+	}
+	return kind
+}
+
+// compareCatalogs compares controls and their assessment requirements by id.
+func compareCatalogs(project, baseline map[string]interface{}) []BaselineFinding {
+	findings := []BaselineFinding{}
+	projectControls := indexByID(mapList(project["controls"]))
+	baselineControls := indexByID(mapList(baseline["controls"]))
+
+	for _, id := range sortedKeys(baselineControls) {
+		base := baselineControls[id]
+		proj, ok := projectControls[id]
+		if !ok {
+			findings = append(findings, BaselineFinding{Kind: BaselineMissing, Entity: "control", ID: id,
+				Baseline: stringField(base, "title"), Message: fmt.Sprintf("control %s of the baseline is missing", id)})
+			continue
+		}
+		findings = append(findings, compareParameters("control", id, "objective", stringField(base, "objective"), stringField(proj, "objective"))...)
+
+		projectRequirements := indexByID(mapList(proj["assessment-requirements"]))
+		baselineRequirements := indexByID(mapList(base["assessment-requirements"]))
+		for _, rid := range sortedKeys(baselineRequirements) {
+			baseReq := baselineRequirements[rid]
+			projReq, ok := projectRequirements[rid]
+			if !ok {
+				findings = append(findings, BaselineFinding{Kind: BaselineMissing, Entity: "requirement", ID: rid,
+					Baseline: stringField(baseReq, "text"), Message: fmt.Sprintf("requirement %s of control %s is missing", rid, id)})
+				continue
+			}
+			findings = append(findings, compareParameters("requirement", rid, "text", stringField(baseReq, "text"), stringField(projReq, "text"))...)
+			if dropped := missingItems(stringList(baseReq["applicability"]), stringList(projReq["applicability"])); len(dropped) > 0 {
+				findings = append(findings, BaselineFinding{Kind: BaselineWeakened, Entity: "requirement", ID: rid, Field: "applicability",
+					Baseline: strings.Join(stringList(baseReq["applicability"]), ", "), Project: strings.Join(stringList(projReq["applicability"]), ", "),
+					Message: fmt.Sprintf("requirement %s no longer applies to %s", rid, strings.Join(dropped, ", "))})
+			}
+		}
+	}
+
+	for _, id := range sortedKeys(projectControls) {
+		if _, ok := baselineControls[id]; !ok {
+			findings = append(findings, BaselineFinding{Kind: BaselineExtra, Entity: "control", ID: id,
+				Project: stringField(projectControls[id], "title"), Message: fmt.Sprintf("control %s is not in the baseline", id)})
+		}
+	}
+	return findings
+}
+
+// comparePolicies compares catalog imports and assessment plans, matching
+// plans by the requirement they assess.
+func comparePolicies(project, baseline map[string]interface{}) []BaselineFinding {
+	findings := []BaselineFinding{}
+	projectImports := policyImports(project)
+	for _, ref := range policyImports(baseline) {
+		if !slices.Contains(projectImports, ref) {
+			findings = append(findings, BaselineFinding{Kind: BaselineMissing, Entity: "import", ID: ref,
+				Message: fmt.Sprintf("the baseline imports catalog %s, the project does not", ref)})
+		}
+	}
+
+	projectPlans := policyPlans(project)
+	baselinePlans := policyPlans(baseline)
+	for _, rid := range sortedKeys(baselinePlans) {
+		base := baselinePlans[rid]
+		proj, ok := projectPlans[rid]
+		if !ok {
+			findings = append(findings, BaselineFinding{Kind: BaselineMissing, Entity: "assessment-plan", ID: rid,
+				Baseline: stringField(base, "frequency"), Message: fmt.Sprintf("no assessment is planned for requirement %s", rid)})
+			continue
+		}
+		if f, ok := compareFrequency(rid, stringField(base, "frequency"), stringField(proj, "frequency")); ok {
+			findings = append(findings, f)
+		}
+		baseMethods, projMethods := evaluationMethods(base), evaluationMethods(proj)
+		if slices.Contains(baseMethods, "automated") && !slices.Contains(projMethods, "automated") {
+			findings = append(findings, BaselineFinding{Kind: BaselineWeakened, Entity: "assessment-plan", ID: rid, Field: "evaluation-methods",
+				Baseline: strings.Join(baseMethods, ", "), Project: strings.Join(projMethods, ", "),
+				Message: fmt.Sprintf("requirement %s is no longer evaluated automatically", rid)})
+		}
+	}
+	for _, rid := range sortedKeys(projectPlans) {
+		if _, ok := baselinePlans[rid]; !ok {
+			findings = append(findings, BaselineFinding{Kind: BaselineExtra, Entity: "assessment-plan", ID: rid,
+				Message: fmt.Sprintf("requirement %s is assessed but not in the baseline", rid)})
+		}
+	}
+	return findings
+}
+
+// comparePolicyToCatalog checks that a policy plans an assessment of every
+// requirement of a baseline catalog.
+func comparePolicyToCatalog(project, baseline map[string]interface{}) []BaselineFinding {
+	findings := []BaselineFinding{}
+	metadata, _ := baseline["metadata"].(map[string]interface{})
+	if id := stringField(metadata, "id"); id != "" && !slices.Contains(policyImports(project), id) {
+		findings = append(findings, BaselineFinding{Kind: BaselineMissing, Entity: "import", ID: id,
+			Message: fmt.Sprintf("the policy does not import the baseline catalog %s", id)})
+	}
+
+	plans := policyPlans(project)
+	required := map[string]bool{}
+	for _, c := range mapList(baseline["controls"]) {
+		for _, r := range mapList(c["assessment-requirements"]) {
+			rid := stringField(r, "id")
+			required[rid] = true
+			if _, ok := plans[rid]; !ok {
+				findings = append(findings, BaselineFinding{Kind: BaselineMissing, Entity: "assessment-plan", ID: rid,
+					Baseline: stringField(r, "text"), Message: fmt.Sprintf("no assessment is planned for requirement %s of control %s", rid, stringField(c, "id"))})
+			}
+		}
+	}
+	for _, rid := range sortedKeys(plans) {
+		if !required[rid] {
+			findings = append(findings, BaselineFinding{Kind: BaselineExtra, Entity: "assessment-plan", ID: rid,
+				Message: fmt.Sprintf("requirement %s is assessed but not in the baseline catalog", rid)})
+		}
+	}
+	return findings
+}
+
+// compareParameters pairs the numbers with units in two texts, in order per
+// unit, and reports values that weaken or change the baseline.
+func compareParameters(entity, id, field, baseline, project string) []BaselineFinding {
+	baseParams := textParameters(baseline)
+	projParams := textParameters(project)
+	var findings []BaselineFinding
+	for _, unit := range sortedKeys(baseParams) {
+		for i, base := range baseParams[unit] {
+			if i >= len(projParams[unit]) {
+				findings = append(findings, BaselineFinding{Kind: BaselineChanged, Entity: entity, ID: id, Field: field,
+					Baseline: formatParameter(base, unit), Message: fmt.Sprintf("%s %s no longer states %s", entity, id, formatParameter(base, unit))})
+				continue
+			}
+			proj := projParams[unit][i]
+			if proj == base {
+				continue
+			}
+			weaker := proj > base
+			if !parameterWeakerWhenHigher[unit] {
+				weaker = proj < base
+			}
+			finding := BaselineFinding{Kind: BaselineChanged, Entity: entity, ID: id, Field: field,
+				Baseline: formatParameter(base, unit), Project: formatParameter(proj, unit)}
+			if weaker {
+				finding.Kind = BaselineWeakened
+				finding.Message = fmt.Sprintf("%s %s weakens %s to %s", entity, id, finding.Baseline, finding.Project)
+			} else {
+				finding.Message = fmt.Sprintf("%s %s tightens %s to %s", entity, id, finding.Baseline, finding.Project)
+			}
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+// textParameters returns the numbers in text by singular unit.
+func textParameters(text string) map[string][]float64 {
+	params := map[string][]float64{}
+	for _, m := range requirementParameter.FindAllStringSubmatch(text, -1) {
+		value, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			continue
+		}
+		unit := strings.TrimSuffix(strings.ToLower(m[2]), "s")
+		params[unit] = append(params[unit], value)
+	}
+	return params
+}
+
+func formatParameter(value float64, unit string) string {
+	s := strconv.FormatFloat(value, 'f', -1, 64)
+	if value != 1 {
+		unit += "s"
+	}
+	return s + " " + unit
+}
+
+// compareFrequency reports a planned frequency that differs from the baseline,
+// as weakened when it is known to be less frequent.
+func compareFrequency(rid, baseline, project string) (BaselineFinding, bool) {
+	base, proj := normalizeFrequency(baseline), normalizeFrequency(project)
+	if base == proj {
+		return BaselineFinding{}, false
+	}
+	finding := BaselineFinding{Kind: BaselineChanged, Entity: "assessment-plan", ID: rid, Field: "frequency", Baseline: baseline, Project: project,
+		Message: fmt.Sprintf("requirement %s is assessed %s instead of %s", rid, project, baseline)}
+	bi, pi := slices.Index(frequencyOrder, base), slices.Index(frequencyOrder, proj)
+	if bi >= 0 && pi > bi {
+		finding.Kind = BaselineWeakened
+	}
+	return finding, true
+}
+
+func normalizeFrequency(frequency string) string {
+	f := strings.ToLower(strings.TrimSpace(frequency))
+	if alias, ok := frequencyAliases[f]; ok {
+		return alias
+	}
+	return f
+}
+
+// policyImports returns the reference ids of the catalogs a policy imports.
+func policyImports(policy map[string]interface{}) []string {
+	imports, _ := policy["imports"].(map[string]interface{})
+	var refs []string
+	for _, c := range mapList(imports["catalogs"]) {
+		refs = append(refs, stringField(c, "reference-id"))
+	}
+	return refs
+}
+
+// policyPlans returns the assessment plans of a policy by requirement id.
+func policyPlans(policy map[string]interface{}) map[string]map[string]interface{} {
+	adherence, _ := policy["adherence"].(map[string]interface{})
+	plans := map[string]map[string]interface{}{}
+	for _, p := range mapList(adherence["assessment-plans"]) {
+		plans[stringField(p, "requirement-id")] = p
+	}
+	return plans
+}
+
+func evaluationMethods(plan map[string]interface{}) []string {
+	var methods []string
+	for _, m := range mapList(plan["evaluation-methods"]) {
+		methods = append(methods, stringField(m, "type"))
+	}
+	return methods
+}
+
+// indexByID maps entries by their id, skipping those without one.
+func indexByID(entries []map[string]interface{}) map[string]map[string]interface{} {
+	index := map[string]map[string]interface{}{}
+	for _, e := range entries {
+		if id := stringField(e, "id"); id != "" {
+			index[id] = e
+		}
+	}
+	return index
+}
+
+// missingItems returns the items of want that have does not.
+func missingItems(want, have []string) []string {
+	var missing []string
+	for _, w := range want {
+		if !slices.Contains(have, w) {
+			missing = append(missing, w)
+		}
+	}
+	return missing
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
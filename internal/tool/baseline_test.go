@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const orgBaselineCatalog = `metadata:
+  id: ORG-BASE
+controls:
+  - id: AC.C01
+    title: Rotate credentials
+    objective: Credentials are rotated.
+    assessment-requirements:
+      - id: AC.C01.TR01
+        text: Keys older than 90 days are revoked.
+        applicability: [prod, dev]
+      - id: AC.C01.TR02
+        text: Keys are at least 2048 bits.
+  - id: AC.C02
+    title: Review access
+    assessment-requirements:
+      - id: AC.C02.TR01
+        text: Access is reviewed every 3 months.
+`
+
+const projectCatalog = `metadata:
+  id: PROJECT
+controls:
+  - id: AC.C01
+    title: Rotate credentials
+    objective: Credentials are rotated.
+    assessment-requirements:
+      - id: AC.C01.TR01
+        text: Keys older than 180 days are revoked.
+        applicability: [prod]
+      - id: AC.C01.TR02
+        text: Keys are at least 4096 bits.
+  - id: PR.C01
+    title: Project specific control
+`
+
+func TestCompareToBaseline(t *testing.T) {
+	policy, err := os.ReadFile(filepath.Join("test-data", "policy.yaml"))
+	require.NoError(t, err)
+	catalog, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err)
+	weakerPolicy := `metadata:
+  id: TEAM-POL
+imports:
+  catalogs: []
+adherence:
+  assessment-plans:
+    - id: AP-1
+      requirement-id: CCC.C01.TR01
+      frequency: monthly
+      evaluation-methods:
+        - type: manual
+    - id: AP-2
+      requirement-id: CCC.C99.TR01
+      frequency: daily
+      evaluation-methods:
+        - type: automated
+`
+
+	tests := []struct {
+		name         string
+		input        InputCompareToBaseline
+		wantFindings []BaselineFinding
+		wantMeets    bool
+		errContains  string
+	}{
+		{
+			name:  "catalog against catalog",
+			input: InputCompareToBaseline{ProjectContent: projectCatalog, BaselineContent: orgBaselineCatalog},
+			wantFindings: []BaselineFinding{
+				{Kind: BaselineWeakened, Entity: "requirement", ID: "AC.C01.TR01", Field: "text", Baseline: "90 days", Project: "180 days", Message: "requirement AC.C01.TR01 weakens 90 days to 180 days"},
+				{Kind: BaselineWeakened, Entity: "requirement", ID: "AC.C01.TR01", Field: "applicability", Baseline: "prod, dev", Project: "prod", Message: "requirement AC.C01.TR01 no longer applies to dev"},
+				{Kind: BaselineChanged, Entity: "requirement", ID: "AC.C01.TR02", Field: "text", Baseline: "2048 bits", Project: "4096 bits", Message: "requirement AC.C01.TR02 tightens 2048 bits to 4096 bits"},
+				{Kind: BaselineMissing, Entity: "control", ID: "AC.C02", Baseline: "Review access", Message: "control AC.C02 of the baseline is missing"},
+				{Kind: BaselineExtra, Entity: "control", ID: "PR.C01", Project: "Project specific control", Message: "control PR.C01 is not in the baseline"},
+			},
+		},
+		{
+			name:      "catalog meets itself",
+			input:     InputCompareToBaseline{ProjectContent: orgBaselineCatalog, BaselineContent: orgBaselineCatalog},
+			wantMeets: true,
+		},
+		{
+			name:  "policy against policy",
+			input: InputCompareToBaseline{ProjectContent: weakerPolicy, BaselineContent: string(policy)},
+			wantFindings: []BaselineFinding{
+				{Kind: BaselineMissing, Entity: "import", ID: "FINOS-CCC", Message: "the baseline imports catalog FINOS-CCC, the project does not"},
+				{Kind: BaselineWeakened, Entity: "assessment-plan", ID: "CCC.C01.TR01", Field: "frequency", Baseline: "daily", Project: "monthly", Message: "requirement CCC.C01.TR01 is assessed monthly instead of daily"},
+				{Kind: BaselineWeakened, Entity: "assessment-plan", ID: "CCC.C01.TR01", Field: "evaluation-methods", Baseline: "automated", Project: "manual", Message: "requirement CCC.C01.TR01 is no longer evaluated automatically"},
+				{Kind: BaselineMissing, Entity: "assessment-plan", ID: "CCC.C06.TR01", Baseline: "weekly", Message: "no assessment is planned for requirement CCC.C06.TR01"},
+				{Kind: BaselineExtra, Entity: "assessment-plan", ID: "CCC.C99.TR01", Message: "requirement CCC.C99.TR01 is assessed but not in the baseline"},
+			},
+		},
+		{
+			name:        "catalog against policy",
+			input:       InputCompareToBaseline{ProjectContent: projectCatalog, BaselineContent: string(policy)},
+			errContains: "cannot compare a ControlCatalog to a Policy baseline",
+		},
+		{
+			name:        "missing baseline",
+			input:       InputCompareToBaseline{ProjectContent: projectCatalog},
+			errContains: "baseline: artifact_content or artifact_uri is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := CompareToBaseline(context.Background(), nil, tt.input)
+			if tt.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantFindings == nil {
+				tt.wantFindings = []BaselineFinding{}
+			}
+			assert.Equal(t, tt.wantFindings, output.Findings)
+			assert.Equal(t, tt.wantMeets, output.MeetsBaseline)
+		})
+	}
+
+	_, output, err := CompareToBaseline(context.Background(), nil, InputCompareToBaseline{ProjectContent: string(policy), BaselineContent: string(catalog)})
+	require.NoError(t, err)
+	assert.Equal(t, "ControlCatalog", output.BaselineKind)
+	assert.Zero(t, output.Extra, "the policy plans only requirements of the catalog")
+	assert.Positive(t, output.Missing, "requirements without a plan should be missing")
+}
@@ -0,0 +1,365 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Layout of an offline bundle directory.
+const (
+	bundleManifestFile = "manifest.yaml"
+	bundleSchemaDir    = "schema"
+	bundleLexiconFile  = "lexicon.yaml"
+	bundleTemplatesDir = "templates"
+	bundleCatalogsDir  = "catalogs"
+	bundleIndexFile    = "index.yaml"
+
+	catalogResourcePrefix = "gemara://catalogs/"
+)
+
+// BundleOptions selects the content packed into an offline bundle.
+type BundleOptions struct {
+	// Output is the directory the bundle is written to. It must not exist or be empty.
+	Output string
+	// LexiconURL is the lexicon to snapshot.
+	LexiconURL string
+	// TemplateIndexURL is the template index to snapshot; templates are skipped when empty.
+	TemplateIndexURL string
+	// CatalogURLs are community catalogs to include.
+	CatalogURLs []string
+}
+
+// BundleManifest describes the contents of an offline bundle.
+type BundleManifest struct {
+	Created       time.Time         `json:"created" yaml:"created"`
+	SchemaVersion string            `json:"schema_version" yaml:"schema_version"`
+	Templates     int               `json:"templates" yaml:"templates"`
+	Catalogs      []string          `json:"catalogs,omitempty" yaml:"catalogs,omitempty"`
+	Sources       map[string]string `json:"sources" yaml:"sources"`
+}
+
+// bundleDir is the bundle the server runs from, if any.
+var bundleDir string
+
+// BuildBundle snapshots the schema, lexicon, templates, and catalogs into a
+// self-contained directory that UseBundle can serve without network access.
+func BuildBundle(ctx context.Context, opts BundleOptions) (BundleManifest, error) {
+	if opts.Output == "" {
+		return BundleManifest{}, fmt.Errorf("output directory is required")
+	}
+	if entries, err := os.ReadDir(opts.Output); err == nil && len(entries) > 0 {
+		return BundleManifest{}, fmt.Errorf("output directory %s is not empty", opts.Output)
+	}
+	if err := os.MkdirAll(opts.Output, 0o755); err != nil {
+		return BundleManifest{}, fmt.Errorf("failed to create %s: %w", opts.Output, err)
+	}
+
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return BundleManifest{}, err
+	}
+	if schema.root == "" {
+		return BundleManifest{}, fmt.Errorf("schema source is not available on disk")
+	}
+	if err := copySchemaSource(schema.root, filepath.Join(opts.Output, bundleSchemaDir)); err != nil {
+		return BundleManifest{}, err
+	}
+
+	manifest := BundleManifest{
+		Created:       time.Now().UTC(),
+		SchemaVersion: schema.version,
+		Sources: map[string]string{
+			"schema": gemaraModulePath,
+		},
+	}
+
+	if opts.LexiconURL != "" {
+		if err := bundleLexicon(ctx, opts.LexiconURL, filepath.Join(opts.Output, bundleLexiconFile)); err != nil {
+			return BundleManifest{}, err
+		}
+		manifest.Sources["lexicon"] = opts.LexiconURL
+	}
+
+	if opts.TemplateIndexURL != "" {
+		n, err := bundleTemplates(ctx, opts.TemplateIndexURL, filepath.Join(opts.Output, bundleTemplatesDir))
+		if err != nil {
+			return BundleManifest{}, err
+		}
+		manifest.Templates = n
+		manifest.Sources["templates"] = opts.TemplateIndexURL
+	}
+
+	for i, catalogURL := range opts.CatalogURLs {
+		name, err := bundleCatalog(ctx, catalogURL, filepath.Join(opts.Output, bundleCatalogsDir), manifest.Catalogs)
+		if err != nil {
+			return BundleManifest{}, err
+		}
+		manifest.Catalogs = append(manifest.Catalogs, name)
+		manifest.Sources[fmt.Sprintf("catalog/%d", i+1)] = catalogURL
+	}
+
+	raw, err := yaml.Marshal(manifest)
+	if err != nil {
+		return BundleManifest{}, fmt.Errorf("failed to encode bundle manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(opts.Output, bundleManifestFile), raw, 0o644); err != nil {
+		return BundleManifest{}, fmt.Errorf("failed to write bundle manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// UseBundle points the schema, lexicon, template, and catalog sources at an
+// offline bundle so the server makes no network requests. The template index
+// of config is set to the bundle's.
+func UseBundle(config *Config, dir string) (BundleManifest, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return BundleManifest{}, fmt.Errorf("invalid bundle directory: %w", err)
+	}
+	manifest, err := readBundleManifest(abs)
+	if err != nil {
+		return BundleManifest{}, err
+	}
+
+	schemaDir := filepath.Join(abs, bundleSchemaDir)
+	if _, err := loadSchemaDir(schemaDir, manifest.SchemaVersion); err != nil {
+		return BundleManifest{}, fmt.Errorf("bundle schema is unusable: %w", err)
+	}
+	schemaLoader = func(context.Context) (*gemaraSchema, error) {
+		return loadSchemaDir(schemaDir, manifest.SchemaVersion)
+	}
+
+	DefaultLexicon.Configure([]string{fileURL(filepath.Join(abs, bundleLexiconFile))}, DefaultLexicon.Conflict())
+	config.TemplateIndexURL = fileURL(filepath.Join(abs, bundleTemplatesDir, bundleIndexFile))
+	bundleDir = abs
+	return manifest, nil
+}
+
+func readBundleManifest(dir string) (BundleManifest, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, bundleManifestFile))
+	if err != nil {
+		return BundleManifest{}, fmt.Errorf("%s is not a bundle: %w", dir, err)
+	}
+	var manifest BundleManifest
+	if err := yaml.Unmarshal(raw, &manifest); err != nil {
+		return BundleManifest{}, fmt.Errorf("failed to parse bundle manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// copySchemaSource copies the CUE files of a module, including cue.mod/module.cue.
+func copySchemaSource(src, dst string) error {
+	err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if rel != "." && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(p) != ".cue" {
+			return nil
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, 0o644)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to snapshot schema: %w", err)
+	}
+	return nil
+}
+
+func bundleLexicon(ctx context.Context, source, target string) error {
+	body, err := fetchURL(ctx, source)
+	if err != nil {
+		return fmt.Errorf("failed to fetch lexicon: %w", err)
+	}
+	var entries []LexiconEntry
+	if err := yaml.Unmarshal(body, &entries); err != nil {
+		return fmt.Errorf("failed to parse lexicon: %w", err)
+	}
+	if err := os.WriteFile(target, body, 0o644); err != nil {
+		return fmt.Errorf("failed to write lexicon: %w", err)
+	}
+	return nil
+}
+
+// bundleTemplates copies a template index and every template it lists,
+// rewriting template URLs to be relative to the bundled index.
+func bundleTemplates(ctx context.Context, indexURL, dir string) (int, error) {
+	index, _, err := loadTemplateIndex(ctx, indexURL, true)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	bundled := TemplateIndex{Templates: make([]TemplateEntry, 0, len(index.Templates))}
+	for _, entry := range index.Templates {
+		templateURL, err := resolveTemplateURL(indexURL, entry.URL)
+		if err != nil {
+			return 0, err
+		}
+		content, err := fetchURL(ctx, templateURL)
+		if err != nil {
+			return 0, fmt.Errorf("failed to fetch template %s: %w", entry.ID, err)
+		}
+
+		name := bundleFileName(entry.ID) + ".yaml"
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			return 0, fmt.Errorf("failed to write template %s: %w", entry.ID, err)
+		}
+		entry.URL = name
+		bundled.Templates = append(bundled.Templates, entry)
+	}
+
+	raw, err := yaml.Marshal(bundled)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode template index: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, bundleIndexFile), raw, 0o644); err != nil {
+		return 0, fmt.Errorf("failed to write template index: %w", err)
+	}
+	return len(bundled.Templates), nil
+}
+
+// bundleCatalog copies a catalog into dir under a name not already in taken.
+func bundleCatalog(ctx context.Context, catalogURL, dir string, taken []string) (string, error) {
+	body, err := fetchURL(ctx, catalogURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch catalog: %w", err)
+	}
+	if _, err := parseArtifact(ctx, string(body)); err != nil {
+		return "", fmt.Errorf("catalog %s: %w", catalogURL, err)
+	}
+
+	u, err := url.Parse(catalogURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid catalog URL %q: %w", catalogURL, err)
+	}
+	base := bundleFileName(strings.TrimSuffix(path.Base(u.Path), path.Ext(u.Path)))
+	if strings.Trim(base, "-.") == "" {
+		base = "catalog"
+	}
+	name := base
+	for i := 2; containsFold(taken, name); i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".yaml"), body, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write catalog: %w", err)
+	}
+	return name, nil
+}
+
+// bundleCatalogResources returns a resource for every catalog in the active bundle.
+func bundleCatalogResources() []*mcp.Resource {
+	names := bundleCatalogNames()
+	resources := make([]*mcp.Resource, 0, len(names))
+	for _, name := range names {
+		resources = append(resources, &mcp.Resource{
+			Name:        "catalog-" + name,
+			URI:         catalogResourcePrefix + name,
+			Title:       "Catalog " + name,
+			Description: fmt.Sprintf("Community catalog %s from the offline bundle", name),
+			MIMEType:    "application/yaml",
+		})
+	}
+	return resources
+}
+
+func bundleCatalogNames() []string {
+	if bundleDir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(filepath.Join(bundleDir, bundleCatalogsDir))
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".yaml" {
+			names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HandleBundleCatalogResource serves a catalog from the offline bundle.
+func HandleBundleCatalogResource(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	content, err := readBundleCatalog(uri)
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: "application/yaml",
+				Text:     string(content),
+			},
+		},
+	}, nil
+}
+
+// readBundleCatalog reads gemara://catalogs/{name} from the active bundle.
+func readBundleCatalog(uri string) ([]byte, error) {
+	name, ok := strings.CutPrefix(uri, catalogResourcePrefix)
+	if !ok || !slices.Contains(bundleCatalogNames(), name) {
+		return nil, fmt.Errorf("catalog %s not found", uri)
+	}
+	content, err := os.ReadFile(filepath.Join(bundleDir, bundleCatalogsDir, name+".yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read catalog: %w", err)
+	}
+	return content, nil
+}
+
+// bundleFileName replaces characters that are unsafe in file names and URLs.
+func bundleFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		}
+		return '-'
+	}, s)
+}
+
+// fileURL returns the file:// URL of an absolute path.
+func fileURL(p string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(p)}).String()
+}
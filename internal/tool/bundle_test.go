@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useBundleSources snapshots the package state that UseBundle overrides and
+// serves the test schema from a directory so it can be copied into a bundle.
+func useBundleSources(t *testing.T) {
+	t.Helper()
+	schemaDir := t.TempDir()
+	content, err := os.ReadFile(filepath.Join("test-data", "schema.cue"))
+	require.NoError(t, err, "should read test schema")
+	writeTestFile(t, schemaDir, "schema.cue", string(content))
+
+	originalLoader, originalLexicon, originalBundle := schemaLoader, DefaultLexicon, bundleDir
+	t.Cleanup(func() {
+		schemaLoader, DefaultLexicon, bundleDir = originalLoader, originalLexicon, originalBundle
+		templateIndexCache = nil
+	})
+	DefaultLexicon = NewLexiconService(originalLexicon.Sources(), originalLexicon.Conflict())
+	schemaLoader = func(context.Context) (*gemaraSchema, error) {
+		return loadSchemaDir(schemaDir, testSchemaVersion)
+	}
+}
+
+func TestBuildAndUseBundle(t *testing.T) {
+	useBundleSources(t)
+
+	sources := t.TempDir()
+	writeTestFile(t, sources, "lexicon.yaml", "- term: Control\n  definition: A safeguard\n  references: []\n")
+	catalog, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err, "should read catalog")
+	writeTestFile(t, sources, "osps baseline.yaml", string(catalog))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/index.yaml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("templates:\n  - id: saas/policy\n    name: SaaS Policy\n    definition: \"#Policy\"\n    url: templates/saas-policy.yaml\n"))
+	})
+	mux.HandleFunc("/templates/saas-policy.yaml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("title: SaaS Policy\n"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	output := filepath.Join(t.TempDir(), "bundle")
+	manifest, err := BuildBundle(context.Background(), BundleOptions{
+		Output:           output,
+		LexiconURL:       fileURL(filepath.Join(sources, "lexicon.yaml")),
+		TemplateIndexURL: server.URL + "/index.yaml",
+		CatalogURLs:      []string{fileURL(filepath.Join(sources, "osps baseline.yaml"))},
+	})
+	require.NoError(t, err, "should build bundle")
+	assert.Equal(t, testSchemaVersion, manifest.SchemaVersion, "manifest should record the schema version")
+	assert.Equal(t, 1, manifest.Templates, "manifest should count templates")
+	assert.Equal(t, []string{"osps-baseline"}, manifest.Catalogs, "catalog names should be file-safe")
+	assert.FileExists(t, filepath.Join(output, bundleSchemaDir, "schema.cue"), "schema should be snapshotted")
+
+	// Serve from the bundle with the sources gone
+	server.Close()
+	require.NoError(t, os.RemoveAll(sources), "should remove sources")
+	schemaLoader = func(context.Context) (*gemaraSchema, error) {
+		t.Fatal("bundle should replace the schema loader")
+		return nil, nil
+	}
+	templateIndexCache = nil
+
+	config := NewConfig()
+	_, err = UseBundle(config, output)
+	require.NoError(t, err, "should use bundle")
+	ctx := WithConfig(context.Background(), config)
+
+	schema, err := schemaLoader(context.Background())
+	require.NoError(t, err, "should load bundled schema")
+	_, err = schema.lookupDefinition("#Policy")
+	assert.NoError(t, err, "bundled schema should define #Policy")
+
+	entries, _, err := DefaultLexicon.fetch(context.Background())
+	require.NoError(t, err, "should read bundled lexicon")
+	assert.Equal(t, "Control", entries[0].Term)
+
+	_, fetched, err := FetchTemplate(ctx, nil, InputFetchTemplate{ID: "saas/policy"})
+	require.NoError(t, err, "should fetch bundled template")
+	assert.Equal(t, "title: SaaS Policy\n", fetched.Content)
+
+	resources := bundleCatalogResources()
+	require.Len(t, resources, 1, "should expose bundled catalog")
+	content, err := readArtifactURI(context.Background(), resources[0].URI)
+	require.NoError(t, err, "should read bundled catalog by URI")
+	assert.Equal(t, string(catalog), string(content))
+}
+
+func TestBuildBundleErrors(t *testing.T) {
+	useBundleSources(t)
+
+	_, err := BuildBundle(context.Background(), BundleOptions{})
+	assert.ErrorContains(t, err, "output directory is required")
+
+	nonEmpty := t.TempDir()
+	writeTestFile(t, nonEmpty, "file.txt", "x")
+	_, err = BuildBundle(context.Background(), BundleOptions{Output: nonEmpty})
+	assert.ErrorContains(t, err, "is not empty")
+
+	_, err = UseBundle(NewConfig(), t.TempDir())
+	assert.ErrorContains(t, err, "is not a bundle")
+}
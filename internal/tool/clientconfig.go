@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// MCP clients whose configuration InstallClientConfig can write.
+const (
+	ClientClaude   = "claude"
+	ClientCursor   = "cursor"
+	ClientVSCode   = "vscode"
+	ClientWindsurf = "windsurf"
+)
+
+// MCPClients lists the clients InstallClientConfig supports.
+func MCPClients() []string {
+	return []string{ClientClaude, ClientCursor, ClientVSCode, ClientWindsurf}
+}
+
+// ServerCommand is how an MCP client starts gemara-mcp.
+type ServerCommand struct {
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Env     map[string]string `json:"env,omitempty"`
+}
+
+// ClientConfigPath returns the MCP configuration file of a client: the user
+// configuration, or with project set the one in the project directory dir.
+// VS Code reads MCP servers only from the workspace, so its configuration is
+// always the project one.
+func ClientConfigPath(client string, project bool, dir string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil && !project {
+		return "", fmt.Errorf("failed to locate the home directory: %w", err)
+	}
+	switch client {
+	case ClientClaude:
+		if project {
+			return filepath.Join(dir, ".mcp.json"), nil
+		}
+		switch runtime.GOOS {
+		case "darwin":
+			return filepath.Join(home, "Library", "Application Support", "Claude", "claude_desktop_config.json"), nil
+		case "windows":
+			return filepath.Join(os.Getenv("APPDATA"), "Claude", "claude_desktop_config.json"), nil
+		default:
+			return filepath.Join(home, ".config", "Claude", "claude_desktop_config.json"), nil
+		}
+	case ClientCursor:
+		if project {
+			return filepath.Join(dir, ".cursor", "mcp.json"), nil
+		}
+		return filepath.Join(home, ".cursor", "mcp.json"), nil
+	case ClientVSCode:
+		return filepath.Join(dir, ".vscode", "mcp.json"), nil
+	case ClientWindsurf:
+		if project {
+			return "", fmt.Errorf("windsurf has no project MCP configuration")
+		}
+		return filepath.Join(home, ".codeium", "windsurf", "mcp_config.json"), nil
+	default:
+		return "", fmt.Errorf("unsupported client %q (supported: %s)", client, strings.Join(MCPClients(), ", "))
+	}
+}
+
+// clientServersKey is the key under which a client's configuration lists servers.
+func clientServersKey(client string) string {
+	if client == ClientVSCode {
+		return "servers"
+	}
+	return "mcpServers"
+}
+
+// PatchClientConfig adds or replaces the server called name in the client
+// configuration content, keeping every other setting, and reports whether
+// the server was already configured. Empty content starts a new configuration.
+func PatchClientConfig(content []byte, client, name string, server ServerCommand) ([]byte, bool, error) {
+	config := map[string]interface{}{}
+	if len(strings.TrimSpace(string(content))) > 0 {
+		if err := json.Unmarshal(content, &config); err != nil {
+			return nil, false, fmt.Errorf("failed to parse the existing configuration (comments and trailing commas are not supported): %w", err)
+		}
+	}
+
+	key := clientServersKey(client)
+	servers, ok := config[key].(map[string]interface{})
+	if config[key] != nil && !ok {
+		return nil, false, fmt.Errorf("%q in the existing configuration is not an object", key)
+	}
+	if servers == nil {
+		servers = map[string]interface{}{}
+	}
+	_, replaced := servers[name]
+
+	entry := map[string]interface{}{
+		"command": server.Command,
+		"args":    server.Args,
+	}
+	if client == ClientVSCode {
+		entry["type"] = "stdio"
+	}
+	if len(server.Env) > 0 {
+		entry["env"] = server.Env
+	}
+	servers[name] = entry
+	config[key] = servers
+
+	patched, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode the configuration: %w", err)
+	}
+	return append(patched, '\n'), replaced, nil
+}
+
+// InstallClientConfig writes the server called name into the client
+// configuration at path, creating the file and its directory when needed,
+// and reports whether an existing entry was replaced. The file is replaced
+// through a temporary file so a failed write leaves it intact.
+func InstallClientConfig(path, client, name string, server ServerCommand) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	patched, replaced, err := PatchClientConfig(content, client, name, server)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".new-*")
+	if err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(patched)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return replaced, nil
+}
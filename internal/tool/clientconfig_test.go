@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchClientConfig(t *testing.T) {
+	server := ServerCommand{
+		Command: "/usr/local/bin/gemara-mcp",
+		Args:    []string{"serve", "--mode", "advisory"},
+		Env:     map[string]string{"GITHUB_TOKEN": "${GITHUB_TOKEN}"},
+	}
+
+	tests := []struct {
+		name         string
+		client       string
+		content      string
+		wantErr      bool
+		errContains  string
+		wantReplaced bool
+		validate     func(t *testing.T, config map[string]interface{})
+	}{
+		{
+			name:   "new configuration",
+			client: ClientClaude,
+			validate: func(t *testing.T, config map[string]interface{}) {
+				entry := config["mcpServers"].(map[string]interface{})["gemara-mcp"].(map[string]interface{})
+				assert.Equal(t, "/usr/local/bin/gemara-mcp", entry["command"])
+				assert.Equal(t, []interface{}{"serve", "--mode", "advisory"}, entry["args"])
+				assert.Equal(t, map[string]interface{}{"GITHUB_TOKEN": "${GITHUB_TOKEN}"}, entry["env"])
+				assert.NotContains(t, entry, "type")
+			},
+		},
+		{
+			name:         "keeps other servers and settings",
+			client:       ClientCursor,
+			content:      `{"theme": "dark", "mcpServers": {"other": {"command": "other"}, "gemara-mcp": {"command": "old"}}}`,
+			wantReplaced: true,
+			validate: func(t *testing.T, config map[string]interface{}) {
+				assert.Equal(t, "dark", config["theme"])
+				servers := config["mcpServers"].(map[string]interface{})
+				assert.Contains(t, servers, "other")
+				assert.Equal(t, "/usr/local/bin/gemara-mcp", servers["gemara-mcp"].(map[string]interface{})["command"])
+			},
+		},
+		{
+			name:   "vscode servers",
+			client: ClientVSCode,
+			validate: func(t *testing.T, config map[string]interface{}) {
+				entry := config["servers"].(map[string]interface{})["gemara-mcp"].(map[string]interface{})
+				assert.Equal(t, "stdio", entry["type"])
+				assert.NotContains(t, config, "mcpServers")
+			},
+		},
+		{
+			name:        "comments",
+			client:      ClientVSCode,
+			content:     "// servers\n{}",
+			wantErr:     true,
+			errContains: "comments",
+		},
+		{
+			name:        "servers not an object",
+			client:      ClientWindsurf,
+			content:     `{"mcpServers": []}`,
+			wantErr:     true,
+			errContains: "not an object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patched, replaced, err := PatchClientConfig([]byte(tt.content), tt.client, "gemara-mcp", server)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantReplaced, replaced)
+			var config map[string]interface{}
+			require.NoError(t, json.Unmarshal(patched, &config))
+			tt.validate(t, config)
+		})
+	}
+}
+
+func TestInstallClientConfig(t *testing.T) {
+	dir := t.TempDir()
+	path, err := ClientConfigPath(ClientCursor, true, dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, ".cursor", "mcp.json"), path)
+
+	server := ServerCommand{Command: "gemara-mcp", Args: []string{"serve"}}
+	replaced, err := InstallClientConfig(path, ClientCursor, "gemara-mcp", server)
+	require.NoError(t, err)
+	assert.False(t, replaced)
+
+	replaced, err = InstallClientConfig(path, ClientCursor, "gemara-mcp", server)
+	require.NoError(t, err)
+	assert.True(t, replaced, "a second install should replace the entry")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"command": "gemara-mcp"`)
+
+	_, err = ClientConfigPath("emacs", false, dir)
+	assert.ErrorContains(t, err, "unsupported client")
+}
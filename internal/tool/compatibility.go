@@ -0,0 +1,359 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/mod/modconfig"
+	"cuelang.org/go/mod/modregistry"
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	schemaChangeRemoved  = "removed"
+	schemaChangeRequired = "now required"
+	schemaChangeType     = "type changed"
+	schemaChangeAdded    = "added"
+	schemaChangeOptional = "now optional"
+
+	// schemaDiffDepth bounds how deep recursive definitions are compared.
+	schemaDiffDepth = 8
+)
+
+// schemaVersionFields are where an artifact may declare the Gemara schema
+// version it was written against, in order of precedence.
+var schemaVersionFields = [][]string{
+	{"apiVersion"},
+	{"schema-version"},
+	{"metadata", "gemara-version"},
+	{"metadata", "schema-version"},
+}
+
+// schemaVersionPattern extracts a module version such as v0.7.0 from a
+// declaration like "gemara/v0.7.0" or "github.com/gemaraproj/gemara@v0.7.0".
+var schemaVersionPattern = regexp.MustCompile(`v?(\d+)\.(\d+)\.(\d+)(-[0-9A-Za-z.-]+)?`)
+
+// schemaVersionLister lists the published versions of the Gemara module. It is
+// a variable so tests can avoid the registry.
+var schemaVersionLister = listGemaraVersions
+
+// schemaVersionLoader builds a published version of the Gemara module. It is a
+// variable so tests can supply local schemas.
+var schemaVersionLoader = func(ctx context.Context, version string) (*gemaraSchema, error) {
+	return loadGemaraModule(ctx, GemaraModule+"@"+version)
+}
+
+// MetadataCheckSchemaCompatibility describes the CheckSchemaCompatibility tool.
+var MetadataCheckSchemaCompatibility = &mcp.Tool{
+	Name: "check_schema_compatibility",
+	Description: "Read the Gemara schema version an artifact declares (apiVersion, schema-version, or " +
+		"metadata.gemara-version), compare it with the module versions published in the CUE registry, and report " +
+		"whether an upgrade is needed, the breaking differences in the artifact's definition between the two versions, " +
+		"and whether the artifact already validates against the newer one.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": func() map[string]interface{} {
+			properties := artifactSourceProperties("check")
+			properties["definition"] = map[string]interface{}{
+				"type":        "string",
+				"description": "Definition to compare (default: detected from the artifact's top-level fields)",
+			}
+			properties["target_version"] = map[string]interface{}{
+				"type":        "string",
+				"description": "Module version to compare against (default: the latest published version)",
+			}
+			return properties
+		}(),
+	},
+}
+
+// InputCheckSchemaCompatibility is the input for the CheckSchemaCompatibility tool.
+type InputCheckSchemaCompatibility struct {
+	ArtifactContent string `json:"artifact_content,omitempty"`
+	ArtifactURI     string `json:"artifact_uri,omitempty"`
+	Definition      string `json:"definition,omitempty"`
+	TargetVersion   string `json:"target_version,omitempty"`
+}
+
+// SchemaChange is a difference in a definition between two schema versions.
+type SchemaChange struct {
+	Path   string `json:"path"`
+	Change string `json:"change"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+// OutputCheckSchemaCompatibility is the output for the CheckSchemaCompatibility tool.
+type OutputCheckSchemaCompatibility struct {
+	Definition string `json:"definition"`
+	// DeclaredVersion is the schema version the artifact declares, if any.
+	DeclaredVersion string `json:"declared_version,omitempty"`
+	// DeclaredIn is the artifact field the version was read from.
+	DeclaredIn         string         `json:"declared_in,omitempty"`
+	TargetVersion      string         `json:"target_version"`
+	AvailableVersions  []string       `json:"available_versions"`
+	UpgradeNeeded      bool           `json:"upgrade_needed"`
+	BreakingChanges    []SchemaChange `json:"breaking_changes"`
+	Additions          []SchemaChange `json:"additions"`
+	ValidAgainstTarget bool           `json:"valid_against_target"`
+	Errors             []string       `json:"errors,omitempty"`
+	Message            string         `json:"message"`
+}
+
+// CheckSchemaCompatibility compares the schema version an artifact declares with the published versions.
+func CheckSchemaCompatibility(ctx context.Context, _ *mcp.CallToolRequest, input InputCheckSchemaCompatibility) (*mcp.CallToolResult, OutputCheckSchemaCompatibility, error) {
+	content, err := artifactSource(ctx, input.ArtifactContent, input.ArtifactURI)
+	if err != nil {
+		return nil, OutputCheckSchemaCompatibility{}, err
+	}
+	doc, err := parseArtifact(ctx, string(content))
+	if err != nil {
+		return nil, OutputCheckSchemaCompatibility{}, err
+	}
+	definition := input.Definition
+	if definition == "" {
+		definition = artifactKind(doc)
+	}
+	if definition == "" {
+		return nil, OutputCheckSchemaCompatibility{}, fmt.Errorf("could not detect the artifact type; set definition")
+	}
+	definition = normalizeDefinition(definition)
+
+	output := OutputCheckSchemaCompatibility{Definition: definition, BreakingChanges: []SchemaChange{}, Additions: []SchemaChange{}}
+	output.DeclaredVersion, output.DeclaredIn = declaredSchemaVersion(doc)
+
+	versions, err := schemaVersionLister(ctx)
+	if err != nil {
+		return nil, OutputCheckSchemaCompatibility{}, fmt.Errorf("failed to list Gemara schema versions: %w", err)
+	}
+	sort.Slice(versions, func(i, j int) bool { return compareSemver(versions[i], versions[j]) < 0 })
+	output.AvailableVersions = versions
+	output.TargetVersion = input.TargetVersion
+	if output.TargetVersion == "" {
+		if len(versions) == 0 {
+			return nil, OutputCheckSchemaCompatibility{}, fmt.Errorf("no Gemara schema versions are published")
+		}
+		output.TargetVersion = versions[len(versions)-1]
+	}
+
+	target, err := schemaVersionLoader(ctx, output.TargetVersion)
+	if err != nil {
+		return nil, OutputCheckSchemaCompatibility{}, fmt.Errorf("failed to load schema %s: %w", output.TargetVersion, err)
+	}
+	// The version declaration describes the artifact rather than being part of it
+	validated := string(content)
+	if output.DeclaredIn != "" {
+		stripped, err := yaml.Marshal(withoutField(doc, strings.Split(output.DeclaredIn, ".")))
+		if err != nil {
+			return nil, OutputCheckSchemaCompatibility{}, fmt.Errorf("failed to encode artifact: %w", err)
+		}
+		validated = string(stripped)
+	}
+	validation, err := validateAgainstSchema(target, definition, validated)
+	if err != nil {
+		return nil, OutputCheckSchemaCompatibility{}, err
+	}
+	output.ValidAgainstTarget = validation.Valid
+	output.Errors = validation.Errors
+
+	if output.DeclaredVersion == "" {
+		output.Message = fmt.Sprintf("The artifact declares no schema version; it %s against %s %s", validityPhrase(output.ValidAgainstTarget), definition, output.TargetVersion)
+		return nil, output, nil
+	}
+	output.UpgradeNeeded = compareSemver(output.DeclaredVersion, output.TargetVersion) < 0
+	if compareSemver(output.DeclaredVersion, output.TargetVersion) == 0 {
+		output.Message = fmt.Sprintf("The artifact declares %s, the target version; it %s", output.DeclaredVersion, validityPhrase(output.ValidAgainstTarget))
+		return nil, output, nil
+	}
+
+	declared, err := schemaVersionLoader(ctx, output.DeclaredVersion)
+	if err != nil {
+		output.Message = fmt.Sprintf("Could not load the declared schema %s to compare (%v); the artifact %s against %s",
+			output.DeclaredVersion, err, validityPhrase(output.ValidAgainstTarget), output.TargetVersion)
+		return nil, output, nil
+	}
+	from, err := declared.lookupDefinition(definition)
+	if err != nil {
+		return nil, OutputCheckSchemaCompatibility{}, fmt.Errorf("schema %s: %w", output.DeclaredVersion, err)
+	}
+	to, err := target.lookupDefinition(definition)
+	if err != nil {
+		return nil, OutputCheckSchemaCompatibility{}, fmt.Errorf("schema %s: %w", output.TargetVersion, err)
+	}
+	for _, change := range diffSchemaValues(from, to, "$", 0) {
+		switch change.Change {
+		case schemaChangeAdded, schemaChangeOptional:
+			output.Additions = append(output.Additions, change)
+		default:
+			output.BreakingChanges = append(output.BreakingChanges, change)
+		}
+	}
+
+	direction := "upgrade from"
+	if !output.UpgradeNeeded {
+		direction = "downgrade from"
+	}
+	output.Message = fmt.Sprintf("%s %s %s to %s has %d breaking change(s) and %d addition(s); the artifact %s against %s",
+		definition, direction, output.DeclaredVersion, output.TargetVersion, len(output.BreakingChanges), len(output.Additions),
+		validityPhrase(output.ValidAgainstTarget), output.TargetVersion)
+	return nil, output, nil
+}
+
+// declaredSchemaVersion returns the schema version an artifact declares and the
+// field it was declared in.
+func declaredSchemaVersion(doc map[string]interface{}) (string, string) {
+	for _, field := range schemaVersionFields {
+		var node interface{} = doc
+		for _, key := range field {
+			m, ok := node.(map[string]interface{})
+			if !ok {
+				node = nil
+				break
+			}
+			node = m[key]
+		}
+		declared, ok := node.(string)
+		if !ok {
+			continue
+		}
+		if m := schemaVersionPattern.FindString(declared); m != "" {
+			return "v" + strings.TrimPrefix(m, "v"), strings.Join(field, ".")
+		}
+	}
+	return "", ""
+}
+
+// withoutField returns a copy of doc with the field at path removed.
+func withoutField(doc map[string]interface{}, path []string) map[string]interface{} {
+	out := make(map[string]interface{}, len(doc))
+	for k, v := range doc {
+		out[k] = v
+	}
+	if len(path) == 1 {
+		delete(out, path[0])
+	} else if child, ok := out[path[0]].(map[string]interface{}); ok {
+		out[path[0]] = withoutField(child, path[1:])
+	}
+	return out
+}
+
+// diffSchemaValues lists the differences between two versions of a schema value.
+func diffSchemaValues(from, to cue.Value, path string, depth int) []SchemaChange {
+	if depth > schemaDiffDepth {
+		return nil
+	}
+	fromKind, toKind := from.IncompleteKind(), to.IncompleteKind()
+	if fromKind != toKind {
+		return []SchemaChange{{Path: path, Change: schemaChangeType, From: fromKind.String(), To: toKind.String()}}
+	}
+
+	switch toKind {
+	case cue.ListKind:
+		fromElem := from.LookupPath(cue.MakePath(cue.AnyIndex))
+		toElem := to.LookupPath(cue.MakePath(cue.AnyIndex))
+		if fromElem.Exists() && toElem.Exists() {
+			return diffSchemaValues(fromElem, toElem, path+"[*]", depth+1)
+		}
+		return nil
+	case cue.StructKind:
+	default:
+		return nil
+	}
+
+	var changes []SchemaChange
+	fromFields := map[string]schemaField{}
+	for _, f := range schemaFields(from) {
+		fromFields[f.name] = f
+	}
+	for _, f := range schemaFields(to) {
+		fieldPath := childPath(path, f.name)
+		previous, ok := fromFields[f.name]
+		delete(fromFields, f.name)
+		switch {
+		case !ok && f.required:
+			changes = append(changes, SchemaChange{Path: fieldPath, Change: schemaChangeRequired, To: f.value.IncompleteKind().String()})
+			continue
+		case !ok:
+			changes = append(changes, SchemaChange{Path: fieldPath, Change: schemaChangeAdded, To: f.value.IncompleteKind().String()})
+			continue
+		case f.required && !previous.required:
+			changes = append(changes, SchemaChange{Path: fieldPath, Change: schemaChangeRequired, From: "optional", To: "required"})
+		case !f.required && previous.required:
+			changes = append(changes, SchemaChange{Path: fieldPath, Change: schemaChangeOptional, From: "required", To: "optional"})
+		}
+		changes = append(changes, diffSchemaValues(previous.value, f.value, fieldPath, depth+1)...)
+	}
+
+	removed := make([]string, 0, len(fromFields))
+	for name := range fromFields {
+		removed = append(removed, name)
+	}
+	sort.Strings(removed)
+	for _, name := range removed {
+		changes = append(changes, SchemaChange{Path: childPath(path, name), Change: schemaChangeRemoved, From: fromFields[name].value.IncompleteKind().String()})
+	}
+	return changes
+}
+
+// listGemaraVersions lists the versions of the Gemara module in the CUE registry.
+func listGemaraVersions(ctx context.Context) ([]string, error) {
+	resolver, err := modconfig.NewResolver(serverConfig(ctx).Registry.cueConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CUE registry: %w", err)
+	}
+	versions, err := modregistry.NewClientWithResolver(resolver).ModuleVersions(ctx, GemaraModule)
+	if err != nil {
+		emitUpstreamFailure(ctx, GemaraModule, err)
+		return nil, err
+	}
+	return versions, nil
+}
+
+// compareSemver orders two semantic versions, with or without a leading v.
+// Pre-releases sort before their release; unparseable versions sort first.
+func compareSemver(a, b string) int {
+	pa, pb := schemaVersionPattern.FindStringSubmatch(a), schemaVersionPattern.FindStringSubmatch(b)
+	switch {
+	case pa == nil && pb == nil:
+		return strings.Compare(a, b)
+	case pa == nil:
+		return -1
+	case pb == nil:
+		return 1
+	}
+	for i := 1; i <= 3; i++ {
+		x, _ := strconv.Atoi(pa[i])
+		y, _ := strconv.Atoi(pb[i])
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case pa[4] == pb[4]:
+		return 0
+	case pa[4] == "":
+		return 1
+	case pb[4] == "":
+		return -1
+	}
+	return strings.Compare(pa[4], pb[4])
+}
+
+// validityPhrase describes a validation result.
+func validityPhrase(valid bool) string {
+	if valid {
+		return "validates"
+	}
+	return "does not validate"
+}
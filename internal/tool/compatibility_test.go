@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useTestSchemaVersions serves the test schema as v0.2.0 and a variant of it as
+// v0.1.0, in which catalogs had no title, controls had a legacy-id, and
+// guideline mappings did not exist.
+func useTestSchemaVersions(t *testing.T) {
+	t.Helper()
+	current, err := os.ReadFile(filepath.Join("test-data", "schema.cue"))
+	require.NoError(t, err, "should be able to read test schema")
+	previous := strings.NewReplacer(
+		"metadata: #Metadata\n\ttitle:    string\n\tfamilies?", "metadata: #Metadata\n\tfamilies?",
+		"\t\"guideline-mappings\"?: [...#Mapping]\n", "\t\"legacy-id\": string\n",
+	).Replace(string(current))
+	schemas := map[string]string{"v0.1.0": previous, "v0.2.0": string(current)}
+
+	originalLister, originalLoader := schemaVersionLister, schemaVersionLoader
+	t.Cleanup(func() { schemaVersionLister, schemaVersionLoader = originalLister, originalLoader })
+	schemaVersionLister = func(context.Context) ([]string, error) {
+		return []string{"v0.2.0", "v0.1.0", "v0.2.0-rc.1"}, nil
+	}
+	schemaVersionLoader = func(_ context.Context, version string) (*gemaraSchema, error) {
+		source, ok := schemas[version]
+		if !ok {
+			return nil, fmt.Errorf("module version %s not found", version)
+		}
+		cueCtx := cuecontext.New()
+		value := cueCtx.CompileString(source)
+		require.NoError(t, value.Err(), "test schema should compile")
+		return &gemaraSchema{ctx: cueCtx, value: value, version: version}, nil
+	}
+}
+
+func TestCheckSchemaCompatibility(t *testing.T) {
+	useTestSchemaVersions(t)
+	catalog, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err, "should read test catalog")
+
+	tests := []struct {
+		name          string
+		input         InputCheckSchemaCompatibility
+		wantErr       bool
+		errContains   string
+		wantDeclared  string
+		wantTarget    string
+		wantUpgrade   bool
+		wantBreaking  []SchemaChange
+		wantAdditions []SchemaChange
+		wantValid     bool
+	}{
+		{
+			name:        "missing artifact",
+			wantErr:     true,
+			errContains: "artifact_content or artifact_uri is required",
+		},
+		{
+			name:        "undetectable artifact",
+			input:       InputCheckSchemaCompatibility{ArtifactContent: "name: something\n"},
+			wantErr:     true,
+			errContains: "set definition",
+		},
+		{
+			name:       "no declared version",
+			input:      InputCheckSchemaCompatibility{ArtifactContent: string(catalog)},
+			wantTarget: "v0.2.0",
+			wantValid:  true,
+		},
+		{
+			name:         "declared in metadata and current",
+			input:        InputCheckSchemaCompatibility{ArtifactContent: strings.Replace(string(catalog), "metadata:\n", "metadata:\n  gemara-version: v0.2.0\n", 1)},
+			wantDeclared: "v0.2.0",
+			wantTarget:   "v0.2.0",
+			wantValid:    true,
+		},
+		{
+			name:         "older apiVersion needs an upgrade",
+			input:        InputCheckSchemaCompatibility{ArtifactContent: "apiVersion: gemara/0.1.0\n" + string(catalog)},
+			wantDeclared: "v0.1.0",
+			wantTarget:   "v0.2.0",
+			wantUpgrade:  true,
+			wantBreaking: []SchemaChange{
+				{Path: "$.title", Change: schemaChangeRequired, To: "string"},
+				{Path: "$.controls[*].legacy-id", Change: schemaChangeRemoved, From: "string"},
+			},
+			wantAdditions: []SchemaChange{
+				{Path: "$.controls[*].guideline-mappings", Change: schemaChangeAdded, To: "list"},
+			},
+			wantValid: true,
+		},
+		{
+			name:         "explicit target",
+			input:        InputCheckSchemaCompatibility{ArtifactContent: "apiVersion: v0.2.0\n" + string(catalog), TargetVersion: "v0.1.0"},
+			wantDeclared: "v0.2.0",
+			wantTarget:   "v0.1.0",
+			wantBreaking: []SchemaChange{
+				{Path: "$.controls[*].legacy-id", Change: schemaChangeRequired, To: "string"},
+				{Path: "$.controls[*].guideline-mappings", Change: schemaChangeRemoved, From: "list"},
+				{Path: "$.title", Change: schemaChangeRemoved, From: "string"},
+			},
+			wantAdditions: []SchemaChange{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := CheckSchemaCompatibility(context.Background(), nil, tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "#ControlCatalog", output.Definition)
+			assert.Equal(t, []string{"v0.1.0", "v0.2.0-rc.1", "v0.2.0"}, output.AvailableVersions)
+			assert.Equal(t, tt.wantDeclared, output.DeclaredVersion)
+			assert.Equal(t, tt.wantTarget, output.TargetVersion)
+			assert.Equal(t, tt.wantUpgrade, output.UpgradeNeeded)
+			assert.Equal(t, tt.wantValid, output.ValidAgainstTarget, output.Errors)
+			if tt.wantBreaking != nil {
+				assert.ElementsMatch(t, tt.wantBreaking, output.BreakingChanges)
+				assert.ElementsMatch(t, tt.wantAdditions, output.Additions)
+			} else {
+				assert.Empty(t, output.BreakingChanges)
+			}
+			assert.NotEmpty(t, output.Message)
+		})
+	}
+}
+
+func TestCompareSemver(t *testing.T) {
+	assert.Equal(t, -1, compareSemver("v0.9.0", "v0.10.0"))
+	assert.Equal(t, 0, compareSemver("0.7.0", "v0.7.0"))
+	assert.Equal(t, -1, compareSemver("v1.0.0-rc.1", "v1.0.0"))
+	assert.Equal(t, 1, compareSemver("v1.0.0", "latest"))
+}
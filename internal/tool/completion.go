@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxCompletionValues is the most values a completion may return.
+const maxCompletionValues = 100
+
+// HandleCompletion completes prompt and resource template arguments. MCP only
+// defines completion for those, so candidates are chosen by argument name and
+// the same names used by tool arguments complete identically:
+//
+//   - definition: schema definitions (e.g., #ControlCatalog); without the # for
+//     gemara://schema/ and gemara://examples/ templates
+//   - term: lexicon terms
+//   - control, control_id, controls: control IDs in workspace catalogs
+//   - n: example numbers of the definition already chosen
+func HandleCompletion(ctx context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	var candidates []string
+	switch req.Params.Argument.Name {
+	case "definition":
+		candidates = definitionCompletions(ctx, req.Params.Ref)
+	case "term":
+		candidates = termCompletions(ctx)
+	case "control", "control_id", "controls":
+		candidates = controlCompletions(ctx)
+	case "n":
+		candidates = exampleNumberCompletions(req.Params.Context)
+	}
+	return completionResult(candidates, req.Params.Argument.Value), nil
+}
+
+// completionResult filters candidates to those starting with value, followed
+// by those merely containing it, ignoring case.
+func completionResult(candidates []string, value string) *mcp.CompleteResult {
+	value = strings.ToLower(value)
+	var prefixed, contained []string
+	seen := map[string]bool{}
+	for _, c := range candidates {
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+		lower := strings.ToLower(c)
+		switch {
+		case strings.HasPrefix(lower, value):
+			prefixed = append(prefixed, c)
+		case strings.Contains(lower, value):
+			contained = append(contained, c)
+		}
+	}
+	sort.Strings(prefixed)
+	sort.Strings(contained)
+	values := append(prefixed, contained...)
+
+	result := &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{Values: []string{}, Total: len(values)}}
+	if len(values) > maxCompletionValues {
+		values = values[:maxCompletionValues]
+		result.Completion.HasMore = true
+	}
+	if values != nil {
+		result.Completion.Values = values
+	}
+	return result
+}
+
+// definitionCompletions lists the struct definitions of the schema. Example
+// resources only exist for some definitions, so those are listed instead for
+// the examples templates.
+func definitionCompletions(ctx context.Context, ref *mcp.CompleteReference) []string {
+	if ref != nil && strings.HasPrefix(ref.URI, examplesResourcePrefix) {
+		examples, err := listExamples()
+		if err != nil {
+			return nil
+		}
+		definitions := make([]string, 0, len(examples))
+		for _, e := range examples {
+			definitions = append(definitions, e.Definition)
+		}
+		return definitions
+	}
+
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil
+	}
+	definitions := schemaDefinitions(schema)
+	if ref != nil && strings.HasPrefix(ref.URI, schemaResourcePrefix) {
+		for i, d := range definitions {
+			definitions[i] = strings.TrimPrefix(d, "#")
+		}
+	}
+	return definitions
+}
+
+// schemaDefinitions lists the struct definitions of a schema, such as #ControlCatalog.
+func schemaDefinitions(schema *gemaraSchema) []string {
+	iter, err := schema.value.Fields(cue.Definitions(true))
+	if err != nil {
+		return nil
+	}
+	var definitions []string
+	for iter.Next() {
+		if iter.Selector().IsDefinition() && iter.Value().IncompleteKind() == cue.StructKind {
+			definitions = append(definitions, iter.Selector().String())
+		}
+	}
+	return definitions
+}
+
+// termCompletions lists the lexicon terms.
+func termCompletions(ctx context.Context) []string {
+	_, lexicon, err := GetLexicon(ctx, nil, InputGetLexicon{})
+	if err != nil {
+		return nil
+	}
+	terms := make([]string, 0, len(lexicon.Entries))
+	for _, e := range lexicon.Entries {
+		terms = append(terms, e.Term)
+	}
+	return terms
+}
+
+// controlCompletions lists the IDs of the controls in the workspace catalogs.
+func controlCompletions(ctx context.Context) []string {
+	root := workspaceRoot(ctx)
+	if root == "" {
+		return nil
+	}
+	files, err := findArtifactFiles(root)
+	if err != nil {
+		return nil
+	}
+	var ids []string
+	for _, file := range files {
+		content, err := readArtifactFile(ctx, file)
+		if err != nil {
+			continue
+		}
+		doc, err := parseArtifact(ctx, string(content))
+		if err != nil || artifactKind(doc) != "ControlCatalog" {
+			continue
+		}
+		controls, _ := doc["controls"].([]interface{})
+		for _, c := range controls {
+			if control, ok := c.(map[string]interface{}); ok {
+				if id, ok := control["id"].(string); ok && id != "" {
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+	return ids
+}
+
+// exampleNumberCompletions lists the example numbers of the definition chosen
+// earlier in a gemara://examples/{definition}/{n} URI.
+func exampleNumberCompletions(completeContext *mcp.CompleteContext) []string {
+	if completeContext == nil {
+		return nil
+	}
+	files, err := exampleFiles(strings.TrimPrefix(completeContext.Arguments["definition"], "#"))
+	if err != nil {
+		return nil
+	}
+	numbers := make([]string, 0, len(files))
+	for i := range files {
+		numbers = append(numbers, strconv.Itoa(i+1))
+	}
+	return numbers
+}
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleCompletion(t *testing.T) {
+	useTestSchema(t)
+	useTestLexicon(t, "- term: Control\n  definition: A safeguard.\n- term: Control Catalog\n  definition: Controls.\n- term: Policy\n  definition: Rules.\n")
+
+	catalog, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err, "should read test catalog")
+	root := t.TempDir()
+	writeTestFile(t, root, "catalog.yaml", string(catalog))
+	writeTestFile(t, root, "notes.yaml", "title: not an artifact\n")
+	ctx := withTestConfig(func(c *Config) { c.WorkspaceRoot = root })
+
+	prompt := &mcp.CompleteReference{Type: "ref/prompt", Name: "author"}
+	tests := []struct {
+		name     string
+		ref      *mcp.CompleteReference
+		argument string
+		value    string
+		context  map[string]string
+		want     []string
+		contains []string
+	}{
+		{name: "definition", ref: prompt, argument: "definition", value: "#Control", want: []string{"#Control", "#ControlCatalog", "#ControlEvaluation", "#ControlPlan"}},
+		{name: "definition ignores case and #", ref: prompt, argument: "definition", value: "evaluationl", want: []string{"#EvaluationLog"}},
+		{name: "schema template", ref: &mcp.CompleteReference{Type: "ref/resource", URI: schemaResourceURITemplate}, argument: "definition", value: "Pol", want: []string{"Policy"}},
+		{name: "examples template", ref: &mcp.CompleteReference{Type: "ref/resource", URI: examplesResourceURITemplate}, argument: "definition", value: "", want: []string{"ControlCatalog", "EvaluationLog", "GuidanceDocument", "Policy"}},
+		{name: "example number", ref: &mcp.CompleteReference{Type: "ref/resource", URI: examplesResourceURITemplate}, argument: "n", context: map[string]string{"definition": "Policy"}, contains: []string{"1"}},
+		{name: "term prefix before substring", ref: prompt, argument: "term", value: "cat", want: []string{"Control Catalog"}},
+		{name: "term", ref: prompt, argument: "term", value: "control", want: []string{"Control", "Control Catalog"}},
+		{name: "control", ref: prompt, argument: "control_id", value: "ccc.c0", want: []string{"CCC.C01", "CCC.C06", "CCC.C08", "CCC.C09"}},
+		{name: "unknown argument", ref: prompt, argument: "output", value: "", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := &mcp.CompleteParams{Ref: tt.ref, Argument: mcp.CompleteParamsArgument{Name: tt.argument, Value: tt.value}}
+			if tt.context != nil {
+				params.Context = &mcp.CompleteContext{Arguments: tt.context}
+			}
+			result, err := HandleCompletion(ctx, &mcp.CompleteRequest{Params: params})
+			require.NoError(t, err, "should complete")
+			if tt.want != nil {
+				assert.Equal(t, tt.want, result.Completion.Values, "values should match")
+			}
+			for _, v := range tt.contains {
+				assert.Contains(t, result.Completion.Values, v, "values should contain %s", v)
+			}
+			assert.Equal(t, len(result.Completion.Values), result.Completion.Total, "total should count the values")
+		})
+	}
+}
+
+func TestCompletionResultLimit(t *testing.T) {
+	candidates := make([]string, 0, maxCompletionValues+20)
+	for i := 0; i < maxCompletionValues+20; i++ {
+		candidates = append(candidates, fmt.Sprintf("C%03d", i))
+	}
+	result := completionResult(candidates, "c")
+	assert.Len(t, result.Completion.Values, maxCompletionValues, "should cap the values")
+	assert.Equal(t, maxCompletionValues+20, result.Completion.Total, "should report every match")
+	assert.True(t, result.Completion.HasMore, "should report more values")
+}
@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Config is the configuration of a server: the workspace its tools read, the
+// limits applied to artifacts, how results are rendered, and the external
+// tools and services its tools use. The serve command builds one from its
+// flags and passes it to the mode, which makes it available to every handler
+// through the request context.
+type Config struct {
+	// WorkspaceRoot is the directory file:// artifact URIs must resolve
+	// within. File URIs are rejected when it is empty. Tenants of a shared
+	// deployment have their own workspace root instead.
+	WorkspaceRoot string
+	// MaxArtifactSize is the largest artifact, in bytes, passed inline or read by URI.
+	MaxArtifactSize int64
+	// MaxArtifactDepth is how deeply mappings and sequences may nest in an artifact.
+	MaxArtifactDepth int
+	// MaxAliasExpansion is how many nodes the aliases in an artifact may expand
+	// to in total, which stops "billion laughs" documents before they are decoded.
+	MaxAliasExpansion int
+	// ValidationTimeout bounds CUE evaluation of an artifact; zero disables it.
+	ValidationTimeout time.Duration
+	// ToolTimeout is how long a tool call may run before it fails; zero
+	// disables the limit.
+	ToolTimeout time.Duration
+
+	// TemplateIndexURL is where ListTemplates and FetchTemplate read the
+	// template index.
+	TemplateIndexURL string
+	// Audience is how tool results are rendered (AudienceAgent or AudienceHuman).
+	Audience string
+	// EventLevel is the least severe server event sent to clients as an MCP
+	// logging notification; clients can raise it further for their session
+	// with logging/setLevel.
+	EventLevel mcp.LoggingLevel
+	// Version is reported in the provenance of every tool result.
+	Version string
+
+	// DryRun makes every tool that writes files or external systems report
+	// the changes it would make instead of making them, so that an agent can
+	// propose changes for a human to approve. Write tools also take a
+	// per-call dry_run input.
+	DryRun bool
+	// RequireApproval makes tools that write files or external systems ask
+	// the user to approve their changes, through MCP elicitation, before
+	// making them. Calls from clients that do not support elicitation are
+	// refused.
+	RequireApproval bool
+
+	// FindingSLA is the remediation window for findings of each severity.
+	FindingSLA map[string]time.Duration
+	// Federation are the federated catalogs served as resources.
+	Federation []FederatedCatalog
+	// Privacy limits what the posture resource, metrics, and posture
+	// webhooks reveal about individual projects.
+	Privacy PrivacyConfig
+
+	HTTP           HTTPConfig
+	Registry       RegistryConfig
+	GitHub         GitHubConfig
+	IssueTracker   IssueTrackerConfig
+	Cosign         CosignConfig
+	ORAS           ORASConfig
+	SOPS           SOPSConfig
+	SemanticSearch SemanticSearchConfig
+	Webhooks       WebhookConfig
+	// Storage persists fetched lexicons, template indexes, and catalogs so
+	// they can be served after a restart while upstream is unavailable. Nil
+	// keeps caches in memory only.
+	Storage Storage
+}
+
+// NewConfig returns the default configuration, which serves no workspace.
+func NewConfig() *Config {
+	return &Config{
+		MaxArtifactSize:   DefaultMaxArtifactSize,
+		MaxArtifactDepth:  DefaultMaxArtifactDepth,
+		MaxAliasExpansion: DefaultMaxAliasExpansion,
+		ValidationTimeout: DefaultValidationTimeout,
+		ToolTimeout:       DefaultToolTimeout,
+		TemplateIndexURL:  DefaultTemplateIndexURL,
+		Audience:          AudienceAgent,
+		EventLevel:        "info",
+		Version:           unknownVersion,
+		FindingSLA:        DefaultFindingSLA(),
+		HTTP:              DefaultHTTPConfig(),
+		GitHub:            GitHubConfig{APIURL: DefaultGitHubAPIURL},
+		IssueTracker:      IssueTrackerConfig{JiraIssueType: DefaultJiraIssueType},
+		Cosign:            CosignConfig{Binary: "cosign"},
+		SOPS:              SOPSConfig{Binary: "sops"},
+		SemanticSearch:    SemanticSearchConfig{Model: DefaultEmbeddingModel, APIURL: DefaultEmbeddingAPIURL},
+		Webhooks:          WebhookConfig{Timeout: DefaultWebhookTimeout},
+	}
+}
+
+// defaultConfig is used by requests whose context carries no configuration.
+// It is never modified.
+var defaultConfig = NewConfig()
+
+type configKey struct{}
+
+// WithConfig returns a context whose handlers use the given configuration.
+// The serve command uses it for the work it starts outside of requests, such
+// as watching the workspace and refreshing caches.
+func WithConfig(ctx context.Context, c *Config) context.Context {
+	return context.WithValue(ctx, configKey{}, c)
+}
+
+// serverConfig returns the configuration of ctx, or the default configuration.
+func serverConfig(ctx context.Context) *Config {
+	if c, ok := ctx.Value(configKey{}).(*Config); ok && c != nil {
+		return c
+	}
+	return defaultConfig
+}
+
+// configMiddleware makes c the configuration of every request to a server.
+func configMiddleware(c *Config) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			return next(WithConfig(ctx, c), method, req)
+		}
+	}
+}
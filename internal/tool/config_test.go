@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withTestConfig returns a context whose configuration is the default one
+// changed by configure.
+func withTestConfig(configure func(*Config)) context.Context {
+	config := NewConfig()
+	configure(config)
+	return WithConfig(context.Background(), config)
+}
+
+func TestServerConfig(t *testing.T) {
+	assert.Same(t, defaultConfig, serverConfig(context.Background()), "requests without a configuration use the default")
+	assert.Equal(t, DefaultMaxArtifactSize, serverConfig(context.Background()).MaxArtifactSize)
+
+	config := NewConfig()
+	config.WorkspaceRoot = t.TempDir()
+	assert.Same(t, config, serverConfig(WithConfig(context.Background(), config)))
+	assert.Same(t, defaultConfig, serverConfig(WithConfig(context.Background(), nil)))
+	assert.NotSame(t, NewConfig(), NewConfig(), "each configuration should be independent")
+}
+
+func TestConfigMiddleware(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "catalog.yaml", baselineCatalog)
+	config := NewConfig()
+	config.WorkspaceRoot = dir
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server"}, nil)
+	AdvisoryMode{Config: config}.Register(server)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ctx := context.Background()
+	_, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err, "server should connect")
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, clientTransport, nil)
+	require.NoError(t, err, "client should connect")
+	defer session.Close()
+
+	// Searching only succeeds with the mode's workspace root
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      MetadataSearchArtifacts.Name,
+		Arguments: map[string]interface{}{"query": "catalog"},
+	})
+	require.NoError(t, err)
+	assert.False(t, result.IsError, "the mode's configuration should reach the handler")
+}
@@ -0,0 +1,189 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MetadataRunConformanceSuite describes the RunConformanceSuite tool.
+var MetadataRunConformanceSuite = &mcp.Tool{
+	Name: "run_conformance_suite",
+	Description: "Check a directory of Gemara artifacts produced by another tool against the Gemara schema and " +
+		"semantic rules, and emit a conformance report.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"directory"},
+		"properties": map[string]interface{}{
+			"directory": map[string]interface{}{
+				"type":        "string",
+				"description": "Directory containing the YAML artifacts to check (searched recursively)",
+			},
+		},
+	},
+}
+
+// InputRunConformanceSuite is the input for the RunConformanceSuite tool.
+type InputRunConformanceSuite struct {
+	Directory string `json:"directory"`
+}
+
+// ConformanceResult is the conformance outcome for a single artifact file.
+type ConformanceResult struct {
+	Path         string        `json:"path"`
+	Definition   string        `json:"definition,omitempty"`
+	SchemaValid  bool          `json:"schema_valid"`
+	SchemaErrors []string      `json:"schema_errors,omitempty"`
+	Findings     []LintFinding `json:"findings,omitempty"`
+	Conformant   bool          `json:"conformant"`
+}
+
+// OutputRunConformanceSuite is the output for the RunConformanceSuite tool.
+type OutputRunConformanceSuite struct {
+	Directory     string              `json:"directory"`
+	ModuleVersion string              `json:"module_version"`
+	Conformant    bool                `json:"conformant"`
+	Total         int                 `json:"total"`
+	Passed        int                 `json:"passed"`
+	Failed        int                 `json:"failed"`
+	Results       []ConformanceResult `json:"results"`
+}
+
+// RunConformanceSuite checks every artifact in a directory for conformance.
+func RunConformanceSuite(ctx context.Context, _ *mcp.CallToolRequest, input InputRunConformanceSuite) (*mcp.CallToolResult, OutputRunConformanceSuite, error) {
+	if input.Directory == "" {
+		return nil, OutputRunConformanceSuite{}, fmt.Errorf("directory is required")
+	}
+	if _, err := workspaceDirectory(ctx, input.Directory); err != nil {
+		return nil, OutputRunConformanceSuite{}, err
+	}
+
+	info, err := os.Stat(input.Directory)
+	if err != nil {
+		return nil, OutputRunConformanceSuite{}, fmt.Errorf("failed to read directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, OutputRunConformanceSuite{}, fmt.Errorf("%s is not a directory", input.Directory)
+	}
+
+	files, err := findArtifactFiles(input.Directory)
+	if err != nil {
+		return nil, OutputRunConformanceSuite{}, err
+	}
+
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputRunConformanceSuite{}, err
+	}
+
+	output := OutputRunConformanceSuite{
+		Directory:     input.Directory,
+		ModuleVersion: schema.version,
+		Results:       []ConformanceResult{},
+	}
+	for _, file := range files {
+		result, err := checkConformance(ctx, schema, input.Directory, file)
+		if err != nil {
+			return nil, OutputRunConformanceSuite{}, err
+		}
+		output.Results = append(output.Results, result)
+		if result.Conformant {
+			output.Passed++
+		} else {
+			output.Failed++
+		}
+	}
+	output.Total = len(output.Results)
+	output.Conformant = output.Total > 0 && output.Failed == 0
+
+	return nil, output, nil
+}
+
+// checkConformance validates one artifact file against the schema and lint rules.
+func checkConformance(ctx context.Context, schema *gemaraSchema, root, file string) (ConformanceResult, error) {
+	rel, err := filepath.Rel(root, file)
+	if err != nil {
+		rel = file
+	}
+	result := ConformanceResult{Path: filepath.ToSlash(rel)}
+
+	content, err := readArtifactFile(ctx, file)
+	if err != nil {
+		return ConformanceResult{}, err
+	}
+
+	doc, err := parseArtifact(ctx, string(content))
+	if err != nil {
+		result.SchemaErrors = []string{err.Error()}
+		return result, nil
+	}
+
+	kind := artifactKind(doc)
+	if kind == "" {
+		result.SchemaErrors = []string{"unable to determine the artifact definition"}
+		return result, nil
+	}
+	result.Definition = "#" + kind
+
+	validation, err := validateAgainstSchema(schema, result.Definition, string(content))
+	if err != nil {
+		result.SchemaErrors = []string{err.Error()}
+		return result, nil
+	}
+	result.SchemaValid = validation.Valid
+	result.SchemaErrors = validation.Errors
+
+	result.Findings = lintDocument(doc, lintRules)
+	result.Conformant = result.SchemaValid && !hasSeverity(result.Findings, severityError)
+	return result, nil
+}
+
+// findArtifactFiles returns the YAML files under dir in lexical order.
+func findArtifactFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && skipDirectory(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml":
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// skipDirectory reports whether a directory holds no artifacts of the
+// workspace: hidden directories, such as .git, and vendored dependencies.
+func skipDirectory(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules"
+}
+
+// hasSeverity reports whether any finding has the given severity.
+func hasSeverity(findings []LintFinding, severity string) bool {
+	for _, f := range findings {
+		if f.Severity == severity {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunConformanceSuite(t *testing.T) {
+	useTestSchema(t)
+
+	dir := t.TempDir()
+	writeTestFile(t, dir, "catalog.yaml", readExample(t, "ControlCatalog", 1))
+	writeTestFile(t, dir, "nested/policy.yml", readExample(t, "Policy", 1))
+	writeTestFile(t, dir, "invalid-log.yaml", "metadata:\n  id: X\nevaluations:\n  - name: missing fields\n")
+	writeTestFile(t, dir, "unknown.yaml", "kind: ConfigMap\n")
+	writeTestFile(t, dir, "notes.txt", "not an artifact")
+	writeTestFile(t, dir, ".hidden/catalog.yaml", "ignored: true\n")
+
+	tests := []struct {
+		name           string
+		input          InputRunConformanceSuite
+		wantErr        bool
+		errContains    string
+		validateOutput func(t *testing.T, output OutputRunConformanceSuite)
+	}{
+		{
+			name:        "missing directory",
+			input:       InputRunConformanceSuite{},
+			wantErr:     true,
+			errContains: "directory is required",
+		},
+		{
+			name:        "directory does not exist",
+			input:       InputRunConformanceSuite{Directory: filepath.Join(dir, "missing")},
+			wantErr:     true,
+			errContains: "failed to read directory",
+		},
+		{
+			name:        "path is a file",
+			input:       InputRunConformanceSuite{Directory: filepath.Join(dir, "catalog.yaml")},
+			wantErr:     true,
+			errContains: "is not a directory",
+		},
+		{
+			name:  "mixed directory",
+			input: InputRunConformanceSuite{Directory: dir},
+			validateOutput: func(t *testing.T, output OutputRunConformanceSuite) {
+				assert.False(t, output.Conformant, "suite should not be conformant")
+				assert.Equal(t, testSchemaVersion, output.ModuleVersion)
+				assert.Equal(t, 4, output.Total, "only visible YAML files should be checked")
+				assert.Equal(t, 2, output.Passed)
+				assert.Equal(t, 2, output.Failed)
+
+				results := map[string]ConformanceResult{}
+				for _, r := range output.Results {
+					results[r.Path] = r
+				}
+				assert.True(t, results["catalog.yaml"].Conformant)
+				assert.Equal(t, "#Policy", results["nested/policy.yml"].Definition)
+				assert.False(t, results["invalid-log.yaml"].SchemaValid)
+				assert.NotEmpty(t, results["invalid-log.yaml"].SchemaErrors)
+				assert.Contains(t, results["unknown.yaml"].SchemaErrors, "unable to determine the artifact definition")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := RunConformanceSuite(context.Background(), nil, tt.input)
+
+			if tt.wantErr {
+				require.Error(t, err, "should return error")
+				assert.Contains(t, err.Error(), tt.errContains, "error should contain expected message")
+				return
+			}
+
+			require.NoError(t, err, "should not return error")
+			if tt.validateOutput != nil {
+				tt.validateOutput(t, output)
+			}
+		})
+	}
+}
+
+// writeTestFile writes content to a file below dir, creating parent directories.
+func writeTestFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755), "should create parent directory")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600), "should write test file")
+}
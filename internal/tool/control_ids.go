@@ -0,0 +1,626 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultIDPadding = 2
+	maxIDAllocation  = 100
+)
+
+// idSchemeProperties are the input properties of a control ID scheme.
+func idSchemeProperties() map[string]interface{} {
+	return map[string]interface{}{
+		"prefix": map[string]interface{}{
+			"type":        "string",
+			"description": "Prefix of control IDs (default: the prefix most existing control IDs use, such as CCC in CCC.C01)",
+		},
+		"padding": map[string]interface{}{
+			"type":        "integer",
+			"description": fmt.Sprintf("Digits control and requirement numbers are zero-padded to (default: %d)", defaultIDPadding),
+		},
+		"family_grouping": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Put a family code in control IDs and number controls per family, as in ORG.DP.C01 (default: false)",
+		},
+		"family_codes": map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": map[string]interface{}{"type": "string"},
+			"description":          "Code per family id used with family_grouping (default: the initials of a multi-word family id, or the id upper-cased)",
+		},
+	}
+}
+
+// controlIDScheme formats control IDs as <prefix>[.<family code>].C<n> and
+// assessment requirement IDs as <control>.TR<n>, as generated drafts do.
+type controlIDScheme struct {
+	prefix         string
+	padding        int
+	familyGrouping bool
+	familyCodes    map[string]string
+}
+
+// newControlIDScheme completes a scheme from the input and the catalog's controls.
+func newControlIDScheme(prefix string, padding int, grouping bool, codes map[string]string, catalog map[string]interface{}) (controlIDScheme, error) {
+	if padding < 0 || padding > 6 {
+		return controlIDScheme{}, fmt.Errorf("padding must be between 0 and 6")
+	}
+	if padding == 0 {
+		padding = defaultIDPadding
+	}
+	if prefix == "" {
+		prefix = inferIDPrefix(catalog)
+	}
+	scheme := controlIDScheme{prefix: prefix, padding: padding, familyGrouping: grouping, familyCodes: map[string]string{}}
+	if !grouping {
+		return scheme, nil
+	}
+
+	families := map[string]bool{}
+	for _, list := range []string{"families", "controls"} {
+		items, _ := catalog[list].([]interface{})
+		for _, item := range items {
+			m, _ := item.(map[string]interface{})
+			id := entityID(item)
+			if list == "controls" {
+				id, _ = m["family"].(string)
+			}
+			if id != "" {
+				families[id] = true
+			}
+		}
+	}
+	owners := map[string]string{}
+	ids := make([]string, 0, len(families))
+	for id := range families {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		code := codes[id]
+		if code == "" {
+			code = familyCode(id)
+		}
+		if other, taken := owners[code]; taken {
+			return controlIDScheme{}, fmt.Errorf("families %s and %s both have the code %s; set family_codes", other, id, code)
+		}
+		owners[code] = id
+		scheme.familyCodes[id] = code
+	}
+	return scheme, nil
+}
+
+// group returns the part of a control ID before its number.
+func (s controlIDScheme) group(family string) (string, error) {
+	if !s.familyGrouping {
+		return s.prefix, nil
+	}
+	code, ok := s.familyCodes[family]
+	if !ok {
+		return "", fmt.Errorf("family %s is not in the catalog", family)
+	}
+	return s.prefix + "." + code, nil
+}
+
+// controlID returns the nth control ID of a group.
+func (s controlIDScheme) controlID(group string, n int) string {
+	return fmt.Sprintf("%s.C%0*d", group, s.padding, n)
+}
+
+// requirementID returns the nth assessment requirement ID of a control.
+func (s controlIDScheme) requirementID(control string, n int) string {
+	return fmt.Sprintf("%s.TR%0*d", control, s.padding, n)
+}
+
+// familyCode derives a short code from a family id: the initials of a
+// multi-word id such as data-protection, or the id upper-cased.
+func familyCode(id string) string {
+	words := applicabilityTokens(id)
+	if len(words) == 0 {
+		return strings.ToUpper(id)
+	}
+	if len(words) == 1 {
+		return strings.ToUpper(words[0])
+	}
+	var code strings.Builder
+	for _, w := range words {
+		code.WriteString(strings.ToUpper(w[:1]))
+	}
+	return code.String()
+}
+
+// inferIDPrefix returns the first segment most control IDs share.
+func inferIDPrefix(catalog map[string]interface{}) string {
+	counts := map[string]int{}
+	controls, _ := catalog["controls"].([]interface{})
+	for _, c := range controls {
+		if id := entityID(c); strings.Contains(id, ".") && !isPlaceholderID(id) {
+			counts[id[:strings.Index(id, ".")]]++
+		}
+	}
+	best := ""
+	for prefix, n := range counts {
+		if n > counts[best] || n == counts[best] && prefix < best {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return skeletonPrefix(metadataID(catalog))
+	}
+	return best
+}
+
+// isPlaceholderID reports whether an ID is missing or left for the author.
+func isPlaceholderID(id string) bool {
+	return id == "" || strings.EqualFold(id, skeletonPlaceholder)
+}
+
+// IDMapping records an ID assigned or changed by allocation or renumbering.
+type IDMapping struct {
+	Old  string `json:"old"`
+	New  string `json:"new"`
+	Kind string `json:"kind"`
+}
+
+// MetadataAllocateControlIDs describes the AllocateControlIDs tool.
+var MetadataAllocateControlIDs = &mcp.Tool{
+	Name: "allocate_control_ids",
+	Description: "Allocate the next free control IDs in a ControlCatalog following an ID scheme (prefix, zero-padding, " +
+		"and optional family grouping), and assign IDs to controls and assessment requirements whose id is missing or " +
+		"TODO. Numbers continue after the highest one in use, so retired IDs are never reused. Nothing is written to disk.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": func() map[string]interface{} {
+			properties := artifactSourceProperties("allocate IDs in")
+			for name, property := range idSchemeProperties() {
+				properties[name] = property
+			}
+			properties["family"] = map[string]interface{}{
+				"type":        "string",
+				"description": "Family id the new controls belong to (required with family_grouping)",
+			}
+			properties["count"] = map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Number of new control IDs to allocate, up to %d (default: 1; 0 only assigns missing IDs)", maxIDAllocation),
+			}
+			return properties
+		}(),
+	},
+}
+
+// InputAllocateControlIDs is the input for the AllocateControlIDs tool.
+type InputAllocateControlIDs struct {
+	ArtifactContent string            `json:"artifact_content,omitempty"`
+	ArtifactURI     string            `json:"artifact_uri,omitempty"`
+	Prefix          string            `json:"prefix,omitempty"`
+	Padding         int               `json:"padding,omitempty"`
+	FamilyGrouping  bool              `json:"family_grouping,omitempty"`
+	FamilyCodes     map[string]string `json:"family_codes,omitempty"`
+	Family          string            `json:"family,omitempty"`
+	Count           *int              `json:"count,omitempty"`
+}
+
+// OutputAllocateControlIDs is the output for the AllocateControlIDs tool.
+type OutputAllocateControlIDs struct {
+	IDs []string `json:"ids"`
+	// Assigned lists the missing or TODO IDs given an ID in Content.
+	Assigned []IDMapping `json:"assigned"`
+	// Content is the catalog with the assigned IDs, when any were assigned.
+	Content string `json:"content,omitempty"`
+	Message string `json:"message"`
+}
+
+// AllocateControlIDs allocates new control IDs and fills in missing ones.
+func AllocateControlIDs(ctx context.Context, _ *mcp.CallToolRequest, input InputAllocateControlIDs) (*mcp.CallToolResult, OutputAllocateControlIDs, error) {
+	count := 1
+	if input.Count != nil {
+		count = *input.Count
+	}
+	if count < 0 || count > maxIDAllocation {
+		return nil, OutputAllocateControlIDs{}, fmt.Errorf("count must be between 0 and %d", maxIDAllocation)
+	}
+	content, err := artifactInputContent(ctx, input.ArtifactContent, input.ArtifactURI)
+	if err != nil {
+		return nil, OutputAllocateControlIDs{}, err
+	}
+	parsed, err := parseArtifact(ctx, content)
+	if err != nil {
+		return nil, OutputAllocateControlIDs{}, err
+	}
+	if artifactKind(parsed) != "ControlCatalog" {
+		return nil, OutputAllocateControlIDs{}, fmt.Errorf("allocating control IDs requires a ControlCatalog")
+	}
+	scheme, err := newControlIDScheme(input.Prefix, input.Padding, input.FamilyGrouping, input.FamilyCodes, parsed)
+	if err != nil {
+		return nil, OutputAllocateControlIDs{}, err
+	}
+	if input.FamilyGrouping && input.Family == "" && count > 0 {
+		return nil, OutputAllocateControlIDs{}, fmt.Errorf("family is required with family_grouping")
+	}
+	var doc yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions([]byte(content), &doc, yaml.UseOrderedMap()); err != nil {
+		return nil, OutputAllocateControlIDs{}, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	// The next number of each group follows the highest in use
+	used := map[string]bool{}
+	next := map[string]int{}
+	controls := mapValueList(doc, "controls")
+	for _, c := range controls {
+		control, _ := c.(yaml.MapSlice)
+		id := orderedID(control)
+		used[id] = true
+		for _, r := range mapValueList(control, "assessment-requirements") {
+			used[orderedID(r)] = true
+		}
+		if i := strings.LastIndex(id, ".C"); i > 0 {
+			if n, err := strconv.Atoi(id[i+2:]); err == nil && n >= next[id[:i]] {
+				next[id[:i]] = n + 1
+			}
+		}
+	}
+	allocate := func(family string) (string, error) {
+		group, err := scheme.group(family)
+		if err != nil {
+			return "", err
+		}
+		if next[group] == 0 {
+			next[group] = 1
+		}
+		id := scheme.controlID(group, next[group])
+		next[group]++
+		used[id] = true
+		return id, nil
+	}
+
+	output := OutputAllocateControlIDs{IDs: []string{}, Assigned: []IDMapping{}}
+	for i, c := range controls {
+		control, _ := c.(yaml.MapSlice)
+		id := orderedID(control)
+		if isPlaceholderID(id) {
+			newID, err := allocate(fmt.Sprint(mapValue(control, "family")))
+			if err != nil {
+				return nil, OutputAllocateControlIDs{}, err
+			}
+			output.Assigned = append(output.Assigned, IDMapping{Old: id, New: newID, Kind: "control"})
+			control, id = setOrderedID(control, newID), newID
+		}
+		requirements := mapValueList(control, "assessment-requirements")
+		n := 1
+		for j, r := range requirements {
+			if !isPlaceholderID(orderedID(r)) {
+				continue
+			}
+			for used[scheme.requirementID(id, n)] {
+				n++
+			}
+			newID := scheme.requirementID(id, n)
+			used[newID] = true
+			output.Assigned = append(output.Assigned, IDMapping{Old: orderedID(r), New: newID, Kind: "requirement"})
+			requirements[j] = setOrderedID(r.(yaml.MapSlice), newID)
+		}
+		if requirements != nil {
+			control = setMapValue(control, "assessment-requirements", requirements)
+		}
+		controls[i] = control
+	}
+	for len(output.IDs) < count {
+		id, err := allocate(input.Family)
+		if err != nil {
+			return nil, OutputAllocateControlIDs{}, err
+		}
+		output.IDs = append(output.IDs, id)
+	}
+
+	if len(output.Assigned) > 0 {
+		doc = setMapValue(doc, "controls", controls)
+		out, err := yaml.MarshalWithOptions(doc, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+		if err != nil {
+			return nil, OutputAllocateControlIDs{}, fmt.Errorf("failed to encode catalog: %w", err)
+		}
+		output.Content = string(out)
+	}
+	output.Message = fmt.Sprintf("Allocated %d new control ID(s) and assigned %d missing ID(s)", len(output.IDs), len(output.Assigned))
+	if len(output.IDs) > 0 {
+		output.Message += fmt.Sprintf("; next: %s", output.IDs[0])
+	}
+	return nil, output, nil
+}
+
+// setOrderedID sets the id of an ordered mapping, adding it first if absent.
+func setOrderedID(m yaml.MapSlice, id string) yaml.MapSlice {
+	m = append(yaml.MapSlice{}, m...)
+	if mapValue(m, "id") == nil {
+		return append(yaml.MapSlice{{Key: "id", Value: id}}, m...)
+	}
+	return setMapValue(m, "id", id)
+}
+
+// MetadataRenumberCatalog describes the RenumberCatalog tool.
+var MetadataRenumberCatalog = &mcp.Tool{
+	Name: "renumber_catalog",
+	Description: "Renumber the controls and assessment requirements of a ControlCatalog in document order following an " +
+		"ID scheme (prefix, zero-padding, and optional family grouping), rewriting every reference to the old IDs in " +
+		"the catalog and in any related artifacts given, such as policies and evaluation plans. Returns the renumbered " +
+		"catalog, validated against the schema, and a table of old to new IDs. Nothing is written to disk.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": func() map[string]interface{} {
+			properties := artifactSourceProperties("renumber")
+			for name, property := range idSchemeProperties() {
+				properties[name] = property
+			}
+			properties["related_artifacts"] = map[string]interface{}{
+				"type":        "array",
+				"description": "Artifacts referencing the catalog's IDs to rewrite with the same mapping, each with a name and YAML content",
+				"items":       artifactInputSchema["items"],
+			}
+			return properties
+		}(),
+	},
+}
+
+// InputRenumberCatalog is the input for the RenumberCatalog tool.
+type InputRenumberCatalog struct {
+	ArtifactContent  string            `json:"artifact_content,omitempty"`
+	ArtifactURI      string            `json:"artifact_uri,omitempty"`
+	Prefix           string            `json:"prefix,omitempty"`
+	Padding          int               `json:"padding,omitempty"`
+	FamilyGrouping   bool              `json:"family_grouping,omitempty"`
+	FamilyCodes      map[string]string `json:"family_codes,omitempty"`
+	RelatedArtifacts []ArtifactInput   `json:"related_artifacts,omitempty"`
+}
+
+// RenumberedArtifact is a related artifact rewritten with the new IDs.
+type RenumberedArtifact struct {
+	Name       string `json:"name"`
+	Content    string `json:"content"`
+	References int    `json:"references"`
+	Mentions   int    `json:"mentions"`
+}
+
+// OutputRenumberCatalog is the output for the RenumberCatalog tool.
+type OutputRenumberCatalog struct {
+	RenumberedContent string      `json:"renumbered_content"`
+	Mapping           []IDMapping `json:"mapping"`
+	// MappingCSV is the mapping as a CSV table with old_id, new_id, and kind columns.
+	MappingCSV string `json:"mapping_csv"`
+	// References counts fields holding an old ID; Mentions counts old IDs
+	// rewritten inside prose.
+	References       int                  `json:"references"`
+	Mentions         int                  `json:"mentions"`
+	RelatedArtifacts []RenumberedArtifact `json:"related_artifacts,omitempty"`
+	Valid            bool                 `json:"valid"`
+	Errors           []string             `json:"errors,omitempty"`
+	Message          string               `json:"message"`
+}
+
+// RenumberCatalog renumbers a catalog and rewrites the references to it.
+func RenumberCatalog(ctx context.Context, _ *mcp.CallToolRequest, input InputRenumberCatalog) (*mcp.CallToolResult, OutputRenumberCatalog, error) {
+	content, err := artifactInputContent(ctx, input.ArtifactContent, input.ArtifactURI)
+	if err != nil {
+		return nil, OutputRenumberCatalog{}, err
+	}
+	parsed, err := parseArtifact(ctx, content)
+	if err != nil {
+		return nil, OutputRenumberCatalog{}, err
+	}
+	if artifactKind(parsed) != "ControlCatalog" {
+		return nil, OutputRenumberCatalog{}, fmt.Errorf("renumbering requires a ControlCatalog")
+	}
+	scheme, err := newControlIDScheme(input.Prefix, input.Padding, input.FamilyGrouping, input.FamilyCodes, parsed)
+	if err != nil {
+		return nil, OutputRenumberCatalog{}, err
+	}
+	var doc yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions([]byte(content), &doc, yaml.UseOrderedMap()); err != nil {
+		return nil, OutputRenumberCatalog{}, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	// Old IDs must be unique for references to them to be unambiguous
+	mapping := map[string]string{}
+	output := OutputRenumberCatalog{Mapping: []IDMapping{}}
+	seen := map[string]bool{}
+	counters := map[string]int{}
+	for _, c := range mapValueList(doc, "controls") {
+		control, _ := c.(yaml.MapSlice)
+		oldID := orderedID(control)
+		if isPlaceholderID(oldID) {
+			return nil, OutputRenumberCatalog{}, fmt.Errorf("a control has no id; assign IDs with allocate_control_ids first")
+		}
+		group, err := scheme.group(fmt.Sprint(mapValue(control, "family")))
+		if err != nil {
+			return nil, OutputRenumberCatalog{}, fmt.Errorf("control %s: %w", oldID, err)
+		}
+		counters[group]++
+		newID := scheme.controlID(group, counters[group])
+		if err := addIDMapping(&output, mapping, seen, oldID, newID, "control"); err != nil {
+			return nil, OutputRenumberCatalog{}, err
+		}
+		for i, r := range mapValueList(control, "assessment-requirements") {
+			oldRequirement := orderedID(r)
+			if isPlaceholderID(oldRequirement) {
+				return nil, OutputRenumberCatalog{}, fmt.Errorf("an assessment requirement of %s has no id; assign IDs with allocate_control_ids first", oldID)
+			}
+			if err := addIDMapping(&output, mapping, seen, oldRequirement, scheme.requirementID(newID, i+1), "requirement"); err != nil {
+				return nil, OutputRenumberCatalog{}, err
+			}
+		}
+	}
+	if len(seen) == 0 {
+		return nil, OutputRenumberCatalog{}, fmt.Errorf("catalog has no controls")
+	}
+
+	rewriter := newIDRewriter(mapping)
+	renumbered := rewriter.rewrite(doc, "", false).(yaml.MapSlice)
+	output.References, output.Mentions = rewriter.references, rewriter.mentions
+	out, err := yaml.MarshalWithOptions(renumbered, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return nil, OutputRenumberCatalog{}, fmt.Errorf("failed to encode renumbered catalog: %w", err)
+	}
+	output.RenumberedContent = string(out)
+
+	for i, a := range input.RelatedArtifacts {
+		var related yaml.MapSlice
+		if err := yaml.UnmarshalWithOptions([]byte(a.Content), &related, yaml.UseOrderedMap()); err != nil {
+			return nil, OutputRenumberCatalog{}, &artifactError{Name: artifactName(a, i), Err: fmt.Errorf("failed to parse YAML: %w", err)}
+		}
+		// Entities of related artifacts keep their own IDs
+		rewriter := newIDRewriter(mapping)
+		out, err := yaml.MarshalWithOptions(rewriter.rewrite(related, "", true), yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+		if err != nil {
+			return nil, OutputRenumberCatalog{}, fmt.Errorf("failed to encode %s: %w", artifactName(a, i), err)
+		}
+		output.RelatedArtifacts = append(output.RelatedArtifacts, RenumberedArtifact{
+			Name:       artifactName(a, i),
+			Content:    string(out),
+			References: rewriter.references,
+			Mentions:   rewriter.mentions,
+		})
+	}
+
+	var table bytes.Buffer
+	w := csv.NewWriter(&table)
+	_ = w.Write([]string{"old_id", "new_id", "kind"})
+	for _, m := range output.Mapping {
+		_ = w.Write([]string{m.Old, m.New, m.Kind})
+	}
+	w.Flush()
+	output.MappingCSV = table.String()
+
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputRenumberCatalog{}, err
+	}
+	validation, err := validateAgainstSchema(schema, "#ControlCatalog", output.RenumberedContent)
+	if err != nil {
+		return nil, OutputRenumberCatalog{}, err
+	}
+	output.Valid = validation.Valid
+	output.Errors = validation.Errors
+	output.Message = fmt.Sprintf("Renumbered %d of %d ID(s); rewrote %d reference(s) and %d mention(s) in the catalog and %d related artifact(s); %s",
+		len(output.Mapping), len(seen), output.References, output.Mentions, len(output.RelatedArtifacts), strings.ToLower(validation.Message))
+	return nil, output, nil
+}
+
+// addIDMapping records the new ID of an entity, rejecting duplicate old IDs.
+// Only changed IDs are listed in the output mapping.
+func addIDMapping(output *OutputRenumberCatalog, mapping map[string]string, seen map[string]bool, oldID, newID, kind string) error {
+	if seen[oldID] {
+		return fmt.Errorf("ID %s is used more than once; references to it are ambiguous", oldID)
+	}
+	seen[oldID] = true
+	if oldID != newID {
+		mapping[oldID] = newID
+		output.Mapping = append(output.Mapping, IDMapping{Old: oldID, New: newID, Kind: kind})
+	}
+	return nil
+}
+
+// idRewriter replaces old IDs with new ones in one pass, so an ID that is
+// renumbered to another's old ID is not rewritten twice.
+type idRewriter struct {
+	mapping    map[string]string
+	pattern    *regexp.Regexp
+	references int
+	mentions   int
+}
+
+func newIDRewriter(mapping map[string]string) *idRewriter {
+	r := &idRewriter{mapping: mapping}
+	if len(mapping) == 0 {
+		return r
+	}
+	ids := make([]string, 0, len(mapping))
+	for id := range mapping {
+		ids = append(ids, regexp.QuoteMeta(id))
+	}
+	// Longer IDs first, so CCC.C01.TR01 is not read as CCC.C01
+	sort.Slice(ids, func(i, j int) bool { return len(ids[i]) > len(ids[j]) || len(ids[i]) == len(ids[j]) && ids[i] < ids[j] })
+	r.pattern = regexp.MustCompile(strings.Join(ids, "|"))
+	return r
+}
+
+// rewrite returns node with old IDs replaced: whole values are references,
+// IDs within longer text are mentions. The id fields of entities are left
+// alone when keepIDs is set.
+func (r *idRewriter) rewrite(node interface{}, key string, keepIDs bool) interface{} {
+	switch v := node.(type) {
+	case yaml.MapSlice:
+		out := make(yaml.MapSlice, len(v))
+		for i, item := range v {
+			out[i] = yaml.MapItem{Key: item.Key, Value: r.rewrite(item.Value, fmt.Sprint(item.Key), keepIDs)}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, elem := range v {
+			out[i] = r.rewrite(elem, key, keepIDs)
+		}
+		return out
+	case string:
+		if keepIDs && key == "id" {
+			return v
+		}
+		if newID, ok := r.mapping[strings.TrimSpace(v)]; ok {
+			if key != "id" {
+				r.references++
+			}
+			return newID
+		}
+		return r.rewriteMentions(v)
+	}
+	return node
+}
+
+// rewriteMentions replaces old IDs that stand alone in text, not those that
+// are part of a longer identifier such as CCC.C010 or CCC.C01-legacy.
+func (r *idRewriter) rewriteMentions(text string) string {
+	if r.pattern == nil {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range r.pattern.FindAllStringIndex(text, -1) {
+		start, end := loc[0], loc[1]
+		if start > 0 && isIDChar(text[start-1]) || end < len(text) && continuesID(text[end:]) {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(r.mapping[text[start:end]])
+		last = end
+		r.mentions++
+	}
+	if last == 0 {
+		return text
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// isIDChar reports whether c can be part of an ID.
+func isIDChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-' || c == '.'
+}
+
+// continuesID reports whether rest, the text after a match, extends the ID.
+// A period followed by a space or the end of the text ends a sentence.
+func continuesID(rest string) bool {
+	if rest[0] == '.' {
+		return len(rest) > 1 && isIDChar(rest[1])
+	}
+	return isIDChar(rest[0])
+}
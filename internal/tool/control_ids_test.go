@@ -0,0 +1,210 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocateControlIDs(t *testing.T) {
+	catalog, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err)
+	three, zero := 3, 0
+
+	withMissing := baselineCatalog + `  - id: TODO
+    family: data
+    title: Back up data
+    objective: Data is backed up.
+    assessment-requirements:
+      - text: Backups run daily.
+        applicability: [prod]
+      - id: TODO
+        text: Restores are tested.
+        applicability: [prod]
+`
+
+	tests := []struct {
+		name         string
+		input        InputAllocateControlIDs
+		errContains  string
+		wantIDs      []string
+		wantAssigned []IDMapping
+		wantContains []string
+	}{
+		{
+			name:    "continues after the highest control",
+			input:   InputAllocateControlIDs{ArtifactContent: string(catalog), Count: &three},
+			wantIDs: []string{"CCC.C11", "CCC.C12", "CCC.C13"},
+		},
+		{
+			name:    "prefix and padding",
+			input:   InputAllocateControlIDs{ArtifactContent: string(catalog), Prefix: "ORG", Padding: 3},
+			wantIDs: []string{"ORG.C001"},
+		},
+		{
+			name: "family grouping",
+			input: InputAllocateControlIDs{
+				ArtifactContent: string(catalog),
+				FamilyGrouping:  true,
+				Family:          "data-protection",
+			},
+			wantIDs: []string{"CCC.DP.C01"},
+		},
+		{
+			name:        "family grouping without family",
+			input:       InputAllocateControlIDs{ArtifactContent: string(catalog), FamilyGrouping: true},
+			errContains: "family is required",
+		},
+		{
+			name:        "unknown family",
+			input:       InputAllocateControlIDs{ArtifactContent: string(catalog), FamilyGrouping: true, Family: "network"},
+			errContains: "family network is not in the catalog",
+		},
+		{
+			name:        "not a catalog",
+			input:       InputAllocateControlIDs{ArtifactContent: "metadata:\n  id: X\ncategories: []\n"},
+			errContains: "requires a ControlCatalog",
+		},
+		{
+			name:    "assigns missing IDs",
+			input:   InputAllocateControlIDs{ArtifactContent: withMissing, Count: &zero},
+			wantIDs: []string{},
+			wantAssigned: []IDMapping{
+				{Old: "TODO", New: "AC.C03", Kind: "control"},
+				{Old: "", New: "AC.C03.TR01", Kind: "requirement"},
+				{Old: "TODO", New: "AC.C03.TR02", Kind: "requirement"},
+			},
+			wantContains: []string{"- id: AC.C03\n", "- id: AC.C03.TR01\n        text: Backups run daily."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := AllocateControlIDs(context.Background(), nil, tt.input)
+			if tt.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantIDs, output.IDs)
+			if tt.wantAssigned == nil {
+				assert.Empty(t, output.Assigned)
+				assert.Empty(t, output.Content, "the catalog is only returned when IDs were assigned")
+			} else {
+				assert.Equal(t, tt.wantAssigned, output.Assigned)
+			}
+			for _, want := range tt.wantContains {
+				assert.Contains(t, output.Content, want)
+			}
+		})
+	}
+}
+
+func TestRenumberCatalog(t *testing.T) {
+	useTestSchema(t)
+	catalog := `metadata:
+  id: ORG
+  description: Controls that supersede ORG.C2.
+  author:
+    id: sec
+    name: Security
+    type: Human
+title: Organization Controls
+families:
+  - id: access
+    title: Access
+    description: Access.
+  - id: data-protection
+    title: Data Protection
+    description: Data.
+controls:
+  - id: ORG.C2
+    family: access
+    title: Review access
+    objective: Access is reviewed; see ORG.C10 for credentials and ORG.C2-legacy for history.
+    assessment-requirements:
+      - id: ORG.C2.TR1
+        text: Reviews are recorded.
+        applicability: [prod]
+  - id: ORG.C10
+    family: data-protection
+    title: Encrypt data
+    objective: Data is encrypted.
+    assessment-requirements:
+      - id: ORG.C10.TR1
+        text: Volumes are encrypted, as ORG.C2.TR1 requires.
+        applicability: [prod]
+`
+	policy := `metadata:
+  id: ORG-POL
+  description: Adopts ORG.C10.
+  author:
+    id: sec
+    name: Security
+    type: Human
+title: Policy
+adherence:
+  assessment-plans:
+    - id: ORG.C10
+      requirement-id: ORG.C10.TR1
+      frequency: daily
+      evaluation-methods:
+        - type: automated
+`
+
+	_, output, err := RenumberCatalog(context.Background(), nil, InputRenumberCatalog{
+		ArtifactContent:  catalog,
+		RelatedArtifacts: []ArtifactInput{{Name: "policy.yaml", Content: policy}},
+	})
+	require.NoError(t, err)
+	assert.True(t, output.Valid, "renumbered catalog should validate: %v", output.Errors)
+	assert.Equal(t, []IDMapping{
+		{Old: "ORG.C2", New: "ORG.C01", Kind: "control"},
+		{Old: "ORG.C2.TR1", New: "ORG.C01.TR01", Kind: "requirement"},
+		{Old: "ORG.C10", New: "ORG.C02", Kind: "control"},
+		{Old: "ORG.C10.TR1", New: "ORG.C02.TR01", Kind: "requirement"},
+	}, output.Mapping)
+	assert.Equal(t, "old_id,new_id,kind\nORG.C2,ORG.C01,control\nORG.C2.TR1,ORG.C01.TR01,requirement\nORG.C10,ORG.C02,control\nORG.C10.TR1,ORG.C02.TR01,requirement\n", output.MappingCSV)
+	assert.Contains(t, output.RenumberedContent, "Controls that supersede ORG.C01.")
+	assert.Contains(t, output.RenumberedContent, "see ORG.C02 for credentials and ORG.C2-legacy for history.")
+	assert.Contains(t, output.RenumberedContent, "as ORG.C01.TR01 requires.")
+	assert.Equal(t, 3, output.Mentions)
+
+	require.Len(t, output.RelatedArtifacts, 1)
+	related := output.RelatedArtifacts[0]
+	assert.Equal(t, 1, related.References)
+	assert.Equal(t, 1, related.Mentions)
+	assert.Contains(t, related.Content, "requirement-id: ORG.C02.TR01")
+	assert.Contains(t, related.Content, "- id: ORG.C10\n", "the policy's own IDs should not change")
+	assert.Contains(t, related.Content, "Adopts ORG.C02.")
+
+	// Family grouping numbers controls per family
+	_, output, err = RenumberCatalog(context.Background(), nil, InputRenumberCatalog{
+		ArtifactContent: catalog,
+		FamilyGrouping:  true,
+		FamilyCodes:     map[string]string{"access": "IAM"},
+	})
+	require.NoError(t, err)
+	assert.True(t, output.Valid, "renumbered catalog should validate: %v", output.Errors)
+	assert.Contains(t, output.RenumberedContent, "- id: ORG.IAM.C01\n")
+	assert.Contains(t, output.RenumberedContent, "- id: ORG.DP.C01.TR01\n")
+
+	_, _, err = RenumberCatalog(context.Background(), nil, InputRenumberCatalog{
+		ArtifactContent: catalog + "  - id: ORG.C2\n    family: access\n    title: Dup\n    objective: Dup.\n    assessment-requirements: []\n",
+	})
+	assert.ErrorContains(t, err, "ID ORG.C2 is used more than once")
+
+	_, _, err = RenumberCatalog(context.Background(), nil, InputRenumberCatalog{
+		ArtifactContent: catalog,
+		FamilyGrouping:  true,
+		FamilyCodes:     map[string]string{"access": "DP"},
+	})
+	assert.ErrorContains(t, err, "both have the code DP")
+}
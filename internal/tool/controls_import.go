@@ -0,0 +1,457 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Catalog fields a spreadsheet column can be mapped to.
+const (
+	importFieldID             = "id"
+	importFieldTitle          = "title"
+	importFieldObjective      = "objective"
+	importFieldFamily         = "family"
+	importFieldRequirementID  = "requirement_id"
+	importFieldRequirement    = "requirement_text"
+	importFieldApplicability  = "applicability"
+	importFieldRecommendation = "recommendation"
+)
+
+// importFields lists the mappable fields in the order they are documented.
+var importFields = []string{
+	importFieldID, importFieldTitle, importFieldObjective, importFieldFamily,
+	importFieldRequirementID, importFieldRequirement, importFieldApplicability, importFieldRecommendation,
+}
+
+// MetadataImportControlsFromCSV describes the ImportControlsFromCSV tool.
+var MetadataImportControlsFromCSV = &mcp.Tool{
+	Name: "import_controls_from_csv",
+	Description: "Convert a spreadsheet of controls (CSV, or an XLSX workbook) into a draft ControlCatalog using a column " +
+		"mapping, validate the draft against the schema, and report the rows that could not be converted. Rows sharing " +
+		"a control ID become assessment requirements of one control.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"csv_content": map[string]interface{}{
+				"type":        "string",
+				"description": "CSV content with a header row",
+			},
+			"xlsx_content": map[string]interface{}{
+				"type":            "string",
+				"contentEncoding": "base64",
+				"description":     "Base64-encoded XLSX workbook to read instead of csv_content; the first row of the sheet is the header",
+			},
+			"sheet": map[string]interface{}{
+				"type":        "string",
+				"description": "Name of the XLSX sheet to read (default: the first sheet)",
+			},
+			"delimiter": map[string]interface{}{
+				"type":        "string",
+				"description": "CSV field delimiter (default: ,)",
+			},
+			"column_mapping": map[string]interface{}{
+				"type": "object",
+				"description": "Header of the column holding each catalog field: " + strings.Join(importFields, ", ") +
+					". Defaults to columns whose header matches the field name. title or objective must be mapped.",
+				"additionalProperties": map[string]interface{}{"type": "string"},
+			},
+			"catalog_id": map[string]interface{}{
+				"type":        "string",
+				"description": fmt.Sprintf("Metadata id of the catalog (default: %s)", defaultSkeletonCatalogID),
+			},
+			"title": map[string]interface{}{
+				"type":        "string",
+				"description": "Title of the catalog (default: Imported Control Catalog)",
+			},
+			"id_prefix": map[string]interface{}{
+				"type":        "string",
+				"description": "Prefix for generated family and control IDs (default: derived from catalog_id)",
+			},
+			"applicability": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": fmt.Sprintf("Applicability category IDs for requirements without an applicability column value (default: [%s])", defaultSkeletonCategory),
+			},
+		},
+	},
+}
+
+// InputImportControlsFromCSV is the input for the ImportControlsFromCSV tool.
+type InputImportControlsFromCSV struct {
+	CSVContent    string            `json:"csv_content,omitempty"`
+	XLSXContent   []byte            `json:"xlsx_content,omitempty"`
+	Sheet         string            `json:"sheet,omitempty"`
+	Delimiter     string            `json:"delimiter,omitempty"`
+	ColumnMapping map[string]string `json:"column_mapping,omitempty"`
+	CatalogID     string            `json:"catalog_id,omitempty"`
+	Title         string            `json:"title,omitempty"`
+	IDPrefix      string            `json:"id_prefix,omitempty"`
+	Applicability []string          `json:"applicability,omitempty"`
+}
+
+// ImportedControl summarizes a control converted from spreadsheet rows.
+type ImportedControl struct {
+	ID           string `json:"id"`
+	Family       string `json:"family"`
+	Title        string `json:"title"`
+	Rows         []int  `json:"rows"`
+	Requirements int    `json:"requirements"`
+}
+
+// ImportIssue is a spreadsheet row that could not be converted, or was only
+// partly converted.
+type ImportIssue struct {
+	// Row is the 1-based row number in the spreadsheet, counting the header.
+	Row    int    `json:"row"`
+	Reason string `json:"reason"`
+}
+
+// OutputImportControlsFromCSV is the output for the ImportControlsFromCSV tool.
+type OutputImportControlsFromCSV struct {
+	Content  string            `json:"content"`
+	Controls []ImportedControl `json:"controls"`
+	Issues   []ImportIssue     `json:"issues"`
+	Valid    bool              `json:"valid"`
+	Errors   []string          `json:"errors,omitempty"`
+	// Placeholders lists the paths left for the author to complete.
+	Placeholders []string `json:"placeholders"`
+	Message      string   `json:"message"`
+}
+
+// importedControl accumulates the rows of one control.
+type importedControl struct {
+	summary        ImportedControl
+	objective      string
+	requirements   []interface{}
+	requirementIDs map[string]bool
+}
+
+// ImportControlsFromCSV drafts a ControlCatalog from spreadsheet rows.
+func ImportControlsFromCSV(ctx context.Context, _ *mcp.CallToolRequest, input InputImportControlsFromCSV) (*mcp.CallToolResult, OutputImportControlsFromCSV, error) {
+	rows, err := readImportRows(ctx, input)
+	if err != nil {
+		return nil, OutputImportControlsFromCSV{}, err
+	}
+	if len(rows) < 2 {
+		return nil, OutputImportControlsFromCSV{}, fmt.Errorf("spreadsheet needs a header row and at least one data row")
+	}
+	columns, err := importColumns(rows[0], input.ColumnMapping)
+	if err != nil {
+		return nil, OutputImportControlsFromCSV{}, err
+	}
+
+	catalogID := input.CatalogID
+	if catalogID == "" {
+		catalogID = defaultSkeletonCatalogID
+	}
+	title := input.Title
+	if title == "" {
+		title = "Imported Control Catalog"
+	}
+	prefix := input.IDPrefix
+	if prefix == "" {
+		prefix = skeletonPrefix(catalogID)
+	}
+	applicability := input.Applicability
+	if len(applicability) == 0 {
+		applicability = []string{defaultSkeletonCategory}
+	}
+
+	output := OutputImportControlsFromCSV{Controls: []ImportedControl{}, Issues: []ImportIssue{}, Placeholders: []string{}}
+	byID := map[string]*importedControl{}
+	var order []*importedControl
+	familyIDs := map[string]string{}
+	var families []interface{}
+	categories := map[string]bool{}
+	for _, id := range applicability {
+		categories[id] = true
+	}
+
+	for i, row := range rows[1:] {
+		number := i + 2
+		cell := func(field string) string {
+			index, ok := columns[field]
+			if !ok || index >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[index])
+		}
+		if strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+
+		controlTitle, objective := cell(importFieldTitle), cell(importFieldObjective)
+		id := cell(importFieldID)
+		control, existing := byID[id]
+		if id == "" || !existing {
+			if controlTitle == "" && objective == "" {
+				output.Issues = append(output.Issues, ImportIssue{Row: number, Reason: "no title or objective"})
+				continue
+			}
+			if id == "" {
+				// Skip numbers already taken by IDs from the spreadsheet
+				for n := len(order) + 1; id == "" || byID[id] != nil; n++ {
+					id = fmt.Sprintf("%s.C%02d", prefix, n)
+				}
+			}
+			if controlTitle == "" {
+				controlTitle = skeletonTitle(objective)
+			}
+			if objective == "" {
+				objective = controlTitle
+			}
+			familyTitle := cell(importFieldFamily)
+			if familyTitle == "" {
+				familyTitle = skeletonGeneralFamily
+			}
+			familyID, ok := familyIDs[familyTitle]
+			if !ok {
+				familyID = fmt.Sprintf("%s.F%02d", prefix, len(familyIDs)+1)
+				familyIDs[familyTitle] = familyID
+				families = append(families, yaml.MapSlice{
+					{Key: "id", Value: familyID},
+					{Key: "title", Value: familyTitle},
+					{Key: "description", Value: fmt.Sprintf("Controls for %s.", strings.ToLower(familyTitle))},
+				})
+			}
+			control = &importedControl{
+				summary:        ImportedControl{ID: id, Family: familyID, Title: controlTitle},
+				objective:      objective,
+				requirementIDs: map[string]bool{},
+			}
+			byID[id] = control
+			order = append(order, control)
+		} else if controlTitle != "" && controlTitle != control.summary.Title {
+			output.Issues = append(output.Issues, ImportIssue{Row: number,
+				Reason: fmt.Sprintf("title %q differs from the earlier row of %s; kept %q", controlTitle, id, control.summary.Title)})
+		}
+		control.summary.Rows = append(control.summary.Rows, number)
+
+		text := cell(importFieldRequirement)
+		if text == "" {
+			continue
+		}
+		requirementID := cell(importFieldRequirementID)
+		if requirementID == "" {
+			requirementID = fmt.Sprintf("%s.TR%02d", id, len(control.requirements)+1)
+		}
+		if control.requirementIDs[requirementID] {
+			output.Issues = append(output.Issues, ImportIssue{Row: number, Reason: fmt.Sprintf("duplicate requirement %s skipped", requirementID)})
+			continue
+		}
+		control.requirementIDs[requirementID] = true
+
+		applies := splitImportList(cell(importFieldApplicability))
+		if len(applies) == 0 {
+			applies = applicability
+		}
+		for _, category := range applies {
+			categories[category] = true
+		}
+		requirement := yaml.MapSlice{
+			{Key: "id", Value: requirementID},
+			{Key: "text", Value: text},
+			{Key: "applicability", Value: applies},
+		}
+		if recommendation := cell(importFieldRecommendation); recommendation != "" {
+			requirement = append(requirement, yaml.MapItem{Key: "recommendation", Value: recommendation})
+		}
+		control.requirements = append(control.requirements, requirement)
+	}
+	if len(order) == 0 {
+		return nil, OutputImportControlsFromCSV{}, fmt.Errorf("no rows could be converted to controls (%d issue(s))", len(output.Issues))
+	}
+
+	var controls []interface{}
+	for i, c := range order {
+		// Controls without requirement rows get a placeholder, as the schema requires one
+		if len(c.requirements) == 0 {
+			path := fmt.Sprintf("$.controls[%d]", i)
+			output.Placeholders = append(output.Placeholders, path+`["assessment-requirements"][0].text`)
+			c.requirements = append(c.requirements, yaml.MapSlice{
+				{Key: "id", Value: c.summary.ID + ".TR01"},
+				{Key: "text", Value: fmt.Sprintf("%s: describe how to verify that %s", skeletonPlaceholder, lowerFirst(strings.TrimSuffix(c.objective, ".")))},
+				{Key: "applicability", Value: applicability},
+			})
+		}
+		c.summary.Requirements = len(c.requirements)
+		controls = append(controls, yaml.MapSlice{
+			{Key: "id", Value: c.summary.ID},
+			{Key: "family", Value: c.summary.Family},
+			{Key: "title", Value: c.summary.Title},
+			{Key: "objective", Value: c.objective},
+			{Key: "assessment-requirements", Value: c.requirements},
+		})
+		output.Controls = append(output.Controls, c.summary)
+	}
+
+	categoryIDs := make([]string, 0, len(categories))
+	for id := range categories {
+		categoryIDs = append(categoryIDs, id)
+	}
+	sort.Strings(categoryIDs)
+	var categoryList []interface{}
+	for _, id := range categoryIDs {
+		categoryList = append(categoryList, yaml.MapSlice{
+			{Key: "id", Value: id},
+			{Key: "title", Value: id},
+			{Key: "description", Value: fmt.Sprintf("%s: describe when the %s category applies.", skeletonPlaceholder, id)},
+		})
+	}
+	output.Placeholders = append([]string{"$.metadata.description", "$.metadata.author"}, output.Placeholders...)
+
+	catalog := yaml.MapSlice{
+		{Key: "metadata", Value: yaml.MapSlice{
+			{Key: "id", Value: catalogID},
+			{Key: "description", Value: fmt.Sprintf("%s: describe the scope of this catalog. Imported from %d spreadsheet row(s).", skeletonPlaceholder, len(rows)-1)},
+			{Key: "version", Value: "0.1.0"},
+			{Key: "author", Value: draftAuthor},
+			{Key: "applicability-categories", Value: categoryList},
+		}},
+		{Key: "title", Value: title},
+		{Key: "families", Value: families},
+		{Key: "controls", Value: controls},
+	}
+	out, err := yaml.MarshalWithOptions(catalog, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return nil, OutputImportControlsFromCSV{}, fmt.Errorf("failed to encode catalog: %w", err)
+	}
+	output.Content = string(out)
+
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputImportControlsFromCSV{}, err
+	}
+	validation, err := validateAgainstSchema(schema, "#ControlCatalog", output.Content)
+	if err != nil {
+		return nil, OutputImportControlsFromCSV{}, err
+	}
+	output.Valid = validation.Valid
+	output.Errors = validation.Errors
+	output.Message = fmt.Sprintf("Imported %d control(s) in %d family(ies); %d row issue(s); %s",
+		len(output.Controls), len(families), len(output.Issues), strings.ToLower(validation.Message))
+	return nil, output, nil
+}
+
+// readImportRows reads the rows of the CSV or XLSX input.
+func readImportRows(ctx context.Context, input InputImportControlsFromCSV) ([][]string, error) {
+	limit := serverConfig(ctx).MaxArtifactSize
+	switch {
+	case input.CSVContent != "" && len(input.XLSXContent) > 0:
+		return nil, fmt.Errorf("csv_content and xlsx_content are mutually exclusive")
+	case len(input.XLSXContent) > 0:
+		if int64(len(input.XLSXContent)) > limit {
+			return nil, fmt.Errorf("workbook is %d bytes, exceeding the %d byte limit", len(input.XLSXContent), limit)
+		}
+		return readXLSXRows(input.XLSXContent, input.Sheet, limit)
+	case input.CSVContent != "":
+		if int64(len(input.CSVContent)) > limit {
+			return nil, fmt.Errorf("CSV is %d bytes, exceeding the %d byte limit", len(input.CSVContent), limit)
+		}
+		return readCSVRows(input.CSVContent, input.Delimiter)
+	}
+	return nil, fmt.Errorf("csv_content or xlsx_content is required")
+}
+
+// readCSVRows parses CSV content, allowing rows of differing lengths.
+func readCSVRows(content, delimiter string) ([][]string, error) {
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(content, "\ufeff")))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	if delimiter != "" {
+		if delimiter == `\t` {
+			delimiter = "\t"
+		}
+		runes := []rune(delimiter)
+		if len(runes) != 1 {
+			return nil, fmt.Errorf("delimiter must be a single character, got %q", delimiter)
+		}
+		reader.Comma = runes[0]
+	}
+	var rows [][]string
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CSV: %w", err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+// importColumns resolves the column index of each mapped field. Without an
+// explicit mapping, fields map to columns whose header matches their name.
+func importColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	indexes := map[string]int{}
+	for i, h := range header {
+		key := normalizeImportHeader(h)
+		if _, seen := indexes[key]; !seen {
+			indexes[key] = i
+		}
+	}
+
+	columns := map[string]int{}
+	if len(mapping) == 0 {
+		for _, field := range importFields {
+			if i, ok := indexes[normalizeImportHeader(field)]; ok {
+				columns[field] = i
+			}
+		}
+	} else {
+		known := map[string]bool{}
+		for _, field := range importFields {
+			known[field] = true
+		}
+		fields := make([]string, 0, len(mapping))
+		for field := range mapping {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			if !known[field] {
+				return nil, fmt.Errorf("unknown column_mapping field %q (expected one of %s)", field, strings.Join(importFields, ", "))
+			}
+			i, ok := indexes[normalizeImportHeader(mapping[field])]
+			if !ok {
+				return nil, fmt.Errorf("column %q mapped to %s is not in the header", mapping[field], field)
+			}
+			columns[field] = i
+		}
+	}
+
+	_, hasTitle := columns[importFieldTitle]
+	_, hasObjective := columns[importFieldObjective]
+	if !hasTitle && !hasObjective {
+		return nil, fmt.Errorf("column_mapping must map title or objective to a column (header: %s)", strings.Join(header, ", "))
+	}
+	return columns, nil
+}
+
+// normalizeImportHeader folds case and separators so "Requirement ID" matches requirement_id.
+func normalizeImportHeader(header string) string {
+	header = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(header)
+}
+
+// splitImportList splits a cell holding several values separated by
+// semicolons, commas, pipes, or newlines.
+func splitImportList(cell string) []string {
+	var values []string
+	for _, v := range strings.FieldsFunc(cell, func(r rune) bool { return r == ';' || r == ',' || r == '|' || r == '\n' }) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const importCSV = `Control ID,Name,Description,Domain,Test ID,Test Procedure,Scope
+AC-1,Enforce MFA,All users must use multi-factor authentication.,Access,AC-1.1,Verify MFA is required at login.,internal;external
+AC-1,,,Access,AC-1.2,Verify MFA cannot be bypassed.,
+AC-1,Enforce 2FA,,Access,AC-1.2,Duplicate test,
+,,,,,,
+DP-1,Encrypt data,Data must be encrypted at rest.,Data,,,
+,,,Data,,orphan row,
+,,Backups must be tested quarterly.,Resilience,,,
+`
+
+var importMapping = map[string]string{
+	"id":               "Control ID",
+	"title":            "Name",
+	"objective":        "Description",
+	"family":           "Domain",
+	"requirement_id":   "Test ID",
+	"requirement_text": "Test Procedure",
+	"applicability":    "Scope",
+}
+
+func TestImportControlsFromCSV(t *testing.T) {
+	useTestSchema(t)
+
+	tests := []struct {
+		name           string
+		input          InputImportControlsFromCSV
+		errContains    string
+		validateOutput func(t *testing.T, output OutputImportControlsFromCSV)
+	}{
+		{
+			name:  "mapped columns",
+			input: InputImportControlsFromCSV{CSVContent: importCSV, ColumnMapping: importMapping, CatalogID: "acme-controls"},
+			validateOutput: func(t *testing.T, output OutputImportControlsFromCSV) {
+				assert.True(t, output.Valid, "draft should validate: %v", output.Errors)
+				require.Len(t, output.Controls, 3)
+				assert.Equal(t, ImportedControl{ID: "AC-1", Family: "ACME.F01", Title: "Enforce MFA", Rows: []int{2, 3, 4}, Requirements: 2},
+					output.Controls[0], "rows sharing a control ID should be merged")
+				assert.Equal(t, "DP-1", output.Controls[1].ID)
+				assert.Equal(t, 1, output.Controls[1].Requirements, "a placeholder requirement should be added")
+				assert.Equal(t, "ACME.C03", output.Controls[2].ID, "missing IDs should be generated")
+				assert.Equal(t, "Backups must be tested quarterly", output.Controls[2].Title, "title should be derived from the objective")
+
+				assert.Equal(t, []ImportIssue{
+					{Row: 4, Reason: `title "Enforce 2FA" differs from the earlier row of AC-1; kept "Enforce MFA"`},
+					{Row: 4, Reason: "duplicate requirement AC-1.2 skipped"},
+					{Row: 7, Reason: "no title or objective"},
+				}, output.Issues)
+				assert.Contains(t, output.Content, "- internal\n", "applicability should be split")
+				assert.Contains(t, output.Content, "id: external", "applicability categories should be declared")
+				assert.Contains(t, output.Placeholders, `$.controls[1]["assessment-requirements"][0].text`)
+			},
+		},
+		{
+			name:  "headers matching field names",
+			input: InputImportControlsFromCSV{CSVContent: "Title;Objective;Requirement Text\nLog access;Access is logged.;Check the logs\n", Delimiter: ";"},
+			validateOutput: func(t *testing.T, output OutputImportControlsFromCSV) {
+				assert.True(t, output.Valid, "draft should validate: %v", output.Errors)
+				require.Len(t, output.Controls, 1)
+				assert.Equal(t, "DRAFT.C01", output.Controls[0].ID)
+				assert.Empty(t, output.Issues)
+			},
+		},
+		{
+			name:        "no content",
+			input:       InputImportControlsFromCSV{},
+			errContains: "csv_content or xlsx_content is required",
+		},
+		{
+			name:        "unknown field",
+			input:       InputImportControlsFromCSV{CSVContent: importCSV, ColumnMapping: map[string]string{"owner": "Name"}},
+			errContains: `unknown column_mapping field "owner"`,
+		},
+		{
+			name:        "missing column",
+			input:       InputImportControlsFromCSV{CSVContent: importCSV, ColumnMapping: map[string]string{"title": "Heading"}},
+			errContains: `column "Heading" mapped to title is not in the header`,
+		},
+		{
+			name:        "nothing to map",
+			input:       InputImportControlsFromCSV{CSVContent: "a,b\n1,2\n"},
+			errContains: "must map title or objective",
+		},
+		{
+			name:        "header only",
+			input:       InputImportControlsFromCSV{CSVContent: "title\n"},
+			errContains: "at least one data row",
+		},
+		{
+			name:        "bad delimiter",
+			input:       InputImportControlsFromCSV{CSVContent: "title\nx\n", Delimiter: ";;"},
+			errContains: "single character",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := ImportControlsFromCSV(context.Background(), nil, tt.input)
+			if tt.errContains != "" {
+				require.Error(t, err, "should return error")
+				assert.Contains(t, err.Error(), tt.errContains, "error should contain expected message")
+				return
+			}
+			require.NoError(t, err, "should not return error")
+			tt.validateOutput(t, output)
+		})
+	}
+}
+
+// testWorkbook builds an XLSX workbook with a second sheet named Controls
+// holding a shared string header, an inline string, and a number.
+func testWorkbook(t *testing.T) []byte {
+	t.Helper()
+	return testWorkbookSheet(t, `<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>
+<row r="2"><c r="A2" t="inlineStr"><is><t>Rotate keys</t></is></c><c r="C2"><v>90</v></c></row>`)
+}
+
+// testWorkbookSheet builds the workbook of testWorkbook with the given rows
+// as the sheet data of Controls.
+func testWorkbookSheet(t *testing.T, rows string) []byte {
+	t.Helper()
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Notes" sheetId="1" r:id="rId1"/><sheet name="Controls" sheetId="2" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Target="worksheets/sheet2.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>Title</t></si><si><r><t>Obj</t></r><r><t>ective</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData/></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+` + rows + `
+</sheetData></worksheet>`,
+	}
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range parts {
+		f, err := w.Create(name)
+		require.NoError(t, err, "should add workbook part")
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err, "should write workbook part")
+	}
+	require.NoError(t, w.Close(), "should close workbook")
+	return buf.Bytes()
+}
+
+func TestReadXLSXRows(t *testing.T) {
+	workbook := testWorkbook(t)
+
+	rows, err := readXLSXRows(workbook, "Controls", DefaultMaxArtifactSize)
+	require.NoError(t, err, "should read the named sheet")
+	assert.Equal(t, [][]string{{"Title", "", "Objective"}, {"Rotate keys", "", "90"}}, rows,
+		"cells should land in their referenced columns")
+
+	rows, err = readXLSXRows(workbook, "", DefaultMaxArtifactSize)
+	require.NoError(t, err, "should read the first sheet")
+	assert.Empty(t, rows)
+
+	_, err = readXLSXRows(workbook, "Missing", DefaultMaxArtifactSize)
+	require.Error(t, err, "should reject unknown sheets")
+	assert.Contains(t, err.Error(), "available: Notes, Controls")
+
+	_, err = readXLSXRows([]byte("not a zip"), "", DefaultMaxArtifactSize)
+	require.Error(t, err, "should reject content that is not a workbook")
+
+	_, err = readXLSXRows(testWorkbookSheet(t, `<row r="1"><c r="ZZZZZZZZ1"><v>1</v></c></row>`), "Controls", DefaultMaxArtifactSize)
+	require.Error(t, err, "should reject references past column XFD")
+	assert.Contains(t, err.Error(), "past the last column XFD")
+
+	rows, err = readXLSXRows(testWorkbookSheet(t, `<row r="1"><c r="XFD1"><v>1</v></c></row>`), "Controls", DefaultMaxArtifactSize)
+	require.NoError(t, err, "should read the last column")
+	require.Len(t, rows, 1)
+	assert.Len(t, rows[0], maxXLSXColumns)
+
+	sparse := strings.Repeat(`<row><c r="XFD1"><v>1</v></c></row>`, maxXLSXCells/maxXLSXColumns+1)
+	_, err = readXLSXRows(testWorkbookSheet(t, sparse), "Controls", DefaultMaxArtifactSize)
+	require.Error(t, err, "should bound the cells padded out to sparse references")
+	assert.Contains(t, err.Error(), "more than")
+
+	useTestSchema(t)
+	_, output, err := ImportControlsFromCSV(context.Background(), nil, InputImportControlsFromCSV{XLSXContent: workbook, Sheet: "Controls"})
+	require.NoError(t, err, "should import from the workbook")
+	require.Len(t, output.Controls, 1)
+	assert.Equal(t, "Rotate keys", output.Controls[0].Title)
+}
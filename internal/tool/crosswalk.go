@@ -0,0 +1,329 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultCrosswalkMinConfidence = 0.2
+	defaultCrosswalkMaxCandidates = 3
+
+	// Confidence levels bucket candidate scores for reviewers.
+	confidenceHigh   = "high"
+	confidenceMedium = "medium"
+	confidenceLow    = "low"
+
+	// crosswalkTitleWeight counts title terms more than objective terms, as
+	// titles name what a control does while objectives add context.
+	crosswalkTitleWeight = 2.0
+)
+
+// crosswalkWord splits control text into candidate terms.
+var crosswalkWord = regexp.MustCompile(`[a-z0-9]+`)
+
+// crosswalkStopWords are too common in control text to indicate similarity.
+var crosswalkStopWords = map[string]bool{
+	"a": true, "all": true, "an": true, "and": true, "any": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "for": true, "from": true, "has": true, "have": true, "in": true, "into": true, "is": true, "it": true,
+	"its": true, "must": true, "not": true, "of": true, "on": true, "or": true, "shall": true, "should": true,
+	"such": true, "that": true, "the": true, "their": true, "these": true, "this": true, "to": true, "with": true,
+	"ensure": true, "control": true, "controls": true,
+}
+
+// MetadataCrosswalkCatalogs describes the CrosswalkCatalogs tool.
+var MetadataCrosswalkCatalogs = &mcp.Tool{
+	Name: "crosswalk_catalogs",
+	Description: "Propose control-to-control mappings between two ControlCatalogs by scoring the similarity of control " +
+		"titles and objectives. Returns confidence-ranked candidates with the terms they share, for a human or agent to confirm.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"source", "target"},
+		"properties": map[string]interface{}{
+			"source": crosswalkCatalogSchema("Catalog whose controls are mapped"),
+			"target": crosswalkCatalogSchema("Catalog searched for matching controls"),
+			"min_confidence": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"maximum":     1,
+				"description": fmt.Sprintf("Drop candidates scoring below this confidence (default: %g)", defaultCrosswalkMinConfidence),
+			},
+			"max_candidates": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"description": fmt.Sprintf("Maximum candidates per source control (default: %d)", defaultCrosswalkMaxCandidates),
+			},
+		},
+	},
+}
+
+// crosswalkCatalogSchema is the JSON schema for one catalog passed to the crosswalk.
+func crosswalkCatalogSchema(description string) map[string]interface{} {
+	schema := map[string]interface{}{}
+	for k, v := range artifactInputSchema["items"].(map[string]interface{}) {
+		schema[k] = v
+	}
+	schema["description"] = description
+	return schema
+}
+
+// InputCrosswalkCatalogs is the input for the CrosswalkCatalogs tool.
+type InputCrosswalkCatalogs struct {
+	Source        ArtifactInput `json:"source"`
+	Target        ArtifactInput `json:"target"`
+	MinConfidence *float64      `json:"min_confidence,omitempty"`
+	MaxCandidates int           `json:"max_candidates,omitempty"`
+}
+
+// CrosswalkCandidate is a proposed mapping from a source control to a target control.
+type CrosswalkCandidate struct {
+	SourceControl string  `json:"source_control"`
+	SourceTitle   string  `json:"source_title,omitempty"`
+	TargetControl string  `json:"target_control"`
+	TargetTitle   string  `json:"target_title,omitempty"`
+	Confidence    float64 `json:"confidence"`
+	Level         string  `json:"level"`
+	// SharedTerms are the most significant terms both controls use.
+	SharedTerms []string `json:"shared_terms"`
+}
+
+// OutputCrosswalkCatalogs is the output for the CrosswalkCatalogs tool.
+type OutputCrosswalkCatalogs struct {
+	SourceCatalog string               `json:"source_catalog"`
+	TargetCatalog string               `json:"target_catalog"`
+	Candidates    []CrosswalkCandidate `json:"candidates"`
+	// Unmatched lists source controls with no candidate above min_confidence.
+	Unmatched []string `json:"unmatched,omitempty"`
+	Message   string   `json:"message"`
+}
+
+// crosswalkControl is a catalog control reduced to weighted terms.
+type crosswalkControl struct {
+	ID    string
+	Title string
+	terms map[string]float64
+}
+
+// CrosswalkCatalogs proposes mappings between the controls of two catalogs.
+func CrosswalkCatalogs(ctx context.Context, _ *mcp.CallToolRequest, input InputCrosswalkCatalogs) (*mcp.CallToolResult, OutputCrosswalkCatalogs, error) {
+	if input.Source.Content == "" || input.Target.Content == "" {
+		return nil, OutputCrosswalkCatalogs{}, fmt.Errorf("source and target catalogs are required")
+	}
+	minConfidence := defaultCrosswalkMinConfidence
+	if input.MinConfidence != nil {
+		minConfidence = *input.MinConfidence
+	}
+	if minConfidence < 0 || minConfidence > 1 {
+		return nil, OutputCrosswalkCatalogs{}, fmt.Errorf("min_confidence must be between 0 and 1")
+	}
+	maxCandidates := input.MaxCandidates
+	if maxCandidates <= 0 {
+		maxCandidates = defaultCrosswalkMaxCandidates
+	}
+
+	sourceName, targetName := input.Source.Name, input.Target.Name
+	if sourceName == "" {
+		sourceName = "source"
+	}
+	if targetName == "" {
+		targetName = "target"
+	}
+	source, err := crosswalkControls(ctx, sourceName, input.Source.Content)
+	if err != nil {
+		return nil, OutputCrosswalkCatalogs{}, err
+	}
+	target, err := crosswalkControls(ctx, targetName, input.Target.Content)
+	if err != nil {
+		return nil, OutputCrosswalkCatalogs{}, err
+	}
+
+	// Terms found in many controls say little about any one of them
+	idf := crosswalkIDF(append(append([]crosswalkControl{}, source...), target...))
+	sourceVectors := make([]map[string]float64, len(source))
+	for i, c := range source {
+		sourceVectors[i] = crosswalkVector(c.terms, idf)
+	}
+	targetVectors := make([]map[string]float64, len(target))
+	for i, c := range target {
+		targetVectors[i] = crosswalkVector(c.terms, idf)
+	}
+
+	output := OutputCrosswalkCatalogs{SourceCatalog: sourceName, TargetCatalog: targetName, Candidates: []CrosswalkCandidate{}}
+	for i, s := range source {
+		var candidates []CrosswalkCandidate
+		for j, t := range target {
+			score := cosineSimilarity(sourceVectors[i], targetVectors[j])
+			if score < minConfidence || score == 0 {
+				continue
+			}
+			candidates = append(candidates, CrosswalkCandidate{
+				SourceControl: s.ID,
+				SourceTitle:   s.Title,
+				TargetControl: t.ID,
+				TargetTitle:   t.Title,
+				Confidence:    math.Round(score*100) / 100,
+				Level:         confidenceLevel(score),
+				SharedTerms:   sharedTerms(sourceVectors[i], targetVectors[j]),
+			})
+		}
+		if len(candidates) == 0 {
+			output.Unmatched = append(output.Unmatched, s.ID)
+			continue
+		}
+		sort.SliceStable(candidates, func(a, b int) bool { return candidates[a].Confidence > candidates[b].Confidence })
+		if len(candidates) > maxCandidates {
+			candidates = candidates[:maxCandidates]
+		}
+		output.Candidates = append(output.Candidates, candidates...)
+	}
+	sort.SliceStable(output.Candidates, func(a, b int) bool {
+		return output.Candidates[a].Confidence > output.Candidates[b].Confidence
+	})
+
+	output.Message = fmt.Sprintf("%d candidate mapping(s) for %d of %d source control(s) against %d target control(s); confirm each before adding it to guideline-mappings",
+		len(output.Candidates), len(source)-len(output.Unmatched), len(source), len(target))
+	return nil, output, nil
+}
+
+// crosswalkControls extracts the controls of a catalog.
+func crosswalkControls(ctx context.Context, name, content string) ([]crosswalkControl, error) {
+	doc, err := parseArtifact(ctx, content)
+	if err != nil {
+		return nil, &artifactError{Name: name, Err: err}
+	}
+	items, ok := doc["controls"].([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("%s: catalog has no controls", name)
+	}
+
+	var controls []crosswalkControl
+	for _, item := range items {
+		control, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := control["id"].(string)
+		if id == "" {
+			continue
+		}
+		title, _ := control["title"].(string)
+		objective, _ := control["objective"].(string)
+
+		terms := map[string]float64{}
+		for _, term := range crosswalkTerms(title) {
+			terms[term] += crosswalkTitleWeight
+		}
+		for _, term := range crosswalkTerms(objective) {
+			terms[term]++
+		}
+		controls = append(controls, crosswalkControl{ID: id, Title: title, terms: terms})
+	}
+	if len(controls) == 0 {
+		return nil, fmt.Errorf("%s: catalog has no controls with IDs", name)
+	}
+	return controls, nil
+}
+
+// crosswalkTerms lower-cases text into stemmed terms without stop words.
+func crosswalkTerms(text string) []string {
+	var terms []string
+	for _, word := range crosswalkWord.FindAllString(strings.ToLower(text), -1) {
+		if crosswalkStopWords[word] || len(word) < 2 {
+			continue
+		}
+		terms = append(terms, crosswalkStem(word))
+	}
+	return terms
+}
+
+// crosswalkStem strips common English suffixes so "encrypted", "encryption",
+// and "encrypts" compare equal.
+func crosswalkStem(word string) string {
+	for _, suffix := range []string{"ations", "ation", "ions", "ion", "ing", "ed", "es", "s"} {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 4 {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
+
+// crosswalkIDF returns the inverse document frequency of every term.
+func crosswalkIDF(controls []crosswalkControl) map[string]float64 {
+	counts := map[string]int{}
+	for _, c := range controls {
+		for term := range c.terms {
+			counts[term]++
+		}
+	}
+	idf := make(map[string]float64, len(counts))
+	for term, n := range counts {
+		idf[term] = math.Log(1 + float64(len(controls))/float64(n))
+	}
+	return idf
+}
+
+// crosswalkVector weights term frequencies by inverse document frequency.
+func crosswalkVector(terms, idf map[string]float64) map[string]float64 {
+	vector := make(map[string]float64, len(terms))
+	for term, tf := range terms {
+		vector[term] = tf * idf[term]
+	}
+	return vector
+}
+
+// cosineSimilarity compares two term vectors, from 0 (disjoint) to 1 (identical).
+func cosineSimilarity(a, b map[string]float64) float64 {
+	var dot, normA, normB float64
+	for term, weight := range a {
+		dot += weight * b[term]
+		normA += weight * weight
+	}
+	for _, weight := range b {
+		normB += weight * weight
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
+
+// sharedTerms lists up to five terms in both vectors, most significant first.
+func sharedTerms(a, b map[string]float64) []string {
+	var terms []string
+	for term := range a {
+		if b[term] > 0 {
+			terms = append(terms, term)
+		}
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		wi, wj := a[terms[i]]*b[terms[i]], a[terms[j]]*b[terms[j]]
+		if wi != wj {
+			return wi > wj
+		}
+		return terms[i] < terms[j]
+	})
+	if len(terms) > 5 {
+		terms = terms[:5]
+	}
+	return terms
+}
+
+// confidenceLevel buckets a similarity score.
+func confidenceLevel(score float64) string {
+	switch {
+	case score >= 0.6:
+		return confidenceHigh
+	case score >= 0.35:
+		return confidenceMedium
+	default:
+		return confidenceLow
+	}
+}
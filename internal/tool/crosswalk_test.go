@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const crosswalkTestSource = `title: Source
+controls:
+  - id: SRC.C01
+    title: Encrypt Data at Rest
+    objective: Stored data is encrypted with managed keys.
+  - id: SRC.C02
+    title: Enforce Multi-Factor Authentication
+    objective: Administrative accounts require multi-factor authentication.
+  - id: SRC.C03
+    title: Maintain Physical Badge Records
+    objective: Visitors sign the lobby register.
+`
+
+const crosswalkTestTarget = `title: Target
+controls:
+  - id: TGT.01
+    title: Authentication for Administrators
+    objective: Require multi-factor authentication for administrator accounts.
+  - id: TGT.02
+    title: Encryption of Stored Data
+    objective: Data at rest is encrypted using keys from a managed key service.
+  - id: TGT.03
+    title: Network Segmentation
+    objective: Workloads are isolated in separate network segments.
+`
+
+func TestCrosswalkCatalogs(t *testing.T) {
+	zero := 0.0
+	tests := []struct {
+		name          string
+		input         InputCrosswalkCatalogs
+		wantErr       bool
+		errContains   string
+		wantBest      map[string]string
+		wantUnmatched []string
+	}{
+		{
+			name:        "missing target",
+			input:       InputCrosswalkCatalogs{Source: ArtifactInput{Content: crosswalkTestSource}},
+			wantErr:     true,
+			errContains: "source and target catalogs are required",
+		},
+		{
+			name: "target without controls",
+			input: InputCrosswalkCatalogs{
+				Source: ArtifactInput{Content: crosswalkTestSource},
+				Target: ArtifactInput{Name: "empty.yaml", Content: "title: Empty\n"},
+			},
+			wantErr:     true,
+			errContains: "empty.yaml: catalog has no controls",
+		},
+		{
+			name: "invalid min_confidence",
+			input: InputCrosswalkCatalogs{
+				Source:        ArtifactInput{Content: crosswalkTestSource},
+				Target:        ArtifactInput{Content: crosswalkTestTarget},
+				MinConfidence: func() *float64 { v := 1.5; return &v }(),
+			},
+			wantErr:     true,
+			errContains: "min_confidence",
+		},
+		{
+			name: "best candidates",
+			input: InputCrosswalkCatalogs{
+				Source: ArtifactInput{Content: crosswalkTestSource},
+				Target: ArtifactInput{Content: crosswalkTestTarget},
+			},
+			wantBest:      map[string]string{"SRC.C01": "TGT.02", "SRC.C02": "TGT.01"},
+			wantUnmatched: []string{"SRC.C03"},
+		},
+		{
+			name: "zero threshold keeps only overlapping controls",
+			input: InputCrosswalkCatalogs{
+				Source:        ArtifactInput{Content: crosswalkTestSource},
+				Target:        ArtifactInput{Content: crosswalkTestTarget},
+				MinConfidence: &zero,
+				MaxCandidates: 1,
+			},
+			wantBest:      map[string]string{"SRC.C01": "TGT.02", "SRC.C02": "TGT.01"},
+			wantUnmatched: []string{"SRC.C03"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := CrosswalkCatalogs(context.Background(), nil, tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+
+			best := map[string]string{}
+			for _, c := range output.Candidates {
+				if _, ok := best[c.SourceControl]; !ok {
+					best[c.SourceControl] = c.TargetControl
+				}
+				assert.NotEmpty(t, c.SharedTerms)
+				assert.Contains(t, []string{confidenceHigh, confidenceMedium, confidenceLow}, c.Level)
+			}
+			assert.Equal(t, tt.wantBest, best)
+			assert.Equal(t, tt.wantUnmatched, output.Unmatched)
+			for i := 1; i < len(output.Candidates); i++ {
+				assert.GreaterOrEqual(t, output.Candidates[i-1].Confidence, output.Candidates[i].Confidence)
+			}
+		})
+	}
+}
+
+func TestCrosswalkTerms(t *testing.T) {
+	assert.Equal(t, []string{"encrypt", "stor", "data"}, crosswalkTerms("Ensure the encryption of stored data"))
+	assert.Equal(t, crosswalkTerms("encrypted"), crosswalkTerms("encrypts"))
+}
@@ -0,0 +1,326 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// csvTable is one way of flattening an artifact kind into rows.
+type csvTable struct {
+	Name    string
+	Kind    string
+	Columns []string
+	rows    func(doc map[string]interface{}) []map[string]string
+}
+
+// csvTables lists the tables each artifact kind can be exported as; the
+// first table of a kind is its default.
+var csvTables = []csvTable{
+	{
+		Name:    "requirements",
+		Kind:    "ControlCatalog",
+		Columns: []string{"catalog_id", "family_id", "family_title", "control_id", "control_title", "requirement_id", "text", "applicability", "recommendation"},
+		rows:    catalogRequirementRows,
+	},
+	{
+		Name:    "controls",
+		Kind:    "ControlCatalog",
+		Columns: []string{"catalog_id", "family_id", "family_title", "control_id", "title", "objective", "requirements"},
+		rows:    catalogControlRows,
+	},
+	{
+		Name:    "mappings",
+		Kind:    "ControlCatalog",
+		Columns: []string{"catalog_id", "control_id", "mapping_type", "reference_id", "entry_id", "strength", "remarks"},
+		rows:    catalogMappingRows,
+	},
+	{
+		Name:    "assessments",
+		Kind:    "EvaluationLog",
+		Columns: []string{"log_id", "catalog_id", "control_id", "evaluation", "requirement_id", "plan_id", "result", "description", "start", "end"},
+		rows:    evaluationAssessmentRows,
+	},
+	{
+		Name:    "evaluations",
+		Kind:    "EvaluationLog",
+		Columns: []string{"log_id", "catalog_id", "control_id", "name", "result", "start", "end"},
+		rows:    evaluationRows,
+	},
+}
+
+// MetadataExportArtifactCSV describes the ExportArtifactCSV tool.
+var MetadataExportArtifactCSV = &mcp.Tool{
+	Name: "export_artifact_csv",
+	Description: "Flatten a ControlCatalog (controls, requirements, or mappings) or an EvaluationLog (evaluations or " +
+		"assessment logs) into CSV with a selectable column set, for spreadsheet-based review workflows.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"artifact_content": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML content of the Gemara artifact to export",
+			},
+			"artifact_uri": map[string]interface{}{
+				"type": "string",
+				"description": "URI of the artifact to export instead of inline content: file:// (within the workspace root), " +
+					"https://, or gemara://examples/{definition}/{n}",
+			},
+			"table": map[string]interface{}{
+				"type":        "string",
+				"enum":        csvTableNames(),
+				"description": "Rows to export: requirements (default), controls, or mappings for catalogs; assessments (default) or evaluations for evaluation logs",
+			},
+			"columns": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Columns to include, in order (default: every column of the table)",
+			},
+		},
+	},
+}
+
+// InputExportArtifactCSV is the input for the ExportArtifactCSV tool.
+type InputExportArtifactCSV struct {
+	ArtifactContent string   `json:"artifact_content,omitempty"`
+	ArtifactURI     string   `json:"artifact_uri,omitempty"`
+	Table           string   `json:"table,omitempty"`
+	Columns         []string `json:"columns,omitempty"`
+}
+
+// OutputExportArtifactCSV is the output for the ExportArtifactCSV tool.
+type OutputExportArtifactCSV struct {
+	Kind    string   `json:"kind"`
+	Table   string   `json:"table"`
+	Columns []string `json:"columns"`
+	Rows    int      `json:"rows"`
+	CSV     string   `json:"csv"`
+	Message string   `json:"message"`
+}
+
+// ExportArtifactCSV flattens an artifact into CSV.
+func ExportArtifactCSV(ctx context.Context, _ *mcp.CallToolRequest, input InputExportArtifactCSV) (*mcp.CallToolResult, OutputExportArtifactCSV, error) {
+	content, err := artifactInputContent(ctx, input.ArtifactContent, input.ArtifactURI)
+	if err != nil {
+		return nil, OutputExportArtifactCSV{}, err
+	}
+	doc, err := parseArtifact(ctx, content)
+	if err != nil {
+		return nil, OutputExportArtifactCSV{}, err
+	}
+
+	kind := artifactKind(doc)
+	table, err := selectCSVTable(kind, input.Table)
+	if err != nil {
+		return nil, OutputExportArtifactCSV{}, err
+	}
+	columns := table.Columns
+	if len(input.Columns) > 0 {
+		known := map[string]bool{}
+		for _, c := range table.Columns {
+			known[c] = true
+		}
+		for _, c := range input.Columns {
+			if !known[c] {
+				return nil, OutputExportArtifactCSV{}, fmt.Errorf("unknown column %q for the %s table (available: %s)", c, table.Name, strings.Join(table.Columns, ", "))
+			}
+		}
+		columns = input.Columns
+	}
+
+	rows := table.rows(doc)
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(columns); err != nil {
+		return nil, OutputExportArtifactCSV{}, err
+	}
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, c := range columns {
+			record[i] = row[c]
+		}
+		if err := w.Write(record); err != nil {
+			return nil, OutputExportArtifactCSV{}, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, OutputExportArtifactCSV{}, err
+	}
+
+	output := OutputExportArtifactCSV{
+		Kind:    kind,
+		Table:   table.Name,
+		Columns: columns,
+		Rows:    len(rows),
+		CSV:     buf.String(),
+		Message: fmt.Sprintf("Exported %d %s row(s) of %s with %d column(s)", len(rows), table.Name, kind, len(columns)),
+	}
+	return nil, output, nil
+}
+
+// selectCSVTable returns the named table of an artifact kind, or its default table.
+func selectCSVTable(kind, name string) (csvTable, error) {
+	var names []string
+	for _, t := range csvTables {
+		if t.Kind != kind {
+			continue
+		}
+		if name == "" || t.Name == name {
+			return t, nil
+		}
+		names = append(names, t.Name)
+	}
+	if len(names) == 0 {
+		if kind == "" {
+			kind = "unknown"
+		}
+		return csvTable{}, fmt.Errorf("cannot export %s artifacts: expected a ControlCatalog or EvaluationLog", kind)
+	}
+	return csvTable{}, fmt.Errorf("unknown table %q for %s (available: %s)", name, kind, strings.Join(names, ", "))
+}
+
+// csvTableNames lists the names of every table.
+func csvTableNames() []string {
+	names := make([]string, 0, len(csvTables))
+	for _, t := range csvTables {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// catalogControls calls fn with the columns shared by every row of a control.
+func catalogControls(doc map[string]interface{}, fn func(control map[string]interface{}, base map[string]string)) {
+	metadata, _ := doc["metadata"].(map[string]interface{})
+	familyTitles := map[string]string{}
+	for _, f := range mapList(doc["families"]) {
+		familyTitles[stringField(f, "id")] = stringField(f, "title")
+	}
+	for _, c := range mapList(doc["controls"]) {
+		family := stringField(c, "family")
+		fn(c, map[string]string{
+			"catalog_id":    stringField(metadata, "id"),
+			"family_id":     family,
+			"family_title":  familyTitles[family],
+			"control_id":    stringField(c, "id"),
+			"control_title": stringField(c, "title"),
+		})
+	}
+}
+
+func catalogControlRows(doc map[string]interface{}) []map[string]string {
+	var rows []map[string]string
+	catalogControls(doc, func(control map[string]interface{}, row map[string]string) {
+		row["title"] = row["control_title"]
+		row["objective"] = strings.TrimSpace(stringField(control, "objective"))
+		row["requirements"] = fmt.Sprint(len(mapList(control["assessment-requirements"])))
+		rows = append(rows, row)
+	})
+	return rows
+}
+
+func catalogRequirementRows(doc map[string]interface{}) []map[string]string {
+	var rows []map[string]string
+	catalogControls(doc, func(control map[string]interface{}, base map[string]string) {
+		for _, r := range mapList(control["assessment-requirements"]) {
+			row := copyRow(base)
+			row["requirement_id"] = stringField(r, "id")
+			row["text"] = strings.TrimSpace(stringField(r, "text"))
+			row["applicability"] = strings.Join(stringList(r["applicability"]), "; ")
+			row["recommendation"] = strings.TrimSpace(stringField(r, "recommendation"))
+			rows = append(rows, row)
+		}
+	})
+	return rows
+}
+
+func catalogMappingRows(doc map[string]interface{}) []map[string]string {
+	var rows []map[string]string
+	catalogControls(doc, func(control map[string]interface{}, base map[string]string) {
+		for _, kind := range []string{"guideline", "threat"} {
+			for _, m := range mapList(control[kind+"-mappings"]) {
+				for _, e := range mapList(m["entries"]) {
+					row := copyRow(base)
+					row["mapping_type"] = kind
+					row["reference_id"] = stringField(m, "reference-id")
+					row["entry_id"] = stringField(e, "reference-id")
+					row["strength"] = csvValue(e["strength"])
+					row["remarks"] = strings.TrimSpace(stringField(e, "remarks"))
+					rows = append(rows, row)
+				}
+			}
+		}
+	})
+	return rows
+}
+
+// logEvaluations calls fn with the columns shared by every row of an evaluation.
+func logEvaluations(doc map[string]interface{}, fn func(evaluation map[string]interface{}, base map[string]string)) {
+	metadata, _ := doc["metadata"].(map[string]interface{})
+	for _, e := range mapList(doc["evaluations"]) {
+		control, _ := e["control"].(map[string]interface{})
+		fn(e, map[string]string{
+			"log_id":     stringField(metadata, "id"),
+			"catalog_id": stringField(control, "reference-id"),
+			"control_id": mappingEntryID(control),
+			"evaluation": stringField(e, "name"),
+		})
+	}
+}
+
+func evaluationRows(doc map[string]interface{}) []map[string]string {
+	var rows []map[string]string
+	logEvaluations(doc, func(evaluation map[string]interface{}, row map[string]string) {
+		row["name"] = row["evaluation"]
+		row["result"] = stringField(evaluation, "result")
+		row["start"] = csvValue(evaluation["start"])
+		row["end"] = csvValue(evaluation["end"])
+		rows = append(rows, row)
+	})
+	return rows
+}
+
+func evaluationAssessmentRows(doc map[string]interface{}) []map[string]string {
+	var rows []map[string]string
+	logEvaluations(doc, func(evaluation map[string]interface{}, base map[string]string) {
+		for _, l := range mapList(evaluation["assessment-logs"]) {
+			row := copyRow(base)
+			row["requirement_id"] = mappingEntryID(l["requirement"])
+			row["plan_id"] = mappingEntryID(l["plan"])
+			row["result"] = stringField(l, "result")
+			row["description"] = strings.TrimSpace(stringField(l, "description"))
+			row["start"] = csvValue(l["start"])
+			row["end"] = csvValue(l["end"])
+			rows = append(rows, row)
+		}
+	})
+	return rows
+}
+
+func copyRow(row map[string]string) map[string]string {
+	c := make(map[string]string, len(row))
+	for k, v := range row {
+		c[k] = v
+	}
+	return c
+}
+
+// csvValue formats a scalar for a CSV cell.
+func csvValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportArtifactCSV(t *testing.T) {
+	read := func(name string) string {
+		content, err := os.ReadFile(filepath.Join("test-data", name))
+		require.NoError(t, err, "should read test data file")
+		return string(content)
+	}
+	catalog, log := read("good-ccc.yaml"), read("evaluation-log.yaml")
+
+	tests := []struct {
+		name        string
+		input       InputExportArtifactCSV
+		wantTable   string
+		wantHeader  []string
+		wantRow     []string
+		wantRows    int
+		errContains string
+	}{
+		{
+			name:       "catalog requirements by default",
+			input:      InputExportArtifactCSV{ArtifactContent: catalog},
+			wantTable:  "requirements",
+			wantHeader: csvTables[0].Columns,
+		},
+		{
+			name:       "catalog controls",
+			input:      InputExportArtifactCSV{ArtifactContent: catalog, Table: "controls", Columns: []string{"control_id", "title", "family_title"}},
+			wantTable:  "controls",
+			wantHeader: []string{"control_id", "title", "family_title"},
+			wantRow:    []string{"CCC.C01", "Prevent Unencrypted Requests", "Data Protection"},
+			wantRows:   5,
+		},
+		{
+			name:       "catalog mappings",
+			input:      InputExportArtifactCSV{ArtifactContent: catalog, Table: "mappings", Columns: []string{"control_id", "mapping_type", "reference_id", "entry_id", "strength"}},
+			wantTable:  "mappings",
+			wantHeader: []string{"control_id", "mapping_type", "reference_id", "entry_id", "strength"},
+			wantRow:    []string{"CCC.C01", "guideline", "CSF", "PR.DS-02", "7"},
+		},
+		{
+			name:       "evaluation assessments by default",
+			input:      InputExportArtifactCSV{ArtifactContent: log, Columns: []string{"control_id", "requirement_id", "plan_id", "result"}},
+			wantTable:  "assessments",
+			wantHeader: []string{"control_id", "requirement_id", "plan_id", "result"},
+			wantRow:    []string{"CCC.C01", "CCC.C01.TR01", "AP-C01-TR01", "Passed"},
+		},
+		{
+			name:       "evaluations",
+			input:      InputExportArtifactCSV{ArtifactContent: log, Table: "evaluations", Columns: []string{"log_id", "control_id", "result"}},
+			wantTable:  "evaluations",
+			wantHeader: []string{"log_id", "control_id", "result"},
+			wantRow:    []string{"EVAL-2025-01", "CCC.C06", "Failed"},
+			wantRows:   2,
+		},
+		{
+			name:        "table of another kind",
+			input:       InputExportArtifactCSV{ArtifactContent: log, Table: "controls"},
+			errContains: "unknown table \"controls\" for EvaluationLog (available: assessments, evaluations)",
+		},
+		{
+			name:        "unknown column",
+			input:       InputExportArtifactCSV{ArtifactContent: catalog, Columns: []string{"owner"}},
+			errContains: "unknown column \"owner\" for the requirements table",
+		},
+		{
+			name:        "unsupported kind",
+			input:       InputExportArtifactCSV{ArtifactContent: read("policy.yaml")},
+			errContains: "cannot export Policy artifacts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := ExportArtifactCSV(context.Background(), nil, tt.input)
+			if tt.errContains != "" {
+				require.Error(t, err, "should return error")
+				assert.Contains(t, err.Error(), tt.errContains, "error should contain expected message")
+				return
+			}
+			require.NoError(t, err, "should not return error")
+			assert.Equal(t, tt.wantTable, output.Table, "table should match")
+
+			records, err := csv.NewReader(strings.NewReader(output.CSV)).ReadAll()
+			require.NoError(t, err, "output should be valid CSV")
+			assert.Equal(t, tt.wantHeader, records[0], "header should list the columns")
+			assert.Equal(t, output.Rows, len(records)-1, "row count should match")
+			assert.NotZero(t, output.Rows, "should export rows")
+			if tt.wantRows > 0 {
+				assert.Equal(t, tt.wantRows, output.Rows, "row count should match")
+			}
+			if tt.wantRow != nil {
+				assert.Contains(t, records[1:], tt.wantRow, "should contain the expected row")
+			}
+		})
+	}
+}
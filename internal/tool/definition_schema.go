@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"cuelang.org/go/encoding/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var (
+	jsonSchemaCacheMu sync.Mutex
+	// jsonSchemaCache holds generated JSON Schemas keyed by module version and definition.
+	jsonSchemaCache = map[string]map[string]interface{}{}
+)
+
+// MetadataGetDefinitionSchema describes the GetDefinitionSchema tool.
+var MetadataGetDefinitionSchema = &mcp.Tool{
+	Name: "get_definition_schema",
+	Description: "Export a Gemara CUE definition as JSON Schema (draft 2020-12) for use by IDEs and " +
+		"JSON Schema validators such as yaml-language-server.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"definition"},
+		"properties": map[string]interface{}{
+			"definition": map[string]interface{}{
+				"type":        "string",
+				"description": "CUE definition name to export (e.g., '#ControlCatalog', '#Policy')",
+			},
+		},
+	},
+}
+
+// InputGetDefinitionSchema is the input for the GetDefinitionSchema tool.
+type InputGetDefinitionSchema struct {
+	Definition string `json:"definition"`
+}
+
+// OutputGetDefinitionSchema is the output for the GetDefinitionSchema tool.
+type OutputGetDefinitionSchema struct {
+	Definition    string                 `json:"definition"`
+	ModuleVersion string                 `json:"module_version"`
+	JSONSchema    map[string]interface{} `json:"json_schema"`
+	Cached        bool                   `json:"cached"`
+}
+
+// GetDefinitionSchema generates the JSON Schema for a Gemara CUE definition.
+func GetDefinitionSchema(ctx context.Context, _ *mcp.CallToolRequest, input InputGetDefinitionSchema) (*mcp.CallToolResult, OutputGetDefinitionSchema, error) {
+	if input.Definition == "" {
+		return nil, OutputGetDefinitionSchema{}, fmt.Errorf("definition is required")
+	}
+	definition := normalizeDefinition(input.Definition)
+
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputGetDefinitionSchema{}, err
+	}
+
+	output := OutputGetDefinitionSchema{
+		Definition:    definition,
+		ModuleVersion: schema.version,
+	}
+
+	generated, cached, err := definitionJSONSchema(schema, definition)
+	if err != nil {
+		return nil, OutputGetDefinitionSchema{}, err
+	}
+
+	output.JSONSchema = generated
+	output.Cached = cached
+	return nil, output, nil
+}
+
+// definitionJSONSchema returns the JSON Schema for a definition, reusing schemas
+// generated earlier for the same module version.
+func definitionJSONSchema(schema *gemaraSchema, definition string) (map[string]interface{}, bool, error) {
+	// Only reuse cached schemas when the module version is known
+	cacheKey := schema.version + "/" + definition
+	cacheable := schema.version != unknownVersion
+	if cacheable {
+		jsonSchemaCacheMu.Lock()
+		cached, ok := jsonSchemaCache[cacheKey]
+		jsonSchemaCacheMu.Unlock()
+		if ok {
+			return cached, true, nil
+		}
+	}
+
+	generated, err := generateJSONSchema(schema, definition)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if cacheable {
+		jsonSchemaCacheMu.Lock()
+		jsonSchemaCache[cacheKey] = generated
+		jsonSchemaCacheMu.Unlock()
+	}
+	return generated, false, nil
+}
+
+// generateJSONSchema converts a schema definition into a JSON Schema document.
+func generateJSONSchema(schema *gemaraSchema, definition string) (map[string]interface{}, error) {
+	entrypoint, err := schema.lookupDefinition(definition)
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := jsonschema.Generate(entrypoint, &jsonschema.GenerateConfig{
+		Version: jsonschema.VersionDraft2020_12,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate JSON Schema for %s: %w", definition, err)
+	}
+
+	value := schema.ctx.BuildExpr(expr)
+	if err := value.Err(); err != nil {
+		return nil, fmt.Errorf("failed to build JSON Schema for %s: %w", definition, err)
+	}
+
+	raw, err := value.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON Schema for %s: %w", definition, err)
+	}
+
+	var generated map[string]interface{}
+	if err := json.Unmarshal(raw, &generated); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON Schema for %s: %w", definition, err)
+	}
+	return generated, nil
+}
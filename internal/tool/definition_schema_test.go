@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchemaVersion = "v0.0.0-test"
+
+func TestGetDefinitionSchema(t *testing.T) {
+	useTestSchema(t)
+
+	tests := []struct {
+		name           string
+		input          InputGetDefinitionSchema
+		wantErr        bool
+		errContains    string
+		validateOutput func(t *testing.T, output OutputGetDefinitionSchema)
+	}{
+		{
+			name:        "missing definition",
+			input:       InputGetDefinitionSchema{},
+			wantErr:     true,
+			errContains: "definition is required",
+		},
+		{
+			name:        "unknown definition",
+			input:       InputGetDefinitionSchema{Definition: "#Unknown"},
+			wantErr:     true,
+			errContains: "not found in schema",
+		},
+		{
+			name:  "control catalog",
+			input: InputGetDefinitionSchema{Definition: "ControlCatalog"},
+			validateOutput: func(t *testing.T, output OutputGetDefinitionSchema) {
+				assert.Equal(t, "#ControlCatalog", output.Definition, "hash prefix should be added")
+				assert.Equal(t, testSchemaVersion, output.ModuleVersion)
+				assert.Equal(t, "https://json-schema.org/draft/2020-12/schema", output.JSONSchema["$schema"])
+				assert.Contains(t, output.JSONSchema, "$defs", "referenced definitions should be emitted")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := GetDefinitionSchema(context.Background(), nil, tt.input)
+
+			if tt.wantErr {
+				require.Error(t, err, "should return error")
+				assert.Contains(t, err.Error(), tt.errContains, "error should contain expected message")
+				return
+			}
+
+			require.NoError(t, err, "should not return error")
+			if tt.validateOutput != nil {
+				tt.validateOutput(t, output)
+			}
+		})
+	}
+}
+
+func TestGetDefinitionSchemaCache(t *testing.T) {
+	useTestSchema(t)
+	jsonSchemaCache = map[string]map[string]interface{}{}
+
+	_, first, err := GetDefinitionSchema(context.Background(), nil, InputGetDefinitionSchema{Definition: "#Policy"})
+	require.NoError(t, err, "first call should not error")
+	assert.False(t, first.Cached, "first call should not be cached")
+
+	_, second, err := GetDefinitionSchema(context.Background(), nil, InputGetDefinitionSchema{Definition: "#Policy"})
+	require.NoError(t, err, "second call should not error")
+	assert.True(t, second.Cached, "second call should be cached")
+	assert.Equal(t, first.JSONSchema, second.JSONSchema, "cached schema should match")
+}
+
+func TestModuleVersion(t *testing.T) {
+	assert.Equal(t, "v0.7.0", moduleVersion("/home/user/.cache/cue/mod/extract/github.com/gemaraproj/gemara@v0.7.0"))
+	assert.Equal(t, "v1.2.3", moduleVersion("/cache/mod/extract/github.com/gemaraproj/gemara@v1.2.3/schemas"))
+	assert.Equal(t, unknownVersion, moduleVersion("/tmp/gemara"))
+}
+
+// useTestSchema replaces the registry schema loader with the offline fixture for the duration of a test.
+func useTestSchema(t testing.TB) {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("test-data", "schema.cue"))
+	require.NoError(t, err, "should be able to read test schema")
+
+	original := schemaLoader
+	schemaLoader = func(context.Context) (*gemaraSchema, error) {
+		cueCtx := cuecontext.New()
+		value := cueCtx.CompileBytes(content)
+		if err := value.Err(); err != nil {
+			return nil, err
+		}
+		return &gemaraSchema{ctx: cueCtx, value: value, version: testSchemaVersion, digest: contentDigest(content)}, nil
+	}
+	t.Cleanup(func() { schemaLoader = original })
+}
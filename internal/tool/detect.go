@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"cuelang.org/go/cue"
+	cueerrors "cuelang.org/go/cue/errors"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// definitionAuto asks a tool to detect the definition of an artifact.
+	definitionAuto = "auto"
+
+	maxDetectionCandidates = 5
+)
+
+// MetadataDetectGemaraArtifactType describes the DetectGemaraArtifactType tool.
+var MetadataDetectGemaraArtifactType = &mcp.Tool{
+	Name: "detect_gemara_artifact_type",
+	Description: "Detect which Gemara definition an artifact is by unifying it against every definition in the schema, " +
+		"and report the best match with a confidence score.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"artifact_content"},
+		"properties": map[string]interface{}{
+			"artifact_content": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML content of the Gemara artifact to identify",
+			},
+		},
+	},
+}
+
+// InputDetectGemaraArtifactType is the input for the DetectGemaraArtifactType tool.
+type InputDetectGemaraArtifactType struct {
+	ArtifactContent string `json:"artifact_content"`
+}
+
+// DefinitionMatch scores how well an artifact fits a definition.
+type DefinitionMatch struct {
+	Definition string  `json:"definition"`
+	Confidence float64 `json:"confidence"`
+	Valid      bool    `json:"valid"`
+	ErrorCount int     `json:"error_count"`
+}
+
+// OutputDetectGemaraArtifactType is the output for the DetectGemaraArtifactType tool.
+type OutputDetectGemaraArtifactType struct {
+	Definition string            `json:"definition"`
+	Confidence float64           `json:"confidence"`
+	Candidates []DefinitionMatch `json:"candidates"`
+	Message    string            `json:"message"`
+}
+
+// DetectGemaraArtifactType reports the definition that best matches an artifact.
+func DetectGemaraArtifactType(ctx context.Context, _ *mcp.CallToolRequest, input InputDetectGemaraArtifactType) (*mcp.CallToolResult, OutputDetectGemaraArtifactType, error) {
+	if input.ArtifactContent == "" {
+		return nil, OutputDetectGemaraArtifactType{}, fmt.Errorf("artifact_content is required")
+	}
+
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputDetectGemaraArtifactType{}, err
+	}
+
+	matches, err := detectDefinition(ctx, schema, input.ArtifactContent)
+	if err != nil {
+		return nil, OutputDetectGemaraArtifactType{}, err
+	}
+
+	best := matches[0]
+	if len(matches) > maxDetectionCandidates {
+		matches = matches[:maxDetectionCandidates]
+	}
+	output := OutputDetectGemaraArtifactType{
+		Definition: best.Definition,
+		Confidence: best.Confidence,
+		Candidates: matches,
+		Message:    fmt.Sprintf("Best match is %s (confidence %.2f)", best.Definition, best.Confidence),
+	}
+	return nil, output, nil
+}
+
+// detectDefinition scores the artifact against every struct definition in the
+// schema and returns the matches ordered from best to worst.
+func detectDefinition(ctx context.Context, schema *gemaraSchema, content string) ([]DefinitionMatch, error) {
+	return detectFormatDefinition(ctx, schema, formatYAML, content)
+}
+
+// detectFormatDefinition is detectDefinition for content encoded in format.
+func detectFormatDefinition(ctx context.Context, schema *gemaraSchema, format, content string) ([]DefinitionMatch, error) {
+	doc, err := decodeArtifact(ctx, schema, format, content)
+	if err != nil {
+		return nil, err
+	}
+	data, err := buildArtifact(schema, format, content)
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := schema.value.Fields(cue.Definitions(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list definitions: %w", err)
+	}
+
+	var matches []DefinitionMatch
+	for iter.Next() {
+		if !iter.Selector().IsDefinition() || iter.Value().IncompleteKind() != cue.StructKind {
+			continue
+		}
+		matches = append(matches, scoreDefinition(iter.Selector().String(), iter.Value(), doc, data))
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("schema defines no artifact definitions")
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Confidence != matches[j].Confidence {
+			return matches[i].Confidence > matches[j].Confidence
+		}
+		return matches[i].ErrorCount < matches[j].ErrorCount
+	})
+	return matches, nil
+}
+
+// scoreDefinition weighs how many of the artifact's top-level keys the definition
+// declares, how many of its required fields are present, and whether it validates.
+func scoreDefinition(name string, definition cue.Value, doc map[string]interface{}, data cue.Value) DefinitionMatch {
+	declared, required, present := 0, 0, 0
+	fields := map[string]bool{}
+	for _, field := range schemaFields(definition) {
+		fields[field.name] = true
+		if field.required {
+			required++
+			if _, ok := doc[field.name]; ok {
+				present++
+			}
+		}
+	}
+	for key := range doc {
+		if fields[key] {
+			declared++
+		}
+	}
+
+	keyScore := float64(declared) / float64(len(doc))
+	requiredScore := 1.0
+	if required > 0 {
+		requiredScore = float64(present) / float64(required)
+	}
+
+	err := definition.Unify(data).Validate(cue.Concrete(true))
+	validScore := 0.0
+	if err == nil {
+		validScore = 1
+	}
+
+	confidence := 0.4*keyScore + 0.4*requiredScore + 0.2*validScore
+	if declared == 0 {
+		confidence = 0
+	}
+	return DefinitionMatch{
+		Definition: name,
+		Confidence: math.Round(confidence*100) / 100,
+		Valid:      err == nil,
+		ErrorCount: len(cueerrors.Errors(err)),
+	}
+}
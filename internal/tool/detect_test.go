@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectGemaraArtifactType(t *testing.T) {
+	useTestSchema(t)
+
+	policy, err := os.ReadFile(filepath.Join("test-data", "policy.yaml"))
+	require.NoError(t, err, "should read policy")
+	evaluationLog, err := os.ReadFile(filepath.Join("test-data", "evaluation-log.yaml"))
+	require.NoError(t, err, "should read evaluation log")
+
+	tests := []struct {
+		name           string
+		content        string
+		wantDefinition string
+		wantErr        string
+	}{
+		{name: "missing content", wantErr: "artifact_content is required"},
+		{name: "invalid YAML", content: "invalid: yaml: [unclosed", wantErr: "failed to parse YAML"},
+		{name: "control catalog", content: readExample(t, "ControlCatalog", 1), wantDefinition: "#ControlCatalog"},
+		{name: "guidance document", content: readExample(t, "GuidanceDocument", 1), wantDefinition: "#GuidanceDocument"},
+		{name: "policy", content: string(policy), wantDefinition: "#Policy"},
+		{name: "evaluation log", content: string(evaluationLog), wantDefinition: "#EvaluationLog"},
+		{name: "bare control", content: "id: C01\nfamily: f\ntitle: t\nobjective: o\nassessment-requirements: []\n", wantDefinition: "#Control"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := DetectGemaraArtifactType(context.Background(), nil, InputDetectGemaraArtifactType{ArtifactContent: tt.content})
+			if tt.wantErr != "" {
+				require.Error(t, err, "should return error")
+				assert.Contains(t, err.Error(), tt.wantErr, "error should contain expected message")
+				return
+			}
+			require.NoError(t, err, "should not return error")
+			assert.Equal(t, tt.wantDefinition, output.Definition, "best match should be detected: %+v", output.Candidates)
+			assert.Greater(t, output.Confidence, 0.5, "best match should be confident")
+			assert.LessOrEqual(t, len(output.Candidates), maxDetectionCandidates, "candidates should be capped")
+			for i := 1; i < len(output.Candidates); i++ {
+				assert.GreaterOrEqual(t, output.Candidates[i-1].Confidence, output.Candidates[i].Confidence, "candidates should be ordered")
+			}
+		})
+	}
+}
+
+func TestValidateGemaraArtifactAuto(t *testing.T) {
+	useTestSchema(t)
+
+	_, output, err := ValidateGemaraArtifact(context.Background(), nil, InputValidateGemaraArtifact{
+		ArtifactContent: readExample(t, "ControlCatalog", 1),
+		Definition:      definitionAuto,
+	})
+	require.NoError(t, err, "should not return error")
+	assert.Equal(t, "#ControlCatalog", output.Definition, "should report the detected definition")
+	assert.True(t, output.Valid, "example should be valid: %v", output.Errors)
+}
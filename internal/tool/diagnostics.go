@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	cueerrors "cuelang.org/go/cue/errors"
+)
+
+// artifactFilename is the name artifact content is extracted under, so error
+// positions in the artifact can be told apart from positions in the schema.
+const artifactFilename = "artifact.yaml"
+
+// ValidationDiagnostic is a machine-readable validation error that editors can
+// place inline.
+type ValidationDiagnostic struct {
+	Message  string `json:"message"`
+	Pointer  string `json:"pointer"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// validationDiagnostics converts a CUE validation error into diagnostics, one per
+// artifact location. prefix is the path of data within the whole artifact.
+func validationDiagnostics(err error, schema, data cue.Value, prefix []pathSegment) []ValidationDiagnostic {
+	var diagnostics []ValidationDiagnostic
+	index := map[string]int{}
+	for _, e := range cueerrors.Errors(err) {
+		segments := errorSegments(e.Path())
+		pointer := jsonPointer(append(append([]pathSegment{}, prefix...), segments...))
+		format, args := e.Msg()
+		message := fmt.Sprintf(format, args...)
+
+		// CUE reports each failed disjunct separately; fold them into one diagnostic
+		if i, ok := index[pointer]; ok {
+			d := &diagnostics[i]
+			if strings.HasSuffix(d.Message, ":") {
+				d.Message += " " + message
+			} else {
+				d.Message += "; " + message
+			}
+			continue
+		}
+
+		d := ValidationDiagnostic{Message: message, Pointer: pointer}
+		d.Line, d.Column = artifactPosition(e, data, segments)
+		if expected, err := schemaAt(schema, segments); err == nil && isScalarSchema(expected) {
+			d.Expected = fmt.Sprint(expected)
+		}
+		if actual := data.LookupPath(cuePath(segments)); actual.Exists() && actual.IsConcrete() && isScalarSchema(actual) {
+			d.Actual = fmt.Sprint(actual)
+		}
+
+		index[pointer] = len(diagnostics)
+		diagnostics = append(diagnostics, d)
+	}
+	return diagnostics
+}
+
+// errorSegments converts a CUE error path into YAML path segments, dropping the
+// definition it was reported against.
+func errorSegments(path []string) []pathSegment {
+	if len(path) > 0 && strings.HasPrefix(path[0], "#") {
+		path = path[1:]
+	}
+	segments := make([]pathSegment, 0, len(path))
+	for _, elem := range path {
+		if n, err := strconv.Atoi(elem); err == nil {
+			segments = append(segments, pathSegment{Index: n, IsIndex: true})
+			continue
+		}
+		if unquoted, err := strconv.Unquote(elem); err == nil {
+			elem = unquoted
+		}
+		segments = append(segments, pathSegment{Key: elem})
+	}
+	return segments
+}
+
+// artifactPosition returns the line and column of an error in the artifact. Errors
+// without an artifact position, such as missing fields, are placed at the nearest
+// enclosing node that exists.
+func artifactPosition(e cueerrors.Error, data cue.Value, segments []pathSegment) (int, int) {
+	for _, pos := range e.InputPositions() {
+		if pos.Filename() == artifactFilename {
+			return pos.Line(), pos.Column()
+		}
+	}
+	for n := len(segments); n >= 0; n-- {
+		v := data.LookupPath(cuePath(segments[:n]))
+		if pos := v.Pos(); v.Exists() && pos.IsValid() && pos.Filename() == artifactFilename {
+			return pos.Line(), pos.Column()
+		}
+	}
+	return 0, 0
+}
+
+// cuePath converts YAML path segments into a CUE path over concrete data.
+func cuePath(segments []pathSegment) cue.Path {
+	selectors := make([]cue.Selector, 0, len(segments))
+	for _, seg := range segments {
+		if seg.IsIndex {
+			selectors = append(selectors, cue.Index(seg.Index))
+		} else {
+			selectors = append(selectors, cue.Str(seg.Key))
+		}
+	}
+	return cue.MakePath(selectors...)
+}
+
+// jsonPointer renders path segments as an RFC 6901 JSON pointer.
+func jsonPointer(segments []pathSegment) string {
+	var b strings.Builder
+	for _, seg := range segments {
+		b.WriteByte('/')
+		if seg.IsIndex {
+			b.WriteString(strconv.Itoa(seg.Index))
+			continue
+		}
+		b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(seg.Key))
+	}
+	return b.String()
+}
+
+// isScalarSchema reports whether v describes a scalar, so it prints compactly.
+func isScalarSchema(v cue.Value) bool {
+	return v.IncompleteKind()&(cue.StructKind|cue.ListKind) == 0
+}
@@ -0,0 +1,292 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Doctor check statuses, from best to worst.
+const (
+	DoctorOK   = "ok"
+	DoctorSkip = "skip"
+	DoctorWarn = "warn"
+	DoctorFail = "fail"
+)
+
+// doctorDialTimeout bounds the connection attempt to a configured proxy.
+const doctorDialTimeout = 5 * time.Second
+
+// DoctorCheck is the outcome of one diagnostic, with what to do about a failure.
+type DoctorCheck struct {
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Detail      string `json:"detail"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// DoctorReport lists the outcome of every diagnostic. Passed is false when a
+// check failed; warnings do not fail the report.
+type DoctorReport struct {
+	Checks []DoctorCheck `json:"checks"`
+	Passed bool          `json:"passed"`
+}
+
+// Doctor checks the configuration the server depends on: the outbound proxy,
+// the CUE registry, the lexicon sources, the caches, and that the schema
+// compiles. It uses the HTTP, registry, lexicon, and storage configuration of
+// ctx, as a server started with the same flags would.
+func Doctor(ctx context.Context) DoctorReport {
+	var report DoctorReport
+	report.Checks = append(report.Checks, doctorProxy(ctx))
+	report.Checks = append(report.Checks, doctorRegistry(ctx))
+	report.Checks = append(report.Checks, doctorLexicon(ctx)...)
+	report.Checks = append(report.Checks, doctorModuleCache(), doctorCacheStorage(ctx))
+	report.Checks = append(report.Checks, doctorSchema(ctx))
+
+	report.Passed = true
+	for _, c := range report.Checks {
+		if c.Status == DoctorFail {
+			report.Passed = false
+		}
+	}
+	return report
+}
+
+// doctorProxy reports the proxy outbound requests use and whether it accepts connections.
+func doctorProxy(ctx context.Context) DoctorCheck {
+	check := DoctorCheck{Name: "proxy"}
+	target := &url.URL{Scheme: "https", Host: "registry.cue.works"}
+	for _, source := range DefaultLexicon.Sources() {
+		if u, err := url.Parse(source); err == nil && u.Scheme == "https" {
+			target = u
+			break
+		}
+	}
+	proxy, err := http.ProxyFromEnvironment(&http.Request{URL: target})
+	switch {
+	case err != nil:
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("invalid proxy configuration: %v", err)
+		check.Remediation = "Set HTTPS_PROXY to a URL such as http://proxy.example.com:3128, or unset it"
+		return check
+	case proxy == nil:
+		check.Status = DoctorOK
+		check.Detail = "no proxy configured for " + target.Host + "; connecting directly (set HTTPS_PROXY and NO_PROXY to use one)"
+		return check
+	}
+
+	host := proxy.Host
+	if proxy.Port() == "" {
+		host = net.JoinHostPort(proxy.Hostname(), "80")
+	}
+	dialer := net.Dialer{Timeout: doctorDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("proxy %s does not accept connections: %v", proxy.Redacted(), err)
+		check.Remediation = "Check HTTPS_PROXY and HTTP_PROXY, or add internal hosts to NO_PROXY"
+		return check
+	}
+	conn.Close()
+	check.Status = DoctorOK
+	check.Detail = fmt.Sprintf("requests to %s go through proxy %s", target.Host, proxy.Redacted())
+	if serverConfig(ctx).HTTP.CABundle == "" {
+		check.Detail += "; if it intercepts TLS, trust its CA with --ca-bundle"
+	}
+	return check
+}
+
+// doctorRegistry lists the published versions of the Gemara module.
+func doctorRegistry(ctx context.Context) DoctorCheck {
+	check := DoctorCheck{Name: "registry"}
+	config := serverConfig(ctx).Registry
+	registry := config.Registry
+	if registry == "" {
+		registry = os.Getenv("CUE_REGISTRY")
+	}
+	if registry == "" {
+		registry = "the public CUE registry"
+	}
+	if err := config.Validate(); err != nil {
+		check.Status = DoctorFail
+		check.Detail = err.Error()
+		check.Remediation = "Fix --cue-registry (same syntax as CUE_REGISTRY) or --registry-docker-config"
+		return check
+	}
+	versions, err := schemaVersionLister(ctx)
+	if err != nil {
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("cannot list %s in %s: %v", GemaraModule, registry, err)
+		check.Remediation = remediationFor(err, "Mirror the module and pass --cue-registry, or serve an offline bundle with --bundle")
+		return check
+	}
+	if len(versions) == 0 {
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("%s publishes no versions of %s", registry, GemaraModule)
+		check.Remediation = "Mirror " + GemaraModule + " into the registry named by --cue-registry"
+		return check
+	}
+	check.Status = DoctorOK
+	check.Detail = fmt.Sprintf("%s has %d version(s) of %s", registry, len(versions), GemaraModule)
+	return check
+}
+
+// doctorLexicon checks that every lexicon source can be read, bypassing the
+// caches that would otherwise hide an unreachable source.
+func doctorLexicon(ctx context.Context) []DoctorCheck {
+	sources := DefaultLexicon.Sources()
+	if len(sources) == 0 {
+		return []DoctorCheck{{
+			Name:        "lexicon",
+			Status:      DoctorFail,
+			Detail:      "no lexicon sources are configured",
+			Remediation: "Set --lexicon-url or --lexicon-overlay",
+		}}
+	}
+
+	checks := make([]DoctorCheck, 0, len(sources))
+	for _, source := range sources {
+		check := DoctorCheck{Name: "lexicon", Status: DoctorOK, Detail: source + " is readable"}
+		u, err := url.Parse(source)
+		switch {
+		case err != nil:
+			err = fmt.Errorf("invalid URL: %w", err)
+		case u.Scheme == "file":
+			_, err = os.Stat(u.Path)
+		default:
+			_, _, err = fetchHTTP(ctx, source)
+		}
+		if err != nil {
+			check.Status = DoctorFail
+			check.Detail = fmt.Sprintf("cannot read %s: %v", source, err)
+			check.Remediation = remediationFor(err, "Point --lexicon-url at a reachable copy (https:// or file://); without one the embedded snapshot is served, marked stale")
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// doctorModuleCache checks that the CUE module cache, where resolved schema
+// modules are kept, is writable.
+func doctorModuleCache() DoctorCheck {
+	check := DoctorCheck{Name: "module cache"}
+	dir := os.Getenv("CUE_CACHE_DIR")
+	if dir == "" {
+		userCache, err := os.UserCacheDir()
+		if err != nil {
+			check.Status = DoctorFail
+			check.Detail = fmt.Sprintf("cannot locate the user cache directory: %v", err)
+			check.Remediation = "Set CUE_CACHE_DIR to a writable directory"
+			return check
+		}
+		dir = filepath.Join(userCache, "cue")
+	}
+	if err := probeWritable(dir); err != nil {
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("%s is not writable: %v", dir, err)
+		check.Remediation = "Make the directory writable, or set CUE_CACHE_DIR to a writable directory"
+		return check
+	}
+	check.Status = DoctorOK
+	check.Detail = dir + " is writable"
+	return check
+}
+
+// doctorCacheStorage writes, reads, and deletes a probe in the configured storage.
+func doctorCacheStorage(ctx context.Context) DoctorCheck {
+	check := DoctorCheck{Name: "cache storage"}
+	storage := serverConfig(ctx).Storage
+	if storage == nil {
+		check.Status = DoctorSkip
+		check.Detail = "no --cache-storage configured; fetched documents are cached in memory only"
+		return check
+	}
+
+	const key = "doctor/probe"
+	probe := []byte(time.Now().UTC().Format(time.RFC3339Nano))
+	err := storage.Put(ctx, key, probe)
+	if err == nil {
+		var got []byte
+		if got, err = storage.Get(ctx, key); err == nil && string(got) != string(probe) {
+			err = errors.New("read back a different value than was written")
+		}
+	}
+	if err == nil {
+		err = storage.Delete(ctx, key)
+	}
+	if err != nil {
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("cache storage is not usable: %v", err)
+		check.Remediation = "Check the --cache-storage location exists and is writable (for s3://, the bucket, region, and AWS credentials)"
+		return check
+	}
+	check.Status = DoctorOK
+	check.Detail = "cache storage is readable and writable"
+	return check
+}
+
+// doctorSchema compiles the Gemara schema as validation does.
+func doctorSchema(ctx context.Context) DoctorCheck {
+	check := DoctorCheck{Name: "schema"}
+	schema, err := loadSchema(ctx)
+	switch {
+	case err != nil:
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("the Gemara schema does not compile: %v", err)
+		check.Remediation = remediationFor(err, "Run 'gemara-mcp warmup' once the registry is reachable, or serve an offline bundle with --bundle")
+	case schema.snapshot:
+		check.Status = DoctorWarn
+		check.Detail = fmt.Sprintf("the registry is unavailable; validation uses the embedded schema %s", schema.version)
+		check.Remediation = "Resolve the registry failure above to validate against the latest published schema"
+	default:
+		check.Status = DoctorOK
+		check.Detail = fmt.Sprintf("%s@%s compiles (%d definitions)", GemaraModule, schema.version, len(schemaDefinitions(schema)))
+	}
+	return check
+}
+
+// probeWritable creates dir if needed and writes and removes a file in it.
+func probeWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".gemara-doctor-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// remediationFor suggests a fix for a network error, or fallback when the
+// cause is not recognized.
+func remediationFor(err error, fallback string) string {
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "x509") || strings.Contains(msg, "certificate"):
+		return "TLS verification failed: if a proxy intercepts TLS, pass its CA with --ca-bundle"
+	case strings.Contains(msg, "proxyconnect"):
+		return "The proxy refused the connection: check HTTPS_PROXY and NO_PROXY"
+	case strings.Contains(msg, "no such host") || strings.Contains(msg, "server misbehaving"):
+		return "The host name does not resolve: check DNS and network access, or set HTTPS_PROXY. " + fallback
+	case strings.Contains(msg, "timeout") || strings.Contains(msg, "deadline exceeded"):
+		return "The request timed out: check network access and proxies, or raise --http-timeout. " + fallback
+	case strings.Contains(msg, "code: 401") || strings.Contains(msg, "code: 403") ||
+		strings.Contains(msg, "unauthorized") || strings.Contains(msg, "forbidden") || strings.Contains(msg, "denied"):
+		return "Access was denied: for the registry, log in with 'cue login' or pass --registry-docker-config; for other URLs, check they are public"
+	case strings.Contains(msg, "no such file"):
+		return "The file does not exist: check the path"
+	default:
+		return fallback
+	}
+}
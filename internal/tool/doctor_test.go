@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoctor(t *testing.T) {
+	require.NoError(t, useHTTPConfig(t, HTTPConfig{}))
+	useTestSchema(t)
+	t.Setenv("CUE_CACHE_DIR", t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/lexicon.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("- term: Control\n  definition: A safeguard\n"))
+	}))
+	defer server.Close()
+
+	overlay := filepath.Join(t.TempDir(), "org.yaml")
+	require.NoError(t, os.WriteFile(overlay, []byte("- term: Org\n  definition: Ours\n"), 0o600))
+	originalLexicon := DefaultLexicon
+	t.Cleanup(func() { DefaultLexicon = originalLexicon })
+	DefaultLexicon = NewLexiconService([]string{server.URL + "/lexicon.yaml", fileURL(overlay)}, LexiconConflictOverride)
+
+	originalLister := schemaVersionLister
+	t.Cleanup(func() { schemaVersionLister = originalLister })
+	schemaVersionLister = func(context.Context) ([]string, error) { return []string{"v0.1.0", "v0.2.0"}, nil }
+
+	storage, err := OpenStorage(t.TempDir())
+	require.NoError(t, err)
+
+	report := Doctor(withTestConfig(func(c *Config) { c.Storage = storage }))
+	statuses := map[string][]string{}
+	for _, c := range report.Checks {
+		statuses[c.Name] = append(statuses[c.Name], c.Status)
+	}
+	assert.True(t, report.Passed, "checks: %+v", report.Checks)
+	assert.Equal(t, []string{DoctorOK}, statuses["registry"])
+	assert.Equal(t, []string{DoctorOK, DoctorOK}, statuses["lexicon"], "every source should be checked")
+	assert.Equal(t, []string{DoctorOK}, statuses["module cache"])
+	assert.Equal(t, []string{DoctorOK}, statuses["cache storage"])
+	assert.Equal(t, []string{DoctorOK}, statuses["schema"])
+	keys, err := storage.List(context.Background(), "doctor/")
+	require.NoError(t, err)
+	assert.Empty(t, keys, "the cache probe should be removed")
+
+	// Unreachable sources fail with a remediation, even with a warm cache
+	DefaultLexicon = NewLexiconService([]string{server.URL + "/missing.yaml"}, LexiconConflictOverride)
+	schemaVersionLister = func(context.Context) ([]string, error) {
+		return nil, errors.New("dial tcp: lookup registry.cue.works: no such host")
+	}
+	report = Doctor(context.Background())
+	assert.False(t, report.Passed)
+	for _, c := range report.Checks {
+		switch c.Name {
+		case "registry":
+			assert.Equal(t, DoctorFail, c.Status)
+			assert.Contains(t, c.Remediation, "does not resolve")
+		case "lexicon":
+			assert.Equal(t, DoctorFail, c.Status)
+			assert.Contains(t, c.Detail, "404")
+			assert.NotEmpty(t, c.Remediation)
+		case "cache storage":
+			assert.Equal(t, DoctorSkip, c.Status)
+		}
+	}
+}
+
+func TestRemediationFor(t *testing.T) {
+	tests := []struct {
+		err  string
+		want string
+	}{
+		{err: "tls: failed to verify certificate: x509: certificate signed by unknown authority", want: "--ca-bundle"},
+		{err: "proxyconnect tcp: dial tcp 10.0.0.1:3128: connection refused", want: "HTTPS_PROXY"},
+		{err: "context deadline exceeded", want: "--http-timeout"},
+		{err: "unexpected status code: 401", want: "cue login"},
+		{err: "something else", want: "fallback"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			assert.Contains(t, remediationFor(errors.New(tt.err), "fallback"), tt.want)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// RequestDrainer tracks in-flight requests so the server can let them finish
+// before shutting down.
+type RequestDrainer struct {
+	mu       sync.Mutex
+	inFlight sync.WaitGroup
+	draining bool
+}
+
+// Middleware counts requests while they run and rejects new tool calls once
+// draining has started.
+func (d *RequestDrainer) Middleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		d.mu.Lock()
+		if d.draining && method == "tools/call" {
+			d.mu.Unlock()
+			return nil, fmt.Errorf("server is shutting down")
+		}
+		d.inFlight.Add(1)
+		d.mu.Unlock()
+		defer d.inFlight.Done()
+
+		return next(ctx, method, req)
+	}
+}
+
+// Drain stops accepting tool calls and waits for in-flight requests to finish,
+// returning ctx.Err() if ctx is done first.
+func (d *RequestDrainer) Drain(ctx context.Context) error {
+	d.mu.Lock()
+	d.draining = true
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
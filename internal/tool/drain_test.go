@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestDrainer(t *testing.T) {
+	drainer := &RequestDrainer{}
+	release := make(chan struct{})
+	started := make(chan struct{})
+	handler := drainer.Middleware(func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		close(started)
+		<-release
+		return &mcp.CallToolResult{}, nil
+	})
+
+	finished := make(chan error, 1)
+	go func() {
+		_, err := handler(context.Background(), "tools/call", nil)
+		finished <- err
+	}()
+	<-started
+
+	// A short deadline expires while the call is still running
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, drainer.Drain(ctx), context.DeadlineExceeded, "drain should time out while a call is in flight")
+
+	_, err := drainer.Middleware(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		t.Fatal("new tool calls should not run while draining")
+		return nil, nil
+	})(context.Background(), "tools/call", nil)
+	assert.ErrorContains(t, err, "shutting down", "new tool calls should be rejected")
+
+	close(release)
+	require.NoError(t, <-finished, "in-flight call should complete")
+	assert.NoError(t, drainer.Drain(context.Background()), "drain should finish once calls complete")
+}
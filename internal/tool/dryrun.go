@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// maxDiffLines bounds the lines compared by lineDiff.
+const maxDiffLines = 2000
+
+// dryRunProperty is the input property of write tools that requests a dry run.
+var dryRunProperty = map[string]interface{}{
+	"type":        "boolean",
+	"description": "Report the changes the call would make without making them (always on when the server runs with --dry-run)",
+}
+
+// PlannedChange is a change a write tool makes, or would make in a dry run.
+type PlannedChange struct {
+	// Action is what happens to the target, such as create, update, push, or sign.
+	Action string `json:"action"`
+	// Target is the path, reference, or issue the change applies to.
+	Target string `json:"target"`
+	Detail string `json:"detail,omitempty"`
+	// Diff is a line diff of the target's content when it is replaced or created.
+	Diff string `json:"diff,omitempty"`
+}
+
+// dryRun reports whether a call should only report its changes.
+func dryRun(ctx context.Context, requested bool) bool {
+	return serverConfig(ctx).DryRun || requested
+}
+
+// checkWritable guards every write to files and external systems, so that
+// nothing is changed while the server runs with --dry-run even if a tool does
+// not honor it.
+func checkWritable(ctx context.Context, target string) error {
+	if serverConfig(ctx).DryRun {
+		return fmt.Errorf("refusing to write %s: the server runs with --dry-run", target)
+	}
+	return nil
+}
+
+// lineDiff returns the lines removed from before ("-") and added in after
+// ("+") around the unchanged lines (" "), or an empty string when they are
+// equal. Content longer than maxDiffLines is summarized instead.
+func lineDiff(before, after string) string {
+	if before == after {
+		return ""
+	}
+	a, b := splitLines(before), splitLines(after)
+	if len(a) > maxDiffLines || len(b) > maxDiffLines {
+		return fmt.Sprintf("%d line(s) replaced by %d line(s)\n", len(a), len(b))
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var diff strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			diff.WriteString(" " + a[i] + "\n")
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			diff.WriteString("-" + a[i] + "\n")
+			i++
+		default:
+			diff.WriteString("+" + b[j] + "\n")
+			j++
+		}
+	}
+	return diff.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
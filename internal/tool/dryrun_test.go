@@ -0,0 +1,57 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{name: "equal", before: "a\nb\n", after: "a\nb\n", want: ""},
+		{name: "created", before: "", after: "a\nb\n", want: "+a\n+b\n"},
+		{name: "changed line", before: "a\nb\nc\n", after: "a\nB\nc\n", want: " a\n-b\n+B\n c\n"},
+		{name: "appended", before: "a\n", after: "a\nb", want: " a\n+b\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, lineDiff(tt.before, tt.after))
+		})
+	}
+}
+
+func TestServerDryRun(t *testing.T) {
+	useTestSchema(t)
+	repositories := memoryRegistry(t)
+	config := NewConfig()
+	config.Cosign = fakeCosign(t, CosignConfig{})
+	config.DryRun = true
+	ctx := WithConfig(context.Background(), config)
+	catalog, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err)
+
+	_, pushed, err := PushArtifactOCI(ctx, nil, InputPushArtifactOCI{ArtifactContent: string(catalog), Reference: "ghcr.io/org/ccc:v1"})
+	require.NoError(t, err)
+	assert.True(t, pushed.DryRun, "--dry-run should apply without the dry_run input")
+	assert.Empty(t, repositories)
+
+	_, err = config.ORAS.push(ctx, "ghcr.io/org/ccc:v1", ociArtifactType("ControlCatalog"), "FINOS-CCC.yaml", catalog, nil)
+	assert.ErrorContains(t, err, "--dry-run", "writes should be refused even when a tool ignores the dry run")
+	_, err = config.Cosign.run(ctx, "sign-blob", "--yes", "artifact.yaml")
+	assert.ErrorContains(t, err, "--dry-run")
+	target := filepath.Join(t.TempDir(), "catalog.yaml")
+	assert.ErrorContains(t, writeArtifactFile(ctx, target, catalog), "--dry-run")
+	assert.NoFileExists(t, target)
+}
@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ambiguousConfidenceMargin is how close the confidence of the two best
+// detected definitions must be for detection to count as ambiguous.
+const ambiguousConfidenceMargin = 0.1
+
+// elicitationSupported reports whether the client that sent req can answer
+// elicitation requests.
+func elicitationSupported(req *mcp.CallToolRequest) bool {
+	if req == nil || req.Session == nil {
+		return false
+	}
+	params := req.Session.InitializeParams()
+	return params != nil && params.Capabilities != nil && params.Capabilities.Elicitation != nil
+}
+
+// ambiguousMatches reports whether detection could not tell the best
+// definitions apart.
+func ambiguousMatches(matches []DefinitionMatch) bool {
+	return len(matches) > 1 && matches[0].Confidence-matches[1].Confidence < ambiguousConfidenceMargin
+}
+
+// chooseDefinition resolves an omitted or auto definition. When the client
+// supports elicitation, the user is asked to pick one if the definition was
+// omitted or detection is ambiguous; otherwise an omitted definition is an
+// error and auto falls back to the best match. Multi-document streams are
+// offered auto, which detects each document separately.
+func chooseDefinition(ctx context.Context, req *mcp.CallToolRequest, schema *gemaraSchema, definition, format, content string) (string, error) {
+	supported := elicitationSupported(req)
+	if definition == "" && !supported {
+		return "", fmt.Errorf("definition is required")
+	}
+	if definition == definitionAuto && !supported {
+		return definitionAuto, nil
+	}
+
+	if format == formatYAML {
+		if documents := splitYAMLDocuments(content); len(documents) > 1 {
+			if definition == definitionAuto {
+				return definitionAuto, nil
+			}
+			candidates := append([]string{definitionAuto}, schemaDefinitions(schema)...)
+			message := fmt.Sprintf("Which definition should the %d documents in this stream be validated against? "+
+				"Choose %s to detect the definition of each document.", len(documents), definitionAuto)
+			return elicitDefinition(ctx, req, message, candidates)
+		}
+	}
+
+	matches, err := withValidationTimeout(ctx, func() ([]DefinitionMatch, error) {
+		return detectFormatDefinition(ctx, schema, format, content)
+	})
+	if err != nil {
+		return "", err
+	}
+	if definition == definitionAuto && !ambiguousMatches(matches) {
+		return matches[0].Definition, nil
+	}
+
+	if len(matches) > maxDetectionCandidates {
+		matches = matches[:maxDetectionCandidates]
+	}
+	candidates := make([]string, 0, len(matches))
+	scores := make([]string, 0, len(matches))
+	for _, m := range matches {
+		candidates = append(candidates, m.Definition)
+		scores = append(scores, fmt.Sprintf("%s (confidence %.2f)", m.Definition, m.Confidence))
+	}
+	reason := "No definition was given"
+	if definition == definitionAuto {
+		reason = "The artifact matches several definitions equally well"
+	}
+	message := fmt.Sprintf("%s. Which definition should it be validated against? Best matches: %s.", reason, strings.Join(scores, ", "))
+	return elicitDefinition(ctx, req, message, candidates)
+}
+
+// elicitDefinition asks the user to choose one of candidates.
+func elicitDefinition(ctx context.Context, req *mcp.CallToolRequest, message string, candidates []string) (string, error) {
+	result, err := req.Session.Elicit(ctx, &mcp.ElicitParams{
+		Message: message,
+		RequestedSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"definition": map[string]interface{}{
+					"type":        "string",
+					"title":       "Definition",
+					"description": "Gemara definition to validate against",
+					"enum":        candidates,
+				},
+			},
+			// Not required, as clients validate declined answers against
+			// the schema too
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to ask for a definition: %w", err)
+	}
+	if result.Action != "accept" {
+		return "", fmt.Errorf("no definition was chosen (%s)", result.Action)
+	}
+	chosen, _ := result.Content["definition"].(string)
+	if !slices.Contains(candidates, chosen) {
+		return "", fmt.Errorf("chosen definition %q is not one of the candidates", chosen)
+	}
+	return chosen, nil
+}
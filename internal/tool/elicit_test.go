@@ -0,0 +1,172 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// callValidate calls validate_gemara_artifact through a client session whose
+// elicitation requests are answered by elicit, or that does not support
+// elicitation when elicit is nil.
+func callValidate(t *testing.T, input InputValidateGemaraArtifact, elicit func(*mcp.ElicitRequest) *mcp.ElicitResult) (OutputValidateGemaraArtifact, string) {
+	t.Helper()
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server"}, nil)
+	mcp.AddTool(server, MetadataValidateGemaraArtifact, ValidateGemaraArtifact)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err, "server should connect")
+
+	options := &mcp.ClientOptions{}
+	if elicit != nil {
+		options.ElicitationHandler = func(_ context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			return elicit(req), nil
+		}
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, options)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err, "client should connect")
+	t.Cleanup(func() { _ = session.Close() })
+
+	arguments, err := json.Marshal(input)
+	require.NoError(t, err, "should marshal input")
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: MetadataValidateGemaraArtifact.Name, Arguments: json.RawMessage(arguments)})
+	require.NoError(t, err, "should call the tool")
+	if result.IsError {
+		return OutputValidateGemaraArtifact{}, result.Content[0].(*mcp.TextContent).Text
+	}
+
+	var output OutputValidateGemaraArtifact
+	structured, err := json.Marshal(result.StructuredContent)
+	require.NoError(t, err, "should marshal structured content")
+	require.NoError(t, json.Unmarshal(structured, &output), "should decode output")
+	return output, ""
+}
+
+func TestValidateGemaraArtifactElicitation(t *testing.T) {
+	useTestSchema(t)
+	catalog, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err, "should read test catalog")
+
+	// A title alone fits several definitions about as well
+	ambiguous := "title: Test\n"
+
+	answer := func(definition string) func(*mcp.ElicitRequest) *mcp.ElicitResult {
+		return func(*mcp.ElicitRequest) *mcp.ElicitResult {
+			return &mcp.ElicitResult{Action: "accept", Content: map[string]any{"definition": definition}}
+		}
+	}
+
+	tests := []struct {
+		name           string
+		input          InputValidateGemaraArtifact
+		elicit         func(*mcp.ElicitRequest) *mcp.ElicitResult
+		wantElicited   bool
+		wantMessage    string
+		wantCandidate  string
+		wantDefinition string
+		wantValid      bool
+		errContains    string
+	}{
+		{
+			name:           "omitted definition",
+			input:          InputValidateGemaraArtifact{ArtifactContent: string(catalog)},
+			elicit:         answer("#ControlCatalog"),
+			wantElicited:   true,
+			wantMessage:    "No definition was given",
+			wantCandidate:  "#ControlCatalog",
+			wantDefinition: "#ControlCatalog",
+			wantValid:      true,
+		},
+		{
+			name:           "ambiguous detection",
+			input:          InputValidateGemaraArtifact{ArtifactContent: ambiguous, Definition: definitionAuto},
+			elicit:         answer("#Policy"),
+			wantElicited:   true,
+			wantMessage:    "matches several definitions",
+			wantCandidate:  "#Category",
+			wantDefinition: "#Policy",
+		},
+		{
+			name:           "clear detection",
+			input:          InputValidateGemaraArtifact{ArtifactContent: string(catalog), Definition: definitionAuto},
+			elicit:         answer("#Policy"),
+			wantDefinition: "#ControlCatalog",
+			wantValid:      true,
+		},
+		{
+			name:          "multi-document stream",
+			input:         InputValidateGemaraArtifact{ArtifactContent: string(catalog) + "---\n" + string(catalog)},
+			elicit:        answer(definitionAuto),
+			wantElicited:  true,
+			wantMessage:   "2 documents",
+			wantCandidate: definitionAuto,
+			wantValid:     true,
+		},
+		{
+			name:  "declined",
+			input: InputValidateGemaraArtifact{ArtifactContent: string(catalog)},
+			elicit: func(*mcp.ElicitRequest) *mcp.ElicitResult {
+				return &mcp.ElicitResult{Action: "decline"}
+			},
+			wantElicited: true,
+			errContains:  "no definition was chosen (decline)",
+		},
+		{
+			name:         "answer outside the candidates",
+			input:        InputValidateGemaraArtifact{ArtifactContent: string(catalog)},
+			elicit:       answer("#Nope"),
+			wantElicited: true,
+			errContains:  "does not match requested schema",
+		},
+		{
+			name:        "client without elicitation",
+			input:       InputValidateGemaraArtifact{ArtifactContent: string(catalog)},
+			errContains: "definition is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []*mcp.ElicitRequest
+			var elicit func(*mcp.ElicitRequest) *mcp.ElicitResult
+			if tt.elicit != nil {
+				elicit = func(req *mcp.ElicitRequest) *mcp.ElicitResult {
+					requests = append(requests, req)
+					return tt.elicit(req)
+				}
+			}
+
+			output, toolErr := callValidate(t, tt.input, elicit)
+			if tt.wantElicited {
+				require.Len(t, requests, 1, "should ask once")
+				assert.Contains(t, requests[0].Params.Message, tt.wantMessage, "should explain the question")
+				if tt.wantCandidate != "" {
+					schema, err := json.Marshal(requests[0].Params.RequestedSchema)
+					require.NoError(t, err, "should marshal requested schema")
+					assert.Contains(t, string(schema), `"`+tt.wantCandidate+`"`, "should offer the candidate")
+				}
+			} else {
+				assert.Empty(t, requests, "should not ask")
+			}
+
+			if tt.errContains != "" {
+				assert.Contains(t, toolErr, tt.errContains, "error should contain expected message")
+				return
+			}
+			require.Empty(t, toolErr, "should not return error")
+			assert.Equal(t, tt.wantValid, output.Valid, "valid status should match: %v", output.Errors)
+			assert.Equal(t, tt.wantDefinition, output.Definition, "should report the definition used")
+		})
+	}
+}
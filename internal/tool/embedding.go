@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxEmbeddingResponseSize bounds an embeddings API response; a batch of
+// large vectors is well beyond MaxArtifactSize.
+const maxEmbeddingResponseSize = 64 << 20
+
+// embedder turns texts into vectors whose cosine similarity reflects how
+// related the texts are.
+type embedder interface {
+	embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// newEmbedder returns the embedder of the provider configured for ctx.
+func newEmbedder(ctx context.Context) (embedder, error) {
+	switch serverConfig(ctx).SemanticSearch.Provider {
+	case EmbeddingHashing:
+		return hashingEmbedder{dims: hashingEmbeddingDims}, nil
+	case EmbeddingOpenAI:
+		return &apiEmbedder{config: serverConfig(ctx).SemanticSearch, http: newHTTPClient()}, nil
+	}
+	return nil, fmt.Errorf("semantic search is not enabled; set --embedding-provider")
+}
+
+// hashingEmbedder hashes the words and word pairs of a text into a fixed
+// number of dimensions. It captures shared vocabulary rather than meaning,
+// but needs no model and gives the same vectors everywhere.
+type hashingEmbedder struct {
+	dims int
+}
+
+func (h hashingEmbedder) embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float32, h.dims)
+		words := crosswalkTerms(text)
+		for j, w := range words {
+			h.add(v, w, 1)
+			if j > 0 {
+				h.add(v, words[j-1]+" "+w, 0.5)
+			}
+		}
+		vectors[i] = v
+	}
+	return vectors, nil
+}
+
+// add hashes a feature into v, with a hashed sign so that collisions cancel
+// out rather than accumulate.
+func (h hashingEmbedder) add(v []float32, feature string, weight float32) {
+	f := fnv.New64a()
+	_, _ = f.Write([]byte(feature))
+	sum := f.Sum64()
+	if sum>>63 == 1 {
+		weight = -weight
+	}
+	v[sum%uint64(h.dims)] += weight
+}
+
+// apiEmbedder calls an OpenAI-compatible embeddings endpoint.
+type apiEmbedder struct {
+	config SemanticSearchConfig
+	http   *http.Client
+}
+
+func (a *apiEmbedder) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	payload, err := json.Marshal(map[string]interface{}{"model": a.config.Model, "input": texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode embeddings request: %w", err)
+	}
+	target := strings.TrimSuffix(a.config.APIURL, "/") + "/embeddings"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if a.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+a.config.APIKey)
+	}
+
+	resp, err := a.http.Do(req)
+	if err != nil {
+		emitUpstreamFailure(ctx, "embeddings API", err)
+		return nil, fmt.Errorf("failed to reach embeddings API: %w", err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxEmbeddingResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embeddings response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var problem struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(raw, &problem)
+		if problem.Error.Message != "" {
+			return nil, fmt.Errorf("embeddings API returned %s: %s", resp.Status, problem.Error.Message)
+		}
+		return nil, fmt.Errorf("embeddings API returned %s", resp.Status)
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse embeddings response: %w", err)
+	}
+	vectors := make([][]float32, len(texts))
+	for _, d := range result.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("embeddings API returned an embedding for unknown input %d", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	for i, v := range vectors {
+		if len(v) == 0 {
+			return nil, fmt.Errorf("embeddings API returned no embedding for input %d", i)
+		}
+	}
+	return vectors, nil
+}
@@ -0,0 +1,233 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MetadataGenerateEvaluationPlan describes the GenerateEvaluationPlan tool.
+var MetadataGenerateEvaluationPlan = &mcp.Tool{
+	Name: "generate_evaluation_plan",
+	Description: "Read a ControlCatalog and draft a Layer 4 EvaluationPlan listing every assessment requirement with " +
+		"placeholder procedures and frequencies, validated against the schema before it is returned.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"catalog_content"},
+		"properties": map[string]interface{}{
+			"catalog_content": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML content of the ControlCatalog to plan evaluations for",
+			},
+			"plan_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Metadata id of the plan (default: the catalog id followed by -EVAL)",
+			},
+			"controls": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Only plan these control IDs (default: every control)",
+			},
+			"applicability": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Only plan assessment requirements that apply to one of these category IDs",
+			},
+			"frequency": map[string]interface{}{
+				"type":        "string",
+				"description": "Frequency for every assessment, such as 'daily' (default: a placeholder)",
+			},
+		},
+	},
+}
+
+// InputGenerateEvaluationPlan is the input for the GenerateEvaluationPlan tool.
+type InputGenerateEvaluationPlan struct {
+	CatalogContent string   `json:"catalog_content"`
+	PlanID         string   `json:"plan_id,omitempty"`
+	Controls       []string `json:"controls,omitempty"`
+	Applicability  []string `json:"applicability,omitempty"`
+	Frequency      string   `json:"frequency,omitempty"`
+}
+
+// OutputGenerateEvaluationPlan is the output for the GenerateEvaluationPlan tool.
+type OutputGenerateEvaluationPlan struct {
+	Content      string   `json:"content"`
+	Controls     int      `json:"controls"`
+	Requirements int      `json:"requirements"`
+	Valid        bool     `json:"valid"`
+	Errors       []string `json:"errors,omitempty"`
+	// Placeholders lists the paths left for the author to complete.
+	Placeholders []string `json:"placeholders"`
+	Message      string   `json:"message"`
+}
+
+// GenerateEvaluationPlan drafts an evaluation plan for the assessment requirements of a catalog.
+func GenerateEvaluationPlan(ctx context.Context, _ *mcp.CallToolRequest, input InputGenerateEvaluationPlan) (*mcp.CallToolResult, OutputGenerateEvaluationPlan, error) {
+	if input.CatalogContent == "" {
+		return nil, OutputGenerateEvaluationPlan{}, fmt.Errorf("catalog_content is required")
+	}
+	catalog, err := parseArtifact(ctx, input.CatalogContent)
+	if err != nil {
+		return nil, OutputGenerateEvaluationPlan{}, err
+	}
+	controls, ok := catalog["controls"].([]interface{})
+	if !ok || len(controls) == 0 {
+		return nil, OutputGenerateEvaluationPlan{}, fmt.Errorf("catalog has no controls")
+	}
+	catalogID := metadataID(catalog)
+	if catalogID == "" {
+		return nil, OutputGenerateEvaluationPlan{}, fmt.Errorf("catalog has no metadata id to reference")
+	}
+	planID := input.PlanID
+	if planID == "" {
+		planID = catalogID + "-EVAL"
+	}
+
+	wantControls := stringSet(input.Controls)
+	wantCategories := stringSet(input.Applicability)
+	output := OutputGenerateEvaluationPlan{Placeholders: []string{"$.metadata.description", "$.metadata.author"}}
+
+	var plans []interface{}
+	found := map[string]bool{}
+	for _, item := range controls {
+		control, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		controlID := entityID(control)
+		if controlID == "" || len(wantControls) > 0 && !wantControls[controlID] {
+			continue
+		}
+		found[controlID] = true
+
+		var assessments []interface{}
+		requirements, _ := control["assessment-requirements"].([]interface{})
+		for _, r := range requirements {
+			requirement, ok := r.(map[string]interface{})
+			if !ok || entityID(requirement) == "" || !appliesTo(requirement, wantCategories) {
+				continue
+			}
+			requirementID := entityID(requirement)
+			path := fmt.Sprintf("$.plans[%d].assessments[%d]", len(plans), len(assessments))
+			frequency := input.Frequency
+			if frequency == "" {
+				frequency = skeletonPlaceholder
+				output.Placeholders = append(output.Placeholders, path+".frequency")
+			}
+			output.Placeholders = append(output.Placeholders, path+".procedures[0].name", path+".procedures[0].description")
+
+			text, _ := requirement["text"].(string)
+			assessments = append(assessments, yaml.MapSlice{
+				{Key: "requirement", Value: entryMapping(catalogID, requirementID)},
+				{Key: "frequency", Value: frequency},
+				{Key: "procedures", Value: []interface{}{
+					yaml.MapSlice{
+						{Key: "id", Value: requirementID + ".P01"},
+						{Key: "name", Value: skeletonPlaceholder},
+						{Key: "description", Value: procedureDescription(text)},
+					},
+				}},
+			})
+		}
+		if len(assessments) == 0 {
+			continue
+		}
+		output.Requirements += len(assessments)
+		plans = append(plans, yaml.MapSlice{
+			{Key: "control", Value: entryMapping(catalogID, controlID)},
+			{Key: "assessments", Value: assessments},
+		})
+	}
+	for _, id := range input.Controls {
+		if !found[id] {
+			return nil, OutputGenerateEvaluationPlan{}, fmt.Errorf("control %s not found in the catalog", id)
+		}
+	}
+	if len(plans) == 0 {
+		return nil, OutputGenerateEvaluationPlan{}, fmt.Errorf("no assessment requirements match the selected controls and applicability")
+	}
+	output.Controls = len(plans)
+
+	title, _ := catalog["title"].(string)
+	if title == "" {
+		title = catalogID
+	}
+	plan := yaml.MapSlice{
+		{Key: "metadata", Value: yaml.MapSlice{
+			{Key: "id", Value: planID},
+			{Key: "description", Value: fmt.Sprintf("%s: describe the scope and schedule of this plan. Drafted from %s.", skeletonPlaceholder, catalogID)},
+			{Key: "version", Value: "0.1.0"},
+			{Key: "author", Value: draftAuthor},
+		}},
+		{Key: "title", Value: "Evaluation Plan for " + title},
+		{Key: "plans", Value: plans},
+	}
+
+	out, err := yaml.MarshalWithOptions(plan, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return nil, OutputGenerateEvaluationPlan{}, fmt.Errorf("failed to encode plan: %w", err)
+	}
+	output.Content = string(out)
+
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputGenerateEvaluationPlan{}, err
+	}
+	validation, err := validateAgainstSchema(schema, "#EvaluationPlan", output.Content)
+	if err != nil {
+		return nil, OutputGenerateEvaluationPlan{}, err
+	}
+	output.Valid = validation.Valid
+	output.Errors = validation.Errors
+	output.Message = fmt.Sprintf("Planned %d assessment requirement(s) across %d control(s); %s; complete the %d %s placeholder(s) before publishing",
+		output.Requirements, output.Controls, strings.ToLower(validation.Message), len(output.Placeholders), skeletonPlaceholder)
+	return nil, output, nil
+}
+
+// entryMapping references an entry in another artifact.
+func entryMapping(referenceID, entryID string) yaml.MapSlice {
+	return yaml.MapSlice{
+		{Key: "reference-id", Value: referenceID},
+		{Key: "entry-id", Value: entryID},
+	}
+}
+
+// procedureDescription is the placeholder description of a procedure that
+// verifies the given requirement text.
+func procedureDescription(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return fmt.Sprintf("%s: describe the steps and evidence that verify this requirement.", skeletonPlaceholder)
+	}
+	return fmt.Sprintf("%s: describe the steps and evidence that verify: %s", skeletonPlaceholder, text)
+}
+
+// appliesTo reports whether a requirement applies to any of the categories.
+// Every requirement applies when no categories are given.
+func appliesTo(requirement map[string]interface{}, categories map[string]bool) bool {
+	if len(categories) == 0 {
+		return true
+	}
+	applicability, _ := requirement["applicability"].([]interface{})
+	for _, c := range applicability {
+		if id, ok := c.(string); ok && categories[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// stringSet returns the set of values.
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
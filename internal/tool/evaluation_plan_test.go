@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateEvaluationPlan(t *testing.T) {
+	useTestSchema(t)
+	catalog, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err, "should read test catalog")
+
+	tests := []struct {
+		name             string
+		input            InputGenerateEvaluationPlan
+		wantErr          bool
+		errContains      string
+		wantControls     int
+		wantRequirements int
+		wantPlaceholders int
+		wantContains     []string
+	}{
+		{
+			name:        "missing catalog",
+			wantErr:     true,
+			errContains: "catalog_content is required",
+		},
+		{
+			name:        "catalog without metadata id",
+			input:       InputGenerateEvaluationPlan{CatalogContent: "title: X\ncontrols:\n  - id: C1\n"},
+			wantErr:     true,
+			errContains: "no metadata id",
+		},
+		{
+			name:        "unknown control",
+			input:       InputGenerateEvaluationPlan{CatalogContent: string(catalog), Controls: []string{"CCC.C99"}},
+			wantErr:     true,
+			errContains: "control CCC.C99 not found",
+		},
+		{
+			name:        "no matching applicability",
+			input:       InputGenerateEvaluationPlan{CatalogContent: string(catalog), Applicability: []string{"tlp_unknown"}},
+			wantErr:     true,
+			errContains: "no assessment requirements match",
+		},
+		{
+			name:             "whole catalog",
+			input:            InputGenerateEvaluationPlan{CatalogContent: string(catalog)},
+			wantControls:     5,
+			wantRequirements: 10,
+			wantPlaceholders: 2 + 3*10,
+			wantContains: []string{
+				"id: FINOS-CCC-EVAL",
+				"title: Evaluation Plan for FINOS Cloud Control Catalog",
+				"entry-id: CCC.C01.TR01",
+				"id: CCC.C01.TR01.P01",
+				"frequency: TODO",
+			},
+		},
+		{
+			name: "selected control with frequency",
+			input: InputGenerateEvaluationPlan{
+				CatalogContent: string(catalog),
+				PlanID:         "CCC-Q1",
+				Controls:       []string{"CCC.C01"},
+				Frequency:      "daily",
+			},
+			wantControls:     1,
+			wantRequirements: 2,
+			wantPlaceholders: 2 + 2*2,
+			wantContains:     []string{"id: CCC-Q1", "frequency: daily", "entry-id: CCC.C01.TR02"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := GenerateEvaluationPlan(context.Background(), nil, tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, output.Valid, "plan should validate: %v", output.Errors)
+			assert.Equal(t, tt.wantControls, output.Controls)
+			assert.Equal(t, tt.wantRequirements, output.Requirements)
+			assert.Len(t, output.Placeholders, tt.wantPlaceholders)
+			for _, want := range tt.wantContains {
+				assert.Contains(t, output.Content, want)
+			}
+		})
+	}
+}
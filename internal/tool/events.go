@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// eventLogger names the logger on server event notifications.
+const eventLogger = "gemara-mcp"
+
+// Server event names, reported in the "event" field of notifications.
+const (
+	eventSchemaLoaded    = "schema_loaded"
+	eventSchemaRefreshed = "schema_refreshed"
+	eventCacheMiss       = "cache_miss"
+	eventStaleCache      = "stale_cache"
+	eventUpstreamFailure = "upstream_failure"
+)
+
+// eventLevels are the MCP logging levels from least to most severe.
+var eventLevels = []mcp.LoggingLevel{"debug", "info", "notice", "warning", "error", "critical", "alert", "emergency"}
+
+var (
+	eventMu     sync.RWMutex
+	eventServer *mcp.Server
+	schemaSeen  string
+)
+
+// EventLevels returns the names of the supported event levels.
+func EventLevels() []string {
+	names := make([]string, 0, len(eventLevels))
+	for _, l := range eventLevels {
+		names = append(names, string(l))
+	}
+	return names
+}
+
+// ValidateEventLevel checks that level is a supported MCP logging level.
+func ValidateEventLevel(level string) error {
+	if !slices.Contains(eventLevels, mcp.LoggingLevel(level)) {
+		return fmt.Errorf("invalid log level %q (must be one of: %s)", level, strings.Join(EventLevels(), ", "))
+	}
+	return nil
+}
+
+// BroadcastEvents sends server events to every session connected to server.
+// The events of requests made for a tenant go to the sessions of the
+// tenant's server instead.
+func BroadcastEvents(server *mcp.Server) {
+	eventMu.Lock()
+	defer eventMu.Unlock()
+	eventServer = server
+}
+
+// emitEvent notifies connected clients of a server event. Fields are sent
+// alongside the event name and message.
+func emitEvent(ctx context.Context, level mcp.LoggingLevel, event, message string, fields map[string]interface{}) {
+	if slices.Index(eventLevels, level) < slices.Index(eventLevels, serverConfig(ctx).EventLevel) {
+		return
+	}
+
+	eventMu.RLock()
+	server := eventServer
+	eventMu.RUnlock()
+	if t := requestTenant(ctx); t != nil {
+		server = t.server
+	}
+	if server == nil {
+		return
+	}
+
+	data := map[string]interface{}{
+		"event":   event,
+		"message": message,
+	}
+	for k, v := range fields {
+		data[k] = v
+	}
+	params := &mcp.LoggingMessageParams{
+		Logger: eventLogger,
+		Level:  level,
+		Data:   data,
+	}
+	for session := range server.Sessions() {
+		// Notifications are best effort; a failed send must not fail the request
+		_ = session.Log(ctx, params)
+	}
+}
+
+// emitUpstreamFailure reports a failed request to an upstream source.
+func emitUpstreamFailure(ctx context.Context, source string, err error) {
+	emitEvent(ctx, "warning", eventUpstreamFailure, fmt.Sprintf("failed to fetch %s", source), map[string]interface{}{
+		"source": source,
+		"error":  err.Error(),
+	})
+}
+
+// emitCacheMiss reports that a cached resource had to be fetched.
+func emitCacheMiss(ctx context.Context, cache, source string) {
+	emitEvent(ctx, "debug", eventCacheMiss, fmt.Sprintf("%s cache miss; fetching %s", cache, source), map[string]interface{}{
+		"cache":  cache,
+		"source": source,
+	})
+}
+
+// emitStaleCache reports that an expired cache is being served because the
+// upstream source could not be fetched.
+func emitStaleCache(ctx context.Context, cache, source string, err error) {
+	emitEvent(ctx, "warning", eventStaleCache, fmt.Sprintf("serving stale %s; failed to refresh from %s", cache, source), map[string]interface{}{
+		"cache":  cache,
+		"source": source,
+		"error":  err.Error(),
+	})
+}
+
+// emitSchemaLoaded reports the first schema load and any later change of version.
+func emitSchemaLoaded(ctx context.Context, version string) {
+	eventMu.Lock()
+	previous := schemaSeen
+	schemaSeen = version
+	eventMu.Unlock()
+
+	switch previous {
+	case version:
+		return
+	case "":
+		emitEvent(ctx, "info", eventSchemaLoaded, fmt.Sprintf("loaded Gemara schema %s", version), map[string]interface{}{
+			"version": version,
+		})
+	default:
+		emitEvent(ctx, "info", eventSchemaRefreshed, fmt.Sprintf("Gemara schema refreshed from %s to %s", previous, version), map[string]interface{}{
+			"version":  version,
+			"previous": previous,
+		})
+	}
+}
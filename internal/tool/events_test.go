@@ -0,0 +1,109 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connectEventClient connects a client that records logging notifications at level.
+func connectEventClient(t *testing.T, level mcp.LoggingLevel) <-chan *mcp.LoggingMessageParams {
+	t.Helper()
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server"}, nil)
+	BroadcastEvents(server)
+	t.Cleanup(func() { BroadcastEvents(nil) })
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err, "server should connect")
+
+	messages := make(chan *mcp.LoggingMessageParams, 10)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, &mcp.ClientOptions{
+		LoggingMessageHandler: func(_ context.Context, req *mcp.LoggingMessageRequest) {
+			messages <- req.Params
+		},
+	})
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err, "client should connect")
+	t.Cleanup(func() { _ = session.Close() })
+
+	require.NoError(t, session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: level}), "should set level")
+	return messages
+}
+
+func receiveEvent(t *testing.T, messages <-chan *mcp.LoggingMessageParams) map[string]interface{} {
+	t.Helper()
+	select {
+	case msg := <-messages:
+		assert.Equal(t, eventLogger, msg.Logger)
+		data, ok := msg.Data.(map[string]interface{})
+		require.True(t, ok, "event data should be an object")
+		return data
+	case <-time.After(time.Second):
+		require.FailNow(t, "no event received")
+		return nil
+	}
+}
+
+func TestEmitEvent(t *testing.T) {
+	ctx := withTestConfig(func(c *Config) { c.EventLevel = "debug" })
+
+	messages := connectEventClient(t, "debug")
+
+	emitUpstreamFailure(ctx, "https://example.com/lexicon.yaml", errors.New("unexpected status code: 503"))
+	data := receiveEvent(t, messages)
+	assert.Equal(t, eventUpstreamFailure, data["event"])
+	assert.Equal(t, "https://example.com/lexicon.yaml", data["source"])
+	assert.Equal(t, "unexpected status code: 503", data["error"])
+
+	emitCacheMiss(ctx, "lexicon", "https://example.com/lexicon.yaml")
+	data = receiveEvent(t, messages)
+	assert.Equal(t, eventCacheMiss, data["event"])
+	assert.Equal(t, "lexicon", data["cache"])
+}
+
+func TestEmitEventLevel(t *testing.T) {
+	ctx := withTestConfig(func(c *Config) { c.EventLevel = "warning" })
+
+	messages := connectEventClient(t, "debug")
+
+	emitCacheMiss(ctx, "lexicon", "https://example.com/lexicon.yaml")
+	emitUpstreamFailure(ctx, "https://example.com/lexicon.yaml", errors.New("timeout"))
+
+	data := receiveEvent(t, messages)
+	assert.Equal(t, eventUpstreamFailure, data["event"], "events below the configured level should be dropped")
+}
+
+func TestEmitSchemaLoaded(t *testing.T) {
+	t.Cleanup(func() { schemaSeen = "" })
+	schemaSeen = ""
+
+	messages := connectEventClient(t, "info")
+	ctx := context.Background()
+
+	emitSchemaLoaded(ctx, "v0.7.0")
+	data := receiveEvent(t, messages)
+	assert.Equal(t, eventSchemaLoaded, data["event"])
+	assert.Equal(t, "v0.7.0", data["version"])
+
+	// An unchanged version is not reported again
+	emitSchemaLoaded(ctx, "v0.7.0")
+	emitSchemaLoaded(ctx, "v0.8.0")
+	data = receiveEvent(t, messages)
+	assert.Equal(t, eventSchemaRefreshed, data["event"])
+	assert.Equal(t, "v0.7.0", data["previous"])
+}
+
+func TestValidateEventLevel(t *testing.T) {
+	assert.NoError(t, ValidateEventLevel("warning"))
+	assert.ErrorContains(t, ValidateEventLevel("verbose"), "invalid log level")
+}
@@ -0,0 +1,338 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Kinds of evidence attach_evidence records.
+const (
+	evidenceCommand = "command"
+	evidenceFile    = "file"
+	evidenceURL     = "url"
+)
+
+var evidenceTypes = []string{evidenceCommand, evidenceFile, evidenceURL}
+
+// evidencePrefix starts the message line of an evidence reference. The
+// #AssessmentLog schema has no field for evidence, so references are kept in
+// the free-text message, one per line, where tools and reviewers can find them.
+const evidencePrefix = "Evidence: "
+
+// MetadataAttachEvidence describes the AttachEvidence tool.
+var MetadataAttachEvidence = &mcp.Tool{
+	Name: "attach_evidence",
+	Description: "Attach evidence (command output, file hashes, or URLs) to assessment logs of an EvaluationLog. " +
+		"Each piece of evidence is recorded as a reference with its sha256 digest in the message of the assessment log " +
+		"for its requirement; command output itself is hashed, not stored. The updated log is validated against the schema.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"evidence"},
+		"properties": map[string]interface{}{
+			"artifact_content": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML content of the EvaluationLog",
+			},
+			"artifact_uri": map[string]interface{}{
+				"type":        "string",
+				"description": "URI of the EvaluationLog instead of inline content",
+			},
+			"evidence": map[string]interface{}{
+				"type":     "array",
+				"minItems": 1,
+				"items": map[string]interface{}{
+					"type":     "object",
+					"required": []string{"requirement", "type"},
+					"properties": map[string]interface{}{
+						"requirement": map[string]interface{}{
+							"type":        "string",
+							"description": "Entry id of the assessment requirement whose log the evidence supports",
+						},
+						"control": map[string]interface{}{
+							"type":        "string",
+							"description": "Entry id of the control, when the requirement is assessed under several controls",
+						},
+						"type": map[string]interface{}{
+							"type":        "string",
+							"enum":        evidenceTypes,
+							"description": "command: the output of a command or query; file: a file by its hash; url: a document or record online",
+						},
+						"command": map[string]interface{}{
+							"type":        "string",
+							"description": "Command or query that produced output, such as an osquery statement",
+						},
+						"output": map[string]interface{}{
+							"type":        "string",
+							"description": "Output of the command, hashed into the reference",
+						},
+						"path": map[string]interface{}{
+							"type":        "string",
+							"description": "Path of the file; hashed when digest is omitted, which requires it to be within the workspace root",
+						},
+						"url": map[string]interface{}{
+							"type":        "string",
+							"description": "https URL of the evidence",
+						},
+						"digest": map[string]interface{}{
+							"type":        "string",
+							"description": "sha256:<hex> digest of the file or URL content, when known",
+						},
+						"description": map[string]interface{}{
+							"type":        "string",
+							"description": "What the evidence shows",
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+// InputAttachEvidence is the input for the AttachEvidence tool.
+type InputAttachEvidence struct {
+	ArtifactContent string          `json:"artifact_content,omitempty"`
+	ArtifactURI     string          `json:"artifact_uri,omitempty"`
+	Evidence        []EvidenceInput `json:"evidence"`
+}
+
+// EvidenceInput is one piece of evidence for an assessment log.
+type EvidenceInput struct {
+	Requirement string `json:"requirement"`
+	Control     string `json:"control,omitempty"`
+	Type        string `json:"type"`
+	Command     string `json:"command,omitempty"`
+	Output      string `json:"output,omitempty"`
+	Path        string `json:"path,omitempty"`
+	URL         string `json:"url,omitempty"`
+	Digest      string `json:"digest,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// AttachedEvidence is an evidence reference recorded in an assessment log.
+type AttachedEvidence struct {
+	Control     string `json:"control"`
+	Requirement string `json:"requirement"`
+	// Path locates the assessment log in the artifact.
+	Path      string `json:"path"`
+	Reference string `json:"reference"`
+	Digest    string `json:"digest,omitempty"`
+	// Duplicate is set when the log already held the reference.
+	Duplicate bool `json:"duplicate,omitempty"`
+}
+
+// OutputAttachEvidence is the output for the AttachEvidence tool.
+type OutputAttachEvidence struct {
+	Content  string             `json:"content"`
+	Attached []AttachedEvidence `json:"attached"`
+	Valid    bool               `json:"valid"`
+	Errors   []string           `json:"errors,omitempty"`
+	Message  string             `json:"message"`
+}
+
+// AttachEvidence records evidence references in the assessment logs of an evaluation log.
+func AttachEvidence(ctx context.Context, _ *mcp.CallToolRequest, input InputAttachEvidence) (*mcp.CallToolResult, OutputAttachEvidence, error) {
+	if len(input.Evidence) == 0 {
+		return nil, OutputAttachEvidence{}, fmt.Errorf("evidence is required")
+	}
+	content, err := artifactInputContent(ctx, input.ArtifactContent, input.ArtifactURI)
+	if err != nil {
+		return nil, OutputAttachEvidence{}, err
+	}
+	doc, err := parseArtifact(ctx, content)
+	if err != nil {
+		return nil, OutputAttachEvidence{}, err
+	}
+	if kind := artifactKind(doc); kind != "EvaluationLog" {
+		if kind == "" {
+			kind = "unknown"
+		}
+		return nil, OutputAttachEvidence{}, fmt.Errorf("expected an EvaluationLog, got %s", kind)
+	}
+
+	var tree yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions([]byte(content), &tree, yaml.UseOrderedMap()); err != nil {
+		return nil, OutputAttachEvidence{}, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	evaluations := mapValueList(tree, "evaluations")
+
+	output := OutputAttachEvidence{Attached: []AttachedEvidence{}}
+	for i, evidence := range input.Evidence {
+		reference, digest, err := evidenceReference(ctx, evidence)
+		if err != nil {
+			return nil, OutputAttachEvidence{}, fmt.Errorf("evidence[%d]: %w", i, err)
+		}
+		target, err := findAssessmentLog(evaluations, evidence.Control, evidence.Requirement)
+		if err != nil {
+			return nil, OutputAttachEvidence{}, fmt.Errorf("evidence[%d]: %w", i, err)
+		}
+
+		line := evidencePrefix + reference
+		message, _ := mapValue(target.log, "message").(string)
+		attached := AttachedEvidence{Control: target.control, Requirement: evidence.Requirement, Path: target.path, Reference: reference, Digest: digest}
+		if containsLine(message, line) {
+			attached.Duplicate = true
+		} else {
+			message = strings.TrimRight(message, "\n")
+			if message != "" {
+				message += "\n"
+			}
+			target.set(setMapValue(target.log, "message", message+line))
+		}
+		output.Attached = append(output.Attached, attached)
+	}
+
+	out, err := yaml.MarshalWithOptions(tree, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return nil, OutputAttachEvidence{}, fmt.Errorf("failed to encode evaluation log: %w", err)
+	}
+	output.Content = string(out)
+
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputAttachEvidence{}, err
+	}
+	validation, err := validateAgainstSchema(schema, "#EvaluationLog", output.Content)
+	if err != nil {
+		return nil, OutputAttachEvidence{}, err
+	}
+	output.Valid = validation.Valid
+	output.Errors = validation.Errors
+
+	var duplicates int
+	for _, a := range output.Attached {
+		if a.Duplicate {
+			duplicates++
+		}
+	}
+	output.Message = fmt.Sprintf("Attached %d evidence reference(s); %s", len(output.Attached)-duplicates, strings.ToLower(validation.Message))
+	if duplicates > 0 {
+		output.Message += fmt.Sprintf("; %d were already attached", duplicates)
+	}
+	return nil, output, nil
+}
+
+// evidenceReference describes a piece of evidence in one line and returns the
+// digest it pins.
+func evidenceReference(ctx context.Context, e EvidenceInput) (string, string, error) {
+	if e.Requirement == "" {
+		return "", "", fmt.Errorf("requirement is required")
+	}
+	if e.Digest != "" && !strings.HasPrefix(e.Digest, "sha256:") {
+		return "", "", fmt.Errorf("digest must be of the form sha256:<hex>")
+	}
+
+	var reference, digest string
+	switch e.Type {
+	case evidenceCommand:
+		if e.Command == "" || e.Output == "" {
+			return "", "", fmt.Errorf("command evidence needs command and output")
+		}
+		digest = contentDigest([]byte(e.Output))
+		reference = fmt.Sprintf("command %q output %s", oneLine(e.Command), digest)
+	case evidenceFile:
+		if e.Path == "" {
+			return "", "", fmt.Errorf("file evidence needs path")
+		}
+		digest = e.Digest
+		if digest == "" {
+			content, err := readWorkspaceFile(ctx, e.Path)
+			if err != nil {
+				return "", "", fmt.Errorf("failed to hash %s; supply digest for files outside the workspace: %w", e.Path, err)
+			}
+			digest = contentDigest(content)
+		}
+		reference = fmt.Sprintf("file %s %s", e.Path, digest)
+	case evidenceURL:
+		u, err := url.Parse(e.URL)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return "", "", fmt.Errorf("url evidence needs an https url")
+		}
+		digest = e.Digest
+		reference = "url " + e.URL
+		if digest != "" {
+			reference += " " + digest
+		}
+	default:
+		return "", "", fmt.Errorf("unsupported type %q; expected one of %s", e.Type, strings.Join(evidenceTypes, ", "))
+	}
+	if e.Description != "" {
+		reference += " - " + oneLine(e.Description)
+	}
+	return reference, digest, nil
+}
+
+// assessmentLogTarget is an assessment log found in an ordered evaluation log
+// tree, with a way to replace it when a key is added.
+type assessmentLogTarget struct {
+	control string
+	path    string
+	log     yaml.MapSlice
+	set     func(yaml.MapSlice)
+}
+
+// findAssessmentLog finds the assessment log of a requirement, under the
+// given control when one is named.
+func findAssessmentLog(evaluations []interface{}, control, requirement string) (assessmentLogTarget, error) {
+	var found []assessmentLogTarget
+	for i, e := range evaluations {
+		evaluation, ok := e.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+		controlID := orderedEntryID(mapValue(evaluation, "control"))
+		if control != "" && controlID != control {
+			continue
+		}
+		logs := mapValueList(evaluation, "assessment-logs")
+		for j, l := range logs {
+			log, ok := l.(yaml.MapSlice)
+			if !ok || orderedEntryID(mapValue(log, "requirement")) != requirement {
+				continue
+			}
+			found = append(found, assessmentLogTarget{
+				control: controlID,
+				path:    fmt.Sprintf("$.evaluations[%d].assessment-logs[%d]", i, j),
+				log:     log,
+				set:     func(updated yaml.MapSlice) { logs[j] = updated },
+			})
+		}
+	}
+	switch {
+	case len(found) == 0 && control != "":
+		return assessmentLogTarget{}, fmt.Errorf("no assessment log for requirement %s under control %s", requirement, control)
+	case len(found) == 0:
+		return assessmentLogTarget{}, fmt.Errorf("no assessment log for requirement %s", requirement)
+	case len(found) > 1:
+		return assessmentLogTarget{}, fmt.Errorf("requirement %s is assessed %d times; name the control", requirement, len(found))
+	}
+	return found[0], nil
+}
+
+// orderedEntryID returns the entry-id of an ordered entry mapping.
+func orderedEntryID(value interface{}) string {
+	m, _ := value.(yaml.MapSlice)
+	id, _ := mapValue(m, "entry-id").(string)
+	return id
+}
+
+// containsLine reports whether text has line as one of its lines.
+func containsLine(text, line string) bool {
+	for _, l := range strings.Split(text, "\n") {
+		if strings.TrimSpace(l) == line {
+			return true
+		}
+	}
+	return false
+}
+
+// oneLine collapses whitespace so a value fits on one message line.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachEvidence(t *testing.T) {
+	useTestSchema(t)
+	log, err := os.ReadFile(filepath.Join("test-data", "evaluation-log.yaml"))
+	require.NoError(t, err)
+
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "tls.json"), []byte(`{"tls": "1.2"}`), 0o600))
+	ctx := withTestConfig(func(c *Config) { c.WorkspaceRoot = root })
+
+	tests := []struct {
+		name         string
+		evidence     []EvidenceInput
+		wantErr      string
+		wantAttached []AttachedEvidence
+		wantContains []string
+	}{
+		{
+			name: "command, file, and url",
+			evidence: []EvidenceInput{
+				{Requirement: "CCC.C01.TR01", Type: evidenceCommand, Command: "SELECT * FROM listening_ports;", Output: "443 tcp\n", Description: "osquery ports"},
+				{Requirement: "CCC.C01.TR01", Type: evidenceFile, Path: filepath.Join(root, "tls.json")},
+				{Requirement: "CCC.C06.TR01", Control: "CCC.C06", Type: evidenceURL, URL: "https://example.com/regions"},
+			},
+			wantAttached: []AttachedEvidence{
+				{
+					Control: "CCC.C01", Requirement: "CCC.C01.TR01", Path: "$.evaluations[0].assessment-logs[0]",
+					Reference: `command "SELECT * FROM listening_ports;" output ` + contentDigest([]byte("443 tcp\n")) + " - osquery ports",
+					Digest:    contentDigest([]byte("443 tcp\n")),
+				},
+				{
+					Control: "CCC.C01", Requirement: "CCC.C01.TR01", Path: "$.evaluations[0].assessment-logs[0]",
+					Reference: "file " + filepath.Join(root, "tls.json") + " " + contentDigest([]byte(`{"tls": "1.2"}`)),
+					Digest:    contentDigest([]byte(`{"tls": "1.2"}`)),
+				},
+				{
+					Control: "CCC.C06", Requirement: "CCC.C06.TR01", Path: "$.evaluations[1].assessment-logs[0]",
+					Reference: "url https://example.com/regions",
+				},
+			},
+			wantContains: []string{"Evidence: command", "Evidence: file", `message: "Evidence: url https://example.com/regions"`},
+		},
+		{
+			name: "same evidence twice",
+			evidence: []EvidenceInput{
+				{Requirement: "CCC.C06.TR01", Type: evidenceURL, URL: "https://example.com/regions", Digest: "sha256:abcd"},
+				{Requirement: "CCC.C06.TR01", Type: evidenceURL, URL: "https://example.com/regions", Digest: "sha256:abcd"},
+			},
+			wantAttached: []AttachedEvidence{
+				{Control: "CCC.C06", Requirement: "CCC.C06.TR01", Path: "$.evaluations[1].assessment-logs[0]", Reference: "url https://example.com/regions sha256:abcd", Digest: "sha256:abcd"},
+				{Control: "CCC.C06", Requirement: "CCC.C06.TR01", Path: "$.evaluations[1].assessment-logs[0]", Reference: "url https://example.com/regions sha256:abcd", Digest: "sha256:abcd", Duplicate: true},
+			},
+		},
+		{
+			name:     "unknown requirement",
+			evidence: []EvidenceInput{{Requirement: "CCC.C99.TR01", Type: evidenceURL, URL: "https://example.com"}},
+			wantErr:  "evidence[0]: no assessment log for requirement CCC.C99.TR01",
+		},
+		{
+			name:     "wrong control",
+			evidence: []EvidenceInput{{Requirement: "CCC.C01.TR01", Control: "CCC.C06", Type: evidenceURL, URL: "https://example.com"}},
+			wantErr:  "under control CCC.C06",
+		},
+		{
+			name:     "command without output",
+			evidence: []EvidenceInput{{Requirement: "CCC.C01.TR01", Type: evidenceCommand, Command: "uname -a"}},
+			wantErr:  "command evidence needs command and output",
+		},
+		{
+			name:     "plain http url",
+			evidence: []EvidenceInput{{Requirement: "CCC.C01.TR01", Type: evidenceURL, URL: "http://example.com"}},
+			wantErr:  "url evidence needs an https url",
+		},
+		{
+			name:     "file outside the workspace",
+			evidence: []EvidenceInput{{Requirement: "CCC.C01.TR01", Type: evidenceFile, Path: "/etc/hostname"}},
+			wantErr:  "supply digest for files outside the workspace",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := AttachEvidence(ctx, nil, InputAttachEvidence{ArtifactContent: string(log), Evidence: tt.evidence})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, output.Valid, "log with evidence should stay valid: %v", output.Errors)
+			assert.Equal(t, tt.wantAttached, output.Attached)
+			for _, want := range tt.wantContains {
+				assert.Contains(t, output.Content, want)
+			}
+
+			// Attaching the same evidence to the result changes nothing
+			_, again, err := AttachEvidence(ctx, nil, InputAttachEvidence{ArtifactContent: output.Content, Evidence: tt.evidence})
+			require.NoError(t, err)
+			assert.Equal(t, output.Content, again.Content)
+		})
+	}
+
+	_, _, err = AttachEvidence(ctx, nil, InputAttachEvidence{
+		ArtifactContent: "metadata:\n  id: X\ncontrols: []\n",
+		Evidence:        []EvidenceInput{{Requirement: "R", Type: evidenceURL, URL: "https://example.com"}},
+	})
+	assert.ErrorContains(t, err, "expected an EvaluationLog")
+}
@@ -0,0 +1,227 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	examplesResourcePrefix      = "gemara://examples/"
+	examplesResourceURITemplate = examplesResourcePrefix + "{definition}/{n}"
+	examplesSampleURITemplate   = examplesResourcePrefix + "{definition}"
+	// exampleSampleSize caps how many examples a definition sample includes.
+	exampleSampleSize = 3
+)
+
+// examplesFS holds small, valid example artifacts grouped by definition name.
+//
+//go:embed examples
+var examplesFS embed.FS
+
+// MetadataExampleResourceTemplate describes the example artifact resource template.
+var MetadataExampleResourceTemplate = &mcp.ResourceTemplate{
+	Name:        "example",
+	URITemplate: examplesResourceURITemplate,
+	Title:       "Gemara Example Artifact",
+	Description: "A small, valid example artifact for a Gemara definition (e.g., gemara://examples/ControlCatalog/1), " +
+		"suitable for few-shot prompting.",
+	MIMEType: "application/yaml",
+}
+
+// MetadataExampleSampleResourceTemplate describes the per-definition example sample.
+var MetadataExampleSampleResourceTemplate = &mcp.ResourceTemplate{
+	Name:        "examples",
+	URITemplate: examplesSampleURITemplate,
+	Title:       "Gemara Example Artifacts",
+	Description: fmt.Sprintf("Up to %d curated, valid example artifacts for a Gemara definition (e.g., gemara://examples/Policy) "+
+		"as a multi-document YAML stream, for few-shot prompting.", exampleSampleSize),
+	MIMEType: "application/yaml",
+}
+
+// exampleArtifact is a bundled example artifact.
+type exampleArtifact struct {
+	Definition string
+	Index      int
+	File       string
+}
+
+// URI returns the resource URI of the example.
+func (e exampleArtifact) URI() string {
+	return fmt.Sprintf("%s%s/%d", examplesResourcePrefix, e.Definition, e.Index)
+}
+
+// listExamples returns every bundled example ordered by definition and index.
+func listExamples() ([]exampleArtifact, error) {
+	defs, err := fs.ReadDir(examplesFS, "examples")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read examples: %w", err)
+	}
+
+	var examples []exampleArtifact
+	for _, def := range defs {
+		if !def.IsDir() {
+			continue
+		}
+		files, err := exampleFiles(def.Name())
+		if err != nil {
+			return nil, err
+		}
+		for i, file := range files {
+			examples = append(examples, exampleArtifact{
+				Definition: def.Name(),
+				Index:      i + 1,
+				File:       file,
+			})
+		}
+	}
+	return examples, nil
+}
+
+// exampleFiles returns the sorted example file paths for a definition.
+func exampleFiles(definition string) ([]string, error) {
+	entries, err := fs.ReadDir(examplesFS, path.Join("examples", definition))
+	if err != nil {
+		return nil, fmt.Errorf("no examples for definition %s", definition)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".yaml") {
+			files = append(files, path.Join("examples", definition, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// exampleResources returns a concrete resource for every bundled example so
+// they show up in resource listings alongside the template.
+func exampleResources() []*mcp.Resource {
+	examples, err := listExamples()
+	if err != nil {
+		return nil
+	}
+
+	resources := make([]*mcp.Resource, 0, len(examples))
+	seen := map[string]bool{}
+	for _, e := range examples {
+		if !seen[e.Definition] {
+			seen[e.Definition] = true
+			resources = append(resources, &mcp.Resource{
+				Name:        fmt.Sprintf("examples-%s", strings.ToLower(e.Definition)),
+				URI:         examplesResourcePrefix + e.Definition,
+				Title:       fmt.Sprintf("Example %s artifacts", e.Definition),
+				Description: fmt.Sprintf("Sample of valid #%s artifacts as a multi-document YAML stream", e.Definition),
+				MIMEType:    "application/yaml",
+			})
+		}
+		resources = append(resources, &mcp.Resource{
+			Name:        fmt.Sprintf("example-%s-%d", strings.ToLower(e.Definition), e.Index),
+			URI:         e.URI(),
+			Title:       fmt.Sprintf("Example %s #%d", e.Definition, e.Index),
+			Description: fmt.Sprintf("Valid example #%s artifact (%s)", e.Definition, path.Base(e.File)),
+			MIMEType:    "application/yaml",
+		})
+	}
+	return resources
+}
+
+// HandleExampleResource serves a bundled example artifact, or a sample of the
+// examples for a definition.
+func HandleExampleResource(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	if rest, ok := strings.CutPrefix(uri, examplesResourcePrefix); ok && rest != "" && !strings.Contains(rest, "/") {
+		return exampleSample(uri, strings.TrimPrefix(rest, "#"))
+	}
+	definition, n, err := parseExampleURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := exampleFiles(definition)
+	if err != nil || n < 1 || n > len(files) {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	content, err := examplesFS.ReadFile(files[n-1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read example: %w", err)
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: "application/yaml",
+				Text:     string(content),
+			},
+		},
+	}, nil
+}
+
+// exampleSample serves up to exampleSampleSize examples of a definition, spread
+// evenly across the bundled ones, as a multi-document YAML stream.
+func exampleSample(uri, definition string) (*mcp.ReadResourceResult, error) {
+	files, err := exampleFiles(definition)
+	if err != nil || len(files) == 0 {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	count := min(len(files), exampleSampleSize)
+	var b strings.Builder
+	for i := 0; i < count; i++ {
+		index := i * len(files) / count
+		content, err := examplesFS.ReadFile(files[index])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read example: %w", err)
+		}
+		if i > 0 {
+			b.WriteString("---\n")
+		}
+		e := exampleArtifact{Definition: definition, Index: index + 1, File: files[index]}
+		fmt.Fprintf(&b, "# %s (%s)\n", e.URI(), path.Base(e.File))
+		b.Write(content)
+		if !strings.HasSuffix(string(content), "\n") {
+			b.WriteString("\n")
+		}
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: "application/yaml",
+				Text:     b.String(),
+			},
+		},
+	}, nil
+}
+
+// parseExampleURI splits gemara://examples/{definition}/{n} into its parts.
+func parseExampleURI(uri string) (string, int, error) {
+	rest, ok := strings.CutPrefix(uri, examplesResourcePrefix)
+	if !ok {
+		return "", 0, fmt.Errorf("invalid example URI %q", uri)
+	}
+
+	definition, index, ok := strings.Cut(rest, "/")
+	if !ok || definition == "" {
+		return "", 0, fmt.Errorf("invalid example URI %q: expected %s", uri, examplesResourceURITemplate)
+	}
+
+	n, err := strconv.Atoi(index)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid example index %q: %w", index, err)
+	}
+	return strings.TrimPrefix(definition, "#"), n, nil
+}
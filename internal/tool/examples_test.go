@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExamplesAreValid(t *testing.T) {
+	useTestSchema(t)
+
+	examples, err := listExamples()
+	require.NoError(t, err, "should list examples")
+	require.NotEmpty(t, examples, "examples should be bundled")
+
+	for _, e := range examples {
+		t.Run(e.URI(), func(t *testing.T) {
+			content, err := examplesFS.ReadFile(e.File)
+			require.NoError(t, err, "should read example")
+
+			_, output, err := ValidateGemaraArtifact(context.Background(), nil, InputValidateGemaraArtifact{
+				ArtifactContent: string(content),
+				Definition:      e.Definition,
+			})
+			require.NoError(t, err, "validation should run")
+			assert.True(t, output.Valid, "example should be valid: %v", output.Errors)
+		})
+	}
+}
+
+func TestHandleExampleResource(t *testing.T) {
+	tests := []struct {
+		name        string
+		uri         string
+		wantErr     bool
+		errContains string
+		contains    string
+	}{
+		{
+			name:     "first control catalog",
+			uri:      "gemara://examples/ControlCatalog/1",
+			contains: "Object Storage Controls",
+		},
+		{
+			name:     "second control catalog",
+			uri:      "gemara://examples/ControlCatalog/2",
+			contains: "CI Pipeline Controls",
+		},
+		{
+			name:        "index out of range",
+			uri:         "gemara://examples/ControlCatalog/99",
+			wantErr:     true,
+			errContains: "not found",
+		},
+		{
+			name:        "unknown definition",
+			uri:         "gemara://examples/Unknown/1",
+			wantErr:     true,
+			errContains: "not found",
+		},
+		{
+			name:        "non-numeric index",
+			uri:         "gemara://examples/Policy/first",
+			wantErr:     true,
+			errContains: "invalid example index",
+		},
+		{
+			name:     "definition sample",
+			uri:      "gemara://examples/Policy",
+			contains: "# gemara://examples/Policy/1 (01-saas-baseline.yaml)",
+		},
+		{
+			name:        "sample of unknown definition",
+			uri:         "gemara://examples/Unknown",
+			wantErr:     true,
+			errContains: "not found",
+		},
+		{
+			name:        "missing definition",
+			uri:         "gemara://examples//1",
+			wantErr:     true,
+			errContains: "invalid example URI",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: tt.uri}}
+			result, err := HandleExampleResource(context.Background(), req)
+
+			if tt.wantErr {
+				require.Error(t, err, "should return error")
+				assert.Contains(t, err.Error(), tt.errContains, "error should contain expected message")
+				return
+			}
+
+			require.NoError(t, err, "should not return error")
+			require.Len(t, result.Contents, 1, "should return one content item")
+			assert.Equal(t, tt.uri, result.Contents[0].URI, "URI should echo the request")
+			assert.Contains(t, result.Contents[0].Text, tt.contains, "content should match the example")
+		})
+	}
+}
+
+func TestExampleSample(t *testing.T) {
+	useTestSchema(t)
+	schema, err := loadSchema(context.Background())
+	require.NoError(t, err)
+
+	for _, definition := range []string{"ControlCatalog", "Policy", "EvaluationLog", "GuidanceDocument"} {
+		t.Run(definition, func(t *testing.T) {
+			uri := "gemara://examples/" + definition
+			result, err := HandleExampleResource(context.Background(), &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: uri}})
+			require.NoError(t, err)
+			require.Len(t, result.Contents, 1)
+
+			files, err := exampleFiles(definition)
+			require.NoError(t, err)
+			documents := strings.Split(result.Contents[0].Text, "\n---\n")
+			assert.Len(t, documents, min(len(files), exampleSampleSize), "sample should include up to %d examples", exampleSampleSize)
+			for _, doc := range documents {
+				validation, err := validateAgainstSchema(schema, "#"+definition, doc)
+				require.NoError(t, err)
+				assert.True(t, validation.Valid, "sampled example should be valid: %v", validation.Errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,422 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	federatedResourcePrefix = "gemara://federated/"
+	federationCacheTTL      = time.Hour
+
+	gitSourcePrefix = "git::"
+	ociSourceScheme = "oci://"
+)
+
+// FederatedCatalog declares a logical catalog composed from several sources.
+type FederatedCatalog struct {
+	Name    string   `yaml:"name"`
+	Title   string   `yaml:"title,omitempty"`
+	Sources []string `yaml:"sources"`
+}
+
+// FederationConfig is the file format read by LoadFederation.
+type FederationConfig struct {
+	Catalogs []FederatedCatalog `yaml:"catalogs"`
+}
+
+var (
+	federationMu    sync.Mutex
+	federationCache = map[string]federatedEntry{}
+	// federationFlight shares one merge of a catalog among concurrent reads.
+	federationFlight singleflight.Group
+)
+
+type federatedEntry struct {
+	content []byte
+	fetched time.Time
+}
+
+// LoadFederation reads federated catalog declarations from a YAML file.
+func LoadFederation(path string) ([]FederatedCatalog, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read federation config: %w", err)
+	}
+	var config FederationConfig
+	if err := yaml.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse federation config: %w", err)
+	}
+
+	seen := map[string]bool{}
+	for _, c := range config.Catalogs {
+		switch {
+		case c.Name == "":
+			return nil, fmt.Errorf("federated catalog is missing a name")
+		case strings.ContainsAny(c.Name, "/?#"):
+			return nil, fmt.Errorf("federated catalog name %q must not contain '/', '?', or '#'", c.Name)
+		case seen[c.Name]:
+			return nil, fmt.Errorf("federated catalog %s is declared twice", c.Name)
+		case len(c.Sources) == 0:
+			return nil, fmt.Errorf("federated catalog %s has no sources", c.Name)
+		}
+		seen[c.Name] = true
+	}
+	return config.Catalogs, nil
+}
+
+// federatedResources returns a resource for every federated catalog.
+func federatedResources(catalogs []FederatedCatalog) []*mcp.Resource {
+	resources := make([]*mcp.Resource, 0, len(catalogs))
+	for _, c := range catalogs {
+		title := c.Title
+		if title == "" {
+			title = c.Name
+		}
+		resources = append(resources, &mcp.Resource{
+			Name:        "federated-" + c.Name,
+			URI:         federatedResourcePrefix + c.Name,
+			Title:       title,
+			Description: fmt.Sprintf("Catalog %s merged on demand from %d source(s)", c.Name, len(c.Sources)),
+			MIMEType:    "application/yaml",
+		})
+	}
+	return resources
+}
+
+// HandleFederatedResource serves a federated catalog, resolving its sources on demand.
+func HandleFederatedResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	content, err := readFederatedCatalog(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: "application/yaml",
+				Text:     string(content),
+			},
+		},
+	}, nil
+}
+
+// readFederatedCatalog returns the merged content of gemara://federated/{name}.
+func readFederatedCatalog(ctx context.Context, uri string) ([]byte, error) {
+	name, _ := strings.CutPrefix(uri, federatedResourcePrefix)
+	for _, c := range serverConfig(ctx).Federation {
+		if c.Name != name {
+			continue
+		}
+		entry, err := resolveFederatedCatalog(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		return entry.content, nil
+	}
+	return nil, mcp.ResourceNotFoundError(uri)
+}
+
+// resolveFederatedCatalog fetches and merges the sources of a catalog, using the cache when fresh.
+func resolveFederatedCatalog(ctx context.Context, c FederatedCatalog) (federatedEntry, error) {
+	federationMu.Lock()
+	cached, ok := federationCache[c.Name]
+	federationMu.Unlock()
+	if ok && time.Since(cached.fetched) < federationCacheTTL {
+		return cached, nil
+	}
+
+	entry, _, err := sharedCall(ctx, &federationFlight, c.Name, func(ctx context.Context) (federatedEntry, error) {
+		return mergeFederatedCatalog(ctx, c, cached, ok)
+	})
+	return entry, err
+}
+
+// mergeFederatedCatalog fetches and merges the sources of a catalog. While a
+// source is down, the previous merge is served if there is one.
+func mergeFederatedCatalog(ctx context.Context, c FederatedCatalog, cached federatedEntry, ok bool) (federatedEntry, error) {
+	emitCacheMiss(ctx, "federated catalog", federatedResourcePrefix+c.Name)
+	docs := make([]yaml.MapSlice, 0, len(c.Sources))
+	for _, source := range c.Sources {
+		raw, err := fetchCatalogSource(ctx, source)
+		if err != nil {
+			// Keep answering from the previous merge while a source is down
+			if ok {
+				emitStaleCache(ctx, "federated catalog", source, err)
+				return cached, nil
+			}
+			return federatedEntry{}, fmt.Errorf("federated catalog %s: %w", c.Name, err)
+		}
+		var doc yaml.MapSlice
+		if err := yaml.UnmarshalWithOptions(raw, &doc, yaml.UseOrderedMap()); err != nil {
+			return federatedEntry{}, fmt.Errorf("federated catalog %s: failed to parse %s: %w", c.Name, source, err)
+		}
+		docs = append(docs, doc)
+	}
+
+	merged, conflicts := mergeCatalogDocuments(docs, c.Sources)
+	if c.Title != "" {
+		merged = setMapValue(merged, "title", c.Title)
+	}
+
+	body, err := yaml.MarshalWithOptions(merged, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return federatedEntry{}, fmt.Errorf("failed to encode federated catalog %s: %w", c.Name, err)
+	}
+
+	var header bytes.Buffer
+	fmt.Fprintf(&header, "# Federated catalog %s merged from:\n", c.Name)
+	for _, source := range c.Sources {
+		fmt.Fprintf(&header, "#   - %s\n", source)
+	}
+	for _, conflict := range conflicts {
+		fmt.Fprintf(&header, "# conflict: %s\n", conflict)
+	}
+
+	entry := federatedEntry{
+		content: append(header.Bytes(), body...),
+		fetched: time.Now(),
+	}
+	federationMu.Lock()
+	federationCache[c.Name] = entry
+	federationMu.Unlock()
+	return entry, nil
+}
+
+// mergeCatalogDocuments composes catalogs in source order. Top-level lists are
+// concatenated, skipping entries whose id was already contributed; other values
+// keep the first source's version. Differing duplicates are reported as conflicts.
+func mergeCatalogDocuments(docs []yaml.MapSlice, sources []string) (yaml.MapSlice, []string) {
+	var merged yaml.MapSlice
+	var conflicts []string
+	index := map[string]int{}
+	seenIDs := map[string]map[string]interface{}{}
+
+	for d, doc := range docs {
+		for _, item := range doc {
+			key := fmt.Sprint(item.Key)
+			i, exists := index[key]
+			if !exists {
+				index[key] = len(merged)
+				if list, ok := item.Value.([]interface{}); ok {
+					// Copy so that deduplication below does not alias the source
+					item.Value = append([]interface{}{}, list...)
+					for _, elem := range list {
+						if id := orderedID(elem); id != "" {
+							if seenIDs[key] == nil {
+								seenIDs[key] = map[string]interface{}{}
+							}
+							seenIDs[key][id] = elem
+						}
+					}
+				}
+				merged = append(merged, item)
+				continue
+			}
+
+			list, isList := item.Value.([]interface{})
+			existing, wasList := merged[i].Value.([]interface{})
+			if !isList || !wasList {
+				if !reflect.DeepEqual(merged[i].Value, item.Value) {
+					conflicts = append(conflicts, fmt.Sprintf("%s from %s ignored; keeping first value", key, sources[d]))
+				}
+				continue
+			}
+
+			for _, elem := range list {
+				id := orderedID(elem)
+				if id != "" {
+					if prior, dup := seenIDs[key][id]; dup {
+						if !reflect.DeepEqual(prior, elem) {
+							conflicts = append(conflicts, fmt.Sprintf("%s %s from %s differs from an earlier source; keeping first", key, id, sources[d]))
+						}
+						continue
+					}
+					if seenIDs[key] == nil {
+						seenIDs[key] = map[string]interface{}{}
+					}
+					seenIDs[key][id] = elem
+				}
+				existing = append(existing, elem)
+			}
+			merged[i].Value = existing
+		}
+	}
+	return merged, conflicts
+}
+
+// orderedID returns the id field of an ordered mapping.
+func orderedID(v interface{}) string {
+	m, ok := v.(yaml.MapSlice)
+	if !ok {
+		return ""
+	}
+	for _, item := range m {
+		if item.Key == "id" {
+			if id, ok := item.Value.(string); ok {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+// setMapValue sets key in an ordered mapping, appending it when absent.
+func setMapValue(m yaml.MapSlice, key string, value interface{}) yaml.MapSlice {
+	for i := range m {
+		if m[i].Key == key {
+			m[i].Value = value
+			return m
+		}
+	}
+	return append(m, yaml.MapItem{Key: key, Value: value})
+}
+
+// fetchCatalogSource retrieves one federation source. Sources are https:// or
+// file:// URLs, git::<repository>//<path>?ref=<ref>, or oci://<reference>//<path>.
+func fetchCatalogSource(ctx context.Context, source string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(source, gitSourcePrefix):
+		return fetchGitSource(ctx, strings.TrimPrefix(source, gitSourcePrefix))
+	case strings.HasPrefix(source, ociSourceScheme):
+		return fetchOCISource(ctx, strings.TrimPrefix(source, ociSourceScheme))
+	default:
+		return fetchURL(ctx, source)
+	}
+}
+
+// splitSourcePath splits "<location>//<path>" where location may itself contain "://".
+func splitSourcePath(s string) (string, string) {
+	start := 0
+	if i := strings.Index(s, "://"); i >= 0 {
+		start = i + len("://")
+	}
+	if i := strings.Index(s[start:], "//"); i >= 0 {
+		return s[:start+i], s[start+i+2:]
+	}
+	return s, ""
+}
+
+// parseGitSource splits a git source spec into the repository, the path of the
+// file within it, and the optional ref.
+func parseGitSource(spec string) (repo, path, ref string, err error) {
+	if i := strings.LastIndex(spec, "?"); i >= 0 {
+		query, err := url.ParseQuery(spec[i+1:])
+		if err != nil {
+			return "", "", "", fmt.Errorf("invalid git source %q: %w", spec, err)
+		}
+		ref = query.Get("ref")
+		spec = spec[:i]
+	}
+	repo, path = splitSourcePath(spec)
+	if path == "" {
+		return "", "", "", fmt.Errorf("invalid git source %q: expected <repository>//<path>", spec)
+	}
+	return repo, path, ref, nil
+}
+
+// validateGitRemote refuses git sources that do not clone over https, so that
+// references from tool input or artifact content cannot clone repositories
+// from the local filesystem or through other git transports.
+func validateGitRemote(spec string) error {
+	repo, _, _, err := parseGitSource(spec)
+	if err != nil {
+		return err
+	}
+	u, err := url.Parse(repo)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("git repository %q must be an https:// URL", repo)
+	}
+	return nil
+}
+
+func fetchGitSource(ctx context.Context, spec string) ([]byte, error) {
+	repo, path, ref, err := parseGitSource(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "gemara-git-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, "--", repo, dir)
+	if err := runSourceCommand(ctx, "git", args...); err != nil {
+		return nil, fmt.Errorf("failed to clone %s: %w", repo, err)
+	}
+	return readSourceFile(dir, path)
+}
+
+func fetchOCISource(ctx context.Context, spec string) ([]byte, error) {
+	reference, path := splitSourcePath(spec)
+	if err := validateOCIReference(reference); err != nil {
+		return nil, err
+	}
+	return serverConfig(ctx).ORAS.pull(ctx, reference, path)
+}
+
+// readSourceFile reads path relative to dir, refusing paths that escape it,
+// including through symlinks within the source.
+func readSourceFile(dir, path string) ([]byte, error) {
+	target := filepath.Join(dir, filepath.FromSlash(path))
+	if !withinDir(dir, target) {
+		return nil, fmt.Errorf("source path %q escapes the source", path)
+	}
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := filepath.EvalSymlinks(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if !withinDir(root, resolved) {
+		return nil, fmt.Errorf("source path %q escapes the source", path)
+	}
+	content, err := os.ReadFile(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return content, nil
+}
+
+// withinDir reports whether target lies within dir.
+func withinDir(dir, target string) bool {
+	rel, err := filepath.Rel(dir, target)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func runSourceCommand(ctx context.Context, name string, args ...string) error {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%s: %w", msg, err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	federationAccess = `metadata:
+  id: ORG
+  description: Access controls
+title: Access
+families:
+  - id: AC
+    title: Access Control
+controls:
+  - id: AC.01
+    family: AC
+    title: Least privilege
+`
+	federationLogging = `metadata:
+  id: ORG-LOG
+  description: Logging controls
+title: Logging
+families:
+  - id: LG
+    title: Logging
+controls:
+  - id: LG.01
+    family: LG
+    title: Audit logs
+  - id: AC.01
+    family: AC
+    title: Conflicting copy
+`
+)
+
+// useFederation returns a context serving federated catalogs, whose cache
+// is emptied for the duration of a test.
+func useFederation(t *testing.T, catalogs ...FederatedCatalog) context.Context {
+	t.Helper()
+	t.Cleanup(func() { federationCache = map[string]federatedEntry{} })
+	federationCache = map[string]federatedEntry{}
+	return withTestConfig(func(c *Config) { c.Federation = catalogs })
+}
+
+func TestFederatedCatalog(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "access.yaml", federationAccess)
+	writeTestFile(t, dir, "logging.yaml", federationLogging)
+	ctx := useFederation(t, FederatedCatalog{
+		Name:  "org",
+		Title: "Organization Catalog",
+		Sources: []string{
+			fileURL(filepath.Join(dir, "access.yaml")),
+			fileURL(filepath.Join(dir, "logging.yaml")),
+		},
+	})
+
+	content, err := readArtifactURI(ctx, "gemara://federated/org")
+	require.NoError(t, err, "should resolve federated catalog")
+
+	doc, err := parseArtifact(ctx, string(content))
+	require.NoError(t, err, "merged catalog should be valid YAML")
+	assert.Equal(t, "Organization Catalog", doc["title"], "declared title should win")
+	assert.Equal(t, "ORG", doc["metadata"].(map[string]interface{})["id"], "first metadata should win")
+	assert.Len(t, doc["families"], 2, "families should be combined")
+	assert.Len(t, doc["controls"], 2, "duplicate control should be dropped")
+	assert.Contains(t, string(content), "conflict: controls AC.01", "differing duplicates should be reported")
+
+	// The cache serves the merged catalog after the sources change
+	require.NoError(t, os.Remove(filepath.Join(dir, "logging.yaml")), "should remove source")
+	cached, err := readArtifactURI(ctx, "gemara://federated/org")
+	require.NoError(t, err, "should serve from cache")
+	assert.Equal(t, content, cached, "cached content should match")
+
+	_, err = readArtifactURI(ctx, "gemara://federated/missing")
+	assert.Error(t, err, "unknown catalogs should not resolve")
+}
+
+func TestFederatedCatalogGitSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	writeTestFile(t, repo, "catalogs/access.yaml", federationAccess)
+	outside := filepath.Join(t.TempDir(), "secret.yaml")
+	require.NoError(t, os.WriteFile(outside, []byte("secret: true\n"), 0o600))
+	require.NoError(t, os.Symlink(outside, filepath.Join(repo, "catalogs", "link.yaml")))
+	for _, args := range [][]string{
+		{"init", "--quiet", "--initial-branch", "main"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "catalog"},
+		{"tag", "v1"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %v: %s", args, out)
+	}
+
+	content, err := fetchCatalogSource(context.Background(), "git::file://"+repo+"//catalogs/access.yaml?ref=v1")
+	require.NoError(t, err, "should read file from git")
+	assert.Equal(t, federationAccess, string(content))
+
+	_, err = fetchCatalogSource(context.Background(), "git::file://"+repo+"//../escape.yaml")
+	assert.ErrorContains(t, err, "escapes the source")
+
+	_, err = fetchCatalogSource(context.Background(), "git::file://"+repo+"//catalogs/link.yaml")
+	assert.ErrorContains(t, err, "escapes the source", "should not follow symlinks out of the clone")
+
+	// References come from tool input, so they may not clone local repositories
+	for _, ref := range []string{
+		"git::file://" + repo + "//catalogs/access.yaml",
+		"git::" + repo + "//catalogs/access.yaml",
+		"git::ext::sh -c touch% /tmp/pwned//catalogs/access.yaml",
+	} {
+		_, err = fetchReference(context.Background(), ref)
+		assert.ErrorContains(t, err, "must be an https:// URL", ref)
+	}
+}
+
+func TestSplitSourcePath(t *testing.T) {
+	tests := []struct {
+		spec, location, path string
+	}{
+		{"https://github.com/org/repo.git//catalogs/a.yaml", "https://github.com/org/repo.git", "catalogs/a.yaml"},
+		{"ghcr.io/org/catalog:v1//catalog.yaml", "ghcr.io/org/catalog:v1", "catalog.yaml"},
+		{"ghcr.io/org/catalog:v1", "ghcr.io/org/catalog:v1", ""},
+	}
+	for _, tt := range tests {
+		location, path := splitSourcePath(tt.spec)
+		assert.Equal(t, tt.location, location, tt.spec)
+		assert.Equal(t, tt.path, path, tt.spec)
+	}
+}
+
+func TestLoadFederation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "valid", content: "catalogs:\n  - name: org\n    sources: [https://example.com/a.yaml]\n"},
+		{name: "missing name", content: "catalogs:\n  - sources: [https://example.com/a.yaml]\n", wantErr: "missing a name"},
+		{name: "no sources", content: "catalogs:\n  - name: org\n", wantErr: "has no sources"},
+		{name: "duplicate", content: "catalogs:\n  - name: org\n    sources: [a]\n  - name: org\n    sources: [b]\n", wantErr: "declared twice"},
+		{name: "bad name", content: "catalogs:\n  - name: a/b\n    sources: [a]\n", wantErr: "must not contain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFile(t, dir, "federation.yaml", tt.content)
+			catalogs, err := LoadFederation(filepath.Join(dir, "federation.yaml"))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err, "should load federation")
+			assert.Len(t, catalogs, 1)
+		})
+	}
+}
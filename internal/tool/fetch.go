@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// fetchURL retrieves the content at the given http(s) or file URL.
+func fetchURL(ctx context.Context, rawURL string) ([]byte, error) {
+	body, _, err := fetchURLWithETag(ctx, rawURL)
+	return body, err
+}
+
+// fetchURLWithETag retrieves the content at the given http(s) or file URL along
+// with the ETag the server reported, if any.
+func fetchURLWithETag(ctx context.Context, rawURL string) ([]byte, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+
+	if u.Scheme == "file" {
+		body, err := os.ReadFile(u.Path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read %s: %w", u.Path, err)
+		}
+		return body, "", nil
+	}
+
+	body, etag, err := fetchHTTP(ctx, rawURL)
+	if err != nil {
+		emitUpstreamFailure(ctx, rawURL, err)
+		// A persisted copy keeps a freshly started server working while upstream is down
+		if persisted, ok := loadPersistedFetch(ctx, rawURL); ok {
+			emitStaleCache(ctx, "persisted copy", rawURL, err)
+			return persisted.Body, persisted.ETag, nil
+		}
+		return nil, "", err
+	}
+	persistFetch(ctx, rawURL, body, etag)
+	return body, etag, nil
+}
+
+// fetchHTTP performs a GET request for rawURL and returns the response body and ETag.
+func fetchHTTP(ctx context.Context, rawURL string) ([]byte, string, error) {
+	client := newHTTPClient()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	return body, resp.Header.Get("ETag"), nil
+}
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolFilter selects which tools of a mode are registered. Entries are tool
+// names or patterns such as "generate_*". With an allow list only the tools it
+// matches are registered; tools the deny list matches never are. The zero
+// value registers every tool.
+type ToolFilter struct {
+	Allow []string
+	Deny  []string
+}
+
+// NewToolFilter returns a filter over tools, rejecting entries that match
+// none of them, so that a misspelled name does not silently expose a tool
+// that was meant to be denied, and filters that leave no tool registered.
+func NewToolFilter(allow, deny []string, tools []*mcp.Tool) (ToolFilter, error) {
+	f := ToolFilter{Allow: allow, Deny: deny}
+	for _, list := range []struct {
+		name    string
+		entries []string
+	}{{"allow", allow}, {"deny", deny}} {
+		for _, entry := range list.entries {
+			if _, err := path.Match(entry, ""); err != nil {
+				return ToolFilter{}, fmt.Errorf("tools %s: invalid pattern %q: %w", list.name, entry, err)
+			}
+			if !matchesAnyTool(entry, tools) {
+				return ToolFilter{}, fmt.Errorf("tools %s: %q matches no tool (available: %s)", list.name, entry, strings.Join(toolNames(tools), ", "))
+			}
+		}
+	}
+	if len(f.apply(tools)) == 0 {
+		return ToolFilter{}, fmt.Errorf("tools allow and deny lists leave no tool registered")
+	}
+	return f, nil
+}
+
+// Allows reports whether the filter registers the named tool.
+func (f ToolFilter) Allows(name string) bool {
+	if len(f.Allow) > 0 && !matchesAnyPattern(name, f.Allow) {
+		return false
+	}
+	return !matchesAnyPattern(name, f.Deny)
+}
+
+// apply returns the tools the filter registers.
+func (f ToolFilter) apply(tools []*mcp.Tool) []*mcp.Tool {
+	allowed := make([]*mcp.Tool, 0, len(tools))
+	for _, t := range tools {
+		if f.Allows(t.Name) {
+			allowed = append(allowed, t)
+		}
+	}
+	return allowed
+}
+
+func matchesAnyPattern(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAnyTool(pattern string, tools []*mcp.Tool) bool {
+	for _, t := range tools {
+		if ok, _ := path.Match(pattern, t.Name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func toolNames(tools []*mcp.Tool) []string {
+	names := make([]string, 0, len(tools))
+	for _, t := range tools {
+		names = append(names, t.Name)
+	}
+	return names
+}
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewToolFilter(t *testing.T) {
+	tools := AdvisoryMode{}.Tools()
+
+	tests := []struct {
+		name      string
+		allow     []string
+		deny      []string
+		wantErr   string
+		wantTools []string
+	}{
+		{
+			name:      "allow patterns",
+			allow:     []string{"get_lexicon", "validate_*"},
+			wantTools: []string{MetadataGetLexicon.Name, MetadataValidateGemaraArtifact.Name},
+		},
+		{
+			name:      "deny wins over allow",
+			allow:     []string{"*_staged_*"},
+			deny:      []string{"list_*"},
+			wantTools: []string{MetadataGetStagedArtifact.Name},
+		},
+		{
+			name:    "misspelled deny",
+			deny:    []string{"push_artefact_oci"},
+			wantErr: `tools deny: "push_artefact_oci" matches no tool`,
+		},
+		{
+			name:    "invalid pattern",
+			allow:   []string{"get_["},
+			wantErr: "invalid pattern",
+		},
+		{
+			name:    "nothing left",
+			allow:   []string{"get_lexicon"},
+			deny:    []string{"get_*"},
+			wantErr: "leave no tool registered",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewToolFilter(tt.allow, tt.deny, tools)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.wantTools, toolNames(AdvisoryMode{Filter: filter}.Tools()))
+		})
+	}
+}
+
+func TestAdvisoryModeFilter(t *testing.T) {
+	ctx := context.Background()
+	mode := AdvisoryMode{Filter: ToolFilter{Deny: []string{"push_artifact_oci", "pull_artifact_oci"}}}
+	assert.Len(t, mode.Tools(), len(AdvisoryMode{}.Tools())-2)
+	assert.NotContains(t, mode.Instructions(), "push_artifact_oci", "denied tools should not be described")
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server"}, nil)
+	mode.Register(server)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err, "server should connect")
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, clientTransport, nil)
+	require.NoError(t, err, "client should connect")
+	t.Cleanup(func() { _ = session.Close() })
+
+	listed, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	var names []string
+	for _, listedTool := range listed.Tools {
+		names = append(names, listedTool.Name)
+	}
+	assert.ElementsMatch(t, toolNames(mode.Tools()), names, "only allowed tools should be registered")
+}
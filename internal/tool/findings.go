@@ -0,0 +1,303 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	severityCritical = "critical"
+	severityHigh     = "high"
+	severityMedium   = "medium"
+	severityLow      = "low"
+)
+
+// DefaultFindingSLA returns the default remediation window per severity.
+func DefaultFindingSLA() map[string]time.Duration {
+	return map[string]time.Duration{
+		severityCritical: 7 * 24 * time.Hour,
+		severityHigh:     30 * 24 * time.Hour,
+		severityMedium:   90 * 24 * time.Hour,
+		severityLow:      180 * 24 * time.Hour,
+	}
+}
+
+// ParseFindingSLA overlays severity=duration pairs on the default SLA policy.
+// Durations accept a day suffix (e.g., 14d) in addition to Go duration syntax.
+func ParseFindingSLA(spec map[string]string) (map[string]time.Duration, error) {
+	sla := DefaultFindingSLA()
+	for severity, value := range spec {
+		d, err := parseSLADuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SLA for %s: %w", severity, err)
+		}
+		sla[strings.ToLower(severity)] = d
+	}
+	return sla, nil
+}
+
+func parseSLADuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+// resultSeverity is the severity of a finding that does not declare one.
+var resultSeverity = map[string]string{
+	"Failed":       severityHigh,
+	"Needs Review": severityMedium,
+	"Unknown":      severityLow,
+}
+
+// MetadataListOverdueFindings describes the ListOverdueFindings tool.
+var MetadataListOverdueFindings = &mcp.Tool{
+	Name: "list_overdue_findings",
+	Description: "List findings (failed or unresolved assessments in evaluation logs) whose remediation due date, " +
+		"derived from the configured per-severity SLA policy, has passed. Use limit or max_output_bytes to page " +
+		"through them with next_cursor.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"artifacts"},
+		"properties": withPagination(map[string]interface{}{
+			"artifacts": artifactInputSchema,
+			"as_of": map[string]interface{}{
+				"type":        "string",
+				"description": "Date to evaluate due dates against (RFC 3339 or YYYY-MM-DD; default: now)",
+			},
+		}, "overdue findings"),
+	},
+}
+
+// InputListOverdueFindings is the input for the ListOverdueFindings tool.
+type InputListOverdueFindings struct {
+	PageInput
+	Artifacts []ArtifactInput `json:"artifacts"`
+	AsOf      string          `json:"as_of,omitempty"`
+}
+
+// Finding is a failed or unresolved assessment with its remediation deadline.
+type Finding struct {
+	Artifact    string `json:"artifact"`
+	Path        string `json:"path"`
+	Catalog     string `json:"catalog,omitempty"`
+	Control     string `json:"control,omitempty"`
+	Requirement string `json:"requirement,omitempty"`
+	Result      string `json:"result"`
+	Severity    string `json:"severity"`
+	Detected    string `json:"detected,omitempty"`
+	Due         string `json:"due,omitempty"`
+	DaysOverdue int    `json:"days_overdue,omitempty"`
+}
+
+// OutputListOverdueFindings is the output for the ListOverdueFindings tool.
+type OutputListOverdueFindings struct {
+	AsOf              string         `json:"as_of"`
+	Overdue           []Finding      `json:"overdue"`
+	OverdueBySeverity map[string]int `json:"overdue_by_severity"`
+	TotalFindings     int            `json:"total_findings"`
+	Undated           int            `json:"undated"`
+	// Page describes which overdue findings were returned.
+	Page    *PageInfo `json:"page,omitempty"`
+	Message string    `json:"message"`
+}
+
+// ListOverdueFindings reports findings that are past their SLA due date.
+func ListOverdueFindings(ctx context.Context, _ *mcp.CallToolRequest, input InputListOverdueFindings) (*mcp.CallToolResult, OutputListOverdueFindings, error) {
+	if len(input.Artifacts) == 0 {
+		return nil, OutputListOverdueFindings{}, fmt.Errorf("artifacts is required")
+	}
+
+	asOf := time.Now().UTC()
+	if input.AsOf != "" {
+		t, ok := parseFindingTime(input.AsOf)
+		if !ok {
+			return nil, OutputListOverdueFindings{}, fmt.Errorf("invalid as_of %q: expected RFC 3339 or YYYY-MM-DD", input.AsOf)
+		}
+		asOf = t
+	}
+
+	findings, err := collectFindings(ctx, input.Artifacts, serverConfig(ctx).FindingSLA)
+	if err != nil {
+		return nil, OutputListOverdueFindings{}, err
+	}
+
+	output := OutputListOverdueFindings{
+		AsOf:              asOf.Format(time.RFC3339),
+		Overdue:           overdueFindings(findings, asOf),
+		OverdueBySeverity: map[string]int{},
+		TotalFindings:     len(findings),
+	}
+	for _, f := range findings {
+		if f.Due == "" {
+			output.Undated++
+		}
+	}
+	for _, f := range output.Overdue {
+		output.OverdueBySeverity[f.Severity]++
+	}
+
+	output.Message = fmt.Sprintf("%d of %d finding(s) overdue as of %s", len(output.Overdue), len(findings), asOf.Format(time.DateOnly))
+	if output.Undated > 0 {
+		output.Message += fmt.Sprintf("; %d finding(s) have no detection date or SLA", output.Undated)
+	}
+
+	page, info, err := paginate(output.Overdue, input.PageInput)
+	if err != nil {
+		return nil, OutputListOverdueFindings{}, err
+	}
+	output.Overdue, output.Page = page, &info
+	return nil, output, nil
+}
+
+// overdueFindings returns the findings whose due date is before asOf, with
+// the days they are overdue, most overdue first.
+func overdueFindings(findings []Finding, asOf time.Time) []Finding {
+	overdue := []Finding{}
+	for _, f := range findings {
+		due, err := time.Parse(time.RFC3339, f.Due)
+		if err != nil || !asOf.After(due) {
+			continue
+		}
+		f.DaysOverdue = int(asOf.Sub(due).Hours() / 24)
+		overdue = append(overdue, f)
+	}
+	sort.SliceStable(overdue, func(i, j int) bool {
+		return overdue[i].DaysOverdue > overdue[j].DaysOverdue
+	})
+	return overdue
+}
+
+// collectFindings extracts findings from the evaluation logs among artifacts and
+// assigns due dates from the SLA policy.
+func collectFindings(ctx context.Context, artifacts []ArtifactInput, sla map[string]time.Duration) ([]Finding, error) {
+	var findings []Finding
+	for i, a := range artifacts {
+		name := artifactName(a, i)
+		doc, err := parseArtifact(ctx, a.Content)
+		if err != nil {
+			return nil, &artifactError{Name: name, Err: err}
+		}
+		if artifactKind(doc) != "EvaluationLog" {
+			continue
+		}
+
+		evaluations, _ := doc["evaluations"].([]interface{})
+		for ei, e := range evaluations {
+			evaluation, ok := e.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			path := fmt.Sprintf("$.evaluations[%d]", ei)
+			mapping, _ := evaluation["control"].(map[string]interface{})
+			catalog, control := stringField(mapping, "reference-id"), mappingEntryID(mapping)
+
+			logs, _ := evaluation["assessment-logs"].([]interface{})
+			logFindings := 0
+			for li, l := range logs {
+				assessment, ok := l.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				f, ok := newFinding(name, fmt.Sprintf("%s[%d]", childPath(path, "assessment-logs"), li), assessment, evaluation, sla)
+				if !ok {
+					continue
+				}
+				f.Catalog, f.Control = catalog, control
+				f.Requirement = mappingEntryID(assessment["requirement"])
+				findings = append(findings, f)
+				logFindings++
+			}
+
+			// An unresolved evaluation without unresolved logs is a finding in its own right
+			if logFindings == 0 {
+				if f, ok := newFinding(name, path, evaluation, nil, sla); ok {
+					f.Catalog, f.Control = catalog, control
+					findings = append(findings, f)
+				}
+			}
+		}
+	}
+	return findings, nil
+}
+
+// newFinding builds a finding from an unresolved evaluation or assessment log.
+// Severity and detection time fall back to the enclosing evaluation.
+func newFinding(artifact, path string, node, parent map[string]interface{}, sla map[string]time.Duration) (Finding, bool) {
+	result, _ := node["result"].(string)
+	defaultSeverity, unresolved := resultSeverity[result]
+	if !unresolved {
+		return Finding{}, false
+	}
+
+	f := Finding{Artifact: artifact, Path: path, Result: result, Severity: defaultSeverity}
+	for _, n := range []map[string]interface{}{node, parent} {
+		if s, ok := n["severity"].(string); ok && s != "" {
+			f.Severity = strings.ToLower(s)
+			break
+		}
+	}
+
+	for _, n := range []map[string]interface{}{node, parent} {
+		detected, ok := findingTime(n)
+		if !ok {
+			continue
+		}
+		f.Detected = detected.Format(time.RFC3339)
+		if window, ok := sla[f.Severity]; ok {
+			f.Due = detected.Add(window).Format(time.RFC3339)
+		}
+		break
+	}
+	return f, true
+}
+
+// findingTime returns when an assessment concluded, preferring its end time.
+func findingTime(node map[string]interface{}) (time.Time, bool) {
+	for _, key := range []string{"end", "start"} {
+		switch v := node[key].(type) {
+		case time.Time:
+			return v.UTC(), true
+		case string:
+			if t, ok := parseFindingTime(v); ok {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func parseFindingTime(s string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// mappingEntryID returns the entry-id of an entry mapping.
+func mappingEntryID(v interface{}) string {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	id, _ := m["entry-id"].(string)
+	return id
+}
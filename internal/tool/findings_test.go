@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const findingsTestLog = `metadata:
+  id: EVAL-1
+  description: Test evaluation
+  author: {id: scanner, name: Scanner, type: Software}
+evaluations:
+  - name: Encryption
+    result: Failed
+    control: {reference-id: CAT, entry-id: C01}
+    assessment-logs:
+      - requirement: {reference-id: CAT, entry-id: C01.TR01}
+        description: Unencrypted bucket
+        result: Failed
+        start: "2025-01-01T00:00:00Z"
+      - requirement: {reference-id: CAT, entry-id: C01.TR02}
+        description: Key rotation
+        result: Passed
+        start: "2025-01-01T00:00:00Z"
+      - requirement: {reference-id: CAT, entry-id: C01.TR03}
+        description: Critical exposure
+        result: Needs Review
+        severity: Critical
+        end: "2025-03-01"
+  - name: Regions
+    result: Failed
+    end: "2025-03-10T00:00:00Z"
+    control: {reference-id: CAT, entry-id: C02}
+    assessment-logs: []
+  - name: Logging
+    result: Unknown
+    control: {reference-id: CAT, entry-id: C03}
+    assessment-logs: []
+`
+
+func TestListOverdueFindings(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          InputListOverdueFindings
+		wantErr        string
+		validateOutput func(t *testing.T, output OutputListOverdueFindings)
+	}{
+		{
+			name:    "missing artifacts",
+			input:   InputListOverdueFindings{},
+			wantErr: "artifacts is required",
+		},
+		{
+			name:    "invalid as_of",
+			input:   InputListOverdueFindings{Artifacts: []ArtifactInput{{Content: findingsTestLog}}, AsOf: "soon"},
+			wantErr: "invalid as_of",
+		},
+		{
+			name:  "overdue findings",
+			input: InputListOverdueFindings{Artifacts: []ArtifactInput{{Name: "eval.yaml", Content: findingsTestLog}}, AsOf: "2025-03-20"},
+			validateOutput: func(t *testing.T, output OutputListOverdueFindings) {
+				assert.Equal(t, 4, output.TotalFindings, "failed, needs review, and unresolved evaluations are findings")
+				assert.Equal(t, 1, output.Undated, "the evaluation without dates cannot be scheduled")
+				require.Len(t, output.Overdue, 2, "high finding from January and critical finding from March are overdue")
+
+				assert.Equal(t, "C01.TR01", output.Overdue[0].Requirement, "most overdue first")
+				assert.Equal(t, severityHigh, output.Overdue[0].Severity, "failed results default to high")
+				assert.Equal(t, "2025-01-31T00:00:00Z", output.Overdue[0].Due, "due date follows the high SLA")
+				assert.Equal(t, 48, output.Overdue[0].DaysOverdue)
+				assert.Equal(t, "$.evaluations[0].assessment-logs[0]", output.Overdue[0].Path)
+
+				assert.Equal(t, "C01.TR03", output.Overdue[1].Requirement)
+				assert.Equal(t, severityCritical, output.Overdue[1].Severity, "declared severity wins")
+				assert.Equal(t, 12, output.Overdue[1].DaysOverdue)
+
+				assert.Equal(t, map[string]int{severityHigh: 1, severityCritical: 1}, output.OverdueBySeverity)
+			},
+		},
+		{
+			name: "page of overdue findings",
+			input: InputListOverdueFindings{
+				Artifacts: []ArtifactInput{{Content: findingsTestLog}},
+				AsOf:      "2025-03-20",
+				PageInput: PageInput{Limit: 1},
+			},
+			validateOutput: func(t *testing.T, output OutputListOverdueFindings) {
+				require.Len(t, output.Overdue, 1)
+				assert.Equal(t, "C01.TR01", output.Overdue[0].Requirement, "most overdue first")
+				assert.Equal(t, PageInfo{Returned: 1, Total: 2, NextCursor: encodeCursor(1)}, *output.Page)
+				assert.Equal(t, map[string]int{severityHigh: 1, severityCritical: 1}, output.OverdueBySeverity, "counts cover every page")
+			},
+		},
+		{
+			name:  "nothing overdue yet",
+			input: InputListOverdueFindings{Artifacts: []ArtifactInput{{Content: findingsTestLog}}, AsOf: "2025-01-15T00:00:00Z"},
+			validateOutput: func(t *testing.T, output OutputListOverdueFindings) {
+				assert.Empty(t, output.Overdue, "no SLA has elapsed")
+			},
+		},
+		{
+			name:  "non-evaluation artifacts are ignored",
+			input: InputListOverdueFindings{Artifacts: []ArtifactInput{{Content: readExample(t, "ControlCatalog", 1)}}},
+			validateOutput: func(t *testing.T, output OutputListOverdueFindings) {
+				assert.Zero(t, output.TotalFindings)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := ListOverdueFindings(context.Background(), nil, tt.input)
+			if tt.wantErr != "" {
+				require.Error(t, err, "should return error")
+				assert.Contains(t, err.Error(), tt.wantErr, "error should contain expected message")
+				return
+			}
+			require.NoError(t, err, "should not return error")
+			tt.validateOutput(t, output)
+		})
+	}
+}
+
+func TestParseFindingSLA(t *testing.T) {
+	sla, err := ParseFindingSLA(map[string]string{"High": "14d", "info": "12h"})
+	require.NoError(t, err, "should parse SLA")
+	assert.Equal(t, 14*24*time.Hour, sla[severityHigh], "override should apply")
+	assert.Equal(t, 12*time.Hour, sla["info"], "new severities can be added")
+	assert.Equal(t, 7*24*time.Hour, sla[severityCritical], "defaults should remain")
+
+	_, err = ParseFindingSLA(map[string]string{"high": "soon"})
+	assert.ErrorContains(t, err, "invalid SLA for high")
+}
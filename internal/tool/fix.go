@@ -0,0 +1,381 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	fixAddDefault    = "add_default"
+	fixNormalizeEnum = "normalize_enum"
+	fixReorderKeys   = "reorder_keys"
+	fixQuoteScalar   = "quote_scalar"
+)
+
+// ambiguousScalars are plain scalars that YAML 1.1 parsers read as booleans or null.
+var ambiguousScalars = map[string]bool{
+	"y": true, "yes": true, "n": true, "no": true, "on": true, "off": true,
+	"true": true, "false": true, "null": true, "~": true,
+}
+
+// MetadataFixGemaraArtifact describes the FixGemaraArtifact tool.
+var MetadataFixGemaraArtifact = &mcp.Tool{
+	Name: "fix_gemara_artifact",
+	Description: "Apply safe automatic repairs to a Gemara artifact (add missing required scalar defaults, normalize enum casing, " +
+		"reorder keys to schema order, quote ambiguous YAML scalars) and return the fixed artifact with a change log.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"artifact_content"},
+		"properties": map[string]interface{}{
+			"artifact_content": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML content of the Gemara artifact to fix",
+			},
+			"definition": map[string]interface{}{
+				"type":        "string",
+				"description": "CUE definition name of the artifact (e.g., '#ControlCatalog'); detected from the content when omitted",
+			},
+		},
+	},
+}
+
+// InputFixGemaraArtifact is the input for the FixGemaraArtifact tool.
+type InputFixGemaraArtifact struct {
+	ArtifactContent string `json:"artifact_content"`
+	Definition      string `json:"definition,omitempty"`
+}
+
+// FixChange records a single repair applied to an artifact.
+type FixChange struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// OutputFixGemaraArtifact is the output for the FixGemaraArtifact tool.
+type OutputFixGemaraArtifact struct {
+	Definition   string      `json:"definition"`
+	FixedContent string      `json:"fixed_content"`
+	Changes      []FixChange `json:"changes"`
+	Valid        bool        `json:"valid"`
+	Errors       []string    `json:"errors,omitempty"`
+	Message      string      `json:"message"`
+}
+
+// FixGemaraArtifact repairs common validation failures using the schema as a guide.
+func FixGemaraArtifact(ctx context.Context, _ *mcp.CallToolRequest, input InputFixGemaraArtifact) (*mcp.CallToolResult, OutputFixGemaraArtifact, error) {
+	if input.ArtifactContent == "" {
+		return nil, OutputFixGemaraArtifact{}, fmt.Errorf("artifact_content is required")
+	}
+
+	doc, err := parseArtifact(ctx, input.ArtifactContent)
+	if err != nil {
+		return nil, OutputFixGemaraArtifact{}, err
+	}
+
+	definition := input.Definition
+	if definition == "" {
+		definition = artifactKind(doc)
+		if definition == "" {
+			return nil, OutputFixGemaraArtifact{}, fmt.Errorf("unable to determine the artifact definition; supply definition")
+		}
+	}
+	definition = normalizeDefinition(definition)
+
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputFixGemaraArtifact{}, err
+	}
+	entrypoint, err := schema.lookupDefinition(definition)
+	if err != nil {
+		return nil, OutputFixGemaraArtifact{}, err
+	}
+
+	var tree interface{}
+	if err := yaml.UnmarshalWithOptions([]byte(input.ArtifactContent), &tree, yaml.UseOrderedMap()); err != nil {
+		return nil, OutputFixGemaraArtifact{}, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	file, err := parser.ParseBytes([]byte(input.ArtifactContent), 0)
+	if err != nil {
+		return nil, OutputFixGemaraArtifact{}, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	fixer := &artifactFixer{file: file, changes: []FixChange{}}
+	fixed := fixer.fixValue(tree, entrypoint, "$")
+
+	out, err := yaml.MarshalWithOptions(fixed, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return nil, OutputFixGemaraArtifact{}, fmt.Errorf("failed to encode fixed artifact: %w", err)
+	}
+
+	validation, err := validateAgainstSchema(schema, definition, string(out))
+	if err != nil {
+		return nil, OutputFixGemaraArtifact{}, err
+	}
+
+	output := OutputFixGemaraArtifact{
+		Definition:   definition,
+		FixedContent: string(out),
+		Changes:      fixer.changes,
+		Valid:        validation.Valid,
+		Errors:       validation.Errors,
+		Message:      fmt.Sprintf("Applied %d change(s); %s", len(fixer.changes), strings.ToLower(validation.Message)),
+	}
+	return nil, output, nil
+}
+
+// artifactFixer walks an ordered YAML tree alongside its schema and records repairs.
+type artifactFixer struct {
+	file    *ast.File
+	changes []FixChange
+}
+
+func (f *artifactFixer) record(path, action, before, after string) {
+	f.changes = append(f.changes, FixChange{Path: path, Action: action, Before: before, After: after})
+}
+
+func (f *artifactFixer) fixValue(value interface{}, schema cue.Value, path string) interface{} {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		if schema.IncompleteKind()&cue.StructKind == 0 {
+			return v
+		}
+		return f.fixStruct(v, schema, path)
+	case []interface{}:
+		elem := schema.LookupPath(cue.MakePath(cue.AnyIndex))
+		if !elem.Exists() {
+			return v
+		}
+		for i := range v {
+			v[i] = f.fixValue(v[i], elem, fmt.Sprintf("%s[%d]", path, i))
+		}
+		return v
+	default:
+		return f.fixScalar(v, schema, path)
+	}
+}
+
+// schemaField is a regular field declared by a struct schema.
+type schemaField struct {
+	name     string
+	value    cue.Value
+	required bool
+}
+
+func schemaFields(schema cue.Value) []schemaField {
+	iter, err := schema.Fields(cue.Optional(true))
+	if err != nil {
+		return nil
+	}
+	var fields []schemaField
+	for iter.Next() {
+		sel := iter.Selector()
+		if sel.LabelType() != cue.StringLabel {
+			continue
+		}
+		fields = append(fields, schemaField{
+			name:     sel.Unquoted(),
+			value:    iter.Value(),
+			required: !iter.IsOptional(),
+		})
+	}
+	return fields
+}
+
+func (f *artifactFixer) fixStruct(m yaml.MapSlice, schema cue.Value, path string) yaml.MapSlice {
+	existing := make(map[string]int, len(m))
+	for i, item := range m {
+		if key, ok := item.Key.(string); ok {
+			existing[key] = i
+		}
+	}
+
+	fields := schemaFields(schema)
+	ordered := make(yaml.MapSlice, 0, len(m)+len(fields))
+	used := make(map[int]bool, len(m))
+	for _, field := range fields {
+		fieldPath := childPath(path, field.name)
+		if i, ok := existing[field.name]; ok {
+			used[i] = true
+			ordered = append(ordered, yaml.MapItem{Key: field.name, Value: f.fixValue(m[i].Value, field.value, fieldPath)})
+			continue
+		}
+		if !field.required {
+			continue
+		}
+		if def, ok := scalarDefault(field.value); ok {
+			ordered = append(ordered, yaml.MapItem{Key: field.name, Value: def})
+			f.record(fieldPath, fixAddDefault, "", fmt.Sprintf("%v", def))
+		}
+	}
+
+	// Keep fields unknown to the schema in their original order
+	for i, item := range m {
+		if !used[i] {
+			ordered = append(ordered, item)
+		}
+	}
+
+	if !sameKeyOrder(m, ordered) {
+		f.record(path, fixReorderKeys, keyList(m), keyList(ordered))
+	}
+	return ordered
+}
+
+func (f *artifactFixer) fixScalar(value interface{}, schema cue.Value, path string) interface{} {
+	if schema.IncompleteKind() != cue.StringKind {
+		return value
+	}
+
+	s, isString := value.(string)
+	if !isString {
+		if value == nil {
+			return value
+		}
+		// Numbers and booleans where a string is expected are re-read from source text
+		raw := f.rawScalar(path)
+		if raw == "" {
+			raw = fmt.Sprint(value)
+		}
+		if !accepts(schema, raw) {
+			return value
+		}
+		f.record(path, fixQuoteScalar, fmt.Sprint(value), raw)
+		return raw
+	}
+
+	if !accepts(schema, s) {
+		for _, candidate := range enumValues(schema) {
+			if strings.EqualFold(candidate, s) {
+				f.record(path, fixNormalizeEnum, s, candidate)
+				return candidate
+			}
+		}
+	}
+
+	if f.isPlainScalar(path) && ambiguousScalars[strings.ToLower(s)] {
+		f.record(path, fixQuoteScalar, s, fmt.Sprintf("%q", s))
+	}
+	return s
+}
+
+// rawScalar returns the source text of the scalar at path.
+func (f *artifactFixer) rawScalar(path string) string {
+	if tok := f.token(path); tok != nil {
+		return tok.Value
+	}
+	return ""
+}
+
+// isPlainScalar reports whether the scalar at path is unquoted in the source.
+func (f *artifactFixer) isPlainScalar(path string) bool {
+	tok := f.token(path)
+	return tok != nil && tok.Type != token.DoubleQuoteType && tok.Type != token.SingleQuoteType
+}
+
+func (f *artifactFixer) token(path string) *token.Token {
+	p, err := yaml.PathString(path)
+	if err != nil {
+		return nil
+	}
+	node, err := p.FilterFile(f.file)
+	if err != nil || node == nil {
+		return nil
+	}
+	return node.GetToken()
+}
+
+// scalarDefault returns the schema default for a scalar field, or the zero value of
+// its kind when that zero value satisfies the schema.
+func scalarDefault(v cue.Value) (interface{}, bool) {
+	if d, ok := v.Default(); ok && d.IsConcrete() {
+		var out interface{}
+		if err := d.Decode(&out); err == nil {
+			switch out.(type) {
+			case string, bool, int, int64, float64:
+				return out, true
+			}
+		}
+	}
+
+	var zero interface{}
+	switch v.IncompleteKind() {
+	case cue.StringKind:
+		zero = ""
+	case cue.IntKind, cue.NumberKind, cue.FloatKind:
+		zero = 0
+	case cue.BoolKind:
+		zero = false
+	default:
+		return nil, false
+	}
+	if !accepts(v, zero) {
+		return nil, false
+	}
+	return zero, true
+}
+
+// accepts reports whether a Go value satisfies the schema.
+func accepts(schema cue.Value, value interface{}) bool {
+	return schema.Unify(schema.Context().Encode(value)).Validate(cue.Concrete(true)) == nil
+}
+
+// enumValues returns the string literals of a disjunction schema, following references.
+func enumValues(schema cue.Value) []string {
+	op, args := cue.Dereference(schema).Expr()
+	switch op {
+	case cue.OrOp:
+		var values []string
+		for _, arg := range args {
+			if s, err := arg.String(); err == nil {
+				values = append(values, s)
+			}
+		}
+		return values
+	case cue.AndOp:
+		for _, arg := range args {
+			if values := enumValues(arg); len(values) > 0 {
+				return values
+			}
+		}
+	}
+	return nil
+}
+
+// sameKeyOrder reports whether the original keys keep their relative order, ignoring added keys.
+func sameKeyOrder(original, ordered yaml.MapSlice) bool {
+	present := make(map[interface{}]bool, len(original))
+	for _, item := range original {
+		present[item.Key] = true
+	}
+	i := 0
+	for _, item := range ordered {
+		if !present[item.Key] {
+			continue
+		}
+		if original[i].Key != item.Key {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+func keyList(m yaml.MapSlice) string {
+	keys := make([]string, 0, len(m))
+	for _, item := range m {
+		keys = append(keys, fmt.Sprint(item.Key))
+	}
+	return strings.Join(keys, ", ")
+}
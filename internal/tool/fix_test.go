@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixTestEvaluationLog = `evaluations:
+  - name: Encrypt Data at Rest
+    result: passed
+    control:
+      entry-id: OBJ.C01
+      reference-id: EXAMPLE-OBJ
+    assessment-logs:
+      - requirement:
+          reference-id: EXAMPLE-OBJ
+          entry-id: OBJ.C01.TR01
+        result: Needs review
+        message: yes
+metadata:
+  id: EVAL
+  description: Example evaluation
+  author:
+    id: scanner
+    name: Scanner
+    type: software
+`
+
+func TestFixGemaraArtifact(t *testing.T) {
+	useTestSchema(t)
+
+	tests := []struct {
+		name           string
+		input          InputFixGemaraArtifact
+		wantErr        bool
+		errContains    string
+		validateOutput func(t *testing.T, output OutputFixGemaraArtifact)
+	}{
+		{
+			name:        "missing artifact_content",
+			input:       InputFixGemaraArtifact{},
+			wantErr:     true,
+			errContains: "artifact_content is required",
+		},
+		{
+			name:        "undetectable definition",
+			input:       InputFixGemaraArtifact{ArtifactContent: "kind: ConfigMap\n"},
+			wantErr:     true,
+			errContains: "unable to determine the artifact definition",
+		},
+		{
+			name:  "repairs evaluation log",
+			input: InputFixGemaraArtifact{ArtifactContent: fixTestEvaluationLog},
+			validateOutput: func(t *testing.T, output OutputFixGemaraArtifact) {
+				assert.Equal(t, "#EvaluationLog", output.Definition, "definition should be detected")
+				assert.True(t, output.Valid, "fixed artifact should be valid: %v", output.Errors)
+
+				changes := map[string]FixChange{}
+				for _, c := range output.Changes {
+					changes[c.Action+" "+c.Path] = c
+				}
+
+				assert.Equal(t, "Passed", changes[fixNormalizeEnum+" $.evaluations[0].result"].After)
+				assert.Equal(t, "Needs Review", changes[fixNormalizeEnum+" $.evaluations[0].assessment-logs[0].result"].After)
+				assert.Equal(t, "Software", changes[fixNormalizeEnum+" $.metadata.author.type"].After)
+				assert.Contains(t, changes, fixAddDefault+" $.evaluations[0].assessment-logs[0].description")
+				assert.Contains(t, changes, fixQuoteScalar+" $.evaluations[0].assessment-logs[0].message")
+				assert.Contains(t, changes, fixReorderKeys+" $", "metadata should move first")
+				assert.Contains(t, changes, fixReorderKeys+" $.evaluations[0].control")
+
+				assert.Regexp(t, `^metadata:`, output.FixedContent, "keys should follow schema order")
+				assert.Contains(t, output.FixedContent, `message: "yes"`, "ambiguous scalar should be quoted")
+			},
+		},
+		{
+			name: "numbers become strings where the schema expects text",
+			input: InputFixGemaraArtifact{
+				Definition: "Policy",
+				ArtifactContent: `metadata:
+  id: POL
+  description: Policy
+  version: 1.0
+  author:
+    id: org
+    name: Org
+    type: Human
+title: 2024
+`,
+			},
+			validateOutput: func(t *testing.T, output OutputFixGemaraArtifact) {
+				assert.True(t, output.Valid, "fixed artifact should be valid: %v", output.Errors)
+				assert.Contains(t, output.FixedContent, `version: "1.0"`, "source text should be preserved")
+				assert.Contains(t, output.FixedContent, `title: "2024"`)
+			},
+		},
+		{
+			name:  "valid example is unchanged",
+			input: InputFixGemaraArtifact{ArtifactContent: readExample(t, "ControlCatalog", 1)},
+			validateOutput: func(t *testing.T, output OutputFixGemaraArtifact) {
+				assert.Empty(t, output.Changes, "no repairs should be needed")
+				assert.True(t, output.Valid)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := FixGemaraArtifact(context.Background(), nil, tt.input)
+
+			if tt.wantErr {
+				require.Error(t, err, "should return error")
+				assert.Contains(t, err.Error(), tt.errContains, "error should contain expected message")
+				return
+			}
+
+			require.NoError(t, err, "should not return error")
+			if tt.validateOutput != nil {
+				tt.validateOutput(t, output)
+			}
+		})
+	}
+}
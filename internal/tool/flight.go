@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// sharedCall runs fn once for concurrent callers with the same key, so that a
+// cold cache hit by several sessions causes one upstream fetch rather than one
+// per session. fn runs detached from the cancellation of the caller that
+// started it, bounded by the tool timeout, so that the other callers still get its
+// result; each caller stops waiting when its own context ends. shared reports
+// whether the result was handed to more than one caller.
+func sharedCall[T any](ctx context.Context, group *singleflight.Group, key string, fn func(context.Context) (T, error)) (result T, shared bool, err error) {
+	ch := group.DoChan(key, func() (interface{}, error) {
+		callCtx := context.WithoutCancel(ctx)
+		if timeout := serverConfig(ctx).ToolTimeout; timeout > 0 {
+			var cancel context.CancelFunc
+			callCtx, cancel = context.WithTimeout(callCtx, timeout)
+			defer cancel()
+		}
+		return fn(callCtx)
+	})
+	select {
+	case r := <-ch:
+		if r.Err != nil {
+			return result, r.Shared, r.Err
+		}
+		return r.Val.(T), r.Shared, nil
+	case <-ctx.Done():
+		return result, false, ctx.Err()
+	}
+}
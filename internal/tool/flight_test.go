@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/singleflight"
+)
+
+func TestSharedCall(t *testing.T) {
+	var group singleflight.Group
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		<-release
+		return "result", ctx.Err()
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan string, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, _, err := sharedCall(context.Background(), &group, "key", fn)
+			assert.NoError(t, err)
+			results <- result
+		}()
+	}
+
+	// A caller that gives up does not cancel the call the others wait for
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := sharedCall(cancelled, &group, "key", fn)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Give the callers time to join the call before it completes
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+	for result := range results {
+		assert.Equal(t, "result", result)
+	}
+	assert.Equal(t, int32(1), calls.Load(), "concurrent callers should share a call")
+}
+
+func TestLexiconSharedFetch(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		_, _ = w.Write([]byte("- term: Control\n  definition: A safeguard\n"))
+	}))
+	t.Cleanup(server.Close)
+	lexicon := NewLexiconService([]string{server.URL}, LexiconConflictOverride)
+	ctx := withLexicon(context.Background(), lexicon)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, output, err := GetLexicon(ctx, nil, InputGetLexicon{})
+			assert.NoError(t, err)
+			assert.Len(t, output.Entries, 1)
+		}()
+	}
+	require.Eventually(t, func() bool { return requests.Load() > 0 }, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), requests.Load(), "a cold cache should be fetched once")
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/encoding/json"
+	"cuelang.org/go/encoding/yaml"
+)
+
+// Artifact encodings accepted by validate_gemara_artifact.
+const (
+	formatYAML = "yaml"
+	formatJSON = "json"
+	formatCUE  = "cue"
+)
+
+// artifactFormats lists the accepted artifact encodings.
+var artifactFormats = []string{formatYAML, formatJSON, formatCUE}
+
+// normalizeFormat lower-cases an artifact format, defaulting to YAML, and
+// rejects formats that are not supported.
+func normalizeFormat(format string) (string, error) {
+	if format == "" {
+		return formatYAML, nil
+	}
+	format = strings.ToLower(format)
+	for _, f := range artifactFormats {
+		if format == f {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported artifact_format %q (expected one of %s)", format, strings.Join(artifactFormats, ", "))
+}
+
+// extractArtifact parses artifact content in the given format into a CUE file
+// named artifactFilename, so validation errors carry positions in it.
+func extractArtifact(format, content string) (*ast.File, error) {
+	switch format {
+	case formatJSON:
+		expr, err := json.Extract(artifactFilename, []byte(content))
+		if err != nil {
+			return nil, err
+		}
+		return astutil.ToFile(expr)
+	case formatCUE:
+		return parser.ParseFile(artifactFilename, content)
+	default:
+		return yaml.Extract(artifactFilename, content)
+	}
+}
+
+// buildArtifact parses artifact content in the given format and builds it into
+// a CUE value. Parse errors are reported as such so callers can tell them from
+// build errors.
+func buildArtifact(schema *gemaraSchema, format, content string) (cue.Value, error) {
+	file, err := extractArtifact(format, content)
+	if err != nil {
+		return cue.Value{}, fmt.Errorf("failed to parse %s: %w", strings.ToUpper(format), err)
+	}
+	data := schema.ctx.BuildFile(file)
+	if err := data.Err(); err != nil {
+		return cue.Value{}, fmt.Errorf("failed to build data instance: %w", err)
+	}
+	return data, nil
+}
+
+// decodeArtifact parses artifact content in the given format into the generic
+// map form used to walk artifacts. JSON is a subset of YAML, so only CUE needs
+// to be evaluated first.
+func decodeArtifact(ctx context.Context, schema *gemaraSchema, format, content string) (map[string]interface{}, error) {
+	if format != formatCUE {
+		return parseArtifact(ctx, content)
+	}
+	data, err := buildArtifact(schema, format, content)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := data.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode CUE: %w", err)
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("artifact is empty")
+	}
+	return doc, nil
+}
@@ -0,0 +1,483 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Full-text fields. Every indexed value is searchable as text; the others
+// narrow a query to one kind of value.
+const (
+	fieldText   = "text"
+	fieldTitle  = "title"
+	fieldFamily = "family"
+	fieldStatus = "status"
+	fieldID     = "id"
+	fieldKind   = "kind"
+)
+
+var fullTextFields = []string{fieldText, fieldTitle, fieldFamily, fieldStatus, fieldID, fieldKind}
+
+const (
+	maxArtifactSnippets = 3
+	maxSnippetLength    = 200
+)
+
+// fullTextValue is a scalar of an indexed artifact and the field it is
+// searchable in besides text.
+type fullTextValue struct {
+	field  string
+	tokens []string
+}
+
+// fullTextDoc is an indexed workspace artifact.
+type fullTextDoc struct {
+	path        string
+	kind        string
+	fingerprint string
+	lines       []string
+	values      []fullTextValue
+	// keys are the postings the document appears in.
+	keys []string
+}
+
+// fullTextIndex is an inverted index over the artifacts of a workspace. It is
+// kept in memory and refreshed before every search for the files that changed.
+type fullTextIndex struct {
+	mu   sync.Mutex
+	root string
+	docs map[string]*fullTextDoc
+	// postings maps "field:term" to the documents containing the term in that
+	// field, with the number of occurrences.
+	postings map[string]map[string]int
+}
+
+var artifactIndex = newFullTextIndex()
+
+// workspaceArtifactIndex returns the full-text index of the tenant of ctx, or
+// artifactIndex.
+func workspaceArtifactIndex(ctx context.Context) *fullTextIndex {
+	if t := requestTenant(ctx); t != nil {
+		return t.index
+	}
+	return artifactIndex
+}
+
+func newFullTextIndex() *fullTextIndex {
+	return &fullTextIndex{docs: map[string]*fullTextDoc{}, postings: map[string]map[string]int{}}
+}
+
+// refresh indexes the artifacts under root that were added or modified since
+// the last refresh, and drops the ones that were removed.
+func (x *fullTextIndex) refresh(ctx context.Context, root string) error {
+	files, err := findArtifactFiles(root)
+	if err != nil {
+		return err
+	}
+	if x.root != root {
+		x.root, x.docs, x.postings = root, map[string]*fullTextDoc{}, map[string]map[string]int{}
+	}
+
+	present := map[string]bool{}
+	for _, file := range files {
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			rel = file
+		}
+		rel = filepath.ToSlash(rel)
+		present[rel] = true
+		fingerprint, err := fileFingerprint([]string{file})
+		if err != nil {
+			return err
+		}
+		if doc, ok := x.docs[rel]; ok && doc.fingerprint == fingerprint {
+			continue
+		}
+		x.remove(rel)
+		content, err := readArtifactFile(ctx, file)
+		if err != nil {
+			continue
+		}
+		doc, err := newFullTextDoc(ctx, rel, string(content))
+		if err != nil {
+			continue
+		}
+		doc.fingerprint = fingerprint
+		x.add(doc)
+	}
+	for path := range x.docs {
+		if !present[path] {
+			x.remove(path)
+		}
+	}
+	return nil
+}
+
+func (x *fullTextIndex) add(doc *fullTextDoc) {
+	counts := map[string]int{}
+	for _, v := range doc.values {
+		for _, term := range v.tokens {
+			counts[fieldText+":"+term]++
+			if v.field != fieldText {
+				counts[v.field+":"+term]++
+			}
+		}
+	}
+	for key, n := range counts {
+		if x.postings[key] == nil {
+			x.postings[key] = map[string]int{}
+		}
+		x.postings[key][doc.path] = n
+		doc.keys = append(doc.keys, key)
+	}
+	x.docs[doc.path] = doc
+}
+
+func (x *fullTextIndex) remove(path string) {
+	doc, ok := x.docs[path]
+	if !ok {
+		return
+	}
+	for _, key := range doc.keys {
+		delete(x.postings[key], path)
+		if len(x.postings[key]) == 0 {
+			delete(x.postings, key)
+		}
+	}
+	delete(x.docs, path)
+}
+
+// newFullTextDoc parses an artifact into the values it is searchable by.
+func newFullTextDoc(ctx context.Context, path, content string) (*fullTextDoc, error) {
+	doc, err := parseArtifact(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+	kind := artifactKind(doc)
+	indexed := &fullTextDoc{path: path, kind: kind, lines: strings.Split(content, "\n")}
+	if kind != "" {
+		indexed.values = append(indexed.values, fullTextValue{field: fieldKind, tokens: fullTextTokens(kind)})
+	}
+	collectFullTextValues(doc, "", "", &indexed.values)
+	return indexed, nil
+}
+
+// collectFullTextValues appends the scalars under node, assigning each to a
+// field by its mapping key and the list it is in.
+func collectFullTextValues(node interface{}, key, list string, values *[]fullTextValue) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			collectFullTextValues(child, k, list, values)
+		}
+	case []interface{}:
+		for _, item := range v {
+			collectFullTextValues(item, key, key, values)
+		}
+	case nil:
+	default:
+		tokens := fullTextTokens(fmt.Sprint(v))
+		if len(tokens) > 0 {
+			*values = append(*values, fullTextValue{field: fullTextField(key, list), tokens: tokens})
+		}
+	}
+}
+
+func fullTextField(key, list string) string {
+	switch {
+	case key == "family" || (list == "families" && (key == "id" || key == "title")):
+		return fieldFamily
+	case key == "title":
+		return fieldTitle
+	case key == "status" || key == "state" || key == "result":
+		return fieldStatus
+	case key == "id":
+		return fieldID
+	}
+	return fieldText
+}
+
+// fullTextTokens lower-cases text into its words and numbers.
+func fullTextTokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// fullTextClause is one condition of a query, such as title:"data at rest".
+type fullTextClause struct {
+	field   string
+	tokens  []string
+	prefix  bool
+	exclude bool
+}
+
+// parseFullTextQuery splits a query into clauses. A clause is a word or a
+// "quoted phrase", optionally scoped with field: and negated with a leading
+// -; a trailing * matches any word with that prefix.
+func parseFullTextQuery(query string) ([]fullTextClause, error) {
+	var clauses []fullTextClause
+	rest := strings.TrimSpace(query)
+	for rest != "" {
+		var clause fullTextClause
+		if strings.HasPrefix(rest, "-") {
+			clause.exclude = true
+			rest = rest[1:]
+		}
+		if i := strings.IndexAny(rest, ": \""); i > 0 && rest[i] == ':' {
+			clause.field = strings.ToLower(rest[:i])
+			rest = rest[i+1:]
+			if !slices.Contains(fullTextFields, clause.field) {
+				return nil, fmt.Errorf("unknown field %q (available: %s)", clause.field, strings.Join(fullTextFields, ", "))
+			}
+		} else {
+			clause.field = fieldText
+		}
+
+		var value string
+		if strings.HasPrefix(rest, "\"") {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid query: unclosed quote")
+			}
+			value, rest = rest[1:end+1], rest[end+2:]
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			value, rest = rest[:end], rest[end:]
+		}
+		rest = strings.TrimSpace(rest)
+
+		clause.prefix = strings.HasSuffix(value, "*")
+		clause.tokens = fullTextTokens(strings.TrimSuffix(value, "*"))
+		if len(clause.tokens) == 0 {
+			continue
+		}
+		clauses = append(clauses, clause)
+	}
+	for _, c := range clauses {
+		if !c.exclude {
+			return clauses, nil
+		}
+	}
+	return nil, fmt.Errorf("query must have at least one term that is not excluded")
+}
+
+// termPostings returns the documents containing term in field, or any term
+// starting with it when prefix is set, with their occurrence counts.
+func (x *fullTextIndex) termPostings(field, term string, prefix bool) map[string]int {
+	if !prefix {
+		return x.postings[field+":"+term]
+	}
+	merged := map[string]int{}
+	for key, docs := range x.postings {
+		if strings.HasPrefix(key, field+":"+term) {
+			for path, n := range docs {
+				merged[path] += n
+			}
+		}
+	}
+	return merged
+}
+
+// match returns the score of every document that satisfies all clauses.
+func (x *fullTextIndex) match(clauses []fullTextClause) map[string]float64 {
+	var scores map[string]float64
+	excluded := map[string]bool{}
+	for _, c := range clauses {
+		clauseScores := map[string]float64{}
+		for i, term := range c.tokens {
+			postings := x.termPostings(c.field, term, c.prefix && i == len(c.tokens)-1)
+			idf := math.Log(1 + float64(len(x.docs))/float64(len(postings)+1))
+			next := map[string]float64{}
+			for path, n := range postings {
+				if _, ok := clauseScores[path]; i == 0 || ok {
+					next[path] = clauseScores[path] + (1+math.Log(float64(n)))*idf
+				}
+			}
+			clauseScores = next
+		}
+		// A phrase only matches documents with its words in a row
+		if len(c.tokens) > 1 {
+			for path := range clauseScores {
+				if !x.docs[path].hasPhrase(c) {
+					delete(clauseScores, path)
+				}
+			}
+		}
+
+		if c.exclude {
+			for path := range clauseScores {
+				excluded[path] = true
+			}
+			continue
+		}
+		if scores == nil {
+			scores = clauseScores
+			continue
+		}
+		for path := range scores {
+			if s, ok := clauseScores[path]; ok {
+				scores[path] += s
+			} else {
+				delete(scores, path)
+			}
+		}
+	}
+	for path := range excluded {
+		delete(scores, path)
+	}
+	return scores
+}
+
+// hasPhrase reports whether a value in the clause's field has the clause's
+// words in a row.
+func (d *fullTextDoc) hasPhrase(c fullTextClause) bool {
+	for _, v := range d.values {
+		if c.field != fieldText && v.field != c.field {
+			continue
+		}
+		for start := 0; start+len(c.tokens) <= len(v.tokens); start++ {
+			matched := true
+			for i, term := range c.tokens {
+				last := i == len(c.tokens)-1
+				if v.tokens[start+i] != term && !(last && c.prefix && strings.HasPrefix(v.tokens[start+i], term)) {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// snippets returns the first lines of the document that mention a term of
+// the query.
+func (d *fullTextDoc) snippets(clauses []fullTextClause) []ArtifactSnippet {
+	var snippets []ArtifactSnippet
+	for i, line := range d.lines {
+		lower := strings.ToLower(line)
+		for _, c := range clauses {
+			if c.exclude || !strings.Contains(lower, c.tokens[0]) {
+				continue
+			}
+			snippets = append(snippets, ArtifactSnippet{Line: i + 1, Text: excerpt(line, maxSnippetLength)})
+			break
+		}
+		if len(snippets) == maxArtifactSnippets {
+			break
+		}
+	}
+	return snippets
+}
+
+// MetadataSearchArtifacts describes the SearchArtifacts tool.
+var MetadataSearchArtifacts = &mcp.Tool{
+	Name: "search_artifacts",
+	Description: "Full-text search over every Gemara artifact in the workspace, returning matching artifact paths " +
+		"ranked by relevance with the lines that matched. Words must all match; scope a word or \"quoted phrase\" " +
+		"to a field with title:, family:, status:, id:, or kind:, exclude it with a leading -, and end it with * " +
+		"to match any word with that prefix, such as: status:failed family:data-protection encrypt*",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"query"},
+		"properties": withPagination(map[string]interface{}{
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": "Words and phrases to find, optionally scoped to title:, family:, status:, id:, or kind:",
+			},
+		}, "artifacts"),
+	},
+}
+
+// InputSearchArtifacts is the input for the SearchArtifacts tool.
+type InputSearchArtifacts struct {
+	PageInput
+	Query string `json:"query"`
+}
+
+// ArtifactSnippet is a line of an artifact that matched a search.
+type ArtifactSnippet struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// ArtifactMatch is an artifact found by a search.
+type ArtifactMatch struct {
+	Path     string            `json:"path"`
+	Kind     string            `json:"kind,omitempty"`
+	Score    float64           `json:"score"`
+	Snippets []ArtifactSnippet `json:"snippets"`
+}
+
+// OutputSearchArtifacts is the output for the SearchArtifacts tool.
+type OutputSearchArtifacts struct {
+	Results []ArtifactMatch `json:"results"`
+	Indexed int             `json:"indexed"`
+	Page    *PageInfo       `json:"page,omitempty"`
+	Message string          `json:"message"`
+}
+
+// SearchArtifacts searches the full-text index of the workspace artifacts.
+func SearchArtifacts(ctx context.Context, _ *mcp.CallToolRequest, input InputSearchArtifacts) (*mcp.CallToolResult, OutputSearchArtifacts, error) {
+	root := workspaceRoot(ctx)
+	if root == "" {
+		return nil, OutputSearchArtifacts{}, fmt.Errorf("search_artifacts requires a workspace root")
+	}
+	clauses, err := parseFullTextQuery(input.Query)
+	if err != nil {
+		return nil, OutputSearchArtifacts{}, err
+	}
+
+	index := workspaceArtifactIndex(ctx)
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	if err := index.refresh(ctx, root); err != nil {
+		return nil, OutputSearchArtifacts{}, err
+	}
+
+	scores := index.match(clauses)
+	results := make([]ArtifactMatch, 0, len(scores))
+	for path, score := range scores {
+		doc := index.docs[path]
+		results = append(results, ArtifactMatch{
+			Path:     path,
+			Kind:     doc.kind,
+			Score:    math.Round(score*1000) / 1000,
+			Snippets: doc.snippets(clauses),
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Path < results[j].Path
+	})
+
+	page, info, err := paginate(results, input.PageInput)
+	if err != nil {
+		return nil, OutputSearchArtifacts{}, err
+	}
+	return nil, OutputSearchArtifacts{
+		Results: page,
+		Indexed: len(index.docs),
+		Page:    &info,
+		Message: fmt.Sprintf("%d of %d artifact(s) match %q", len(results), len(index.docs), input.Query),
+	}, nil
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useArtifactIndex gives the test an empty full-text index and returns a
+// context serving root.
+func useArtifactIndex(t *testing.T, root string) context.Context {
+	t.Helper()
+	originalIndex := artifactIndex
+	t.Cleanup(func() { artifactIndex = originalIndex })
+	artifactIndex = newFullTextIndex()
+	return withTestConfig(func(c *Config) { c.WorkspaceRoot = root })
+}
+
+func TestSearchArtifacts(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"good-ccc.yaml", "evaluation-log.yaml", "policy.yaml"} {
+		content, err := os.ReadFile(filepath.Join("test-data", name))
+		require.NoError(t, err)
+		writeTestFile(t, root, name, string(content))
+	}
+	ctx := useArtifactIndex(t, root)
+
+	tests := []struct {
+		name        string
+		query       string
+		wantPaths   []string
+		errContains string
+	}{
+		{name: "word", query: "replication", wantPaths: []string{"good-ccc.yaml"}},
+		{name: "field scoped", query: "status:failed", wantPaths: []string{"evaluation-log.yaml"}},
+		{name: "phrase in family", query: "family:data-protection", wantPaths: []string{"good-ccc.yaml"}},
+		{name: "kind", query: "kind:policy", wantPaths: []string{"policy.yaml"}},
+		{name: "prefix", query: "kind:evaluation*", wantPaths: []string{"evaluation-log.yaml"}},
+		{name: "excluded", query: "replication -kind:controlcatalog", wantPaths: []string{}},
+		{name: "no match", query: "title:nonexistentword", wantPaths: []string{}},
+		{name: "unknown field", query: "owner:me", errContains: "unknown field"},
+		{name: "only exclusions", query: "-status:failed", errContains: "at least one term"},
+		{name: "unclosed quote", query: `title:"data`, errContains: "unclosed quote"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := SearchArtifacts(ctx, nil, InputSearchArtifacts{Query: tt.query})
+			if tt.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 3, output.Indexed)
+			paths := []string{}
+			for _, r := range output.Results {
+				paths = append(paths, r.Path)
+			}
+			assert.Equal(t, tt.wantPaths, paths)
+		})
+	}
+
+	_, output, err := SearchArtifacts(ctx, nil, InputSearchArtifacts{Query: "replication"})
+	require.NoError(t, err)
+	require.Len(t, output.Results, 1)
+	require.NotEmpty(t, output.Results[0].Snippets)
+	assert.Contains(t, output.Results[0].Snippets[0].Text, "replication")
+	assert.Positive(t, output.Results[0].Snippets[0].Line)
+}
+
+func TestSearchArtifactsRefresh(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "catalog.yaml", "metadata:\n  id: A\ncontrols:\n  - id: A.C01\n    title: Rotate keys\n")
+	ctx := useArtifactIndex(t, root)
+
+	_, output, err := SearchArtifacts(ctx, nil, InputSearchArtifacts{Query: "title:rotate"})
+	require.NoError(t, err)
+	require.Len(t, output.Results, 1)
+
+	writeTestFile(t, root, "catalog.yaml", "metadata:\n  id: A\ncontrols:\n  - id: A.C01\n    title: Revoke stale credentials\n")
+	writeTestFile(t, root, "other.yaml", "metadata:\n  id: B\ncontrols:\n  - id: B.C01\n    title: Rotate certificates\n")
+	_, output, err = SearchArtifacts(ctx, nil, InputSearchArtifacts{Query: "title:rotate"})
+	require.NoError(t, err)
+	require.Len(t, output.Results, 1, "the edited catalog should be reindexed")
+	assert.Equal(t, "other.yaml", output.Results[0].Path)
+
+	require.NoError(t, os.Remove(filepath.Join(root, "other.yaml")))
+	_, output, err = SearchArtifacts(ctx, nil, InputSearchArtifacts{Query: "title:rotate"})
+	require.NoError(t, err)
+	assert.Empty(t, output.Results, "removed artifacts should leave the index")
+	assert.Equal(t, 1, output.Indexed)
+	assert.Empty(t, artifactIndex.postings["title:certificates"])
+}
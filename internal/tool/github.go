@@ -0,0 +1,357 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// DefaultGitHubAPIURL is the public GitHub REST API.
+	DefaultGitHubAPIURL = "https://api.github.com"
+	// maxRepoFetchFiles caps how many files one call retrieves.
+	maxRepoFetchFiles = 20
+)
+
+// GitHubConfig configures access to the GitHub REST API.
+type GitHubConfig struct {
+	// APIURL is the REST API root, such as https://github.example.com/api/v3
+	// for GitHub Enterprise Server.
+	APIURL string
+	// Token authenticates requests, raising the rate limit and granting access
+	// to private repositories. Requests are anonymous when it is empty.
+	Token string
+}
+
+// githubRepoName matches an owner/name repository reference.
+var githubRepoName = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+
+// MetadataFetchArtifactsFromRepo describes the FetchArtifactsFromRepo tool.
+var MetadataFetchArtifactsFromRepo = &mcp.Tool{
+	Name: "fetch_artifacts_from_repo",
+	Description: "List the Gemara artifact files (YAML or JSON) in a GitHub repository at a ref, or retrieve the content " +
+		"of chosen files, so catalogs published by other projects can be validated or analyzed without pasting them. " +
+		"Use limit or max_output_bytes to page through a listing with next_cursor.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"repo"},
+		"properties": withPagination(map[string]interface{}{
+			"repo": map[string]interface{}{
+				"type":        "string",
+				"description": "Repository as owner/name (e.g., 'finos/common-cloud-controls')",
+			},
+			"ref": map[string]interface{}{
+				"type":        "string",
+				"description": "Branch, tag, or commit SHA (default: the repository's default branch)",
+			},
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Only list files below this directory",
+			},
+			"files": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"maxItems":    maxRepoFetchFiles,
+				"description": fmt.Sprintf("Paths of files to retrieve, up to %d; when omitted, files are listed without content", maxRepoFetchFiles),
+			},
+			"fields": fieldsProperty,
+		}, "listed files"),
+	},
+}
+
+// InputFetchArtifactsFromRepo is the input for the FetchArtifactsFromRepo tool.
+type InputFetchArtifactsFromRepo struct {
+	// PageInput pages through a listing; retrieved files are bounded by maxRepoFetchFiles.
+	PageInput
+	Repo   string   `json:"repo"`
+	Ref    string   `json:"ref,omitempty"`
+	Path   string   `json:"path,omitempty"`
+	Files  []string `json:"files,omitempty"`
+	Fields []string `json:"fields,omitempty"`
+}
+
+// RepoArtifact is an artifact file in a repository.
+type RepoArtifact struct {
+	Path string `json:"path"`
+	Size int64  `json:"size,omitempty"`
+	SHA  string `json:"sha,omitempty"`
+	// Definition is the Gemara definition guessed from the content, when retrieved.
+	Definition string `json:"definition,omitempty"`
+	Content    string `json:"content,omitempty"`
+}
+
+// OutputFetchArtifactsFromRepo is the output for the FetchArtifactsFromRepo tool.
+type OutputFetchArtifactsFromRepo struct {
+	Repo  string         `json:"repo"`
+	Ref   string         `json:"ref"`
+	Files []RepoArtifact `json:"files"`
+	// Truncated is set when GitHub returned only part of a very large tree.
+	Truncated bool `json:"truncated,omitempty"`
+	// Page describes which files of a listing were returned.
+	Page *PageInfo `json:"page,omitempty"`
+	// RateLimitRemaining is the number of API requests left in the current window.
+	RateLimitRemaining *int   `json:"rate_limit_remaining,omitempty"`
+	Message            string `json:"message"`
+}
+
+// FetchArtifactsFromRepo lists or retrieves artifact files from a GitHub repository.
+func FetchArtifactsFromRepo(ctx context.Context, _ *mcp.CallToolRequest, input InputFetchArtifactsFromRepo) (*mcp.CallToolResult, OutputFetchArtifactsFromRepo, error) {
+	if !githubRepoName.MatchString(input.Repo) {
+		return nil, OutputFetchArtifactsFromRepo{}, fmt.Errorf("repo must be owner/name, got %q", input.Repo)
+	}
+	if len(input.Files) > maxRepoFetchFiles {
+		return nil, OutputFetchArtifactsFromRepo{}, fmt.Errorf("at most %d files can be retrieved per call", maxRepoFetchFiles)
+	}
+
+	client := &githubClient{http: newHTTPClient(), config: serverConfig(ctx).GitHub}
+	output := OutputFetchArtifactsFromRepo{Repo: input.Repo, Ref: input.Ref, Files: []RepoArtifact{}}
+	if output.Ref == "" {
+		var repo struct {
+			DefaultBranch string `json:"default_branch"`
+		}
+		if err := client.getJSON(ctx, "repos/"+input.Repo, nil, &repo); err != nil {
+			return nil, OutputFetchArtifactsFromRepo{}, err
+		}
+		output.Ref = repo.DefaultBranch
+	}
+
+	if len(input.Files) > 0 {
+		for _, file := range input.Files {
+			content, err := client.getContent(ctx, input.Repo, output.Ref, file)
+			if err != nil {
+				return nil, OutputFetchArtifactsFromRepo{}, err
+			}
+			artifact := RepoArtifact{Path: strings.TrimPrefix(file, "/"), Size: int64(len(content)), Content: string(content)}
+			if doc, err := parseArtifact(ctx, artifact.Content); err == nil {
+				if kind := artifactKind(doc); kind != "" {
+					artifact.Definition = "#" + kind
+				}
+			}
+			if len(input.Fields) > 0 {
+				if artifact.Content, err = projectArtifact(artifact.Content, input.Fields); err != nil {
+					return nil, OutputFetchArtifactsFromRepo{}, fmt.Errorf("%s: %w", artifact.Path, err)
+				}
+			}
+			output.Files = append(output.Files, artifact)
+		}
+		output.RateLimitRemaining = client.remaining
+		output.Message = fmt.Sprintf("Retrieved %d file(s) from %s@%s", len(output.Files), input.Repo, output.Ref)
+		return nil, output, nil
+	}
+
+	var tree struct {
+		Tree []struct {
+			Path string `json:"path"`
+			Type string `json:"type"`
+			Size int64  `json:"size"`
+			SHA  string `json:"sha"`
+		} `json:"tree"`
+		Truncated bool `json:"truncated"`
+	}
+	treePath := fmt.Sprintf("repos/%s/git/trees/%s", input.Repo, url.PathEscape(output.Ref))
+	if err := client.getJSON(ctx, treePath, url.Values{"recursive": {"1"}}, &tree); err != nil {
+		return nil, OutputFetchArtifactsFromRepo{}, err
+	}
+
+	dir := strings.Trim(input.Path, "/")
+	for _, entry := range tree.Tree {
+		if entry.Type != "blob" || !isArtifactFileName(entry.Path) {
+			continue
+		}
+		if dir != "" && !strings.HasPrefix(entry.Path, dir+"/") {
+			continue
+		}
+		output.Files = append(output.Files, RepoArtifact{Path: entry.Path, Size: entry.Size, SHA: entry.SHA})
+	}
+	sort.Slice(output.Files, func(i, j int) bool { return output.Files[i].Path < output.Files[j].Path })
+	output.Truncated = tree.Truncated
+	output.RateLimitRemaining = client.remaining
+
+	output.Message = fmt.Sprintf("%d artifact file(s) in %s@%s; pass paths as files to retrieve them", len(output.Files), input.Repo, output.Ref)
+	if output.Truncated {
+		output.Message += " (the tree was truncated by GitHub; narrow the search with path)"
+	}
+
+	page, info, err := paginate(output.Files, input.PageInput)
+	if err != nil {
+		return nil, OutputFetchArtifactsFromRepo{}, err
+	}
+	output.Files, output.Page = page, &info
+	return nil, output, nil
+}
+
+// isArtifactFileName reports whether a file may hold a Gemara artifact.
+func isArtifactFileName(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// githubClient calls the GitHub REST API, tracking the remaining rate limit.
+type githubClient struct {
+	http      *http.Client
+	config    GitHubConfig
+	remaining *int
+}
+
+// getJSON decodes the response to a GET of an API path into v.
+func (c *githubClient) getJSON(ctx context.Context, apiPath string, query url.Values, v interface{}) error {
+	body, err := c.get(ctx, apiPath, query, "application/vnd.github+json")
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse GitHub response: %w", err)
+	}
+	return nil
+}
+
+// getContent returns the raw content of a file in a repository at ref.
+func (c *githubClient) getContent(ctx context.Context, repo, ref, file string) ([]byte, error) {
+	file = strings.TrimPrefix(path.Clean("/"+file), "/")
+	if file == "" {
+		return nil, fmt.Errorf("file path is required")
+	}
+	segments := strings.Split(file, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return c.get(ctx, "repos/"+repo+"/contents/"+strings.Join(segments, "/"), url.Values{"ref": {ref}}, "application/vnd.github.raw")
+}
+
+// get performs an authenticated GET of an API path, enforcing the artifact
+// size limit and reporting rate limiting with the time it resets.
+func (c *githubClient) get(ctx context.Context, apiPath string, query url.Values, accept string) ([]byte, error) {
+	return c.do(ctx, http.MethodGet, apiPath, query, accept, nil)
+}
+
+// sendJSON sends payload as JSON with the given method and decodes the
+// response into v, if v is not nil.
+func (c *githubClient) sendJSON(ctx context.Context, method, apiPath string, payload, v interface{}) error {
+	if err := checkWritable(ctx, "GitHub "+apiPath); err != nil {
+		return err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode GitHub request: %w", err)
+	}
+	response, err := c.do(ctx, method, apiPath, nil, "application/vnd.github+json", body)
+	if err != nil || v == nil {
+		return err
+	}
+	if err := json.Unmarshal(response, v); err != nil {
+		return fmt.Errorf("failed to parse GitHub response: %w", err)
+	}
+	return nil
+}
+
+// do performs an authenticated request to an API path, as get does.
+func (c *githubClient) do(ctx context.Context, method, apiPath string, query url.Values, accept string, payload []byte) ([]byte, error) {
+	base, err := url.Parse(strings.TrimSuffix(c.config.APIURL, "/") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub API URL %q: %w", c.config.APIURL, err)
+	}
+	target, err := base.Parse(apiPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub API path %q: %w", apiPath, err)
+	}
+	target.RawQuery = query.Encode()
+
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", accept)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.Token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		emitUpstreamFailure(ctx, "GitHub", err)
+		return nil, fmt.Errorf("failed to fetch %s: %w", target.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	if remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		c.remaining = &remaining
+	}
+	if err := githubStatusError(resp, c.config.Token != ""); err != nil {
+		return nil, err
+	}
+	limit := serverConfig(ctx).MaxArtifactSize
+	if resp.ContentLength > limit {
+		return nil, fmt.Errorf("%s is %d bytes, exceeding the %d byte limit", apiPath, resp.ContentLength, limit)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%s exceeds the %d byte limit", apiPath, limit)
+	}
+	return body, nil
+}
+
+// githubStatusError describes an unsuccessful GitHub API response.
+func githubStatusError(resp *http.Response, authenticated bool) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	var problem struct {
+		Message string `json:"message"`
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	_ = json.Unmarshal(body, &problem)
+
+	rateLimited := resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0"
+	switch {
+	case rateLimited:
+		msg := "GitHub API rate limit exceeded"
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			msg += fmt.Sprintf("; it resets at %s", time.Unix(reset, 0).UTC().Format(time.RFC3339))
+		} else if retry := resp.Header.Get("Retry-After"); retry != "" {
+			msg += fmt.Sprintf("; retry after %s second(s)", retry)
+		}
+		if !authenticated {
+			msg += "; set GITHUB_TOKEN for a higher limit"
+		}
+		return fmt.Errorf("%s", msg)
+	case resp.StatusCode == http.StatusNotFound:
+		if authenticated {
+			return fmt.Errorf("not found on GitHub: check the repository, ref, and path")
+		}
+		return fmt.Errorf("not found on GitHub: check the repository, ref, and path, or set GITHUB_TOKEN for private repositories")
+	case resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("GitHub rejected the token: %s", problem.Message)
+	case problem.Message != "":
+		return fmt.Errorf("GitHub request failed with status %d: %s", resp.StatusCode, problem.Message)
+	default:
+		return fmt.Errorf("GitHub request failed with status %d", resp.StatusCode)
+	}
+}
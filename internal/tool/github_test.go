@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useGitHub returns a context whose GitHub configuration is config.
+func useGitHub(config GitHubConfig) context.Context {
+	return withTestConfig(func(c *Config) { c.GitHub = config })
+}
+
+func TestFetchArtifactsFromRepo(t *testing.T) {
+	require.NoError(t, useHTTPConfig(t, HTTPConfig{}))
+
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		switch r.URL.Path {
+		case "/repos/acme/controls":
+			_ = json.NewEncoder(w).Encode(map[string]string{"default_branch": "main"})
+		case "/repos/acme/controls/git/trees/main", "/repos/acme/controls/git/trees/v1.0":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"tree": []map[string]interface{}{
+					{"path": "catalogs", "type": "tree"},
+					{"path": "catalogs/storage.yaml", "type": "blob", "size": 120, "sha": "abc"},
+					{"path": "catalogs/README.md", "type": "blob", "size": 10, "sha": "def"},
+					{"path": "policies/baseline.yml", "type": "blob", "size": 80, "sha": "012"},
+				},
+			})
+		case "/repos/acme/controls/contents/catalogs/storage.yaml":
+			assert.Equal(t, "v1.0", r.URL.Query().Get("ref"))
+			assert.Equal(t, "application/vnd.github.raw", r.Header.Get("Accept"))
+			_, _ = w.Write([]byte("title: Storage\ncontrols:\n  - id: ST.C01\n"))
+		case "/repos/acme/limited":
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "1700000000")
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"API rate limit exceeded"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		token       string
+		input       InputFetchArtifactsFromRepo
+		wantErr     bool
+		errContains string
+		wantRef     string
+		wantFiles   []RepoArtifact
+		wantAuth    string
+	}{
+		{
+			name:        "invalid repo",
+			input:       InputFetchArtifactsFromRepo{Repo: "https://github.com/acme/controls"},
+			wantErr:     true,
+			errContains: "owner/name",
+		},
+		{
+			name:    "list default branch",
+			input:   InputFetchArtifactsFromRepo{Repo: "acme/controls"},
+			wantRef: "main",
+			wantFiles: []RepoArtifact{
+				{Path: "catalogs/storage.yaml", Size: 120, SHA: "abc"},
+				{Path: "policies/baseline.yml", Size: 80, SHA: "012"},
+			},
+		},
+		{
+			name:      "list below path with token",
+			token:     "secret",
+			input:     InputFetchArtifactsFromRepo{Repo: "acme/controls", Ref: "v1.0", Path: "catalogs/"},
+			wantRef:   "v1.0",
+			wantFiles: []RepoArtifact{{Path: "catalogs/storage.yaml", Size: 120, SHA: "abc"}},
+			wantAuth:  "Bearer secret",
+		},
+		{
+			name:      "list a page",
+			input:     InputFetchArtifactsFromRepo{Repo: "acme/controls", PageInput: PageInput{Offset: 1, Limit: 1}},
+			wantRef:   "main",
+			wantFiles: []RepoArtifact{{Path: "policies/baseline.yml", Size: 80, SHA: "012"}},
+		},
+		{
+			name:    "retrieve files",
+			input:   InputFetchArtifactsFromRepo{Repo: "acme/controls", Ref: "v1.0", Files: []string{"/catalogs/storage.yaml"}},
+			wantRef: "v1.0",
+			wantFiles: []RepoArtifact{{
+				Path:       "catalogs/storage.yaml",
+				Size:       40,
+				Definition: "#ControlCatalog",
+				Content:    "title: Storage\ncontrols:\n  - id: ST.C01\n",
+			}},
+		},
+		{
+			name:    "retrieve files with fields",
+			input:   InputFetchArtifactsFromRepo{Repo: "acme/controls", Ref: "v1.0", Files: []string{"catalogs/storage.yaml"}, Fields: []string{"$.controls[*].id"}},
+			wantRef: "v1.0",
+			wantFiles: []RepoArtifact{{
+				Path:       "catalogs/storage.yaml",
+				Size:       40,
+				Definition: "#ControlCatalog",
+				Content:    "controls:\n  - id: ST.C01\n",
+			}},
+		},
+		{
+			name:        "missing file",
+			input:       InputFetchArtifactsFromRepo{Repo: "acme/controls", Ref: "main", Files: []string{"missing.yaml"}},
+			wantErr:     true,
+			errContains: "set GITHUB_TOKEN for private repositories",
+		},
+		{
+			name:        "rate limited",
+			input:       InputFetchArtifactsFromRepo{Repo: "acme/limited"},
+			wantErr:     true,
+			errContains: "rate limit exceeded; it resets at 2023-11-14T22:13:20Z",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := useGitHub(GitHubConfig{APIURL: server.URL, Token: tt.token})
+			authorization = ""
+
+			_, output, err := FetchArtifactsFromRepo(ctx, nil, tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRef, output.Ref)
+			assert.Equal(t, tt.wantFiles, output.Files)
+			assert.Equal(t, tt.wantAuth, authorization)
+			require.NotNil(t, output.RateLimitRemaining)
+			assert.Equal(t, 42, *output.RateLimitRemaining)
+		})
+	}
+}
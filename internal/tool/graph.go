@@ -0,0 +1,160 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"strings"
+)
+
+// graphEntity is an identified element (control, requirement, plan, ...) in an artifact.
+type graphEntity struct {
+	ID       string
+	Artifact string
+	Kind     string
+	Path     string
+}
+
+// graphReference is a location in an artifact that refers to an entity by ID.
+type graphReference struct {
+	Target   string
+	Artifact string
+	Kind     string
+	Path     string
+	// Owner is the ID of the nearest enclosing entity, if any.
+	Owner string
+}
+
+// artifactGraph indexes entities and the references between them across a set of artifacts.
+type artifactGraph struct {
+	entities   map[string][]graphEntity
+	references map[string][]graphReference
+}
+
+// buildArtifactGraph parses the given artifacts and indexes every entity and reference.
+// Any map with a string "id" field is treated as an entity; any other string value
+// equal to a known entity ID is treated as a reference to it.
+func buildArtifactGraph(ctx context.Context, artifacts []ArtifactInput) (*artifactGraph, error) {
+	type parsed struct {
+		name string
+		kind string
+		doc  map[string]interface{}
+	}
+
+	docs := make([]parsed, 0, len(artifacts))
+	g := &artifactGraph{
+		entities:   make(map[string][]graphEntity),
+		references: make(map[string][]graphReference),
+	}
+
+	// First pass: collect entities
+	for i, a := range artifacts {
+		doc, err := parseArtifact(ctx, a.Content)
+		if err != nil {
+			return nil, &artifactError{Name: artifactName(a, i), Err: err}
+		}
+		p := parsed{name: artifactName(a, i), kind: artifactKind(doc), doc: doc}
+		docs = append(docs, p)
+
+		walkArtifact(doc, "$", func(path string, value interface{}) {
+			if id := entityID(value); id != "" {
+				g.entities[id] = append(g.entities[id], graphEntity{
+					ID:       id,
+					Artifact: p.name,
+					Kind:     p.kind,
+					Path:     path,
+				})
+			}
+		})
+	}
+
+	// Second pass: collect references to known entities
+	for _, p := range docs {
+		owners := map[string]string{}
+		walkArtifact(p.doc, "$", func(path string, value interface{}) {
+			if id := entityID(value); id != "" {
+				owners[path] = id
+			}
+			s, ok := value.(string)
+			if !ok || lastPathKey(path) == "id" {
+				return
+			}
+			s = strings.TrimSpace(s)
+			if _, known := g.entities[s]; !known {
+				return
+			}
+			g.references[s] = append(g.references[s], graphReference{
+				Target:   s,
+				Artifact: p.name,
+				Kind:     p.kind,
+				Path:     path,
+				Owner:    nearestOwner(owners, path),
+			})
+		})
+	}
+
+	return g, nil
+}
+
+// descendants returns the IDs of entities nested under the given entity in the same artifact.
+func (g *artifactGraph) descendants(e graphEntity) []string {
+	var ids []string
+	for id, list := range g.entities {
+		for _, other := range list {
+			if other.Artifact == e.Artifact && strings.HasPrefix(other.Path, e.Path+".") {
+				ids = append(ids, id)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// definedIn reports whether the entity is defined in the named artifact.
+func (g *artifactGraph) definedIn(id, artifact string) bool {
+	for _, e := range g.entities[id] {
+		if e.Artifact == artifact {
+			return true
+		}
+	}
+	return false
+}
+
+// entityID returns the "id" field of a map node, or an empty string.
+func entityID(value interface{}) string {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	id, _ := m["id"].(string)
+	return strings.TrimSpace(id)
+}
+
+// nearestOwner finds the closest ancestor path registered as an entity.
+func nearestOwner(owners map[string]string, path string) string {
+	for p := path; p != "$" && p != ""; {
+		i := strings.LastIndexAny(p, ".[")
+		if i < 0 {
+			break
+		}
+		p = p[:i]
+		if id, ok := owners[p]; ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// artifactError reports a failure tied to a specific inline artifact.
+type artifactError struct {
+	Name string
+	Err  error
+}
+
+func (e *artifactError) Error() string {
+	return e.Name + ": " + e.Err.Error()
+}
+
+func (e *artifactError) Unwrap() error {
+	return e.Err
+}
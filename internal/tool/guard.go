@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+const (
+	// DefaultMaxArtifactDepth is the default limit on how deeply mappings and
+	// sequences may nest in an artifact.
+	DefaultMaxArtifactDepth = 64
+	// DefaultMaxAliasExpansion is the default limit on the number of nodes YAML
+	// aliases may expand to.
+	DefaultMaxAliasExpansion = 10000
+	// DefaultValidationTimeout is the default limit on CUE evaluation of an artifact.
+	DefaultValidationTimeout = 30 * time.Second
+)
+
+// checkArtifactLimits rejects artifact content that is too large, too deeply
+// nested, or whose aliases expand to too many nodes. The structure is checked
+// on the YAML syntax tree, where aliases are still unexpanded, so the check
+// itself is linear in the size of the content. CUE content is only checked for
+// size, as it has no aliases and is bounded by ValidationTimeout instead.
+// The limits are those of the configuration of ctx.
+func checkArtifactLimits(ctx context.Context, format, content string) error {
+	config := serverConfig(ctx)
+	if int64(len(content)) > config.MaxArtifactSize {
+		return fmt.Errorf("artifact is %d bytes, exceeding the %d byte limit", len(content), config.MaxArtifactSize)
+	}
+	if format == formatCUE {
+		return nil
+	}
+
+	file, err := parser.ParseBytes([]byte(content), 0)
+	if err != nil {
+		// Syntax errors are reported by validation itself
+		return nil
+	}
+	counter := &nodeCounter{anchors: map[string]nodeCount{}, limit: config.MaxAliasExpansion}
+	for _, doc := range file.Docs {
+		if doc.Body == nil {
+			continue
+		}
+		count := counter.count(doc.Body)
+		if count.depth > config.MaxArtifactDepth {
+			return fmt.Errorf("artifact nests %d levels deep, exceeding the limit of %d", count.depth, config.MaxArtifactDepth)
+		}
+		if counter.aliased > config.MaxAliasExpansion {
+			return fmt.Errorf("artifact aliases expand to more than %d nodes", config.MaxAliasExpansion)
+		}
+	}
+	return nil
+}
+
+// nodeCount is the size and nesting depth of a YAML node once its aliases are expanded.
+type nodeCount struct {
+	nodes int
+	depth int
+}
+
+// nodeCounter measures YAML nodes without expanding aliases, by remembering
+// the expanded count of every anchor.
+type nodeCounter struct {
+	anchors map[string]nodeCount
+	// aliased is the number of nodes reached through aliases so far.
+	aliased int
+	// limit is the alias expansion past which counting stops.
+	limit int
+}
+
+func (c *nodeCounter) count(node ast.Node) nodeCount {
+	switch n := node.(type) {
+	case *ast.AliasNode:
+		anchor := c.anchors[n.Value.String()]
+		c.aliased = saturatingAdd(c.aliased, anchor.nodes)
+		return anchor
+	case *ast.AnchorNode:
+		if n.Value == nil {
+			return nodeCount{nodes: 1}
+		}
+		count := c.count(n.Value)
+		c.anchors[n.Name.String()] = count
+		return count
+	}
+
+	total := nodeCount{nodes: 1}
+	for _, child := range childNodes(node) {
+		count := c.count(child)
+		total.nodes = saturatingAdd(total.nodes, count.nodes)
+		total.depth = max(total.depth, count.depth)
+		// Stop early rather than walk the rest of an oversized document
+		if c.aliased > c.limit {
+			break
+		}
+	}
+	switch node.(type) {
+	case *ast.MappingNode, *ast.SequenceNode:
+		total.depth++
+	}
+	return total
+}
+
+// childNodes returns the direct children of a YAML node, skipping comments.
+func childNodes(node ast.Node) []ast.Node {
+	collector := &childCollector{parent: node}
+	ast.Walk(collector, node)
+	return collector.children
+}
+
+// childCollector is an ast.Visitor that records the children of parent
+// without descending further.
+type childCollector struct {
+	parent   ast.Node
+	children []ast.Node
+}
+
+func (c *childCollector) Visit(node ast.Node) ast.Visitor {
+	switch node.(type) {
+	case nil, *ast.CommentNode, *ast.CommentGroupNode:
+		return nil
+	}
+	if node == c.parent {
+		return c
+	}
+	c.children = append(c.children, node)
+	return nil
+}
+
+func saturatingAdd(a, b int) int {
+	if sum := a + b; sum >= a {
+		return sum
+	}
+	return int(^uint(0) >> 1)
+}
+
+// withValidationTimeout runs validate, giving up after ValidationTimeout. CUE
+// evaluation cannot be interrupted, so on timeout the evaluation is abandoned
+// to finish in the background, with the schemas the call borrowed, and only
+// its result is discarded.
+func withValidationTimeout[T any](ctx context.Context, validate func() (T, error)) (T, error) {
+	timeout := serverConfig(ctx).ValidationTimeout
+	if timeout <= 0 {
+		return validate()
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := validate()
+		done <- result{value, err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	var zero T
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-timer.C:
+		abandonSchemas(ctx)
+		return zero, fmt.Errorf("validation exceeded the %s time limit", timeout)
+	case <-ctx.Done():
+		abandonSchemas(ctx)
+		return zero, ctx.Err()
+	}
+}
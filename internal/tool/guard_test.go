@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useLimits returns a context with the given artifact limits.
+func useLimits(size int64, depth, aliases int, timeout time.Duration) context.Context {
+	return withTestConfig(func(c *Config) {
+		c.MaxArtifactSize, c.MaxArtifactDepth, c.MaxAliasExpansion, c.ValidationTimeout = size, depth, aliases, timeout
+	})
+}
+
+func TestCheckArtifactLimits(t *testing.T) {
+	ctx := useLimits(4096, 4, 50, DefaultValidationTimeout)
+
+	laughs := `a: &a [x, x, x, x, x]
+b: &b [*a, *a, *a, *a, *a]
+c: &c [*b, *b, *b, *b, *b]
+d: &d [*c, *c, *c, *c, *c]
+`
+
+	tests := []struct {
+		name        string
+		format      string
+		content     string
+		errContains string
+	}{
+		{name: "within limits", format: formatYAML, content: "a:\n  b:\n    - c: 1\n"},
+		{name: "too large", format: formatYAML, content: strings.Repeat("a", 4097), errContains: "exceeding the 4096 byte limit"},
+		{name: "too large CUE", format: formatCUE, content: strings.Repeat("a", 4097), errContains: "byte limit"},
+		{name: "too deep", format: formatYAML, content: "a:\n  b:\n    c:\n      d:\n        e: 1\n", errContains: "nests 5 levels deep"},
+		{name: "too deep flow", format: formatJSON, content: `{"a": [[[[1]]]]}`, errContains: "nests 5 levels deep"},
+		{name: "aliases within limit", format: formatYAML, content: "a: &a [x, x]\nb: [*a, *a]\n"},
+		{name: "billion laughs", format: formatYAML, content: laughs, errContains: "aliases expand to more than 50 nodes"},
+		{name: "CUE depth not checked", format: formatCUE, content: "a: b: c: d: e: 1\n"},
+		{name: "syntax error left to validation", format: formatYAML, content: "a: [\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkArtifactLimits(ctx, tt.format, tt.content)
+			if tt.errContains != "" {
+				require.Error(t, err, "should reject the artifact")
+				assert.Contains(t, err.Error(), tt.errContains, "error should contain expected message")
+				return
+			}
+			assert.NoError(t, err, "should accept the artifact")
+		})
+	}
+}
+
+func TestValidateGemaraArtifactLimits(t *testing.T) {
+	useTestSchema(t)
+	content, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err, "should read test catalog")
+
+	ctx := useLimits(64, DefaultMaxArtifactDepth, DefaultMaxAliasExpansion, DefaultValidationTimeout)
+	_, _, err = ValidateGemaraArtifact(ctx, nil, InputValidateGemaraArtifact{
+		ArtifactContent: string(content),
+		Definition:      "#ControlCatalog",
+	})
+	require.Error(t, err, "should reject inline content over the size limit")
+	assert.Contains(t, err.Error(), "byte limit", "error should name the limit")
+}
+
+// aliasBomb is a 9^9 node YAML alias expansion in under 400 bytes.
+const aliasBomb = `a: &a [x, x, x, x, x, x, x, x, x]
+b: &b [*a, *a, *a, *a, *a, *a, *a, *a, *a]
+c: &c [*b, *b, *b, *b, *b, *b, *b, *b, *b]
+d: &d [*c, *c, *c, *c, *c, *c, *c, *c, *c]
+e: &e [*d, *d, *d, *d, *d, *d, *d, *d, *d]
+f: &f [*e, *e, *e, *e, *e, *e, *e, *e, *e]
+g: &g [*f, *f, *f, *f, *f, *f, *f, *f, *f]
+h: &h [*g, *g, *g, *g, *g, *g, *g, *g, *g]
+i: &i [*h, *h, *h, *h, *h, *h, *h, *h, *h]
+`
+
+func TestArtifactLimitsBeforeParsing(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "bomb.yaml", aliasBomb)
+	ctx := withTestConfig(func(c *Config) { c.WorkspaceRoot = dir })
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{
+			name: "lint inline content",
+			call: func() error {
+				_, _, err := LintGemaraArtifact(ctx, nil, InputLintGemaraArtifact{ArtifactContent: aliasBomb})
+				return err
+			},
+		},
+		{
+			name: "compatibility by URI",
+			call: func() error {
+				_, _, err := CheckSchemaCompatibility(ctx, nil, InputCheckSchemaCompatibility{ArtifactURI: fileURL(filepath.Join(dir, "bomb.yaml"))})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done := make(chan error, 1)
+			go func() { done <- tt.call() }()
+			select {
+			case err := <-done:
+				require.Error(t, err, "should reject the alias bomb")
+				assert.Contains(t, err.Error(), "aliases expand to more than", "error should name the limit")
+			case <-time.After(5 * time.Second):
+				t.Fatal("the alias bomb was expanded")
+			}
+		})
+	}
+}
+
+func TestWithValidationTimeout(t *testing.T) {
+	ctx := useLimits(DefaultMaxArtifactSize, DefaultMaxArtifactDepth, DefaultMaxAliasExpansion, 10*time.Millisecond)
+
+	value, err := withValidationTimeout(ctx, func() (string, error) { return "done", nil })
+	require.NoError(t, err, "should return the result of a quick validation")
+	assert.Equal(t, "done", value)
+
+	release := make(chan struct{})
+	defer close(release)
+	_, err = withValidationTimeout(ctx, func() (string, error) {
+		<-release
+		return "late", nil
+	})
+	require.Error(t, err, "should give up on a slow validation")
+	assert.Contains(t, err.Error(), "time limit", "error should name the limit")
+
+	ctx, cancel := context.WithCancel(useLimits(DefaultMaxArtifactSize, DefaultMaxArtifactDepth, DefaultMaxAliasExpansion, time.Hour))
+	cancel()
+	_, err = withValidationTimeout(ctx, func() (string, error) {
+		<-release
+		return "late", nil
+	})
+	assert.ErrorIs(t, err, context.Canceled, "should stop when the request is cancelled")
+}
@@ -0,0 +1,441 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Document formats accepted by ImportGuidanceDocument.
+const (
+	documentFormatMarkdown = "markdown"
+	documentFormatHTML     = "html"
+)
+
+const defaultGuidanceID = "DRAFT-GUIDANCE"
+
+// Kinds of block read from a prose document.
+const (
+	blockHeading = iota
+	blockParagraph
+	blockNumbered
+)
+
+// guidanceBlock is a heading, paragraph, or numbered item of a prose document.
+type guidanceBlock struct {
+	kind  int
+	level int
+	text  string
+}
+
+var (
+	markdownHeading = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	// markdownNumbered matches "1." and "1)" list items and "3.1" clauses, not
+	// lines that merely start with a number.
+	markdownNumbered = regexp.MustCompile(`^(\s*)(\d+[.)]|\d+(?:\.\d+)+[.)]?)\s+(\S.*)$`)
+	markdownBullet   = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	markdownFence    = regexp.MustCompile("^\\s*(```|~~~)")
+	markdownLink     = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	markdownEmphasis = regexp.MustCompile("(\\*\\*|__|\\*|`)")
+	// sectionNumber matches the numbering of headings such as "3.1 Access Control".
+	sectionNumber = regexp.MustCompile(`^(?:(?:section|chapter)\s+)?\d+(?:\.\d+)*[.):]?\s+`)
+)
+
+// MetadataImportGuidanceDocument describes the ImportGuidanceDocument tool.
+var MetadataImportGuidanceDocument = &mcp.Tool{
+	Name: "import_guidance_document",
+	Description: "Convert a prose standard or guideline written in Markdown or HTML into a draft Layer 1 GuidanceDocument: " +
+		"headings become categories, numbered requirements under them become guidelines, and the paragraphs between a " +
+		"heading and its requirements become the category description. The draft is validated against the schema and " +
+		"headings without numbered requirements are reported. Nothing is written to disk.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"document_content": map[string]interface{}{
+				"type":        "string",
+				"description": "Markdown or HTML content of the standard",
+			},
+			"document_uri": map[string]interface{}{
+				"type": "string",
+				"description": "URI of the standard instead of inline content: file:// (within the workspace root) or " +
+					"https://",
+			},
+			"format": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{documentFormatMarkdown, documentFormatHTML},
+				"description": "Format of the document (default: html when the content starts with a tag, markdown otherwise)",
+			},
+			"guidance_id": map[string]interface{}{
+				"type":        "string",
+				"description": fmt.Sprintf("Metadata id of the guidance document (default: %s)", defaultGuidanceID),
+			},
+			"title": map[string]interface{}{
+				"type":        "string",
+				"description": "Title of the guidance document (default: the document's only top-level heading)",
+			},
+			"id_prefix": map[string]interface{}{
+				"type":        "string",
+				"description": "Prefix for generated category and guideline IDs (default: derived from guidance_id)",
+			},
+		},
+	},
+}
+
+// InputImportGuidanceDocument is the input for the ImportGuidanceDocument tool.
+type InputImportGuidanceDocument struct {
+	DocumentContent string `json:"document_content,omitempty"`
+	DocumentURI     string `json:"document_uri,omitempty"`
+	Format          string `json:"format,omitempty"`
+	GuidanceID      string `json:"guidance_id,omitempty"`
+	Title           string `json:"title,omitempty"`
+	IDPrefix        string `json:"id_prefix,omitempty"`
+}
+
+// ImportedCategory summarizes a category converted from a document section.
+type ImportedCategory struct {
+	ID         string   `json:"id"`
+	Title      string   `json:"title"`
+	Guidelines []string `json:"guidelines"`
+}
+
+// OutputImportGuidanceDocument is the output for the ImportGuidanceDocument tool.
+type OutputImportGuidanceDocument struct {
+	Content    string             `json:"content"`
+	Categories []ImportedCategory `json:"categories"`
+	// Skipped lists the headings without numbered requirements.
+	Skipped []string `json:"skipped"`
+	Valid   bool     `json:"valid"`
+	Errors  []string `json:"errors,omitempty"`
+	// Placeholders lists the paths left for the author to complete.
+	Placeholders []string `json:"placeholders"`
+	Message      string   `json:"message"`
+}
+
+// importedSection accumulates the blocks under one heading.
+type importedSection struct {
+	title       string
+	description []string
+	guidelines  []string
+}
+
+// ImportGuidanceDocument drafts a GuidanceDocument from a prose standard.
+func ImportGuidanceDocument(ctx context.Context, _ *mcp.CallToolRequest, input InputImportGuidanceDocument) (*mcp.CallToolResult, OutputImportGuidanceDocument, error) {
+	content, err := readGuidanceDocument(ctx, input)
+	if err != nil {
+		return nil, OutputImportGuidanceDocument{}, err
+	}
+	format := input.Format
+	if format == "" {
+		format = documentFormatMarkdown
+		if strings.HasPrefix(strings.TrimSpace(content), "<") {
+			format = documentFormatHTML
+		}
+	}
+	var blocks []guidanceBlock
+	switch format {
+	case documentFormatMarkdown:
+		blocks = markdownBlocks(content)
+	case documentFormatHTML:
+		if blocks, err = htmlBlocks(content); err != nil {
+			return nil, OutputImportGuidanceDocument{}, err
+		}
+	default:
+		return nil, OutputImportGuidanceDocument{}, fmt.Errorf("unsupported format %q: must be %q or %q", format, documentFormatMarkdown, documentFormatHTML)
+	}
+
+	guidanceID := input.GuidanceID
+	if guidanceID == "" {
+		guidanceID = defaultGuidanceID
+	}
+	prefix := input.IDPrefix
+	if prefix == "" {
+		prefix = skeletonPrefix(guidanceID)
+	}
+
+	// A single top-level heading names the document rather than a section
+	title := input.Title
+	topLevel := 0
+	for _, b := range blocks {
+		if b.kind == blockHeading && b.level == 1 {
+			topLevel++
+		}
+	}
+	var intro []string
+	var sections []*importedSection
+	var current *importedSection
+	for _, b := range blocks {
+		switch b.kind {
+		case blockHeading:
+			if topLevel == 1 && b.level == 1 {
+				if title == "" {
+					title = b.text
+				}
+				current = nil
+				continue
+			}
+			current = &importedSection{title: sectionNumber.ReplaceAllString(b.text, "")}
+			sections = append(sections, current)
+		case blockParagraph:
+			switch {
+			case current == nil:
+				intro = append(intro, b.text)
+			case len(current.guidelines) == 0:
+				current.description = append(current.description, b.text)
+			}
+		case blockNumbered:
+			if current == nil {
+				current = &importedSection{title: skeletonGeneralFamily}
+				sections = append(sections, current)
+			}
+			current.guidelines = append(current.guidelines, b.text)
+		}
+	}
+	if title == "" {
+		title = "Imported Guidance Document"
+	}
+
+	output := OutputImportGuidanceDocument{Categories: []ImportedCategory{}, Skipped: []string{}, Placeholders: []string{"$.metadata.author"}}
+	description := strings.Join(intro, "\n\n")
+	if description == "" {
+		description = fmt.Sprintf("%s: describe the scope of this guidance. Imported from a %s document.", skeletonPlaceholder, format)
+		output.Placeholders = append([]string{"$.metadata.description"}, output.Placeholders...)
+	}
+
+	var categories []interface{}
+	for _, section := range sections {
+		if len(section.guidelines) == 0 {
+			output.Skipped = append(output.Skipped, section.title)
+			continue
+		}
+		categoryID := fmt.Sprintf("%s.%02d", prefix, len(categories)+1)
+		summary := ImportedCategory{ID: categoryID, Title: section.title}
+		categoryDescription := strings.Join(section.description, "\n\n")
+		if categoryDescription == "" {
+			categoryDescription = fmt.Sprintf("%s: describe the %s guidelines.", skeletonPlaceholder, strings.ToLower(section.title))
+			output.Placeholders = append(output.Placeholders, fmt.Sprintf("$.categories[%d].description", len(categories)))
+		}
+
+		var guidelines []interface{}
+		for _, text := range section.guidelines {
+			guidelineID := fmt.Sprintf("%s.%02d", categoryID, len(guidelines)+1)
+			summary.Guidelines = append(summary.Guidelines, guidelineID)
+			guidelines = append(guidelines, yaml.MapSlice{
+				{Key: "id", Value: guidelineID},
+				{Key: "title", Value: skeletonTitle(text)},
+				{Key: "objective", Value: text},
+			})
+		}
+		categories = append(categories, yaml.MapSlice{
+			{Key: "id", Value: categoryID},
+			{Key: "title", Value: section.title},
+			{Key: "description", Value: categoryDescription},
+			{Key: "guidelines", Value: guidelines},
+		})
+		output.Categories = append(output.Categories, summary)
+	}
+	if len(categories) == 0 {
+		return nil, OutputImportGuidanceDocument{}, fmt.Errorf("no numbered requirements found in the document (%d heading(s) without any)", len(output.Skipped))
+	}
+
+	guidance := yaml.MapSlice{
+		{Key: "metadata", Value: yaml.MapSlice{
+			{Key: "id", Value: guidanceID},
+			{Key: "description", Value: description},
+			{Key: "version", Value: "0.1.0"},
+			{Key: "author", Value: draftAuthor},
+		}},
+		{Key: "title", Value: title},
+		{Key: "categories", Value: categories},
+	}
+	out, err := yaml.MarshalWithOptions(guidance, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return nil, OutputImportGuidanceDocument{}, fmt.Errorf("failed to encode guidance document: %w", err)
+	}
+	output.Content = string(out)
+
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputImportGuidanceDocument{}, err
+	}
+	validation, err := validateAgainstSchema(schema, "#GuidanceDocument", output.Content)
+	if err != nil {
+		return nil, OutputImportGuidanceDocument{}, err
+	}
+	output.Valid = validation.Valid
+	output.Errors = validation.Errors
+
+	guidelines := 0
+	for _, c := range output.Categories {
+		guidelines += len(c.Guidelines)
+	}
+	output.Message = fmt.Sprintf("Imported %d guideline(s) in %d category(ies); skipped %d heading(s) without numbered requirements; %s",
+		guidelines, len(output.Categories), len(output.Skipped), strings.ToLower(validation.Message))
+	return nil, output, nil
+}
+
+// readGuidanceDocument returns the document passed inline or by URI.
+func readGuidanceDocument(ctx context.Context, input InputImportGuidanceDocument) (string, error) {
+	content := input.DocumentContent
+	switch {
+	case content == "" && input.DocumentURI == "":
+		return "", fmt.Errorf("document_content or document_uri is required")
+	case content != "" && input.DocumentURI != "":
+		return "", fmt.Errorf("document_content and document_uri are mutually exclusive")
+	case input.DocumentURI != "":
+		raw, err := readArtifactURI(ctx, input.DocumentURI)
+		if err != nil {
+			return "", err
+		}
+		content = string(raw)
+	}
+	if int64(len(content)) > serverConfig(ctx).MaxArtifactSize {
+		return "", fmt.Errorf("document is %d bytes, exceeding the %d byte limit", len(content), serverConfig(ctx).MaxArtifactSize)
+	}
+	return strings.TrimPrefix(content, "\ufeff"), nil
+}
+
+// markdownBlocks reads the ATX headings, paragraphs, and numbered items of a
+// Markdown document. Indented lines continue the item above them, so nested
+// lists stay part of their parent requirement; code blocks and tables are
+// skipped.
+func markdownBlocks(content string) []guidanceBlock {
+	var blocks []guidanceBlock
+	var text []string
+	kind := -1
+	flush := func() {
+		if kind >= 0 && len(text) > 0 {
+			if t := markdownPlainText(strings.Join(text, " ")); t != "" {
+				blocks = append(blocks, guidanceBlock{kind: kind, text: t})
+			}
+		}
+		text, kind = nil, -1
+	}
+
+	fenced := false
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if markdownFence.MatchString(line) {
+			flush()
+			fenced = !fenced
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case fenced || strings.HasPrefix(trimmed, "|") || strings.HasPrefix(trimmed, "<!--"):
+			flush()
+		case trimmed == "":
+			if kind == blockParagraph {
+				flush()
+			}
+		case markdownHeading.MatchString(trimmed):
+			flush()
+			m := markdownHeading.FindStringSubmatch(trimmed)
+			if t := markdownPlainText(m[2]); t != "" {
+				blocks = append(blocks, guidanceBlock{kind: blockHeading, level: len(m[1]), text: t})
+			}
+		case kind == blockNumbered && line != trimmed:
+			// Indented lines, including nested list items, continue the item
+			text = append(text, strings.TrimSpace(markdownBullet.ReplaceAllString(trimmed, "$1")))
+		case markdownNumbered.MatchString(line):
+			flush()
+			kind = blockNumbered
+			text = append(text, markdownNumbered.FindStringSubmatch(line)[3])
+		case markdownBullet.MatchString(line):
+			if kind != blockParagraph {
+				flush()
+				kind = blockParagraph
+			}
+			text = append(text, markdownBullet.FindStringSubmatch(line)[1])
+		default:
+			if kind != blockParagraph {
+				flush()
+				kind = blockParagraph
+			}
+			text = append(text, trimmed)
+		}
+	}
+	flush()
+	return blocks
+}
+
+// markdownPlainText strips links, emphasis, and code spans from Markdown text.
+func markdownPlainText(text string) string {
+	text = markdownLink.ReplaceAllString(text, "$1")
+	return markdownInline(markdownEmphasis.ReplaceAllString(text, ""))
+}
+
+// htmlBlocks reads the headings, paragraphs, and ordered list items of an HTML
+// document. The text of an item includes its nested lists.
+func htmlBlocks(content string) ([]guidanceBlock, error) {
+	root, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	var blocks []guidanceBlock
+	add := func(kind, level int, n *html.Node) {
+		if t := htmlText(n); t != "" {
+			blocks = append(blocks, guidanceBlock{kind: kind, level: level, text: t})
+		}
+	}
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch n.DataAtom {
+			case atom.Head, atom.Script, atom.Style, atom.Nav, atom.Table:
+				return
+			case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+				level, _ := strconv.Atoi(n.Data[1:])
+				add(blockHeading, level, n)
+				return
+			case atom.P:
+				add(blockParagraph, 0, n)
+				return
+			case atom.Ol, atom.Ul:
+				kind := blockParagraph
+				if n.DataAtom == atom.Ol {
+					kind = blockNumbered
+				}
+				for c := n.FirstChild; c != nil; c = c.NextSibling {
+					if c.Type == html.ElementNode && c.DataAtom == atom.Li {
+						add(kind, 0, c)
+					}
+				}
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(root)
+	return blocks, nil
+}
+
+// htmlText returns the text of a node with whitespace collapsed.
+func htmlText(n *html.Node) string {
+	var b strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			b.WriteString(n.Data)
+		case n.Type == html.ElementNode && (n.DataAtom == atom.Script || n.DataAtom == atom.Style):
+			return
+		case n.Type == html.ElementNode && (n.DataAtom == atom.Br || n.DataAtom == atom.Li || n.DataAtom == atom.P):
+			b.WriteString(" ")
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+	}
+	collect(n)
+	return strings.Join(strings.Fields(b.String()), " ")
+}
@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testStandardMarkdown = `# Secure Development Standard
+
+This standard applies to every production service.
+2024 is the first year it is enforced.
+
+## 1. Access Control
+
+Who may change production systems.
+
+1. Require **multi-factor authentication** for all administrative access.
+2. Review access rights every quarter.
+   - Remove accounts of departed staff.
+
+## 2. Scope
+
+Definitions used in this standard.
+
+` + "```" + `
+1. not a requirement
+` + "```" + `
+
+## 3. Logging
+
+3.1 Retain audit logs for at least one year.
+3.2 Send security alerts to the [SOC](https://soc.example.com).
+`
+
+const testStandardHTML = `<html><head><title>ignored</title></head><body>
+<h1>Network Standard</h1>
+<h2>Segmentation</h2>
+<p>Separate environments.</p>
+<ol>
+  <li>Isolate production networks from development networks.</li>
+  <li>Deny inbound traffic by default.<ol><li>Document exceptions.</li></ol></li>
+</ol>
+<h2>Encryption</h2>
+<ul><li>Background on ciphers.</li></ul>
+<ol><li>Use TLS 1.2 or later for all traffic.</li></ol>
+</body></html>`
+
+func TestImportGuidanceDocument(t *testing.T) {
+	useTestSchema(t)
+
+	tests := []struct {
+		name             string
+		input            InputImportGuidanceDocument
+		wantErr          bool
+		errContains      string
+		wantCategories   []ImportedCategory
+		wantSkipped      []string
+		wantPlaceholders int
+		wantContains     []string
+	}{
+		{
+			name:        "missing document",
+			wantErr:     true,
+			errContains: "document_content or document_uri is required",
+		},
+		{
+			name:        "unsupported format",
+			input:       InputImportGuidanceDocument{DocumentContent: "# X\n1. Y\n", Format: "rst"},
+			wantErr:     true,
+			errContains: "unsupported format",
+		},
+		{
+			name:        "no numbered requirements",
+			input:       InputImportGuidanceDocument{DocumentContent: "# Title\n## Section\nProse only.\n"},
+			wantErr:     true,
+			errContains: "no numbered requirements",
+		},
+		{
+			name:  "markdown",
+			input: InputImportGuidanceDocument{DocumentContent: testStandardMarkdown, GuidanceID: "ORG-SDS"},
+			wantCategories: []ImportedCategory{
+				{ID: "ORG.01", Title: "Access Control", Guidelines: []string{"ORG.01.01", "ORG.01.02"}},
+				{ID: "ORG.02", Title: "Logging", Guidelines: []string{"ORG.02.01", "ORG.02.02"}},
+			},
+			wantSkipped:      []string{"Scope"},
+			wantPlaceholders: 2,
+			wantContains: []string{
+				"id: ORG-SDS",
+				"title: Secure Development Standard",
+				"description: This standard applies to every production service. 2024 is the first year it is enforced.",
+				"description: Who may change production systems.",
+				"objective: Require multi-factor authentication for all administrative access.",
+				"objective: Review access rights every quarter. Remove accounts of departed staff.",
+				"objective: Send security alerts to the SOC.",
+			},
+		},
+		{
+			name:  "html",
+			input: InputImportGuidanceDocument{DocumentContent: testStandardHTML, IDPrefix: "NET"},
+			wantCategories: []ImportedCategory{
+				{ID: "NET.01", Title: "Segmentation", Guidelines: []string{"NET.01.01", "NET.01.02"}},
+				{ID: "NET.02", Title: "Encryption", Guidelines: []string{"NET.02.01"}},
+			},
+			wantSkipped:      []string{},
+			wantPlaceholders: 2,
+			wantContains: []string{
+				"id: DRAFT-GUIDANCE",
+				"title: Network Standard",
+				"description: Background on ciphers.",
+				"objective: Deny inbound traffic by default. Document exceptions.",
+				"objective: Use TLS 1.2 or later for all traffic.",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := ImportGuidanceDocument(context.Background(), nil, tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, output.Valid, "guidance should validate: %v", output.Errors)
+			assert.Equal(t, tt.wantCategories, output.Categories)
+			assert.Equal(t, tt.wantSkipped, output.Skipped)
+			assert.Len(t, output.Placeholders, tt.wantPlaceholders, "placeholders: %v", output.Placeholders)
+			for _, want := range tt.wantContains {
+				assert.Contains(t, output.Content, want)
+			}
+		})
+	}
+}
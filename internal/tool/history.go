@@ -0,0 +1,425 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultHistoryLimit = 10
+	maxHistoryLimit     = 100
+
+	changeAdded   = "added"
+	changeRemoved = "removed"
+	changeChanged = "changed"
+)
+
+// MetadataGetArtifactHistory describes the GetArtifactHistory tool.
+var MetadataGetArtifactHistory = &mcp.Tool{
+	Name: "get_artifact_history",
+	Description: "Return the git history of a workspace artifact: the commits that changed it, with a semantic diff of " +
+		"each revision (entities added or removed by ID, fields changed). Set since to a tag or commit to see everything " +
+		"that changed since a release.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"path"},
+		"properties": map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "Artifact file within the workspace root, absolute or relative to it",
+			},
+			"since": map[string]interface{}{
+				"type":        "string",
+				"description": "Tag, branch, or commit to compare the current file against (e.g., 'v1.2.0')",
+			},
+			"limit": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"maximum":     maxHistoryLimit,
+				"description": fmt.Sprintf("Maximum number of revisions (default: %d)", defaultHistoryLimit),
+			},
+		},
+	},
+}
+
+// InputGetArtifactHistory is the input for the GetArtifactHistory tool.
+type InputGetArtifactHistory struct {
+	Path  string `json:"path"`
+	Since string `json:"since,omitempty"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+// ArtifactChange is one semantic difference between two versions of an artifact.
+type ArtifactChange struct {
+	Kind string `json:"kind"`
+	// Path locates the change; list items with an id are addressed as [id=...].
+	Path string `json:"path"`
+	// ID is set when a whole entity, such as a control, was added or removed.
+	ID  string `json:"id,omitempty"`
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+// ArtifactRevision is a commit that changed an artifact.
+type ArtifactRevision struct {
+	Commit  string `json:"commit"`
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+	Subject string `json:"subject"`
+	// Path is the file's path in the repository at this commit, which differs
+	// from the current path when the file was renamed.
+	Path    string           `json:"path"`
+	Changes []ArtifactChange `json:"changes"`
+	Summary string           `json:"summary"`
+}
+
+// OutputGetArtifactHistory is the output for the GetArtifactHistory tool.
+type OutputGetArtifactHistory struct {
+	Path      string             `json:"path"`
+	Revisions []ArtifactRevision `json:"revisions"`
+	// Changes compares the version at since with the file in the workspace.
+	Changes []ArtifactChange `json:"changes,omitempty"`
+	Message string           `json:"message"`
+}
+
+// GetArtifactHistory reports how a workspace artifact changed over its git history.
+func GetArtifactHistory(ctx context.Context, _ *mcp.CallToolRequest, input InputGetArtifactHistory) (*mcp.CallToolResult, OutputGetArtifactHistory, error) {
+	if input.Path == "" {
+		return nil, OutputGetArtifactHistory{}, fmt.Errorf("path is required")
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	if limit > maxHistoryLimit {
+		return nil, OutputGetArtifactHistory{}, fmt.Errorf("limit must be at most %d", maxHistoryLimit)
+	}
+
+	path := input.Path
+	if root := workspaceRoot(ctx); !filepath.IsAbs(path) && root != "" {
+		path = filepath.Join(root, path)
+	}
+	resolved, err := resolveWorkspacePath(ctx, path)
+	if err != nil {
+		return nil, OutputGetArtifactHistory{}, err
+	}
+	current, err := readArtifactFile(ctx, resolved)
+	if err != nil {
+		return nil, OutputGetArtifactHistory{}, err
+	}
+
+	repo, err := git.PlainOpenWithOptions(filepath.Dir(resolved), &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, OutputGetArtifactHistory{}, fmt.Errorf("%s is not in a git repository: %w", input.Path, err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, OutputGetArtifactHistory{}, fmt.Errorf("%s is not in a git repository: %w", input.Path, err)
+	}
+	rel, err := filepath.Rel(worktree.Filesystem.Root(), resolved)
+	if err != nil {
+		return nil, OutputGetArtifactHistory{}, err
+	}
+	rel = filepath.ToSlash(rel)
+
+	var since *object.Commit
+	if input.Since != "" {
+		since, err = resolveCommit(repo, input.Since)
+		if err != nil {
+			return nil, OutputGetArtifactHistory{}, fmt.Errorf("failed to read history of %s: %w", input.Path, err)
+		}
+	}
+	// One extra revision gives the oldest listed revision something to diff against
+	revisions, contents, err := fileHistory(ctx, repo, rel, since, limit+1)
+	if err != nil {
+		return nil, OutputGetArtifactHistory{}, fmt.Errorf("failed to read history of %s: %w", input.Path, err)
+	}
+
+	var base string
+	hasBase := false
+	if len(revisions) > limit {
+		base, hasBase = contents[limit], true
+		revisions, contents = revisions[:limit], contents[:limit]
+	} else if input.Since != "" {
+		basePath := rel
+		if len(revisions) > 0 {
+			basePath = revisions[len(revisions)-1].Path
+		}
+		if file, err := since.File(basePath); err == nil {
+			if content, err := file.Contents(); err == nil {
+				base, hasBase = content, true
+			}
+		}
+	}
+
+	output := OutputGetArtifactHistory{Path: rel, Revisions: []ArtifactRevision{}}
+	for i := range revisions {
+		r := &revisions[i]
+		switch {
+		case i+1 < len(revisions):
+			r.Changes, err = diffArtifactVersions(ctx, contents[i+1], contents[i])
+		case hasBase:
+			r.Changes, err = diffArtifactVersions(ctx, base, contents[i])
+		default:
+			r.Changes, r.Summary = []ArtifactChange{}, "created"
+		}
+		if err != nil {
+			r.Changes, r.Summary = []ArtifactChange{}, fmt.Sprintf("not comparable: %v", err)
+		}
+		if r.Summary == "" {
+			r.Summary = summarizeChanges(r.Changes)
+		}
+		output.Revisions = append(output.Revisions, *r)
+	}
+
+	if input.Since == "" {
+		output.Message = fmt.Sprintf("%d revision(s) of %s", len(output.Revisions), rel)
+		return nil, output, nil
+	}
+	if !hasBase {
+		return nil, OutputGetArtifactHistory{}, fmt.Errorf("%s does not exist at %s", rel, input.Since)
+	}
+	output.Changes, err = diffArtifactVersions(ctx, base, string(current))
+	if err != nil {
+		return nil, OutputGetArtifactHistory{}, err
+	}
+	output.Message = fmt.Sprintf("%s since %s: %s across %d revision(s)", rel, input.Since, summarizeChanges(output.Changes), len(output.Revisions))
+	return nil, output, nil
+}
+
+// resolveCommit returns the commit a tag, branch, or commit names.
+func resolveCommit(repo *git.Repository, revision string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, fmt.Errorf("unknown revision %q", revision)
+	}
+	if tag, err := repo.TagObject(*hash); err == nil {
+		return tag.Commit()
+	}
+	return repo.CommitObject(*hash)
+}
+
+// fileHistory walks the first-parent history of HEAD, stopping at the commits
+// reachable from since, and returns up to max commits that changed the file
+// at path, newest first, with the content of the file at each. Renames are
+// followed, so older revisions may have another path.
+func fileHistory(ctx context.Context, repo *git.Repository, path string, since *object.Commit, max int) ([]ArtifactRevision, []string, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, nil, err
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, nil, err
+	}
+	excluded := map[plumbing.Hash]bool{}
+	if since != nil {
+		err := object.NewCommitPreorderIter(since, nil, nil).ForEach(func(c *object.Commit) error {
+			excluded[c.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var revisions []ArtifactRevision
+	var contents []string
+	for commit != nil && len(revisions) < max && !excluded[commit.Hash] {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		file, err := commit.File(path)
+		if errors.Is(err, object.ErrFileNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		var parent *object.Commit
+		if commit.NumParents() > 0 {
+			if parent, err = commit.Parent(0); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		// previous is the path of the file in the parent, or empty when the commit added it
+		previous, changed := "", true
+		if parent != nil {
+			if before, err := parent.File(path); err == nil {
+				previous, changed = path, before.Hash != file.Hash
+			} else if previous, err = renamedFrom(ctx, parent, commit, path); err != nil {
+				return nil, nil, err
+			}
+		}
+		if changed {
+			content, err := file.Contents()
+			if err != nil {
+				return nil, nil, err
+			}
+			subject, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+			revisions = append(revisions, ArtifactRevision{
+				Commit:  commit.Hash.String(),
+				Author:  commit.Author.Name,
+				Date:    commit.Author.When.Format(time.RFC3339),
+				Subject: subject,
+				Path:    path,
+			})
+			contents = append(contents, content)
+		}
+		if previous == "" {
+			break
+		}
+		path, commit = previous, parent
+	}
+	return revisions, contents, nil
+}
+
+// renamedFrom returns the path in parent of the file that commit has at path,
+// or an empty string when commit added the file.
+func renamedFrom(ctx context.Context, parent, commit *object.Commit, path string) (string, error) {
+	from, err := parent.Tree()
+	if err != nil {
+		return "", err
+	}
+	to, err := commit.Tree()
+	if err != nil {
+		return "", err
+	}
+	changes, err := object.DiffTreeWithOptions(ctx, from, to, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return "", err
+	}
+	for _, change := range changes {
+		if change.To.Name == path && change.From.Name != "" {
+			return change.From.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// diffArtifactVersions compares two versions of an artifact field by field.
+// List items with an id are matched by id rather than position, so reordering
+// controls is not reported and an added control is one change, not many.
+func diffArtifactVersions(ctx context.Context, before, after string) ([]ArtifactChange, error) {
+	oldDoc, err := parseArtifact(ctx, before)
+	if err != nil {
+		return nil, fmt.Errorf("previous version: %w", err)
+	}
+	newDoc, err := parseArtifact(ctx, after)
+	if err != nil {
+		return nil, fmt.Errorf("new version: %w", err)
+	}
+	oldLeaves, oldEntities := map[string]string{}, map[string]string{}
+	newLeaves, newEntities := map[string]string{}, map[string]string{}
+	flattenArtifact(oldDoc, "$", oldLeaves, oldEntities)
+	flattenArtifact(newDoc, "$", newLeaves, newEntities)
+
+	changes := []ArtifactChange{}
+	var added, removed []string
+	for path, id := range newEntities {
+		if _, ok := oldEntities[path]; !ok {
+			changes = append(changes, ArtifactChange{Kind: changeAdded, Path: path, ID: id})
+			added = append(added, path)
+		}
+	}
+	for path, id := range oldEntities {
+		if _, ok := newEntities[path]; !ok {
+			changes = append(changes, ArtifactChange{Kind: changeRemoved, Path: path, ID: id})
+			removed = append(removed, path)
+		}
+	}
+	within := func(path string, entities []string) bool {
+		for _, e := range entities {
+			if strings.HasPrefix(path, e+".") || strings.HasPrefix(path, e+"[") {
+				return true
+			}
+		}
+		return false
+	}
+
+	for path, value := range newLeaves {
+		if within(path, added) {
+			continue
+		}
+		old, ok := oldLeaves[path]
+		switch {
+		case !ok:
+			changes = append(changes, ArtifactChange{Kind: changeAdded, Path: path, New: value})
+		case old != value:
+			changes = append(changes, ArtifactChange{Kind: changeChanged, Path: path, Old: old, New: value})
+		}
+	}
+	for path, value := range oldLeaves {
+		if _, ok := newLeaves[path]; !ok && !within(path, removed) {
+			changes = append(changes, ArtifactChange{Kind: changeRemoved, Path: path, Old: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Path != changes[j].Path {
+			return changes[i].Path < changes[j].Path
+		}
+		return changes[i].Kind < changes[j].Kind
+	})
+	return changes, nil
+}
+
+// flattenArtifact records every scalar in node by path, and every list item
+// with an id by its path. Empty mappings and lists are recorded as {} and [].
+func flattenArtifact(node interface{}, path string, leaves, entities map[string]string) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			leaves[path] = "{}"
+		}
+		for k, child := range v {
+			flattenArtifact(child, childPath(path, k), leaves, entities)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			leaves[path] = "[]"
+		}
+		for i, item := range v {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			if id := entityID(item); id != "" {
+				itemPath = fmt.Sprintf("%s[id=%s]", path, id)
+				entities[itemPath] = id
+			}
+			flattenArtifact(item, itemPath, leaves, entities)
+		}
+	case nil:
+		leaves[path] = "null"
+	default:
+		leaves[path] = strings.TrimSpace(fmt.Sprint(v))
+	}
+}
+
+// summarizeChanges counts changes by kind.
+func summarizeChanges(changes []ArtifactChange) string {
+	if len(changes) == 0 {
+		return "no semantic changes"
+	}
+	counts := map[string]int{}
+	for _, c := range changes {
+		counts[c.Kind]++
+	}
+	var parts []string
+	for _, kind := range []string{changeAdded, changeRemoved, changeChanged} {
+		if counts[kind] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[kind], kind))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,214 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	historyV1 = `title: Storage
+controls:
+  - id: ST.C01
+    title: Encrypt Buckets
+  - id: ST.C02
+    title: Log Access
+`
+	historyV2 = `title: Storage
+controls:
+  - id: ST.C02
+    title: Log All Access
+  - id: ST.C01
+    title: Encrypt Buckets
+  - id: ST.C03
+    title: Version Objects
+`
+	historyV3 = `title: Storage Catalog
+controls:
+  - id: ST.C02
+    title: Log All Access
+  - id: ST.C03
+    title: Version Objects
+`
+)
+
+// gitRepo creates a repository in a temporary directory and returns a
+// context serving it as the workspace root.
+func gitRepo(t *testing.T) (context.Context, string) {
+	t.Helper()
+	dir := t.TempDir()
+	ctx := withTestConfig(func(c *Config) { c.WorkspaceRoot = dir })
+	_, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	return ctx, dir
+}
+
+// gitWorktree returns the worktree of the repository in dir.
+func gitWorktree(t *testing.T, dir string) (*git.Repository, *git.Worktree) {
+	t.Helper()
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	return repo, worktree
+}
+
+// gitCommit commits the staged changes in dir with a fixed identity. Commits
+// are a minute apart so their order does not depend on the clock.
+func gitCommit(t *testing.T, dir, message string) {
+	t.Helper()
+	repo, worktree := gitWorktree(t, dir)
+	when := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	if head, err := repo.Head(); err == nil {
+		previous, err := repo.CommitObject(head.Hash())
+		require.NoError(t, err)
+		when = previous.Author.When.Add(time.Minute)
+	}
+	signature := &object.Signature{Name: "Test", Email: "test@example.com", When: when}
+	_, err := worktree.Commit(message, &git.CommitOptions{Author: signature, Committer: signature})
+	require.NoError(t, err)
+}
+
+// commitFile writes content to name and commits it.
+func commitFile(t *testing.T, dir, name, content, message string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	_, worktree := gitWorktree(t, dir)
+	_, err := worktree.Add(name)
+	require.NoError(t, err)
+	gitCommit(t, dir, message)
+}
+
+// tagHead tags the current commit in dir; opts makes an annotated tag.
+func tagHead(t *testing.T, dir, name string, opts *git.CreateTagOptions) {
+	t.Helper()
+	repo, _ := gitWorktree(t, dir)
+	head, err := repo.Head()
+	require.NoError(t, err)
+	_, err = repo.CreateTag(name, head.Hash(), opts)
+	require.NoError(t, err)
+}
+
+func TestGetArtifactHistory(t *testing.T) {
+	ctx, dir := gitRepo(t)
+	commitFile(t, dir, "catalog.yaml", historyV1, "Add catalog")
+	tagHead(t, dir, "v1", nil)
+	tagHead(t, dir, "release-1", &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+		Message: "First release",
+	})
+	commitFile(t, dir, "catalog.yaml", historyV2, "Add versioning")
+	commitFile(t, dir, "catalog.yaml", historyV3, "Retire encryption control")
+
+	tests := []struct {
+		name         string
+		input        InputGetArtifactHistory
+		wantErr      bool
+		errContains  string
+		wantSubjects []string
+		wantSummary  []string
+		wantChanges  []ArtifactChange
+	}{
+		{
+			name:        "missing path",
+			input:       InputGetArtifactHistory{},
+			wantErr:     true,
+			errContains: "path is required",
+		},
+		{
+			name:        "outside workspace",
+			input:       InputGetArtifactHistory{Path: "/etc/hosts"},
+			wantErr:     true,
+			errContains: "outside the workspace root",
+		},
+		{
+			name:        "unknown since",
+			input:       InputGetArtifactHistory{Path: "catalog.yaml", Since: "v9"},
+			wantErr:     true,
+			errContains: "failed to read history",
+		},
+		{
+			name:         "full history",
+			input:        InputGetArtifactHistory{Path: "catalog.yaml"},
+			wantSubjects: []string{"Retire encryption control", "Add versioning", "Add catalog"},
+			wantSummary:  []string{"1 removed, 1 changed", "1 added, 1 changed", "created"},
+		},
+		{
+			name:         "limited history diffs against the next older revision",
+			input:        InputGetArtifactHistory{Path: filepath.Join(dir, "catalog.yaml"), Limit: 1},
+			wantSubjects: []string{"Retire encryption control"},
+			wantSummary:  []string{"1 removed, 1 changed"},
+		},
+		{
+			name:         "since annotated tag",
+			input:        InputGetArtifactHistory{Path: "catalog.yaml", Since: "release-1"},
+			wantSubjects: []string{"Retire encryption control", "Add versioning"},
+			wantSummary:  []string{"1 removed, 1 changed", "1 added, 1 changed"},
+			wantChanges: []ArtifactChange{
+				{Kind: changeRemoved, Path: "$.controls[id=ST.C01]", ID: "ST.C01"},
+				{Kind: changeChanged, Path: "$.controls[id=ST.C02].title", Old: "Log Access", New: "Log All Access"},
+				{Kind: changeAdded, Path: "$.controls[id=ST.C03]", ID: "ST.C03"},
+				{Kind: changeChanged, Path: "$.title", Old: "Storage", New: "Storage Catalog"},
+			},
+		},
+		{
+			name:         "since release",
+			input:        InputGetArtifactHistory{Path: "catalog.yaml", Since: "v1"},
+			wantSubjects: []string{"Retire encryption control", "Add versioning"},
+			wantSummary:  []string{"1 removed, 1 changed", "1 added, 1 changed"},
+			wantChanges: []ArtifactChange{
+				{Kind: changeRemoved, Path: "$.controls[id=ST.C01]", ID: "ST.C01"},
+				{Kind: changeChanged, Path: "$.controls[id=ST.C02].title", Old: "Log Access", New: "Log All Access"},
+				{Kind: changeAdded, Path: "$.controls[id=ST.C03]", ID: "ST.C03"},
+				{Kind: changeChanged, Path: "$.title", Old: "Storage", New: "Storage Catalog"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := GetArtifactHistory(ctx, nil, tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "catalog.yaml", output.Path)
+			var subjects, summaries []string
+			for _, r := range output.Revisions {
+				subjects = append(subjects, r.Subject)
+				summaries = append(summaries, r.Summary)
+				assert.Len(t, r.Commit, 40)
+			}
+			assert.Equal(t, tt.wantSubjects, subjects)
+			assert.Equal(t, tt.wantSummary, summaries)
+			assert.Equal(t, tt.wantChanges, output.Changes)
+		})
+	}
+}
+
+func TestGetArtifactHistoryFollowsRenames(t *testing.T) {
+	ctx, dir := gitRepo(t)
+	commitFile(t, dir, "old.yaml", historyV1, "Add catalog")
+	_, worktree := gitWorktree(t, dir)
+	_, err := worktree.Move("old.yaml", "catalog.yaml")
+	require.NoError(t, err)
+	gitCommit(t, dir, "Rename catalog")
+
+	_, output, err := GetArtifactHistory(ctx, nil, InputGetArtifactHistory{Path: "catalog.yaml"})
+	require.NoError(t, err)
+	require.Len(t, output.Revisions, 2)
+	assert.Equal(t, "catalog.yaml", output.Revisions[0].Path)
+	assert.Equal(t, "no semantic changes", output.Revisions[0].Summary)
+	assert.Equal(t, "old.yaml", output.Revisions[1].Path)
+}
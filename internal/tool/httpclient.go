@@ -0,0 +1,236 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultRetries is how many times a failed outbound GET is retried by default.
+	DefaultRetries = 2
+	// DefaultRetryBackoff is the delay before the first retry; it doubles on each attempt.
+	DefaultRetryBackoff = 500 * time.Millisecond
+	// DefaultRetryMaxBackoff caps the delay between retries.
+	DefaultRetryMaxBackoff = 10 * time.Second
+)
+
+// HTTPConfig configures every outbound HTTP request the server makes, including
+// lexicon, template, and catalog fetches and CUE registry access. Proxies are
+// taken from the HTTPS_PROXY, HTTP_PROXY, and NO_PROXY environment variables.
+type HTTPConfig struct {
+	// CABundle is a PEM file of additional trusted certificate authorities,
+	// such as the CA of a TLS-intercepting proxy.
+	CABundle string
+	// ClientCert and ClientKey are a PEM client certificate and key for mutual TLS.
+	ClientCert string
+	ClientKey  string
+	// Timeout bounds each attempt of a request, including reading the response body.
+	Timeout time.Duration
+	// Retries is how many times a failed GET is retried after a network error,
+	// timeout, 429, or 5xx response.
+	Retries int
+	// RetryBackoff is the delay before the first retry. Later retries double it,
+	// up to RetryMaxBackoff, and each delay is jittered.
+	RetryBackoff    time.Duration
+	RetryMaxBackoff time.Duration
+}
+
+// DefaultHTTPConfig returns the default outbound HTTP configuration.
+func DefaultHTTPConfig() HTTPConfig {
+	return HTTPConfig{
+		Timeout:         httpTimeout,
+		Retries:         DefaultRetries,
+		RetryBackoff:    DefaultRetryBackoff,
+		RetryMaxBackoff: DefaultRetryMaxBackoff,
+	}
+}
+
+// httpTransport carries every outbound request of the process. It is shared
+// by the servers of all tenants, as are the caches it fills.
+var httpTransport = mustHTTPTransport(DefaultHTTPConfig())
+
+// ConfigureHTTP builds the shared outbound transport from config.
+func ConfigureHTTP(config HTTPConfig) error {
+	transport, err := newHTTPTransport(config)
+	if err != nil {
+		return err
+	}
+	httpTransport = transport
+	artifactHTTPClient = newHTTPClient()
+	return nil
+}
+
+// newHTTPClient returns a client that uses the shared transport. Timeouts are
+// enforced per attempt by the transport.
+func newHTTPClient() *http.Client {
+	return &http.Client{Transport: httpTransport}
+}
+
+// mustHTTPTransport builds the default transport, which cannot fail.
+func mustHTTPTransport(config HTTPConfig) http.RoundTripper {
+	transport, err := newHTTPTransport(config)
+	if err != nil {
+		panic(err)
+	}
+	return transport
+}
+
+// newHTTPTransport builds a transport with the configured trust, client
+// certificate, timeout, and retries.
+func newHTTPTransport(config HTTPConfig) (http.RoundTripper, error) {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.Proxy = http.ProxyFromEnvironment
+
+	if config.CABundle != "" || config.ClientCert != "" || config.ClientKey != "" {
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+		if config.CABundle != "" {
+			pem, err := os.ReadFile(config.CABundle)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+			}
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("CA bundle %s contains no PEM certificates", config.CABundle)
+			}
+			tlsConfig.RootCAs = pool
+		}
+
+		if config.ClientCert != "" || config.ClientKey != "" {
+			if config.ClientCert == "" || config.ClientKey == "" {
+				return nil, fmt.Errorf("client certificate and key must be set together")
+			}
+			cert, err := tls.LoadX509KeyPair(config.ClientCert, config.ClientKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load client certificate: %w", err)
+			}
+			tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+		base.TLSClientConfig = tlsConfig
+	}
+
+	if config.Retries < 0 {
+		return nil, fmt.Errorf("retries must not be negative")
+	}
+	if config.RetryBackoff < 0 || config.RetryMaxBackoff < 0 {
+		return nil, fmt.Errorf("retry backoff must not be negative")
+	}
+	return &retryTransport{
+		next:       base,
+		timeout:    config.Timeout,
+		retries:    config.Retries,
+		backoff:    config.RetryBackoff,
+		maxBackoff: config.RetryMaxBackoff,
+	}, nil
+}
+
+// retryTransport bounds each attempt of a request by a timeout and retries
+// idempotent requests that fail with a network error, timeout, 429, or 5xx
+// response, backing off exponentially with jitter between attempts.
+type retryTransport struct {
+	next       http.RoundTripper
+	timeout    time.Duration
+	retries    int
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	retries := t.retries
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		retries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.attempt(req)
+		if !retryableResponse(resp, err) || attempt >= retries || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		delay := t.delay(attempt, resp)
+		if resp != nil {
+			resp.Body.Close()
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// attempt sends req once, bounded by the per-attempt timeout. The deadline also
+// covers reading the response body.
+func (t *retryTransport) attempt(req *http.Request) (*http.Response, error) {
+	if t.timeout <= 0 {
+		return t.next.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// delay returns how long to wait before retrying after the given attempt,
+// honoring a Retry-After header within the backoff cap.
+func (t *retryTransport) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, t.maxBackoff)
+		}
+	}
+	if t.backoff == 0 {
+		return 0
+	}
+	d := t.backoff << attempt
+	if d <= 0 || d > t.maxBackoff {
+		d = t.maxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	// Jitter within [d/2, d] so concurrent clients do not retry in lockstep
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryableResponse reports whether a request should be retried. Unknown hosts
+// and untrusted certificates fail the same way on every attempt.
+func retryableResponse(resp *http.Response, err error) bool {
+	if err != nil {
+		var dnsErr *net.DNSError
+		var certErr *tls.CertificateVerificationError
+		return !(errors.As(err, &dnsErr) && dnsErr.IsNotFound) && !errors.As(err, &certErr)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// cancelOnClose releases a per-attempt context once the response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useHTTPConfig applies config to the shared transport for the duration of a test.
+func useHTTPConfig(t *testing.T, config HTTPConfig) error {
+	t.Helper()
+	originalTransport, originalClient := httpTransport, artifactHTTPClient
+	t.Cleanup(func() {
+		httpTransport, artifactHTTPClient = originalTransport, originalClient
+	})
+	return ConfigureHTTP(config)
+}
+
+func TestConfigureHTTPCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("term: Control\n"))
+	}))
+	defer server.Close()
+
+	require.NoError(t, useHTTPConfig(t, HTTPConfig{Timeout: httpTimeout}))
+	_, err := fetchURL(context.Background(), server.URL)
+	assert.Error(t, err, "untrusted certificate should be rejected")
+
+	dir := t.TempDir()
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	writeTestFile(t, dir, "ca.pem", string(cert))
+
+	require.NoError(t, useHTTPConfig(t, HTTPConfig{Timeout: httpTimeout, CABundle: filepath.Join(dir, "ca.pem")}))
+	body, err := fetchURL(context.Background(), server.URL)
+	require.NoError(t, err, "CA bundle should be trusted")
+	assert.Equal(t, "term: Control\n", string(body))
+}
+
+func TestConfigureHTTPErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "empty.pem", "not a certificate")
+
+	tests := []struct {
+		name    string
+		config  HTTPConfig
+		wantErr string
+	}{
+		{name: "missing CA bundle", config: HTTPConfig{CABundle: filepath.Join(dir, "missing.pem")}, wantErr: "failed to read CA bundle"},
+		{name: "CA bundle without certificates", config: HTTPConfig{CABundle: filepath.Join(dir, "empty.pem")}, wantErr: "contains no PEM certificates"},
+		{name: "client cert without key", config: HTTPConfig{ClientCert: filepath.Join(dir, "empty.pem")}, wantErr: "must be set together"},
+		{name: "negative retries", config: HTTPConfig{Retries: -1}, wantErr: "must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorContains(t, useHTTPConfig(t, tt.config), tt.wantErr)
+		})
+	}
+}
+
+func TestRetryTransport(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		retries   int
+		wantCalls int32
+		wantCode  int
+	}{
+		{name: "recovers within retries", retries: 2, wantCalls: 3, wantCode: http.StatusOK},
+		{name: "gives up after retries", retries: 1, wantCalls: 2, wantCode: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			client := &http.Client{Transport: &retryTransport{next: http.DefaultTransport, retries: tt.retries}}
+			resp, err := client.Get(server.URL)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+			assert.Equal(t, tt.wantCalls, calls.Load())
+		})
+	}
+}
+
+func TestRetryTransportTimeout(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			// Stall the first attempt past the per-attempt timeout
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &retryTransport{next: http.DefaultTransport, timeout: 100 * time.Millisecond, retries: 1}}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err, "timed out attempt should be retried")
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err, "body should be readable after the attempt returns")
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestRetryTransportDelay(t *testing.T) {
+	transport := &retryTransport{backoff: 100 * time.Millisecond, maxBackoff: time.Second}
+
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		d := transport.delay(attempt, nil)
+		assert.GreaterOrEqual(t, d, want/2, "attempt %d delay should be at least half the backoff", attempt)
+		assert.LessOrEqual(t, d, want, "attempt %d delay should not exceed the backoff", attempt)
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"30"}}}
+	assert.Equal(t, time.Second, transport.delay(0, resp), "Retry-After should be capped")
+	resp.Header.Set("Retry-After", "0")
+	assert.Equal(t, time.Duration(0), transport.delay(0, resp), "Retry-After should be honored")
+}
+
+func TestRetryableResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *http.Response
+		err  error
+		want bool
+	}{
+		{name: "ok", resp: &http.Response{StatusCode: http.StatusOK}},
+		{name: "not found", resp: &http.Response{StatusCode: http.StatusNotFound}},
+		{name: "too many requests", resp: &http.Response{StatusCode: http.StatusTooManyRequests}, want: true},
+		{name: "server error", resp: &http.Response{StatusCode: http.StatusBadGateway}, want: true},
+		{name: "connection error", err: errors.New("connection reset by peer"), want: true},
+		{name: "unknown host", err: &net.DNSError{Err: "no such host", IsNotFound: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, retryableResponse(tt.resp, tt.err))
+		})
+	}
+}
@@ -0,0 +1,227 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	changeEdit   = "edit"
+	changeRemove = "remove"
+
+	impactBreaking = "breaking"
+	impactReview   = "review"
+)
+
+// MetadataImpactOfChange describes the ImpactOfChange tool.
+var MetadataImpactOfChange = &mcp.Tool{
+	Name: "impact_of_change",
+	Description: "Report every policy, mapping, assessment plan, and evaluation entry affected by a proposed edit to, " +
+		"or removal of, a control before the change is committed.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"control_id", "artifacts"},
+		"properties": map[string]interface{}{
+			"control_id": map[string]interface{}{
+				"type":        "string",
+				"description": "ID of the control being changed (e.g., 'CCC.C01')",
+			},
+			"change": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{changeEdit, changeRemove},
+				"description": "Kind of change proposed (default: edit)",
+			},
+			"proposed_control": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML of the control after the edit; used to detect renamed IDs and dropped requirements",
+			},
+			"artifacts": artifactInputSchema,
+		},
+	},
+}
+
+// InputImpactOfChange is the input for the ImpactOfChange tool.
+type InputImpactOfChange struct {
+	ControlID       string          `json:"control_id"`
+	Change          string          `json:"change,omitempty"`
+	ProposedControl string          `json:"proposed_control,omitempty"`
+	Artifacts       []ArtifactInput `json:"artifacts"`
+}
+
+// ImpactedItem is a single location affected by a control change.
+type ImpactedItem struct {
+	Artifact     string `json:"artifact"`
+	ArtifactKind string `json:"artifact_kind,omitempty"`
+	Category     string `json:"category"`
+	Path         string `json:"path"`
+	Reference    string `json:"reference"`
+	Impact       string `json:"impact"`
+	Depth        int    `json:"depth"`
+}
+
+// OutputImpactOfChange is the output for the ImpactOfChange tool.
+type OutputImpactOfChange struct {
+	ControlID string         `json:"control_id"`
+	Change    string         `json:"change"`
+	Affected  []ImpactedItem `json:"affected"`
+	Summary   map[string]int `json:"summary"`
+	Message   string         `json:"message"`
+}
+
+// ImpactOfChange walks the relationship graph of the supplied artifacts and reports
+// everything that depends on the changed control.
+func ImpactOfChange(ctx context.Context, _ *mcp.CallToolRequest, input InputImpactOfChange) (*mcp.CallToolResult, OutputImpactOfChange, error) {
+	if input.ControlID == "" {
+		return nil, OutputImpactOfChange{}, fmt.Errorf("control_id is required")
+	}
+	if len(input.Artifacts) == 0 {
+		return nil, OutputImpactOfChange{}, fmt.Errorf("artifacts is required")
+	}
+
+	change := input.Change
+	if change == "" {
+		change = changeEdit
+	}
+	if change != changeEdit && change != changeRemove {
+		return nil, OutputImpactOfChange{}, fmt.Errorf("unsupported change %q: must be %q or %q", change, changeEdit, changeRemove)
+	}
+
+	graph, err := buildArtifactGraph(ctx, input.Artifacts)
+	if err != nil {
+		return nil, OutputImpactOfChange{}, err
+	}
+
+	defs := graph.entities[input.ControlID]
+	if len(defs) == 0 {
+		return nil, OutputImpactOfChange{}, fmt.Errorf("control %s not found in the supplied artifacts", input.ControlID)
+	}
+
+	// Seed the walk with the control and everything nested under it
+	seeds := map[string]string{input.ControlID: impactReview}
+	for _, def := range defs {
+		for _, id := range graph.descendants(def) {
+			seeds[id] = impactReview
+		}
+	}
+
+	if change == changeRemove {
+		for id := range seeds {
+			seeds[id] = impactBreaking
+		}
+	} else if input.ProposedControl != "" {
+		if err := classifyEdit(ctx, seeds, input.ControlID, input.ProposedControl); err != nil {
+			return nil, OutputImpactOfChange{}, err
+		}
+	}
+
+	affected := walkImpact(graph, seeds)
+	summary := make(map[string]int)
+	for _, item := range affected {
+		summary[item.Category]++
+	}
+
+	output := OutputImpactOfChange{
+		ControlID: input.ControlID,
+		Change:    change,
+		Affected:  affected,
+		Summary:   summary,
+		Message:   fmt.Sprintf("%d location(s) affected by %s of %s", len(affected), change, input.ControlID),
+	}
+	return nil, output, nil
+}
+
+// classifyEdit marks IDs that disappear in the proposed control as breaking.
+func classifyEdit(ctx context.Context, seeds map[string]string, controlID, proposed string) error {
+	doc, err := parseArtifact(ctx, proposed)
+	if err != nil {
+		return fmt.Errorf("invalid proposed_control: %w", err)
+	}
+
+	kept := map[string]bool{}
+	walkArtifact(doc, "$", func(_ string, value interface{}) {
+		if id := entityID(value); id != "" {
+			kept[id] = true
+		}
+	})
+
+	for id := range seeds {
+		if !kept[id] {
+			seeds[id] = impactBreaking
+		}
+	}
+	if !kept[controlID] {
+		seeds[controlID] = impactBreaking
+	}
+	return nil
+}
+
+// walkImpact performs a breadth-first walk from the seed IDs. References whose
+// enclosing entity lives in another artifact propagate the impact to that entity.
+func walkImpact(graph *artifactGraph, seeds map[string]string) []ImpactedItem {
+	type queued struct {
+		id     string
+		impact string
+		depth  int
+	}
+
+	var queue []queued
+	visited := map[string]bool{}
+	for id, impact := range seeds {
+		queue = append(queue, queued{id: id, impact: impact, depth: 1})
+		visited[id] = true
+	}
+	sort.Slice(queue, func(i, j int) bool { return queue[i].id < queue[j].id })
+
+	var affected []ImpactedItem
+	seen := map[string]bool{}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, ref := range graph.references[current.id] {
+			key := ref.Artifact + "|" + ref.Path
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			affected = append(affected, ImpactedItem{
+				Artifact:     ref.Artifact,
+				ArtifactKind: ref.Kind,
+				Category:     impactCategory(ref),
+				Path:         ref.Path,
+				Reference:    current.id,
+				Impact:       current.impact,
+				Depth:        current.depth,
+			})
+
+			if ref.Owner != "" && !visited[ref.Owner] && !graph.definedIn(current.id, ref.Artifact) {
+				visited[ref.Owner] = true
+				queue = append(queue, queued{id: ref.Owner, impact: impactReview, depth: current.depth + 1})
+			}
+		}
+	}
+
+	return affected
+}
+
+// impactCategory classifies a reference by the artifact and section it appears in.
+func impactCategory(ref graphReference) string {
+	switch {
+	case strings.Contains(ref.Path, "assessment-plans"):
+		return "assessment_plan"
+	case ref.Kind == "EvaluationLog":
+		return "evaluation_entry"
+	case strings.Contains(ref.Path, "mappings"):
+		return "mapping"
+	case ref.Kind == "Policy":
+		return "policy"
+	}
+	return "reference"
+}
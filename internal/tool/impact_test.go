@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImpactOfChange(t *testing.T) {
+	artifacts := loadTestArtifacts(t, "good-ccc.yaml", "policy.yaml", "evaluation-log.yaml")
+
+	tests := []struct {
+		name           string
+		input          InputImpactOfChange
+		wantErr        bool
+		errContains    string
+		validateOutput func(t *testing.T, output OutputImpactOfChange)
+	}{
+		{
+			name:        "missing control_id",
+			input:       InputImpactOfChange{Artifacts: artifacts},
+			wantErr:     true,
+			errContains: "control_id is required",
+		},
+		{
+			name:        "missing artifacts",
+			input:       InputImpactOfChange{ControlID: "CCC.C01"},
+			wantErr:     true,
+			errContains: "artifacts is required",
+		},
+		{
+			name:        "unknown control",
+			input:       InputImpactOfChange{ControlID: "CCC.C99", Artifacts: artifacts},
+			wantErr:     true,
+			errContains: "not found",
+		},
+		{
+			name:        "unsupported change",
+			input:       InputImpactOfChange{ControlID: "CCC.C01", Change: "rename", Artifacts: artifacts},
+			wantErr:     true,
+			errContains: "unsupported change",
+		},
+		{
+			name:  "removal reaches plans and evaluation entries",
+			input: InputImpactOfChange{ControlID: "CCC.C01", Change: "remove", Artifacts: artifacts},
+			validateOutput: func(t *testing.T, output OutputImpactOfChange) {
+				assert.Equal(t, "remove", output.Change)
+				assert.Equal(t, 1, output.Summary["assessment_plan"], "policy assessment plan should be affected")
+				assert.Equal(t, 3, output.Summary["evaluation_entry"], "evaluation entries should be affected")
+				for _, item := range output.Affected {
+					if item.Depth == 1 {
+						assert.Equal(t, impactBreaking, item.Impact, "direct references should break on removal")
+					}
+				}
+
+				var transitive bool
+				for _, item := range output.Affected {
+					if item.Reference == "AP-C01-TR01" {
+						transitive = true
+						assert.Equal(t, 2, item.Depth, "plan reference should be reached transitively")
+					}
+				}
+				assert.True(t, transitive, "evaluation entry referencing the plan should be reported")
+			},
+		},
+		{
+			name: "edit dropping a requirement is breaking only for that requirement",
+			input: InputImpactOfChange{
+				ControlID: "CCC.C01",
+				ProposedControl: `id: CCC.C01
+title: Prevent Unencrypted Requests
+assessment-requirements:
+  - id: CCC.C01.TR02
+    text: SSH traffic MUST use SSHv2`,
+				Artifacts: artifacts,
+			},
+			validateOutput: func(t *testing.T, output OutputImpactOfChange) {
+				assert.Equal(t, "edit", output.Change, "change should default to edit")
+				for _, item := range output.Affected {
+					switch item.Reference {
+					case "CCC.C01.TR01":
+						assert.Equal(t, impactBreaking, item.Impact)
+					case "CCC.C01":
+						assert.Equal(t, impactReview, item.Impact)
+					}
+				}
+			},
+		},
+		{
+			name:  "control with no dependents",
+			input: InputImpactOfChange{ControlID: "CCC.C10", Artifacts: artifacts},
+			validateOutput: func(t *testing.T, output OutputImpactOfChange) {
+				assert.Empty(t, output.Affected, "nothing references CCC.C10")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := ImpactOfChange(context.Background(), nil, tt.input)
+
+			if tt.wantErr {
+				require.Error(t, err, "should return error")
+				assert.Contains(t, err.Error(), tt.errContains, "error should contain expected message")
+				return
+			}
+
+			require.NoError(t, err, "should not return error")
+			if tt.validateOutput != nil {
+				tt.validateOutput(t, output)
+			}
+		})
+	}
+}
+
+// loadTestArtifacts reads the named files from test-data as inline artifacts.
+func loadTestArtifacts(t *testing.T, names ...string) []ArtifactInput {
+	t.Helper()
+	var artifacts []ArtifactInput
+	for _, name := range names {
+		content, err := os.ReadFile(filepath.Join("test-data", name))
+		require.NoError(t, err, "should be able to read test data file")
+		artifacts = append(artifacts, ArtifactInput{Name: name, Content: string(content)})
+	}
+	return artifacts
+}
@@ -0,0 +1,758 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Scanner output formats ingest_scan_results reads.
+const (
+	scanFormatSARIF = "sarif"
+	scanFormatTrivy = "trivy"
+	scanFormatARF   = "arf"
+)
+
+var scanFormats = []string{scanFormatSARIF, scanFormatTrivy, scanFormatARF}
+
+// Results of an EvaluationLog, as defined by the #Result schema.
+const (
+	resultNotRun        = "Not Run"
+	resultPassed        = "Passed"
+	resultFailed        = "Failed"
+	resultNeedsReview   = "Needs Review"
+	resultNotApplicable = "Not Applicable"
+	resultUnknown       = "Unknown"
+)
+
+// resultRank orders results so that the most severe result of the
+// findings for a requirement is the result of its assessment.
+var resultRank = map[string]int{
+	resultNotRun:        0,
+	resultNotApplicable: 1,
+	resultPassed:        2,
+	resultNeedsReview:   3,
+	resultUnknown:       4,
+	resultFailed:        5,
+}
+
+const (
+	// defaultIngestMinScore is the similarity a rule needs to be mapped to a
+	// requirement heuristically.
+	defaultIngestMinScore = 0.3
+	// maxFindingMessages bounds the messages kept in one assessment log.
+	maxFindingMessages = 5
+)
+
+// MetadataIngestScanResults describes the IngestScanResults tool.
+var MetadataIngestScanResults = &mcp.Tool{
+	Name: "ingest_scan_results",
+	Description: "Convert scanner output (SARIF, Trivy JSON, or an OpenSCAP ARF or XCCDF result) into a Layer 5 EvaluationLog " +
+		"against the assessment requirements of a ControlCatalog. Rules are mapped to requirements by the given mapping, " +
+		"and the remaining rules by the similarity of their descriptions to requirement text. " +
+		"The log is validated against the schema and the rules that could not be mapped are reported.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"scan_content", "catalog_content"},
+		"properties": map[string]interface{}{
+			"scan_content": map[string]interface{}{
+				"type":        "string",
+				"description": "Scanner output to convert",
+			},
+			"format": map[string]interface{}{
+				"type":        "string",
+				"enum":        scanFormats,
+				"description": "Format of scan_content (default: detected from the content)",
+			},
+			"catalog_content": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML content of the ControlCatalog the findings are evaluated against",
+			},
+			"mapping": map[string]interface{}{
+				"type": "object",
+				"description": "Assessment requirement ID for each rule ID. Keys may be patterns such as 'CVE-*'; " +
+					"an exact rule ID takes precedence over patterns",
+				"additionalProperties": map[string]interface{}{"type": "string"},
+			},
+			"heuristic": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Map rules missing from mapping by the similarity of their descriptions to requirement text (default: true)",
+			},
+			"min_score": map[string]interface{}{
+				"type":        "number",
+				"minimum":     0,
+				"maximum":     1,
+				"description": fmt.Sprintf("Similarity a heuristic mapping needs (default: %.1f)", defaultIngestMinScore),
+			},
+			"log_id": map[string]interface{}{
+				"type":        "string",
+				"description": "Metadata id of the log (default: the catalog id followed by -SCAN)",
+			},
+		},
+	},
+}
+
+// InputIngestScanResults is the input for the IngestScanResults tool.
+type InputIngestScanResults struct {
+	ScanContent    string            `json:"scan_content"`
+	Format         string            `json:"format,omitempty"`
+	CatalogContent string            `json:"catalog_content"`
+	Mapping        map[string]string `json:"mapping,omitempty"`
+	Heuristic      *bool             `json:"heuristic,omitempty"`
+	MinScore       float64           `json:"min_score,omitempty"`
+	LogID          string            `json:"log_id,omitempty"`
+}
+
+// RuleMapping records how a scanner rule was mapped to a requirement.
+type RuleMapping struct {
+	Rule        string `json:"rule"`
+	Control     string `json:"control"`
+	Requirement string `json:"requirement"`
+	// Heuristic is set when the rule was mapped by similarity rather than by
+	// the given mapping; such mappings deserve a review.
+	Heuristic bool    `json:"heuristic,omitempty"`
+	Score     float64 `json:"score,omitempty"`
+}
+
+// OutputIngestScanResults is the output for the IngestScanResults tool.
+type OutputIngestScanResults struct {
+	Content     string        `json:"content"`
+	Format      string        `json:"format"`
+	Scanner     string        `json:"scanner,omitempty"`
+	Findings    int           `json:"findings"`
+	Evaluations int           `json:"evaluations"`
+	Mappings    []RuleMapping `json:"mappings"`
+	// Unmapped lists the rules whose findings are left out of the log.
+	Unmapped []string `json:"unmapped,omitempty"`
+	Valid    bool     `json:"valid"`
+	Errors   []string `json:"errors,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// scanFinding is the result of one scanner rule for one target.
+type scanFinding struct {
+	Rule    string
+	Result  string
+	Message string
+}
+
+// scanReport is scanner output in a format-independent form.
+type scanReport struct {
+	Scanner  string
+	Findings []scanFinding
+	// RuleText describes each rule, for heuristic mapping.
+	RuleText map[string]string
+}
+
+// ingestRequirement is an assessment requirement findings can be mapped to.
+type ingestRequirement struct {
+	Control      string
+	ControlTitle string
+}
+
+// IngestScanResults converts scanner output into an evaluation log.
+func IngestScanResults(ctx context.Context, _ *mcp.CallToolRequest, input InputIngestScanResults) (*mcp.CallToolResult, OutputIngestScanResults, error) {
+	if input.ScanContent == "" {
+		return nil, OutputIngestScanResults{}, fmt.Errorf("scan_content is required")
+	}
+	if int64(len(input.ScanContent)) > serverConfig(ctx).MaxArtifactSize {
+		return nil, OutputIngestScanResults{}, fmt.Errorf("scan_content is %d bytes, exceeding the %d byte limit", len(input.ScanContent), serverConfig(ctx).MaxArtifactSize)
+	}
+	if input.CatalogContent == "" {
+		return nil, OutputIngestScanResults{}, fmt.Errorf("catalog_content is required")
+	}
+	minScore := input.MinScore
+	if minScore == 0 {
+		minScore = defaultIngestMinScore
+	}
+	if minScore < 0 || minScore > 1 {
+		return nil, OutputIngestScanResults{}, fmt.Errorf("min_score must be between 0 and 1")
+	}
+
+	format := input.Format
+	if format == "" {
+		format = detectScanFormat(input.ScanContent)
+		if format == "" {
+			return nil, OutputIngestScanResults{}, fmt.Errorf("could not detect the scan format; set format to one of %s", strings.Join(scanFormats, ", "))
+		}
+	}
+	var report *scanReport
+	var err error
+	switch format {
+	case scanFormatSARIF:
+		report, err = parseSARIF(input.ScanContent)
+	case scanFormatTrivy:
+		report, err = parseTrivy(input.ScanContent)
+	case scanFormatARF:
+		report, err = parseARF(input.ScanContent)
+	default:
+		return nil, OutputIngestScanResults{}, fmt.Errorf("unsupported format %q; expected one of %s", format, strings.Join(scanFormats, ", "))
+	}
+	if err != nil {
+		return nil, OutputIngestScanResults{}, fmt.Errorf("failed to parse %s scan: %w", format, err)
+	}
+	if len(report.Findings) == 0 {
+		return nil, OutputIngestScanResults{}, fmt.Errorf("the %s scan has no findings", format)
+	}
+
+	catalog, err := parseArtifact(ctx, input.CatalogContent)
+	if err != nil {
+		return nil, OutputIngestScanResults{}, err
+	}
+	catalogID := metadataID(catalog)
+	if catalogID == "" {
+		return nil, OutputIngestScanResults{}, fmt.Errorf("catalog has no metadata id to reference")
+	}
+	requirements, targets := ingestRequirements(catalog)
+	if len(requirements) == 0 {
+		return nil, OutputIngestScanResults{}, fmt.Errorf("catalog has no assessment requirements")
+	}
+	for rule, requirement := range input.Mapping {
+		if _, ok := requirements[requirement]; !ok {
+			return nil, OutputIngestScanResults{}, fmt.Errorf("mapping of %s: requirement %s not found in the catalog", rule, requirement)
+		}
+		if _, err := path.Match(rule, ""); err != nil {
+			return nil, OutputIngestScanResults{}, fmt.Errorf("mapping of %s: invalid pattern: %w", rule, err)
+		}
+	}
+
+	output := OutputIngestScanResults{Format: format, Scanner: report.Scanner, Findings: len(report.Findings), Mappings: []RuleMapping{}}
+	heuristic := input.Heuristic == nil || *input.Heuristic
+	var idf map[string]float64
+	if heuristic {
+		idf = crosswalkIDF(targets)
+	}
+
+	// Findings are grouped by requirement, in catalog order
+	mapped := map[string]string{}
+	unmapped := map[string]bool{}
+	grouped := map[string][]scanFinding{}
+	for _, finding := range report.Findings {
+		requirement, seen := mapped[finding.Rule]
+		if !seen && !unmapped[finding.Rule] {
+			m, ok := mapScanRule(finding.Rule, input.Mapping)
+			if !ok && heuristic {
+				m, ok = matchScanRule(finding.Rule, report.RuleText[finding.Rule], targets, idf, minScore)
+			}
+			if !ok {
+				unmapped[finding.Rule] = true
+				continue
+			}
+			m.Control = requirements[m.Requirement].Control
+			output.Mappings = append(output.Mappings, m)
+			requirement = m.Requirement
+			mapped[finding.Rule] = requirement
+		}
+		grouped[requirement] = append(grouped[requirement], finding)
+	}
+	for rule := range unmapped {
+		output.Unmapped = append(output.Unmapped, rule)
+	}
+	sort.Strings(output.Unmapped)
+	if len(grouped) == 0 {
+		return nil, OutputIngestScanResults{}, fmt.Errorf("none of the %d rule(s) in the scan could be mapped to a requirement; supply a mapping", len(output.Unmapped))
+	}
+
+	scanner := report.Scanner
+	if scanner == "" {
+		scanner = format
+	}
+	var evaluations []interface{}
+	var controlOrder []string
+	byControl := map[string][]string{}
+	for _, target := range targets {
+		if _, ok := grouped[target.ID]; !ok {
+			continue
+		}
+		control := requirements[target.ID].Control
+		if _, ok := byControl[control]; !ok {
+			controlOrder = append(controlOrder, control)
+		}
+		byControl[control] = append(byControl[control], target.ID)
+	}
+	for _, control := range controlOrder {
+		var logs []interface{}
+		controlResult := resultNotRun
+		for _, requirement := range byControl[control] {
+			findings := grouped[requirement]
+			result := worstResult(findings)
+			controlResult = moreSevere(controlResult, result)
+			logs = append(logs, assessmentLog(catalogID, requirement, scanner, findings, result))
+		}
+		name := requirements[byControl[control][0]].ControlTitle
+		if name == "" {
+			name = control
+		}
+		evaluations = append(evaluations, yaml.MapSlice{
+			{Key: "name", Value: name},
+			{Key: "result", Value: controlResult},
+			{Key: "control", Value: entryMapping(catalogID, control)},
+			{Key: "assessment-logs", Value: logs},
+		})
+	}
+	output.Evaluations = len(evaluations)
+
+	logID := input.LogID
+	if logID == "" {
+		logID = catalogID + "-SCAN"
+	}
+	evaluationLog := yaml.MapSlice{
+		{Key: "metadata", Value: yaml.MapSlice{
+			{Key: "id", Value: logID},
+			{Key: "description", Value: fmt.Sprintf("Results of %s imported from %s output and evaluated against %s.", scanner, format, catalogID)},
+			{Key: "author", Value: yaml.MapSlice{
+				{Key: "id", Value: scannerID(scanner)},
+				{Key: "name", Value: scanner},
+				{Key: "type", Value: "Software"},
+			}},
+		}},
+		{Key: "evaluations", Value: evaluations},
+	}
+	out, err := yaml.MarshalWithOptions(evaluationLog, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return nil, OutputIngestScanResults{}, fmt.Errorf("failed to encode evaluation log: %w", err)
+	}
+	output.Content = string(out)
+
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputIngestScanResults{}, err
+	}
+	validation, err := validateAgainstSchema(schema, "#EvaluationLog", output.Content)
+	if err != nil {
+		return nil, OutputIngestScanResults{}, err
+	}
+	output.Valid = validation.Valid
+	output.Errors = validation.Errors
+
+	var guessed int
+	for _, m := range output.Mappings {
+		if m.Heuristic {
+			guessed++
+		}
+	}
+	output.Message = fmt.Sprintf("Converted %d finding(s) into %d evaluation(s); %s", output.Findings, output.Evaluations, strings.ToLower(validation.Message))
+	if guessed > 0 {
+		output.Message += fmt.Sprintf("; review the %d heuristic mapping(s)", guessed)
+	}
+	if len(output.Unmapped) > 0 {
+		output.Message += fmt.Sprintf("; %d unmapped rule(s) were left out", len(output.Unmapped))
+	}
+	return nil, output, nil
+}
+
+// ingestRequirements indexes the assessment requirements of a catalog by ID
+// and returns their terms, in catalog order, for heuristic mapping.
+func ingestRequirements(catalog map[string]interface{}) (map[string]ingestRequirement, []crosswalkControl) {
+	requirements := map[string]ingestRequirement{}
+	var targets []crosswalkControl
+	controls, _ := catalog["controls"].([]interface{})
+	for _, item := range controls {
+		control, ok := item.(map[string]interface{})
+		if !ok || entityID(control) == "" {
+			continue
+		}
+		title, _ := control["title"].(string)
+		objective, _ := control["objective"].(string)
+		for _, r := range mapList(control["assessment-requirements"]) {
+			id := entityID(r)
+			if id == "" {
+				continue
+			}
+			requirements[id] = ingestRequirement{Control: entityID(control), ControlTitle: title}
+
+			text, _ := r["text"].(string)
+			terms := map[string]float64{}
+			for _, term := range crosswalkTerms(title) {
+				terms[term]++
+			}
+			for _, term := range crosswalkTerms(objective) {
+				terms[term]++
+			}
+			for _, term := range crosswalkTerms(text) {
+				terms[term] += crosswalkTitleWeight
+			}
+			targets = append(targets, crosswalkControl{ID: id, Title: text, terms: terms})
+		}
+	}
+	return requirements, targets
+}
+
+// mapScanRule looks a rule up in the user-supplied mapping: an exact rule ID
+// first, then the lexically first matching pattern.
+func mapScanRule(rule string, mapping map[string]string) (RuleMapping, bool) {
+	if requirement, ok := mapping[rule]; ok {
+		return RuleMapping{Rule: rule, Requirement: requirement}, true
+	}
+	patterns := make([]string, 0, len(mapping))
+	for pattern := range mapping {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, rule); ok {
+			return RuleMapping{Rule: rule, Requirement: mapping[pattern]}, true
+		}
+	}
+	return RuleMapping{}, false
+}
+
+// matchScanRule maps a rule to the requirement whose text is most similar to
+// the rule's description.
+func matchScanRule(rule, text string, targets []crosswalkControl, idf map[string]float64, minScore float64) (RuleMapping, bool) {
+	terms := map[string]float64{}
+	for _, term := range crosswalkTerms(text) {
+		terms[term]++
+	}
+	if len(terms) == 0 {
+		return RuleMapping{}, false
+	}
+	vector := crosswalkVector(terms, idf)
+	best, bestScore := "", 0.0
+	for _, target := range targets {
+		if score := cosineSimilarity(vector, crosswalkVector(target.terms, idf)); score > bestScore {
+			best, bestScore = target.ID, score
+		}
+	}
+	if best == "" || bestScore < minScore {
+		return RuleMapping{}, false
+	}
+	return RuleMapping{Rule: rule, Requirement: best, Heuristic: true, Score: math.Round(bestScore*100) / 100}, true
+}
+
+// assessmentLog records the findings for one requirement.
+func assessmentLog(catalogID, requirement, scanner string, findings []scanFinding, result string) yaml.MapSlice {
+	var rules, messages []string
+	seen := map[string]bool{}
+	for _, f := range findings {
+		if !seen[f.Rule] {
+			seen[f.Rule] = true
+			rules = append(rules, f.Rule)
+		}
+		if f.Message != "" && len(messages) < maxFindingMessages {
+			messages = append(messages, fmt.Sprintf("%s: %s", f.Rule, f.Message))
+		}
+	}
+	if len(findings) > maxFindingMessages {
+		messages = append(messages, fmt.Sprintf("... %d finding(s) in total", len(findings)))
+	}
+	log := yaml.MapSlice{
+		{Key: "requirement", Value: entryMapping(catalogID, requirement)},
+		{Key: "description", Value: fmt.Sprintf("%d %s finding(s) for %s", len(findings), scanner, strings.Join(rules, ", "))},
+		{Key: "result", Value: result},
+	}
+	if len(messages) > 0 {
+		log = append(log, yaml.MapItem{Key: "message", Value: strings.Join(messages, "\n")})
+	}
+	return log
+}
+
+// worstResult returns the most severe result of the findings.
+func worstResult(findings []scanFinding) string {
+	result := resultNotRun
+	for _, f := range findings {
+		result = moreSevere(result, f.Result)
+	}
+	return result
+}
+
+func moreSevere(a, b string) string {
+	if resultRank[b] > resultRank[a] {
+		return b
+	}
+	return a
+}
+
+// scannerID turns a scanner name into a metadata author id.
+func scannerID(name string) string {
+	id := strings.Join(crosswalkWord.FindAllString(strings.ToLower(name), -1), "-")
+	if id == "" {
+		return "scanner"
+	}
+	return id
+}
+
+// detectScanFormat recognizes SARIF and Trivy JSON by their top-level fields
+// and ARF and XCCDF by their XML root.
+func detectScanFormat(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "<") {
+		return scanFormatARF
+	}
+	var probe struct {
+		Version       string          `json:"version"`
+		Runs          json.RawMessage `json:"runs"`
+		SchemaVersion json.RawMessage `json:"SchemaVersion"`
+		Results       json.RawMessage `json:"Results"`
+	}
+	if err := json.Unmarshal([]byte(trimmed), &probe); err != nil {
+		return ""
+	}
+	switch {
+	case probe.Runs != nil:
+		return scanFormatSARIF
+	case probe.SchemaVersion != nil || probe.Results != nil:
+		return scanFormatTrivy
+	}
+	return ""
+}
+
+// parseSARIF reads the results of every run of a SARIF log. Rules a run
+// declares but reports no results for passed.
+func parseSARIF(content string) (*scanReport, error) {
+	var log struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Name  string `json:"name"`
+					Rules []struct {
+						ID               string        `json:"id"`
+						Name             string        `json:"name"`
+						ShortDescription *sarifMessage `json:"shortDescription"`
+						FullDescription  *sarifMessage `json:"fullDescription"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string       `json:"ruleId"`
+				Kind      string       `json:"kind"`
+				Level     string       `json:"level"`
+				Message   sarifMessage `json:"message"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal([]byte(content), &log); err != nil {
+		return nil, err
+	}
+	if len(log.Runs) == 0 {
+		return nil, fmt.Errorf("log has no runs")
+	}
+
+	report := &scanReport{RuleText: map[string]string{}}
+	for _, run := range log.Runs {
+		if report.Scanner == "" {
+			report.Scanner = run.Tool.Driver.Name
+		}
+		reported := map[string]bool{}
+		for _, r := range run.Results {
+			if r.RuleID == "" {
+				continue
+			}
+			reported[r.RuleID] = true
+			message := r.Message.Text
+			if len(r.Locations) > 0 && r.Locations[0].PhysicalLocation.ArtifactLocation.URI != "" {
+				message = strings.TrimSpace(message + " (" + r.Locations[0].PhysicalLocation.ArtifactLocation.URI + ")")
+			}
+			report.Findings = append(report.Findings, scanFinding{Rule: r.RuleID, Result: sarifResult(r.Kind, r.Level), Message: message})
+		}
+		for _, rule := range run.Tool.Driver.Rules {
+			if rule.ID == "" {
+				continue
+			}
+			report.RuleText[rule.ID] = strings.TrimSpace(strings.Join([]string{rule.Name, rule.ShortDescription.text(), rule.FullDescription.text()}, " "))
+			if !reported[rule.ID] {
+				report.Findings = append(report.Findings, scanFinding{Rule: rule.ID, Result: resultPassed})
+			}
+		}
+	}
+	return report, nil
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+func (m *sarifMessage) text() string {
+	if m == nil {
+		return ""
+	}
+	return m.Text
+}
+
+// sarifResult maps the kind and level of a SARIF result to a Gemara result.
+// A result without a kind is a failure, unless its level is none.
+func sarifResult(kind, level string) string {
+	switch kind {
+	case "pass":
+		return resultPassed
+	case "notApplicable":
+		return resultNotApplicable
+	case "review", "open":
+		return resultNeedsReview
+	case "informational":
+		return resultPassed
+	}
+	switch level {
+	case "none":
+		return resultPassed
+	case "note":
+		return resultNeedsReview
+	}
+	return resultFailed
+}
+
+// parseTrivy reads the vulnerabilities, misconfigurations, and secrets of a
+// Trivy JSON report. Every vulnerability and secret is a failure.
+func parseTrivy(content string) (*scanReport, error) {
+	var report struct {
+		ArtifactName string `json:"ArtifactName"`
+		Results      []struct {
+			Target          string `json:"Target"`
+			Vulnerabilities []struct {
+				VulnerabilityID  string `json:"VulnerabilityID"`
+				PkgName          string `json:"PkgName"`
+				InstalledVersion string `json:"InstalledVersion"`
+				Severity         string `json:"Severity"`
+				Title            string `json:"Title"`
+			} `json:"Vulnerabilities"`
+			Misconfigurations []struct {
+				ID          string `json:"ID"`
+				Title       string `json:"Title"`
+				Description string `json:"Description"`
+				Message     string `json:"Message"`
+				Status      string `json:"Status"`
+				Severity    string `json:"Severity"`
+			} `json:"Misconfigurations"`
+			Secrets []struct {
+				RuleID   string `json:"RuleID"`
+				Title    string `json:"Title"`
+				Severity string `json:"Severity"`
+			} `json:"Secrets"`
+		} `json:"Results"`
+	}
+	if err := json.Unmarshal([]byte(content), &report); err != nil {
+		return nil, err
+	}
+
+	scan := &scanReport{Scanner: "Trivy", RuleText: map[string]string{}}
+	for _, result := range report.Results {
+		for _, v := range result.Vulnerabilities {
+			scan.RuleText[v.VulnerabilityID] = v.Title
+			scan.Findings = append(scan.Findings, scanFinding{
+				Rule:    v.VulnerabilityID,
+				Result:  resultFailed,
+				Message: fmt.Sprintf("%s %s %s in %s", v.Severity, v.PkgName, v.InstalledVersion, result.Target),
+			})
+		}
+		for _, m := range result.Misconfigurations {
+			scan.RuleText[m.ID] = strings.TrimSpace(m.Title + " " + m.Description)
+			finding := scanFinding{Rule: m.ID, Result: resultFailed, Message: fmt.Sprintf("%s %s in %s", m.Severity, m.Message, result.Target)}
+			switch m.Status {
+			case "PASS":
+				finding = scanFinding{Rule: m.ID, Result: resultPassed}
+			case "EXCEPTION":
+				finding.Result = resultNotApplicable
+			}
+			scan.Findings = append(scan.Findings, finding)
+		}
+		for _, s := range result.Secrets {
+			scan.RuleText[s.RuleID] = s.Title
+			scan.Findings = append(scan.Findings, scanFinding{
+				Rule:    s.RuleID,
+				Result:  resultFailed,
+				Message: fmt.Sprintf("%s secret in %s", s.Severity, result.Target),
+			})
+		}
+	}
+	return scan, nil
+}
+
+// parseARF reads the rule results of an OpenSCAP ARF report or XCCDF result
+// file, with rule titles from the benchmark when the report includes it.
+func parseARF(content string) (*scanReport, error) {
+	report := &scanReport{RuleText: map[string]string{}}
+	decoder := xml.NewDecoder(bytes.NewReader([]byte(content)))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "Rule":
+			var rule struct {
+				ID          string `xml:"id,attr"`
+				Title       string `xml:"title"`
+				Description string `xml:"description"`
+			}
+			if err := decoder.DecodeElement(&rule, &start); err != nil {
+				return nil, err
+			}
+			report.RuleText[rule.ID] = strings.Join(strings.Fields(rule.Title+" "+rule.Description), " ")
+		case "TestResult":
+			if report.Scanner == "" {
+				for _, attr := range start.Attr {
+					if attr.Name.Local == "test-system" {
+						report.Scanner = attr.Value
+					}
+				}
+			}
+		case "rule-result":
+			var result struct {
+				IDRef    string `xml:"idref,attr"`
+				Severity string `xml:"severity,attr"`
+				Result   string `xml:"result"`
+			}
+			if err := decoder.DecodeElement(&result, &start); err != nil {
+				return nil, err
+			}
+			status, ok := xccdfResult(result.Result)
+			if !ok {
+				continue
+			}
+			finding := scanFinding{Rule: result.IDRef, Result: status}
+			if status == resultFailed {
+				finding.Message = result.Severity + " severity rule failed"
+			}
+			report.Findings = append(report.Findings, finding)
+		}
+	}
+	if report.Scanner == "" {
+		report.Scanner = "OpenSCAP"
+	}
+	return report, nil
+}
+
+// xccdfResult maps an XCCDF rule result to a Gemara result. Rules that were
+// not selected or are informational are not part of the evaluation.
+func xccdfResult(result string) (string, bool) {
+	switch strings.TrimSpace(result) {
+	case "pass", "fixed":
+		return resultPassed, true
+	case "fail":
+		return resultFailed, true
+	case "notapplicable":
+		return resultNotApplicable, true
+	case "notchecked":
+		return resultNotRun, true
+	case "error", "unknown":
+		return resultUnknown, true
+	}
+	return "", false
+}
@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSARIF = `{
+  "version": "2.1.0",
+  "runs": [{
+    "tool": {"driver": {"name": "tfsec", "rules": [
+      {"id": "AWS-0089", "shortDescription": {"text": "Listener does not use TLS 1.2 encrypted traffic"}},
+      {"id": "AWS-0090", "shortDescription": {"text": "Bucket replication across regions is not enabled"}},
+      {"id": "CUSTOM-1", "shortDescription": {"text": "Lambda runtime is deprecated"}}
+    ]}},
+    "results": [
+      {"ruleId": "AWS-0089", "level": "error", "message": {"text": "Listener uses plain HTTP"},
+       "locations": [{"physicalLocation": {"artifactLocation": {"uri": "main.tf"}}}]},
+      {"ruleId": "CUSTOM-1", "level": "warning", "message": {"text": "nodejs12.x"}}
+    ]
+  }]
+}`
+
+const testTrivy = `{
+  "SchemaVersion": 2,
+  "ArtifactName": "app:1.0",
+  "Results": [{
+    "Target": "app:1.0 (alpine 3.18)",
+    "Vulnerabilities": [
+      {"VulnerabilityID": "CVE-2024-0001", "PkgName": "openssl", "InstalledVersion": "3.1.0", "Severity": "HIGH"}
+    ],
+    "Misconfigurations": [
+      {"ID": "DS002", "Title": "Image user should not be root", "Status": "PASS", "Severity": "HIGH"}
+    ]
+  }]
+}`
+
+const testARF = `<?xml version="1.0" encoding="UTF-8"?>
+<arf:asset-report-collection xmlns:arf="http://scap.nist.gov/schema/asset-reporting-format/1.1">
+  <arf:reports>
+    <arf:report id="xccdf1">
+      <arf:content>
+        <TestResult xmlns="http://checklists.nist.gov/xccdf/1.2" id="xccdf_org.open-scap_testresult_default" test-system="cpe:/a:redhat:openscap:1.3.8">
+          <rule-result idref="xccdf_rule_sshd_use_approved_ciphers" severity="medium"><result>fail</result></rule-result>
+          <rule-result idref="xccdf_rule_sshd_disable_root_login" severity="high"><result>pass</result></rule-result>
+          <rule-result idref="xccdf_rule_aide_installed" severity="low"><result>notselected</result></rule-result>
+        </TestResult>
+      </arf:content>
+    </arf:report>
+  </arf:reports>
+</arf:asset-report-collection>`
+
+func TestIngestScanResults(t *testing.T) {
+	useTestSchema(t)
+	catalog, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err)
+	off := false
+
+	tests := []struct {
+		name            string
+		input           InputIngestScanResults
+		wantErr         string
+		wantFormat      string
+		wantMappings    []RuleMapping
+		wantUnmapped    []string
+		wantEvaluations int
+		wantContains    []string
+	}{
+		{
+			name:       "SARIF with a heuristic mapping",
+			input:      InputIngestScanResults{ScanContent: testSARIF, Mapping: map[string]string{"AWS-0090": "CCC.C08.TR01"}},
+			wantFormat: scanFormatSARIF,
+			wantMappings: []RuleMapping{
+				{Rule: "AWS-0089", Control: "CCC.C01", Requirement: "CCC.C01.TR01", Heuristic: true},
+				{Rule: "AWS-0090", Control: "CCC.C08", Requirement: "CCC.C08.TR01"},
+			},
+			wantUnmapped:    []string{"CUSTOM-1"},
+			wantEvaluations: 2,
+			wantContains:    []string{"result: Failed", "result: Passed", "AWS-0089: Listener uses plain HTTP (main.tf)", "name: tfsec"},
+		},
+		{
+			name: "Trivy with patterns",
+			input: InputIngestScanResults{ScanContent: testTrivy, Heuristic: &off, Mapping: map[string]string{
+				"CVE-*": "CCC.C01.TR01",
+				"DS*":   "CCC.C09.TR01",
+			}},
+			wantFormat: scanFormatTrivy,
+			wantMappings: []RuleMapping{
+				{Rule: "CVE-2024-0001", Control: "CCC.C01", Requirement: "CCC.C01.TR01"},
+				{Rule: "DS002", Control: "CCC.C09", Requirement: "CCC.C09.TR01"},
+			},
+			wantEvaluations: 2,
+			wantContains:    []string{"HIGH openssl 3.1.0 in app:1.0 (alpine 3.18)", "id: trivy"},
+		},
+		{
+			name: "ARF skips unselected rules",
+			input: InputIngestScanResults{ScanContent: testARF, Heuristic: &off, Mapping: map[string]string{
+				"xccdf_rule_sshd_*": "CCC.C01.TR02",
+			}},
+			wantFormat: scanFormatARF,
+			wantMappings: []RuleMapping{
+				{Rule: "xccdf_rule_sshd_use_approved_ciphers", Control: "CCC.C01", Requirement: "CCC.C01.TR02"},
+				{Rule: "xccdf_rule_sshd_disable_root_login", Control: "CCC.C01", Requirement: "CCC.C01.TR02"},
+			},
+			wantEvaluations: 1,
+			wantContains:    []string{"2 cpe:/a:redhat:openscap:1.3.8 finding(s)", "medium severity rule failed"},
+		},
+		{
+			name:    "nothing mapped",
+			input:   InputIngestScanResults{ScanContent: testTrivy, Heuristic: &off},
+			wantErr: "none of the 2 rule(s) in the scan could be mapped",
+		},
+		{
+			name:    "mapping to an unknown requirement",
+			input:   InputIngestScanResults{ScanContent: testTrivy, Mapping: map[string]string{"CVE-*": "CCC.C99.TR01"}},
+			wantErr: "requirement CCC.C99.TR01 not found in the catalog",
+		},
+		{
+			name:    "undetectable format",
+			input:   InputIngestScanResults{ScanContent: `{"findings": []}`},
+			wantErr: "could not detect the scan format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input.CatalogContent = string(catalog)
+			_, output, err := IngestScanResults(context.Background(), nil, tt.input)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, output.Valid, "ingested log should validate: %v", output.Errors)
+			assert.Equal(t, tt.wantFormat, output.Format)
+			for i := range output.Mappings {
+				output.Mappings[i].Score = 0
+			}
+			assert.ElementsMatch(t, tt.wantMappings, output.Mappings)
+			assert.Equal(t, tt.wantUnmapped, output.Unmapped)
+			assert.Equal(t, tt.wantEvaluations, output.Evaluations)
+			for _, want := range tt.wantContains {
+				assert.Contains(t, output.Content, want)
+			}
+		})
+	}
+}
+
+func TestDetectScanFormat(t *testing.T) {
+	assert.Equal(t, scanFormatSARIF, detectScanFormat(testSARIF))
+	assert.Equal(t, scanFormatTrivy, detectScanFormat(testTrivy))
+	assert.Equal(t, scanFormatARF, detectScanFormat(testARF))
+	assert.Equal(t, "", detectScanFormat("metadata: {}"))
+}
@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"fmt"
+	"strings"
+)
+
+// layerDefinitions lists the schema definitions that make up each Gemara
+// layer, in the order of gemaraLayers. Layers without definitions in the
+// schema are described by the lexicon only.
+var layerDefinitions = [][]string{
+	{"#GuidanceDocument"},
+	{"#ControlCatalog"},
+	{"#Policy"},
+	{"#EvaluationPlan", "#EvaluationLog"},
+	nil,
+	nil,
+}
+
+// writeLayerInstructions describes the Gemara layers and the definitions that
+// belong to each.
+func writeLayerInstructions(b *strings.Builder) {
+	b.WriteString("Gemara organizes security and compliance artifacts in layers:\n")
+	for i, name := range gemaraLayers {
+		fmt.Fprintf(b, "- Layer %d, %s", i+1, name)
+		if definitions := layerDefinitions[i]; len(definitions) > 0 {
+			fmt.Fprintf(b, ": %s", strings.Join(definitions, ", "))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("Artifacts build on the layers before them: a Policy imports ControlCatalogs and " +
+		"an EvaluationLog records the results of a plan.\n")
+}
+
+// writeToolInstructions lists the tools with the first sentence of their
+// description, which says when to use each.
+func writeToolInstructions(b *strings.Builder, entries []toolEntry) {
+	b.WriteString("Tools:\n")
+	for _, e := range entries {
+		fmt.Fprintf(b, "- %s: %s\n", e.tool.Name, firstSentence(e.tool.Description))
+	}
+}
+
+// writeURIInstructions lists the URI schemes accepted by artifact_uri and the
+// resources the server exposes under its current configuration for the
+// workspace at root.
+func writeURIInstructions(b *strings.Builder, config *Config, root string) {
+	b.WriteString("artifact_uri accepts:\n")
+	if root != "" {
+		fmt.Fprintf(b, "- file:// paths within the workspace %s\n", root)
+	}
+	b.WriteString("- https:// URLs serving YAML or JSON\n")
+	fmt.Fprintf(b, "- %s examples\n", examplesResourceURITemplate)
+
+	b.WriteString("Resources:\n")
+	fmt.Fprintf(b, "- %s: the Gemara lexicon of terms\n", LexiconResourceURIAlias)
+	fmt.Fprintf(b, "- %s: a definition as CUE or JSON Schema\n", schemaResourceURITemplate)
+	fmt.Fprintf(b, "- %s: valid example artifacts for few-shot prompting\n", examplesResourceURITemplate)
+	if len(bundleCatalogResources()) > 0 {
+		fmt.Fprintf(b, "- %s{name}: community catalogs from the offline bundle\n", catalogResourcePrefix)
+	}
+	if len(config.Federation) > 0 {
+		fmt.Fprintf(b, "- %s{name}: catalogs composed from several sources\n", federatedResourcePrefix)
+	}
+	if root != "" {
+		fmt.Fprintf(b, "- %s: compliance summary of the workspace evaluation logs\n", PostureResourceURI)
+	}
+}
+
+// writePromptInstructions lists the prompts, which clients usually offer to
+// users directly.
+func writePromptInstructions(b *strings.Builder) {
+	b.WriteString("Prompts:\n")
+	for _, p := range wizardPrompts() {
+		fmt.Fprintf(b, "- %s: %s\n", p.Name, firstSentence(p.Description))
+	}
+}
+
+// firstSentence returns text up to the end of its first sentence.
+func firstSentence(text string) string {
+	if i := strings.Index(text, ". "); i >= 0 {
+		return text[:i+1]
+	}
+	return text
+}
@@ -0,0 +1,521 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Supported issue trackers.
+const (
+	TrackerGitHub = "github"
+	TrackerJira   = "jira"
+)
+
+const (
+	// DefaultJiraIssueType is the issue type of Jira issues created for findings.
+	DefaultJiraIssueType = "Task"
+	// findingLabel marks every issue created for a finding.
+	findingLabel = "gemara-finding"
+	// fingerprintLabelPrefix starts the label that identifies the finding of an issue.
+	fingerprintLabelPrefix = "gemara-fp-"
+)
+
+// IssueTrackerConfig configures where create_findings_issues files issues.
+// The tool is only registered when Tracker is set.
+type IssueTrackerConfig struct {
+	// Tracker is TrackerGitHub or TrackerJira.
+	Tracker string
+	// Repo is the owner/name of the GitHub repository to file issues in. The
+	// API URL and token are those of GitHub.
+	Repo string
+	// JiraURL is the base URL of the Jira site, such as https://example.atlassian.net.
+	JiraURL string
+	// JiraProject is the key of the Jira project to file issues in.
+	JiraProject string
+	// JiraIssueType is the type of the Jira issues created.
+	JiraIssueType string
+	// JiraUser and JiraToken authenticate to Jira: with basic auth when the
+	// user is set, or as a bearer token otherwise.
+	JiraUser  string
+	JiraToken string
+}
+
+// Validate checks that the configured tracker has what it needs.
+func (c IssueTrackerConfig) Validate() error {
+	switch c.Tracker {
+	case "":
+		return nil
+	case TrackerGitHub:
+		if !githubRepoName.MatchString(c.Repo) {
+			return fmt.Errorf("the github issue tracker needs a repository as owner/name, got %q", c.Repo)
+		}
+	case TrackerJira:
+		if c.JiraURL == "" || c.JiraProject == "" {
+			return fmt.Errorf("the jira issue tracker needs a site URL and a project key")
+		}
+		if _, err := url.Parse(c.JiraURL); err != nil {
+			return fmt.Errorf("invalid Jira URL %q: %w", c.JiraURL, err)
+		}
+	default:
+		return fmt.Errorf("unknown issue tracker %q (available: %s, %s)", c.Tracker, TrackerGitHub, TrackerJira)
+	}
+	return nil
+}
+
+// MetadataCreateFindingsIssues describes the CreateFindingsIssues tool.
+var MetadataCreateFindingsIssues = &mcp.Tool{
+	Name: "create_findings_issues",
+	Description: "Create or update one issue per failing control of an EvaluationLog in the configured issue tracker " +
+		"(GitHub Issues or Jira). Issues are deduplicated by a fingerprint label derived from the catalog and control, " +
+		"so running it again updates, or reopens, the existing issue instead of filing a new one.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"artifact_content": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML content of the EvaluationLog",
+			},
+			"artifact_uri": map[string]interface{}{
+				"type": "string",
+				"description": "URI of the EvaluationLog instead of inline content: file:// (within the workspace root), " +
+					"https://, or gemara://examples/{definition}/{n}",
+			},
+			"results": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Evaluation results that count as failing (default: [Failed])",
+			},
+			"labels": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Extra labels to add to every issue",
+			},
+			"dry_run": dryRunProperty,
+		},
+	},
+}
+
+// InputCreateFindingsIssues is the input for the CreateFindingsIssues tool.
+type InputCreateFindingsIssues struct {
+	ArtifactContent string   `json:"artifact_content,omitempty"`
+	ArtifactURI     string   `json:"artifact_uri,omitempty"`
+	Results         []string `json:"results,omitempty"`
+	Labels          []string `json:"labels,omitempty"`
+	DryRun          bool     `json:"dry_run,omitempty"`
+}
+
+// FindingIssue is the issue filed for one failing control.
+type FindingIssue struct {
+	Catalog     string `json:"catalog"`
+	Control     string `json:"control"`
+	Fingerprint string `json:"fingerprint"`
+	// Action is created, updated, or reopened; in a dry run, what would be done.
+	Action string `json:"action"`
+	Key    string `json:"key"`
+	URL    string `json:"url,omitempty"`
+}
+
+// OutputCreateFindingsIssues is the output for the CreateFindingsIssues tool.
+type OutputCreateFindingsIssues struct {
+	Tracker string         `json:"tracker"`
+	Issues  []FindingIssue `json:"issues"`
+	DryRun  bool           `json:"dry_run,omitempty"`
+	// Changes lists the changes made, or in a dry run, those that would be.
+	Changes []PlannedChange `json:"changes,omitempty"`
+	Message string          `json:"message"`
+}
+
+// findingIssueContent is what an issue for a finding says.
+type findingIssueContent struct {
+	Fingerprint string
+	Title       string
+	Body        string
+	Labels      []string
+}
+
+// existingIssue is an issue already filed for a fingerprint.
+type existingIssue struct {
+	Key    string
+	URL    string
+	Closed bool
+	// Body is the current description, used to show what an update changes.
+	Body string
+}
+
+// issueTracker files issues in one tracker.
+type issueTracker interface {
+	// find returns the issue labeled with the fingerprint label, if any.
+	find(ctx context.Context, label string) (*existingIssue, error)
+	create(ctx context.Context, content findingIssueContent) (existingIssue, error)
+	// update replaces the content of an issue, reopening it if it is closed.
+	update(ctx context.Context, issue existingIssue, content findingIssueContent) error
+}
+
+// CreateFindingsIssues files an issue per failing control of an evaluation log.
+func CreateFindingsIssues(ctx context.Context, req *mcp.CallToolRequest, input InputCreateFindingsIssues) (*mcp.CallToolResult, OutputCreateFindingsIssues, error) {
+	tracker, err := newIssueTracker(ctx)
+	if err != nil {
+		return nil, OutputCreateFindingsIssues{}, err
+	}
+	content, err := artifactInputContent(ctx, input.ArtifactContent, input.ArtifactURI)
+	if err != nil {
+		return nil, OutputCreateFindingsIssues{}, err
+	}
+	doc, err := parseArtifact(ctx, content)
+	if err != nil {
+		return nil, OutputCreateFindingsIssues{}, err
+	}
+	if kind := artifactKind(doc); kind != "EvaluationLog" {
+		if kind == "" {
+			kind = "unknown"
+		}
+		return nil, OutputCreateFindingsIssues{}, fmt.Errorf("expected an EvaluationLog, got %s", kind)
+	}
+
+	failing := map[string]bool{}
+	for _, r := range input.Results {
+		failing[r] = true
+	}
+	if len(failing) == 0 {
+		failing["Failed"] = true
+	}
+
+	output := OutputCreateFindingsIssues{Tracker: serverConfig(ctx).IssueTracker.Tracker, Issues: []FindingIssue{}, DryRun: dryRun(ctx, input.DryRun)}
+	// Every issue is looked up first, so that the changes can be reviewed
+	// and approved as a whole before any of them is made
+	var existingIssues []*existingIssue
+	var contents []findingIssueContent
+	counts := map[string]int{}
+	for _, e := range findingEvaluations(doc, failing) {
+		issueContent := findingIssue(doc, e, input.Labels)
+		control, _ := e["control"].(map[string]interface{})
+		issue := FindingIssue{
+			Catalog:     stringField(control, "reference-id"),
+			Control:     mappingEntryID(control),
+			Fingerprint: issueContent.Fingerprint,
+		}
+
+		existing, err := tracker.find(ctx, fingerprintLabelPrefix+issueContent.Fingerprint)
+		if err != nil {
+			return nil, OutputCreateFindingsIssues{}, fmt.Errorf("failed to look up the issue for %s: %w", issue.Control, err)
+		}
+		change := PlannedChange{Action: "create", Target: output.Tracker + " issue", Detail: issueContent.Title, Diff: lineDiff("", issueContent.Body)}
+		issue.Action = "created"
+		if existing != nil {
+			change = PlannedChange{Action: "update", Target: existing.Key, Detail: issueContent.Title, Diff: lineDiff(existing.Body, issueContent.Body)}
+			issue.Action, issue.Key, issue.URL = "updated", existing.Key, existing.URL
+			if existing.Closed {
+				issue.Action, change.Action = "reopened", "reopen"
+			}
+		}
+		counts[issue.Action]++
+		output.Issues = append(output.Issues, issue)
+		output.Changes = append(output.Changes, change)
+		existingIssues = append(existingIssues, existing)
+		contents = append(contents, issueContent)
+	}
+
+	if output.DryRun {
+		output.Message = fmt.Sprintf("Dry run: %d failing control(s) in %s: would create %d issue(s), update %d, reopen %d",
+			len(output.Issues), output.Tracker, counts["created"], counts["updated"], counts["reopened"])
+		return nil, output, nil
+	}
+	summary := fmt.Sprintf("create_findings_issues will create %d issue(s), update %d, and reopen %d in %s.",
+		counts["created"], counts["updated"], counts["reopened"], output.Tracker)
+	if err := approveChanges(ctx, req, summary, output.Changes); err != nil {
+		return nil, OutputCreateFindingsIssues{}, err
+	}
+
+	for i, existing := range existingIssues {
+		issue := &output.Issues[i]
+		if existing == nil {
+			created, err := tracker.create(ctx, contents[i])
+			if err != nil {
+				return nil, OutputCreateFindingsIssues{}, fmt.Errorf("failed to create the issue for %s: %w", issue.Control, err)
+			}
+			issue.Key, issue.URL = created.Key, created.URL
+			output.Changes[i].Target = created.Key
+			continue
+		}
+		if err := tracker.update(ctx, *existing, contents[i]); err != nil {
+			return nil, OutputCreateFindingsIssues{}, fmt.Errorf("failed to update issue %s for %s: %w", existing.Key, issue.Control, err)
+		}
+	}
+	output.Message = fmt.Sprintf("%d failing control(s) in %s: %d issue(s) created, %d updated, %d reopened",
+		len(output.Issues), output.Tracker, counts["created"], counts["updated"], counts["reopened"])
+	return nil, output, nil
+}
+
+// findingEvaluations returns the evaluations of doc with a failing result,
+// one per fingerprint: repeated evaluations of a control are merged into the
+// first, with the assessment logs of all of them, so that a control gets a
+// single issue.
+func findingEvaluations(doc map[string]interface{}, failing map[string]bool) []map[string]interface{} {
+	var evaluations []map[string]interface{}
+	byFingerprint := map[string]map[string]interface{}{}
+	for _, e := range mapList(doc["evaluations"]) {
+		if !failing[stringField(e, "result")] {
+			continue
+		}
+		control, _ := e["control"].(map[string]interface{})
+		fingerprint := findingFingerprint(stringField(control, "reference-id"), mappingEntryID(control))
+		if first, ok := byFingerprint[fingerprint]; ok {
+			logs, _ := first["assessment-logs"].([]interface{})
+			more, _ := e["assessment-logs"].([]interface{})
+			first["assessment-logs"] = append(logs, more...)
+			continue
+		}
+		merged := make(map[string]interface{}, len(e))
+		for k, v := range e {
+			merged[k] = v
+		}
+		if logs, ok := e["assessment-logs"].([]interface{}); ok {
+			merged["assessment-logs"] = append([]interface{}{}, logs...)
+		}
+		byFingerprint[fingerprint] = merged
+		evaluations = append(evaluations, merged)
+	}
+	return evaluations
+}
+
+// findingFingerprint identifies the issue of a control across runs.
+func findingFingerprint(catalog, controlID string) string {
+	sum := sha256.Sum256([]byte(catalog + "\x00" + controlID))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// findingIssue writes the issue for a failing evaluation.
+func findingIssue(doc, evaluation map[string]interface{}, extraLabels []string) findingIssueContent {
+	metadata, _ := doc["metadata"].(map[string]interface{})
+	control, _ := evaluation["control"].(map[string]interface{})
+	catalog, controlID := stringField(control, "reference-id"), mappingEntryID(control)
+	name, result := stringField(evaluation, "name"), stringField(evaluation, "result")
+	fingerprint := findingFingerprint(catalog, controlID)
+
+	title := fmt.Sprintf("[%s] %s %s", result, catalog, controlID)
+	if name != "" {
+		title += ": " + name
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Control **%s** of catalog **%s** was evaluated as **%s**", controlID, catalog, result)
+	if id := stringField(metadata, "id"); id != "" {
+		fmt.Fprintf(&b, " in evaluation log %s", id)
+	}
+	b.WriteString(".\n\n")
+	if logs := mapList(evaluation["assessment-logs"]); len(logs) > 0 {
+		b.WriteString("| Requirement | Result | Description |\n|---|---|---|\n")
+		for _, l := range logs {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", markdownCell(mappingEntryID(l["requirement"])),
+				markdownCell(stringField(l, "result")), markdownCell(stringField(l, "description")))
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "Fingerprint: `%s`. This issue is updated when the control is evaluated again; keep the `%s%s` label to preserve that.\n",
+		fingerprint, fingerprintLabelPrefix, fingerprint)
+
+	labels := append([]string{findingLabel, fingerprintLabelPrefix + fingerprint}, extraLabels...)
+	return findingIssueContent{Fingerprint: fingerprint, Title: title, Body: b.String(), Labels: labels}
+}
+
+// newIssueTracker returns a client for the tracker configured for ctx.
+func newIssueTracker(ctx context.Context) (issueTracker, error) {
+	config := serverConfig(ctx)
+	switch config.IssueTracker.Tracker {
+	case TrackerGitHub:
+		return &githubIssueTracker{client: &githubClient{http: newHTTPClient(), config: config.GitHub}, repo: config.IssueTracker.Repo}, nil
+	case TrackerJira:
+		return &jiraIssueTracker{http: newHTTPClient(), config: config.IssueTracker}, nil
+	}
+	return nil, fmt.Errorf("no issue tracker is configured; start the server with --issue-tracker")
+}
+
+// githubIssueTracker files findings as GitHub issues.
+type githubIssueTracker struct {
+	client *githubClient
+	repo   string
+}
+
+type githubIssue struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	State   string `json:"state"`
+	Body    string `json:"body"`
+	// PullRequest is set when the result is a pull request, which the
+	// issues API lists along with issues.
+	PullRequest *struct{} `json:"pull_request"`
+}
+
+func (g *githubIssueTracker) find(ctx context.Context, label string) (*existingIssue, error) {
+	var issues []githubIssue
+	query := url.Values{"labels": {label}, "state": {"all"}, "per_page": {"100"}}
+	if err := g.client.getJSON(ctx, "repos/"+g.repo+"/issues", query, &issues); err != nil {
+		return nil, err
+	}
+	for _, issue := range issues {
+		if issue.PullRequest != nil {
+			continue
+		}
+		return &existingIssue{Key: fmt.Sprintf("#%d", issue.Number), URL: issue.HTMLURL, Closed: issue.State == "closed", Body: issue.Body}, nil
+	}
+	return nil, nil
+}
+
+func (g *githubIssueTracker) create(ctx context.Context, content findingIssueContent) (existingIssue, error) {
+	var issue githubIssue
+	payload := map[string]interface{}{"title": content.Title, "body": content.Body, "labels": content.Labels}
+	if err := g.client.sendJSON(ctx, http.MethodPost, "repos/"+g.repo+"/issues", payload, &issue); err != nil {
+		return existingIssue{}, err
+	}
+	return existingIssue{Key: fmt.Sprintf("#%d", issue.Number), URL: issue.HTMLURL}, nil
+}
+
+func (g *githubIssueTracker) update(ctx context.Context, issue existingIssue, content findingIssueContent) error {
+	payload := map[string]interface{}{"title": content.Title, "body": content.Body, "state": "open"}
+	return g.client.sendJSON(ctx, http.MethodPatch, "repos/"+g.repo+"/issues/"+strings.TrimPrefix(issue.Key, "#"), payload, nil)
+}
+
+// jiraIssueTracker files findings as Jira issues through the REST API v2,
+// which takes plain text descriptions on both Jira Cloud and Data Center.
+type jiraIssueTracker struct {
+	http   *http.Client
+	config IssueTrackerConfig
+}
+
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Status struct {
+			StatusCategory struct {
+				Key string `json:"key"`
+			} `json:"statusCategory"`
+		} `json:"status"`
+		Description string `json:"description"`
+	} `json:"fields"`
+}
+
+func (j *jiraIssueTracker) find(ctx context.Context, label string) (*existingIssue, error) {
+	jql := fmt.Sprintf(`project = "%s" AND labels = "%s" ORDER BY created ASC`, j.config.JiraProject, label)
+	query := url.Values{"jql": {jql}, "maxResults": {"1"}, "fields": {"status,description"}}
+	var result struct {
+		Issues []jiraIssue `json:"issues"`
+	}
+	if err := j.do(ctx, http.MethodGet, "rest/api/2/search", query, nil, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Issues) == 0 {
+		return nil, nil
+	}
+	issue := result.Issues[0]
+	return &existingIssue{Key: issue.Key, URL: j.browseURL(issue.Key), Closed: issue.Fields.Status.StatusCategory.Key == "done", Body: issue.Fields.Description}, nil
+}
+
+func (j *jiraIssueTracker) create(ctx context.Context, content findingIssueContent) (existingIssue, error) {
+	payload := map[string]interface{}{"fields": map[string]interface{}{
+		"project":     map[string]string{"key": j.config.JiraProject},
+		"issuetype":   map[string]string{"name": j.config.JiraIssueType},
+		"summary":     content.Title,
+		"description": content.Body,
+		"labels":      content.Labels,
+	}}
+	var issue jiraIssue
+	if err := j.do(ctx, http.MethodPost, "rest/api/2/issue", nil, payload, &issue); err != nil {
+		return existingIssue{}, err
+	}
+	return existingIssue{Key: issue.Key, URL: j.browseURL(issue.Key)}, nil
+}
+
+// update replaces the summary and description. Jira workflows differ too
+// much to reopen issues generically, so a closed issue is updated in place
+// and reported as reopened for someone to move back into progress.
+func (j *jiraIssueTracker) update(ctx context.Context, issue existingIssue, content findingIssueContent) error {
+	payload := map[string]interface{}{"fields": map[string]interface{}{
+		"summary":     content.Title,
+		"description": content.Body,
+	}}
+	return j.do(ctx, http.MethodPut, "rest/api/2/issue/"+url.PathEscape(issue.Key), nil, payload, nil)
+}
+
+func (j *jiraIssueTracker) browseURL(key string) string {
+	return strings.TrimSuffix(j.config.JiraURL, "/") + "/browse/" + key
+}
+
+// do sends a Jira REST request and decodes the response into v, if v is not nil.
+func (j *jiraIssueTracker) do(ctx context.Context, method, apiPath string, query url.Values, payload, v interface{}) error {
+	if method != http.MethodGet {
+		if err := checkWritable(ctx, "Jira "+apiPath); err != nil {
+			return err
+		}
+	}
+	target := strings.TrimSuffix(j.config.JiraURL, "/") + "/" + apiPath
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	var body io.Reader
+	if payload != nil {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode Jira request: %w", err)
+		}
+		body = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	switch {
+	case j.config.JiraUser != "":
+		req.SetBasicAuth(j.config.JiraUser, j.config.JiraToken)
+	case j.config.JiraToken != "":
+		req.Header.Set("Authorization", "Bearer "+j.config.JiraToken)
+	}
+
+	resp, err := j.http.Do(req)
+	if err != nil {
+		emitUpstreamFailure(ctx, "Jira", err)
+		return fmt.Errorf("failed to reach Jira: %w", err)
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, serverConfig(ctx).MaxArtifactSize))
+	if err != nil {
+		return fmt.Errorf("failed to read Jira response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var problem struct {
+			ErrorMessages []string          `json:"errorMessages"`
+			Errors        map[string]string `json:"errors"`
+		}
+		_ = json.Unmarshal(raw, &problem)
+		messages := problem.ErrorMessages
+		for field, message := range problem.Errors {
+			messages = append(messages, field+": "+message)
+		}
+		if len(messages) > 0 {
+			return fmt.Errorf("Jira request failed with status %d: %s", resp.StatusCode, strings.Join(messages, "; "))
+		}
+		return fmt.Errorf("Jira request failed with status %d", resp.StatusCode)
+	}
+	if v == nil || len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("failed to parse Jira response: %w", err)
+	}
+	return nil
+}
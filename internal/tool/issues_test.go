@@ -0,0 +1,268 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateIssueTracker(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  IssueTrackerConfig
+		wantErr string
+	}{
+		{name: "disabled", config: IssueTrackerConfig{}},
+		{name: "github", config: IssueTrackerConfig{Tracker: TrackerGitHub, Repo: "acme/compliance"}},
+		{name: "github without repo", config: IssueTrackerConfig{Tracker: TrackerGitHub}, wantErr: "owner/name"},
+		{name: "jira", config: IssueTrackerConfig{Tracker: TrackerJira, JiraURL: "https://acme.atlassian.net", JiraProject: "SEC"}},
+		{name: "jira without project", config: IssueTrackerConfig{Tracker: TrackerJira, JiraURL: "https://acme.atlassian.net"}, wantErr: "project key"},
+		{name: "unknown tracker", config: IssueTrackerConfig{Tracker: "trello"}, wantErr: "unknown issue tracker"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestCreateFindingsIssuesGitHub(t *testing.T) {
+	require.NoError(t, useHTTPConfig(t, HTTPConfig{}))
+	log, err := os.ReadFile(filepath.Join("test-data", "evaluation-log.yaml"))
+	require.NoError(t, err)
+
+	// issues holds the filed issues by number, as the GitHub API would
+	type issue struct {
+		Title  string   `json:"title"`
+		Body   string   `json:"body"`
+		State  string   `json:"state"`
+		Labels []string `json:"labels"`
+		// PullRequest marks a pull request, which the API lists as an issue
+		PullRequest bool `json:"-"`
+	}
+	issues := map[string]*issue{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/compliance/issues":
+			assert.Equal(t, "all", r.URL.Query().Get("state"))
+			found := []map[string]interface{}{}
+			for number, i := range issues {
+				for _, l := range i.Labels {
+					if l == r.URL.Query().Get("labels") {
+						entry := map[string]interface{}{"number": json.Number(number), "state": i.State, "body": i.Body, "html_url": "https://github.com/acme/compliance/issues/" + number}
+						if i.PullRequest {
+							entry["pull_request"] = map[string]interface{}{}
+						}
+						found = append(found, entry)
+					}
+				}
+			}
+			_ = json.NewEncoder(w).Encode(found)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/acme/compliance/issues":
+			var created issue
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			created.State = "open"
+			issues["7"] = &created
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"number": 7, "state": "open", "html_url": "https://github.com/acme/compliance/issues/7"})
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/acme/compliance/issues/7":
+			var update issue
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&update))
+			issues["7"].Title, issues["7"].Body, issues["7"].State = update.Title, update.Body, update.State
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"number": 7})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	ctx := withTestConfig(func(c *Config) {
+		c.GitHub = GitHubConfig{APIURL: server.URL, Token: "secret"}
+		c.IssueTracker = IssueTrackerConfig{Tracker: TrackerGitHub, Repo: "acme/compliance"}
+	})
+
+	input := InputCreateFindingsIssues{ArtifactContent: string(log), Labels: []string{"security"}}
+	_, output, err := CreateFindingsIssues(ctx, nil, input)
+	require.NoError(t, err)
+	require.Len(t, output.Issues, 1, "only the failing control should get an issue")
+	first := output.Issues[0]
+	assert.Equal(t, "created", first.Action)
+	assert.Equal(t, "CCC.C06", first.Control)
+	assert.Equal(t, "#7", first.Key)
+	require.Contains(t, issues, "7")
+	assert.Contains(t, issues["7"].Title, "FINOS-CCC CCC.C06")
+	assert.Contains(t, issues["7"].Body, "Bucket found in restricted region")
+	assert.ElementsMatch(t, []string{findingLabel, fingerprintLabelPrefix + first.Fingerprint, "security"}, issues["7"].Labels)
+
+	t.Run("closed issue is reopened", func(t *testing.T) {
+		issues["7"].State = "closed"
+		_, output, err := CreateFindingsIssues(ctx, nil, input)
+		require.NoError(t, err)
+		require.Len(t, output.Issues, 1)
+		assert.Equal(t, "reopened", output.Issues[0].Action)
+		assert.Equal(t, first.Fingerprint, output.Issues[0].Fingerprint, "fingerprint should be stable across runs")
+		assert.Equal(t, "open", issues["7"].State)
+		assert.Len(t, issues, 1, "no duplicate issue should be filed")
+	})
+
+	t.Run("dry run changes nothing", func(t *testing.T) {
+		issues["7"].State, issues["7"].Body = "closed", "Stale description\n"
+		dryRunInput := input
+		dryRunInput.DryRun = true
+		_, output, err := CreateFindingsIssues(ctx, nil, dryRunInput)
+		require.NoError(t, err)
+		assert.True(t, output.DryRun)
+		require.Len(t, output.Changes, 1)
+		assert.Equal(t, "reopen", output.Changes[0].Action)
+		assert.Equal(t, "#7", output.Changes[0].Target)
+		assert.Contains(t, output.Changes[0].Diff, "-Stale description\n")
+		assert.Contains(t, output.Changes[0].Diff, "+Control **CCC.C06**")
+		assert.Equal(t, "closed", issues["7"].State, "a dry run should not update the issue")
+		assert.Equal(t, "Stale description\n", issues["7"].Body)
+	})
+
+	t.Run("custom failing results", func(t *testing.T) {
+		_, output, err := CreateFindingsIssues(ctx, nil, InputCreateFindingsIssues{
+			ArtifactContent: string(log),
+			Results:         []string{"Passed", "Failed"},
+		})
+		require.NoError(t, err)
+		assert.Len(t, output.Issues, 2)
+	})
+
+	t.Run("repeated evaluations share one issue", func(t *testing.T) {
+		repeated := strings.Replace(string(log), "evaluations:\n", `evaluations:
+  - name: Prevent Deployment in Restricted Regions
+    result: Failed
+    control:
+      reference-id: FINOS-CCC
+      entry-id: CCC.C06
+    assessment-logs:
+      - requirement:
+          reference-id: FINOS-CCC
+          entry-id: CCC.C06.TR02
+        description: Volume found in restricted region
+        result: Failed
+`, 1)
+		_, output, err := CreateFindingsIssues(ctx, nil, InputCreateFindingsIssues{ArtifactContent: repeated})
+		require.NoError(t, err)
+		require.Len(t, output.Issues, 1, "a control evaluated twice should get one issue")
+		assert.Len(t, issues, 1, "no duplicate issue should be filed")
+		assert.Contains(t, issues["7"].Body, "Volume found in restricted region")
+		assert.Contains(t, issues["7"].Body, "Bucket found in restricted region")
+	})
+
+	t.Run("pull requests are not issues", func(t *testing.T) {
+		pr := *issues["7"]
+		pr.PullRequest = true
+		delete(issues, "7")
+		issues["9"] = &pr
+		_, output, err := CreateFindingsIssues(ctx, nil, input)
+		require.NoError(t, err)
+		require.Len(t, output.Issues, 1)
+		assert.Equal(t, "created", output.Issues[0].Action, "a labeled pull request should not be updated")
+		assert.Equal(t, "#7", output.Issues[0].Key)
+	})
+}
+
+func TestCreateFindingsIssuesJira(t *testing.T) {
+	require.NoError(t, useHTTPConfig(t, HTTPConfig{}))
+	log, err := os.ReadFile(filepath.Join("test-data", "evaluation-log.yaml"))
+	require.NoError(t, err)
+
+	var created, updated map[string]interface{}
+	existing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, token, ok := r.BasicAuth()
+		assert.True(t, ok, "Jira requests should use basic auth when a user is set")
+		assert.Equal(t, "bot@acme.example", user)
+		assert.Equal(t, "secret", token)
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/search":
+			jql := r.URL.Query().Get("jql")
+			assert.True(t, strings.HasPrefix(jql, `project = "SEC" AND labels = "gemara-fp-`), jql)
+			result := map[string]interface{}{"issues": []interface{}{}}
+			if existing {
+				result["issues"] = []interface{}{map[string]interface{}{
+					"key":    "SEC-12",
+					"fields": map[string]interface{}{"status": map[string]interface{}{"statusCategory": map[string]string{"key": "indeterminate"}}},
+				}}
+			}
+			_ = json.NewEncoder(w).Encode(result)
+		case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(map[string]string{"key": "SEC-12"})
+		case r.Method == http.MethodPut && r.URL.Path == "/rest/api/2/issue/SEC-12":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&updated))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	ctx := withTestConfig(func(c *Config) {
+		c.IssueTracker = IssueTrackerConfig{
+			Tracker:       TrackerJira,
+			JiraURL:       server.URL,
+			JiraProject:   "SEC",
+			JiraIssueType: "Bug",
+			JiraUser:      "bot@acme.example",
+			JiraToken:     "secret",
+		}
+	})
+
+	input := InputCreateFindingsIssues{ArtifactContent: string(log)}
+	_, output, err := CreateFindingsIssues(ctx, nil, input)
+	require.NoError(t, err)
+	require.Len(t, output.Issues, 1)
+	assert.Equal(t, "created", output.Issues[0].Action)
+	assert.Equal(t, server.URL+"/browse/SEC-12", output.Issues[0].URL)
+	fields, _ := created["fields"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"name": "Bug"}, fields["issuetype"])
+	assert.Equal(t, map[string]interface{}{"key": "SEC"}, fields["project"])
+
+	existing = true
+	_, output, err = CreateFindingsIssues(ctx, nil, input)
+	require.NoError(t, err)
+	require.Len(t, output.Issues, 1)
+	assert.Equal(t, "updated", output.Issues[0].Action)
+	assert.Equal(t, "SEC-12", output.Issues[0].Key)
+	fields, _ = updated["fields"].(map[string]interface{})
+	assert.Contains(t, fields["description"], "Bucket found in restricted region")
+}
+
+func TestCreateFindingsIssuesErrors(t *testing.T) {
+	catalog, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err)
+
+	_, _, err = CreateFindingsIssues(context.Background(), nil, InputCreateFindingsIssues{ArtifactContent: string(catalog)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no issue tracker is configured")
+
+	ctx := withTestConfig(func(c *Config) {
+		c.IssueTracker = IssueTrackerConfig{Tracker: TrackerGitHub, Repo: "acme/compliance"}
+	})
+	_, _, err = CreateFindingsIssues(ctx, nil, InputCreateFindingsIssues{ArtifactContent: string(catalog)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "expected an EvaluationLog")
+}
@@ -0,0 +1,304 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	k8sFormatKyverno    = "kyverno"
+	k8sFormatGatekeeper = "gatekeeper"
+
+	// k8sAnnotationPrefix prefixes the annotations that trace a policy back to Gemara.
+	k8sAnnotationPrefix = "gemara.openssf.org/"
+)
+
+// k8sCategory matches applicability categories that describe Kubernetes.
+var k8sCategory = regexp.MustCompile(`(?i)kubernetes|\bk8s\b`)
+
+// k8sName matches characters that are not valid in a Kubernetes resource name.
+var k8sName = regexp.MustCompile(`[^a-z0-9]+`)
+
+// k8sKind matches characters that are not valid in a constraint kind.
+var k8sKind = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// MetadataExportK8sPolicies describes the ExportK8sPolicies tool.
+var MetadataExportK8sPolicies = &mcp.Tool{
+	Name: "export_k8s_policies",
+	Description: "Generate Kyverno ClusterPolicy or Gatekeeper ConstraintTemplate skeletons, one per control, from the " +
+		"assessment requirements of a ControlCatalog that apply to Kubernetes. Each policy carries annotations tracing it " +
+		"back to its Gemara catalog, control, and requirement IDs.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"catalog_content"},
+		"properties": map[string]interface{}{
+			"catalog_content": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML content of the ControlCatalog",
+			},
+			"format": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{k8sFormatKyverno, k8sFormatGatekeeper},
+				"description": "Policy engine to target (default: kyverno)",
+			},
+			"applicability": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string"},
+				"description": "Category IDs that mark a requirement as applying to Kubernetes (default: categories " +
+					"whose ID or title mentions Kubernetes or k8s)",
+			},
+		},
+	},
+}
+
+// InputExportK8sPolicies is the input for the ExportK8sPolicies tool.
+type InputExportK8sPolicies struct {
+	CatalogContent string   `json:"catalog_content"`
+	Format         string   `json:"format,omitempty"`
+	Applicability  []string `json:"applicability,omitempty"`
+}
+
+// K8sPolicy is a generated Kubernetes policy resource.
+type K8sPolicy struct {
+	Kind         string   `json:"kind"`
+	Name         string   `json:"name"`
+	Control      string   `json:"control"`
+	Requirements []string `json:"requirements"`
+	Content      string   `json:"content"`
+}
+
+// OutputExportK8sPolicies is the output for the ExportK8sPolicies tool.
+type OutputExportK8sPolicies struct {
+	Format   string      `json:"format"`
+	Policies []K8sPolicy `json:"policies"`
+	// Content holds every policy as one multi-document YAML stream.
+	Content string `json:"content"`
+	Message string `json:"message"`
+}
+
+// ExportK8sPolicies drafts Kubernetes admission policies for the Kubernetes
+// requirements of a catalog.
+func ExportK8sPolicies(ctx context.Context, _ *mcp.CallToolRequest, input InputExportK8sPolicies) (*mcp.CallToolResult, OutputExportK8sPolicies, error) {
+	if input.CatalogContent == "" {
+		return nil, OutputExportK8sPolicies{}, fmt.Errorf("catalog_content is required")
+	}
+	format := input.Format
+	if format == "" {
+		format = k8sFormatKyverno
+	}
+	if format != k8sFormatKyverno && format != k8sFormatGatekeeper {
+		return nil, OutputExportK8sPolicies{}, fmt.Errorf("unsupported format %q: use %s or %s", format, k8sFormatKyverno, k8sFormatGatekeeper)
+	}
+	catalog, err := parseArtifact(ctx, input.CatalogContent)
+	if err != nil {
+		return nil, OutputExportK8sPolicies{}, err
+	}
+	controls, ok := catalog["controls"].([]interface{})
+	if !ok || len(controls) == 0 {
+		return nil, OutputExportK8sPolicies{}, fmt.Errorf("catalog has no controls")
+	}
+	catalogID := metadataID(catalog)
+
+	categories := stringSet(input.Applicability)
+	if len(categories) == 0 {
+		categories = k8sCategories(catalog)
+	}
+	if len(categories) == 0 {
+		return nil, OutputExportK8sPolicies{}, fmt.Errorf("catalog declares no Kubernetes applicability category; name the categories to export with applicability")
+	}
+
+	output := OutputExportK8sPolicies{Format: format, Policies: []K8sPolicy{}}
+	var docs []string
+	requirements := 0
+	for _, item := range controls {
+		control, ok := item.(map[string]interface{})
+		if !ok || entityID(control) == "" {
+			continue
+		}
+		var matched []map[string]interface{}
+		items, _ := control["assessment-requirements"].([]interface{})
+		for _, r := range items {
+			requirement, ok := r.(map[string]interface{})
+			if ok && entityID(requirement) != "" && appliesTo(requirement, categories) {
+				matched = append(matched, requirement)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		var policy K8sPolicy
+		var resource yaml.MapSlice
+		if format == k8sFormatGatekeeper {
+			policy, resource = gatekeeperTemplate(catalogID, control, matched)
+		} else {
+			policy, resource = kyvernoPolicy(catalogID, control, matched)
+		}
+		out, err := yaml.MarshalWithOptions(resource, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+		if err != nil {
+			return nil, OutputExportK8sPolicies{}, fmt.Errorf("failed to encode %s policy: %w", format, err)
+		}
+		policy.Content = string(out)
+		requirements += len(matched)
+		docs = append(docs, policy.Content)
+		output.Policies = append(output.Policies, policy)
+	}
+
+	output.Content = strings.Join(docs, "---\n")
+	output.Message = fmt.Sprintf("Generated %d %s polic(ies) covering %d Kubernetes requirement(s)", len(output.Policies), format, requirements)
+	if len(output.Policies) > 0 {
+		output.Message += fmt.Sprintf("; replace the %s resource kinds and conditions before enforcing", skeletonPlaceholder)
+	}
+	return nil, output, nil
+}
+
+// k8sCategories returns the applicability categories of a catalog that
+// describe Kubernetes.
+func k8sCategories(catalog map[string]interface{}) map[string]bool {
+	categories := map[string]bool{}
+	metadata, _ := catalog["metadata"].(map[string]interface{})
+	items, _ := metadata["applicability-categories"].([]interface{})
+	for _, item := range items {
+		category, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id := entityID(category)
+		title, _ := category["title"].(string)
+		if id != "" && (k8sCategory.MatchString(id) || k8sCategory.MatchString(title)) {
+			categories[id] = true
+		}
+	}
+	return categories
+}
+
+// k8sAnnotations are the traceability annotations of a generated policy.
+func k8sAnnotations(catalogID string, control map[string]interface{}, requirements []string) yaml.MapSlice {
+	return yaml.MapSlice{
+		{Key: k8sAnnotationPrefix + "catalog", Value: catalogID},
+		{Key: k8sAnnotationPrefix + "control", Value: entityID(control)},
+		{Key: k8sAnnotationPrefix + "requirements", Value: strings.Join(requirements, ",")},
+	}
+}
+
+// kyvernoPolicy renders a Kyverno ClusterPolicy with one audit rule per requirement.
+func kyvernoPolicy(catalogID string, control map[string]interface{}, requirements []map[string]interface{}) (K8sPolicy, yaml.MapSlice) {
+	controlID := entityID(control)
+	policy := K8sPolicy{Kind: "ClusterPolicy", Name: k8sResourceName(controlID), Control: controlID}
+	title, _ := control["title"].(string)
+	objective, _ := control["objective"].(string)
+
+	var rules []interface{}
+	for _, requirement := range requirements {
+		requirementID := entityID(requirement)
+		policy.Requirements = append(policy.Requirements, requirementID)
+		text, _ := requirement["text"].(string)
+		rules = append(rules, yaml.MapSlice{
+			{Key: "name", Value: k8sResourceName(requirementID)},
+			{Key: "match", Value: yaml.MapSlice{
+				{Key: "any", Value: []interface{}{
+					yaml.MapSlice{{Key: "resources", Value: yaml.MapSlice{
+						{Key: "kinds", Value: []string{skeletonPlaceholder}},
+					}}},
+				}},
+			}},
+			{Key: "validate", Value: yaml.MapSlice{
+				{Key: "message", Value: requirementID + ": " + strings.Join(strings.Fields(text), " ")},
+				{Key: "deny", Value: yaml.MapSlice{
+					{Key: "conditions", Value: yaml.MapSlice{
+						{Key: "any", Value: []interface{}{
+							yaml.MapSlice{
+								{Key: "key", Value: "{{ request.object.kind }}"},
+								{Key: "operator", Value: "Equals"},
+								{Key: "value", Value: skeletonPlaceholder},
+							},
+						}},
+					}},
+				}},
+			}},
+		})
+	}
+
+	annotations := yaml.MapSlice{{Key: "policies.kyverno.io/title", Value: firstNonEmpty(title, controlID)}}
+	if objective = strings.Join(strings.Fields(objective), " "); objective != "" {
+		annotations = append(annotations, yaml.MapItem{Key: "policies.kyverno.io/description", Value: objective})
+	}
+	annotations = append(annotations, k8sAnnotations(catalogID, control, policy.Requirements)...)
+
+	return policy, yaml.MapSlice{
+		{Key: "apiVersion", Value: "kyverno.io/v1"},
+		{Key: "kind", Value: policy.Kind},
+		{Key: "metadata", Value: yaml.MapSlice{
+			{Key: "name", Value: policy.Name},
+			{Key: "annotations", Value: annotations},
+		}},
+		{Key: "spec", Value: yaml.MapSlice{
+			{Key: "validationFailureAction", Value: "Audit"},
+			{Key: "background", Value: true},
+			{Key: "rules", Value: rules},
+		}},
+	}
+}
+
+// gatekeeperTemplate renders a Gatekeeper ConstraintTemplate with one
+// violation rule per requirement.
+func gatekeeperTemplate(catalogID string, control map[string]interface{}, requirements []map[string]interface{}) (K8sPolicy, yaml.MapSlice) {
+	controlID := entityID(control)
+	// Gatekeeper requires the template name to be the lower-cased constraint kind
+	kind := "Gemara" + k8sKind.ReplaceAllString(controlID, "")
+	policy := K8sPolicy{Kind: "ConstraintTemplate", Name: strings.ToLower(kind), Control: controlID}
+
+	var rego strings.Builder
+	fmt.Fprintf(&rego, "package %s\n", policy.Name)
+	for _, requirement := range requirements {
+		requirementID := entityID(requirement)
+		policy.Requirements = append(policy.Requirements, requirementID)
+		text, _ := requirement["text"].(string)
+		rego.WriteString("\n")
+		fmt.Fprintf(&rego, "violation[{\"msg\": msg}] {\n")
+		fmt.Fprintf(&rego, "  # %s: check input.review.object for %s, then remove this line\n", skeletonPlaceholder, requirementID)
+		fmt.Fprintf(&rego, "  false\n")
+		fmt.Fprintf(&rego, "  msg := %q\n", requirementID+": "+strings.Join(strings.Fields(text), " "))
+		fmt.Fprintf(&rego, "}\n")
+	}
+
+	annotations := yaml.MapSlice{}
+	if title, _ := control["title"].(string); title != "" {
+		annotations = append(annotations, yaml.MapItem{Key: "metadata.gatekeeper.sh/title", Value: title})
+	}
+	annotations = append(annotations, k8sAnnotations(catalogID, control, policy.Requirements)...)
+
+	return policy, yaml.MapSlice{
+		{Key: "apiVersion", Value: "templates.gatekeeper.sh/v1"},
+		{Key: "kind", Value: policy.Kind},
+		{Key: "metadata", Value: yaml.MapSlice{
+			{Key: "name", Value: policy.Name},
+			{Key: "annotations", Value: annotations},
+		}},
+		{Key: "spec", Value: yaml.MapSlice{
+			{Key: "crd", Value: yaml.MapSlice{
+				{Key: "spec", Value: yaml.MapSlice{
+					{Key: "names", Value: yaml.MapSlice{{Key: "kind", Value: kind}}},
+				}},
+			}},
+			{Key: "targets", Value: []interface{}{
+				yaml.MapSlice{
+					{Key: "target", Value: "admission.k8s.gatekeeper.sh"},
+					{Key: "rego", Value: rego.String()},
+				},
+			}},
+		}},
+	}
+}
+
+// k8sResourceName converts an ID into a Kubernetes resource name, e.g. "CCC.C01" -> "ccc-c01".
+func k8sResourceName(id string) string {
+	return strings.Trim(k8sName.ReplaceAllString(strings.ToLower(id), "-"), "-")
+}
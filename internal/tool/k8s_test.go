@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const k8sTestCatalog = `metadata:
+  id: ACME-Cloud
+  applicability-categories:
+    - id: k8s
+      title: Kubernetes clusters
+    - id: vm
+      title: Virtual machines
+title: ACME Cloud
+controls:
+  - id: AC.C01
+    title: Restrict Privileges
+    objective: Workloads run with least privilege.
+    assessment-requirements:
+      - id: AC.C01.TR01
+        text: Pods MUST NOT run as root.
+        applicability: [k8s]
+      - id: AC.C01.TR02
+        text: Hosts MUST disable root login.
+        applicability: [vm]
+  - id: AC.C02
+    title: Patch Hosts
+    objective: Hosts are patched.
+    assessment-requirements:
+      - id: AC.C02.TR01
+        text: Hosts MUST apply security updates within 30 days.
+        applicability: [vm]
+`
+
+func TestExportK8sPolicies(t *testing.T) {
+	ccc, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err, "should read test catalog")
+
+	tests := []struct {
+		name             string
+		input            InputExportK8sPolicies
+		wantErr          bool
+		errContains      string
+		wantKind         string
+		wantNames        []string
+		wantRequirements [][]string
+	}{
+		{
+			name:        "missing catalog",
+			wantErr:     true,
+			errContains: "catalog_content is required",
+		},
+		{
+			name:        "unsupported format",
+			input:       InputExportK8sPolicies{CatalogContent: k8sTestCatalog, Format: "opa"},
+			wantErr:     true,
+			errContains: "unsupported format",
+		},
+		{
+			name:        "no kubernetes category",
+			input:       InputExportK8sPolicies{CatalogContent: string(ccc)},
+			wantErr:     true,
+			errContains: "no Kubernetes applicability category",
+		},
+		{
+			name:             "kyverno by default",
+			input:            InputExportK8sPolicies{CatalogContent: k8sTestCatalog},
+			wantKind:         "ClusterPolicy",
+			wantNames:        []string{"ac-c01"},
+			wantRequirements: [][]string{{"AC.C01.TR01"}},
+		},
+		{
+			name:             "gatekeeper",
+			input:            InputExportK8sPolicies{CatalogContent: k8sTestCatalog, Format: "gatekeeper"},
+			wantKind:         "ConstraintTemplate",
+			wantNames:        []string{"gemaraacc01"},
+			wantRequirements: [][]string{{"AC.C01.TR01"}},
+		},
+		{
+			name:             "explicit applicability",
+			input:            InputExportK8sPolicies{CatalogContent: k8sTestCatalog, Applicability: []string{"vm"}},
+			wantKind:         "ClusterPolicy",
+			wantNames:        []string{"ac-c01", "ac-c02"},
+			wantRequirements: [][]string{{"AC.C01.TR02"}, {"AC.C02.TR01"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := ExportK8sPolicies(context.Background(), nil, tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			var names []string
+			var requirements [][]string
+			for _, p := range output.Policies {
+				assert.Equal(t, tt.wantKind, p.Kind)
+				names = append(names, p.Name)
+				requirements = append(requirements, p.Requirements)
+
+				var resource struct {
+					Kind     string `yaml:"kind"`
+					Metadata struct {
+						Name        string            `yaml:"name"`
+						Annotations map[string]string `yaml:"annotations"`
+					} `yaml:"metadata"`
+				}
+				require.NoError(t, yaml.Unmarshal([]byte(p.Content), &resource))
+				assert.Equal(t, p.Kind, resource.Kind)
+				assert.Equal(t, p.Name, resource.Metadata.Name)
+				assert.Equal(t, "ACME-Cloud", resource.Metadata.Annotations["gemara.openssf.org/catalog"])
+				assert.Equal(t, p.Control, resource.Metadata.Annotations["gemara.openssf.org/control"])
+				assert.Contains(t, output.Content, p.Content)
+			}
+			assert.Equal(t, tt.wantNames, names)
+			assert.Equal(t, tt.wantRequirements, requirements)
+		})
+	}
+}
+
+func TestExportK8sPoliciesGatekeeperRego(t *testing.T) {
+	_, output, err := ExportK8sPolicies(context.Background(), nil, InputExportK8sPolicies{CatalogContent: k8sTestCatalog, Format: "gatekeeper"})
+	require.NoError(t, err)
+	require.Len(t, output.Policies, 1)
+
+	var template struct {
+		Spec struct {
+			CRD struct {
+				Spec struct {
+					Names struct {
+						Kind string `yaml:"kind"`
+					} `yaml:"names"`
+				} `yaml:"spec"`
+			} `yaml:"crd"`
+			Targets []struct {
+				Rego string `yaml:"rego"`
+			} `yaml:"targets"`
+		} `yaml:"spec"`
+	}
+	require.NoError(t, yaml.Unmarshal([]byte(output.Policies[0].Content), &template))
+	assert.Equal(t, "GemaraACC01", template.Spec.CRD.Spec.Names.Kind)
+	require.Len(t, template.Spec.Targets, 1)
+	assert.Contains(t, template.Spec.Targets[0].Rego, "package gemaraacc01\n")
+	assert.Contains(t, template.Spec.Targets[0].Rego, `msg := "AC.C01.TR01: Pods MUST NOT run as root."`)
+}
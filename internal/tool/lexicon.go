@@ -4,45 +4,162 @@ package tool
 
 import (
 	"context"
-	"encoding/json"
+	_ "embed"
 	"fmt"
-	"io"
-	"net/http"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/goccy/go-yaml"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/sync/singleflight"
 )
 
 const (
-	lexiconURL      = "https://raw.githubusercontent.com/gemaraproj/gemara/main/docs/lexicon.yaml"
-	httpTimeout     = 30 * time.Second
-	lexiconCacheTTL = 24 * time.Hour // Cache for 24 hours since lexicon changes infrequently
+	// DefaultLexiconURL is the published Gemara lexicon.
+	DefaultLexiconURL = "https://raw.githubusercontent.com/gemaraproj/gemara/main/docs/lexicon.yaml"
+	httpTimeout       = 30 * time.Second
+	lexiconCacheTTL   = 24 * time.Hour // Cache for 24 hours since lexicon changes infrequently
+	// lexiconRepoPath is where a checkout of the gemara repository keeps its lexicon.
+	lexiconRepoPath = "docs/lexicon.yaml"
+	// lexiconSnapshotSource identifies the embedded snapshot in output and provenance.
+	lexiconSnapshotSource = "embedded lexicon snapshot"
 )
 
-var (
-	lexiconCache     []LexiconEntry
-	lexiconCacheTime time.Time
+// lexiconSnapshot is a copy of the upstream lexicon served when it cannot be
+// fetched and nothing is cached, so a fresh install works offline.
+//
+//go:embed lexicon_snapshot.yaml
+var lexiconSnapshot []byte
+
+// Lexicon conflict rules decide which definition wins when layered lexicon
+// sources define the same term.
+const (
+	// LexiconConflictOverride lets later sources replace earlier definitions.
+	LexiconConflictOverride = "override"
+	// LexiconConflictPreserve keeps the earliest definition of a term.
+	LexiconConflictPreserve = "preserve"
+	// LexiconConflictError rejects sources that define a term differently.
+	LexiconConflictError = "error"
 )
 
+// LexiconService serves the lexicon layered from its sources, with its own
+// cache. Tool calls and resource reads use the service of the mode that
+// registered them, or DefaultLexicon.
+type LexiconService struct {
+	mu       sync.RWMutex
+	sources  []string
+	conflict string
+	cache    lexiconState
+	// flight shares one fetch of the sources among concurrent readers.
+	flight singleflight.Group
+}
+
+// lexiconState is a cached lexicon.
+type lexiconState struct {
+	entries []LexiconEntry
+	// fetched is when the sources were read; zero for the embedded snapshot
+	// so that the next read tries upstream again.
+	fetched  time.Time
+	revision lexiconRevision
+	// snapshot is set while the cache holds the embedded snapshot.
+	snapshot bool
+}
+
+// DefaultLexicon serves the lexicon of the configured sources, which point
+// into the bundle when serving offline.
+var DefaultLexicon = NewLexiconService([]string{DefaultLexiconURL}, LexiconConflictOverride)
+
+// NewLexiconService returns a service that merges sources in order, so that
+// org-specific terms can be layered over the upstream lexicon, resolving terms
+// defined by several sources with the conflict rule.
+func NewLexiconService(sources []string, conflict string) *LexiconService {
+	return &LexiconService{sources: slices.Clone(sources), conflict: conflict}
+}
+
+// Configure replaces the sources and conflict rule and empties the cache.
+func (s *LexiconService) Configure(sources []string, conflict string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sources = slices.Clone(sources)
+	s.conflict = conflict
+	s.cache = lexiconState{}
+}
+
+// Sources returns the lexicon sources in the order they are layered.
+func (s *LexiconService) Sources() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Clone(s.sources)
+}
+
+// Conflict returns the rule applied when sources define the same term.
+func (s *LexiconService) Conflict() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.conflict
+}
+
+func (s *LexiconService) cached() lexiconState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cache
+}
+
+func (s *LexiconService) store(state lexiconState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache = state
+}
+
+type lexiconKey struct{}
+
+// withLexicon returns a context whose handlers use the given lexicon service.
+func withLexicon(ctx context.Context, s *LexiconService) context.Context {
+	return context.WithValue(ctx, lexiconKey{}, s)
+}
+
+// lexiconService returns the lexicon service of ctx, or DefaultLexicon.
+func lexiconService(ctx context.Context) *LexiconService {
+	if s, ok := ctx.Value(lexiconKey{}).(*LexiconService); ok {
+		return s
+	}
+	return DefaultLexicon
+}
+
+// lexiconMiddleware makes s the lexicon service of every request to a server.
+func lexiconMiddleware(s *LexiconService) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			return next(withLexicon(ctx, s), method, req)
+		}
+	}
+}
+
 // MetadataGetLexicon describes the GetLexicon tool.
 var MetadataGetLexicon = &mcp.Tool{
-	Name:        "get_lexicon",
-	Description: "Retrieve the Gemara Lexicon containing definitions of terms used in the Gemara model.",
+	Name: "get_lexicon",
+	Description: "Retrieve the Gemara Lexicon containing definitions of terms used in the Gemara model. " +
+		"Use limit or max_output_bytes to page through it with next_cursor.",
 	InputSchema: map[string]interface{}{
 		"type": "object",
-		"properties": map[string]interface{}{
+		"properties": withPagination(map[string]interface{}{
 			"refresh": map[string]interface{}{
 				"type":        "boolean",
 				"description": "Force refresh of lexicon cache (default: false)",
 			},
-		},
+		}, "entries"),
 	},
 }
 
 // InputGetLexicon is the input for the GetLexicon tool.
 type InputGetLexicon struct {
 	Refresh bool `json:"refresh"`
+	PageInput
 }
 
 // LexiconEntry represents a single term in the Gemara Lexicon.
@@ -57,121 +174,387 @@ type OutputGetLexicon struct {
 	Entries []LexiconEntry `json:"entries"`
 	Source  string         `json:"source"`
 	Cached  bool           `json:"cached"`
+	// Stale is set when an expired cache or the embedded snapshot was served
+	// because upstream was unavailable.
+	Stale bool `json:"stale,omitempty"`
+	// Page describes which entries were returned.
+	Page *PageInfo `json:"page,omitempty"`
 }
 
-// GetLexicon retrieves the Gemara Lexicon using the resource handler.
+// GetLexicon retrieves a page of the Gemara Lexicon.
 func GetLexicon(ctx context.Context, _ *mcp.CallToolRequest, input InputGetLexicon) (*mcp.CallToolResult, OutputGetLexicon, error) {
-	// If refresh is requested, fetch fresh data and update cache
-	if input.Refresh {
-		entries, err := fetchLexiconFromURL(ctx, lexiconURL)
+	output, err := lexiconService(ctx).read(ctx, input.Refresh)
+	if err != nil {
+		return nil, OutputGetLexicon{}, err
+	}
+	entries, page, err := paginate(output.Entries, input.PageInput)
+	if err != nil {
+		return nil, OutputGetLexicon{}, err
+	}
+	output.Entries, output.Page = entries, &page
+	return nil, output, nil
+}
+
+// read returns the whole lexicon, fetching it again when refresh is set.
+func (s *LexiconService) read(ctx context.Context, refresh bool) (OutputGetLexicon, error) {
+	if !refresh {
+		state, cache, err := s.load(ctx)
 		if err != nil {
-			return nil, OutputGetLexicon{}, err
+			return OutputGetLexicon{}, err
 		}
+		recordLexicon(ctx, state.revision, cache)
+		output := OutputGetLexicon{
+			Entries: state.entries,
+			Source:  s.source(),
+			Cached:  cache != cacheMiss,
+			Stale:   cache == cacheStale,
+		}
+		if state.snapshot {
+			output.Source = lexiconSnapshotSource
+			output.Cached = false
+		}
+		return output, nil
+	}
 
-		// Update cache
-		lexiconCache = entries
-		lexiconCacheTime = time.Now()
-
+	entries, rev, err := s.fetch(ctx)
+	if err != nil {
+		state := s.cached()
+		if len(state.entries) == 0 {
+			var fallbackErr error
+			if state, fallbackErr = s.useSnapshot(ctx, err); fallbackErr != nil {
+				return OutputGetLexicon{}, err
+			}
+		} else {
+			emitStaleCache(ctx, "lexicon", s.source(), err)
+		}
+		recordLexicon(ctx, state.revision, cacheStale)
 		output := OutputGetLexicon{
-			Entries: entries,
-			Source:  lexiconURL,
-			Cached:  false,
+			Entries: state.entries,
+			Source:  state.revision.source,
+			Cached:  !state.snapshot,
+			Stale:   true,
 		}
-		return nil, output, nil
+		return output, nil
+	}
+
+	s.store(lexiconState{entries: entries, fetched: time.Now(), revision: rev})
+	recordLexicon(ctx, rev, cacheMiss)
+	return OutputGetLexicon{Entries: entries, Source: s.source()}, nil
+}
+
+// Read returns the whole lexicon as get_lexicon does, from cache unless refresh
+// is set, for use outside a tool call.
+func (s *LexiconService) Read(ctx context.Context, refresh bool) (OutputGetLexicon, error) {
+	return s.read(ctx, refresh)
+}
+
+// LookupTerm returns the entry for term, ignoring case.
+func LookupTerm(entries []LexiconEntry, term string) (LexiconEntry, bool) {
+	term = strings.TrimSpace(term)
+	for _, e := range entries {
+		if strings.EqualFold(e.Term, term) {
+			return e, true
+		}
+	}
+	return LexiconEntry{}, false
+}
+
+// SearchLexicon returns the entries whose term or definition contains query,
+// ignoring case: the exact term first, then other term matches, then
+// definition matches, each in lexicon order.
+func SearchLexicon(entries []LexiconEntry, query string) []LexiconEntry {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+	var exact, terms, definitions []LexiconEntry
+	for _, e := range entries {
+		switch term := strings.ToLower(e.Term); {
+		case term == query:
+			exact = append(exact, e)
+		case strings.Contains(term, query):
+			terms = append(terms, e)
+		case strings.Contains(strings.ToLower(e.Definition), query):
+			definitions = append(definitions, e)
+		}
+	}
+	return append(append(exact, terms...), definitions...)
+}
+
+// load returns the cached lexicon, fetching it when the cache is empty or
+// expired. When the sources cannot be read it serves the expired cache, or
+// else the embedded snapshot.
+func (s *LexiconService) load(ctx context.Context) (lexiconState, string, error) {
+	if s.fresh() {
+		return s.cached(), cacheHit, nil
+	}
+	emitCacheMiss(ctx, "lexicon", s.source())
+	entries, rev, err := s.fetch(ctx)
+	if err == nil {
+		state := lexiconState{entries: entries, fetched: time.Now(), revision: rev}
+		s.store(state)
+		return state, cacheMiss, nil
+	}
+	// Keep answering from the expired cache while upstream is down
+	if state := s.cached(); len(state.entries) > 0 {
+		emitStaleCache(ctx, "lexicon", s.source(), err)
+		return state, cacheStale, nil
+	}
+	// Fall back to the lexicon embedded in the binary
+	state, fallbackErr := s.useSnapshot(ctx, err)
+	if fallbackErr != nil {
+		return lexiconState{}, "", fmt.Errorf("failed to fetch lexicon: %w", err)
 	}
+	return state, cacheStale, nil
+}
 
-	// Otherwise, use the resource handler which will use cached data or fetch if needed
-	req := &mcp.ReadResourceRequest{
-		Params: &mcp.ReadResourceParams{
-			URI: lexiconResourceURI,
-		},
+// fetchLexiconFromURL fetches the lexicon from the given URL.
+func fetchLexiconFromURL(ctx context.Context, url string) ([]LexiconEntry, lexiconRevision, error) {
+	if path, ok := lexiconFilePath(url); ok {
+		return readLexiconFile(url, path)
 	}
 
-	result, err := HandleLexiconResource(ctx, req)
+	body, etag, err := fetchURLWithETag(ctx, url)
 	if err != nil {
-		return nil, OutputGetLexicon{}, fmt.Errorf("failed to read lexicon resource: %w", err)
+		return nil, lexiconRevision{}, fmt.Errorf("failed to fetch lexicon: %w", err)
 	}
 
-	if len(result.Contents) == 0 {
-		return nil, OutputGetLexicon{}, fmt.Errorf("resource returned no contents")
+	var entries []LexiconEntry
+	if err := yaml.Unmarshal(body, &entries); err != nil {
+		return nil, lexiconRevision{}, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	return entries, lexiconRevision{source: url, digest: contentDigest(body), etag: etag}, nil
+}
+
+// readLexiconFile reads a local lexicon and records its modification time so
+// the cache is invalidated when the file changes rather than by TTL.
+func readLexiconFile(url, path string) ([]LexiconEntry, lexiconRevision, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, lexiconRevision{}, fmt.Errorf("failed to fetch lexicon: %w", err)
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, lexiconRevision{}, fmt.Errorf("failed to fetch lexicon: %w", err)
 	}
 
 	var entries []LexiconEntry
-	if err := json.Unmarshal([]byte(result.Contents[0].Text), &entries); err != nil {
-		return nil, OutputGetLexicon{}, fmt.Errorf("failed to parse lexicon JSON: %w", err)
+	if err := yaml.Unmarshal(body, &entries); err != nil {
+		return nil, lexiconRevision{}, fmt.Errorf("failed to parse YAML: %w", err)
 	}
 
-	// Determine if data was cached (check if it was already cached before resource call)
-	wasCached := !lexiconCacheTime.IsZero() && time.Since(lexiconCacheTime) < lexiconCacheTTL
+	rev := lexiconRevision{source: url, digest: contentDigest(body), modTimes: map[string]time.Time{path: info.ModTime()}}
+	return entries, rev, nil
+}
 
-	output := OutputGetLexicon{
-		Entries: entries,
-		Source:  lexiconURL,
-		Cached:  wasCached,
+// lexiconFilePath returns the local path of a file:// lexicon source. A
+// directory, such as a checkout of the gemara repository, resolves to the
+// lexicon it contains.
+func lexiconFilePath(source string) (string, bool) {
+	u, err := neturl.Parse(source)
+	if err != nil || u.Scheme != "file" {
+		return "", false
 	}
+	if info, err := os.Stat(u.Path); err == nil && info.IsDir() {
+		return filepath.Join(u.Path, lexiconRepoPath), true
+	}
+	return u.Path, true
+}
 
-	return nil, output, nil
+// fresh reports whether the cached lexicon can be served. Local file sources
+// are fresh until their modification time changes; remote sources expire after
+// lexiconCacheTTL.
+func (s *LexiconService) fresh() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.cache.entries) == 0 || s.cache.fetched.IsZero() {
+		return false
+	}
+	for _, source := range s.sources {
+		if _, ok := lexiconFilePath(source); !ok && time.Since(s.cache.fetched) >= lexiconCacheTTL {
+			return false
+		}
+	}
+	for path, modTime := range s.cache.revision.modTimes {
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().Equal(modTime) {
+			return false
+		}
+	}
+	return true
 }
 
-// fetchLexiconFromURL fetches the lexicon from the given URL.
-func fetchLexiconFromURL(ctx context.Context, url string) ([]LexiconEntry, error) {
-	client := &http.Client{
-		Timeout: httpTimeout,
+// LexiconConflictRules returns the supported lexicon conflict rules.
+func LexiconConflictRules() []string {
+	return []string{LexiconConflictOverride, LexiconConflictPreserve, LexiconConflictError}
+}
+
+// ValidateLexiconConflict returns an error if rule is not a supported conflict rule.
+func ValidateLexiconConflict(rule string) error {
+	if !slices.Contains(LexiconConflictRules(), rule) {
+		return fmt.Errorf("unknown lexicon conflict rule %q (available: %s)", rule, strings.Join(LexiconConflictRules(), ", "))
 	}
+	return nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// source describes the lexicon sources.
+func (s *LexiconService) source() string {
+	return strings.Join(s.Sources(), ", ")
+}
+
+// fetchedLexicon is the result of a fetch of the lexicon sources.
+type fetchedLexicon struct {
+	entries  []LexiconEntry
+	revision lexiconRevision
+}
+
+// fetch reads every lexicon source and merges them. Concurrent fetches share
+// one read of the sources.
+func (s *LexiconService) fetch(ctx context.Context) ([]LexiconEntry, lexiconRevision, error) {
+	fetched, _, err := sharedCall(ctx, &s.flight, "lexicon", func(ctx context.Context) (fetchedLexicon, error) {
+		entries, rev, err := s.fetchSources(ctx)
+		return fetchedLexicon{entries: entries, revision: rev}, err
+	})
+	return fetched.entries, fetched.revision, err
+}
+
+// fetchSources reads every lexicon source and merges them.
+func (s *LexiconService) fetchSources(ctx context.Context) ([]LexiconEntry, lexiconRevision, error) {
+	sources, conflict := s.Sources(), s.Conflict()
+	if len(sources) == 0 {
+		return nil, lexiconRevision{}, fmt.Errorf("no lexicon sources are configured")
 	}
 
-	resp, err := client.Do(req)
+	layers := make([][]LexiconEntry, 0, len(sources))
+	revisions := make([]lexiconRevision, 0, len(sources))
+	for _, source := range sources {
+		entries, rev, err := fetchLexiconFromURL(ctx, source)
+		if err != nil {
+			return nil, lexiconRevision{}, err
+		}
+		layers = append(layers, entries)
+		revisions = append(revisions, rev)
+	}
+
+	merged, err := mergeLexicons(layers, sources, conflict)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch lexicon: %w", err)
+		return nil, lexiconRevision{}, err
+	}
+	if len(revisions) == 1 {
+		return merged, revisions[0], nil
+	}
+
+	// The digest of a layered lexicon covers every layer in order
+	var digests strings.Builder
+	for _, rev := range revisions {
+		digests.WriteString(rev.digest + "\n")
 	}
-	defer resp.Body.Close()
+	layered := lexiconRevision{source: strings.Join(sources, ", "), digest: contentDigest([]byte(digests.String()))}
+	for _, rev := range revisions {
+		for path, modTime := range rev.modTimes {
+			if layered.modTimes == nil {
+				layered.modTimes = map[string]time.Time{}
+			}
+			layered.modTimes[path] = modTime
+		}
+	}
+	return merged, layered, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+// useSnapshot caches the embedded snapshot in place of the upstream lexicon
+// after fetchErr left nothing to serve. Other sources, such as local overlays,
+// are still read and layered over it.
+func (s *LexiconService) useSnapshot(ctx context.Context, fetchErr error) (lexiconState, error) {
+	sources, conflict := s.Sources(), s.Conflict()
+	if !slices.Contains(sources, DefaultLexiconURL) {
+		return lexiconState{}, fmt.Errorf("no snapshot of the configured lexicon sources is embedded")
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	var snapshot []LexiconEntry
+	if err := yaml.Unmarshal(lexiconSnapshot, &snapshot); err != nil {
+		return lexiconState{}, fmt.Errorf("failed to parse embedded lexicon snapshot: %w", err)
+	}
+	layers := make([][]LexiconEntry, 0, len(sources))
+	for _, source := range sources {
+		if source == DefaultLexiconURL {
+			layers = append(layers, snapshot)
+			continue
+		}
+		entries, _, err := fetchLexiconFromURL(ctx, source)
+		if err != nil {
+			return lexiconState{}, err
+		}
+		layers = append(layers, entries)
+	}
+	merged, err := mergeLexicons(layers, sources, conflict)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return lexiconState{}, err
 	}
 
-	var entries []LexiconEntry
-	if err := yaml.Unmarshal(body, &entries); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	emitStaleCache(ctx, "lexicon", strings.Join(sources, ", "), fetchErr)
+	state := lexiconState{
+		entries:  merged,
+		revision: lexiconRevision{source: lexiconSnapshotSource, digest: contentDigest(lexiconSnapshot)},
+		snapshot: true,
 	}
+	s.store(state)
+	return state, nil
+}
 
-	return entries, nil
+// mergeLexicons layers lexicons in order. Terms match case-insensitively; new
+// terms are appended and redefined terms are resolved by the conflict rule.
+func mergeLexicons(layers [][]LexiconEntry, sources []string, rule string) ([]LexiconEntry, error) {
+	var merged []LexiconEntry
+	index := map[string]int{}
+	origin := map[string]string{}
+	for i, layer := range layers {
+		for _, entry := range layer {
+			key := strings.ToLower(entry.Term)
+			j, exists := index[key]
+			if !exists {
+				index[key] = len(merged)
+				origin[key] = sources[i]
+				merged = append(merged, entry)
+				continue
+			}
+			if merged[j].Definition == entry.Definition && slices.Equal(merged[j].References, entry.References) {
+				continue
+			}
+			switch rule {
+			case LexiconConflictPreserve:
+			case LexiconConflictError:
+				return nil, fmt.Errorf("lexicon term %q is defined by both %s and %s", entry.Term, origin[key], sources[i])
+			default:
+				merged[j] = entry
+				origin[key] = sources[i]
+			}
+		}
+	}
+	return merged, nil
 }
 
-// getLexiconWithURL retrieves the lexicon from the specified URL (used for testing).
-func getLexiconWithURL(ctx context.Context, input InputGetLexicon, url string) (*mcp.CallToolResult, OutputGetLexicon, error) {
-	if !input.Refresh && !lexiconCacheTime.IsZero() && time.Since(lexiconCacheTime) < lexiconCacheTTL {
+// getWithURL retrieves the lexicon from the specified URL (used for testing).
+func (s *LexiconService) getWithURL(ctx context.Context, input InputGetLexicon, url string) (*mcp.CallToolResult, OutputGetLexicon, error) {
+	if !input.Refresh && s.fresh() {
 		output := OutputGetLexicon{
-			Entries: lexiconCache,
+			Entries: s.cached().entries,
 			Source:  url,
 			Cached:  true,
 		}
 		return nil, output, nil
 	}
 
-	entries, err := fetchLexiconFromURL(ctx, url)
+	entries, rev, err := fetchLexiconFromURL(ctx, url)
 	if err != nil {
 		return nil, OutputGetLexicon{}, err
 	}
-
-	// Update cache
-	lexiconCache = entries
-	lexiconCacheTime = time.Now()
+	s.store(lexiconState{entries: entries, fetched: time.Now(), revision: rev})
 
 	output := OutputGetLexicon{
 		Entries: entries,
 		Source:  url,
 		Cached:  false,
 	}
-
 	return nil, output, nil
 }
@@ -4,16 +4,25 @@ package tool
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestGetLexicon(t *testing.T) {
+	// Fail fast on server errors rather than backing off between retries
+	require.NoError(t, useHTTPConfig(t, HTTPConfig{Timeout: httpTimeout}))
+
 	tests := []struct {
 		name           string
 		setupServer    func() *httptest.Server
@@ -106,9 +115,8 @@ func TestGetLexicon(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Reset cache for each test
-			lexiconCache = nil
-			lexiconCacheTime = time.Time{}
+			// Each test has its own cache
+			lexicon := NewLexiconService([]string{DefaultLexiconURL}, LexiconConflictOverride)
 
 			server := tt.setupServer()
 			defer server.Close()
@@ -118,12 +126,12 @@ func TestGetLexicon(t *testing.T) {
 			// For cache hit test, make two calls
 			if tt.name == "cache hit on second call" {
 				// First call - should fetch
-				_, output1, err1 := getLexiconWithURL(ctx, InputGetLexicon{Refresh: false}, server.URL)
+				_, output1, err1 := lexicon.getWithURL(ctx, InputGetLexicon{Refresh: false}, server.URL)
 				require.NoError(t, err1, "first call should not error")
 				assert.False(t, output1.Cached, "first call should not be cached")
 
 				// Second call - should use cache
-				_, output2, err2 := getLexiconWithURL(ctx, InputGetLexicon{Refresh: false}, server.URL)
+				_, output2, err2 := lexicon.getWithURL(ctx, InputGetLexicon{Refresh: false}, server.URL)
 				require.NoError(t, err2, "second call should not error")
 				assert.True(t, output2.Cached, "second call should be cached")
 				assert.Equal(t, len(output1.Entries), len(output2.Entries), "cached entries should match")
@@ -133,18 +141,18 @@ func TestGetLexicon(t *testing.T) {
 			// For cache refresh test, make two calls with refresh=true on second
 			if tt.name == "cache refresh bypasses cache" {
 				// First call
-				_, _, err1 := getLexiconWithURL(ctx, InputGetLexicon{Refresh: false}, server.URL)
+				_, _, err1 := lexicon.getWithURL(ctx, InputGetLexicon{Refresh: false}, server.URL)
 				require.NoError(t, err1, "first call should not error")
 
 				// Second call with refresh
-				_, output2, err2 := getLexiconWithURL(ctx, InputGetLexicon{Refresh: true}, server.URL)
+				_, output2, err2 := lexicon.getWithURL(ctx, InputGetLexicon{Refresh: true}, server.URL)
 				require.NoError(t, err2, "refresh call should not error")
 				assert.False(t, output2.Cached, "refresh call should not be cached")
 				return
 			}
 
 			// Regular test execution - use getLexiconWithURL to pass test server URL
-			_, output, err := getLexiconWithURL(ctx, tt.input, server.URL)
+			_, output, err := lexicon.getWithURL(ctx, tt.input, server.URL)
 
 			if tt.wantErr {
 				assert.Error(t, err, "should return error")
@@ -160,3 +168,283 @@ func TestGetLexicon(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeLexicons(t *testing.T) {
+	upstream := []LexiconEntry{
+		{Term: "Control", Definition: "A safeguard"},
+		{Term: "Policy", Definition: "A set of rules"},
+	}
+	org := []LexiconEntry{
+		{Term: "control", Definition: "An ACME safeguard"},
+		{Term: "Policy", Definition: "A set of rules"},
+		{Term: "Crown Jewel", Definition: "A critical asset"},
+	}
+	sources := []string{"upstream", "org"}
+
+	tests := []struct {
+		name           string
+		rule           string
+		wantErr        string
+		wantControl    string
+		wantTermsCount int
+	}{
+		{name: "override", rule: LexiconConflictOverride, wantControl: "An ACME safeguard", wantTermsCount: 3},
+		{name: "preserve", rule: LexiconConflictPreserve, wantControl: "A safeguard", wantTermsCount: 3},
+		{name: "error", rule: LexiconConflictError, wantErr: `lexicon term "control" is defined by both upstream and org`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := mergeLexicons([][]LexiconEntry{upstream, org}, sources, tt.rule)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err, "should merge lexicons")
+			assert.Len(t, merged, tt.wantTermsCount, "identical duplicates should collapse")
+			assert.Equal(t, tt.wantControl, merged[0].Definition)
+			assert.Equal(t, "Crown Jewel", merged[2].Term, "new terms should be appended")
+		})
+	}
+}
+
+func TestFetchLexiconLayers(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "upstream.yaml", "- term: Control\n  definition: A safeguard\n")
+	writeTestFile(t, dir, "org.yaml", "- term: Crown Jewel\n  definition: A critical asset\n")
+
+	lexicon := NewLexiconService([]string{fileURL(filepath.Join(dir, "upstream.yaml")), fileURL(filepath.Join(dir, "org.yaml"))}, LexiconConflictOverride)
+
+	entries, rev, err := lexicon.fetch(context.Background())
+	require.NoError(t, err, "should fetch layered lexicon")
+	assert.Len(t, entries, 2)
+	assert.Equal(t, lexicon.source(), rev.source, "revision should name every source")
+	assert.Contains(t, rev.digest, "sha256:")
+
+	lexicon.Configure(nil, LexiconConflictOverride)
+	_, _, err = lexicon.fetch(context.Background())
+	assert.ErrorContains(t, err, "no lexicon sources")
+}
+
+func TestLocalLexiconCache(t *testing.T) {
+	repo := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "docs"), 0o755))
+	writeTestFile(t, filepath.Join(repo, "docs"), "lexicon.yaml", "- term: Control\n  definition: A safeguard\n")
+	path := filepath.Join(repo, "docs", "lexicon.yaml")
+
+	// A repository checkout resolves to the lexicon it contains
+	lexicon := NewLexiconService([]string{fileURL(repo)}, LexiconConflictOverride)
+	ctx := withLexicon(context.Background(), lexicon)
+	_, output, err := GetLexicon(ctx, nil, InputGetLexicon{})
+	require.NoError(t, err, "should read the lexicon from the checkout")
+	require.Len(t, output.Entries, 1)
+	assert.Equal(t, "A safeguard", output.Entries[0].Definition)
+
+	// Local sources do not expire by TTL
+	lexicon.cache.fetched = time.Now().Add(-2 * lexiconCacheTTL)
+	_, output, err = GetLexicon(ctx, nil, InputGetLexicon{})
+	require.NoError(t, err)
+	assert.True(t, output.Cached, "an unchanged file should be served from cache past the TTL")
+	assert.False(t, output.Stale)
+
+	// Changing the file invalidates the cache
+	require.NoError(t, os.WriteFile(path, []byte("- term: Control\n  definition: A revised safeguard\n"), 0o600))
+	modTime := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+	_, output, err = GetLexicon(ctx, nil, InputGetLexicon{})
+	require.NoError(t, err)
+	assert.False(t, output.Cached, "a modified file should be re-read")
+	assert.Equal(t, "A revised safeguard", output.Entries[0].Definition)
+
+	// A removed file falls back to the cached copy
+	require.NoError(t, os.Remove(path))
+	_, output, err = GetLexicon(ctx, nil, InputGetLexicon{})
+	require.NoError(t, err)
+	assert.True(t, output.Stale, "a missing file should serve the stale cache")
+}
+
+func TestGetLexiconPagination(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "lexicon.yaml", "- term: Assessment\n  definition: A check\n- term: Control\n  definition: A safeguard\n- term: Threat\n  definition: A risk\n")
+
+	lexicon := NewLexiconService([]string{fileURL(filepath.Join(dir, "lexicon.yaml"))}, LexiconConflictOverride)
+	ctx := withLexicon(context.Background(), lexicon)
+	_, output, err := GetLexicon(ctx, nil, InputGetLexicon{PageInput: PageInput{Limit: 2}})
+	require.NoError(t, err)
+	require.Len(t, output.Entries, 2)
+	require.NotNil(t, output.Page)
+	assert.Equal(t, 3, output.Page.Total)
+	require.NotEmpty(t, output.Page.NextCursor, "a partial page should have a cursor")
+
+	_, output, err = GetLexicon(ctx, nil, InputGetLexicon{PageInput: PageInput{Limit: 2, Cursor: output.Page.NextCursor}})
+	require.NoError(t, err)
+	require.Len(t, output.Entries, 1)
+	assert.Equal(t, "Threat", output.Entries[0].Term)
+	assert.Empty(t, output.Page.NextCursor, "the last page should have no cursor")
+
+	_, output, err = GetLexicon(ctx, nil, InputGetLexicon{PageInput: PageInput{MaxOutputBytes: 80}})
+	require.NoError(t, err)
+	assert.Len(t, output.Entries, 1, "entries beyond the byte budget should be left out")
+	assert.True(t, output.Page.Truncated)
+}
+
+func TestValidateLexiconConflict(t *testing.T) {
+	assert.NoError(t, ValidateLexiconConflict(LexiconConflictPreserve))
+	assert.ErrorContains(t, ValidateLexiconConflict("merge"), "unknown lexicon conflict rule")
+}
+
+// offlineTransport fails every request, as when upstream is unreachable.
+type offlineTransport struct{}
+
+func (offlineTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("dial tcp: lookup raw.githubusercontent.com: no such host")
+}
+
+func TestLexiconSnapshotFallback(t *testing.T) {
+	overlay := t.TempDir()
+	writeTestFile(t, overlay, "org.yaml", "- term: Exception\n  definition: An approved deviation from policy\n")
+
+	originalTransport := httpTransport
+	t.Cleanup(func() { httpTransport = originalTransport })
+	httpTransport = offlineTransport{}
+
+	tests := []struct {
+		name      string
+		sources   []string
+		refresh   bool
+		wantErr   bool
+		wantTerms []string
+	}{
+		{
+			name:      "upstream unreachable",
+			sources:   []string{DefaultLexiconURL},
+			wantTerms: []string{"Control", "Threat"},
+		},
+		{
+			name:      "refresh with nothing cached",
+			sources:   []string{DefaultLexiconURL},
+			refresh:   true,
+			wantTerms: []string{"Control"},
+		},
+		{
+			name:      "overlays are layered over the snapshot",
+			sources:   []string{DefaultLexiconURL, fileURL(filepath.Join(overlay, "org.yaml"))},
+			wantTerms: []string{"Control", "Exception"},
+		},
+		{
+			name:    "no snapshot of a custom lexicon",
+			sources: []string{"https://lexicon.example.com/lexicon.yaml"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := withLexicon(context.Background(), NewLexiconService(tt.sources, LexiconConflictOverride))
+
+			_, output, err := GetLexicon(ctx, nil, InputGetLexicon{Refresh: tt.refresh})
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, output.Stale, "the snapshot should be marked stale")
+			assert.False(t, output.Cached)
+			assert.Equal(t, lexiconSnapshotSource, output.Source)
+			var terms []string
+			for _, e := range output.Entries {
+				terms = append(terms, e.Term)
+			}
+			assert.Subset(t, terms, tt.wantTerms)
+
+			// Later reads keep retrying upstream and keep serving the snapshot
+			_, output, err = GetLexicon(ctx, nil, InputGetLexicon{})
+			require.NoError(t, err)
+			assert.True(t, output.Stale)
+			assert.Equal(t, lexiconSnapshotSource, output.Source)
+		})
+	}
+}
+
+func TestAdvisoryModeLexicon(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "upstream.yaml", "- term: Control\n  definition: A safeguard\n")
+	writeTestFile(t, dir, "org.yaml", "- term: Crown Jewel\n  definition: A critical asset\n")
+	ctx := context.Background()
+
+	// Servers of modes with their own lexicon services do not share a cache
+	for _, name := range []string{"upstream.yaml", "org.yaml"} {
+		lexicon := NewLexiconService([]string{fileURL(filepath.Join(dir, name))}, LexiconConflictOverride)
+		server := mcp.NewServer(&mcp.Implementation{Name: "test-server"}, nil)
+		AdvisoryMode{Lexicon: lexicon}.Register(server)
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		_, err := server.Connect(ctx, serverTransport, nil)
+		require.NoError(t, err, "server should connect")
+		session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, clientTransport, nil)
+		require.NoError(t, err, "client should connect")
+		t.Cleanup(func() { _ = session.Close() })
+
+		read, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: LexiconResourceURIAlias})
+		require.NoError(t, err, "should read lexicon resource")
+		var entries []LexiconEntry
+		require.NoError(t, json.Unmarshal([]byte(read.Contents[0].Text), &entries))
+		require.Len(t, entries, 1)
+		assert.Equal(t, lexicon.cached().entries[0].Term, entries[0].Term, "the mode's service should serve the resource")
+	}
+}
+
+func TestLexiconServiceConcurrentReads(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "lexicon.yaml", "- term: Control\n  definition: A safeguard\n")
+	lexicon := NewLexiconService([]string{fileURL(filepath.Join(dir, "lexicon.yaml"))}, LexiconConflictOverride)
+	ctx := withLexicon(context.Background(), lexicon)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(refresh bool) {
+			defer wg.Done()
+			_, output, err := GetLexicon(ctx, nil, InputGetLexicon{Refresh: refresh})
+			assert.NoError(t, err)
+			assert.Len(t, output.Entries, 1)
+		}(i%2 == 0)
+	}
+	wg.Wait()
+	assert.True(t, lexicon.fresh())
+}
+
+func TestSearchLexicon(t *testing.T) {
+	entries := []LexiconEntry{
+		{Term: "Control Catalog", Definition: "A set of controls"},
+		{Term: "Policy", Definition: "Rules that select a control"},
+		{Term: "Control", Definition: "A safeguard"},
+		{Term: "Threat", Definition: "A potential cause of harm"},
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "exact term first", query: "control", want: []string{"Control", "Control Catalog", "Policy"}},
+		{name: "definition only", query: "harm", want: []string{"Threat"}},
+		{name: "no match", query: "assessment", want: nil},
+		{name: "blank query", query: "  ", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var terms []string
+			for _, e := range SearchLexicon(entries, tt.query) {
+				terms = append(terms, e.Term)
+			}
+			assert.Equal(t, tt.want, terms)
+		})
+	}
+
+	entry, ok := LookupTerm(entries, " control catalog ")
+	require.True(t, ok)
+	assert.Equal(t, "Control Catalog", entry.Term)
+	_, ok = LookupTerm(entries, "Catalog")
+	assert.False(t, ok, "lookup should not match partial terms")
+}
@@ -0,0 +1,397 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+	severityInfo    = "info"
+)
+
+// MetadataLintGemaraArtifact describes the LintGemaraArtifact tool.
+var MetadataLintGemaraArtifact = &mcp.Tool{
+	Name: "lint_gemara_artifact",
+	Description: "Check a Gemara artifact against style and best-practice rules beyond schema validity " +
+		"(missing descriptions, empty mappings, duplicate IDs, non-semver versions, inconsistent ID prefixes).",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"artifact_content"},
+		"properties": map[string]interface{}{
+			"artifact_content": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML content of the Gemara artifact to lint",
+			},
+			"rules": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Only run these rule IDs (default: all rules)",
+			},
+		},
+	},
+}
+
+// InputLintGemaraArtifact is the input for the LintGemaraArtifact tool.
+type InputLintGemaraArtifact struct {
+	ArtifactContent string   `json:"artifact_content"`
+	Rules           []string `json:"rules,omitempty"`
+}
+
+// LintFix is a machine-applicable replacement for the value at a path.
+type LintFix struct {
+	Path  string `json:"path"`
+	Value string `json:"value"`
+}
+
+// LintFinding is a single rule violation.
+type LintFinding struct {
+	RuleID     string   `json:"rule_id"`
+	Severity   string   `json:"severity"`
+	Path       string   `json:"path"`
+	Message    string   `json:"message"`
+	Suggestion string   `json:"suggestion,omitempty"`
+	Fix        *LintFix `json:"fix,omitempty"`
+}
+
+// OutputLintGemaraArtifact is the output for the LintGemaraArtifact tool.
+type OutputLintGemaraArtifact struct {
+	Passed   bool           `json:"passed"`
+	Findings []LintFinding  `json:"findings"`
+	Summary  map[string]int `json:"summary"`
+	Message  string         `json:"message"`
+}
+
+// lintRule is a single style or best-practice check. New rules are added to lintRules.
+type lintRule struct {
+	ID          string
+	Description string
+	Severity    string
+	Check       func(doc map[string]interface{}) []LintFinding
+}
+
+// lintRules is the registry of rules applied by the linter.
+var lintRules = []lintRule{
+	{
+		ID:          "missing-description",
+		Description: "Metadata, families, controls, guidelines, and requirements should be described",
+		Severity:    severityWarning,
+		Check:       checkMissingDescriptions,
+	},
+	{
+		ID:          "empty-mapping",
+		Description: "Mapping lists and mapping entries should not be empty",
+		Severity:    severityWarning,
+		Check:       checkEmptyMappings,
+	},
+	{
+		ID:          "duplicate-id",
+		Description: "IDs must be unique within an artifact",
+		Severity:    severityError,
+		Check:       checkDuplicateIDs,
+	},
+	{
+		ID:          "non-semver-version",
+		Description: "Versions should follow semantic versioning",
+		Severity:    severityWarning,
+		Check:       checkSemverVersions,
+	},
+	{
+		ID:          "inconsistent-id-prefix",
+		Description: "Control IDs should share a prefix and requirement IDs should extend their control ID",
+		Severity:    severityInfo,
+		Check:       checkIDPrefixes,
+	},
+}
+
+// LintGemaraArtifact applies the lint rule set to an artifact.
+func LintGemaraArtifact(ctx context.Context, _ *mcp.CallToolRequest, input InputLintGemaraArtifact) (*mcp.CallToolResult, OutputLintGemaraArtifact, error) {
+	if input.ArtifactContent == "" {
+		return nil, OutputLintGemaraArtifact{}, fmt.Errorf("artifact_content is required")
+	}
+
+	rules, err := selectLintRules(input.Rules)
+	if err != nil {
+		return nil, OutputLintGemaraArtifact{}, err
+	}
+
+	doc, err := parseArtifact(ctx, input.ArtifactContent)
+	if err != nil {
+		return nil, OutputLintGemaraArtifact{}, err
+	}
+
+	findings := lintDocument(doc, rules)
+	summary := map[string]int{severityError: 0, severityWarning: 0, severityInfo: 0}
+	for _, f := range findings {
+		summary[f.Severity]++
+	}
+
+	output := OutputLintGemaraArtifact{
+		Passed:   summary[severityError] == 0,
+		Findings: findings,
+		Summary:  summary,
+		Message: fmt.Sprintf("%d error(s), %d warning(s), %d info finding(s)",
+			summary[severityError], summary[severityWarning], summary[severityInfo]),
+	}
+	return nil, output, nil
+}
+
+// selectLintRules returns the rules with the given IDs, or all rules when none are given.
+func selectLintRules(ids []string) ([]lintRule, error) {
+	if len(ids) == 0 {
+		return lintRules, nil
+	}
+
+	byID := make(map[string]lintRule, len(lintRules))
+	for _, r := range lintRules {
+		byID[r.ID] = r
+	}
+
+	selected := make([]lintRule, 0, len(ids))
+	for _, id := range ids {
+		r, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("unknown lint rule %q", id)
+		}
+		selected = append(selected, r)
+	}
+	return selected, nil
+}
+
+// lintDocument runs the rules and stamps each finding with its rule ID and severity.
+func lintDocument(doc map[string]interface{}, rules []lintRule) []LintFinding {
+	findings := []LintFinding{}
+	for _, r := range rules {
+		for _, f := range r.Check(doc) {
+			f.RuleID = r.ID
+			f.Severity = r.Severity
+			findings = append(findings, f)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Path < findings[j].Path
+	})
+	return findings
+}
+
+// describedFields maps entity collections to the field that should describe each entry.
+var describedFields = map[string]string{
+	"families":                "description",
+	"categories":              "description",
+	"controls":                "objective",
+	"guidelines":              "objective",
+	"assessment-requirements": "text",
+}
+
+func checkMissingDescriptions(doc map[string]interface{}) []LintFinding {
+	var findings []LintFinding
+
+	if metadata, ok := doc["metadata"].(map[string]interface{}); ok && isBlank(metadata["description"]) {
+		findings = append(findings, LintFinding{
+			Path:       "$.metadata.description",
+			Message:    "metadata has no description",
+			Suggestion: "Add a metadata.description summarizing the purpose and scope of the artifact",
+		})
+	}
+
+	walkArtifact(doc, "$", func(path string, value interface{}) {
+		id := entityID(value)
+		field, ok := describedFields[lastPathKey(path)]
+		if id == "" || !ok || !strings.HasSuffix(path, "]") {
+			return
+		}
+		if entry := value.(map[string]interface{}); isBlank(entry[field]) {
+			findings = append(findings, LintFinding{
+				Path:       childPath(path, field),
+				Message:    fmt.Sprintf("%s has no %s", id, field),
+				Suggestion: fmt.Sprintf("Add a %s to %s", field, id),
+			})
+		}
+	})
+	return findings
+}
+
+func checkEmptyMappings(doc map[string]interface{}) []LintFinding {
+	var findings []LintFinding
+	walkArtifact(doc, "$", func(path string, value interface{}) {
+		key := lastPathKey(path)
+		if !strings.HasSuffix(key, "mappings") || strings.HasSuffix(path, "]") {
+			return
+		}
+
+		list, ok := value.([]interface{})
+		if ok && len(list) == 0 {
+			findings = append(findings, LintFinding{
+				Path:       path,
+				Message:    fmt.Sprintf("%s is empty", key),
+				Suggestion: fmt.Sprintf("Remove %s or add mapping entries", key),
+			})
+			return
+		}
+
+		for i, item := range list {
+			mapping, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if entries, ok := mapping["entries"].([]interface{}); !ok || len(entries) == 0 {
+				findings = append(findings, LintFinding{
+					Path:       fmt.Sprintf("%s[%d].entries", path, i),
+					Message:    fmt.Sprintf("mapping to %v has no entries", mapping["reference-id"]),
+					Suggestion: "Add the referenced entries or remove the mapping",
+				})
+			}
+		}
+	})
+	return findings
+}
+
+func checkDuplicateIDs(doc map[string]interface{}) []LintFinding {
+	var findings []LintFinding
+	seen := map[string]string{}
+	walkArtifact(doc, "$", func(path string, value interface{}) {
+		id := entityID(value)
+		if id == "" {
+			return
+		}
+		first, dup := seen[id]
+		if !dup {
+			seen[id] = path
+			return
+		}
+
+		replacement := uniqueID(id, seen)
+		seen[replacement] = path
+		findings = append(findings, LintFinding{
+			Path:       childPath(path, "id"),
+			Message:    fmt.Sprintf("ID %s is already used at %s", id, first),
+			Suggestion: fmt.Sprintf("Rename the duplicate to %s", replacement),
+			Fix:        &LintFix{Path: childPath(path, "id"), Value: replacement},
+		})
+	})
+	return findings
+}
+
+// uniqueID returns the first "<id>-<n>" that has not been used.
+func uniqueID(id string, used map[string]string) string {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s-%d", id, n)
+		if _, ok := used[candidate]; !ok {
+			return candidate
+		}
+	}
+}
+
+var (
+	semverPattern  = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+	partialVersion = regexp.MustCompile(`^(v?)(\d+)(?:\.(\d+))?$`)
+)
+
+func checkSemverVersions(doc map[string]interface{}) []LintFinding {
+	var findings []LintFinding
+	walkArtifact(doc, "$", func(path string, value interface{}) {
+		if lastPathKey(path) != "version" {
+			return
+		}
+		version := strings.TrimSpace(fmt.Sprint(value))
+		if semverPattern.MatchString(version) {
+			return
+		}
+
+		finding := LintFinding{
+			Path:       path,
+			Message:    fmt.Sprintf("version %q is not a semantic version", version),
+			Suggestion: "Use MAJOR.MINOR.PATCH (e.g., 1.0.0)",
+		}
+		if m := partialVersion.FindStringSubmatch(version); m != nil {
+			minor := m[3]
+			if minor == "" {
+				minor = "0"
+			}
+			fixed := fmt.Sprintf("%s%s.%s.0", m[1], m[2], minor)
+			finding.Suggestion = fmt.Sprintf("Use %s", fixed)
+			finding.Fix = &LintFix{Path: path, Value: fixed}
+		}
+		findings = append(findings, finding)
+	})
+	return findings
+}
+
+func checkIDPrefixes(doc map[string]interface{}) []LintFinding {
+	controls, _ := doc["controls"].([]interface{})
+	if len(controls) == 0 {
+		return nil
+	}
+
+	// Find the prefix shared by most controls
+	counts := map[string]int{}
+	for _, c := range controls {
+		if id := entityID(c); id != "" {
+			counts[idPrefix(id)]++
+		}
+	}
+	dominant := ""
+	for prefix, n := range counts {
+		if n > counts[dominant] || (n == counts[dominant] && prefix < dominant) {
+			dominant = prefix
+		}
+	}
+
+	var findings []LintFinding
+	for i, c := range controls {
+		id := entityID(c)
+		if id == "" {
+			continue
+		}
+		path := fmt.Sprintf("$.controls[%d]", i)
+
+		if prefix := idPrefix(id); prefix != dominant {
+			fixed := dominant + strings.TrimPrefix(id, prefix)
+			findings = append(findings, LintFinding{
+				Path:       childPath(path, "id"),
+				Message:    fmt.Sprintf("control %s does not use the catalog prefix %s", id, dominant),
+				Suggestion: fmt.Sprintf("Rename to %s", fixed),
+				Fix:        &LintFix{Path: childPath(path, "id"), Value: fixed},
+			})
+		}
+
+		reqs, _ := c.(map[string]interface{})["assessment-requirements"].([]interface{})
+		for j, r := range reqs {
+			reqID := entityID(r)
+			if reqID == "" || strings.HasPrefix(reqID, id+".") {
+				continue
+			}
+			findings = append(findings, LintFinding{
+				Path:       fmt.Sprintf("%s.assessment-requirements[%d].id", path, j),
+				Message:    fmt.Sprintf("requirement %s does not extend its control ID %s", reqID, id),
+				Suggestion: fmt.Sprintf("Prefix the requirement ID with %s.", id),
+			})
+		}
+	}
+	return findings
+}
+
+// idPrefix returns the leading segment of an ID (before the first '.' or '-').
+func idPrefix(id string) string {
+	if i := strings.IndexAny(id, ".-"); i >= 0 {
+		return id[:i]
+	}
+	return id
+}
+
+// isBlank reports whether a value is missing or an empty string.
+func isBlank(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	s, ok := value.(string)
+	return ok && strings.TrimSpace(s) == ""
+}
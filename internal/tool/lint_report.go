@@ -0,0 +1,218 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+)
+
+// lintParseErrorRule is the rule ID reported for files that are not valid YAML.
+const lintParseErrorRule = "parse-error"
+
+// severityRank orders lint severities from least to most severe.
+var severityRank = map[string]int{severityInfo: 1, severityWarning: 2, severityError: 3}
+
+// LintFileFinding is a lint finding located in a file.
+type LintFileFinding struct {
+	LintFinding
+	File string `json:"file"`
+	// Line is the line of the finding's path, or of its closest existing
+	// parent for missing fields.
+	Line int `json:"line,omitempty"`
+}
+
+// LintReport is the result of linting files with LintFiles.
+type LintReport struct {
+	Files    int               `json:"files"`
+	Findings []LintFileFinding `json:"findings"`
+	Summary  map[string]int    `json:"summary"`
+	// Threshold is the least severe finding that fails the report.
+	Threshold string `json:"threshold"`
+	// Failing counts the findings at or above Threshold.
+	Failing int  `json:"failing"`
+	Passed  bool `json:"passed"`
+}
+
+// LintSeverities returns the lint severities, from least to most severe.
+func LintSeverities() []string {
+	return []string{severityInfo, severityWarning, severityError}
+}
+
+// LintFiles applies the lint rules with the given IDs (all rules when none
+// are given) to artifact files. Directories are searched for YAML files as
+// run_conformance_suite does. The report fails when a finding is at least as
+// severe as threshold; files that cannot be parsed are reported as errors.
+func LintFiles(ctx context.Context, paths, ruleIDs []string, threshold string) (LintReport, error) {
+	if _, ok := severityRank[threshold]; !ok {
+		return LintReport{}, fmt.Errorf("unknown severity %q (available: %s)", threshold, strings.Join(LintSeverities(), ", "))
+	}
+	rules, err := selectLintRules(ruleIDs)
+	if err != nil {
+		return LintReport{}, err
+	}
+
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return LintReport{}, fmt.Errorf("failed to read %s: %w", p, err)
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		found, err := findArtifactFiles(p)
+		if err != nil {
+			return LintReport{}, err
+		}
+		files = append(files, found...)
+	}
+
+	report := LintReport{
+		Files:     len(files),
+		Findings:  []LintFileFinding{},
+		Summary:   map[string]int{severityError: 0, severityWarning: 0, severityInfo: 0},
+		Threshold: threshold,
+	}
+	for _, file := range files {
+		findings, err := lintFile(ctx, file, rules)
+		if err != nil {
+			return LintReport{}, err
+		}
+		for _, f := range findings {
+			report.Summary[f.Severity]++
+			if severityRank[f.Severity] >= severityRank[threshold] {
+				report.Failing++
+			}
+		}
+		report.Findings = append(report.Findings, findings...)
+	}
+	report.Passed = report.Failing == 0
+	return report, nil
+}
+
+// lintFile lints one artifact file and locates its findings.
+func lintFile(ctx context.Context, file string, rules []lintRule) ([]LintFileFinding, error) {
+	content, err := readArtifactFile(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.ToSlash(file)
+	doc, err := parseArtifact(ctx, string(content))
+	if err != nil {
+		// The parser's message continues with an excerpt of the source
+		message, _, _ := strings.Cut(err.Error(), "\n")
+		return []LintFileFinding{{
+			LintFinding: LintFinding{RuleID: lintParseErrorRule, Severity: severityError, Path: "$", Message: message},
+			File:        name,
+		}}, nil
+	}
+
+	// Lines are best effort; findings are reported without one if the source
+	// cannot be parsed again with positions
+	source, _ := parser.ParseBytes(content, 0)
+	var located []LintFileFinding
+	for _, f := range lintDocument(doc, rules) {
+		located = append(located, LintFileFinding{LintFinding: f, File: name, Line: findingLine(source, f.Path)})
+	}
+	return located, nil
+}
+
+// findingLine returns the line of the node at path, or of its closest
+// existing parent, in source.
+func findingLine(source *ast.File, path string) int {
+	segments, err := parseArtifactPath(path)
+	if source == nil || err != nil {
+		return 0
+	}
+	for n := len(segments); n >= 0; n-- {
+		candidate := "$"
+		for _, s := range segments[:n] {
+			if s.IsIndex {
+				candidate += fmt.Sprintf("[%d]", s.Index)
+			} else {
+				candidate = childPath(candidate, s.Key)
+			}
+		}
+		p, err := yaml.PathString(candidate)
+		if err != nil {
+			continue
+		}
+		if node, err := p.FilterFile(source); err == nil && node != nil {
+			return node.GetToken().Position.Line
+		}
+	}
+	return 0
+}
+
+// SARIF renders the report as a SARIF 2.1.0 log, as code scanning services
+// such as GitHub code scanning expect.
+func (r LintReport) SARIF(version string) ([]byte, error) {
+	rules := make([]map[string]interface{}, 0, len(lintRules)+1)
+	index := map[string]int{}
+	parseError := lintRule{ID: lintParseErrorRule, Description: "Artifacts must be valid YAML", Severity: severityError}
+	for _, rule := range append(append([]lintRule{}, lintRules...), parseError) {
+		index[rule.ID] = len(rules)
+		rules = append(rules, map[string]interface{}{
+			"id":                   rule.ID,
+			"shortDescription":     map[string]string{"text": rule.Description},
+			"defaultConfiguration": map[string]string{"level": sarifLevel(rule.Severity)},
+		})
+	}
+
+	results := make([]map[string]interface{}, 0, len(r.Findings))
+	for _, f := range r.Findings {
+		message := f.Message
+		if f.Suggestion != "" {
+			message += ". " + f.Suggestion
+		}
+		location := map[string]interface{}{
+			"physicalLocation": map[string]interface{}{
+				"artifactLocation": map[string]string{"uri": f.File},
+			},
+			"logicalLocations": []map[string]string{{"fullyQualifiedName": f.Path}},
+		}
+		if f.Line > 0 {
+			location["physicalLocation"].(map[string]interface{})["region"] = map[string]int{"startLine": f.Line}
+		}
+		results = append(results, map[string]interface{}{
+			"ruleId":    f.RuleID,
+			"ruleIndex": index[f.RuleID],
+			"level":     sarifLevel(f.Severity),
+			"message":   map[string]string{"text": message},
+			"locations": []interface{}{location},
+		})
+	}
+
+	log := map[string]interface{}{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []interface{}{map[string]interface{}{
+			"tool": map[string]interface{}{"driver": map[string]interface{}{
+				"name":           "gemara-mcp",
+				"version":        version,
+				"informationUri": "https://github.com/gemaraproj/gemara-mcp",
+				"rules":          rules,
+			}},
+			"results": results,
+		}},
+	}
+	return json.MarshalIndent(log, "", "  ")
+}
+
+// sarifLevel maps a lint severity to a SARIF result level.
+func sarifLevel(severity string) string {
+	if severity == severityInfo {
+		return "note"
+	}
+	return severity
+}
@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/goccy/go-yaml/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lintDuplicateCatalog = `metadata:
+  id: ACME
+  description: Duplicate controls
+  version: 1.0.0
+controls:
+  - id: AC.01
+    objective: First
+  - id: AC.01
+    objective: Second
+`
+
+func TestLintFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "catalogs/duplicate.yaml", lintDuplicateCatalog)
+	writeTestFile(t, dir, "catalogs/broken.yaml", "controls: [\n")
+	writeTestFile(t, dir, "unversioned.yaml", "metadata:\n  id: ACME\n  description: Unversioned\n  version: \"1\"\n")
+
+	tests := []struct {
+		name        string
+		paths       []string
+		rules       []string
+		threshold   string
+		wantErr     string
+		wantFiles   int
+		wantRules   []string
+		wantFailing int
+	}{
+		{
+			name:        "directory",
+			paths:       []string{filepath.Join(dir, "catalogs")},
+			threshold:   severityError,
+			wantFiles:   2,
+			wantRules:   []string{lintParseErrorRule, "duplicate-id"},
+			wantFailing: 2,
+		},
+		{
+			name:      "warnings below the threshold",
+			paths:     []string{filepath.Join(dir, "unversioned.yaml")},
+			threshold: severityError,
+			wantFiles: 1,
+			wantRules: []string{"non-semver-version"},
+		},
+		{
+			name:        "warnings at the threshold",
+			paths:       []string{filepath.Join(dir, "unversioned.yaml")},
+			threshold:   severityWarning,
+			wantFiles:   1,
+			wantRules:   []string{"non-semver-version"},
+			wantFailing: 1,
+		},
+		{
+			name:      "selected rules",
+			paths:     []string{filepath.Join(dir, "unversioned.yaml"), filepath.Join(dir, "catalogs", "duplicate.yaml")},
+			rules:     []string{"duplicate-id"},
+			threshold: severityInfo,
+			wantFiles: 2,
+			wantRules: []string{"duplicate-id"},
+			// Only the duplicate fails
+			wantFailing: 1,
+		},
+		{name: "unknown rule", paths: []string{dir}, rules: []string{"no-such-rule"}, threshold: severityError, wantErr: "unknown lint rule"},
+		{name: "unknown threshold", paths: []string{dir}, threshold: "fatal", wantErr: "unknown severity"},
+		{name: "missing path", paths: []string{filepath.Join(dir, "missing")}, threshold: severityError, wantErr: "failed to read"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := LintFiles(context.Background(), tt.paths, tt.rules, tt.threshold)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFiles, report.Files)
+			var rules []string
+			for _, f := range report.Findings {
+				rules = append(rules, f.RuleID)
+			}
+			assert.ElementsMatch(t, tt.wantRules, rules)
+			assert.Equal(t, tt.wantFailing, report.Failing)
+			assert.Equal(t, tt.wantFailing == 0, report.Passed)
+		})
+	}
+}
+
+func TestLintReportSARIF(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "duplicate.yaml", lintDuplicateCatalog)
+	report, err := LintFiles(context.Background(), []string{dir}, nil, severityError)
+	require.NoError(t, err)
+	require.Len(t, report.Findings, 1)
+	assert.Equal(t, 8, report.Findings[0].Line, "the finding should point at the duplicate ID")
+
+	raw, err := report.SARIF("v1.2.3")
+	require.NoError(t, err)
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	require.Len(t, log.Runs[0].Results, 1)
+	result := log.Runs[0].Results[0]
+	assert.Equal(t, "duplicate-id", result.RuleID)
+	assert.Equal(t, "duplicate-id", log.Runs[0].Tool.Driver.Rules[result.RuleIndex].ID)
+	assert.Equal(t, "error", result.Level)
+	assert.Equal(t, filepath.ToSlash(filepath.Join(dir, "duplicate.yaml")), result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 8, result.Locations[0].PhysicalLocation.Region.StartLine)
+}
+
+func TestFindingLine(t *testing.T) {
+	source, err := parser.ParseBytes([]byte(lintDuplicateCatalog), 0)
+	require.NoError(t, err)
+	tests := []struct {
+		path string
+		want int
+	}{
+		{path: "$.metadata.version", want: 4},
+		{path: "$.controls[1].objective", want: 9},
+		// Missing fields are located at their closest parent
+		{path: "$.controls[1].title", want: 8},
+		{path: "$.'guideline-mappings'", want: 1},
+		{path: "$.controls[", want: 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, findingLine(source, tt.path), tt.path)
+	}
+	assert.Zero(t, findingLine(nil, "$.controls[0]"))
+}
@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const lintTestCatalog = `metadata:
+  id: TEST
+  version: "1.2"
+  author:
+    id: test
+    name: Test
+    type: Human
+title: Lint Test Catalog
+families:
+  - id: fam
+    title: Family
+controls:
+  - id: TST.C01
+    family: fam
+    title: First
+    objective: First control
+    guideline-mappings: []
+    threat-mappings:
+      - reference-id: THREATS
+        entries: []
+    assessment-requirements:
+      - id: TST.C01.TR01
+        text: Requirement text
+        applicability: [all]
+  - id: TST.C02
+    family: fam
+    title: Second
+    objective: Second control
+    assessment-requirements:
+      - id: REQ-1
+        text: Requirement text
+        applicability: [all]
+  - id: OTHER.C03
+    family: fam
+    title: Third
+    assessment-requirements: []
+  - id: TST.C01
+    family: fam
+    title: Duplicate
+    objective: Duplicate control
+    assessment-requirements: []
+`
+
+func TestLintGemaraArtifact(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          InputLintGemaraArtifact
+		wantErr        bool
+		errContains    string
+		validateOutput func(t *testing.T, output OutputLintGemaraArtifact)
+	}{
+		{
+			name:        "missing artifact_content",
+			input:       InputLintGemaraArtifact{},
+			wantErr:     true,
+			errContains: "artifact_content is required",
+		},
+		{
+			name:        "unknown rule",
+			input:       InputLintGemaraArtifact{ArtifactContent: lintTestCatalog, Rules: []string{"no-such-rule"}},
+			wantErr:     true,
+			errContains: "unknown lint rule",
+		},
+		{
+			name:        "invalid YAML",
+			input:       InputLintGemaraArtifact{ArtifactContent: "invalid: yaml: [unclosed"},
+			wantErr:     true,
+			errContains: "failed to parse YAML",
+		},
+		{
+			name:  "all rules",
+			input: InputLintGemaraArtifact{ArtifactContent: lintTestCatalog},
+			validateOutput: func(t *testing.T, output OutputLintGemaraArtifact) {
+				assert.False(t, output.Passed, "duplicate IDs should fail the lint")
+				byRule := findingsByRule(output.Findings)
+
+				assert.Len(t, byRule["missing-description"], 3, "metadata, family, and OTHER.C03 lack descriptions")
+				assert.Len(t, byRule["empty-mapping"], 2, "empty mapping list and empty entries")
+				require.Len(t, byRule["duplicate-id"], 1, "TST.C01 is duplicated")
+				assert.Equal(t, "TST.C01-2", byRule["duplicate-id"][0].Fix.Value, "should suggest a unique ID")
+				require.Len(t, byRule["non-semver-version"], 1, "version 1.2 is not semver")
+				assert.Equal(t, "1.2.0", byRule["non-semver-version"][0].Fix.Value, "should suggest a semver version")
+				assert.Len(t, byRule["inconsistent-id-prefix"], 2, "OTHER.C03 and REQ-1 are inconsistent")
+				assert.Equal(t, 1, output.Summary[severityError], "summary should count errors")
+			},
+		},
+		{
+			name:  "selected rules only",
+			input: InputLintGemaraArtifact{ArtifactContent: lintTestCatalog, Rules: []string{"non-semver-version"}},
+			validateOutput: func(t *testing.T, output OutputLintGemaraArtifact) {
+				assert.True(t, output.Passed, "no error-level rules selected")
+				require.Len(t, output.Findings, 1, "only the selected rule should run")
+				assert.Equal(t, "non-semver-version", output.Findings[0].RuleID)
+				assert.Equal(t, severityWarning, output.Findings[0].Severity)
+			},
+		},
+		{
+			name:  "clean example passes",
+			input: InputLintGemaraArtifact{ArtifactContent: readExample(t, "ControlCatalog", 1)},
+			validateOutput: func(t *testing.T, output OutputLintGemaraArtifact) {
+				assert.True(t, output.Passed, "example should pass")
+				assert.Empty(t, output.Findings, "example should have no findings")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := LintGemaraArtifact(context.Background(), nil, tt.input)
+
+			if tt.wantErr {
+				require.Error(t, err, "should return error")
+				assert.Contains(t, err.Error(), tt.errContains, "error should contain expected message")
+				return
+			}
+
+			require.NoError(t, err, "should not return error")
+			if tt.validateOutput != nil {
+				tt.validateOutput(t, output)
+			}
+		})
+	}
+}
+
+// findingsByRule groups lint findings by rule ID.
+func findingsByRule(findings []LintFinding) map[string][]LintFinding {
+	byRule := map[string][]LintFinding{}
+	for _, f := range findings {
+		byRule[f.RuleID] = append(byRule[f.RuleID], f)
+	}
+	return byRule
+}
+
+// readExample returns the content of the n-th bundled example for a definition.
+func readExample(t *testing.T, definition string, n int) string {
+	t.Helper()
+	files, err := exampleFiles(definition)
+	require.NoError(t, err, "should list examples")
+	require.GreaterOrEqual(t, len(files), n, "example should exist")
+	content, err := examplesFS.ReadFile(files[n-1])
+	require.NoError(t, err, "should read example")
+	return string(content)
+}
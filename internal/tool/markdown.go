@@ -0,0 +1,322 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MetadataRenderArtifactMarkdown describes the RenderArtifactMarkdown tool.
+var MetadataRenderArtifactMarkdown = &mcp.Tool{
+	Name: "render_artifact_markdown",
+	Description: "Render a ControlCatalog, Policy, or EvaluationLog as human-readable Markdown (tables of controls, " +
+		"requirement lists, assessment plans, result summaries) for PR descriptions, wikis, or audit reports.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"artifact_content": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML content of the Gemara artifact to render",
+			},
+			"artifact_uri": map[string]interface{}{
+				"type": "string",
+				"description": "URI of the artifact to render instead of inline content: file:// (within the workspace root), " +
+					"https://, or gemara://examples/{definition}/{n}",
+			},
+		},
+	},
+}
+
+// InputRenderArtifactMarkdown is the input for the RenderArtifactMarkdown tool.
+type InputRenderArtifactMarkdown struct {
+	ArtifactContent string `json:"artifact_content,omitempty"`
+	ArtifactURI     string `json:"artifact_uri,omitempty"`
+}
+
+// OutputRenderArtifactMarkdown is the output for the RenderArtifactMarkdown tool.
+type OutputRenderArtifactMarkdown struct {
+	Kind     string `json:"kind"`
+	Markdown string `json:"markdown"`
+	Message  string `json:"message"`
+}
+
+// markdownRenderers render each supported artifact kind.
+var markdownRenderers = map[string]func(*strings.Builder, map[string]interface{}){
+	"ControlCatalog": writeCatalogMarkdown,
+	"Policy":         writePolicyMarkdown,
+	"EvaluationLog":  writeEvaluationLogMarkdown,
+}
+
+// RenderArtifactMarkdown renders an artifact as Markdown.
+func RenderArtifactMarkdown(ctx context.Context, _ *mcp.CallToolRequest, input InputRenderArtifactMarkdown) (*mcp.CallToolResult, OutputRenderArtifactMarkdown, error) {
+	content, err := artifactInputContent(ctx, input.ArtifactContent, input.ArtifactURI)
+	if err != nil {
+		return nil, OutputRenderArtifactMarkdown{}, err
+	}
+	doc, err := parseArtifact(ctx, content)
+	if err != nil {
+		return nil, OutputRenderArtifactMarkdown{}, err
+	}
+
+	kind := artifactKind(doc)
+	markdown, err := renderArtifactMarkdown(doc)
+	if err != nil {
+		return nil, OutputRenderArtifactMarkdown{}, err
+	}
+	output := OutputRenderArtifactMarkdown{
+		Kind:     kind,
+		Markdown: markdown,
+		Message:  fmt.Sprintf("Rendered %s as %d lines of Markdown", kind, strings.Count(markdown, "\n")),
+	}
+	return nil, output, nil
+}
+
+// artifactInputContent returns artifact content passed inline or by URI,
+// checked against the artifact limits.
+func artifactInputContent(ctx context.Context, content, uri string) (string, error) {
+	if content == "" && uri == "" {
+		return "", fmt.Errorf("artifact_content or artifact_uri is required")
+	}
+	if content != "" && uri != "" {
+		return "", fmt.Errorf("artifact_content and artifact_uri are mutually exclusive")
+	}
+	if uri != "" {
+		raw, err := readArtifactURI(ctx, uri)
+		if err != nil {
+			return "", err
+		}
+		content = string(raw)
+	}
+	if err := checkArtifactLimits(ctx, formatYAML, content); err != nil {
+		return "", err
+	}
+	return content, nil
+}
+
+// renderArtifactMarkdown renders a parsed artifact of a supported kind as Markdown.
+func renderArtifactMarkdown(doc map[string]interface{}) (string, error) {
+	kind := artifactKind(doc)
+	render, ok := markdownRenderers[kind]
+	if !ok {
+		kinds := make([]string, 0, len(markdownRenderers))
+		for k := range markdownRenderers {
+			kinds = append(kinds, k)
+		}
+		sort.Strings(kinds)
+		if kind == "" {
+			kind = "unknown"
+		}
+		return "", fmt.Errorf("cannot render %s artifacts: expected one of %s", kind, strings.Join(kinds, ", "))
+	}
+
+	var b strings.Builder
+	writeMarkdownHeader(&b, doc, kind)
+	render(&b, doc)
+	return b.String(), nil
+}
+
+// writeMarkdownHeader writes the title and metadata shared by every artifact.
+func writeMarkdownHeader(b *strings.Builder, doc map[string]interface{}, kind string) {
+	metadata, _ := doc["metadata"].(map[string]interface{})
+	title := stringField(doc, "title")
+	if title == "" {
+		title = stringField(metadata, "id")
+	}
+	if title == "" {
+		title = kind
+	}
+	fmt.Fprintf(b, "# %s\n\n", title)
+
+	fields := [][2]string{{"Kind", kind}, {"ID", stringField(metadata, "id")}, {"Version", stringField(metadata, "version")}}
+	if author, ok := metadata["author"].(map[string]interface{}); ok {
+		fields = append(fields, [2]string{"Author", stringField(author, "name")})
+	}
+	for _, f := range fields {
+		if f[1] != "" {
+			fmt.Fprintf(b, "- **%s**: %s\n", f[0], markdownInline(f[1]))
+		}
+	}
+	b.WriteString("\n")
+	if description := stringField(metadata, "description"); description != "" {
+		fmt.Fprintf(b, "%s\n\n", strings.TrimSpace(description))
+	}
+}
+
+// writeCatalogMarkdown writes a table of controls per family, then the
+// assessment requirements of each control.
+func writeCatalogMarkdown(b *strings.Builder, doc map[string]interface{}) {
+	controls := mapList(doc["controls"])
+	families := mapList(doc["families"])
+	familyTitles := map[string]string{}
+	var order []string
+	for _, f := range families {
+		id := stringField(f, "id")
+		familyTitles[id] = stringField(f, "title")
+		order = append(order, id)
+	}
+	byFamily := map[string][]map[string]interface{}{}
+	for _, c := range controls {
+		family := stringField(c, "family")
+		if _, known := familyTitles[family]; !known {
+			familyTitles[family] = family
+			order = append(order, family)
+		}
+		byFamily[family] = append(byFamily[family], c)
+	}
+
+	b.WriteString("## Controls\n\n")
+	for _, family := range order {
+		if len(byFamily[family]) == 0 {
+			continue
+		}
+		heading := familyTitles[family]
+		if heading == "" {
+			heading = "Other"
+		}
+		fmt.Fprintf(b, "### %s\n\n", markdownInline(heading))
+		b.WriteString("| ID | Title | Objective | Requirements |\n|---|---|---|---|\n")
+		for _, c := range byFamily[family] {
+			fmt.Fprintf(b, "| %s | %s | %s | %d |\n", markdownCell(stringField(c, "id")), markdownCell(stringField(c, "title")),
+				markdownCell(stringField(c, "objective")), len(mapList(c["assessment-requirements"])))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Assessment Requirements\n\n")
+	for _, c := range controls {
+		requirements := mapList(c["assessment-requirements"])
+		if len(requirements) == 0 {
+			continue
+		}
+		fmt.Fprintf(b, "### %s: %s\n\n", markdownInline(stringField(c, "id")), markdownInline(stringField(c, "title")))
+		for _, r := range requirements {
+			fmt.Fprintf(b, "- **%s**: %s", markdownInline(stringField(r, "id")), markdownInline(stringField(r, "text")))
+			if applicability := stringList(r["applicability"]); len(applicability) > 0 {
+				fmt.Fprintf(b, " _(applies to: %s)_", markdownInline(strings.Join(applicability, ", ")))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+}
+
+// writePolicyMarkdown writes the imported catalogs and the assessment plans.
+func writePolicyMarkdown(b *strings.Builder, doc map[string]interface{}) {
+	imports, _ := doc["imports"].(map[string]interface{})
+	if catalogs := mapList(imports["catalogs"]); len(catalogs) > 0 {
+		b.WriteString("## Imported Catalogs\n\n")
+		for _, c := range catalogs {
+			fmt.Fprintf(b, "- %s\n", markdownInline(stringField(c, "reference-id")))
+		}
+		b.WriteString("\n")
+	}
+
+	adherence, _ := doc["adherence"].(map[string]interface{})
+	plans := mapList(adherence["assessment-plans"])
+	if len(plans) == 0 {
+		return
+	}
+	b.WriteString("## Assessment Plans\n\n| ID | Requirement | Frequency | Methods |\n|---|---|---|---|\n")
+	for _, p := range plans {
+		var methods []string
+		for _, m := range mapList(p["evaluation-methods"]) {
+			methods = append(methods, stringField(m, "type"))
+		}
+		fmt.Fprintf(b, "| %s | %s | %s | %s |\n", markdownCell(stringField(p, "id")), markdownCell(stringField(p, "requirement-id")),
+			markdownCell(stringField(p, "frequency")), markdownCell(strings.Join(methods, ", ")))
+	}
+	b.WriteString("\n")
+}
+
+// writeEvaluationLogMarkdown writes a summary of results, a table of
+// evaluations, and the assessment logs of every evaluation that did not pass.
+func writeEvaluationLogMarkdown(b *strings.Builder, doc map[string]interface{}) {
+	evaluations := mapList(doc["evaluations"])
+	counts := map[string]int{}
+	var results []string
+	for _, e := range evaluations {
+		result := stringField(e, "result")
+		if counts[result] == 0 {
+			results = append(results, result)
+		}
+		counts[result]++
+	}
+
+	b.WriteString("## Summary\n\n| Result | Evaluations |\n|---|---|\n")
+	for _, result := range results {
+		fmt.Fprintf(b, "| %s | %d |\n", markdownCell(result), counts[result])
+	}
+	fmt.Fprintf(b, "| **Total** | %d |\n\n", len(evaluations))
+
+	b.WriteString("## Evaluations\n\n| Control | Evaluation | Result |\n|---|---|---|\n")
+	for _, e := range evaluations {
+		fmt.Fprintf(b, "| %s | %s | %s |\n", markdownCell(mappingEntryID(e["control"])), markdownCell(stringField(e, "name")),
+			markdownCell(stringField(e, "result")))
+	}
+	b.WriteString("\n")
+
+	var unresolved []map[string]interface{}
+	for _, e := range evaluations {
+		if stringField(e, "result") != "Passed" {
+			unresolved = append(unresolved, e)
+		}
+	}
+	if len(unresolved) == 0 {
+		return
+	}
+	b.WriteString("## Findings\n\n")
+	for _, e := range unresolved {
+		fmt.Fprintf(b, "### %s: %s (%s)\n\n", markdownInline(mappingEntryID(e["control"])), markdownInline(stringField(e, "name")),
+			markdownInline(stringField(e, "result")))
+		for _, l := range mapList(e["assessment-logs"]) {
+			fmt.Fprintf(b, "- **%s** %s: %s\n", markdownInline(mappingEntryID(l["requirement"])), markdownInline(stringField(l, "result")),
+				markdownInline(stringField(l, "description")))
+		}
+		b.WriteString("\n")
+	}
+}
+
+// markdownInline collapses text onto a single line.
+func markdownInline(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// markdownCell makes text safe to place in a Markdown table cell.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(markdownInline(s), "|", `\|`)
+}
+
+// stringField returns a string field of a mapping, or an empty string.
+func stringField(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// mapList returns the mappings in a sequence node, skipping other values.
+func mapList(v interface{}) []map[string]interface{} {
+	items, _ := v.([]interface{})
+	maps := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			maps = append(maps, m)
+		}
+	}
+	return maps
+}
+
+// stringList returns the strings in a sequence node, skipping other values.
+func stringList(v interface{}) []string {
+	items, _ := v.([]interface{})
+	var strs []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+	return strs
+}
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderArtifactMarkdown(t *testing.T) {
+	read := func(name string) string {
+		content, err := os.ReadFile(filepath.Join("test-data", name))
+		require.NoError(t, err, "should read test data file")
+		return string(content)
+	}
+
+	tests := []struct {
+		name         string
+		input        InputRenderArtifactMarkdown
+		wantKind     string
+		wantContains []string
+		errContains  string
+	}{
+		{
+			name:     "catalog",
+			input:    InputRenderArtifactMarkdown{ArtifactContent: read("good-ccc.yaml")},
+			wantKind: "ControlCatalog",
+			wantContains: []string{
+				"## Controls",
+				"| ID | Title | Objective | Requirements |",
+				"| CCC.C01 | Prevent Unencrypted Requests |",
+				"### CCC.C01: Prevent Unencrypted Requests",
+				"- **CCC.C01.TR01**: When a port is exposed",
+				"_(applies to: tlp_clear",
+			},
+		},
+		{
+			name:     "policy",
+			input:    InputRenderArtifactMarkdown{ArtifactContent: read("policy.yaml")},
+			wantKind: "Policy",
+			wantContains: []string{
+				"# Cloud Data Protection Policy",
+				"- FINOS-CCC",
+				"| AP-C01-TR01 | CCC.C01.TR01 | daily | automated |",
+			},
+		},
+		{
+			name:     "evaluation log",
+			input:    InputRenderArtifactMarkdown{ArtifactContent: read("evaluation-log.yaml")},
+			wantKind: "EvaluationLog",
+			wantContains: []string{
+				"| Passed | 1 |",
+				"| Failed | 1 |",
+				"| **Total** | 2 |",
+				"### CCC.C06: Prevent Deployment in Restricted Regions (Failed)",
+				"- **CCC.C06.TR01** Failed: Bucket found in restricted region",
+			},
+		},
+		{
+			name:         "example by URI",
+			input:        InputRenderArtifactMarkdown{ArtifactURI: "gemara://examples/ControlCatalog/1"},
+			wantKind:     "ControlCatalog",
+			wantContains: []string{"# Object Storage Controls", "### Data Protection"},
+		},
+		{
+			name:         "table cells escaped",
+			input:        InputRenderArtifactMarkdown{ArtifactContent: "title: T\ncontrols:\n  - id: X.C01\n    title: \"a | b\"\n    objective: |\n      line one\n      line two\n"},
+			wantKind:     "ControlCatalog",
+			wantContains: []string{`| X.C01 | a \| b | line one line two | 0 |`},
+		},
+		{
+			name:        "missing content",
+			input:       InputRenderArtifactMarkdown{},
+			errContains: "artifact_content or artifact_uri is required",
+		},
+		{
+			name:        "unsupported kind",
+			input:       InputRenderArtifactMarkdown{ArtifactContent: "title: T\nguidelines: []\n"},
+			errContains: "cannot render GuidanceDocument artifacts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := RenderArtifactMarkdown(context.Background(), nil, tt.input)
+			if tt.errContains != "" {
+				require.Error(t, err, "should return error")
+				assert.Contains(t, err.Error(), tt.errContains, "error should contain expected message")
+				return
+			}
+			require.NoError(t, err, "should not return error")
+			assert.Equal(t, tt.wantKind, output.Kind, "kind should match")
+			for _, want := range tt.wantContains {
+				assert.Contains(t, output.Markdown, want, "markdown should contain %q", want)
+			}
+		})
+	}
+}
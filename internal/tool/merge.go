@@ -0,0 +1,350 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ID collision strategies for merging catalogs.
+const (
+	// MergeStrategyError fails the merge on any collision.
+	MergeStrategyError = "error"
+	// MergeStrategyPrefix renames a colliding entry with its catalog's ID as a prefix.
+	MergeStrategyPrefix = "prefix"
+	// MergeStrategyPreferFirst keeps the entry of the first catalog that has the ID.
+	MergeStrategyPreferFirst = "prefer-first"
+)
+
+var mergeStrategies = []string{MergeStrategyError, MergeStrategyPrefix, MergeStrategyPreferFirst}
+
+// MetadataMergeCatalogs describes the MergeCatalogs tool.
+var MetadataMergeCatalogs = &mcp.Tool{
+	Name: "merge_catalogs",
+	Description: "Combine several ControlCatalogs, such as per-team catalogs, into one org baseline. Families, controls, " +
+		"and other lists are concatenated by id; identical duplicates are kept once and differing entries with the same " +
+		"id are resolved by the chosen strategy. Metadata lists are merged the same way, and the merged catalog is " +
+		"validated against the schema. Nothing is written to disk.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"catalogs"},
+		"properties": map[string]interface{}{
+			"catalogs": catalogListSchema("ControlCatalogs to merge, in order of precedence"),
+			"strategy": map[string]interface{}{
+				"type": "string",
+				"enum": mergeStrategies,
+				"description": "How to resolve entries of different catalogs with the same id but different content: " +
+					"error (fail, the default), prefix (rename the later entry to <catalog id>.<id> and update references to it), " +
+					"or prefer-first (keep the earlier entry)",
+			},
+			"id": map[string]interface{}{
+				"type":        "string",
+				"description": "metadata.id of the merged catalog (default: that of the first catalog)",
+			},
+			"title": map[string]interface{}{
+				"type":        "string",
+				"description": "Title of the merged catalog (default: that of the first catalog)",
+			},
+			"description": map[string]interface{}{
+				"type":        "string",
+				"description": "metadata.description of the merged catalog (default: that of the first catalog)",
+			},
+		},
+	},
+}
+
+// catalogListSchema is the artifact list schema for two or more catalogs.
+func catalogListSchema(description string) map[string]interface{} {
+	schema := map[string]interface{}{}
+	for k, v := range artifactInputSchema {
+		schema[k] = v
+	}
+	schema["description"] = description
+	schema["minItems"] = 2
+	return schema
+}
+
+// InputMergeCatalogs is the input for the MergeCatalogs tool.
+type InputMergeCatalogs struct {
+	Catalogs    []ArtifactInput `json:"catalogs"`
+	Strategy    string          `json:"strategy,omitempty"`
+	ID          string          `json:"id,omitempty"`
+	Title       string          `json:"title,omitempty"`
+	Description string          `json:"description,omitempty"`
+}
+
+// MergeCollision is an id used by differing entries of several catalogs, and
+// how it was resolved.
+type MergeCollision struct {
+	List    string `json:"list"`
+	ID      string `json:"id"`
+	Catalog string `json:"catalog"`
+	// Kept names the catalog whose entry has the id in the merged catalog.
+	Kept string `json:"kept"`
+	// RenamedTo is the new id of the entry from Catalog under the prefix strategy.
+	RenamedTo string `json:"renamed_to,omitempty"`
+}
+
+// OutputMergeCatalogs is the output for the MergeCatalogs tool.
+type OutputMergeCatalogs struct {
+	MergedContent string           `json:"merged_content"`
+	Controls      int              `json:"controls"`
+	Duplicates    int              `json:"duplicates"`
+	Collisions    []MergeCollision `json:"collisions"`
+	Valid         bool             `json:"valid"`
+	Errors        []string         `json:"errors,omitempty"`
+	Message       string           `json:"message"`
+}
+
+// mergeSource is a parsed catalog being merged.
+type mergeSource struct {
+	name   string
+	prefix string
+	doc    yaml.MapSlice
+}
+
+// MergeCatalogs merges several control catalogs into one.
+func MergeCatalogs(ctx context.Context, _ *mcp.CallToolRequest, input InputMergeCatalogs) (*mcp.CallToolResult, OutputMergeCatalogs, error) {
+	if len(input.Catalogs) < 2 {
+		return nil, OutputMergeCatalogs{}, fmt.Errorf("at least two catalogs are required")
+	}
+	strategy := input.Strategy
+	if strategy == "" {
+		strategy = MergeStrategyError
+	}
+	if !slices.Contains(mergeStrategies, strategy) {
+		return nil, OutputMergeCatalogs{}, fmt.Errorf("unknown strategy %q (available: %s)", strategy, strings.Join(mergeStrategies, ", "))
+	}
+
+	sources := make([]mergeSource, 0, len(input.Catalogs))
+	for i, c := range input.Catalogs {
+		name := c.Name
+		if name == "" {
+			name = fmt.Sprintf("catalogs[%d]", i)
+		}
+		parsed, err := parseArtifact(ctx, c.Content)
+		if err != nil {
+			return nil, OutputMergeCatalogs{}, fmt.Errorf("%s: %w", name, err)
+		}
+		if kind := artifactKind(parsed); kind != "ControlCatalog" {
+			return nil, OutputMergeCatalogs{}, fmt.Errorf("%s is not a ControlCatalog", name)
+		}
+		var doc yaml.MapSlice
+		if err := yaml.UnmarshalWithOptions([]byte(c.Content), &doc, yaml.UseOrderedMap()); err != nil {
+			return nil, OutputMergeCatalogs{}, fmt.Errorf("%s: failed to parse YAML: %w", name, err)
+		}
+		metadata, _ := parsed["metadata"].(map[string]interface{})
+		prefix := stringField(metadata, "id")
+		if prefix == "" {
+			prefix = strings.TrimSuffix(strings.TrimSuffix(name, ".yaml"), ".yml")
+		}
+		sources = append(sources, mergeSource{name: name, prefix: prefix, doc: doc})
+	}
+
+	merged, output, err := mergeCatalogSources(sources, strategy)
+	if err != nil {
+		return nil, OutputMergeCatalogs{}, err
+	}
+	metadata, _ := mapValue(merged, "metadata").(yaml.MapSlice)
+	if input.ID != "" {
+		metadata = setMapValue(metadata, "id", input.ID)
+	}
+	if input.Description != "" {
+		metadata = setMapValue(metadata, "description", input.Description)
+	}
+	if metadata != nil {
+		merged = setMapValue(merged, "metadata", metadata)
+	}
+	if input.Title != "" {
+		merged = setMapValue(merged, "title", input.Title)
+	}
+
+	out, err := yaml.MarshalWithOptions(merged, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return nil, OutputMergeCatalogs{}, fmt.Errorf("failed to encode merged catalog: %w", err)
+	}
+	output.MergedContent = string(out)
+	controls, _ := mapValue(merged, "controls").([]interface{})
+	output.Controls = len(controls)
+
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputMergeCatalogs{}, err
+	}
+	validation, err := validateAgainstSchema(schema, "#ControlCatalog", output.MergedContent)
+	if err != nil {
+		return nil, OutputMergeCatalogs{}, err
+	}
+	output.Valid, output.Errors = validation.Valid, validation.Errors
+	output.Message = fmt.Sprintf("Merged %d catalogs into %d control(s) with the %s strategy (%d duplicate(s) kept once, %d collision(s)); %s",
+		len(sources), output.Controls, strategy, output.Duplicates, len(output.Collisions), strings.ToLower(validation.Message))
+	return nil, output, nil
+}
+
+// mergeCatalogSources concatenates the lists of the catalogs by id, resolving
+// collisions by strategy, and keeps the first value of every other field.
+func mergeCatalogSources(sources []mergeSource, strategy string) (yaml.MapSlice, OutputMergeCatalogs, error) {
+	output := OutputMergeCatalogs{Collisions: []MergeCollision{}}
+	var merged yaml.MapSlice
+	// owners records which catalog contributed each id of each list
+	owners := map[string]map[string]int{}
+	var unresolved []string
+
+	for s, source := range sources {
+		renamed := map[string]map[string]string{}
+		var added []yaml.MapSlice
+		for _, item := range source.doc {
+			key := fmt.Sprint(item.Key)
+			if key == "metadata" {
+				continue
+			}
+			list, isList := item.Value.([]interface{})
+			existing, exists := mapValue(merged, key).([]interface{})
+			if !isList {
+				if mapValue(merged, key) == nil {
+					merged = setMapValue(merged, key, item.Value)
+				}
+				continue
+			}
+			if !exists {
+				existing = []interface{}{}
+			}
+			if owners[key] == nil {
+				owners[key] = map[string]int{}
+			}
+
+			for _, elem := range list {
+				id := orderedID(elem)
+				if id == "" {
+					existing = append(existing, elem)
+					continue
+				}
+				owner, taken := owners[key][id]
+				if !taken {
+					owners[key][id] = s
+					existing = append(existing, elem)
+					if m, ok := elem.(yaml.MapSlice); ok {
+						added = append(added, m)
+					}
+					continue
+				}
+				if reflect.DeepEqual(findByID(existing, id), elem) {
+					output.Duplicates++
+					continue
+				}
+
+				collision := MergeCollision{List: key, ID: id, Catalog: source.name, Kept: sources[owner].name}
+				switch strategy {
+				case MergeStrategyError:
+					unresolved = append(unresolved, fmt.Sprintf("%s %s in %s differs from %s", key, id, source.name, sources[owner].name))
+				case MergeStrategyPreferFirst:
+				case MergeStrategyPrefix:
+					newID := source.prefix + "." + id
+					if _, clash := owners[key][newID]; clash {
+						return nil, OutputMergeCatalogs{}, fmt.Errorf("cannot rename %s %s of %s: %s is also taken", key, id, source.name, newID)
+					}
+					owners[key][newID] = s
+					entry := setMapValue(append(yaml.MapSlice{}, elem.(yaml.MapSlice)...), "id", newID)
+					existing = append(existing, entry)
+					added = append(added, entry)
+					if renamed[key] == nil {
+						renamed[key] = map[string]string{}
+					}
+					renamed[key][id] = newID
+					collision.RenamedTo = newID
+				}
+				output.Collisions = append(output.Collisions, collision)
+			}
+			merged = setMapValue(merged, key, existing)
+		}
+		renameCatalogReferences(added, renamed)
+	}
+	if len(unresolved) > 0 {
+		return nil, OutputMergeCatalogs{}, fmt.Errorf("%d id collision(s): %s; merge with strategy %s or %s to resolve them",
+			len(unresolved), strings.Join(unresolved, "; "), MergeStrategyPrefix, MergeStrategyPreferFirst)
+	}
+
+	if metadata := mergeCatalogMetadata(sources); metadata != nil {
+		merged = append(yaml.MapSlice{{Key: "metadata", Value: metadata}}, merged...)
+	}
+	return merged, output, nil
+}
+
+// renameCatalogReferences points the family of the entries a catalog
+// contributed at its renamed family, and renames the assessment requirements
+// of its renamed controls to match.
+func renameCatalogReferences(entries []yaml.MapSlice, renamed map[string]map[string]string) {
+	for _, entry := range entries {
+		for i, item := range entry {
+			switch item.Key {
+			case "family":
+				if newID, ok := renamed["families"][fmt.Sprint(item.Value)]; ok {
+					entry[i].Value = newID
+				}
+			case "assessment-requirements":
+				controlID := orderedID(entry)
+				for oldID, newID := range renamed["controls"] {
+					if newID != controlID {
+						continue
+					}
+					requirements, _ := item.Value.([]interface{})
+					renamedRequirements := make([]interface{}, 0, len(requirements))
+					for _, r := range requirements {
+						m, ok := r.(yaml.MapSlice)
+						if id := orderedID(r); ok && strings.HasPrefix(id, oldID) {
+							m = setMapValue(append(yaml.MapSlice{}, m...), "id", newID+strings.TrimPrefix(id, oldID))
+							r = m
+						}
+						renamedRequirements = append(renamedRequirements, r)
+					}
+					entry[i].Value = renamedRequirements
+				}
+			}
+		}
+	}
+}
+
+// mergeCatalogMetadata keeps the metadata of the first catalog, adding the
+// entries of the others' metadata lists, such as mapping references, by id.
+func mergeCatalogMetadata(sources []mergeSource) yaml.MapSlice {
+	var docs []yaml.MapSlice
+	var names []string
+	for _, source := range sources {
+		if m, ok := mapValue(source.doc, "metadata").(yaml.MapSlice); ok {
+			docs = append(docs, m)
+			names = append(names, source.name)
+		}
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+	merged, _ := mergeCatalogDocuments(docs, names)
+	return merged
+}
+
+// mapValue returns the value of key in an ordered mapping, or nil.
+func mapValue(m yaml.MapSlice, key string) interface{} {
+	for _, item := range m {
+		if fmt.Sprint(item.Key) == key {
+			return item.Value
+		}
+	}
+	return nil
+}
+
+// findByID returns the list element with the id, or nil.
+func findByID(list []interface{}, id string) interface{} {
+	for _, elem := range list {
+		if orderedID(elem) == id {
+			return elem
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const teamACatalog = `metadata:
+  id: TEAM-A
+  description: Team A controls
+  author:
+    id: team-a
+    name: Team A
+    type: Human
+  applicability-categories:
+    - id: prod
+      title: Production
+      description: Production.
+title: Team A Catalog
+families:
+  - id: access
+    title: Access
+    description: Access.
+controls:
+  - id: AC.C01
+    family: access
+    title: Require MFA
+    objective: Users use a second factor.
+    assessment-requirements:
+      - id: AC.C01.TR01
+        text: MFA is enforced.
+        applicability: [prod]
+`
+
+const teamBCatalog = `metadata:
+  id: TEAM-B
+  description: Team B controls
+  author:
+    id: team-b
+    name: Team B
+    type: Human
+  applicability-categories:
+    - id: prod
+      title: Production
+      description: Production.
+    - id: dev
+      title: Development
+      description: Development.
+title: Team B Catalog
+families:
+  - id: access
+    title: Access Management
+    description: Access Management.
+controls:
+  - id: AC.C01
+    family: access
+    title: Rotate credentials
+    objective: Credentials are rotated.
+    assessment-requirements:
+      - id: AC.C01.TR01
+        text: Keys are rotated every 90 days.
+        applicability: [dev]
+  - id: AC.C02
+    family: access
+    title: Review access
+    objective: Access is reviewed quarterly.
+    assessment-requirements:
+      - id: AC.C02.TR01
+        text: Reviews are recorded.
+        applicability: [prod]
+`
+
+func TestMergeCatalogs(t *testing.T) {
+	useTestSchema(t)
+	ctx := context.Background()
+	catalogs := []ArtifactInput{{Name: "a.yaml", Content: teamACatalog}, {Name: "b.yaml", Content: teamBCatalog}}
+
+	tests := []struct {
+		name           string
+		input          InputMergeCatalogs
+		wantControls   int
+		wantCollisions int
+		wantContent    []string
+		notContent     []string
+		errContains    string
+	}{
+		{
+			name:        "error strategy reports collisions",
+			input:       InputMergeCatalogs{Catalogs: catalogs},
+			errContains: "2 id collision(s): families access in b.yaml differs from a.yaml; controls AC.C01",
+		},
+		{
+			name:           "prefer first keeps earlier entries",
+			input:          InputMergeCatalogs{Catalogs: catalogs, Strategy: MergeStrategyPreferFirst},
+			wantControls:   2,
+			wantCollisions: 2,
+			wantContent:    []string{"title: Require MFA", "id: AC.C02", "id: dev"},
+			notContent:     []string{"Rotate credentials", "Access Management"},
+		},
+		{
+			name:           "prefix renames later entries and their references",
+			input:          InputMergeCatalogs{Catalogs: catalogs, Strategy: MergeStrategyPrefix, ID: "ORG", Title: "Org Baseline"},
+			wantControls:   3,
+			wantCollisions: 2,
+			wantContent: []string{
+				"id: ORG", "title: Org Baseline", "id: TEAM-B.access", "id: TEAM-B.AC.C01",
+				"family: TEAM-B.access", "id: TEAM-B.AC.C01.TR01", "id: AC.C01.TR01",
+			},
+		},
+		{
+			name:         "identical entries are kept once",
+			input:        InputMergeCatalogs{Catalogs: []ArtifactInput{{Content: teamACatalog}, {Content: teamACatalog}}},
+			wantControls: 1,
+		},
+		{
+			name:        "unknown strategy",
+			input:       InputMergeCatalogs{Catalogs: catalogs, Strategy: "newest"},
+			errContains: "unknown strategy",
+		},
+		{
+			name:        "single catalog",
+			input:       InputMergeCatalogs{Catalogs: catalogs[:1]},
+			errContains: "at least two",
+		},
+		{
+			name:        "not a catalog",
+			input:       InputMergeCatalogs{Catalogs: []ArtifactInput{catalogs[0], {Name: "log.yaml", Content: "evaluations: []\n"}}},
+			errContains: "log.yaml is not a ControlCatalog",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := MergeCatalogs(ctx, nil, tt.input)
+			if tt.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, output.Valid, "merged catalog should be valid: %v", output.Errors)
+			assert.Equal(t, tt.wantControls, output.Controls)
+			assert.Len(t, output.Collisions, tt.wantCollisions)
+			for _, want := range tt.wantContent {
+				assert.Contains(t, output.MergedContent, want)
+			}
+			for _, unwanted := range tt.notContent {
+				assert.NotContains(t, output.MergedContent, unwanted)
+			}
+		})
+	}
+}
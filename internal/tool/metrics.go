@@ -0,0 +1,313 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MetricsPath is where the metrics handler is served.
+	MetricsPath = "/metrics"
+
+	openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+	// prometheusContentType is the Prometheus text format, served to scrapers
+	// that do not ask for OpenMetrics.
+	prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// MetricsHandler serves the compliance status of the workspace evaluation logs
+// in the OpenMetrics text format, or the Prometheus text format unless the
+// scraper accepts OpenMetrics, for Prometheus and the OpenTelemetry Collector's
+// Prometheus receiver. With tenants, the workspace of every tenant is reported
+// and each series carries a tenant label; otherwise the workspace root of
+// config is. The metrics of a workspace are recomputed when any of its
+// artifacts was added, removed, or modified since the last scrape, except for
+// overdue findings, which are counted on every scrape.
+func MetricsHandler(config *Config, tenants []*Tenant) http.Handler {
+	var workspaces []*workspaceMetrics
+	for _, t := range tenants {
+		workspaces = append(workspaces, &workspaceMetrics{root: t.WorkspaceRoot, tenant: t})
+	}
+	if tenants == nil && config.WorkspaceRoot != "" {
+		workspaces = append(workspaces, &workspaceMetrics{root: config.WorkspaceRoot})
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(workspaces) == 0 {
+			http.Error(w, "metrics require a workspace root", http.StatusServiceUnavailable)
+			return
+		}
+		ctx := WithConfig(r.Context(), config)
+		asOf := time.Now().UTC()
+		families := newMetricFamilies()
+		for _, workspace := range workspaces {
+			if err := workspace.collect(ctx, families, asOf); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		var b bytes.Buffer
+		families.write(&b)
+		if strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text") {
+			w.Header().Set("Content-Type", openMetricsContentType)
+			_, _ = w.Write(b.Bytes())
+			_, _ = io.WriteString(w, "# EOF\n")
+			return
+		}
+		w.Header().Set("Content-Type", prometheusContentType)
+		_, _ = w.Write(b.Bytes())
+	})
+}
+
+// workspaceMetrics caches the metrics of one workspace between scrapes.
+type workspaceMetrics struct {
+	root string
+	// tenant is nil when the server is not shared.
+	tenant *Tenant
+
+	mu          sync.Mutex
+	fingerprint string
+	families    *metricFamilies
+	// findings are kept rather than counted, as findings become overdue
+	// without any artifact changing.
+	findings []Finding
+}
+
+// collect adds the metrics of the workspace at asOf to families, recomputing
+// them when an artifact changed.
+func (m *workspaceMetrics) collect(ctx context.Context, families *metricFamilies, asOf time.Time) error {
+	files, err := findArtifactFiles(m.root)
+	if err != nil {
+		return err
+	}
+	fingerprint, err := fileFingerprint(files)
+	if err != nil {
+		return err
+	}
+	var labels []string
+	if m.tenant != nil {
+		ctx = withTenant(ctx, m.tenant)
+		labels = []string{"tenant", m.tenant.ID}
+	}
+	privacy := serverConfig(ctx).Privacy
+
+	m.mu.Lock()
+	if m.families == nil || fingerprint != m.fingerprint {
+		var posture Posture
+		m.families = newMetricFamilies(labels...)
+		writeComplianceMetrics(m.families, latestControlResults(ctx, m.root, files, &posture), posture, privacy)
+		m.fingerprint = fingerprint
+		m.findings = nil
+		if !privacy.enabled() {
+			m.findings = workspaceFindings(ctx, m.root, files)
+		}
+	}
+	cached, findings := m.families, m.findings
+	m.mu.Unlock()
+
+	families.add(cached)
+	if !privacy.enabled() {
+		overdue := newMetricFamilies(labels...)
+		writeOverdueMetrics(overdue, findings, asOf)
+		families.add(overdue)
+	}
+	return nil
+}
+
+// metricFamilies collects the samples of gauge metric families, so that the
+// samples of several workspaces are written under one header per family.
+type metricFamilies struct {
+	// labels are the label names and values every sample starts with.
+	labels  []string
+	names   []string
+	help    map[string]string
+	samples map[string][]string
+}
+
+// newMetricFamilies returns an empty set of families whose samples carry
+// labels, given as alternating names and values.
+func newMetricFamilies(labels ...string) *metricFamilies {
+	return &metricFamilies{labels: labels, help: map[string]string{}, samples: map[string][]string{}}
+}
+
+// family declares a metric family. Families are written in the order they
+// are first declared, even without samples.
+func (f *metricFamilies) family(name, help string) {
+	if _, ok := f.help[name]; !ok {
+		f.names = append(f.names, name)
+		f.help[name] = help
+	}
+}
+
+// sample adds a sample of the family name with labels, given as alternating
+// names and values.
+func (f *metricFamilies) sample(name string, value interface{}, labels ...string) {
+	labels = append(append([]string{}, f.labels...), labels...)
+	series := name
+	if len(labels) > 0 {
+		pairs := make([]string, 0, len(labels)/2)
+		for i := 0; i+1 < len(labels); i += 2 {
+			pairs = append(pairs, labels[i]+"="+metricLabel(labels[i+1]))
+		}
+		series += "{" + strings.Join(pairs, ",") + "}"
+	}
+	f.samples[name] = append(f.samples[name], fmt.Sprintf("%s %v", series, value))
+}
+
+// add appends the families and samples of other.
+func (f *metricFamilies) add(other *metricFamilies) {
+	for _, name := range other.names {
+		f.family(name, other.help[name])
+		f.samples[name] = append(f.samples[name], other.samples[name]...)
+	}
+}
+
+// write writes every family, without the OpenMetrics EOF marker.
+func (f *metricFamilies) write(w io.Writer) {
+	for _, name := range f.names {
+		fmt.Fprintf(w, "# HELP %s %s.\n# TYPE %s gauge\n", name, f.help[name], name)
+		for _, sample := range f.samples[name] {
+			fmt.Fprintln(w, sample)
+		}
+	}
+}
+
+// workspaceFindings collects the findings of the evaluation logs among files.
+// Files that cannot be read or parsed are skipped, as the posture counts them.
+func workspaceFindings(ctx context.Context, root string, files []string) []Finding {
+	sla := serverConfig(ctx).FindingSLA
+	var findings []Finding
+	for _, file := range files {
+		content, err := readArtifactFile(ctx, file)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			rel = file
+		}
+		found, err := collectFindings(ctx, []ArtifactInput{{Name: filepath.ToSlash(rel), Content: string(content)}}, sla)
+		if err != nil {
+			continue
+		}
+		findings = append(findings, found...)
+	}
+	return findings
+}
+
+// writeOverdueMetrics adds the number of findings past their due date at
+// asOf, by catalog and severity, to f.
+func writeOverdueMetrics(f *metricFamilies, findings []Finding, asOf time.Time) {
+	type key struct{ catalog, severity string }
+	counts := map[key]int{}
+	for _, finding := range overdueFindings(findings, asOf) {
+		counts[key{finding.Catalog, finding.Severity}]++
+	}
+	keys := make([]key, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].catalog != keys[j].catalog {
+			return keys[i].catalog < keys[j].catalog
+		}
+		return keys[i].severity < keys[j].severity
+	})
+
+	f.family("gemara_findings_overdue", "Findings past their remediation due date under the SLA policy")
+	for _, k := range keys {
+		f.sample("gemara_findings_overdue", counts[k], "catalog", k.catalog, "severity", k.severity)
+	}
+}
+
+// writeComplianceMetrics adds the metric families of the latest control
+// results to f. With privacy enabled, only the catalog aggregates it allows
+// are added.
+func writeComplianceMetrics(f *metricFamilies, latest map[string]map[string]ControlPosture, posture Posture, privacy PrivacyConfig) {
+	catalogs := make([]string, 0, len(latest))
+	for catalog := range latest {
+		catalogs = append(catalogs, catalog)
+	}
+	sort.Strings(catalogs)
+	controlIDs := func(catalog string) []string {
+		ids := make([]string, 0, len(latest[catalog]))
+		for id := range latest[catalog] {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	if privacy.enabled() {
+		writePrivateMetrics(f, latest, catalogs, posture, privacy)
+		return
+	}
+
+	f.family("gemara_control_result", "Latest evaluation result of a control; 1 for the recorded result")
+	for _, catalog := range catalogs {
+		for _, id := range controlIDs(catalog) {
+			f.sample("gemara_control_result", 1, "catalog", catalog, "control", id, "result", latest[catalog][id].Result)
+		}
+	}
+
+	f.family("gemara_control_last_evaluated_timestamp_seconds", "When the latest result of a control was recorded")
+	for _, catalog := range catalogs {
+		for _, id := range controlIDs(catalog) {
+			if evaluated := latest[catalog][id].evaluated; !evaluated.IsZero() {
+				f.sample("gemara_control_last_evaluated_timestamp_seconds", evaluated.Unix(), "catalog", catalog, "control", id)
+			}
+		}
+	}
+
+	f.family("gemara_catalog_compliance_ratio", "Share of the applicable, evaluated controls of a catalog that passed")
+	for _, catalog := range catalogs {
+		c := catalogPosture(catalog, latest[catalog])
+		if applicable := c.ControlsEvaluated - c.NotApplicable; applicable > 0 {
+			f.sample("gemara_catalog_compliance_ratio", float64(c.Passed)/float64(applicable), "catalog", catalog)
+		}
+	}
+
+	f.family("gemara_evaluation_logs", "Evaluation logs in the workspace")
+	f.sample("gemara_evaluation_logs", posture.EvaluationLogs)
+	f.family("gemara_unreadable_files", "Workspace files that could not be parsed")
+	f.sample("gemara_unreadable_files", len(posture.Unreadable))
+}
+
+// writePrivateMetrics adds the catalog aggregates of the latest control
+// results that privacy allows, with its noise, and how many catalogs were
+// suppressed, to f.
+func writePrivateMetrics(f *metricFamilies, latest map[string]map[string]ControlPosture, catalogs []string, posture Posture, privacy PrivacyConfig) {
+	suppressed := 0
+	f.family("gemara_catalog_compliance_ratio", "Share of the applicable, evaluated controls of a catalog that passed")
+	for _, catalog := range catalogs {
+		c, ok := privacy.catalog(catalogPosture(catalog, latest[catalog]))
+		if !ok {
+			suppressed++
+			continue
+		}
+		if applicable := c.ControlsEvaluated - c.NotApplicable; applicable > 0 {
+			f.sample("gemara_catalog_compliance_ratio", float64(c.Passed)/float64(applicable), "catalog", catalog)
+		}
+	}
+
+	f.family("gemara_suppressed_catalogs", "Catalogs left out because fewer evaluation logs than the minimum cohort evaluate them")
+	f.sample("gemara_suppressed_catalogs", suppressed)
+	f.family("gemara_evaluation_logs", "Evaluation logs in the workspace")
+	f.sample("gemara_evaluation_logs", privacy.noisyCount(posture.EvaluationLogs))
+}
+
+// metricLabel quotes a label value, escaping it as the exposition formats
+// require.
+func metricLabel(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scrapeMetrics fetches the metrics of config with the given Accept header.
+func scrapeMetrics(t *testing.T, config *Config, accept string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, MetricsPath, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	MetricsHandler(config, nil).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMetricsHandler(t *testing.T) {
+	dir := t.TempDir()
+	config := NewConfig()
+	config.WorkspaceRoot = dir
+
+	writeTestFile(t, dir, "logs/2025-01.yaml", postureOldLog)
+	writeTestFile(t, dir, "logs/2025-02.yaml", postureNewLog)
+	writeTestFile(t, dir, "broken.yaml", "evaluations: [")
+
+	rec := scrapeMetrics(t, config, "application/openmetrics-text;version=1.0.0,text/plain;q=0.5")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, openMetricsContentType, rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE gemara_control_result gauge\n",
+		// The newer passing evaluation of CCC.C01 wins
+		`gemara_control_result{catalog="FINOS-CCC",control="CCC.C01",result="Passed"} 1` + "\n",
+		`gemara_control_result{catalog="FINOS-CCC",control="CCC.C06",result="Failed"} 1` + "\n",
+		`gemara_control_result{catalog="FINOS-CCC",control="CCC.C08",result="Not Applicable"} 1` + "\n",
+		`gemara_control_last_evaluated_timestamp_seconds{catalog="ORG-POL",control="ORG.C01"} 1736899200` + "\n",
+		`gemara_catalog_compliance_ratio{catalog="FINOS-CCC"} 0.5` + "\n",
+		`gemara_catalog_compliance_ratio{catalog="ORG-POL"} 1` + "\n",
+		"gemara_evaluation_logs 2\n",
+		"gemara_unreadable_files 1\n",
+		// Both failures of CCC.C01 and CCC.C06 are past the 30 day SLA of high severity
+		"# TYPE gemara_findings_overdue gauge\n",
+		`gemara_findings_overdue{catalog="FINOS-CCC",severity="high"} 2` + "\n",
+	} {
+		assert.Contains(t, body, want)
+	}
+	assert.Equal(t, "# EOF\n", body[len(body)-len("# EOF\n"):])
+
+	rec = scrapeMetrics(t, config, "")
+	assert.Equal(t, prometheusContentType, rec.Header().Get("Content-Type"))
+	assert.NotContains(t, rec.Body.String(), "# EOF", "the Prometheus format has no EOF marker")
+
+	// Changing a log recomputes the metrics
+	require.NoError(t, os.Remove(filepath.Join(dir, "logs", "2025-02.yaml")))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "logs", "2025-01.yaml"), later, later))
+	body = scrapeMetrics(t, config, "").Body.String()
+	assert.Contains(t, body, `gemara_control_result{catalog="FINOS-CCC",control="CCC.C01",result="Failed"} 1`)
+	assert.Contains(t, body, `gemara_catalog_compliance_ratio{catalog="FINOS-CCC"} 0`+"\n")
+	assert.Contains(t, body, "gemara_evaluation_logs 1\n")
+	assert.Contains(t, body, `gemara_findings_overdue{catalog="FINOS-CCC",severity="high"} 1`+"\n")
+}
+
+func TestWriteOverdueMetrics(t *testing.T) {
+	findings := []Finding{
+		{Catalog: "B", Severity: severityLow, Due: "2025-01-10T00:00:00Z"},
+		{Catalog: "A", Severity: severityHigh, Due: "2025-01-10T00:00:00Z"},
+		{Catalog: "A", Severity: severityCritical, Due: "2025-01-01T00:00:00Z"},
+		{Catalog: "A", Severity: severityCritical, Due: "2025-01-02T00:00:00Z"},
+		{Catalog: "A", Severity: severityMedium},
+	}
+	families := newMetricFamilies()
+	writeOverdueMetrics(families, findings, time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC))
+	var b strings.Builder
+	families.write(&b)
+	assert.Equal(t, "# HELP gemara_findings_overdue Findings past their remediation due date under the SLA policy.\n"+
+		"# TYPE gemara_findings_overdue gauge\n"+
+		`gemara_findings_overdue{catalog="A",severity="critical"} 2`+"\n", b.String(),
+		"findings not yet due or without a due date should not be counted")
+}
+
+func TestMetricsHandlerTenants(t *testing.T) {
+	alpha, beta := t.TempDir(), t.TempDir()
+	writeTestFile(t, alpha, "logs/2025-01.yaml", postureOldLog)
+	writeTestFile(t, beta, "logs/2025-01.yaml", postureOldLog)
+	writeTestFile(t, beta, "logs/2025-02.yaml", postureNewLog)
+	tenants := []*Tenant{{ID: "alpha", WorkspaceRoot: alpha}, {ID: "beta", WorkspaceRoot: beta}}
+	config := NewConfig()
+	config.WorkspaceRoot = t.TempDir()
+
+	rec := httptest.NewRecorder()
+	MetricsHandler(config, tenants).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, MetricsPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	for _, want := range []string{
+		`gemara_control_result{tenant="alpha",catalog="FINOS-CCC",control="CCC.C01",result="Failed"} 1` + "\n",
+		`gemara_control_result{tenant="beta",catalog="FINOS-CCC",control="CCC.C01",result="Passed"} 1` + "\n",
+		`gemara_evaluation_logs{tenant="alpha"} 1` + "\n",
+		`gemara_evaluation_logs{tenant="beta"} 2` + "\n",
+		`gemara_findings_overdue{tenant="beta",catalog="FINOS-CCC",severity="high"} 2` + "\n",
+	} {
+		assert.Contains(t, body, want)
+	}
+	assert.Equal(t, 1, strings.Count(body, "# TYPE gemara_evaluation_logs gauge\n"), "each family should have one header")
+	assert.NotContains(t, body, `gemara_evaluation_logs 0`, "the global workspace root should not be reported")
+}
+
+func TestMetricsHandlerWithoutWorkspaceRoot(t *testing.T) {
+	assert.Equal(t, http.StatusServiceUnavailable, scrapeMetrics(t, NewConfig(), "").Code)
+}
+
+func TestMetricLabel(t *testing.T) {
+	assert.Equal(t, `"plain"`, metricLabel("plain"))
+	assert.Equal(t, `"a \"quoted\" \\ value\nwith lines"`, metricLabel("a \"quoted\" \\ value\nwith lines"))
+}
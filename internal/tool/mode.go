@@ -2,7 +2,12 @@
 
 package tool
 
-import "github.com/modelcontextprotocol/go-sdk/mcp"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
 
 // Mode represents the operational mode of the MCP server.
 type Mode interface {
@@ -10,27 +15,297 @@ type Mode interface {
 	Name() string
 	// Description returns a human-readable description of the mode.
 	Description() string
+	// Instructions returns the server instructions sent to clients on
+	// initialization, describing the tools and resources of the mode.
+	Instructions() string
+	// Tools returns the metadata of every tool the mode registers.
+	Tools() []*mcp.Tool
 	// Register adds mode-related tools to the mcp server
 	Register(*mcp.Server)
 }
 
-// AdvisoryMode defines tools and resources for operating in a read-only query mode
-type AdvisoryMode struct{}
+// Modes returns every operational mode supported by the server.
+func Modes() []Mode {
+	return []Mode{
+		AdvisoryMode{},
+	}
+}
+
+// LookupMode returns the mode with the given name.
+func LookupMode(name string) (Mode, error) {
+	var names []string
+	for _, m := range Modes() {
+		if m.Name() == name {
+			return m, nil
+		}
+		names = append(names, m.Name())
+	}
+	return nil, fmt.Errorf("unknown mode %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// toolEntry pairs tool metadata with the function that registers its handler.
+type toolEntry struct {
+	tool *mcp.Tool
+	add  func(*mcp.Server)
+}
+
+// newToolEntry binds a typed tool handler to its metadata. Results are rendered
+// for the configured Audience, calls are limited to ToolTimeout, the calling
+// session is available to the handler through its context, and the schemas
+// the handler loads are returned to the cache when it returns.
+func newToolEntry[In, Out any](t *mcp.Tool, h mcp.ToolHandlerFor[In, Out]) toolEntry {
+	return toolEntry{
+		tool: t,
+		add: func(server *mcp.Server) {
+			mcp.AddTool(server, t, renderedHandler(timeoutHandler(t.Name, sessionHandler(schemaHandler(h)))))
+		},
+	}
+}
+
+// toolMetadata extracts the tool metadata from a list of entries.
+func toolMetadata(entries []toolEntry) []*mcp.Tool {
+	tools := make([]*mcp.Tool, 0, len(entries))
+	for _, e := range entries {
+		tools = append(tools, e.tool)
+	}
+	return tools
+}
+
+// AdvisoryMode defines tools and resources for querying and checking Gemara
+// artifacts. Its tools never modify the workspace, but some write to external
+// systems (issue trackers, OCI registries, and Sigstore); those honor dry_run,
+// --dry-run, and --require-approval, and can be denied with the tool filter.
+type AdvisoryMode struct {
+	// Lexicon serves the lexicon to the mode's tools and resources;
+	// DefaultLexicon when nil.
+	Lexicon *LexiconService
+	// Filter selects the tools the mode registers and describes; every tool
+	// when empty.
+	Filter ToolFilter
+	// Config configures the mode's tools and resources; the default
+	// configuration when nil.
+	Config *Config
+	// Tenant is the tenant every request to the mode's server is made for, in
+	// a shared deployment; nil serves the workspace root of Config.
+	Tenant *Tenant
+}
+
+// config returns the configuration the mode serves.
+func (a AdvisoryMode) config() *Config {
+	if a.Config != nil {
+		return a.Config
+	}
+	return defaultConfig
+}
+
+// workspaceRoot returns the workspace root the mode serves.
+func (a AdvisoryMode) workspaceRoot() string {
+	if a.Tenant != nil {
+		return a.Tenant.WorkspaceRoot
+	}
+	return a.config().WorkspaceRoot
+}
 
 func (a AdvisoryMode) Name() string {
 	return "advisory"
 }
 
 func (a AdvisoryMode) Description() string {
-	return "Advisory mode: Provides information about Gemara artifacts in the workspace (read-only)"
+	return "Advisory mode: Provides information about Gemara artifacts in the workspace without modifying it; " +
+		"some tools write to issue trackers, OCI registries, or Sigstore"
+}
+
+// Instructions describes the registered tools, the URI schemes they accept,
+// and how the Gemara layers fit together, so clients can use the tools
+// without external documentation.
+func (a AdvisoryMode) Instructions() string {
+	var b strings.Builder
+	b.WriteString(a.Description() + ". Validate every artifact you draft or edit with " +
+		"validate_gemara_artifact, and read the schema or an example resource of a definition before drafting one.\n\n")
+	writeLayerInstructions(&b)
+	b.WriteString("\n")
+	writeToolInstructions(&b, a.tools())
+	b.WriteString("\n")
+	writeURIInstructions(&b, a.config(), a.workspaceRoot())
+	b.WriteString("\n")
+	writePromptInstructions(&b)
+	return b.String()
+}
+
+func (a AdvisoryMode) Tools() []*mcp.Tool {
+	return toolMetadata(a.tools())
 }
 
 func (a AdvisoryMode) Register(server *mcp.Server) {
-	// Lexicon tool - provides information about Gemara terms
+	// Lexicon service - every request to the server reads the mode's lexicon
+	lexicon := a.Lexicon
+	if lexicon == nil {
+		lexicon = DefaultLexicon
+	}
+	server.AddReceivingMiddleware(lexiconMiddleware(lexicon))
+	// Configuration - every request to the server reads the mode's configuration
+	server.AddReceivingMiddleware(configMiddleware(a.config()))
+	// Tenant - every request to the server reads the tenant's workspace and caches
+	if a.Tenant != nil {
+		a.Tenant.server = server
+		server.AddReceivingMiddleware(tenantMiddleware(a.Tenant))
+	}
+
+	// Lexicon resources - provide information about Gemara terms
 	server.AddResource(MetadataLexiconResource, HandleLexiconResource)
 	server.AddResource(MetadataLexiconResourceAlias, HandleLexiconResource)
-	mcp.AddTool(server, MetadataGetLexicon, GetLexicon)
 
-	// Validation tool - validates artifacts without modifying them
-	mcp.AddTool(server, MetadataValidateGemaraArtifact, ValidateGemaraArtifact)
+	// Schema resources - expose definition schemas as context without a tool call
+	server.AddResourceTemplate(MetadataSchemaResourceTemplate, HandleSchemaResource)
+
+	// Example resources - provide valid artifacts for few-shot prompting
+	server.AddResourceTemplate(MetadataExampleResourceTemplate, HandleExampleResource)
+	server.AddResourceTemplate(MetadataExampleSampleResourceTemplate, HandleExampleResource)
+	for _, r := range exampleResources() {
+		server.AddResource(r, HandleExampleResource)
+	}
+
+	// Catalog resources - community catalogs packed into an offline bundle
+	for _, r := range bundleCatalogResources() {
+		server.AddResource(r, HandleBundleCatalogResource)
+	}
+
+	// Federated catalog resources - catalogs composed from several sources on demand
+	for _, r := range federatedResources(a.config().Federation) {
+		server.AddResource(r, HandleFederatedResource)
+	}
+
+	// Wizard prompts - guide non-expert users through drafting an artifact
+	server.AddPrompt(MetadataCatalogWizardPrompt, HandleCatalogWizardPrompt)
+	server.AddPrompt(MetadataPolicyWizardPrompt, HandlePolicyWizardPrompt)
+
+	// Posture resource - compliance summary of the evaluation logs in the workspace
+	if a.workspaceRoot() != "" {
+		server.AddResource(MetadataPostureResource, HandlePostureResource)
+	}
+
+	for _, e := range a.tools() {
+		e.add(server)
+	}
+}
+
+func (a AdvisoryMode) tools() []toolEntry {
+	tools := []toolEntry{
+		// Lexicon tool - provides information about Gemara terms
+		newToolEntry(MetadataGetLexicon, GetLexicon),
+		// Term graph tool - shows how lexicon terms reference each other
+		newToolEntry(MetadataGetTermRelationships, GetTermRelationships),
+		// Validation tool - validates artifacts without modifying them
+		newToolEntry(MetadataValidateGemaraArtifact, ValidateGemaraArtifact),
+		// Signing tools - produce and check detached Sigstore bundles
+		newToolEntry(MetadataSignGemaraArtifact, SignGemaraArtifact),
+		newToolEntry(MetadataVerifyGemaraArtifactSignature, VerifyGemaraArtifactSignature),
+		// Attestation tool - wraps evaluation logs as in-toto statements, optionally signed
+		newToolEntry(MetadataWrapAsAttestation, WrapAsAttestation),
+		// OCI tools - distribute artifacts through container registries
+		newToolEntry(MetadataPushArtifactOCI, PushArtifactOCI),
+		newToolEntry(MetadataPullArtifactOCI, PullArtifactOCI),
+		// Detection tool - identifies the definition an artifact conforms to
+		newToolEntry(MetadataDetectGemaraArtifactType, DetectGemaraArtifactType),
+		// Fix tool - returns a repaired copy of an artifact without writing it
+		newToolEntry(MetadataFixGemaraArtifact, FixGemaraArtifact),
+		// Lint tool - checks style and best-practice rules beyond schema validity
+		newToolEntry(MetadataLintGemaraArtifact, LintGemaraArtifact),
+		// Conformance tool - checks a directory of artifacts from other implementations
+		newToolEntry(MetadataRunConformanceSuite, RunConformanceSuite),
+		// Schema export tool - converts CUE definitions to JSON Schema
+		newToolEntry(MetadataGetDefinitionSchema, GetDefinitionSchema),
+		// Compatibility tool - compares an artifact's declared schema version with published versions
+		newToolEntry(MetadataCheckSchemaCompatibility, CheckSchemaCompatibility),
+		// Template tools - provide vetted starting points for new artifacts
+		newToolEntry(MetadataListTemplates, ListTemplates),
+		newToolEntry(MetadataFetchTemplate, FetchTemplate),
+		// Repository tool - lists and retrieves artifacts published on GitHub
+		newToolEntry(MetadataFetchArtifactsFromRepo, FetchArtifactsFromRepo),
+		// Findings tool - reports unresolved assessments past their SLA due date
+		newToolEntry(MetadataListOverdueFindings, ListOverdueFindings),
+		// Impact analysis tool - reports dependents of a proposed control change
+		newToolEntry(MetadataImpactOfChange, ImpactOfChange),
+		// Traceability tool - links guidance to controls, policy statements, and evaluation results
+		newToolEntry(MetadataGenerateTraceabilityMatrix, GenerateTraceabilityMatrix),
+		// Dependency tool - resolves the artifacts an artifact references, recursively
+		newToolEntry(MetadataResolveArtifactRefs, ResolveArtifactRefs),
+		// Reference integrity tool - reports workspace references that do not resolve
+		newToolEntry(MetadataCheckReferenceIntegrity, CheckReferenceIntegrity),
+		// Markdown tool - renders artifacts for PR descriptions, wikis, and audit reports
+		newToolEntry(MetadataRenderArtifactMarkdown, RenderArtifactMarkdown),
+		// Report tool - renders evaluation logs as HTML or PDF reports for auditors
+		newToolEntry(MetadataGenerateComplianceReport, GenerateComplianceReport),
+		// CSV export tool - flattens catalogs and evaluation logs for spreadsheet review
+		newToolEntry(MetadataExportArtifactCSV, ExportArtifactCSV),
+		// Threat coverage tool - finds threats without controls and controls without threats
+		newToolEntry(MetadataAnalyzeThreatCoverage, AnalyzeThreatCoverage),
+		// History tool - diffs an artifact across its git revisions
+		newToolEntry(MetadataGetArtifactHistory, GetArtifactHistory),
+		// Crosswalk tool - proposes control mappings between two catalogs
+		newToolEntry(MetadataCrosswalkCatalogs, CrosswalkCatalogs),
+		// Merge tool - consolidates per-team catalogs into one baseline
+		newToolEntry(MetadataMergeCatalogs, MergeCatalogs),
+		// Tailoring tool - derives a profile of a baseline catalog with a record of the decisions
+		newToolEntry(MetadataTailorCatalog, TailorCatalog),
+		// Applicability tool - keeps the controls of a catalog that apply in a described context
+		newToolEntry(MetadataFilterCatalogByApplicability, FilterCatalogByApplicability),
+		// Parameter tool - substitutes policy or profile parameter values into a catalog
+		newToolEntry(MetadataResolveControlParameters, ResolveControlParameters),
+		// ID tools - allocate control IDs by scheme and renumber a catalog with its references
+		newToolEntry(MetadataAllocateControlIDs, AllocateControlIDs),
+		newToolEntry(MetadataRenumberCatalog, RenumberCatalog),
+		// Baseline tool - checks a project's catalog or policy against org minimum standards
+		newToolEntry(MetadataCompareToBaseline, CompareToBaseline),
+		// Anonymization tool - strips identifying content so artifacts can be shared
+		newToolEntry(MetadataAnonymizeArtifact, AnonymizeArtifact),
+		// Guidance tool - recommends the next tool calls for a workspace
+		newToolEntry(MetadataSuggestNextAction, SuggestNextAction),
+		// Skeleton tool - drafts a catalog structure from requirement statements
+		newToolEntry(MetadataGenerateControlCatalogSkeleton, GenerateControlCatalogSkeleton),
+		// Import tool - drafts a catalog from a spreadsheet of controls
+		newToolEntry(MetadataImportControlsFromCSV, ImportControlsFromCSV),
+		// Guidance import tool - drafts a guidance document from a Markdown or HTML standard
+		newToolEntry(MetadataImportGuidanceDocument, ImportGuidanceDocument),
+		// Evaluation plan tool - drafts a Layer 4 plan from a catalog's assessment requirements
+		newToolEntry(MetadataGenerateEvaluationPlan, GenerateEvaluationPlan),
+		// Policy candidate tool - proposes Layer 3 policy statements from a guidance document
+		newToolEntry(MetadataExtractPolicyCandidates, ExtractPolicyCandidates),
+		// Scan ingestion tool - converts SARIF, Trivy, and OpenSCAP output into an evaluation log
+		newToolEntry(MetadataIngestScanResults, IngestScanResults),
+		// Evidence tool - records evidence references in an evaluation log's assessments
+		newToolEntry(MetadataAttachEvidence, AttachEvidence),
+		// SBOM tool - links evaluation subjects to SBOM components and flags removed ones
+		newToolEntry(MetadataLinkEvaluationSubjects, LinkEvaluationSubjects),
+		// Rego tool - drafts policy-as-code stubs from assessment requirements
+		newToolEntry(MetadataGenerateRegoStubs, GenerateRegoStubs),
+		// Kubernetes policy tool - drafts Kyverno or Gatekeeper policies from Kubernetes requirements
+		newToolEntry(MetadataExportK8sPolicies, ExportK8sPolicies),
+		// Synthetic catalog tool - generates large catalogs for load testing
+		newToolEntry(MetadataGenerateSyntheticCatalog, GenerateSyntheticCatalogTool),
+		// Staging tools - keep drafts in memory for the session between edits
+		newToolEntry(MetadataStageArtifact, StageArtifact),
+		newToolEntry(MetadataGetStagedArtifact, GetStagedArtifact),
+		newToolEntry(MetadataListStagedArtifacts, ListStagedArtifacts),
+	}
+	if a.config().IssueTracker.Tracker != "" {
+		// Issue tool - files failing controls in the configured tracker, so it is only offered when one is set
+		tools = append(tools, newToolEntry(MetadataCreateFindingsIssues, CreateFindingsIssues))
+	}
+	if a.workspaceRoot() != "" {
+		// Full-text search tool - finds workspace artifacts by words in their fields
+		tools = append(tools, newToolEntry(MetadataSearchArtifacts, SearchArtifacts))
+	}
+	if a.config().SemanticSearch.Provider != "" {
+		// Search tool - finds controls by meaning, offered when an embedding provider is set
+		tools = append(tools, newToolEntry(MetadataSearchControls, SearchControls))
+	}
+
+	allowed := tools[:0]
+	for _, e := range tools {
+		if a.Filter.Allows(e.tool.Name) {
+			allowed = append(allowed, e)
+		}
+	}
+	return allowed
 }
@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name: "advisory mode",
+			mode: "advisory",
+		},
+		{
+			name:        "unknown mode",
+			mode:        "unknown",
+			wantErr:     true,
+			errContains: "available: advisory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode, err := LookupMode(tt.mode)
+			if tt.wantErr {
+				require.Error(t, err, "should return error")
+				assert.Contains(t, err.Error(), tt.errContains, "error should contain expected message")
+				return
+			}
+			require.NoError(t, err, "should not return error")
+			assert.Equal(t, tt.mode, mode.Name(), "mode name should match")
+		})
+	}
+}
+
+func TestModeRegister(t *testing.T) {
+	for _, mode := range Modes() {
+		t.Run(mode.Name(), func(t *testing.T) {
+			server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+			require.NotPanics(t, func() { mode.Register(server) }, "tool schemas should be accepted by the SDK")
+
+			seen := map[string]bool{}
+			for _, tool := range mode.Tools() {
+				assert.False(t, seen[tool.Name], "tool %s should be listed once", tool.Name)
+				seen[tool.Name] = true
+				assert.NotEmpty(t, tool.Description, "tool %s should have a description", tool.Name)
+			}
+		})
+	}
+}
+
+func TestAdvisoryModeInstructions(t *testing.T) {
+	mode := AdvisoryMode{Config: NewConfig()}
+	instructions := mode.Instructions()
+	for _, tool := range mode.Tools() {
+		assert.Contains(t, instructions, "- "+tool.Name+": ", "should describe tool %s", tool.Name)
+	}
+	assert.Contains(t, instructions, "Layer 2, Controls: #ControlCatalog", "should map layers to definitions")
+	assert.Contains(t, instructions, "https://", "should list artifact URI schemes")
+	assert.Contains(t, instructions, schemaResourceURITemplate, "should list resources")
+	assert.Contains(t, instructions, "- "+MetadataCatalogWizardPrompt.Name+": ", "should list prompts")
+	assert.NotContains(t, instructions, "file://", "should not offer file URIs without a workspace")
+	assert.NotContains(t, instructions, PostureResourceURI, "should not list the posture resource without a workspace")
+
+	mode.Config.WorkspaceRoot = t.TempDir()
+	instructions = mode.Instructions()
+	assert.Contains(t, instructions, "file:// paths within the workspace", "should offer file URIs")
+	assert.Contains(t, instructions, PostureResourceURI, "should list the posture resource")
+}
+
+func TestFirstSentence(t *testing.T) {
+	assert.Equal(t, "Do this.", firstSentence("Do this. Then that."))
+	assert.Equal(t, "Use it (e.g., here)", firstSentence("Use it (e.g., here)"))
+}
@@ -0,0 +1,529 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	pathpkg "path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+)
+
+// OCI media types of Gemara artifacts. The artifact type names the kind, as in
+// application/vnd.gemara.control-catalog.v1, and the single layer holds the YAML.
+const (
+	ociArtifactTypePrefix = "application/vnd.gemara."
+	ociArtifactTypeSuffix = ".v1"
+	ociLayerMediaType     = "application/vnd.gemara.artifact.v1+yaml"
+)
+
+// ORASConfig configures access to the OCI registries artifacts are pushed to
+// and pulled from.
+type ORASConfig struct {
+	// RegistryConfig is the registry credentials file, in the Docker
+	// configuration format; the Docker credentials are used when it is empty.
+	RegistryConfig string
+}
+
+// ociTarget opens a registry repository, authenticating with the configured
+// credentials. Tests replace it with in-memory repositories.
+var ociTarget = func(c ORASConfig, repository string) (oras.Target, error) {
+	repo, err := remote.NewRepository(repository)
+	if err != nil {
+		return nil, err
+	}
+	var store credentials.Store
+	if c.RegistryConfig != "" {
+		store, err = credentials.NewStore(c.RegistryConfig, credentials.StoreOptions{})
+	} else {
+		store, err = credentials.NewStoreFromDocker(credentials.StoreOptions{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load registry credentials: %w", err)
+	}
+	repo.Client = &auth.Client{
+		Client:     newHTTPClient(),
+		Cache:      auth.NewCache(),
+		Credential: credentials.Credential(store),
+	}
+	return repo, nil
+}
+
+// open opens the repository of a reference and returns it with the tag or
+// digest the reference names.
+func (c ORASConfig) open(reference string) (oras.Target, string, error) {
+	ref, err := registry.ParseReference(reference)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid OCI reference %q: %w", reference, err)
+	}
+	if ref.Reference == "" {
+		return nil, "", fmt.Errorf("OCI reference %q has no tag or digest", reference)
+	}
+	target, err := ociTarget(c, ref.Registry+"/"+ref.Repository)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open %s: %w", reference, err)
+	}
+	return target, ref.Reference, nil
+}
+
+// resolve returns the manifest digest a reference points to.
+func (c ORASConfig) resolve(ctx context.Context, reference string) (string, error) {
+	target, version, err := c.open(reference)
+	if err != nil {
+		return "", err
+	}
+	desc, err := target.Resolve(ctx, version)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", reference, err)
+	}
+	return desc.Digest.String(), nil
+}
+
+// push pushes data as the single layer of an artifact of artifactType, tags
+// its manifest with the reference's tag, and returns the manifest digest.
+func (c ORASConfig) push(ctx context.Context, reference, artifactType, filename string, data []byte, annotations map[string]string) (string, error) {
+	if err := checkWritable(ctx, "OCI registry"); err != nil {
+		return "", err
+	}
+	target, tag, err := c.open(reference)
+	if err != nil {
+		return "", err
+	}
+	layer := content.NewDescriptorFromBytes(ociLayerMediaType, data)
+	layer.Annotations = map[string]string{ocispec.AnnotationTitle: filename}
+	if err := target.Push(ctx, layer, bytes.NewReader(data)); err != nil && !errors.Is(err, errdef.ErrAlreadyExists) {
+		return "", err
+	}
+	manifest, err := oras.PackManifest(ctx, target, oras.PackManifestVersion1_1, artifactType, oras.PackManifestOptions{
+		Layers:              []ocispec.Descriptor{layer},
+		ManifestAnnotations: annotations,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := target.Tag(ctx, manifest, tag); err != nil {
+		return "", err
+	}
+	return manifest.Digest.String(), nil
+}
+
+// maxOCIManifestSize bounds the manifests read when pulling.
+const maxOCIManifestSize = 4 << 20
+
+// pull returns the layer of an artifact titled path, or without a path, its
+// only YAML layer. Layers larger than the artifact size limit are not fetched.
+func (c ORASConfig) pull(ctx context.Context, reference, path string) ([]byte, error) {
+	target, version, err := c.open(reference)
+	if err != nil {
+		return nil, err
+	}
+	_, data, err := oras.FetchBytes(ctx, target, version, oras.FetchBytesOptions{MaxBytes: maxOCIManifestSize})
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull %s: %w", reference, err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to pull %s: invalid manifest: %w", reference, err)
+	}
+
+	var layers []ocispec.Descriptor
+	for _, layer := range manifest.Layers {
+		title := layer.Annotations[ocispec.AnnotationTitle]
+		if path != "" {
+			if title == pathpkg.Clean(path) {
+				layers = append(layers, layer)
+			}
+			continue
+		}
+		switch strings.ToLower(pathpkg.Ext(title)) {
+		case ".yaml", ".yml":
+			layers = append(layers, layer)
+		}
+	}
+	switch {
+	case path != "" && len(layers) == 0:
+		return nil, fmt.Errorf("%s has no file %q", reference, path)
+	case path == "" && len(layers) != 1:
+		return nil, fmt.Errorf("%s contains %d YAML files; select one with oci://<reference>//<path>", reference, len(layers))
+	}
+	layer := layers[0]
+	if limit := serverConfig(ctx).MaxArtifactSize; layer.Size > limit {
+		return nil, fmt.Errorf("artifact is %d bytes, exceeding the %d byte limit", layer.Size, limit)
+	}
+	data, err = content.FetchAll(ctx, target, layer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull %s: %w", reference, err)
+	}
+	return data, nil
+}
+
+// ociReferencePattern matches a registry reference: an optional registry host
+// and port, a lowercase repository path, and an optional tag and digest. It
+// rejects references that cosign could read as a flag.
+var ociReferencePattern = regexp.MustCompile(`^(?:[a-zA-Z0-9](?:[a-zA-Z0-9.-]*[a-zA-Z0-9])?(?::[0-9]+)?/)?` +
+	`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` +
+	`(?::[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127})?(?:@[a-z0-9]+(?:[+._-][a-z0-9]+)*:[a-zA-Z0-9=_-]+)?$`)
+
+// validateOCIReference reports whether reference is a registry reference.
+func validateOCIReference(reference string) error {
+	if !ociReferencePattern.MatchString(reference) {
+		return fmt.Errorf("invalid OCI reference %q", reference)
+	}
+	return nil
+}
+
+var kindWordPattern = regexp.MustCompile(`[A-Z][a-z0-9]*`)
+
+// ociArtifactType is the OCI artifact type of an artifact kind.
+func ociArtifactType(kind string) string {
+	words := kindWordPattern.FindAllString(kind, -1)
+	return ociArtifactTypePrefix + strings.ToLower(strings.Join(words, "-")) + ociArtifactTypeSuffix
+}
+
+// ociRepository strips the tag or digest from a reference.
+func ociRepository(reference string) string {
+	if i := strings.Index(reference, "@"); i >= 0 {
+		return reference[:i]
+	}
+	slash := strings.LastIndex(reference, "/")
+	if i := strings.LastIndex(reference, ":"); i > slash {
+		return reference[:i]
+	}
+	return reference
+}
+
+// ociVersioned reports whether a reference names a tag or digest.
+func ociVersioned(reference string) bool {
+	return ociRepository(reference) != reference
+}
+
+// MetadataPushArtifactOCI describes the PushArtifactOCI tool.
+var MetadataPushArtifactOCI = &mcp.Tool{
+	Name: "push_artifact_oci",
+	Description: "Push a valid Gemara artifact to an OCI registry as an OCI artifact whose type names its kind " +
+		"(e.g., application/vnd.gemara.control-catalog.v1) and whose layer is the YAML (" + ociLayerMediaType + "). " +
+		"Without a tag the artifact's metadata version is used. Optionally signs the pushed manifest with cosign, " +
+		"so catalogs are distributed versioned and signed alongside container images.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"reference"},
+		"properties": func() map[string]interface{} {
+			properties := artifactSourceProperties("push")
+			properties["reference"] = map[string]interface{}{
+				"type":        "string",
+				"description": "Registry reference to push to (e.g., 'ghcr.io/org/catalogs/ccc:v1.2.0')",
+			}
+			properties["filename"] = map[string]interface{}{
+				"type":        "string",
+				"description": "Name of the artifact file in the OCI artifact (default: '<metadata id>.yaml')",
+			}
+			properties["annotations"] = map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": map[string]interface{}{"type": "string"},
+				"description":          "Additional manifest annotations",
+			}
+			properties["sign"] = map[string]interface{}{
+				"type":        "boolean",
+				"description": "Sign the pushed manifest with cosign, keyless or with the server's configured key",
+			}
+			properties["dry_run"] = dryRunProperty
+			return properties
+		}(),
+	},
+}
+
+// InputPushArtifactOCI is the input for the PushArtifactOCI tool.
+type InputPushArtifactOCI struct {
+	ArtifactContent string            `json:"artifact_content,omitempty"`
+	ArtifactURI     string            `json:"artifact_uri,omitempty"`
+	Reference       string            `json:"reference"`
+	Filename        string            `json:"filename,omitempty"`
+	Annotations     map[string]string `json:"annotations,omitempty"`
+	Sign            bool              `json:"sign,omitempty"`
+	DryRun          bool              `json:"dry_run,omitempty"`
+}
+
+// OutputPushArtifactOCI is the output for the PushArtifactOCI tool.
+type OutputPushArtifactOCI struct {
+	Reference    string `json:"reference"`
+	Digest       string `json:"digest"`
+	ArtifactType string `json:"artifact_type"`
+	Signed       bool   `json:"signed"`
+	// Mode is how the manifest was signed, when it was.
+	Mode   string `json:"mode,omitempty"`
+	DryRun bool   `json:"dry_run,omitempty"`
+	// Changes lists the changes made, or in a dry run, those that would be.
+	Changes []PlannedChange `json:"changes,omitempty"`
+	Message string          `json:"message"`
+}
+
+// PushArtifactOCI pushes an artifact to an OCI registry.
+func PushArtifactOCI(ctx context.Context, req *mcp.CallToolRequest, input InputPushArtifactOCI) (*mcp.CallToolResult, OutputPushArtifactOCI, error) {
+	reference := strings.TrimPrefix(strings.TrimSpace(input.Reference), ociSourceScheme)
+	if reference == "" {
+		return nil, OutputPushArtifactOCI{}, fmt.Errorf("reference is required")
+	}
+	if err := validateOCIReference(reference); err != nil {
+		return nil, OutputPushArtifactOCI{}, err
+	}
+	content, err := artifactSource(ctx, input.ArtifactContent, input.ArtifactURI)
+	if err != nil {
+		return nil, OutputPushArtifactOCI{}, err
+	}
+	doc, err := parseArtifact(ctx, string(content))
+	if err != nil {
+		return nil, OutputPushArtifactOCI{}, err
+	}
+	kind := artifactKind(doc)
+	if kind == "" {
+		return nil, OutputPushArtifactOCI{}, fmt.Errorf("could not detect the artifact type; only Gemara artifacts can be pushed")
+	}
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputPushArtifactOCI{}, err
+	}
+	validation, err := validateAgainstSchema(schema, normalizeDefinition(kind), string(content))
+	if err != nil {
+		return nil, OutputPushArtifactOCI{}, err
+	}
+	if !validation.Valid {
+		return nil, OutputPushArtifactOCI{}, fmt.Errorf("refusing to push an invalid %s: %s", kind, strings.Join(validation.Errors, "; "))
+	}
+
+	metadata, _ := doc["metadata"].(map[string]interface{})
+	version := stringField(metadata, "version")
+	if !ociVersioned(reference) {
+		if version == "" {
+			return nil, OutputPushArtifactOCI{}, fmt.Errorf("reference %s has no tag and the artifact has no metadata version", reference)
+		}
+		reference += ":" + version
+	}
+	filename := input.Filename
+	if filename == "" {
+		filename = stringField(metadata, "id") + ".yaml"
+		if filename == ".yaml" {
+			filename = "artifact.yaml"
+		}
+	}
+	if filepath.Base(filename) != filename {
+		return nil, OutputPushArtifactOCI{}, fmt.Errorf("filename %q must not contain a path", filename)
+	}
+
+	annotations := map[string]string{}
+	if version != "" {
+		annotations["org.opencontainers.image.version"] = version
+	}
+	if description := stringField(metadata, "description"); description != "" {
+		annotations["org.opencontainers.image.description"] = description
+	}
+	for key, value := range input.Annotations {
+		annotations[key] = value
+	}
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	output := OutputPushArtifactOCI{ArtifactType: ociArtifactType(kind), DryRun: dryRun(ctx, input.DryRun)}
+	detail := fmt.Sprintf("%s (%s, %s)", filename, ociLayerMediaType, contentDigest(content))
+	for _, key := range keys {
+		detail += fmt.Sprintf("; %s=%s", key, annotations[key])
+	}
+	output.Changes = []PlannedChange{{Action: "push", Target: reference, Detail: detail}}
+	signingMode := signingModeKeyless
+	cosign := serverConfig(ctx).Cosign
+	if cosign.Key != "" {
+		signingMode = signingModeKey
+	}
+	if input.Sign {
+		output.Changes = append(output.Changes, PlannedChange{Action: "sign", Target: reference, Detail: signingMode})
+	}
+	if output.DryRun {
+		output.Reference = reference
+		output.Message = fmt.Sprintf("Dry run: would push %s %s as %s", kind, filename, reference)
+		if input.Sign {
+			output.Mode = signingMode
+			output.Message += fmt.Sprintf(" and sign it (%s)", output.Mode)
+		}
+		return nil, output, nil
+	}
+	if err := approveChanges(ctx, req, fmt.Sprintf("push_artifact_oci will push %s %s to %s.", kind, filename, reference), output.Changes); err != nil {
+		return nil, OutputPushArtifactOCI{}, err
+	}
+
+	output.Digest, err = serverConfig(ctx).ORAS.push(ctx, reference, output.ArtifactType, filename, content, annotations)
+	if err != nil {
+		return nil, OutputPushArtifactOCI{}, fmt.Errorf("failed to push %s: %w", reference, err)
+	}
+	output.Reference = reference
+	output.Message = fmt.Sprintf("Pushed %s %s as %s@%s", kind, filename, reference, output.Digest)
+
+	if input.Sign {
+		output.Mode = signingMode
+		signArgs := []string{"sign", "--yes"}
+		if cosign.Key != "" {
+			signArgs = append(signArgs, "--key", cosign.Key)
+		}
+		pinned := ociRepository(reference) + "@" + output.Digest
+		if _, err := cosign.run(ctx, append(signArgs, "--", pinned)...); err != nil {
+			return nil, OutputPushArtifactOCI{}, fmt.Errorf("pushed %s but failed to sign it: %w", pinned, err)
+		}
+		output.Signed = true
+		output.Message += fmt.Sprintf(" and signed it (%s)", output.Mode)
+	}
+	return nil, output, nil
+}
+
+// MetadataPullArtifactOCI describes the PullArtifactOCI tool.
+var MetadataPullArtifactOCI = &mcp.Tool{
+	Name: "pull_artifact_oci",
+	Description: "Pull a Gemara artifact from an OCI registry, pinned to the digest its reference resolves to, " +
+		"and return its content with its kind, id, and schema validation status. Optionally verifies the manifest's " +
+		"cosign signature before pulling.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"reference"},
+		"properties": map[string]interface{}{
+			"reference": map[string]interface{}{
+				"type":        "string",
+				"description": "Registry reference to pull (e.g., 'ghcr.io/org/catalogs/ccc:v1.2.0')",
+			},
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "File to read when the OCI artifact holds several YAML files",
+			},
+			"verify_signature": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Verify the manifest's cosign signature, with the server's public key or the expected signer identity",
+			},
+			"certificate_identity": map[string]interface{}{
+				"type":        "string",
+				"description": "Expected signer identity for keyless signatures (e.g., an email or workflow URL)",
+			},
+			"certificate_oidc_issuer": map[string]interface{}{
+				"type":        "string",
+				"description": "Expected OIDC issuer for keyless signatures (e.g., 'https://token.actions.githubusercontent.com')",
+			},
+		},
+	},
+}
+
+// InputPullArtifactOCI is the input for the PullArtifactOCI tool.
+type InputPullArtifactOCI struct {
+	Reference             string `json:"reference"`
+	Path                  string `json:"path,omitempty"`
+	VerifySignature       bool   `json:"verify_signature,omitempty"`
+	CertificateIdentity   string `json:"certificate_identity,omitempty"`
+	CertificateOIDCIssuer string `json:"certificate_oidc_issuer,omitempty"`
+}
+
+// OutputPullArtifactOCI is the output for the PullArtifactOCI tool.
+type OutputPullArtifactOCI struct {
+	Reference string   `json:"reference"`
+	Digest    string   `json:"digest"`
+	Kind      string   `json:"kind,omitempty"`
+	ID        string   `json:"id,omitempty"`
+	Version   string   `json:"version,omitempty"`
+	Valid     bool     `json:"valid"`
+	Errors    []string `json:"errors,omitempty"`
+	Verified  bool     `json:"verified"`
+	Content   string   `json:"content"`
+	Message   string   `json:"message"`
+}
+
+// PullArtifactOCI pulls an artifact from an OCI registry.
+func PullArtifactOCI(ctx context.Context, _ *mcp.CallToolRequest, input InputPullArtifactOCI) (*mcp.CallToolResult, OutputPullArtifactOCI, error) {
+	reference := strings.TrimPrefix(strings.TrimSpace(input.Reference), ociSourceScheme)
+	if reference == "" {
+		return nil, OutputPullArtifactOCI{}, fmt.Errorf("reference is required")
+	}
+	if err := validateOCIReference(reference); err != nil {
+		return nil, OutputPullArtifactOCI{}, err
+	}
+	var verifyArgs []string
+	cosign := serverConfig(ctx).Cosign
+	if input.VerifySignature {
+		verifyArgs = []string{"verify"}
+		if cosign.PublicKey != "" {
+			verifyArgs = append(verifyArgs, "--key", cosign.PublicKey)
+		} else if input.CertificateIdentity == "" || input.CertificateOIDCIssuer == "" {
+			return nil, OutputPullArtifactOCI{}, fmt.Errorf("certificate_identity and certificate_oidc_issuer are required to verify keyless signatures")
+		} else {
+			verifyArgs = append(verifyArgs, "--certificate-identity="+input.CertificateIdentity, "--certificate-oidc-issuer="+input.CertificateOIDCIssuer)
+		}
+	}
+
+	// Pin the digest so the verified manifest is the one pulled
+	digest, err := serverConfig(ctx).ORAS.resolve(ctx, reference)
+	if err != nil {
+		return nil, OutputPullArtifactOCI{}, err
+	}
+	pinned := ociRepository(reference) + "@" + digest
+	output := OutputPullArtifactOCI{Reference: reference, Digest: digest}
+	if input.VerifySignature {
+		if _, err := cosign.run(ctx, append(verifyArgs, "--", pinned)...); err != nil {
+			return nil, OutputPullArtifactOCI{}, fmt.Errorf("signature verification failed for %s: %w", pinned, err)
+		}
+		output.Verified = true
+	}
+
+	spec := pinned
+	if input.Path != "" {
+		spec += "//" + input.Path
+	}
+	content, err := fetchOCISource(ctx, spec)
+	if err != nil {
+		return nil, OutputPullArtifactOCI{}, err
+	}
+	if err := checkArtifactLimits(ctx, formatYAML, string(content)); err != nil {
+		return nil, OutputPullArtifactOCI{}, err
+	}
+	output.Content = string(content)
+
+	doc, err := parseArtifact(ctx, output.Content)
+	if err != nil {
+		return nil, OutputPullArtifactOCI{}, fmt.Errorf("%s does not hold a YAML artifact: %w", pinned, err)
+	}
+	output.Kind = artifactKind(doc)
+	metadata, _ := doc["metadata"].(map[string]interface{})
+	output.ID = stringField(metadata, "id")
+	output.Version = stringField(metadata, "version")
+	if output.Kind != "" {
+		schema, err := loadSchema(ctx)
+		if err != nil {
+			return nil, OutputPullArtifactOCI{}, err
+		}
+		validation, err := validateAgainstSchema(schema, normalizeDefinition(output.Kind), output.Content)
+		if err != nil {
+			return nil, OutputPullArtifactOCI{}, err
+		}
+		output.Valid = validation.Valid
+		output.Errors = validation.Errors
+	}
+
+	output.Message = fmt.Sprintf("Pulled %s %s from %s", output.Kind, output.ID, pinned)
+	if output.Verified {
+		output.Message += " (signature verified)"
+	}
+	if !output.Valid {
+		output.Message += "; it is not a valid Gemara artifact"
+	}
+	return nil, output, nil
+}
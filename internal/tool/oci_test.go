@@ -0,0 +1,222 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+	"oras.land/oras-go/v2/errdef"
+)
+
+// memoryRepository is an in-memory OCI repository. Like a registry, it
+// resolves the digests of tagged manifests as well as their tags.
+type memoryRepository struct {
+	*memory.Store
+}
+
+func (r memoryRepository) Tag(ctx context.Context, desc ocispec.Descriptor, reference string) error {
+	if err := r.Store.Tag(ctx, desc, reference); err != nil {
+		return err
+	}
+	return r.Store.Tag(ctx, desc, desc.Digest.String())
+}
+
+// memoryRegistry replaces the OCI registry with in-memory repositories,
+// keyed by repository, for the duration of the test.
+func memoryRegistry(t *testing.T) map[string]memoryRepository {
+	t.Helper()
+	repositories := map[string]memoryRepository{}
+	original := ociTarget
+	t.Cleanup(func() { ociTarget = original })
+	ociTarget = func(_ ORASConfig, repository string) (oras.Target, error) {
+		if _, ok := repositories[repository]; !ok {
+			repositories[repository] = memoryRepository{memory.New()}
+		}
+		return repositories[repository], nil
+	}
+	return repositories
+}
+
+func TestPushPullArtifactOCI(t *testing.T) {
+	useTestSchema(t)
+	repositories := memoryRegistry(t)
+	ctx := withTestConfig(func(c *Config) {
+		c.Cosign = fakeCosign(t, CosignConfig{Key: "cosign.key", PublicKey: "cosign.pub"})
+	})
+	catalog, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err)
+
+	_, _, err = PushArtifactOCI(ctx, nil, InputPushArtifactOCI{ArtifactContent: string(catalog), Reference: "ghcr.io/org/ccc"})
+	assert.ErrorContains(t, err, "has no tag and the artifact has no metadata version")
+
+	_, _, err = PushArtifactOCI(ctx, nil, InputPushArtifactOCI{ArtifactContent: "controls: x\n", Reference: "ghcr.io/org/ccc:v1"})
+	assert.ErrorContains(t, err, "refusing to push an invalid ControlCatalog")
+
+	_, pushed, err := PushArtifactOCI(ctx, nil, InputPushArtifactOCI{
+		ArtifactContent: string(catalog),
+		Reference:       "oci://ghcr.io/org/ccc:v1",
+		Annotations:     map[string]string{"org.opencontainers.image.source": "https://github.com/org/catalogs"},
+		Sign:            true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ghcr.io/org/ccc:v1", pushed.Reference)
+	assert.Equal(t, "application/vnd.gemara.control-catalog.v1", pushed.ArtifactType)
+	assert.True(t, pushed.Signed)
+	assert.Equal(t, signingModeKey, pushed.Mode)
+
+	repository, ok := repositories["ghcr.io/org/ccc"]
+	require.True(t, ok, "the artifact should be pushed to its repository")
+	desc, err := repository.Resolve(ctx, "v1")
+	require.NoError(t, err)
+	assert.Equal(t, desc.Digest.String(), pushed.Digest)
+	data, err := content.FetchAll(ctx, repository, desc)
+	require.NoError(t, err)
+	var manifest ocispec.Manifest
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	assert.Equal(t, "application/vnd.gemara.control-catalog.v1", manifest.ArtifactType)
+	assert.Equal(t, "https://github.com/org/catalogs", manifest.Annotations["org.opencontainers.image.source"])
+	require.Len(t, manifest.Layers, 1)
+	assert.Equal(t, ociLayerMediaType, manifest.Layers[0].MediaType)
+	assert.Equal(t, "FINOS-CCC.yaml", manifest.Layers[0].Annotations[ocispec.AnnotationTitle])
+
+	_, planned, err := PushArtifactOCI(ctx, nil, InputPushArtifactOCI{
+		ArtifactContent: string(catalog),
+		Reference:       "ghcr.io/org/ccc:v2",
+		Sign:            true,
+		DryRun:          true,
+	})
+	require.NoError(t, err)
+	assert.True(t, planned.DryRun)
+	assert.False(t, planned.Signed)
+	require.Len(t, planned.Changes, 2)
+	assert.Equal(t, PlannedChange{Action: "sign", Target: "ghcr.io/org/ccc:v2", Detail: signingModeKey}, planned.Changes[1])
+	assert.Contains(t, planned.Changes[0].Detail, contentDigest(catalog))
+	_, err = repository.Resolve(ctx, "v2")
+	assert.ErrorIs(t, err, errdef.ErrNotFound, "a dry run should not push")
+
+	_, pulled, err := PullArtifactOCI(ctx, nil, InputPullArtifactOCI{Reference: "ghcr.io/org/ccc:v1", VerifySignature: true})
+	require.NoError(t, err)
+	assert.Equal(t, pushed.Digest, pulled.Digest)
+	assert.Equal(t, "ControlCatalog", pulled.Kind)
+	assert.Equal(t, "FINOS-CCC", pulled.ID)
+	assert.True(t, pulled.Valid)
+	assert.True(t, pulled.Verified)
+	assert.Equal(t, string(catalog), pulled.Content)
+}
+
+func TestPullArtifactOCIKeylessVerification(t *testing.T) {
+	memoryRegistry(t)
+	ctx := withTestConfig(func(c *Config) {
+		c.Cosign = fakeCosign(t, CosignConfig{})
+	})
+	_, _, err := PullArtifactOCI(ctx, nil, InputPullArtifactOCI{Reference: "ghcr.io/org/ccc:v1", VerifySignature: true})
+	assert.ErrorContains(t, err, "certificate_identity and certificate_oidc_issuer are required")
+}
+
+func TestOCIReferences(t *testing.T) {
+	tests := []struct {
+		reference      string
+		wantRepository string
+		wantVersioned  bool
+	}{
+		{reference: "ghcr.io/org/ccc", wantRepository: "ghcr.io/org/ccc"},
+		{reference: "localhost:5000/ccc", wantRepository: "localhost:5000/ccc"},
+		{reference: "localhost:5000/ccc:v1", wantRepository: "localhost:5000/ccc", wantVersioned: true},
+		{reference: "ghcr.io/org/ccc@sha256:0123", wantRepository: "ghcr.io/org/ccc", wantVersioned: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reference, func(t *testing.T) {
+			assert.Equal(t, tt.wantRepository, ociRepository(tt.reference))
+			assert.Equal(t, tt.wantVersioned, ociVersioned(tt.reference))
+		})
+	}
+	assert.Equal(t, "application/vnd.gemara.evaluation-log.v1", ociArtifactType("EvaluationLog"))
+}
+
+func TestValidateOCIReference(t *testing.T) {
+	tests := []struct {
+		reference string
+		wantErr   bool
+	}{
+		{reference: "ghcr.io/org/ccc"},
+		{reference: "ghcr.io/org/ccc:v1.2.0"},
+		{reference: "localhost:5000/ccc:v1"},
+		{reference: "registry.example.com/org/my_catalogs/ccc-storage@sha256:0123abcd"},
+		{reference: "--registry-config=/etc/shadow", wantErr: true},
+		{reference: "-ghcr.io/org/ccc", wantErr: true},
+		{reference: "ghcr.io/org/ccc --insecure", wantErr: true},
+		{reference: "ghcr.io/Org/ccc", wantErr: true},
+		{reference: "ghcr.io/org/ccc:-v1", wantErr: true},
+		{reference: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reference, func(t *testing.T) {
+			err := validateOCIReference(tt.reference)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "invalid OCI reference")
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPullArtifactOCIInvalidReference(t *testing.T) {
+	repositories := memoryRegistry(t)
+	ctx := withTestConfig(func(c *Config) {})
+	_, _, err := PullArtifactOCI(ctx, nil, InputPullArtifactOCI{Reference: "--output=/tmp"})
+	assert.ErrorContains(t, err, "invalid OCI reference")
+	_, err = fetchOCISource(ctx, "--output=/tmp//catalog.yaml")
+	assert.ErrorContains(t, err, "invalid OCI reference")
+	_, err = fetchOCISource(ctx, "ghcr.io/org/ccc//catalog.yaml")
+	assert.ErrorContains(t, err, "has no tag or digest")
+	assert.Empty(t, repositories, "no repository should be opened")
+}
+
+func TestFetchOCISourceLayers(t *testing.T) {
+	repositories := memoryRegistry(t)
+	ctx := context.Background()
+	repository := memoryRepository{memory.New()}
+	repositories["ghcr.io/org/bundle"] = repository
+
+	var layers []ocispec.Descriptor
+	for title, data := range map[string]string{
+		"catalog.yaml": "title: catalog\n",
+		"policy.yml":   "title: policy\n",
+		"README.md":    "# bundle\n",
+	} {
+		layer := content.NewDescriptorFromBytes(ociLayerMediaType, []byte(data))
+		layer.Annotations = map[string]string{ocispec.AnnotationTitle: title}
+		require.NoError(t, repository.Push(ctx, layer, bytes.NewReader([]byte(data))))
+		layers = append(layers, layer)
+	}
+	manifest, err := oras.PackManifest(ctx, repository, oras.PackManifestVersion1_1, "application/vnd.gemara.bundle.v1", oras.PackManifestOptions{Layers: layers})
+	require.NoError(t, err)
+	require.NoError(t, repository.Tag(ctx, manifest, "v1"))
+
+	ctx = withTestConfig(func(c *Config) {})
+	_, err = fetchOCISource(ctx, "ghcr.io/org/bundle:v1")
+	assert.ErrorContains(t, err, "contains 2 YAML files")
+
+	data, err := fetchOCISource(ctx, "ghcr.io/org/bundle@"+manifest.Digest.String()+"//policy.yml")
+	require.NoError(t, err)
+	assert.Equal(t, "title: policy\n", string(data))
+
+	_, err = fetchOCISource(ctx, "ghcr.io/org/bundle:v1//missing.yaml")
+	assert.ErrorContains(t, err, `has no file "missing.yaml"`)
+
+	ctx = withTestConfig(func(c *Config) { c.MaxArtifactSize = 4 })
+	_, err = fetchOCISource(ctx, "ghcr.io/org/bundle:v1//catalog.yaml")
+	assert.ErrorContains(t, err, "exceeding the 4 byte limit")
+}
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// cursorPrefix starts the decoded form of every pagination cursor, so that a
+// cursor from another source is rejected rather than misread.
+const cursorPrefix = "offset:"
+
+// PageInput selects a page of a tool's list output. It is embedded in the
+// input of every paginated tool.
+type PageInput struct {
+	// Offset is the index of the first item returned.
+	Offset int `json:"offset,omitempty"`
+	// Limit is the maximum number of items returned; zero returns them all.
+	Limit int `json:"limit,omitempty"`
+	// Cursor continues from a previous page and takes precedence over Offset.
+	Cursor string `json:"cursor,omitempty"`
+	// MaxOutputBytes bounds the JSON size of the items returned; zero is unbounded.
+	MaxOutputBytes int `json:"max_output_bytes,omitempty"`
+}
+
+// PageInfo describes the page of items a paginated tool returned.
+type PageInfo struct {
+	Offset   int `json:"offset"`
+	Returned int `json:"returned"`
+	Total    int `json:"total"`
+	// NextCursor continues with the next page; it is empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// Truncated is set when items were left out because of max_output_bytes.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// paginationProperties returns the input schema properties of PageInput, to
+// be merged into a paginated tool's input schema.
+func paginationProperties(items string) map[string]interface{} {
+	return map[string]interface{}{
+		"offset": map[string]interface{}{
+			"type":        "integer",
+			"minimum":     0,
+			"description": fmt.Sprintf("Index of the first of the %s to return (default: 0)", items),
+		},
+		"limit": map[string]interface{}{
+			"type":        "integer",
+			"minimum":     0,
+			"description": fmt.Sprintf("Maximum number of %s to return (default: all)", items),
+		},
+		"cursor": map[string]interface{}{
+			"type":        "string",
+			"description": "next_cursor of a previous page, to continue where it stopped; takes precedence over offset",
+		},
+		"max_output_bytes": map[string]interface{}{
+			"type":        "integer",
+			"minimum":     0,
+			"description": fmt.Sprintf("Stop adding %s once their JSON would exceed this many bytes, returning next_cursor and truncated instead (default: no limit)", items),
+		},
+	}
+}
+
+// withPagination returns schema properties merged with the pagination properties.
+func withPagination(properties map[string]interface{}, items string) map[string]interface{} {
+	merged := paginationProperties(items)
+	for k, v := range properties {
+		merged[k] = v
+	}
+	return merged
+}
+
+// paginate returns the page of items that input selects. At least one item is
+// returned when any remain, even if it alone exceeds MaxOutputBytes, so that
+// paging always makes progress.
+func paginate[T any](items []T, input PageInput) ([]T, PageInfo, error) {
+	offset := input.Offset
+	if input.Cursor != "" {
+		var err error
+		if offset, err = decodeCursor(input.Cursor); err != nil {
+			return nil, PageInfo{}, err
+		}
+	}
+	if offset < 0 || input.Limit < 0 || input.MaxOutputBytes < 0 {
+		return nil, PageInfo{}, fmt.Errorf("offset, limit, and max_output_bytes must not be negative")
+	}
+	if offset > len(items) {
+		offset = len(items)
+	}
+
+	end := len(items)
+	if input.Limit > 0 && offset+input.Limit < end {
+		end = offset + input.Limit
+	}
+	page := PageInfo{Offset: offset, Total: len(items)}
+	if input.MaxOutputBytes > 0 {
+		// Brackets and separating commas count towards the budget
+		size := 2
+		for i := offset; i < end; i++ {
+			raw, err := json.Marshal(items[i])
+			if err != nil {
+				return nil, PageInfo{}, fmt.Errorf("failed to measure output: %w", err)
+			}
+			if i > offset {
+				size++
+			}
+			size += len(raw)
+			if size > input.MaxOutputBytes && i > offset {
+				end, page.Truncated = i, true
+				break
+			}
+		}
+	}
+
+	page.Returned = end - offset
+	if end < len(items) {
+		page.NextCursor = encodeCursor(end)
+	}
+	return items[offset:end], page, nil
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil && strings.HasPrefix(string(raw), cursorPrefix) {
+		if offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix)); err == nil && offset >= 0 {
+			return offset, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid cursor %q: pass next_cursor from a previous page unchanged", cursor)
+}
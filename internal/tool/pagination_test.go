@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginate(t *testing.T) {
+	// Each item marshals to 7 bytes, such as "item0"
+	items := []string{"item0", "item1", "item2", "item3", "item4"}
+
+	tests := []struct {
+		name        string
+		input       PageInput
+		want        []string
+		wantPage    PageInfo
+		errContains string
+	}{
+		{
+			name:     "everything by default",
+			want:     items,
+			wantPage: PageInfo{Returned: 5, Total: 5},
+		},
+		{
+			name:     "limit",
+			input:    PageInput{Limit: 2},
+			want:     []string{"item0", "item1"},
+			wantPage: PageInfo{Returned: 2, Total: 5, NextCursor: encodeCursor(2)},
+		},
+		{
+			name:     "cursor takes precedence over offset",
+			input:    PageInput{Offset: 0, Limit: 2, Cursor: encodeCursor(4)},
+			want:     []string{"item4"},
+			wantPage: PageInfo{Offset: 4, Returned: 1, Total: 5},
+		},
+		{
+			name:     "offset past the end",
+			input:    PageInput{Offset: 9},
+			want:     []string{},
+			wantPage: PageInfo{Offset: 5, Total: 5},
+		},
+		{
+			name:     "byte budget",
+			input:    PageInput{MaxOutputBytes: 20},
+			want:     []string{"item0", "item1"},
+			wantPage: PageInfo{Returned: 2, Total: 5, NextCursor: encodeCursor(2), Truncated: true},
+		},
+		{
+			name:     "budget smaller than one item still makes progress",
+			input:    PageInput{Offset: 3, MaxOutputBytes: 1},
+			want:     []string{"item3"},
+			wantPage: PageInfo{Offset: 3, Returned: 1, Total: 5, NextCursor: encodeCursor(4), Truncated: true},
+		},
+		{
+			name:        "foreign cursor",
+			input:       PageInput{Cursor: "bm90LWEtY3Vyc29y"},
+			errContains: "invalid cursor",
+		},
+		{
+			name:        "negative limit",
+			input:       PageInput{Limit: -1},
+			errContains: "must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, page, err := paginate(items, tt.input)
+			if tt.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantPage, page)
+		})
+	}
+}
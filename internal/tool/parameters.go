@@ -0,0 +1,384 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Statuses of a parameter in a resolution.
+const (
+	parameterResolved   = "resolved"
+	parameterOutOfRange = "out_of_range"
+	parameterUnresolved = "unresolved"
+	parameterUnused     = "unused"
+)
+
+// MetadataResolveControlParameters describes the ResolveControlParameters tool.
+var MetadataResolveControlParameters = &mcp.Tool{
+	Name: "resolve_control_parameters",
+	Description: "Substitute the {{ parameter }} placeholders of a ControlCatalog with the values set by a policy or " +
+		"profile, checking each value against its constraints (min, max, allowed values, pattern). Returns the resolved " +
+		"catalog, validated against the schema, and every parameter with its status: resolved, out of range (left as " +
+		"a placeholder), unresolved, or set but unused. Nothing is written to disk.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": func() map[string]interface{} {
+			properties := artifactSourceProperties("resolve")
+			properties["values_content"] = map[string]interface{}{
+				"type": "string",
+				"description": "YAML or JSON document of parameter values: a 'parameters' mapping of name to a value or to " +
+					"{value, default, min, max, allowed, pattern}, or a 'parameters' list of {name or id, value or values} " +
+					"such as a tailoring record",
+			}
+			properties["values_uri"] = map[string]interface{}{
+				"type":        "string",
+				"description": "URI of the parameter values instead of inline content: file:// (within the workspace root) or https://",
+			}
+			properties["id"] = map[string]interface{}{
+				"type":        "string",
+				"description": "metadata.id of the resolved catalog (default: <catalog id>-resolved)",
+			}
+			return properties
+		}(),
+	},
+}
+
+// InputResolveControlParameters is the input for the ResolveControlParameters tool.
+type InputResolveControlParameters struct {
+	ArtifactContent string `json:"artifact_content,omitempty"`
+	ArtifactURI     string `json:"artifact_uri,omitempty"`
+	ValuesContent   string `json:"values_content,omitempty"`
+	ValuesURI       string `json:"values_uri,omitempty"`
+	ID              string `json:"id,omitempty"`
+}
+
+// ResolvedParameter is the outcome of resolving one parameter.
+type ResolvedParameter struct {
+	Name   string `json:"name"`
+	Value  string `json:"value,omitempty"`
+	Status string `json:"status"`
+	// Problem explains an out of range, unresolved, or unused parameter.
+	Problem  string   `json:"problem,omitempty"`
+	Controls []string `json:"controls"`
+}
+
+// OutputResolveControlParameters is the output for the ResolveControlParameters tool.
+type OutputResolveControlParameters struct {
+	ResolvedContent string              `json:"resolved_content"`
+	Parameters      []ResolvedParameter `json:"parameters"`
+	// Resolved is true when no placeholders are left in the catalog.
+	Resolved bool     `json:"resolved"`
+	Valid    bool     `json:"valid"`
+	Errors   []string `json:"errors,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// parameterSpec is the value and constraints of a parameter.
+type parameterSpec struct {
+	value    string
+	hasValue bool
+	min, max *float64
+	allowed  []string
+	pattern  *regexp.Regexp
+}
+
+// ResolveControlParameters substitutes parameter values into a catalog.
+func ResolveControlParameters(ctx context.Context, _ *mcp.CallToolRequest, input InputResolveControlParameters) (*mcp.CallToolResult, OutputResolveControlParameters, error) {
+	content, err := artifactInputContent(ctx, input.ArtifactContent, input.ArtifactURI)
+	if err != nil {
+		return nil, OutputResolveControlParameters{}, err
+	}
+	parsed, err := parseArtifact(ctx, content)
+	if err != nil {
+		return nil, OutputResolveControlParameters{}, err
+	}
+	if artifactKind(parsed) != "ControlCatalog" {
+		return nil, OutputResolveControlParameters{}, fmt.Errorf("parameter resolution requires a ControlCatalog")
+	}
+	var doc yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions([]byte(content), &doc, yaml.UseOrderedMap()); err != nil {
+		return nil, OutputResolveControlParameters{}, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	var valuesContent string
+	switch {
+	case input.ValuesContent == "" && input.ValuesURI == "":
+		return nil, OutputResolveControlParameters{}, fmt.Errorf("values_content or values_uri is required")
+	case input.ValuesContent != "" && input.ValuesURI != "":
+		return nil, OutputResolveControlParameters{}, fmt.Errorf("values_content and values_uri are mutually exclusive")
+	case input.ValuesURI != "":
+		raw, err := readArtifactURI(ctx, input.ValuesURI)
+		if err != nil {
+			return nil, OutputResolveControlParameters{}, err
+		}
+		valuesContent = string(raw)
+	default:
+		valuesContent = input.ValuesContent
+	}
+	specs, err := parseParameterValues(ctx, valuesContent)
+	if err != nil {
+		return nil, OutputResolveControlParameters{}, err
+	}
+
+	// Only values that satisfy their constraints are substituted
+	values := map[string]string{}
+	problems := map[string]string{}
+	for name, spec := range specs {
+		if !spec.hasValue {
+			continue
+		}
+		if problem := spec.check(); problem != "" {
+			problems[name] = problem
+			continue
+		}
+		values[name] = spec.value
+	}
+
+	// Placeholders outside controls, such as in family descriptions, are
+	// resolved too but attributed to no control
+	controlsOf := map[string][]string{}
+	for _, name := range parameterPlaceholders(doc) {
+		controlsOf[name] = []string{}
+	}
+	for _, c := range mapValueList(doc, "controls") {
+		for _, name := range parameterPlaceholders(c) {
+			if id := orderedID(c); !slices.Contains(controlsOf[name], id) {
+				controlsOf[name] = append(controlsOf[name], id)
+			}
+		}
+	}
+	unresolved := map[string]bool{}
+	resolved := substituteParameters(doc, values, map[string]bool{}, unresolved).(yaml.MapSlice)
+
+	output := OutputResolveControlParameters{Parameters: []ResolvedParameter{}, Resolved: len(unresolved) == 0}
+	names := make([]string, 0, len(specs)+len(controlsOf))
+	for name := range controlsOf {
+		names = append(names, name)
+	}
+	for name := range specs {
+		if _, ok := controlsOf[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	counts := map[string]int{}
+	for _, name := range names {
+		controls, found := controlsOf[name]
+		p := ResolvedParameter{Name: name, Controls: controls}
+		spec, set := specs[name]
+		switch {
+		case !found:
+			p.Controls = []string{}
+			p.Status = parameterUnused
+			p.Value = spec.value
+			p.Problem = "no placeholder in the catalog uses this parameter"
+		case problems[name] != "":
+			p.Status = parameterOutOfRange
+			p.Value = spec.value
+			p.Problem = problems[name]
+		case unresolved[name]:
+			p.Status = parameterUnresolved
+			p.Problem = "no value is set"
+			if set {
+				p.Problem = "the parameter has constraints but no value or default"
+			}
+		default:
+			p.Status = parameterResolved
+			p.Value = spec.value
+		}
+		counts[p.Status]++
+		output.Parameters = append(output.Parameters, p)
+	}
+
+	catalogID := metadataID(parsed)
+	resolvedID := input.ID
+	if resolvedID == "" {
+		resolvedID = catalogID + "-resolved"
+	}
+	if m, ok := mapValue(resolved, "metadata").(yaml.MapSlice); ok {
+		resolved = setMapValue(resolved, "metadata", setMapValue(m, "id", resolvedID))
+	}
+	out, err := yaml.MarshalWithOptions(resolved, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return nil, OutputResolveControlParameters{}, fmt.Errorf("failed to encode resolved catalog: %w", err)
+	}
+	output.ResolvedContent = string(out)
+
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputResolveControlParameters{}, err
+	}
+	validation, err := validateAgainstSchema(schema, "#ControlCatalog", output.ResolvedContent)
+	if err != nil {
+		return nil, OutputResolveControlParameters{}, err
+	}
+	output.Valid = validation.Valid
+	output.Errors = validation.Errors
+	output.Message = fmt.Sprintf("Resolved %d parameter(s) in %s; %d out of range, %d unresolved, %d unused; %s",
+		counts[parameterResolved], resolvedID, counts[parameterOutOfRange], counts[parameterUnresolved], counts[parameterUnused],
+		strings.ToLower(validation.Message))
+	return nil, output, nil
+}
+
+// parameterPlaceholders returns the names of the placeholders under node.
+func parameterPlaceholders(node interface{}) []string {
+	var names []string
+	switch v := node.(type) {
+	case yaml.MapSlice:
+		for _, item := range v {
+			names = append(names, parameterPlaceholders(item.Value)...)
+		}
+	case []interface{}:
+		for _, elem := range v {
+			names = append(names, parameterPlaceholders(elem)...)
+		}
+	case string:
+		for _, m := range parameterPlaceholder.FindAllStringSubmatch(v, -1) {
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
+
+// parseParameterValues reads a parameter values document. Parameters are a
+// mapping of name to a scalar value or to a value with constraints, or a list
+// of entries naming the parameter with name, id, or param-id, as tailoring
+// records and OSCAL set-parameters do.
+func parseParameterValues(ctx context.Context, content string) (map[string]parameterSpec, error) {
+	if err := checkArtifactLimits(ctx, formatYAML, content); err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse parameter values: %w", err)
+	}
+	raw, ok := doc["parameters"]
+	if !ok {
+		raw = doc["set-parameters"]
+	}
+
+	specs := map[string]parameterSpec{}
+	switch params := raw.(type) {
+	case map[string]interface{}:
+		for name, v := range params {
+			spec, err := parameterSpecOf(name, v)
+			if err != nil {
+				return nil, err
+			}
+			specs[name] = spec
+		}
+	case []interface{}:
+		for i, v := range params {
+			entry, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("parameters[%d] is not a mapping", i)
+			}
+			var name string
+			for _, key := range []string{"name", "id", "param-id"} {
+				if s, ok := entry[key].(string); ok && s != "" {
+					name = s
+					break
+				}
+			}
+			if name == "" {
+				return nil, fmt.Errorf("parameters[%d] has no name, id, or param-id", i)
+			}
+			spec, err := parameterSpecOf(name, entry)
+			if err != nil {
+				return nil, err
+			}
+			specs[name] = spec
+		}
+	default:
+		return nil, fmt.Errorf("parameter values need a 'parameters' mapping or list")
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("parameter values set no parameters")
+	}
+	return specs, nil
+}
+
+// parameterSpecOf reads the value and constraints of a parameter.
+func parameterSpecOf(name string, v interface{}) (parameterSpec, error) {
+	entry, ok := v.(map[string]interface{})
+	if !ok {
+		if v == nil {
+			return parameterSpec{}, nil
+		}
+		return parameterSpec{value: fmt.Sprint(v), hasValue: true}, nil
+	}
+
+	var spec parameterSpec
+	switch {
+	case entry["value"] != nil:
+		spec.value, spec.hasValue = fmt.Sprint(entry["value"]), true
+	case entry["values"] != nil:
+		list, ok := entry["values"].([]interface{})
+		if !ok {
+			return spec, fmt.Errorf("parameter %s: values must be a list", name)
+		}
+		parts := make([]string, len(list))
+		for i, item := range list {
+			parts[i] = fmt.Sprint(item)
+		}
+		spec.value, spec.hasValue = strings.Join(parts, ", "), len(parts) > 0
+	case entry["default"] != nil:
+		spec.value, spec.hasValue = fmt.Sprint(entry["default"]), true
+	}
+	for key, bound := range map[string]**float64{"min": &spec.min, "max": &spec.max} {
+		if entry[key] == nil {
+			continue
+		}
+		n, err := strconv.ParseFloat(fmt.Sprint(entry[key]), 64)
+		if err != nil {
+			return spec, fmt.Errorf("parameter %s: %s must be a number", name, key)
+		}
+		*bound = &n
+	}
+	if allowed, ok := entry["allowed"].([]interface{}); ok {
+		for _, a := range allowed {
+			spec.allowed = append(spec.allowed, fmt.Sprint(a))
+		}
+	}
+	if pattern, ok := entry["pattern"].(string); ok && pattern != "" {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return spec, fmt.Errorf("parameter %s: invalid pattern: %w", name, err)
+		}
+		spec.pattern = re
+	}
+	return spec, nil
+}
+
+// check returns why the value violates the constraints, or an empty string.
+func (s parameterSpec) check() string {
+	if s.min != nil || s.max != nil {
+		n, err := strconv.ParseFloat(strings.TrimSpace(s.value), 64)
+		if err != nil {
+			return fmt.Sprintf("value %q is not a number", s.value)
+		}
+		if s.min != nil && n < *s.min {
+			return fmt.Sprintf("value %s is below the minimum of %s", s.value, strconv.FormatFloat(*s.min, 'f', -1, 64))
+		}
+		if s.max != nil && n > *s.max {
+			return fmt.Sprintf("value %s is above the maximum of %s", s.value, strconv.FormatFloat(*s.max, 'f', -1, 64))
+		}
+	}
+	if len(s.allowed) > 0 && !slices.Contains(s.allowed, s.value) {
+		return fmt.Sprintf("value %q is not one of: %s", s.value, strings.Join(s.allowed, ", "))
+	}
+	if s.pattern != nil && !s.pattern.MatchString(s.value) {
+		return fmt.Sprintf("value %q does not match the pattern", s.value)
+	}
+	return ""
+}
@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveControlParameters(t *testing.T) {
+	useTestSchema(t)
+
+	tests := []struct {
+		name           string
+		values         string
+		wantErr        bool
+		errContains    string
+		wantStatuses   map[string]string
+		wantResolved   bool
+		wantContains   []string
+		wantProblem    map[string]string
+		wantNotContain []string
+	}{
+		{
+			name:        "no parameters",
+			values:      "title: nothing\n",
+			wantErr:     true,
+			errContains: "'parameters' mapping or list",
+		},
+		{
+			name:        "invalid bound",
+			values:      "parameters:\n  rotation_days:\n    value: 90\n    max: ninety\n",
+			wantErr:     true,
+			errContains: "max must be a number",
+		},
+		{
+			name:   "every parameter in range",
+			values: "parameters:\n  rotation_days:\n    value: 90\n    min: 1\n    max: 365\n  reviewer: the security team\n",
+			wantStatuses: map[string]string{
+				"rotation_days": parameterResolved,
+				"reviewer":      parameterResolved,
+			},
+			wantResolved: true,
+			wantContains: []string{
+				"id: BASE-resolved",
+				"Credentials are rotated every 90 days.",
+				"Keys older than 90 days are revoked.",
+				"Access is reviewed by the security team.",
+			},
+		},
+		{
+			name: "out of range, unresolved, and unused",
+			values: `parameters:
+  rotation_days:
+    value: 400
+    max: 365
+  reviewer:
+    allowed: [security, audit]
+  region: eu-west-1
+`,
+			wantStatuses: map[string]string{
+				"rotation_days": parameterOutOfRange,
+				"reviewer":      parameterUnresolved,
+				"region":        parameterUnused,
+			},
+			wantProblem: map[string]string{
+				"rotation_days": "above the maximum of 365",
+				"reviewer":      "constraints but no value",
+			},
+			wantContains:   []string{"{{ rotation_days }}", "{{ reviewer }}"},
+			wantNotContain: []string{"400"},
+		},
+		{
+			name: "tailoring record list",
+			values: `parameters:
+  - name: rotation_days
+    value: "30"
+    controls: [AC.C01]
+  - param-id: reviewer
+    values: [security, audit]
+    allowed: ["security, audit"]
+`,
+			wantStatuses: map[string]string{
+				"rotation_days": parameterResolved,
+				"reviewer":      parameterResolved,
+			},
+			wantResolved: true,
+			wantContains: []string{"every 30 days", "reviewed by security, audit."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := ResolveControlParameters(context.Background(), nil, InputResolveControlParameters{
+				ArtifactContent: baselineCatalog,
+				ValuesContent:   tt.values,
+			})
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, output.Valid, "resolved catalog should validate: %v", output.Errors)
+			assert.Equal(t, tt.wantResolved, output.Resolved)
+			statuses := map[string]string{}
+			for _, p := range output.Parameters {
+				statuses[p.Name] = p.Status
+				if want, ok := tt.wantProblem[p.Name]; ok {
+					assert.Contains(t, p.Problem, want)
+				}
+			}
+			assert.Equal(t, tt.wantStatuses, statuses)
+			for _, want := range tt.wantContains {
+				assert.Contains(t, output.ResolvedContent, want)
+			}
+			for _, unwanted := range tt.wantNotContain {
+				assert.NotContains(t, output.ResolvedContent, unwanted)
+			}
+		})
+	}
+}
+
+func TestResolveControlParametersControls(t *testing.T) {
+	useTestSchema(t)
+	_, output, err := ResolveControlParameters(context.Background(), nil, InputResolveControlParameters{
+		ArtifactContent: baselineCatalog,
+		ValuesContent:   `{"parameters": {"rotation_days": 90}}`,
+		ID:              "ORG-PROD",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []ResolvedParameter{
+		{Name: "reviewer", Status: parameterUnresolved, Problem: "no value is set", Controls: []string{"AC.C02"}},
+		{Name: "rotation_days", Value: "90", Status: parameterResolved, Controls: []string{"AC.C01"}},
+	}, output.Parameters)
+	assert.Contains(t, output.ResolvedContent, "id: ORG-PROD")
+	assert.Contains(t, output.Message, "Resolved 1 parameter(s) in ORG-PROD; 0 out of range, 1 unresolved, 0 unused")
+}
@@ -0,0 +1,272 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Normative strengths of candidate policy statements.
+const (
+	strengthMust   = "must"
+	strengthShould = "should"
+	strengthMay    = "may"
+)
+
+var (
+	// normativeShould and normativeMay match objectives that recommend or
+	// permit rather than require; everything else becomes a "must".
+	normativeShould = regexp.MustCompile(`(?i)\b(?:should|recommended|encouraged|preferably|where (?:possible|practical|feasible))\b`)
+	normativeMay    = regexp.MustCompile(`(?i)\b(?:may|optional(?:ly)?|can choose)\b`)
+	// normativeVerb matches objectives that already read as an obligation.
+	normativeVerb = regexp.MustCompile(`(?i)\b(?:must|shall|should|may|will|is required to|are required to)\b`)
+	// imperativeObjective matches objectives phrased as an instruction, which
+	// become the action of the statement rather than its outcome.
+	imperativeObjective = regexp.MustCompile(`(?i)^(?:require|use|enable|disable|implement|maintain|establish|restrict|limit|protect|encrypt|review|define|document|monitor|log|apply|enforce|prevent|perform|provide|configure|manage|identify|rotate|remove|retain|train|assign|approve|test|scan|patch|back up|segment)\b`)
+)
+
+// MetadataExtractPolicyCandidates describes the ExtractPolicyCandidates tool.
+var MetadataExtractPolicyCandidates = &mcp.Tool{
+	Name: "extract_policy_candidates",
+	Description: "Read a Layer 1 GuidanceDocument and propose Layer 3 policy statements, one per guideline, each mapped " +
+		"back to its guidance category and guideline with an inferred normative strength (must, should, may) and " +
+		"evaluation method. Returns the candidates and a draft Policy importing the guidance with an assessment plan " +
+		"per candidate, validated against the schema. Nothing is written to disk.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": func() map[string]interface{} {
+			properties := artifactSourceProperties("analyze")
+			properties["policy_id"] = map[string]interface{}{
+				"type":        "string",
+				"description": "Metadata id of the draft policy (default: the guidance id followed by -POLICY)",
+			}
+			properties["title"] = map[string]interface{}{
+				"type":        "string",
+				"description": "Title of the draft policy (default: derived from the guidance title)",
+			}
+			properties["categories"] = map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Only extract candidates from these guidance category IDs (default: every category)",
+			}
+			properties["frequency"] = map[string]interface{}{
+				"type":        "string",
+				"description": "Assessment frequency for every candidate, such as 'quarterly' (default: a placeholder)",
+			}
+			return properties
+		}(),
+	},
+}
+
+// InputExtractPolicyCandidates is the input for the ExtractPolicyCandidates tool.
+type InputExtractPolicyCandidates struct {
+	ArtifactContent string   `json:"artifact_content,omitempty"`
+	ArtifactURI     string   `json:"artifact_uri,omitempty"`
+	PolicyID        string   `json:"policy_id,omitempty"`
+	Title           string   `json:"title,omitempty"`
+	Categories      []string `json:"categories,omitempty"`
+	Frequency       string   `json:"frequency,omitempty"`
+}
+
+// PolicyCandidate is a proposed policy statement and the guidance it derives from.
+type PolicyCandidate struct {
+	// ID is the id of the assessment plan drafted for the candidate.
+	ID        string `json:"id"`
+	Statement string `json:"statement"`
+	Strength  string `json:"strength"`
+	// Method is the evaluation method of the drafted assessment plan.
+	Method        string `json:"method"`
+	Category      string `json:"category"`
+	CategoryTitle string `json:"category_title"`
+	Guideline     string `json:"guideline"`
+	Objective     string `json:"objective"`
+}
+
+// OutputExtractPolicyCandidates is the output for the ExtractPolicyCandidates tool.
+type OutputExtractPolicyCandidates struct {
+	Candidates []PolicyCandidate `json:"candidates"`
+	// Content is the draft Policy, with an assessment plan per candidate.
+	Content string   `json:"content"`
+	Valid   bool     `json:"valid"`
+	Errors  []string `json:"errors,omitempty"`
+	// Placeholders lists the paths left for the author to complete.
+	Placeholders []string `json:"placeholders"`
+	Message      string   `json:"message"`
+}
+
+// ExtractPolicyCandidates proposes policy statements for the guidelines of a
+// guidance document and drafts the policy that adopts them.
+func ExtractPolicyCandidates(ctx context.Context, _ *mcp.CallToolRequest, input InputExtractPolicyCandidates) (*mcp.CallToolResult, OutputExtractPolicyCandidates, error) {
+	content, err := artifactInputContent(ctx, input.ArtifactContent, input.ArtifactURI)
+	if err != nil {
+		return nil, OutputExtractPolicyCandidates{}, err
+	}
+	guidance, err := parseArtifact(ctx, content)
+	if err != nil {
+		return nil, OutputExtractPolicyCandidates{}, err
+	}
+	categories, ok := guidance["categories"].([]interface{})
+	if !ok || len(categories) == 0 {
+		return nil, OutputExtractPolicyCandidates{}, fmt.Errorf("guidance document has no categories")
+	}
+	guidanceID := metadataID(guidance)
+	if guidanceID == "" {
+		return nil, OutputExtractPolicyCandidates{}, fmt.Errorf("guidance document has no metadata id to reference")
+	}
+	policyID := input.PolicyID
+	if policyID == "" {
+		policyID = guidanceID + "-POLICY"
+	}
+
+	wantCategories := stringSet(input.Categories)
+	output := OutputExtractPolicyCandidates{
+		Candidates:   []PolicyCandidate{},
+		Placeholders: []string{"$.metadata.description", "$.metadata.author"},
+	}
+	found := map[string]bool{}
+	var plans []interface{}
+	for _, item := range categories {
+		category, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		categoryID := entityID(category)
+		if categoryID == "" || len(wantCategories) > 0 && !wantCategories[categoryID] {
+			continue
+		}
+		found[categoryID] = true
+		categoryTitle, _ := category["title"].(string)
+
+		guidelines, _ := category["guidelines"].([]interface{})
+		for _, g := range guidelines {
+			guideline, ok := g.(map[string]interface{})
+			if !ok || entityID(guideline) == "" {
+				continue
+			}
+			candidate := policyCandidate(guideline)
+			candidate.Category, candidate.CategoryTitle = categoryID, categoryTitle
+			output.Candidates = append(output.Candidates, candidate)
+
+			frequency := input.Frequency
+			if frequency == "" {
+				frequency = skeletonPlaceholder
+				output.Placeholders = append(output.Placeholders, fmt.Sprintf("$.adherence.assessment-plans[%d].frequency", len(plans)))
+			}
+			plans = append(plans, yaml.MapSlice{
+				{Key: "id", Value: candidate.ID},
+				{Key: "requirement-id", Value: candidate.Guideline},
+				{Key: "frequency", Value: frequency},
+				{Key: "evaluation-methods", Value: []interface{}{
+					yaml.MapSlice{{Key: "type", Value: candidate.Method}},
+				}},
+			})
+		}
+	}
+	for _, id := range input.Categories {
+		if !found[id] {
+			return nil, OutputExtractPolicyCandidates{}, fmt.Errorf("category %s not found in the guidance document", id)
+		}
+	}
+	if len(plans) == 0 {
+		return nil, OutputExtractPolicyCandidates{}, fmt.Errorf("no guidelines found in the selected categories")
+	}
+
+	title := input.Title
+	if title == "" {
+		guidanceTitle, _ := guidance["title"].(string)
+		if guidanceTitle == "" {
+			guidanceTitle = guidanceID
+		}
+		title = "Policy adopting " + guidanceTitle
+	}
+	policy := yaml.MapSlice{
+		{Key: "metadata", Value: yaml.MapSlice{
+			{Key: "id", Value: policyID},
+			{Key: "description", Value: fmt.Sprintf("%s: describe the scope of this policy. Drafted from the guidelines of %s.", skeletonPlaceholder, guidanceID)},
+			{Key: "version", Value: "0.1.0"},
+			{Key: "author", Value: draftAuthor},
+		}},
+		{Key: "title", Value: title},
+		{Key: "imports", Value: yaml.MapSlice{
+			{Key: "catalogs", Value: []interface{}{
+				yaml.MapSlice{{Key: "reference-id", Value: guidanceID}},
+			}},
+		}},
+		{Key: "adherence", Value: yaml.MapSlice{
+			{Key: "assessment-plans", Value: plans},
+		}},
+	}
+
+	out, err := yaml.MarshalWithOptions(policy, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return nil, OutputExtractPolicyCandidates{}, fmt.Errorf("failed to encode policy: %w", err)
+	}
+	output.Content = string(out)
+
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputExtractPolicyCandidates{}, err
+	}
+	validation, err := validateAgainstSchema(schema, "#Policy", output.Content)
+	if err != nil {
+		return nil, OutputExtractPolicyCandidates{}, err
+	}
+	output.Valid = validation.Valid
+	output.Errors = validation.Errors
+
+	strengths := map[string]int{}
+	for _, c := range output.Candidates {
+		strengths[c.Strength]++
+	}
+	output.Message = fmt.Sprintf("Extracted %d candidate statement(s) from %s (%d must, %d should, %d may); %s; review each statement and complete the %d %s placeholder(s) before publishing",
+		len(output.Candidates), guidanceID, strengths[strengthMust], strengths[strengthShould], strengths[strengthMay],
+		strings.ToLower(validation.Message), len(output.Placeholders), skeletonPlaceholder)
+	return nil, output, nil
+}
+
+// policyCandidate proposes a policy statement for a guideline.
+func policyCandidate(guideline map[string]interface{}) PolicyCandidate {
+	id := entityID(guideline)
+	objective, _ := guideline["objective"].(string)
+	objective = strings.Join(strings.Fields(objective), " ")
+	if objective == "" {
+		objective, _ = guideline["title"].(string)
+	}
+
+	candidate := PolicyCandidate{
+		ID:        "AP-" + id,
+		Strength:  strengthMust,
+		Method:    "automated",
+		Guideline: id,
+		Objective: objective,
+	}
+	switch {
+	case normativeShould.MatchString(objective):
+		candidate.Strength = strengthShould
+	case normativeMay.MatchString(objective):
+		candidate.Strength = strengthMay
+	}
+	if regoManual.MatchString(objective) {
+		candidate.Method = "manual"
+	}
+
+	statement := strings.TrimSuffix(objective, ".")
+	if !normativeVerb.MatchString(statement) {
+		if trimmed := requirementPreamble.ReplaceAllString(statement, ""); trimmed != "" {
+			statement = trimmed
+		}
+		if imperativeObjective.MatchString(statement) {
+			statement = fmt.Sprintf("The organization %s %s", candidate.Strength, lowerFirst(statement))
+		} else {
+			statement = fmt.Sprintf("The organization %s ensure that %s", candidate.Strength, lowerFirst(statement))
+		}
+	}
+	candidate.Statement = statement + "."
+	return candidate
+}
@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testGuidance = `metadata:
+  id: ORG-GUIDE
+  description: Secure development guidance
+  author:
+    id: org-security
+    name: Org Security
+    type: Human
+title: Secure Development Guidance
+categories:
+  - id: AC
+    title: Access Control
+    description: Limit who can change production
+    guidelines:
+      - id: AC-01
+        title: Multi-factor authentication
+        objective: Require multi-factor authentication for all administrative access.
+      - id: AC-02
+        title: Access reviews
+        objective: Access rights should be reviewed and approved by system owners each quarter.
+  - id: DP
+    title: Data Protection
+    description: Protect data at rest
+    guidelines:
+      - id: DP-01
+        title: Encryption
+        objective: Teams may use customer-managed keys for encryption at rest.
+      - id: DP-02
+        title: Backups
+        objective: Ensure that backups are encrypted.
+`
+
+func TestExtractPolicyCandidates(t *testing.T) {
+	useTestSchema(t)
+
+	tests := []struct {
+		name             string
+		input            InputExtractPolicyCandidates
+		wantErr          bool
+		errContains      string
+		wantCandidates   []PolicyCandidate
+		wantPlaceholders int
+		wantContains     []string
+	}{
+		{
+			name:        "missing guidance",
+			wantErr:     true,
+			errContains: "artifact_content or artifact_uri is required",
+		},
+		{
+			name:        "not a guidance document",
+			input:       InputExtractPolicyCandidates{ArtifactContent: "metadata:\n  id: X\ncontrols: []\n"},
+			wantErr:     true,
+			errContains: "no categories",
+		},
+		{
+			name:        "unknown category",
+			input:       InputExtractPolicyCandidates{ArtifactContent: testGuidance, Categories: []string{"IR"}},
+			wantErr:     true,
+			errContains: "category IR not found",
+		},
+		{
+			name:  "whole document",
+			input: InputExtractPolicyCandidates{ArtifactContent: testGuidance},
+			wantCandidates: []PolicyCandidate{
+				{
+					ID:            "AP-AC-01",
+					Statement:     "The organization must require multi-factor authentication for all administrative access.",
+					Strength:      "must",
+					Method:        "automated",
+					Category:      "AC",
+					CategoryTitle: "Access Control",
+					Guideline:     "AC-01",
+					Objective:     "Require multi-factor authentication for all administrative access.",
+				},
+				{
+					ID:            "AP-AC-02",
+					Statement:     "Access rights should be reviewed and approved by system owners each quarter.",
+					Strength:      "should",
+					Method:        "manual",
+					Category:      "AC",
+					CategoryTitle: "Access Control",
+					Guideline:     "AC-02",
+					Objective:     "Access rights should be reviewed and approved by system owners each quarter.",
+				},
+				{
+					ID:            "AP-DP-01",
+					Statement:     "Teams may use customer-managed keys for encryption at rest.",
+					Strength:      "may",
+					Method:        "automated",
+					Category:      "DP",
+					CategoryTitle: "Data Protection",
+					Guideline:     "DP-01",
+					Objective:     "Teams may use customer-managed keys for encryption at rest.",
+				},
+				{
+					ID:            "AP-DP-02",
+					Statement:     "The organization must ensure that backups are encrypted.",
+					Strength:      "must",
+					Method:        "automated",
+					Category:      "DP",
+					CategoryTitle: "Data Protection",
+					Guideline:     "DP-02",
+					Objective:     "Ensure that backups are encrypted.",
+				},
+			},
+			wantPlaceholders: 2 + 4,
+			wantContains: []string{
+				"id: ORG-GUIDE-POLICY",
+				"title: Policy adopting Secure Development Guidance",
+				"reference-id: ORG-GUIDE",
+				"requirement-id: AC-02",
+				"type: manual",
+				"frequency: TODO",
+			},
+		},
+		{
+			name: "selected category with frequency",
+			input: InputExtractPolicyCandidates{
+				ArtifactContent: testGuidance,
+				PolicyID:        "ORG-DP",
+				Title:           "Data Protection Policy",
+				Categories:      []string{"DP"},
+				Frequency:       "quarterly",
+			},
+			wantPlaceholders: 2,
+			wantContains:     []string{"id: ORG-DP", "title: Data Protection Policy", "frequency: quarterly", "id: AP-DP-01"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := ExtractPolicyCandidates(context.Background(), nil, tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, output.Valid, "policy should validate: %v", output.Errors)
+			if tt.wantCandidates != nil {
+				assert.Equal(t, tt.wantCandidates, output.Candidates)
+			}
+			assert.Len(t, output.Placeholders, tt.wantPlaceholders)
+			for _, want := range tt.wantContains {
+				assert.Contains(t, output.Content, want)
+			}
+		})
+	}
+}
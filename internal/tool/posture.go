@@ -0,0 +1,265 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// PostureResourceURI is the URI of the workspace compliance posture summary.
+const PostureResourceURI = "gemara://posture"
+
+// MetadataPostureResource describes the compliance posture resource.
+var MetadataPostureResource = &mcp.Resource{
+	Name:  "posture",
+	URI:   PostureResourceURI,
+	Title: "Compliance Posture",
+	Description: "Compliance posture of the workspace aggregated from every evaluation log in it: per-catalog " +
+		"compliance percentage, failing controls, and when each was last evaluated. Recomputed when the files change.",
+	MIMEType: "application/json",
+}
+
+// Posture summarizes the evaluation logs in the workspace.
+type Posture struct {
+	Workspace      string           `json:"workspace,omitempty"`
+	EvaluationLogs int              `json:"evaluation_logs"`
+	Catalogs       []CatalogPosture `json:"catalogs"`
+	// Unreadable lists workspace files that could not be parsed.
+	Unreadable []string `json:"unreadable,omitempty"`
+	// SuppressedCatalogs counts the catalogs left out because they are
+	// evaluated by fewer logs than the minimum cohort of the export.
+	SuppressedCatalogs int `json:"suppressed_catalogs,omitempty"`
+	// Computed is when the summary was last recomputed.
+	Computed string `json:"computed"`
+}
+
+// CatalogPosture is the compliance posture against one catalog.
+type CatalogPosture struct {
+	Catalog           string `json:"catalog"`
+	ControlsEvaluated int    `json:"controls_evaluated"`
+	Passed            int    `json:"passed"`
+	Failed            int    `json:"failed"`
+	NeedsReview       int    `json:"needs_review"`
+	NotApplicable     int    `json:"not_applicable"`
+	// CompliancePercent is the share of applicable, evaluated controls that passed.
+	CompliancePercent float64          `json:"compliance_percent"`
+	FailingControls   []ControlPosture `json:"failing_controls"`
+	LastEvaluated     string           `json:"last_evaluated,omitempty"`
+
+	// sources is how many evaluation logs the latest results come from.
+	sources int
+}
+
+// ControlPosture is the latest result recorded for a control.
+type ControlPosture struct {
+	Control       string `json:"control"`
+	Result        string `json:"result"`
+	Source        string `json:"source"`
+	LastEvaluated string `json:"last_evaluated,omitempty"`
+
+	evaluated time.Time
+}
+
+// postureCache keeps the posture of a workspace until its artifacts change.
+type postureCache struct {
+	mu          sync.Mutex
+	fingerprint string
+	content     []byte
+}
+
+var workspacePosture = &postureCache{}
+
+// HandlePostureResource reads the workspace posture, recomputing it when any
+// workspace artifact was added, removed, or modified since the last read.
+func HandlePostureResource(ctx context.Context, _ *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	root := workspaceRoot(ctx)
+	if root == "" {
+		return nil, fmt.Errorf("posture requires a workspace root")
+	}
+	files, err := findArtifactFiles(root)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint, err := fileFingerprint(files)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := workspacePosture
+	if t := requestTenant(ctx); t != nil {
+		cache = t.posture
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.content == nil || fingerprint != cache.fingerprint {
+		// Noise is drawn once per change so repeated reads cannot average it out
+		posture := computePosture(ctx, root, files)
+		if privacy := serverConfig(ctx).Privacy; privacy.enabled() {
+			posture = privacy.posture(posture)
+		}
+		content, err := json.Marshal(posture)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal posture: %w", err)
+		}
+		cache.fingerprint, cache.content = fingerprint, content
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      PostureResourceURI,
+				MIMEType: "application/json",
+				Text:     string(cache.content),
+			},
+		},
+	}, nil
+}
+
+// fileFingerprint identifies the state of a set of files by their names,
+// sizes, and modification times.
+func fileFingerprint(files []string) (string, error) {
+	h := sha256.New()
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", file, info.Size(), info.ModTime().UnixNano())
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// computePosture aggregates the latest result of every control evaluated in the
+// evaluation logs among files.
+func computePosture(ctx context.Context, root string, files []string) Posture {
+	posture := Posture{Workspace: root, Catalogs: []CatalogPosture{}, Computed: time.Now().UTC().Format(time.RFC3339)}
+	for catalog, controls := range latestControlResults(ctx, root, files, &posture) {
+		posture.Catalogs = append(posture.Catalogs, catalogPosture(catalog, controls))
+	}
+	sort.Slice(posture.Catalogs, func(i, j int) bool { return posture.Catalogs[i].Catalog < posture.Catalogs[j].Catalog })
+	return posture
+}
+
+// catalogPosture summarizes the latest results of the controls of a catalog.
+func catalogPosture(catalog string, controls map[string]ControlPosture) CatalogPosture {
+	c := CatalogPosture{Catalog: catalog, ControlsEvaluated: len(controls), FailingControls: []ControlPosture{}}
+	var lastEvaluated time.Time
+	sources := map[string]bool{}
+	for _, control := range controls {
+		sources[control.Source] = true
+		if !control.evaluated.IsZero() {
+			control.LastEvaluated = control.evaluated.Format(time.RFC3339)
+			if control.evaluated.After(lastEvaluated) {
+				lastEvaluated = control.evaluated
+			}
+		}
+		switch control.Result {
+		case "Passed":
+			c.Passed++
+		case "Failed":
+			c.Failed++
+			c.FailingControls = append(c.FailingControls, control)
+		case "Needs Review":
+			c.NeedsReview++
+		case "Not Applicable":
+			c.NotApplicable++
+		}
+	}
+	if applicable := c.ControlsEvaluated - c.NotApplicable; applicable > 0 {
+		c.CompliancePercent = math.Round(float64(c.Passed)/float64(applicable)*1000) / 10
+	}
+	if !lastEvaluated.IsZero() {
+		c.LastEvaluated = lastEvaluated.Format(time.RFC3339)
+	}
+	c.sources = len(sources)
+	sort.Slice(c.FailingControls, func(i, j int) bool { return c.FailingControls[i].Control < c.FailingControls[j].Control })
+	return c
+}
+
+// latestControlResults returns the latest result of every control evaluated in
+// the evaluation logs among files, by catalog and control, counting the logs
+// and unreadable files in posture. When several logs evaluate a control, the
+// most recent evaluation wins.
+func latestControlResults(ctx context.Context, root string, files []string, posture *Posture) map[string]map[string]ControlPosture {
+	latest := map[string]map[string]ControlPosture{}
+	for _, file := range files {
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			rel = file
+		}
+		content, err := readArtifactFile(ctx, file)
+		if err != nil {
+			posture.Unreadable = append(posture.Unreadable, rel)
+			continue
+		}
+		doc, err := parseArtifact(ctx, string(content))
+		if err != nil {
+			posture.Unreadable = append(posture.Unreadable, rel)
+			continue
+		}
+		if artifactKind(doc) != "EvaluationLog" {
+			continue
+		}
+		posture.EvaluationLogs++
+
+		// Evaluations without timestamps are dated by the file itself
+		modified := time.Time{}
+		if info, err := os.Stat(file); err == nil {
+			modified = info.ModTime().UTC()
+		}
+		evaluations, _ := doc["evaluations"].([]interface{})
+		for _, e := range evaluations {
+			evaluation, ok := e.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			mapping, _ := evaluation["control"].(map[string]interface{})
+			catalog, _ := mapping["reference-id"].(string)
+			control := mappingEntryID(mapping)
+			result, _ := evaluation["result"].(string)
+			if catalog == "" || control == "" || result == "" {
+				continue
+			}
+			evaluated := evaluationTime(evaluation)
+			if evaluated.IsZero() {
+				evaluated = modified
+			}
+
+			if latest[catalog] == nil {
+				latest[catalog] = map[string]ControlPosture{}
+			}
+			if previous, ok := latest[catalog][control]; ok && previous.evaluated.After(evaluated) {
+				continue
+			}
+			latest[catalog][control] = ControlPosture{Control: control, Result: result, Source: rel, evaluated: evaluated}
+		}
+	}
+	return latest
+}
+
+// evaluationTime returns the latest time recorded on an evaluation or its
+// assessment logs.
+func evaluationTime(evaluation map[string]interface{}) time.Time {
+	latest, _ := findingTime(evaluation)
+	logs, _ := evaluation["assessment-logs"].([]interface{})
+	for _, l := range logs {
+		if assessment, ok := l.(map[string]interface{}); ok {
+			if t, ok := findingTime(assessment); ok && t.After(latest) {
+				latest = t
+			}
+		}
+	}
+	return latest
+}
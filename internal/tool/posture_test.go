@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const postureOldLog = `metadata:
+  id: EVAL-OLD
+evaluations:
+  - name: Encryption
+    result: Failed
+    end: 2025-01-01T00:00:00Z
+    control:
+      reference-id: FINOS-CCC
+      entry-id: CCC.C01
+    assessment-logs: []
+`
+
+const postureNewLog = `metadata:
+  id: EVAL-NEW
+evaluations:
+  - name: Encryption
+    result: Passed
+    control:
+      reference-id: FINOS-CCC
+      entry-id: CCC.C01
+    assessment-logs:
+      - requirement:
+          reference-id: FINOS-CCC
+          entry-id: CCC.C01.TR01
+        description: TLS enforced
+        result: Passed
+        end: 2025-02-01T00:00:00Z
+  - name: Regions
+    result: Failed
+    end: 2025-02-01T00:00:00Z
+    control:
+      reference-id: FINOS-CCC
+      entry-id: CCC.C06
+    assessment-logs: []
+  - name: Replication
+    result: Not Applicable
+    end: 2025-02-01T00:00:00Z
+    control:
+      reference-id: FINOS-CCC
+      entry-id: CCC.C08
+    assessment-logs: []
+  - name: Access Reviews
+    result: Passed
+    end: 2025-01-15T00:00:00Z
+    control:
+      reference-id: ORG-POL
+      entry-id: ORG.C01
+    assessment-logs: []
+`
+
+// readPosture reads and decodes the posture resource.
+func readPosture(t *testing.T, ctx context.Context) Posture {
+	t.Helper()
+	result, err := HandlePostureResource(ctx, &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: PostureResourceURI}})
+	require.NoError(t, err)
+	require.Len(t, result.Contents, 1)
+	assert.Equal(t, PostureResourceURI, result.Contents[0].URI)
+	var posture Posture
+	require.NoError(t, json.Unmarshal([]byte(result.Contents[0].Text), &posture))
+	return posture
+}
+
+func TestHandlePostureResource(t *testing.T) {
+	dir := t.TempDir()
+	ctx := withTestConfig(func(c *Config) { c.WorkspaceRoot = dir })
+
+	catalog, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err, "should read test catalog")
+	writeTestFile(t, dir, "catalogs/ccc.yaml", string(catalog))
+	writeTestFile(t, dir, "logs/2025-01.yaml", postureOldLog)
+	writeTestFile(t, dir, "logs/2025-02.yaml", postureNewLog)
+	writeTestFile(t, dir, "broken.yaml", "evaluations: [")
+
+	posture := readPosture(t, ctx)
+	assert.Equal(t, 2, posture.EvaluationLogs)
+	assert.Equal(t, []string{"broken.yaml"}, posture.Unreadable)
+	require.Len(t, posture.Catalogs, 2)
+
+	ccc := posture.Catalogs[0]
+	assert.Equal(t, "FINOS-CCC", ccc.Catalog)
+	assert.Equal(t, 3, ccc.ControlsEvaluated)
+	assert.Equal(t, 1, ccc.Passed, "the newer passing evaluation of CCC.C01 should win")
+	assert.Equal(t, 1, ccc.Failed)
+	assert.Equal(t, 1, ccc.NotApplicable)
+	assert.Equal(t, 50.0, ccc.CompliancePercent)
+	assert.Equal(t, "2025-02-01T00:00:00Z", ccc.LastEvaluated)
+	require.Len(t, ccc.FailingControls, 1)
+	assert.Equal(t, ControlPosture{Control: "CCC.C06", Result: "Failed", Source: filepath.Join("logs", "2025-02.yaml"), LastEvaluated: "2025-02-01T00:00:00Z"}, ccc.FailingControls[0])
+
+	org := posture.Catalogs[1]
+	assert.Equal(t, "ORG-POL", org.Catalog)
+	assert.Equal(t, 100.0, org.CompliancePercent)
+	assert.Empty(t, org.FailingControls)
+
+	// Unchanged files are served from the cached summary
+	assert.Equal(t, posture.Computed, readPosture(t, ctx).Computed)
+
+	// Changing a log recomputes the summary
+	require.NoError(t, os.Remove(filepath.Join(dir, "logs", "2025-02.yaml")))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "logs", "2025-01.yaml"), later, later))
+	posture = readPosture(t, ctx)
+	assert.Equal(t, 1, posture.EvaluationLogs)
+	require.Len(t, posture.Catalogs, 1)
+	assert.Equal(t, 0.0, posture.Catalogs[0].CompliancePercent)
+	assert.Equal(t, "2025-01-01T00:00:00Z", posture.Catalogs[0].LastEvaluated)
+}
+
+func TestHandlePostureResourceWithoutWorkspaceRoot(t *testing.T) {
+	_, err := HandlePostureResource(context.Background(), &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: PostureResourceURI}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "workspace root")
+}
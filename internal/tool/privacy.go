@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+)
+
+// PrivacyConfig limits what the aggregate posture, metrics, and posture
+// webhooks reveal about individual projects, so that the exports of one team
+// can be shared across an organization. When either limit is set, the exports
+// carry per-catalog counts only: failing controls, per-control metrics, and
+// the workspace path are left out.
+type PrivacyConfig struct {
+	// MinCohort is the fewest evaluation logs the results of a catalog must
+	// come from for the catalog to be exported; catalogs evaluated by fewer
+	// logs are suppressed. Zero exports every catalog.
+	MinCohort int
+	// Epsilon is the privacy budget of the Laplace noise added to exported
+	// counts; smaller values add more noise. Zero adds no noise.
+	Epsilon float64
+}
+
+// Validate reports whether the privacy configuration is usable.
+func (c PrivacyConfig) Validate() error {
+	if c.MinCohort < 0 {
+		return fmt.Errorf("export-min-cohort must not be negative")
+	}
+	if c.Epsilon < 0 || math.IsNaN(c.Epsilon) || math.IsInf(c.Epsilon, 0) {
+		return fmt.Errorf("export-epsilon must be a non-negative number")
+	}
+	return nil
+}
+
+// enabled reports whether exports are aggregated for sharing.
+func (c PrivacyConfig) enabled() bool {
+	return c.MinCohort > 0 || c.Epsilon > 0
+}
+
+// noiseSource returns uniform samples in [0, 1) for the Laplace noise.
+var noiseSource = rand.Float64
+
+// noisyCount adds Laplace noise of scale 1/Epsilon to n, rounded to a
+// non-negative count.
+func (c PrivacyConfig) noisyCount(n int) int {
+	if c.Epsilon <= 0 {
+		return n
+	}
+	u := noiseSource() - 0.5
+	noise := -math.Copysign(1/c.Epsilon, u) * math.Log(1-2*math.Abs(u))
+	return max(0, int(math.Round(float64(n)+noise)))
+}
+
+// catalog returns the exported posture of a catalog, or false when the
+// catalog is evaluated by too few logs to be exported.
+func (c PrivacyConfig) catalog(p CatalogPosture) (CatalogPosture, bool) {
+	if p.sources < c.MinCohort {
+		return CatalogPosture{}, false
+	}
+	p.Passed = c.noisyCount(p.Passed)
+	p.Failed = c.noisyCount(p.Failed)
+	p.NeedsReview = c.noisyCount(p.NeedsReview)
+	p.NotApplicable = c.noisyCount(p.NotApplicable)
+	p.ControlsEvaluated = max(c.noisyCount(p.ControlsEvaluated), p.Passed+p.Failed+p.NeedsReview+p.NotApplicable)
+	p.CompliancePercent = 0
+	if applicable := p.ControlsEvaluated - p.NotApplicable; applicable > 0 {
+		p.CompliancePercent = math.Round(float64(p.Passed)/float64(applicable)*1000) / 10
+	}
+	p.FailingControls = []ControlPosture{}
+	return p, true
+}
+
+// posture returns the exported form of a workspace posture, which leaves out
+// the workspace path and the names of unreadable files.
+func (c PrivacyConfig) posture(p Posture) Posture {
+	p.Workspace = ""
+	p.Unreadable = nil
+	catalogs := []CatalogPosture{}
+	for _, catalog := range p.Catalogs {
+		if exported, ok := c.catalog(catalog); ok {
+			catalogs = append(catalogs, exported)
+		} else {
+			p.SuppressedCatalogs++
+		}
+	}
+	p.Catalogs = catalogs
+	p.EvaluationLogs = c.noisyCount(p.EvaluationLogs)
+	return p
+}
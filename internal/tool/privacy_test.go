@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// privacyLog evaluates FINOS-CCC a second time, so its cohort is two logs
+// while ORG-POL is evaluated by one.
+const privacyLog = `metadata:
+  id: EVAL-OTHER
+evaluations:
+  - name: Logging
+    result: Failed
+    end: 2025-02-01T00:00:00Z
+    control:
+      reference-id: FINOS-CCC
+      entry-id: CCC.C10
+    assessment-logs: []
+`
+
+// useNoise makes the Laplace noise draw u from the test.
+func useNoise(t *testing.T, u float64) {
+	t.Helper()
+	original := noiseSource
+	t.Cleanup(func() { noiseSource = original })
+	noiseSource = func() float64 { return u }
+}
+
+func TestPrivacyConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  PrivacyConfig
+		wantErr string
+	}{
+		{name: "disabled", config: PrivacyConfig{}},
+		{name: "cohort and noise", config: PrivacyConfig{MinCohort: 5, Epsilon: 0.5}},
+		{name: "negative cohort", config: PrivacyConfig{MinCohort: -1}, wantErr: "export-min-cohort"},
+		{name: "negative epsilon", config: PrivacyConfig{Epsilon: -1}, wantErr: "export-epsilon"},
+		{name: "infinite epsilon", config: PrivacyConfig{Epsilon: math.Inf(1)}, wantErr: "export-epsilon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNoisyCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		epsilon float64
+		u       float64
+		n       int
+		want    int
+	}{
+		{name: "no noise", epsilon: 0, u: 0.99, n: 3, want: 3},
+		{name: "positive noise", epsilon: 1, u: 0.75, n: 3, want: 4},
+		{name: "negative noise", epsilon: 1, u: 0.25, n: 3, want: 2},
+		{name: "smaller epsilon adds more noise", epsilon: 0.1, u: 0.75, n: 3, want: 10},
+		{name: "never negative", epsilon: 1, u: 0.25, n: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useNoise(t, tt.u)
+			assert.Equal(t, tt.want, PrivacyConfig{Epsilon: tt.epsilon}.noisyCount(tt.n))
+		})
+	}
+}
+
+func TestHandlePostureResourcePrivacy(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "logs/2025-01.yaml", postureOldLog)
+	writeTestFile(t, dir, "logs/2025-02.yaml", postureNewLog)
+	writeTestFile(t, dir, "logs/other.yaml", privacyLog)
+	useNoise(t, 0.75)
+
+	ctx := withTestConfig(func(c *Config) {
+		c.WorkspaceRoot = dir
+		c.Privacy = PrivacyConfig{MinCohort: 2}
+	})
+	posture := readPosture(t, ctx)
+	assert.Equal(t, 1, posture.SuppressedCatalogs, "ORG-POL is evaluated by a single log")
+	require.Len(t, posture.Catalogs, 1)
+	ccc := posture.Catalogs[0]
+	assert.Equal(t, "FINOS-CCC", ccc.Catalog)
+	assert.Equal(t, 4, ccc.ControlsEvaluated)
+	assert.Equal(t, 2, ccc.Failed)
+	assert.Empty(t, ccc.FailingControls, "exports should not name failing controls")
+	assert.Equal(t, 3, posture.EvaluationLogs)
+
+	// Noise is added to every count and the percentage follows the noisy counts
+	noisy := t.TempDir()
+	writeTestFile(t, noisy, "logs/2025-01.yaml", postureOldLog)
+	writeTestFile(t, noisy, "logs/2025-02.yaml", postureNewLog)
+	writeTestFile(t, noisy, "logs/other.yaml", privacyLog)
+	ctx = withTestConfig(func(c *Config) {
+		c.WorkspaceRoot = noisy
+		c.Privacy = PrivacyConfig{Epsilon: 1}
+	})
+	posture = readPosture(t, ctx)
+	require.Len(t, posture.Catalogs, 2, "no catalog is suppressed without a cohort")
+	ccc = posture.Catalogs[0]
+	assert.Equal(t, 2, ccc.Passed)
+	assert.Equal(t, 3, ccc.Failed)
+	assert.Equal(t, 1, ccc.NeedsReview)
+	assert.Equal(t, 2, ccc.NotApplicable)
+	assert.Equal(t, 8, ccc.ControlsEvaluated, "controls evaluated should cover every noisy result")
+	assert.Equal(t, 33.3, ccc.CompliancePercent)
+	assert.Equal(t, 4, posture.EvaluationLogs)
+
+	raw, err := json.Marshal(posture)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "CCC.C06", "no control should be named")
+	assert.NotContains(t, string(raw), noisy, "the workspace path should not be exported")
+}
+
+func TestMetricsHandlerPrivacy(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "logs/2025-01.yaml", postureOldLog)
+	writeTestFile(t, dir, "logs/2025-02.yaml", postureNewLog)
+	writeTestFile(t, dir, "logs/other.yaml", privacyLog)
+	useNoise(t, 0.5)
+	config := NewConfig()
+	config.WorkspaceRoot = dir
+	config.Privacy = PrivacyConfig{MinCohort: 2, Epsilon: 1}
+
+	body := scrapeMetrics(t, config, "").Body.String()
+	assert.Contains(t, body, `gemara_catalog_compliance_ratio{catalog="FINOS-CCC"} 0.3333333333333333`+"\n")
+	assert.NotContains(t, body, `catalog="ORG-POL"`, "catalogs below the cohort should be suppressed")
+	assert.Contains(t, body, "gemara_suppressed_catalogs 1\n")
+	assert.Contains(t, body, "gemara_evaluation_logs 3\n")
+	assert.NotContains(t, body, "gemara_control_result", "per-control metrics should not be exported")
+	assert.NotContains(t, body, "gemara_control_last_evaluated_timestamp_seconds")
+	assert.NotContains(t, body, "gemara_findings_overdue", "findings name catalogs below the cohort")
+}
@@ -0,0 +1,208 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// fieldsProperty is the input schema of the fields parameter of tools that
+// return artifact content.
+var fieldsProperty = map[string]interface{}{
+	"type":  "array",
+	"items": map[string]interface{}{"type": "string"},
+	"description": "Return only these parts of the artifact, as YAML paths where [*] selects every list element and " +
+		"[?key=value] or [?key!=value] only the matching ones, such as $.controls[*].id, $.controls[*].title, or " +
+		"$.evaluations[?result=Failed]. The structure around selected fields is kept (default: the whole artifact)",
+}
+
+// projectionSegment is one step of a projection path.
+type projectionSegment struct {
+	key     string
+	isIndex bool
+	// A list selector takes every element, the matching ones, or one by index.
+	wildcard bool
+	filter   *projectionFilter
+	index    int
+}
+
+// projectionFilter keeps the list elements whose key equals, or does not
+// equal, value.
+type projectionFilter struct {
+	key    string
+	value  string
+	negate bool
+}
+
+func (f *projectionFilter) matches(element interface{}) bool {
+	m, _ := element.(yaml.MapSlice)
+	for _, item := range m {
+		if fmt.Sprint(item.Key) == f.key {
+			return (fmt.Sprint(item.Value) == f.value) != f.negate
+		}
+	}
+	return f.negate
+}
+
+// parseProjectionPath splits a projection path into its segments. It extends
+// the artifact path syntax with [*] and [?key=value] list selectors.
+func parseProjectionPath(path string) ([]projectionSegment, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	var segments []projectionSegment
+	for rest != "" {
+		switch {
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid field %q: unclosed [", path)
+			}
+			selector := rest[1:end]
+			rest = rest[end+1:]
+			switch {
+			case selector == "*":
+				segments = append(segments, projectionSegment{isIndex: true, wildcard: true})
+			case strings.HasPrefix(selector, "?"):
+				filter, err := parseProjectionFilter(strings.TrimPrefix(selector, "?"))
+				if err != nil {
+					return nil, fmt.Errorf("invalid field %q: %w", path, err)
+				}
+				segments = append(segments, projectionSegment{isIndex: true, filter: filter})
+			default:
+				index, err := strconv.Atoi(selector)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid field %q: bad index %q", path, selector)
+				}
+				segments = append(segments, projectionSegment{isIndex: true, index: index})
+			}
+		case rest[0] == '.' || len(segments) == 0:
+			rest = strings.TrimPrefix(rest, ".")
+			var key string
+			if strings.HasPrefix(rest, "'") {
+				end := strings.IndexByte(rest[1:], '\'')
+				if end < 0 {
+					return nil, fmt.Errorf("invalid field %q: unclosed quote", path)
+				}
+				key, rest = rest[1:end+1], rest[end+2:]
+			} else {
+				end := strings.IndexAny(rest, ".[")
+				if end < 0 {
+					end = len(rest)
+				}
+				key, rest = rest[:end], rest[end:]
+			}
+			if key == "" {
+				return nil, fmt.Errorf("invalid field %q: empty key", path)
+			}
+			segments = append(segments, projectionSegment{key: key})
+		default:
+			return nil, fmt.Errorf("invalid field %q: unexpected %q", path, rest[:1])
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("invalid field %q: select at least one key", path)
+	}
+	return segments, nil
+}
+
+func parseProjectionFilter(expr string) (*projectionFilter, error) {
+	negate := false
+	i := strings.Index(expr, "!=")
+	if i >= 0 {
+		negate = true
+	} else {
+		i = strings.Index(expr, "=")
+	}
+	if i <= 0 {
+		return nil, fmt.Errorf("filter %q must be key=value or key!=value", expr)
+	}
+	op := 1
+	if negate {
+		op = 2
+	}
+	value := strings.Trim(strings.TrimSpace(expr[i+op:]), `'"`)
+	return &projectionFilter{key: strings.TrimSpace(expr[:i]), value: value, negate: negate}, nil
+}
+
+// projectArtifact returns content reduced to the parts selected by fields,
+// keeping the order of mapping keys and list elements.
+func projectArtifact(content string, fields []string) (string, error) {
+	paths := make([][]projectionSegment, 0, len(fields))
+	for _, f := range fields {
+		segments, err := parseProjectionPath(f)
+		if err != nil {
+			return "", err
+		}
+		paths = append(paths, segments)
+	}
+	var tree interface{}
+	if err := yaml.UnmarshalWithOptions([]byte(content), &tree, yaml.UseOrderedMap()); err != nil {
+		return "", fmt.Errorf("failed to parse artifact: %w", err)
+	}
+	projected, ok := projectNode(tree, paths)
+	if !ok {
+		return "", nil
+	}
+	out, err := yaml.MarshalWithOptions(projected, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return "", fmt.Errorf("failed to encode projection: %w", err)
+	}
+	return string(out), nil
+}
+
+// projectNode returns the parts of node selected by paths, and whether any
+// part was selected. A path that ends at a node selects all of it.
+func projectNode(node interface{}, paths [][]projectionSegment) (interface{}, bool) {
+	for _, p := range paths {
+		if len(p) == 0 {
+			return node, true
+		}
+	}
+
+	switch node := node.(type) {
+	case yaml.MapSlice:
+		var projected yaml.MapSlice
+		for _, item := range node {
+			var rest [][]projectionSegment
+			for _, p := range paths {
+				if !p[0].isIndex && p[0].key == fmt.Sprint(item.Key) {
+					rest = append(rest, p[1:])
+				}
+			}
+			if len(rest) == 0 {
+				continue
+			}
+			if value, ok := projectNode(item.Value, rest); ok {
+				projected = append(projected, yaml.MapItem{Key: item.Key, Value: value})
+			}
+		}
+		return projected, len(projected) > 0
+	case []interface{}:
+		projected := []interface{}{}
+		selected := false
+		for i, element := range node {
+			var rest [][]projectionSegment
+			for _, p := range paths {
+				seg := p[0]
+				if !seg.isIndex {
+					continue
+				}
+				if seg.wildcard || (seg.filter != nil && seg.filter.matches(element)) || (seg.filter == nil && seg.index == i) {
+					rest = append(rest, p[1:])
+				}
+			}
+			if len(rest) == 0 {
+				continue
+			}
+			selected = true
+			if value, ok := projectNode(element, rest); ok {
+				projected = append(projected, value)
+			}
+		}
+		return projected, selected
+	}
+	return nil, false
+}
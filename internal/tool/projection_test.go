@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectArtifact(t *testing.T) {
+	log, err := os.ReadFile(filepath.Join("test-data", "evaluation-log.yaml"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		content     string
+		fields      []string
+		want        string
+		errContains string
+	}{
+		{
+			name:    "ids and titles",
+			content: stagedCatalog,
+			fields:  []string{"$.controls[*].id", "$.title", "controls[*].title"},
+			want:    "title: Draft Catalog\ncontrols:\n  - id: DRAFT.C01\n    title: First\n",
+		},
+		{
+			name:    "filtered results",
+			content: string(log),
+			fields:  []string{"$.evaluations[?result=Failed].control", "$.evaluations[?result=Failed].result"},
+			want:    "evaluations:\n  - result: Failed\n    control:\n      reference-id: FINOS-CCC\n      entry-id: CCC.C06\n",
+		},
+		{
+			name:    "negated filter and index",
+			content: string(log),
+			fields:  []string{"$.evaluations[?result!=Failed].name", "$.metadata.author.id"},
+			want:    "metadata:\n  author:\n    id: scanner\nevaluations:\n  - name: Prevent Unencrypted Requests\n",
+		},
+		{
+			name:    "quoted keys and indexes",
+			content: string(log),
+			fields:  []string{"$.evaluations[1].'assessment-logs'[0].description"},
+			want:    "evaluations:\n  - assessment-logs:\n      - description: Bucket found in restricted region\n",
+		},
+		{
+			name:    "nothing selected",
+			content: stagedCatalog,
+			fields:  []string{"$.families[*].id"},
+			want:    "",
+		},
+		{
+			name:        "bad filter",
+			content:     stagedCatalog,
+			fields:      []string{"$.controls[?id]"},
+			errContains: "must be key=value",
+		},
+		{
+			name:        "empty path",
+			content:     stagedCatalog,
+			fields:      []string{"$"},
+			errContains: "select at least one key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := projectArtifact(tt.content, tt.fields)
+			if tt.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetStagedArtifactFields(t *testing.T) {
+	useStaging(t)
+	ctx := context.Background()
+	_, _, err := StageArtifact(ctx, nil, InputStageArtifact{Name: "catalog", ArtifactContent: stagedCatalog})
+	require.NoError(t, err)
+
+	_, output, err := GetStagedArtifact(ctx, nil, InputGetStagedArtifact{Name: "catalog", Fields: []string{"$.controls[*].id"}})
+	require.NoError(t, err)
+	assert.Equal(t, "controls:\n  - id: DRAFT.C01\n", output.Content)
+	assert.Equal(t, len(stagedCatalog), output.Size, "size should describe the whole draft")
+}
@@ -0,0 +1,209 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// wizardStep is one question an artifact wizard asks. Its answer may be
+// given up front as the prompt argument of the same name.
+type wizardStep struct {
+	argument    string
+	title       string
+	description string
+	question    string
+}
+
+// artifactWizard is a prompt that walks the user through drafting an
+// artifact of one definition, one step at a time.
+type artifactWizard struct {
+	name        string
+	title       string
+	description string
+	definition  string
+	steps       []wizardStep
+	// guidance holds definition-specific advice for assembling the artifact.
+	guidance string
+}
+
+var catalogWizard = artifactWizard{
+	name:  "catalog_wizard",
+	title: "Control Catalog Wizard",
+	description: "Draft a Gemara ControlCatalog step by step: collect its metadata, control families, controls, " +
+		"and assessment requirements one question at a time, then assemble and validate the catalog.",
+	definition: "#ControlCatalog",
+	steps: []wizardStep{
+		{
+			argument:    "catalog_id",
+			title:       "Catalog ID",
+			description: "Metadata id of the catalog, also used as the prefix of its control IDs (e.g., ACME-CLOUD)",
+			question:    "What id should the catalog have? It identifies the catalog in policies and evaluations, and its initials prefix the control IDs (for example ACME-CLOUD gives AC.C01).",
+		},
+		{
+			argument:    "title",
+			title:       "Title",
+			description: "Title of the catalog",
+			question:    "What is the title of the catalog, and in a sentence or two, what does it protect?",
+		},
+		{
+			argument:    "author",
+			title:       "Author",
+			description: "Person, team, or tool that maintains the catalog",
+			question:    "Who maintains the catalog? Give a name and whether it is a person or team (Human) or a tool (Software).",
+		},
+		{
+			argument:    "families",
+			title:       "Control families",
+			description: "Comma-separated control families that group the controls (e.g., Access Control, Data Protection)",
+			question:    "Which families should group the controls? Families are broad areas such as Access Control or Data Protection; two to five is typical.",
+		},
+		{
+			argument:    "controls",
+			title:       "Controls",
+			description: "The controls of each family, as a title and the objective they achieve",
+			question:    "For each family, which controls should the catalog have? Give each a short title and the objective it achieves.",
+		},
+		{
+			argument:    "requirements",
+			title:       "Assessment requirements",
+			description: "How each control is assessed, as verifiable statements",
+			question:    "How should each control be assessed? Give one or more verifiable requirements per control, such as \"Storage buckets MUST reject unencrypted requests\".",
+		},
+	},
+	guidance: "Assign control and requirement IDs with allocate_control_ids rather than by hand. " +
+		"Write requirements with MUST, SHOULD, or MAY so that they can be evaluated.",
+}
+
+var policyWizard = artifactWizard{
+	name:  "policy_wizard",
+	title: "Policy Wizard",
+	description: "Draft a Gemara Policy step by step: collect its metadata, the catalogs it imports, the controls " +
+		"in scope, and how often and by what method each is assessed, then assemble and validate the policy.",
+	definition: "#Policy",
+	steps: []wizardStep{
+		{
+			argument:    "policy_id",
+			title:       "Policy ID",
+			description: "Metadata id of the policy (e.g., ACME-CLOUD-POL)",
+			question:    "What id should the policy have? Evaluation logs reference their assessment plans by it.",
+		},
+		{
+			argument:    "title",
+			title:       "Title",
+			description: "Title of the policy",
+			question:    "What is the title of the policy, and which part of the organization does it apply to?",
+		},
+		{
+			argument:    "author",
+			title:       "Author",
+			description: "Person or team that owns the policy",
+			question:    "Who owns the policy? Give a name and whether it is a person or team (Human) or a tool (Software).",
+		},
+		{
+			argument:    "catalogs",
+			title:       "Imported catalogs",
+			description: "Comma-separated ids, file:// URIs, or https:// URLs of the control catalogs the policy adopts",
+			question:    "Which control catalogs does the policy adopt? Give their ids, or where to read them (a workspace file or a URL).",
+		},
+		{
+			argument:    "controls",
+			title:       "Controls in scope",
+			description: "Comma-separated controls of the imported catalogs that the policy requires, or all",
+			question:    "Which controls of those catalogs are in scope? Answer all, or list the controls to adopt.",
+		},
+		{
+			argument:    "frequency",
+			title:       "Assessment frequency and method",
+			description: "How often the requirements are assessed and whether automatically or manually",
+			question:    "How often should the requirements be assessed (for example daily or quarterly), and is each assessment automated or manual?",
+		},
+	},
+	guidance: "Read the imported catalogs with artifact_uri before drafting, and add one assessment plan per " +
+		"assessment requirement of the controls in scope, citing it by requirement-id.",
+}
+
+// MetadataCatalogWizardPrompt describes the catalog wizard prompt.
+var MetadataCatalogWizardPrompt = catalogWizard.prompt()
+
+// MetadataPolicyWizardPrompt describes the policy wizard prompt.
+var MetadataPolicyWizardPrompt = policyWizard.prompt()
+
+// HandleCatalogWizardPrompt starts or resumes the catalog wizard.
+func HandleCatalogWizardPrompt(_ context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	return catalogWizard.messages(req.Params.Arguments), nil
+}
+
+// HandlePolicyWizardPrompt starts or resumes the policy wizard.
+func HandlePolicyWizardPrompt(_ context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	return policyWizard.messages(req.Params.Arguments), nil
+}
+
+// prompt returns the prompt metadata, with one optional argument per step.
+func (w artifactWizard) prompt() *mcp.Prompt {
+	arguments := make([]*mcp.PromptArgument, 0, len(w.steps))
+	for _, s := range w.steps {
+		arguments = append(arguments, &mcp.PromptArgument{Name: s.argument, Title: s.title, Description: s.description})
+	}
+	return &mcp.Prompt{
+		Name:        w.name,
+		Title:       w.title,
+		Description: w.description + " Arguments answer their step up front; the wizard asks for the rest.",
+		Arguments:   arguments,
+	}
+}
+
+// messages opens the conversation with the wizard's plan, replays the steps
+// answered by arguments as turns, and asks the first open question. When
+// every step is answered the artifact is assembled straight away.
+func (w artifactWizard) messages(arguments map[string]string) *mcp.GetPromptResult {
+	name := strings.TrimPrefix(w.definition, "#")
+	var b strings.Builder
+	fmt.Fprintf(&b, "Help me draft a Gemara %s. I am not familiar with the format, so guide me through it one step "+
+		"at a time: ask a single question per turn, say briefly why it matters, suggest a sensible answer when I am "+
+		"unsure, and wait for my reply before moving on.\n\nThe steps are:\n", name)
+	for i, s := range w.steps {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, s.title)
+	}
+	fmt.Fprintf(&b, "\nWhen every step is answered, read %s%s and %s%s, assemble the %s as YAML, and validate it "+
+		"with validate_gemara_artifact using definition %s. Fix any errors and validate again until it passes, "+
+		"then show me the final artifact. %s", schemaResourcePrefix, name, examplesResourcePrefix, name, name,
+		w.definition, w.guidance)
+
+	result := &mcp.GetPromptResult{
+		Description: w.description,
+		Messages:    []*mcp.PromptMessage{promptMessage("user", b.String())},
+	}
+	var open *wizardStep
+	for i, s := range w.steps {
+		answer := strings.TrimSpace(arguments[s.argument])
+		if answer == "" {
+			if open == nil {
+				open = &w.steps[i]
+			}
+			continue
+		}
+		result.Messages = append(result.Messages, promptMessage("assistant", s.question), promptMessage("user", answer))
+	}
+	if open != nil {
+		result.Messages = append(result.Messages, promptMessage("assistant", open.question))
+		return result
+	}
+	result.Messages = append(result.Messages, promptMessage("user",
+		fmt.Sprintf("That is everything. Assemble the %s and validate it now.", name)))
+	return result
+}
+
+// promptMessage returns a text message of a prompt.
+func promptMessage(role mcp.Role, text string) *mcp.PromptMessage {
+	return &mcp.PromptMessage{Role: role, Content: &mcp.TextContent{Text: text}}
+}
+
+// wizardPrompts returns the metadata of the artifact wizard prompts.
+func wizardPrompts() []*mcp.Prompt {
+	return []*mcp.Prompt{MetadataCatalogWizardPrompt, MetadataPolicyWizardPrompt}
+}
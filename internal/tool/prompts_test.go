@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWizardPrompts(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   mcp.PromptHandler
+		arguments map[string]string
+		wantRoles []mcp.Role
+		wantLast  string
+		wantPlan  []string
+	}{
+		{
+			name:      "catalog wizard asks the first question",
+			handler:   HandleCatalogWizardPrompt,
+			wantRoles: []mcp.Role{"user", "assistant"},
+			wantLast:  "What id should the catalog have?",
+			wantPlan:  []string{"1. Catalog ID", "6. Assessment requirements", "gemara://schema/ControlCatalog", "definition #ControlCatalog", "allocate_control_ids"},
+		},
+		{
+			name:      "answered steps are replayed",
+			handler:   HandleCatalogWizardPrompt,
+			arguments: map[string]string{"catalog_id": "ACME-CLOUD", "families": "Access Control", "title": " "},
+			wantRoles: []mcp.Role{"user", "assistant", "user", "assistant", "user", "assistant"},
+			wantLast:  "What is the title of the catalog",
+		},
+		{
+			name:    "policy wizard assembles when every step is answered",
+			handler: HandlePolicyWizardPrompt,
+			arguments: map[string]string{
+				"policy_id": "ACME-POL",
+				"title":     "Cloud Policy",
+				"author":    "Security team (Human)",
+				"catalogs":  "FINOS-CCC",
+				"controls":  "all",
+				"frequency": "daily, automated",
+			},
+			wantRoles: []mcp.Role{"user", "assistant", "user", "assistant", "user", "assistant", "user",
+				"assistant", "user", "assistant", "user", "assistant", "user", "user"},
+			wantLast: "Assemble the Policy and validate it now.",
+			wantPlan: []string{"definition #Policy", "gemara://examples/Policy"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.handler(context.Background(), &mcp.GetPromptRequest{Params: &mcp.GetPromptParams{Arguments: tt.arguments}})
+			require.NoError(t, err)
+			require.NotEmpty(t, result.Messages)
+
+			var roles []mcp.Role
+			for _, m := range result.Messages {
+				roles = append(roles, m.Role)
+			}
+			assert.Equal(t, tt.wantRoles, roles)
+
+			last, ok := result.Messages[len(result.Messages)-1].Content.(*mcp.TextContent)
+			require.True(t, ok)
+			assert.Contains(t, last.Text, tt.wantLast)
+
+			plan := result.Messages[0].Content.(*mcp.TextContent).Text
+			for _, want := range tt.wantPlan {
+				assert.Contains(t, plan, want)
+			}
+		})
+	}
+}
+
+func TestWizardPromptArguments(t *testing.T) {
+	for _, p := range wizardPrompts() {
+		seen := map[string]bool{}
+		for _, a := range p.Arguments {
+			assert.False(t, seen[a.Name], "prompt %s should list argument %s once", p.Name, a.Name)
+			seen[a.Name] = true
+			assert.False(t, a.Required, "wizard arguments should be optional")
+			assert.NotEmpty(t, a.Description)
+		}
+	}
+}
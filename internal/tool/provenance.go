@@ -0,0 +1,200 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"cuelang.org/go/cue/build"
+)
+
+const (
+	cacheHit  = "hit"
+	cacheMiss = "miss"
+	// cacheStale marks content served from an expired cache because upstream failed.
+	cacheStale = "stale"
+)
+
+// Provenance records what produced a tool result, so results embedded in audits
+// are self-describing and reproducible.
+type Provenance struct {
+	ServerVersion string             `json:"server_version"`
+	Schema        *SchemaProvenance  `json:"schema,omitempty"`
+	Lexicon       *LexiconProvenance `json:"lexicon,omitempty"`
+}
+
+// SchemaProvenance identifies the Gemara schema a result was computed against.
+type SchemaProvenance struct {
+	Version string `json:"version"`
+	Digest  string `json:"digest,omitempty"`
+	// Fallback is set when the registry was unreachable and the schema
+	// embedded in the binary was used instead.
+	Fallback bool `json:"fallback,omitempty"`
+}
+
+// LexiconProvenance identifies the lexicon a result was computed from.
+type LexiconProvenance struct {
+	Source string `json:"source"`
+	Digest string `json:"digest,omitempty"`
+	ETag   string `json:"etag,omitempty"`
+	Cache  string `json:"cache"`
+}
+
+// lexiconRevision identifies fetched lexicon content.
+type lexiconRevision struct {
+	source string
+	digest string
+	etag   string
+	// modTimes holds the modification time of each local file source read.
+	modTimes map[string]time.Time
+}
+
+// provenanceRecorder collects the inputs a single tool call used.
+type provenanceRecorder struct {
+	mu      sync.Mutex
+	version string
+	schema  *SchemaProvenance
+	lexicon *LexiconProvenance
+}
+
+type provenanceKey struct{}
+
+// withProvenance returns a context that records the inputs used under it.
+func withProvenance(ctx context.Context) (context.Context, *provenanceRecorder) {
+	r := &provenanceRecorder{version: serverConfig(ctx).Version}
+	return context.WithValue(ctx, provenanceKey{}, r), r
+}
+
+// provenance returns the provenance recorded so far.
+func (r *provenanceRecorder) provenance() Provenance {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Provenance{
+		ServerVersion: r.version,
+		Schema:        r.schema,
+		Lexicon:       r.lexicon,
+	}
+}
+
+// loadSchema loads the Gemara schema, borrowing it for the call, and records
+// it in the call's provenance.
+func loadSchema(ctx context.Context) (*gemaraSchema, error) {
+	schema, err := schemaLoader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	borrowSchema(ctx, schema)
+	if r, ok := ctx.Value(provenanceKey{}).(*provenanceRecorder); ok {
+		r.mu.Lock()
+		r.schema = &SchemaProvenance{Version: schema.version, Digest: schema.digest, Fallback: schema.snapshot}
+		r.mu.Unlock()
+	}
+	return schema, nil
+}
+
+// recordLexicon records the lexicon revision a call used and its cache state.
+func recordLexicon(ctx context.Context, rev lexiconRevision, cache string) {
+	r, ok := ctx.Value(provenanceKey{}).(*provenanceRecorder)
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lexicon = &LexiconProvenance{Source: rev.source, Digest: rev.digest, ETag: rev.etag, Cache: cache}
+}
+
+// contentDigest returns the sha256 digest of content.
+func contentDigest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// instanceDigest hashes the source files of a CUE instance and its imports.
+func instanceDigest(inst *build.Instance) (string, error) {
+	files := map[string][]byte{}
+	seen := map[*build.Instance]bool{}
+	var collect func(*build.Instance) error
+	collect = func(inst *build.Instance) error {
+		if seen[inst] {
+			return nil
+		}
+		seen[inst] = true
+		for _, f := range inst.BuildFiles {
+			content, ok := f.Source.([]byte)
+			if !ok {
+				var err error
+				if content, err = os.ReadFile(f.Filename); err != nil {
+					return err
+				}
+			}
+			name := f.Filename
+			if rel, err := filepath.Rel(inst.Root, f.Filename); err == nil && inst.Root != "" {
+				name = filepath.Join(inst.ImportPath, rel)
+			}
+			files[filepath.ToSlash(name)] = content
+		}
+		for _, imp := range inst.Imports {
+			if err := collect(imp); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := collect(inst); err != nil {
+		return "", fmt.Errorf("failed to digest schema: %w", err)
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%s\x00%d\x00", name, len(files[name]))
+		h.Write(files[name])
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// provenancedOutput renders a tool output with its provenance as a trailing field.
+type provenancedOutput struct {
+	output     interface{}
+	provenance Provenance
+}
+
+func (p provenancedOutput) MarshalJSON() ([]byte, error) {
+	raw, err := json.Marshal(p.output)
+	if err != nil {
+		return nil, err
+	}
+	prov, err := json.Marshal(p.provenance)
+	if err != nil {
+		return nil, err
+	}
+
+	raw = bytes.TrimSpace(raw)
+	if len(raw) < 2 || raw[0] != '{' {
+		return []byte(`{"result":` + string(raw) + `,"provenance":` + string(prov) + `}`), nil
+	}
+	var b bytes.Buffer
+	b.Write(raw[:len(raw)-1])
+	if len(bytes.TrimSpace(raw[1:len(raw)-1])) > 0 {
+		b.WriteByte(',')
+	}
+	b.WriteString(`"provenance":`)
+	b.Write(prov)
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
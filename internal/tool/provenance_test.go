@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvenancedOutput(t *testing.T) {
+	provenance := Provenance{ServerVersion: "1.0.0", Schema: &SchemaProvenance{Version: "v0.7.0"}}
+
+	tests := []struct {
+		name   string
+		output interface{}
+		want   string
+	}{
+		{
+			name:   "object",
+			output: OutputGetLexicon{Source: "file:///lexicon.yaml"},
+			want:   `{"entries":null,"source":"file:///lexicon.yaml","cached":false,"provenance":{"server_version":"1.0.0","schema":{"version":"v0.7.0"}}}`,
+		},
+		{
+			name:   "empty object",
+			output: struct{}{},
+			want:   `{"provenance":{"server_version":"1.0.0","schema":{"version":"v0.7.0"}}}`,
+		},
+		{
+			name:   "non-object",
+			output: []string{"a"},
+			want:   `{"result":["a"],"provenance":{"server_version":"1.0.0","schema":{"version":"v0.7.0"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := json.Marshal(provenancedOutput{output: tt.output, provenance: provenance})
+			require.NoError(t, err, "should marshal")
+			assert.JSONEq(t, tt.want, string(raw))
+			assert.Equal(t, tt.want, string(raw), "provenance should trail the output fields")
+		})
+	}
+}
+
+func TestRenderedHandlerProvenance(t *testing.T) {
+	useTestSchema(t)
+	ctx := withTestConfig(func(c *Config) { c.Version = "1.2.3-test" })
+
+	handler := renderedHandler(func(ctx context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, OutputGetLexicon, error) {
+		_, err := loadSchema(ctx)
+		return nil, OutputGetLexicon{}, err
+	})
+	result, _, err := handler(ctx, nil, struct{}{})
+	require.NoError(t, err, "should not return error")
+
+	provenance, ok := result.Meta["provenance"].(Provenance)
+	require.True(t, ok, "provenance should be attached as metadata")
+	assert.Equal(t, "1.2.3-test", provenance.ServerVersion)
+	require.NotNil(t, provenance.Schema, "schema use should be recorded")
+	assert.Equal(t, testSchemaVersion, provenance.Schema.Version)
+	assert.Contains(t, provenance.Schema.Digest, "sha256:")
+	assert.Nil(t, provenance.Lexicon, "unused inputs should be omitted")
+
+	text := result.Content[0].(*mcp.TextContent).Text
+	assert.Contains(t, text, `"provenance":{"server_version":"1.2.3-test"`, "provenance should be rendered with the output")
+}
+
+func TestLexiconProvenance(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "lexicon.yaml", "- term: Control\n  definition: A safeguard\n")
+
+	lexicon := NewLexiconService([]string{fileURL(filepath.Join(dir, "lexicon.yaml"))}, LexiconConflictOverride)
+
+	req := &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: lexiconResourceURI}}
+	for _, wantCache := range []string{cacheMiss, cacheHit} {
+		ctx, recorder := withProvenance(withLexicon(context.Background(), lexicon))
+		_, err := HandleLexiconResource(ctx, req)
+		require.NoError(t, err, "should read lexicon")
+
+		recorded := recorder.provenance().Lexicon
+		require.NotNil(t, recorded, "lexicon use should be recorded")
+		assert.Equal(t, wantCache, recorded.Cache)
+		assert.Equal(t, lexicon.Sources()[0], recorded.Source)
+		assert.Equal(t, contentDigest([]byte("- term: Control\n  definition: A safeguard\n")), recorded.Digest)
+	}
+}
+
+func TestSchemaDigest(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("test-data", "schema.cue"))
+	require.NoError(t, err, "should read test schema")
+
+	dir := t.TempDir()
+	writeTestFile(t, dir, "schema.cue", string(content))
+	first, err := loadSchemaDir(dir, testSchemaVersion)
+	require.NoError(t, err, "should load schema")
+	again, err := loadSchemaDir(dir, testSchemaVersion)
+	require.NoError(t, err, "should load schema")
+	assert.Equal(t, first.digest, again.digest, "digest should be stable")
+
+	writeTestFile(t, dir, "schema.cue", string(content)+"\n#Extra: {name: string}\n")
+	changed, err := loadSchemaDir(dir, testSchemaVersion)
+	require.NoError(t, err, "should load schema")
+	assert.NotEqual(t, first.digest, changed.digest, "digest should change with the source")
+}
+
+func TestLexiconStaleFallback(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "lexicon.yaml", "- term: Control\n  definition: A safeguard\n")
+
+	lexicon := NewLexiconService([]string{fileURL(filepath.Join(dir, "lexicon.yaml"))}, LexiconConflictOverride)
+	lexiconCtx := withLexicon(context.Background(), lexicon)
+
+	_, _, err := GetLexicon(lexiconCtx, nil, InputGetLexicon{Refresh: true})
+	require.NoError(t, err, "should fetch lexicon")
+
+	// Expire the cache and take the source away
+	lexicon.cache.fetched = time.Now().Add(-2 * lexiconCacheTTL)
+	require.NoError(t, os.Remove(filepath.Join(dir, "lexicon.yaml")), "should remove lexicon")
+
+	ctx, recorder := withProvenance(lexiconCtx)
+	_, output, err := GetLexicon(ctx, nil, InputGetLexicon{})
+	require.NoError(t, err, "should fall back to the stale cache")
+	assert.True(t, output.Stale, "output should be marked stale")
+	assert.Equal(t, "Control", output.Entries[0].Term)
+	assert.Equal(t, cacheStale, recorder.provenance().Lexicon.Cache)
+
+	_, output, err = GetLexicon(lexiconCtx, nil, InputGetLexicon{Refresh: true})
+	require.NoError(t, err, "refresh should fall back to the stale cache")
+	assert.True(t, output.Stale, "refreshed output should be marked stale")
+
+	lexicon.cache.entries = nil
+	_, _, err = GetLexicon(lexiconCtx, nil, InputGetLexicon{Refresh: true})
+	assert.Error(t, err, "without a cache the failure should surface")
+}
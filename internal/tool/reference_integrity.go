@@ -0,0 +1,310 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Kinds of cross-artifact reference.
+const (
+	referenceImport      = "import"
+	referenceMapping     = "mapping"
+	referenceEntry       = "entry"
+	referenceRequirement = "requirement"
+)
+
+// MetadataCheckReferenceIntegrity describes the CheckReferenceIntegrity tool.
+var MetadataCheckReferenceIntegrity = &mcp.Tool{
+	Name: "check_reference_integrity",
+	Description: "Check that every cross-artifact reference in a workspace resolves: catalog imports and threat or " +
+		"guideline mappings must name an artifact in the workspace, entry mappings such as the controls and " +
+		"requirements of evaluation plans and logs must name an entry of that artifact, and policy assessment plans " +
+		"must cite a requirement of a catalog the policy imports. References to the mapping-references an artifact " +
+		"declares, or to the external artifacts given, are counted as unchecked. Dangling references are reported " +
+		"with their file and line.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"directory": map[string]interface{}{
+				"type":        "string",
+				"description": "Directory of artifacts to check, searched recursively (default: the workspace root)",
+			},
+			"external": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Metadata ids of artifacts kept outside the workspace; references to them are counted as unchecked rather than dangling, as are references to the mapping-references an artifact declares",
+			},
+		},
+	},
+}
+
+// InputCheckReferenceIntegrity is the input for the CheckReferenceIntegrity tool.
+type InputCheckReferenceIntegrity struct {
+	Directory string   `json:"directory,omitempty"`
+	External  []string `json:"external,omitempty"`
+}
+
+// DanglingReference is a reference to an artifact or entry that does not exist.
+type DanglingReference struct {
+	// Location is the file and line, as in policy.yaml:12.
+	Location string `json:"location"`
+	File     string `json:"file"`
+	Line     int    `json:"line,omitempty"`
+	Path     string `json:"path"`
+	Kind     string `json:"kind"`
+	Target   string `json:"target"`
+	Message  string `json:"message"`
+}
+
+// OutputCheckReferenceIntegrity is the output for the CheckReferenceIntegrity tool.
+type OutputCheckReferenceIntegrity struct {
+	Directory  string              `json:"directory"`
+	Artifacts  int                 `json:"artifacts"`
+	References int                 `json:"references"`
+	Dangling   []DanglingReference `json:"dangling"`
+	// Unchecked counts references to external artifacts.
+	Unchecked int `json:"unchecked"`
+	// Skipped lists the files that could not be read as artifacts.
+	Skipped []string `json:"skipped,omitempty"`
+	Passed  bool     `json:"passed"`
+	Message string   `json:"message"`
+}
+
+// workspaceArtifact is a parsed artifact file of a workspace.
+type workspaceArtifact struct {
+	file   string
+	id     string
+	doc    map[string]interface{}
+	source *ast.File
+	// declared holds the ids of the artifact's metadata mapping-references,
+	// which are resolved by url rather than within the workspace.
+	declared map[string]bool
+}
+
+// referenceChecker resolves references against the artifacts of a workspace.
+type referenceChecker struct {
+	entities map[string]map[string]bool
+	external map[string]bool
+	output   *OutputCheckReferenceIntegrity
+}
+
+// CheckReferenceIntegrity reports the references in a workspace that do not resolve.
+func CheckReferenceIntegrity(ctx context.Context, _ *mcp.CallToolRequest, input InputCheckReferenceIntegrity) (*mcp.CallToolResult, OutputCheckReferenceIntegrity, error) {
+	dir, err := workspaceDirectory(ctx, input.Directory)
+	if err != nil {
+		return nil, OutputCheckReferenceIntegrity{}, err
+	}
+	if dir == "" {
+		return nil, OutputCheckReferenceIntegrity{}, fmt.Errorf("directory is required when no workspace root is configured")
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, OutputCheckReferenceIntegrity{}, fmt.Errorf("failed to read directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, OutputCheckReferenceIntegrity{}, fmt.Errorf("%s is not a directory", dir)
+	}
+	files, err := findArtifactFiles(dir)
+	if err != nil {
+		return nil, OutputCheckReferenceIntegrity{}, err
+	}
+
+	output := OutputCheckReferenceIntegrity{Directory: dir, Dangling: []DanglingReference{}}
+	checker := referenceChecker{entities: map[string]map[string]bool{}, external: stringSet(input.External), output: &output}
+	var artifacts []workspaceArtifact
+	for _, file := range files {
+		name := file
+		if rel, err := filepath.Rel(dir, file); err == nil {
+			name = filepath.ToSlash(rel)
+		}
+		content, err := readArtifactFile(ctx, file)
+		if err != nil {
+			output.Skipped = append(output.Skipped, name)
+			continue
+		}
+		doc, err := parseArtifact(ctx, string(content))
+		if err != nil || metadataID(doc) == "" {
+			output.Skipped = append(output.Skipped, name)
+			continue
+		}
+		// Lines are best effort, as for lint findings
+		source, _ := parser.ParseBytes(content, 0)
+		a := workspaceArtifact{file: name, id: metadataID(doc), doc: doc, source: source, declared: map[string]bool{}}
+		metadata, _ := doc["metadata"].(map[string]interface{})
+		for _, ref := range mapList(metadata["mapping-references"]) {
+			if id := stringField(ref, "id"); id != "" {
+				a.declared[id] = true
+			}
+		}
+		artifacts = append(artifacts, a)
+
+		// Artifacts sharing an id are treated as one
+		if checker.entities[a.id] == nil {
+			checker.entities[a.id] = map[string]bool{}
+		}
+		walkArtifact(doc, "$", func(path string, value interface{}) {
+			if id := entityID(value); id != "" && path != "$.metadata" {
+				checker.entities[a.id][id] = true
+			}
+		})
+	}
+	output.Artifacts = len(artifacts)
+	if len(artifacts) == 0 {
+		return nil, OutputCheckReferenceIntegrity{}, fmt.Errorf("no Gemara artifacts found in %s", dir)
+	}
+
+	for _, a := range artifacts {
+		checker.check(a)
+	}
+	sort.SliceStable(output.Dangling, func(i, j int) bool {
+		if output.Dangling[i].File != output.Dangling[j].File {
+			return output.Dangling[i].File < output.Dangling[j].File
+		}
+		return output.Dangling[i].Line < output.Dangling[j].Line
+	})
+
+	output.Passed = len(output.Dangling) == 0
+	output.Message = fmt.Sprintf("Checked %d reference(s) in %d artifact(s): %d dangling, %d to external artifacts unchecked",
+		output.References, output.Artifacts, len(output.Dangling), output.Unchecked)
+	if len(output.Skipped) > 0 {
+		output.Message += fmt.Sprintf("; skipped %d file(s) that are not Gemara artifacts", len(output.Skipped))
+	}
+	return nil, output, nil
+}
+
+// check resolves the references of one artifact.
+func (c *referenceChecker) check(a workspaceArtifact) {
+	var imports []string
+	walkArtifact(a.doc, "$", func(path string, value interface{}) {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		target, ok := m["reference-id"].(string)
+		// The entries of a mapping are checked with the mapping
+		if !ok || lastPathKey(path) == "entries" && strings.HasSuffix(path, "]") {
+			return
+		}
+		switch {
+		case m["entry-id"] != nil:
+			if !c.resolveArtifact(a, childPath(path, "reference-id"), referenceEntry, target) {
+				return
+			}
+			entry, _ := m["entry-id"].(string)
+			c.resolveEntry(a, childPath(path, "entry-id"), referenceEntry, target, entry)
+		case m["entries"] != nil:
+			if !c.resolveArtifact(a, childPath(path, "reference-id"), referenceMapping, target) {
+				return
+			}
+			entries, _ := m["entries"].([]interface{})
+			for i, e := range entries {
+				entry, _ := e.(map[string]interface{})
+				if id, ok := entry["reference-id"].(string); ok {
+					c.output.References++
+					entryPath := childPath(fmt.Sprintf("%s[%d]", childPath(path, "entries"), i), "reference-id")
+					c.resolveEntry(a, entryPath, referenceMapping, target, id)
+				}
+			}
+		default:
+			// Requirements are resolved against the catalogs a policy imports
+			if c.resolveArtifact(a, childPath(path, "reference-id"), referenceImport, target) && strings.HasPrefix(path, "$.imports.") {
+				imports = appendUnique(imports, target)
+			}
+		}
+	})
+
+	walkArtifact(a.doc, "$", func(path string, value interface{}) {
+		requirement, ok := value.(string)
+		if !ok || lastPathKey(path) != "requirement-id" {
+			return
+		}
+		c.output.References++
+		c.resolveRequirement(a, path, requirement, imports)
+	})
+}
+
+// resolveArtifact checks that target names an artifact of the workspace.
+func (c *referenceChecker) resolveArtifact(a workspaceArtifact, path, kind, target string) bool {
+	c.output.References++
+	switch {
+	case c.entities[target] != nil:
+		return true
+	case c.external[target] || a.declared[target]:
+		c.output.Unchecked++
+		return true
+	}
+	c.dangling(a, path, kind, target, fmt.Sprintf("no artifact with id %s in the workspace", target))
+	return false
+}
+
+// resolveEntry checks that entry is an entry of the artifact target, which
+// has already been resolved. Entries of external artifacts are not checked.
+func (c *referenceChecker) resolveEntry(a workspaceArtifact, path, kind, target, entry string) {
+	if c.entities[target] != nil && !c.entities[target][entry] {
+		c.dangling(a, path, kind, entry, fmt.Sprintf("%s has no entry %s", target, entry))
+	}
+}
+
+// resolveRequirement checks that a policy cites a requirement of a catalog it
+// imports, or of any workspace artifact when it imports none.
+func (c *referenceChecker) resolveRequirement(a workspaceArtifact, path, requirement string, imports []string) {
+	if len(imports) > 0 {
+		external := false
+		for _, id := range imports {
+			if c.entities[id][requirement] {
+				return
+			}
+			external = external || c.entities[id] == nil
+		}
+		if external {
+			c.output.Unchecked++
+			return
+		}
+		c.dangling(a, path, referenceRequirement, requirement,
+			fmt.Sprintf("no imported artifact (%s) has requirement %s", strings.Join(imports, ", "), requirement))
+		return
+	}
+	for id, entities := range c.entities {
+		if id != a.id && entities[requirement] {
+			return
+		}
+	}
+	c.dangling(a, path, referenceRequirement, requirement, fmt.Sprintf("no artifact in the workspace has requirement %s", requirement))
+}
+
+// dangling records a reference that does not resolve.
+func (c *referenceChecker) dangling(a workspaceArtifact, path, kind, target, message string) {
+	line := findingLine(a.source, path)
+	location := a.file
+	if line > 0 {
+		location = fmt.Sprintf("%s:%d", a.file, line)
+	}
+	c.output.Dangling = append(c.output.Dangling, DanglingReference{
+		Location: location,
+		File:     a.file,
+		Line:     line,
+		Path:     path,
+		Kind:     kind,
+		Target:   target,
+		Message:  message,
+	})
+}
+
+// appendUnique appends s to list unless it is already there.
+func appendUnique(list []string, s string) []string {
+	if slices.Contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
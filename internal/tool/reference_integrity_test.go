@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckReferenceIntegrity(t *testing.T) {
+	readTestData := func(name string) string {
+		content, err := os.ReadFile("test-data/" + name)
+		require.NoError(t, err)
+		return string(content)
+	}
+	consistent := t.TempDir()
+	writeTestFile(t, consistent, "catalog.yaml", readTestData("good-ccc.yaml"))
+	writeTestFile(t, consistent, "policies/policy.yaml", readTestData("policy.yaml"))
+	writeTestFile(t, consistent, "evaluations/log.yaml", readTestData("evaluation-log.yaml"))
+
+	dangling := t.TempDir()
+	writeTestFile(t, dangling, "catalog.yaml", `metadata:
+  id: FINOS-CCC
+  mapping-references:
+    - id: CSF
+      url: https://www.nist.gov/cyberframework
+controls:
+  - id: CCC.C01
+    assessment-requirements:
+      - id: CCC.C01.TR01
+    guideline-mappings:
+      - reference-id: CSF
+        entries:
+          - reference-id: PR.DS-02
+      - reference-id: CCM
+        entries:
+          - reference-id: IVS-03
+`)
+	writeTestFile(t, dangling, "policy.yaml", `metadata:
+  id: ORG-POL
+title: Policy
+imports:
+  catalogs:
+    - reference-id: FINOS-CCC
+    - reference-id: ORG-BASELINE
+adherence:
+  assessment-plans:
+    - id: AP-1
+      requirement-id: CCC.C01.TR01
+    - id: AP-2
+      requirement-id: CCC.C99.TR01
+`)
+	writeTestFile(t, dangling, "log.yaml", `metadata:
+  id: EVAL-1
+evaluations:
+  - name: Missing control
+    control:
+      reference-id: FINOS-CCC
+      entry-id: CCC.C42
+  - name: Missing artifact
+    control:
+      reference-id: OTHER-CATALOG
+      entry-id: OC.C01
+`)
+	writeTestFile(t, dangling, "notes.yaml", "- just\n- a list\n")
+
+	tests := []struct {
+		name         string
+		input        InputCheckReferenceIntegrity
+		wantErr      bool
+		wantPassed   bool
+		wantDangling []string
+		wantSkipped  []string
+		wantExternal int
+	}{
+		{
+			name:         "all references resolve",
+			input:        InputCheckReferenceIntegrity{Directory: consistent, External: []string{"CCC", "CSF", "CCM", "ISO-27001", "NIST-800-53"}},
+			wantPassed:   true,
+			wantExternal: 21,
+		},
+		{
+			name:  "dangling references are located",
+			input: InputCheckReferenceIntegrity{Directory: dangling},
+			wantDangling: []string{
+				"catalog.yaml:14 mapping CCM",
+				"log.yaml:7 entry CCC.C42",
+				"log.yaml:10 entry OTHER-CATALOG",
+				"policy.yaml:7 import ORG-BASELINE",
+				"policy.yaml:13 requirement CCC.C99.TR01",
+			},
+			wantSkipped:  []string{"notes.yaml"},
+			wantExternal: 1,
+		},
+		{
+			name:  "external artifacts are unchecked",
+			input: InputCheckReferenceIntegrity{Directory: dangling, External: []string{"ORG-BASELINE", "OTHER-CATALOG"}},
+			wantDangling: []string{
+				"catalog.yaml:14 mapping CCM",
+				"log.yaml:7 entry CCC.C42",
+			},
+			wantSkipped:  []string{"notes.yaml"},
+			wantExternal: 4,
+		},
+		{
+			name:    "missing directory",
+			input:   InputCheckReferenceIntegrity{Directory: dangling + "/missing"},
+			wantErr: true,
+		},
+		{
+			name:    "no artifacts",
+			input:   InputCheckReferenceIntegrity{Directory: t.TempDir()},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := CheckReferenceIntegrity(context.Background(), nil, tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPassed, output.Passed, output.Dangling)
+			assert.Equal(t, tt.wantSkipped, output.Skipped)
+			assert.Equal(t, tt.wantExternal, output.Unchecked)
+			assert.Positive(t, output.References)
+
+			var got []string
+			for _, d := range output.Dangling {
+				got = append(got, d.Location+" "+d.Kind+" "+d.Target)
+				assert.NotEmpty(t, d.Message)
+			}
+			assert.Equal(t, tt.wantDangling, got)
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultRefreshInterval is how often cached remote resources are checked for
+// upcoming expiry.
+const DefaultRefreshInterval = 10 * time.Minute
+
+// eventBackgroundRefresh is the server event reported when a cached resource
+// is refreshed ahead of its expiry.
+const eventBackgroundRefresh = "background_refresh"
+
+// Refresher re-fetches the lexicon, the resolved schema module, and subscribed
+// federated catalogs shortly before their cache entries expire, so that tool
+// calls and resource reads are answered from cache instead of waiting on the
+// network. Only resources that were already loaded are refreshed; local files
+// and the embedded snapshots are left alone.
+type Refresher struct {
+	server  *mcp.Server
+	lexicon *LexiconService
+}
+
+// NewRefresher creates a refresher for the lexicon service a server uses.
+// Subscribers on server are notified of refreshed resources whose content
+// changed.
+func NewRefresher(server *mcp.Server, lexicon *LexiconService) *Refresher {
+	if lexicon == nil {
+		lexicon = DefaultLexicon
+	}
+	return &Refresher{server: server, lexicon: lexicon}
+}
+
+// Run refreshes every interval until ctx is cancelled.
+func (r *Refresher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Refresh(ctx, interval)
+		}
+	}
+}
+
+// Refresh re-fetches every resource that expires within margin. A failed
+// fetch keeps the cached copy, which is then served stale or fetched again by
+// the next caller, as without background refresh.
+func (r *Refresher) Refresh(ctx context.Context, margin time.Duration) {
+	r.refreshLexicon(ctx, margin)
+	r.refreshSchema(ctx, margin)
+	r.refreshFederation(ctx, margin)
+}
+
+func (r *Refresher) refreshLexicon(ctx context.Context, margin time.Duration) {
+	if !r.lexicon.expiring(margin) {
+		return
+	}
+	previous := r.lexicon.cached().revision.digest
+	entries, rev, err := r.lexicon.fetch(ctx)
+	if err != nil {
+		emitStaleCache(ctx, "lexicon", r.lexicon.source(), err)
+		return
+	}
+	r.lexicon.store(lexiconState{entries: entries, fetched: time.Now(), revision: rev})
+	r.refreshed(ctx, "lexicon", r.lexicon.source(), LexiconResourceURI, rev.digest != previous)
+}
+
+func (r *Refresher) refreshSchema(ctx context.Context, margin time.Duration) {
+	schemaResolutionMu.Lock()
+	root, previous, resolved := resolvedSchemaRoot, resolvedSchemaVersion, resolvedSchemaTime
+	failing := schemaFailure != nil && time.Since(schemaFailureTime) < schemaFailureTTL
+	schemaResolutionMu.Unlock()
+	// Schemas never resolved from the registry, such as bundled ones, are not
+	// refreshed, nor are they while the registry recently failed
+	if root == "" || failing || !expiresWithin(resolved, schemaResolutionTTL, margin) {
+		return
+	}
+	schema, _, err := sharedCall(ctx, &schemaFlight, gemaraModulePath, resolveGemaraSchema)
+	if err != nil || schema.snapshot {
+		// resolveGemaraSchema reported the failure; the resolved module stays in use until it expires
+		return
+	}
+	r.refreshed(ctx, "schema", gemaraModulePath, "", schema.version != previous)
+}
+
+func (r *Refresher) refreshFederation(ctx context.Context, margin time.Duration) {
+	for _, c := range serverConfig(ctx).Federation {
+		uri := federatedResourcePrefix + c.Name
+		if !subscribed(uri) {
+			continue
+		}
+		federationMu.Lock()
+		cached, ok := federationCache[c.Name]
+		federationMu.Unlock()
+		if !ok || !expiresWithin(cached.fetched, federationCacheTTL, margin) {
+			continue
+		}
+		entry, _, err := sharedCall(ctx, &federationFlight, c.Name, func(ctx context.Context) (federatedEntry, error) {
+			return mergeFederatedCatalog(ctx, c, cached, ok)
+		})
+		if err != nil || entry.fetched.Equal(cached.fetched) {
+			continue
+		}
+		r.refreshed(ctx, "federated catalog", uri, uri, !bytes.Equal(entry.content, cached.content))
+	}
+}
+
+// refreshed reports a refreshed resource and notifies its subscribers when
+// its content changed.
+func (r *Refresher) refreshed(ctx context.Context, kind, source, uri string, changed bool) {
+	emitEvent(ctx, "debug", eventBackgroundRefresh, "refreshed "+kind+" ahead of expiry", map[string]interface{}{
+		"kind":    kind,
+		"source":  source,
+		"changed": changed,
+	})
+	if changed && uri != "" && r.server != nil {
+		_ = r.server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: uri})
+	}
+}
+
+// expiresWithin reports whether an entry fetched at the given time expires
+// within margin. Entries never fetched do not expire.
+func expiresWithin(fetched time.Time, ttl, margin time.Duration) bool {
+	return !fetched.IsZero() && time.Since(fetched) >= ttl-margin
+}
+
+// expiring reports whether the cached lexicon has remote sources and expires
+// within margin.
+func (s *LexiconService) expiring(margin time.Duration) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.cache.entries) == 0 || s.cache.snapshot || !expiresWithin(s.cache.fetched, lexiconCacheTTL, margin) {
+		return false
+	}
+	for _, source := range s.sources {
+		if _, ok := lexiconFilePath(source); !ok {
+			return true
+		}
+	}
+	return false
+}
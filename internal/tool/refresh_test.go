@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefresherLexicon(t *testing.T) {
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := fetches.Add(1)
+		fmt.Fprintf(w, "- term: Control\n  definition: Revision %d\n", n)
+	}))
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+	lexicon := NewLexiconService([]string{server.URL}, LexiconConflictOverride)
+	refresher := NewRefresher(nil, lexicon)
+
+	refresher.Refresh(ctx, time.Minute)
+	assert.Equal(t, int32(0), fetches.Load(), "a lexicon never loaded should not be fetched")
+
+	_, _, err := lexicon.load(ctx)
+	require.NoError(t, err)
+	refresher.Refresh(ctx, time.Minute)
+	assert.Equal(t, int32(1), fetches.Load(), "a fresh lexicon should not be refetched")
+
+	// Expiring within the margin
+	state := lexicon.cached()
+	state.fetched = time.Now().Add(-lexiconCacheTTL + 30*time.Second)
+	lexicon.store(state)
+	refresher.Refresh(ctx, time.Minute)
+	assert.Equal(t, int32(2), fetches.Load(), "an expiring lexicon should be refetched")
+
+	refreshed, cache, err := lexicon.load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, cacheHit, cache, "the refreshed lexicon should be served from cache")
+	assert.Equal(t, "Revision 2", refreshed.entries[0].Definition)
+}
+
+func TestRefresherFederation(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "access.yaml", federationAccess)
+	ctx := useFederation(t,
+		FederatedCatalog{Name: "org", Sources: []string{fileURL(filepath.Join(dir, "access.yaml"))}},
+		FederatedCatalog{Name: "unwatched", Sources: []string{fileURL(filepath.Join(dir, "access.yaml"))}},
+	)
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server"}, &mcp.ServerOptions{
+		SubscribeHandler:   SubscribeResource,
+		UnsubscribeHandler: UnsubscribeResource,
+	})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err, "server should connect")
+	updates := make(chan string, 10)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, &mcp.ClientOptions{
+		ResourceUpdatedHandler: func(_ context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			updates <- req.Params.URI
+		},
+	})
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err, "client should connect")
+	t.Cleanup(func() { _ = session.Close() })
+
+	require.NoError(t, session.Subscribe(ctx, &mcp.SubscribeParams{URI: "gemara://federated/org"}))
+	t.Cleanup(func() { _ = session.Unsubscribe(ctx, &mcp.UnsubscribeParams{URI: "gemara://federated/org"}) })
+	for _, name := range []string{"org", "unwatched"} {
+		_, err := readArtifactURI(ctx, "gemara://federated/"+name)
+		require.NoError(t, err)
+		federationMu.Lock()
+		entry := federationCache[name]
+		entry.fetched = time.Now().Add(-federationCacheTTL)
+		federationCache[name] = entry
+		federationMu.Unlock()
+	}
+
+	writeTestFile(t, dir, "access.yaml", federationAccess+"  - id: AC.02\n    family: AC\n    title: Separation of duties\n")
+	NewRefresher(server, nil).Refresh(ctx, time.Minute)
+
+	select {
+	case uri := <-updates:
+		assert.Equal(t, "gemara://federated/org", uri)
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscribers of a changed catalog should be notified")
+	}
+	federationMu.Lock()
+	org, unwatched := federationCache["org"], federationCache["unwatched"]
+	federationMu.Unlock()
+	assert.Contains(t, string(org.content), "AC.02", "the subscribed catalog should be refreshed")
+	assert.NotContains(t, string(unwatched.content), "AC.02", "catalogs nobody subscribed to should be left to expire")
+}
+
+func TestExpiresWithin(t *testing.T) {
+	assert.False(t, expiresWithin(time.Time{}, time.Hour, time.Minute))
+	assert.False(t, expiresWithin(time.Now(), time.Hour, time.Minute))
+	assert.True(t, expiresWithin(time.Now().Add(-59*time.Minute-30*time.Second), time.Hour, time.Minute))
+}
@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"fmt"
+	"os"
+
+	"cuelang.org/go/mod/modconfig"
+)
+
+// RegistryConfig configures the CUE registry the Gemara module is resolved
+// from, such as an internal OCI mirror of the public registry.
+type RegistryConfig struct {
+	// Registry is a CUE_REGISTRY value: a registry such as
+	// registry.example.com/cue-mirror, or module prefixes mapped to registries.
+	// $CUE_REGISTRY, then the public registry, are used when it is empty.
+	Registry string
+	// DockerConfig is a directory holding a Docker config.json whose
+	// credentials (auths or credential helpers) authenticate to the registry.
+	// $DOCKER_CONFIG, then ~/.docker, are used when it is empty. Credentials
+	// stored by 'cue login' take precedence.
+	DockerConfig string
+}
+
+// cueConfig returns the configuration for resolving CUE modules.
+func (c RegistryConfig) cueConfig() *modconfig.Config {
+	cfg := &modconfig.Config{Transport: httpTransport, CUERegistry: c.Registry}
+	if c.DockerConfig != "" {
+		// Later entries win, so this overrides any inherited DOCKER_CONFIG
+		cfg.Env = append(os.Environ(), "DOCKER_CONFIG="+c.DockerConfig)
+	}
+	return cfg
+}
+
+// Validate reports whether the registry configuration can be parsed, so a
+// bad --cue-registry fails at startup rather than on the first call.
+func (c RegistryConfig) Validate() error {
+	if c.DockerConfig != "" {
+		info, err := os.Stat(c.DockerConfig)
+		if err != nil {
+			return fmt.Errorf("invalid registry docker config: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("registry docker config %s must be the directory holding config.json", c.DockerConfig)
+		}
+	}
+	if _, err := modconfig.NewResolver(c.cueConfig()); err != nil {
+		return fmt.Errorf("invalid CUE registry configuration: %w", err)
+	}
+	return nil
+}
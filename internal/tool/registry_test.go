@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRegistry(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "config.json", "{}")
+
+	tests := []struct {
+		name        string
+		registry    RegistryConfig
+		errContains string
+	}{
+		{name: "default", registry: RegistryConfig{}},
+		{name: "mirror", registry: RegistryConfig{Registry: "registry.example.com/cue-mirror"}},
+		{name: "module prefix mapping", registry: RegistryConfig{Registry: "github.com/gemaraproj=registry.example.com/mirror,registry.cue.works"}},
+		{name: "docker config", registry: RegistryConfig{Registry: "registry.example.com", DockerConfig: dir}},
+		{name: "bad registry", registry: RegistryConfig{Registry: "bad registry!"}, errContains: "invalid CUE registry configuration"},
+		{name: "missing docker config", registry: RegistryConfig{DockerConfig: dir + "/missing"}, errContains: "invalid registry docker config"},
+		{name: "docker config file", registry: RegistryConfig{DockerConfig: dir + "/config.json"}, errContains: "must be the directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.registry.Validate()
+			if tt.errContains != "" {
+				require.Error(t, err, "should reject the configuration")
+				assert.Contains(t, err.Error(), tt.errContains, "error should contain expected message")
+				return
+			}
+			assert.NoError(t, err, "should accept the configuration")
+		})
+	}
+}
+
+func TestRegistryMirrorWithDockerCredentials(t *testing.T) {
+	const user, password = "mirror-user", "mirror-password"
+	var paths []string
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, gotPassword, ok := r.BasicAuth()
+		if !ok || gotUser != user || gotPassword != password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="mirror"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"name": "mirror/github.com/gemaraproj/gemara", "tags": ["v0.1.0", "v0.2.0"]}`)
+	}))
+	t.Cleanup(mirror.Close)
+	host := strings.TrimPrefix(mirror.URL, "http://")
+
+	dir := t.TempDir()
+	auth := base64.StdEncoding.EncodeToString([]byte(user + ":" + password))
+	writeTestFile(t, dir, "config.json", fmt.Sprintf(`{"auths": {%q: {"auth": %q}}}`, host, auth))
+	t.Setenv("CUE_CONFIG_DIR", t.TempDir())
+
+	ctx := withTestConfig(func(c *Config) { c.Registry = RegistryConfig{Registry: host + "/mirror+insecure", DockerConfig: dir} })
+	versions, err := listGemaraVersions(ctx)
+	require.NoError(t, err, "should list versions from the mirror")
+	assert.ElementsMatch(t, []string{"v0.1.0", "v0.2.0"}, versions, "should return the mirror's versions")
+	require.NotEmpty(t, paths, "should query the mirror")
+	assert.Contains(t, paths[0], "/v2/mirror/github.com/gemaraproj/gemara/tags/list", "should use the mirror repository prefix")
+}
@@ -0,0 +1,271 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// regoNormative matches the RFC 2119 keywords that make a requirement testable.
+var regoNormative = regexp.MustCompile(`\b(?:MUST|SHALL|MUST NOT|SHALL NOT|REQUIRED)\b`)
+
+// regoManual matches requirements about people and process, which configuration
+// data cannot show to be met.
+var regoManual = regexp.MustCompile(`(?i)\b(?:document(?:ed|ation)?|review(?:ed)?|train(?:ed|ing)?|approv(?:e|ed|al)|interview|attest(?:ed|ation)?|procedure|manual(?:ly)?|sign[- ]off)\b`)
+
+// regoIdentifier matches characters that are not valid in a Rego package segment.
+var regoIdentifier = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// MetadataGenerateRegoStubs describes the GenerateRegoStubs tool.
+var MetadataGenerateRegoStubs = &mcp.Tool{
+	Name: "generate_rego_stubs",
+	Description: "Convert the machine-checkable assessment requirements of a ControlCatalog into skeleton OPA Rego " +
+		"packages, one per control, with METADATA annotations linking each rule back to its Gemara catalog, control, " +
+		"and requirement IDs. Requirements about documentation, review, or training are skipped unless include_manual is set.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"catalog_content"},
+		"properties": map[string]interface{}{
+			"catalog_content": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML content of the ControlCatalog",
+			},
+			"package_prefix": map[string]interface{}{
+				"type":        "string",
+				"description": "Rego package prefix (default: gemara.<catalog id>)",
+			},
+			"controls": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Only generate packages for these control IDs (default: every control)",
+			},
+			"include_manual": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Also generate rules for requirements that look manual (default: false)",
+			},
+		},
+	},
+}
+
+// InputGenerateRegoStubs is the input for the GenerateRegoStubs tool.
+type InputGenerateRegoStubs struct {
+	CatalogContent string   `json:"catalog_content"`
+	PackagePrefix  string   `json:"package_prefix,omitempty"`
+	Controls       []string `json:"controls,omitempty"`
+	IncludeManual  bool     `json:"include_manual,omitempty"`
+}
+
+// RegoFile is a generated Rego package.
+type RegoFile struct {
+	Path         string   `json:"path"`
+	Package      string   `json:"package"`
+	Control      string   `json:"control"`
+	Requirements []string `json:"requirements"`
+	Content      string   `json:"content"`
+}
+
+// SkippedRequirement is an assessment requirement no rule was generated for.
+type SkippedRequirement struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// OutputGenerateRegoStubs is the output for the GenerateRegoStubs tool.
+type OutputGenerateRegoStubs struct {
+	Files   []RegoFile           `json:"files"`
+	Skipped []SkippedRequirement `json:"skipped,omitempty"`
+	Message string               `json:"message"`
+}
+
+// GenerateRegoStubs drafts Rego packages for the assessment requirements of a catalog.
+func GenerateRegoStubs(ctx context.Context, _ *mcp.CallToolRequest, input InputGenerateRegoStubs) (*mcp.CallToolResult, OutputGenerateRegoStubs, error) {
+	if input.CatalogContent == "" {
+		return nil, OutputGenerateRegoStubs{}, fmt.Errorf("catalog_content is required")
+	}
+	catalog, err := parseArtifact(ctx, input.CatalogContent)
+	if err != nil {
+		return nil, OutputGenerateRegoStubs{}, err
+	}
+	controls, ok := catalog["controls"].([]interface{})
+	if !ok || len(controls) == 0 {
+		return nil, OutputGenerateRegoStubs{}, fmt.Errorf("catalog has no controls")
+	}
+	catalogID := metadataID(catalog)
+	prefix := input.PackagePrefix
+	if prefix == "" {
+		prefix = "gemara." + regoSegment(catalogID)
+	}
+	for _, segment := range strings.Split(prefix, ".") {
+		if segment == "" || regoSegment(segment) != segment {
+			return nil, OutputGenerateRegoStubs{}, fmt.Errorf("invalid package_prefix %q: use dot-separated lower-case identifiers", prefix)
+		}
+	}
+
+	wantControls := stringSet(input.Controls)
+	found := map[string]bool{}
+	output := OutputGenerateRegoStubs{Files: []RegoFile{}}
+	requirements := 0
+	for _, item := range controls {
+		control, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		controlID := entityID(control)
+		if controlID == "" || len(wantControls) > 0 && !wantControls[controlID] {
+			continue
+		}
+		found[controlID] = true
+
+		var checkable []map[string]interface{}
+		items, _ := control["assessment-requirements"].([]interface{})
+		for _, r := range items {
+			requirement, ok := r.(map[string]interface{})
+			if !ok || entityID(requirement) == "" {
+				continue
+			}
+			text, _ := requirement["text"].(string)
+			if reason := regoSkipReason(text); reason != "" && !input.IncludeManual {
+				output.Skipped = append(output.Skipped, SkippedRequirement{ID: entityID(requirement), Reason: reason})
+				continue
+			}
+			checkable = append(checkable, requirement)
+		}
+		if len(checkable) == 0 {
+			continue
+		}
+
+		pkg := prefix + "." + regoSegment(controlID)
+		file := RegoFile{
+			Path:    strings.ReplaceAll(pkg, ".", "/") + ".rego",
+			Package: pkg,
+			Control: controlID,
+		}
+		file.Content, err = regoPackage(pkg, catalogID, control, checkable)
+		if err != nil {
+			return nil, OutputGenerateRegoStubs{}, err
+		}
+		for _, r := range checkable {
+			file.Requirements = append(file.Requirements, entityID(r))
+		}
+		requirements += len(checkable)
+		output.Files = append(output.Files, file)
+	}
+	for _, id := range input.Controls {
+		if !found[id] {
+			return nil, OutputGenerateRegoStubs{}, fmt.Errorf("control %s not found in the catalog", id)
+		}
+	}
+
+	output.Message = fmt.Sprintf("Generated %d package(s) with %d rule stub(s); skipped %d requirement(s) that need manual evaluation",
+		len(output.Files), requirements, len(output.Skipped))
+	if len(output.Files) > 0 {
+		output.Message += "; replace each stub's false condition with a check of input"
+	}
+	return nil, output, nil
+}
+
+// regoSkipReason explains why a requirement is not machine-checkable, or
+// returns an empty string when it is.
+func regoSkipReason(text string) string {
+	if m := regoManual.FindString(text); m != "" {
+		return fmt.Sprintf("mentions %q, which suggests a manual check", strings.ToLower(m))
+	}
+	if !regoNormative.MatchString(text) {
+		return "has no MUST or SHALL statement to test"
+	}
+	return ""
+}
+
+// regoPackage renders a Rego package with one deny rule per requirement.
+func regoPackage(pkg, catalogID string, control map[string]interface{}, requirements []map[string]interface{}) (string, error) {
+	controlID := entityID(control)
+	title, _ := control["title"].(string)
+	objective, _ := control["objective"].(string)
+
+	var b strings.Builder
+	packageMeta := yaml.MapSlice{{Key: "title", Value: firstNonEmpty(title, controlID)}}
+	if objective = strings.Join(strings.Fields(objective), " "); objective != "" {
+		packageMeta = append(packageMeta, yaml.MapItem{Key: "description", Value: objective})
+	}
+	packageMeta = append(packageMeta, yaml.MapItem{Key: "custom", Value: yaml.MapSlice{
+		{Key: "gemara", Value: yaml.MapSlice{
+			{Key: "catalog", Value: catalogID},
+			{Key: "control", Value: controlID},
+		}},
+	}})
+	if err := writeRegoMetadata(&b, "package", packageMeta); err != nil {
+		return "", err
+	}
+	fmt.Fprintf(&b, "package %s\n\nimport rego.v1\n", pkg)
+
+	for _, requirement := range requirements {
+		requirementID := entityID(requirement)
+		text, _ := requirement["text"].(string)
+		text = strings.Join(strings.Fields(text), " ")
+		gemara := yaml.MapSlice{
+			{Key: "catalog", Value: catalogID},
+			{Key: "control", Value: controlID},
+			{Key: "requirement", Value: requirementID},
+		}
+		if applicability, ok := requirement["applicability"].([]interface{}); ok && len(applicability) > 0 {
+			gemara = append(gemara, yaml.MapItem{Key: "applicability", Value: applicability})
+		}
+		meta := yaml.MapSlice{
+			{Key: "title", Value: requirementID},
+			{Key: "description", Value: text},
+			{Key: "custom", Value: yaml.MapSlice{{Key: "gemara", Value: gemara}}},
+		}
+		b.WriteString("\n")
+		if err := writeRegoMetadata(&b, "rule", meta); err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "deny contains msg if {\n")
+		fmt.Fprintf(&b, "\t# %s: check input for %s, then remove this line\n", skeletonPlaceholder, requirementID)
+		fmt.Fprintf(&b, "\tfalse\n")
+		fmt.Fprintf(&b, "\tmsg := %q\n", requirementID+": "+text)
+		fmt.Fprintf(&b, "}\n")
+	}
+	return b.String(), nil
+}
+
+// writeRegoMetadata writes an OPA METADATA annotation block.
+func writeRegoMetadata(b *strings.Builder, scope string, meta yaml.MapSlice) error {
+	meta = append(yaml.MapSlice{{Key: "scope", Value: scope}}, meta...)
+	out, err := yaml.MarshalWithOptions(meta, yaml.Indent(2), yaml.IndentSequence(true))
+	if err != nil {
+		return fmt.Errorf("failed to encode Rego metadata: %w", err)
+	}
+	b.WriteString("# METADATA\n")
+	for _, line := range strings.Split(strings.TrimRight(string(out), "\n"), "\n") {
+		b.WriteString(strings.TrimRight("# "+line, " ") + "\n")
+	}
+	return nil
+}
+
+// regoSegment converts an ID into a Rego package segment, e.g. "CCC.C01" -> "ccc_c01".
+func regoSegment(id string) string {
+	segment := strings.Trim(regoIdentifier.ReplaceAllString(strings.ToLower(id), "_"), "_")
+	if segment == "" {
+		return "catalog"
+	}
+	if segment[0] >= '0' && segment[0] <= '9' {
+		segment = "_" + segment
+	}
+	return segment
+}
+
+// firstNonEmpty returns the first non-empty value.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
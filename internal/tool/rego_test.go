@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const regoTestCatalog = `metadata:
+  id: ACME-Cloud
+title: ACME Cloud
+controls:
+  - id: AC.C01
+    title: Encrypt Buckets
+    objective: Stored data is encrypted.
+    assessment-requirements:
+      - id: AC.C01.TR01
+        text: Buckets MUST enable default encryption with "aws:kms".
+        applicability: [prod]
+      - id: AC.C01.TR02
+        text: Key rotation MUST be documented and reviewed annually.
+  - id: AC.C02
+    title: Security Training
+    objective: Staff understand their duties.
+    assessment-requirements:
+      - id: AC.C02.TR01
+        text: Engineers complete training each year.
+`
+
+func TestGenerateRegoStubs(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            InputGenerateRegoStubs
+		wantErr          bool
+		errContains      string
+		wantPackages     []string
+		wantRequirements [][]string
+		wantSkipped      []string
+	}{
+		{
+			name:        "missing catalog",
+			wantErr:     true,
+			errContains: "catalog_content is required",
+		},
+		{
+			name:        "invalid prefix",
+			input:       InputGenerateRegoStubs{CatalogContent: regoTestCatalog, PackagePrefix: "Policy.Acme"},
+			wantErr:     true,
+			errContains: "invalid package_prefix",
+		},
+		{
+			name:        "unknown control",
+			input:       InputGenerateRegoStubs{CatalogContent: regoTestCatalog, Controls: []string{"AC.C09"}},
+			wantErr:     true,
+			errContains: "control AC.C09 not found",
+		},
+		{
+			name:             "machine-checkable requirements only",
+			input:            InputGenerateRegoStubs{CatalogContent: regoTestCatalog},
+			wantPackages:     []string{"gemara.acme_cloud.ac_c01"},
+			wantRequirements: [][]string{{"AC.C01.TR01"}},
+			wantSkipped:      []string{"AC.C01.TR02", "AC.C02.TR01"},
+		},
+		{
+			name:             "include manual with custom prefix",
+			input:            InputGenerateRegoStubs{CatalogContent: regoTestCatalog, PackagePrefix: "policy.acme", IncludeManual: true},
+			wantPackages:     []string{"policy.acme.ac_c01", "policy.acme.ac_c02"},
+			wantRequirements: [][]string{{"AC.C01.TR01", "AC.C01.TR02"}, {"AC.C02.TR01"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := GenerateRegoStubs(context.Background(), nil, tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			var packages []string
+			var requirements [][]string
+			for _, f := range output.Files {
+				packages = append(packages, f.Package)
+				requirements = append(requirements, f.Requirements)
+				assert.Contains(t, f.Content, "package "+f.Package+"\n")
+				assert.Contains(t, f.Content, "import rego.v1")
+			}
+			assert.Equal(t, tt.wantPackages, packages)
+			assert.Equal(t, tt.wantRequirements, requirements)
+			var skipped []string
+			for _, s := range output.Skipped {
+				skipped = append(skipped, s.ID)
+			}
+			assert.Equal(t, tt.wantSkipped, skipped)
+		})
+	}
+}
+
+func TestGenerateRegoStubsContent(t *testing.T) {
+	_, output, err := GenerateRegoStubs(context.Background(), nil, InputGenerateRegoStubs{CatalogContent: regoTestCatalog, Controls: []string{"AC.C01"}})
+	require.NoError(t, err)
+	require.Len(t, output.Files, 1)
+
+	want := `# METADATA
+# scope: package
+# title: Encrypt Buckets
+# description: Stored data is encrypted.
+# custom:
+#   gemara:
+#     catalog: ACME-Cloud
+#     control: AC.C01
+package gemara.acme_cloud.ac_c01
+
+import rego.v1
+
+# METADATA
+# scope: rule
+# title: AC.C01.TR01
+# description: Buckets MUST enable default encryption with "aws:kms".
+# custom:
+#   gemara:
+#     catalog: ACME-Cloud
+#     control: AC.C01
+#     requirement: AC.C01.TR01
+#     applicability:
+#       - prod
+deny contains msg if {
+	# TODO: check input for AC.C01.TR01, then remove this line
+	false
+	msg := "AC.C01.TR01: Buckets MUST enable default encryption with \"aws:kms\"."
+}
+`
+	assert.Equal(t, want, output.Files[0].Content)
+	assert.Equal(t, "gemara/acme_cloud/ac_c01.rego", output.Files[0].Path)
+}
@@ -0,0 +1,296 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// gemaraLayers names the layers of the Gemara model in order.
+var gemaraLayers = []string{"Guidance", "Controls", "Policy", "Evaluation", "Enforcement", "Audit"}
+
+// layerPatterns match terms named after each layer, such as "Control Catalog".
+var layerPatterns = func() []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, len(gemaraLayers))
+	for i, name := range gemaraLayers {
+		patterns[i] = regexp.MustCompile(`(?i)\b` + strings.TrimSuffix(name, "s") + `s?\b`)
+	}
+	return patterns
+}()
+
+// layerMention matches an explicit layer reference such as "Layer 2".
+var layerMention = regexp.MustCompile(`(?i)\blayer\s+([1-6])\b`)
+
+// MetadataGetTermRelationships describes the GetTermRelationships tool.
+var MetadataGetTermRelationships = &mcp.Tool{
+	Name: "get_term_relationships",
+	Description: "Return a graph of how Gemara lexicon terms reference each other: nodes annotated with their Gemara layer " +
+		"and edges from each term to the terms its definition mentions. Focus on a term, or give two terms to get the path between them.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"term": map[string]interface{}{
+				"type":        "string",
+				"description": "Only return terms within depth hops of this term (case-insensitive)",
+			},
+			"related_to": map[string]interface{}{
+				"type":        "string",
+				"description": "Second term; returns the shortest chain of references connecting it to term",
+			},
+			"depth": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"description": "Hops to include around term (default: 1)",
+			},
+		},
+	},
+}
+
+// InputGetTermRelationships is the input for the GetTermRelationships tool.
+type InputGetTermRelationships struct {
+	Term      string `json:"term,omitempty"`
+	RelatedTo string `json:"related_to,omitempty"`
+	Depth     int    `json:"depth,omitempty"`
+}
+
+// TermNode is a lexicon term in the relationship graph.
+type TermNode struct {
+	Term string `json:"term"`
+	// Layer is the Gemara layer (1-6) the term belongs to, or 0 when it spans layers.
+	Layer     int    `json:"layer,omitempty"`
+	LayerName string `json:"layer_name,omitempty"`
+}
+
+// TermEdge records that the definition of From mentions To.
+type TermEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// OutputGetTermRelationships is the output for the GetTermRelationships tool.
+type OutputGetTermRelationships struct {
+	Nodes []TermNode `json:"nodes"`
+	Edges []TermEdge `json:"edges"`
+	// Path is the chain of terms connecting term and related_to, when both are given.
+	Path    []string `json:"path,omitempty"`
+	Message string   `json:"message"`
+}
+
+// GetTermRelationships builds the cross-reference graph of the lexicon.
+func GetTermRelationships(ctx context.Context, _ *mcp.CallToolRequest, input InputGetTermRelationships) (*mcp.CallToolResult, OutputGetTermRelationships, error) {
+	if input.RelatedTo != "" && input.Term == "" {
+		return nil, OutputGetTermRelationships{}, fmt.Errorf("term is required with related_to")
+	}
+	if input.Depth < 0 {
+		return nil, OutputGetTermRelationships{}, fmt.Errorf("depth must be at least 1")
+	}
+
+	_, lexicon, err := GetLexicon(ctx, nil, InputGetLexicon{})
+	if err != nil {
+		return nil, OutputGetTermRelationships{}, err
+	}
+	graph := buildTermGraph(lexicon.Entries)
+
+	if input.Term == "" {
+		output := OutputGetTermRelationships{Nodes: graph.nodes, Edges: graph.edges}
+		output.Message = fmt.Sprintf("%d term(s) with %d reference(s)", len(output.Nodes), len(output.Edges))
+		return nil, output, nil
+	}
+
+	from, ok := graph.lookup(input.Term)
+	if !ok {
+		return nil, OutputGetTermRelationships{}, fmt.Errorf("term %q is not in the lexicon", input.Term)
+	}
+
+	if input.RelatedTo != "" {
+		to, ok := graph.lookup(input.RelatedTo)
+		if !ok {
+			return nil, OutputGetTermRelationships{}, fmt.Errorf("term %q is not in the lexicon", input.RelatedTo)
+		}
+		path := graph.shortestPath(from, to)
+		output := graph.subgraph(path)
+		output.Path = path
+		if len(path) == 0 {
+			output.Message = fmt.Sprintf("%s and %s are not connected by lexicon references", from, to)
+		} else {
+			output.Message = fmt.Sprintf("%s relates to %s through %d reference(s)", from, to, len(path)-1)
+		}
+		return nil, output, nil
+	}
+
+	depth := input.Depth
+	if depth == 0 {
+		depth = 1
+	}
+	output := graph.subgraph(graph.neighborhood(from, depth))
+	output.Message = fmt.Sprintf("%d term(s) within %d hop(s) of %s", len(output.Nodes), depth, from)
+	return nil, output, nil
+}
+
+// termGraph is the cross-reference graph of a lexicon.
+type termGraph struct {
+	nodes []TermNode
+	edges []TermEdge
+	// terms maps lower-cased terms to their canonical spelling.
+	terms map[string]string
+	// adjacent lists the neighbors of each term in either direction.
+	adjacent map[string][]string
+}
+
+// buildTermGraph links each term to the terms its definition mentions. The
+// longest matching term wins, so "Control Catalog" is not also read as "Control".
+func buildTermGraph(entries []LexiconEntry) *termGraph {
+	g := &termGraph{terms: map[string]string{}, adjacent: map[string][]string{}}
+	for _, entry := range entries {
+		if _, ok := g.terms[strings.ToLower(entry.Term)]; ok || entry.Term == "" {
+			continue
+		}
+		g.terms[strings.ToLower(entry.Term)] = entry.Term
+		layer := termLayer(entry)
+		node := TermNode{Term: entry.Term, Layer: layer}
+		if layer > 0 {
+			node.LayerName = gemaraLayers[layer-1]
+		}
+		g.nodes = append(g.nodes, node)
+	}
+
+	byLength := make([]string, 0, len(g.nodes))
+	patterns := map[string]*regexp.Regexp{}
+	for _, node := range g.nodes {
+		byLength = append(byLength, node.Term)
+		patterns[node.Term] = regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(node.Term) + `(?:s|es)?\b`)
+	}
+	sort.SliceStable(byLength, func(i, j int) bool { return len(byLength[i]) > len(byLength[j]) })
+
+	seen := map[TermEdge]bool{}
+	for _, entry := range entries {
+		from, ok := g.terms[strings.ToLower(entry.Term)]
+		if !ok {
+			continue
+		}
+		covered := make([]bool, len(entry.Definition))
+		for _, to := range byLength {
+			for _, loc := range patterns[to].FindAllStringIndex(entry.Definition, -1) {
+				if coveredSpan(covered, loc[0], loc[1]) {
+					continue
+				}
+				for i := loc[0]; i < loc[1]; i++ {
+					covered[i] = true
+				}
+				edge := TermEdge{From: from, To: to}
+				if to == from || seen[edge] {
+					continue
+				}
+				seen[edge] = true
+				g.edges = append(g.edges, edge)
+				g.adjacent[from] = append(g.adjacent[from], to)
+				g.adjacent[to] = append(g.adjacent[to], from)
+			}
+		}
+	}
+	return g
+}
+
+// coveredSpan reports whether any byte in [start, end) is already matched.
+func coveredSpan(covered []bool, start, end int) bool {
+	for i := start; i < end; i++ {
+		if covered[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// termLayer returns the Gemara layer a term belongs to: the layer it is named
+// after, or the one its definition explicitly mentions.
+func termLayer(entry LexiconEntry) int {
+	for i, pattern := range layerPatterns {
+		if pattern.MatchString(entry.Term) {
+			return i + 1
+		}
+	}
+	if m := layerMention.FindStringSubmatch(entry.Definition); m != nil {
+		layer, _ := strconv.Atoi(m[1])
+		return layer
+	}
+	return 0
+}
+
+// lookup returns the canonical spelling of a term.
+func (g *termGraph) lookup(term string) (string, bool) {
+	canonical, ok := g.terms[strings.ToLower(strings.TrimSpace(term))]
+	return canonical, ok
+}
+
+// neighborhood returns the terms within depth hops of term, in either direction.
+func (g *termGraph) neighborhood(term string, depth int) []string {
+	visited := map[string]bool{term: true}
+	found := []string{term}
+	frontier := []string{term}
+	for hop := 0; hop < depth && len(frontier) > 0; hop++ {
+		var next []string
+		for _, t := range frontier {
+			for _, n := range g.adjacent[t] {
+				if !visited[n] {
+					visited[n] = true
+					found = append(found, n)
+					next = append(next, n)
+				}
+			}
+		}
+		frontier = next
+	}
+	return found
+}
+
+// shortestPath returns the shortest chain of terms from one term to another,
+// following references in either direction, or nil when they are unconnected.
+func (g *termGraph) shortestPath(from, to string) []string {
+	previous := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		if t == to {
+			var path []string
+			for ; t != ""; t = previous[t] {
+				path = append([]string{t}, path...)
+			}
+			return path
+		}
+		for _, n := range g.adjacent[t] {
+			if _, ok := previous[n]; !ok {
+				previous[n] = t
+				queue = append(queue, n)
+			}
+		}
+	}
+	return nil
+}
+
+// subgraph returns the nodes for terms and the edges among them.
+func (g *termGraph) subgraph(terms []string) OutputGetTermRelationships {
+	include := map[string]bool{}
+	for _, t := range terms {
+		include[t] = true
+	}
+	output := OutputGetTermRelationships{Nodes: []TermNode{}, Edges: []TermEdge{}}
+	for _, node := range g.nodes {
+		if include[node.Term] {
+			output.Nodes = append(output.Nodes, node)
+		}
+	}
+	for _, edge := range g.edges {
+		if include[edge.From] && include[edge.To] {
+			output.Edges = append(output.Edges, edge)
+		}
+	}
+	return output
+}
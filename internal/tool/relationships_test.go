@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const relationshipsTestLexicon = `- term: Guidance
+  definition: Best practices that inform a Control Catalog.
+- term: Control
+  definition: A safeguard described in Layer 2.
+- term: Control Catalog
+  definition: A collection of controls grouped into families.
+- term: Policy
+  definition: Organizational rules that select controls from a Control Catalog.
+- term: Assessment
+  definition: A check of whether a Policy requirement is met.
+- term: Evaluation
+  definition: The process of running each Assessment and recording results.
+- term: Glossary
+  definition: A list of words.
+`
+
+// useTestLexicon serves lexicon content from a local file as DefaultLexicon
+// for the duration of a test.
+func useTestLexicon(t *testing.T, content string) *LexiconService {
+	t.Helper()
+	dir := t.TempDir()
+	writeTestFile(t, dir, "lexicon.yaml", content)
+
+	original := DefaultLexicon
+	t.Cleanup(func() { DefaultLexicon = original })
+	DefaultLexicon = NewLexiconService([]string{fileURL(filepath.Join(dir, "lexicon.yaml"))}, LexiconConflictOverride)
+	return DefaultLexicon
+}
+
+func TestGetTermRelationships(t *testing.T) {
+	useTestLexicon(t, relationshipsTestLexicon)
+
+	tests := []struct {
+		name           string
+		input          InputGetTermRelationships
+		wantErr        bool
+		errContains    string
+		validateOutput func(t *testing.T, output OutputGetTermRelationships)
+	}{
+		{
+			name:  "full graph",
+			input: InputGetTermRelationships{},
+			validateOutput: func(t *testing.T, output OutputGetTermRelationships) {
+				assert.Len(t, output.Nodes, 7)
+				assert.Contains(t, output.Edges, TermEdge{From: "Guidance", To: "Control Catalog"})
+				assert.NotContains(t, output.Edges, TermEdge{From: "Guidance", To: "Control"}, "the longest matching term should win")
+				assert.Contains(t, output.Edges, TermEdge{From: "Control Catalog", To: "Control"}, "plurals should match")
+				assert.NotContains(t, output.Edges, TermEdge{From: "Control Catalog", To: "Control Catalog"}, "self references should be dropped")
+				assert.Contains(t, output.Nodes, TermNode{Term: "Control Catalog", Layer: 2, LayerName: "Controls"})
+				assert.Contains(t, output.Nodes, TermNode{Term: "Assessment"}, "terms without a layer should not be annotated")
+			},
+		},
+		{
+			name:  "explicit layer mention",
+			input: InputGetTermRelationships{Term: "control", Depth: 1},
+			validateOutput: func(t *testing.T, output OutputGetTermRelationships) {
+				assert.Contains(t, output.Nodes, TermNode{Term: "Control", Layer: 2, LayerName: "Controls"})
+				assert.Contains(t, output.Message, "within 1 hop(s) of Control")
+			},
+		},
+		{
+			name:  "neighborhood follows both directions",
+			input: InputGetTermRelationships{Term: "Assessment"},
+			validateOutput: func(t *testing.T, output OutputGetTermRelationships) {
+				terms := make([]string, 0, len(output.Nodes))
+				for _, node := range output.Nodes {
+					terms = append(terms, node.Term)
+				}
+				assert.ElementsMatch(t, []string{"Policy", "Assessment", "Evaluation"}, terms)
+				assert.Len(t, output.Edges, 2)
+			},
+		},
+		{
+			name:  "path between terms",
+			input: InputGetTermRelationships{Term: "Evaluation", RelatedTo: "Control Catalog"},
+			validateOutput: func(t *testing.T, output OutputGetTermRelationships) {
+				assert.Equal(t, []string{"Evaluation", "Assessment", "Policy", "Control Catalog"}, output.Path)
+				assert.Len(t, output.Edges, 3)
+				assert.Contains(t, output.Message, "through 3 reference(s)")
+			},
+		},
+		{
+			name:  "unconnected terms",
+			input: InputGetTermRelationships{Term: "Glossary", RelatedTo: "Policy"},
+			validateOutput: func(t *testing.T, output OutputGetTermRelationships) {
+				assert.Empty(t, output.Path)
+				assert.Contains(t, output.Message, "not connected")
+			},
+		},
+		{
+			name:        "unknown term",
+			input:       InputGetTermRelationships{Term: "Widget"},
+			wantErr:     true,
+			errContains: "not in the lexicon",
+		},
+		{
+			name:        "related_to without term",
+			input:       InputGetTermRelationships{RelatedTo: "Policy"},
+			wantErr:     true,
+			errContains: "term is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := GetTermRelationships(context.Background(), nil, tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			tt.validateOutput(t, output)
+		})
+	}
+}
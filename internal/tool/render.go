@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// AudienceAgent renders tool results as terse JSON for programmatic consumers.
+	AudienceAgent = "agent"
+	// AudienceHuman renders tool results as annotated text for people reading them directly.
+	AudienceHuman = "human"
+)
+
+// Audiences returns the supported result audiences.
+func Audiences() []string {
+	return []string{AudienceAgent, AudienceHuman}
+}
+
+// ValidateAudience returns an error if audience is not supported.
+func ValidateAudience(audience string) error {
+	for _, a := range Audiences() {
+		if a == audience {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown audience %q (available: %s)", audience, strings.Join(Audiences(), ", "))
+}
+
+// renderedHandler wraps a tool handler so its output is rendered for the configured
+// audience, followed by the provenance of the call. Handlers that build their own
+// result content are left untouched. Provenance is also attached as result metadata.
+func renderedHandler[In, Out any](h mcp.ToolHandlerFor[In, Out]) mcp.ToolHandlerFor[In, Out] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		ctx, recorder := withProvenance(ctx)
+		result, output, err := h(ctx, req, input)
+		if err != nil {
+			return result, output, err
+		}
+
+		provenance := recorder.provenance()
+		if result == nil {
+			result = &mcp.CallToolResult{}
+		}
+		if result.Meta == nil {
+			result.Meta = mcp.Meta{}
+		}
+		result.Meta["provenance"] = provenance
+		if result.Content != nil {
+			return result, output, nil
+		}
+
+		text, err := renderOutput(serverConfig(ctx).Audience, provenancedOutput{output: output, provenance: provenance})
+		if err != nil {
+			return nil, output, err
+		}
+		result.Content = []mcp.Content{&mcp.TextContent{Text: text}}
+		return result, output, nil
+	}
+}
+
+// renderOutput formats a tool output for the given audience.
+func renderOutput(audience string, output interface{}) (string, error) {
+	raw, err := json.Marshal(output)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal tool output: %w", err)
+	}
+	if audience != AudienceHuman {
+		return string(raw), nil
+	}
+
+	// JSON is valid YAML; decoding it as ordered maps keeps the field order of the output type
+	var value interface{}
+	if err := yaml.UnmarshalWithOptions(raw, &value, yaml.UseOrderedMap()); err != nil {
+		return "", fmt.Errorf("failed to decode tool output: %w", err)
+	}
+
+	var b strings.Builder
+	writeAnnotated(&b, value, 0)
+	return strings.TrimRight(b.String(), "\n") + "\n", nil
+}
+
+// writeAnnotated writes value as indented, labelled text.
+func writeAnnotated(b *strings.Builder, value interface{}, depth int) {
+	indent := strings.Repeat("  ", depth)
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		for _, item := range v {
+			label := humanizeKey(fmt.Sprint(item.Key))
+			if isCompound(item.Value) {
+				if isEmpty(item.Value) {
+					fmt.Fprintf(b, "%s%s: none\n", indent, label)
+					continue
+				}
+				fmt.Fprintf(b, "%s%s:\n", indent, label)
+				writeAnnotated(b, item.Value, depth+1)
+				continue
+			}
+			writeScalar(b, indent, label+": ", item.Value)
+		}
+	case []interface{}:
+		for i, elem := range v {
+			if isCompound(elem) {
+				fmt.Fprintf(b, "%s%d.\n", indent, i+1)
+				writeAnnotated(b, elem, depth+1)
+				continue
+			}
+			writeScalar(b, indent, "- ", elem)
+		}
+	default:
+		writeScalar(b, indent, "", v)
+	}
+}
+
+// writeScalar writes a labelled scalar, moving multi-line text into an indented block.
+func writeScalar(b *strings.Builder, indent, label string, value interface{}) {
+	s := scalarText(value)
+	if !strings.Contains(s, "\n") {
+		fmt.Fprintf(b, "%s%s%s\n", indent, label, s)
+		return
+	}
+	fmt.Fprintf(b, "%s%s\n", indent, strings.TrimSpace(label))
+	for _, line := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		fmt.Fprintf(b, "%s    %s\n", indent, line)
+	}
+}
+
+func scalarText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "none"
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func isCompound(value interface{}) bool {
+	switch value.(type) {
+	case yaml.MapSlice, []interface{}:
+		return true
+	}
+	return false
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// humanizeKey turns a JSON field name such as "module_version" into "Module version".
+func humanizeKey(key string) string {
+	words := strings.FieldsFunc(key, func(r rune) bool { return r == '_' || r == '-' })
+	if len(words) == 0 {
+		return key
+	}
+	label := strings.Join(words, " ")
+	return strings.ToUpper(label[:1]) + label[1:]
+}
@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderOutput(t *testing.T) {
+	output := OutputRunConformanceSuite{
+		Directory:     "artifacts",
+		ModuleVersion: "v0.0.0-test",
+		Total:         1,
+		Failed:        1,
+		Results: []ConformanceResult{
+			{Path: "catalog.yaml", SchemaErrors: []string{"missing field"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		audience string
+		want     string
+	}{
+		{
+			name:     "agent",
+			audience: AudienceAgent,
+			want:     `{"directory":"artifacts","module_version":"v0.0.0-test","conformant":false,"total":1,"passed":0,"failed":1,"results":[{"path":"catalog.yaml","schema_valid":false,"schema_errors":["missing field"],"conformant":false}]}`,
+		},
+		{
+			name:     "human",
+			audience: AudienceHuman,
+			want: `Directory: artifacts
+Module version: v0.0.0-test
+Conformant: no
+Total: 1
+Passed: 0
+Failed: 1
+Results:
+  1.
+    Path: catalog.yaml
+    Schema valid: no
+    Schema errors:
+      - missing field
+    Conformant: no
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := renderOutput(tt.audience, output)
+			require.NoError(t, err, "should render output")
+			assert.Equal(t, tt.want, text, "rendered output should match")
+		})
+	}
+}
+
+func TestRenderOutputMultiline(t *testing.T) {
+	text, err := renderOutput(AudienceHuman, OutputFixGemaraArtifact{
+		Definition:   "#ControlCatalog",
+		FixedContent: "title: Test\ncontrols: []\n",
+		Changes:      []FixChange{},
+		Valid:        true,
+	})
+	require.NoError(t, err, "should render output")
+	assert.Contains(t, text, "Fixed content:\n    title: Test\n    controls: []\n", "multi-line text should be indented as a block")
+	assert.Contains(t, text, "Changes: none", "empty lists should be annotated")
+}
+
+func TestRenderedHandler(t *testing.T) {
+	ctx := withTestConfig(func(c *Config) { c.Audience = AudienceHuman })
+
+	handler := renderedHandler(func(_ context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, OutputGetLexicon, error) {
+		return nil, OutputGetLexicon{}, nil
+	})
+	result, _, err := handler(ctx, nil, struct{}{})
+	require.NoError(t, err, "should not return error")
+	require.Len(t, result.Content, 1, "should render a single text block")
+	assert.IsType(t, &mcp.TextContent{}, result.Content[0], "content should be text")
+}
+
+func TestValidateAudience(t *testing.T) {
+	assert.NoError(t, ValidateAudience(AudienceHuman))
+	assert.ErrorContains(t, ValidateAudience("robot"), "unknown audience")
+}
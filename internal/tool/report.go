@@ -0,0 +1,381 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	reportFormatHTML = "html"
+	reportFormatPDF  = "pdf"
+)
+
+// MetadataGenerateComplianceReport describes the GenerateComplianceReport tool.
+var MetadataGenerateComplianceReport = &mcp.Tool{
+	Name: "generate_compliance_report",
+	Description: "Produce a self-contained compliance report from one or more EvaluationLogs, with pass/fail charts " +
+		"a detail section per control, and the findings past their remediation due date under the SLA policy, as HTML " +
+		"or PDF for handing to auditors.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"artifacts": artifactInputSchema,
+			"directory": map[string]interface{}{
+				"type":        "string",
+				"description": "Directory whose evaluation logs to report on when artifacts is omitted (default: the workspace root)",
+			},
+			"title": map[string]interface{}{
+				"type":        "string",
+				"description": "Report title (default: Compliance Report)",
+			},
+			"format": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{reportFormatHTML, reportFormatPDF},
+				"description": "Report format: html (default) or pdf, returned base64-encoded",
+			},
+		},
+	},
+}
+
+// InputGenerateComplianceReport is the input for the GenerateComplianceReport tool.
+type InputGenerateComplianceReport struct {
+	Artifacts []ArtifactInput `json:"artifacts,omitempty"`
+	Directory string          `json:"directory,omitempty"`
+	Title     string          `json:"title,omitempty"`
+	Format    string          `json:"format,omitempty"`
+}
+
+// OutputGenerateComplianceReport is the output for the GenerateComplianceReport tool.
+type OutputGenerateComplianceReport struct {
+	Format string `json:"format"`
+	HTML   string `json:"html,omitempty"`
+	// PDF is encoded as base64 in JSON.
+	PDF      []byte         `json:"pdf,omitempty"`
+	Summary  map[string]int `json:"summary"`
+	Controls int            `json:"controls"`
+	// OverdueFindings counts the findings past their due date when the report was generated.
+	OverdueFindings int    `json:"overdue_findings"`
+	Message         string `json:"message"`
+}
+
+// complianceReport is the data a report is rendered from.
+type complianceReport struct {
+	Title     string
+	Generated string
+	Sources   []string
+	Total     int
+	Results   []reportResult
+	Catalogs  []reportCatalog
+	Controls  []reportControl
+	// Overdue are the findings past their due date, most overdue first.
+	Overdue []Finding
+}
+
+// reportResult is the number of controls with one result.
+type reportResult struct {
+	Result  string
+	Count   int
+	Percent float64
+	Color   string
+}
+
+// reportCatalog summarizes the results of the controls of one catalog.
+type reportCatalog struct {
+	Catalog string
+	Total   int
+	Results []reportResult
+}
+
+// reportControl is the evaluation of one control.
+type reportControl struct {
+	Catalog string
+	Control string
+	Name    string
+	Result  string
+	Color   string
+	Source  string
+	Logs    []reportLog
+}
+
+// reportLog is one assessment log of a control evaluation.
+type reportLog struct {
+	Requirement string
+	Result      string
+	Description string
+}
+
+// reportResultOrder fixes the order results are listed and charted in.
+var reportResultOrder = []string{"Passed", "Failed", "Needs Review", "Not Applicable"}
+
+// reportColors are the chart colors of each result.
+var reportColors = map[string]string{
+	"Passed":         "#2e7d32",
+	"Failed":         "#c62828",
+	"Needs Review":   "#f9a825",
+	"Not Applicable": "#9e9e9e",
+}
+
+// reportOtherColor charts results without a color of their own.
+const reportOtherColor = "#5c6bc0"
+
+// GenerateComplianceReport renders the evaluation logs among the artifacts as a report.
+func GenerateComplianceReport(ctx context.Context, _ *mcp.CallToolRequest, input InputGenerateComplianceReport) (*mcp.CallToolResult, OutputGenerateComplianceReport, error) {
+	format := input.Format
+	if format == "" {
+		format = reportFormatHTML
+	}
+	if format != reportFormatHTML && format != reportFormatPDF {
+		return nil, OutputGenerateComplianceReport{}, fmt.Errorf("unsupported format %q: must be %q or %q", format, reportFormatHTML, reportFormatPDF)
+	}
+	artifacts := input.Artifacts
+	if len(artifacts) == 0 {
+		var err error
+		artifacts, err = directoryArtifacts(ctx, input.Directory)
+		if err != nil {
+			return nil, OutputGenerateComplianceReport{}, err
+		}
+	}
+
+	title := input.Title
+	if title == "" {
+		title = "Compliance Report"
+	}
+	report, err := buildComplianceReport(ctx, title, artifacts, time.Now().UTC())
+	if err != nil {
+		return nil, OutputGenerateComplianceReport{}, err
+	}
+
+	output := OutputGenerateComplianceReport{Format: format, Summary: map[string]int{}, Controls: report.Total, OverdueFindings: len(report.Overdue)}
+	for _, r := range report.Results {
+		output.Summary[r.Result] = r.Count
+	}
+	switch format {
+	case reportFormatPDF:
+		output.PDF = renderReportPDF(report)
+	default:
+		html, err := renderReportHTML(report)
+		if err != nil {
+			return nil, OutputGenerateComplianceReport{}, err
+		}
+		output.HTML = html
+	}
+	output.Message = fmt.Sprintf("Reported on %d control(s) and %d overdue finding(s) from %d evaluation log(s) as %s",
+		report.Total, len(report.Overdue), len(report.Sources), format)
+	return nil, output, nil
+}
+
+// buildComplianceReport collects the control evaluations of the evaluation
+// logs among the artifacts, and the findings overdue at generated. A control
+// evaluated in several logs is reported with its latest evaluation.
+func buildComplianceReport(ctx context.Context, title string, artifacts []ArtifactInput, generated time.Time) (complianceReport, error) {
+	report := complianceReport{Title: title, Generated: generated.Format(time.RFC3339)}
+
+	type evaluated struct {
+		control reportControl
+		at      time.Time
+	}
+	latest := map[string]evaluated{}
+	for i, a := range artifacts {
+		doc, err := parseArtifact(ctx, a.Content)
+		if err != nil {
+			return complianceReport{}, &artifactError{Name: artifactName(a, i), Err: err}
+		}
+		if artifactKind(doc) != "EvaluationLog" {
+			continue
+		}
+		name := artifactName(a, i)
+		report.Sources = append(report.Sources, name)
+
+		for _, e := range mapList(doc["evaluations"]) {
+			mapping, _ := e["control"].(map[string]interface{})
+			control := reportControl{
+				Catalog: stringField(mapping, "reference-id"),
+				Control: mappingEntryID(mapping),
+				Name:    stringField(e, "name"),
+				Result:  stringField(e, "result"),
+				Source:  name,
+			}
+			if control.Control == "" || control.Result == "" {
+				continue
+			}
+			control.Color = reportColor(control.Result)
+			for _, l := range mapList(e["assessment-logs"]) {
+				control.Logs = append(control.Logs, reportLog{
+					Requirement: mappingEntryID(l["requirement"]),
+					Result:      stringField(l, "result"),
+					Description: stringField(l, "description"),
+				})
+			}
+
+			key := control.Catalog + "|" + control.Control
+			at := evaluationTime(e)
+			if previous, ok := latest[key]; ok && previous.at.After(at) {
+				continue
+			}
+			latest[key] = evaluated{control: control, at: at}
+		}
+	}
+	if len(report.Sources) == 0 {
+		return complianceReport{}, fmt.Errorf("no evaluation logs found in the supplied artifacts")
+	}
+
+	byCatalog := map[string][]reportControl{}
+	for _, e := range latest {
+		report.Controls = append(report.Controls, e.control)
+		byCatalog[e.control.Catalog] = append(byCatalog[e.control.Catalog], e.control)
+	}
+	sort.Slice(report.Controls, func(i, j int) bool {
+		if report.Controls[i].Catalog != report.Controls[j].Catalog {
+			return report.Controls[i].Catalog < report.Controls[j].Catalog
+		}
+		return report.Controls[i].Control < report.Controls[j].Control
+	})
+	report.Total = len(report.Controls)
+	report.Results = countReportResults(report.Controls)
+
+	catalogs := make([]string, 0, len(byCatalog))
+	for catalog := range byCatalog {
+		catalogs = append(catalogs, catalog)
+	}
+	sort.Strings(catalogs)
+	for _, catalog := range catalogs {
+		controls := byCatalog[catalog]
+		report.Catalogs = append(report.Catalogs, reportCatalog{Catalog: catalog, Total: len(controls), Results: countReportResults(controls)})
+	}
+
+	findings, err := collectFindings(ctx, artifacts, serverConfig(ctx).FindingSLA)
+	if err != nil {
+		return complianceReport{}, err
+	}
+	report.Overdue = overdueFindings(findings, generated)
+	return report, nil
+}
+
+// countReportResults counts the controls per result, in reportResultOrder
+// followed by any other results alphabetically.
+func countReportResults(controls []reportControl) []reportResult {
+	counts := map[string]int{}
+	for _, c := range controls {
+		counts[c.Result]++
+	}
+	var others []string
+	for result := range counts {
+		if _, known := reportColors[result]; !known {
+			others = append(others, result)
+		}
+	}
+	sort.Strings(others)
+
+	var results []reportResult
+	for _, result := range append(append([]string{}, reportResultOrder...), others...) {
+		if counts[result] == 0 {
+			continue
+		}
+		percent := math.Round(float64(counts[result])/float64(len(controls))*1000) / 10
+		results = append(results, reportResult{Result: result, Count: counts[result], Percent: percent, Color: reportColor(result)})
+	}
+	return results
+}
+
+func reportColor(result string) string {
+	if color, ok := reportColors[result]; ok {
+		return color
+	}
+	return reportOtherColor
+}
+
+// reportTemplate renders a report as a single HTML page with inline styles
+// and SVG charts, so it can be opened or archived without other files.
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"bars": reportBars,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem auto; max-width: 960px; color: #212121; }
+table { border-collapse: collapse; width: 100%; margin: 0.5rem 0 1.5rem; }
+th, td { border: 1px solid #e0e0e0; padding: 0.4rem 0.6rem; text-align: left; vertical-align: top; }
+th { background: #f5f5f5; }
+.badge { color: #fff; border-radius: 4px; padding: 0.1rem 0.5rem; font-size: 0.85rem; white-space: nowrap; }
+.muted { color: #757575; font-size: 0.9rem; }
+section.control { border-top: 1px solid #e0e0e0; padding-top: 0.5rem; page-break-inside: avoid; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="muted">Generated {{.Generated}} from {{len .Sources}} evaluation log(s): {{range $i, $s := .Sources}}{{if $i}}, {{end}}{{$s}}{{end}}</p>
+
+<h2>Summary</h2>
+<svg width="720" height="40" role="img" aria-label="Results of {{.Total}} controls">{{bars .Results 720 32}}</svg>
+<table>
+<tr><th>Result</th><th>Controls</th><th>Share</th></tr>
+{{range .Results}}<tr><td><span class="badge" style="background: {{.Color}}">{{.Result}}</span></td><td>{{.Count}}</td><td>{{.Percent}}%</td></tr>
+{{end}}<tr><th>Total</th><th>{{.Total}}</th><th></th></tr>
+</table>
+
+<h2>By Catalog</h2>
+<table>
+<tr><th>Catalog</th><th>Controls</th><th>Results</th></tr>
+{{range .Catalogs}}<tr><td>{{.Catalog}}</td><td>{{.Total}}</td><td><svg width="480" height="20" role="img" aria-label="Results for {{.Catalog}}">{{bars .Results 480 20}}</svg></td></tr>
+{{end}}</table>
+
+<h2>Overdue Findings</h2>
+{{if .Overdue}}<table>
+<tr><th>Control</th><th>Requirement</th><th>Result</th><th>Severity</th><th>Due</th><th>Days overdue</th><th>Recorded in</th></tr>
+{{range .Overdue}}<tr><td>{{.Control}}</td><td>{{.Requirement}}</td><td>{{.Result}}</td><td>{{.Severity}}</td><td>{{.Due}}</td><td>{{.DaysOverdue}}</td><td>{{.Artifact}}</td></tr>
+{{end}}</table>
+{{else}}<p>No findings are past their remediation due date.</p>
+{{end}}
+<h2>Controls</h2>
+{{range .Controls}}<section class="control" id="{{.Catalog}}-{{.Control}}">
+<h3>{{.Control}}{{if .Name}}: {{.Name}}{{end}} <span class="badge" style="background: {{.Color}}">{{.Result}}</span></h3>
+<p class="muted">Catalog {{.Catalog}} · recorded in {{.Source}}</p>
+{{if .Logs}}<table>
+<tr><th>Requirement</th><th>Result</th><th>Description</th></tr>
+{{range .Logs}}<tr><td>{{.Requirement}}</td><td>{{.Result}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>
+{{end}}</section>
+{{end}}
+</body>
+</html>
+`))
+
+// reportBars draws results as a stacked horizontal bar of the given size.
+func reportBars(results []reportResult, width, height int) template.HTML {
+	total := 0
+	for _, r := range results {
+		total += r.Count
+	}
+	if total == 0 {
+		return ""
+	}
+	var b bytes.Buffer
+	x := 0.0
+	for _, r := range results {
+		w := float64(width) * float64(r.Count) / float64(total)
+		fmt.Fprintf(&b, `<rect x="%.1f" y="0" width="%.1f" height="%d" fill="%s"><title>%s: %d</title></rect>`,
+			x, w, height, r.Color, template.HTMLEscapeString(r.Result), r.Count)
+		x += w
+	}
+	return template.HTML(b.String())
+}
+
+// renderReportHTML renders a report as a self-contained HTML page.
+func renderReportHTML(report complianceReport) (string, error) {
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, report); err != nil {
+		return "", fmt.Errorf("failed to render report: %w", err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,234 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PDF page geometry in points (A4).
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 50
+	pdfBodyWidth  = pdfPageWidth - 2*pdfMargin
+)
+
+// Fonts are the standard Helvetica faces every PDF reader provides, so
+// nothing needs to be embedded.
+const (
+	pdfFontRegular = "F1"
+	pdfFontBold    = "F2"
+)
+
+// pdfLayout lays out text and bars top to bottom, starting a new page when
+// the current one is full.
+type pdfLayout struct {
+	pages []*bytes.Buffer
+	y     float64
+}
+
+func newPDFLayout() *pdfLayout {
+	l := &pdfLayout{}
+	l.newPage()
+	return l
+}
+
+func (l *pdfLayout) newPage() {
+	l.pages = append(l.pages, &bytes.Buffer{})
+	l.y = pdfPageHeight - pdfMargin
+}
+
+func (l *pdfLayout) page() *bytes.Buffer {
+	return l.pages[len(l.pages)-1]
+}
+
+// reserve starts a new page unless height points fit on the current one.
+func (l *pdfLayout) reserve(height float64) {
+	if l.y-height < pdfMargin {
+		l.newPage()
+	}
+}
+
+// text writes text in the given font and size, wrapped to the body width.
+func (l *pdfLayout) text(font string, size float64, color, text string) {
+	// Helvetica averages about half an em per character
+	perLine := int(pdfBodyWidth / (size * 0.5))
+	for _, line := range wrapText(text, perLine) {
+		l.reserve(size * 1.4)
+		l.y -= size * 1.4
+		fmt.Fprintf(l.page(), "%s rg BT /%s %.1f Tf %d %.1f Td (%s) Tj ET\n",
+			pdfColor(color), font, size, pdfMargin, l.y, pdfEscape(line))
+	}
+}
+
+// space moves down by height points.
+func (l *pdfLayout) space(height float64) {
+	l.y -= height
+}
+
+// bars draws results as a stacked horizontal bar across the body width.
+func (l *pdfLayout) bars(results []reportResult, height float64) {
+	total := 0
+	for _, r := range results {
+		total += r.Count
+	}
+	if total == 0 {
+		return
+	}
+	l.reserve(height + 4)
+	l.y -= height + 4
+	x := float64(pdfMargin)
+	for _, r := range results {
+		w := pdfBodyWidth * float64(r.Count) / float64(total)
+		fmt.Fprintf(l.page(), "%s rg %.1f %.1f %.1f %.1f re f\n", pdfColor(r.Color), x, l.y, w, height)
+		x += w
+	}
+}
+
+// renderReportPDF renders a report as a PDF document.
+func renderReportPDF(report complianceReport) []byte {
+	const black, grey = "#212121", "#757575"
+	l := newPDFLayout()
+
+	l.text(pdfFontBold, 20, black, report.Title)
+	l.text(pdfFontRegular, 9, grey, fmt.Sprintf("Generated %s from %d evaluation log(s): %s",
+		report.Generated, len(report.Sources), strings.Join(report.Sources, ", ")))
+	l.space(10)
+
+	l.text(pdfFontBold, 14, black, "Summary")
+	l.bars(report.Results, 18)
+	for _, r := range report.Results {
+		l.text(pdfFontRegular, 10, r.Color, fmt.Sprintf("%s: %d (%.1f%%)", r.Result, r.Count, r.Percent))
+	}
+	l.text(pdfFontBold, 10, black, fmt.Sprintf("Total: %d", report.Total))
+	l.space(10)
+
+	l.text(pdfFontBold, 14, black, "By Catalog")
+	for _, c := range report.Catalogs {
+		l.text(pdfFontRegular, 10, black, fmt.Sprintf("%s (%d controls)", c.Catalog, c.Total))
+		l.bars(c.Results, 10)
+		l.space(4)
+	}
+	l.space(10)
+
+	l.text(pdfFontBold, 14, black, "Overdue Findings")
+	if len(report.Overdue) == 0 {
+		l.text(pdfFontRegular, 10, black, "No findings are past their remediation due date.")
+	}
+	for _, f := range report.Overdue {
+		line := f.Control
+		if f.Requirement != "" {
+			line += " / " + f.Requirement
+		}
+		l.text(pdfFontRegular, 10, reportColor(f.Result), fmt.Sprintf("%s - %s, %s severity, due %s (%d days overdue), recorded in %s",
+			line, f.Result, f.Severity, f.Due, f.DaysOverdue, f.Artifact))
+	}
+	l.space(10)
+
+	l.text(pdfFontBold, 14, black, "Controls")
+	for _, c := range report.Controls {
+		l.reserve(60)
+		l.space(6)
+		heading := c.Control
+		if c.Name != "" {
+			heading += ": " + c.Name
+		}
+		l.text(pdfFontBold, 11, black, heading)
+		l.text(pdfFontBold, 10, c.Color, c.Result)
+		l.text(pdfFontRegular, 9, grey, fmt.Sprintf("Catalog %s, recorded in %s", c.Catalog, c.Source))
+		for _, log := range c.Logs {
+			line := fmt.Sprintf("%s - %s", log.Requirement, log.Result)
+			if log.Description != "" {
+				line += ": " + log.Description
+			}
+			l.text(pdfFontRegular, 9, black, line)
+		}
+	}
+
+	return writePDF(l.pages)
+}
+
+// writePDF assembles page content streams into a PDF document.
+func writePDF(pages []*bytes.Buffer) []byte {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+	// Objects 1-4 are the catalog, page tree, and fonts; each page then takes
+	// two objects, the page and its content stream
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, content := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, pdfFontRegular, pdfFontBold, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+// wrapText splits text into lines of at most width characters at spaces.
+func wrapText(text string, width int) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return nil
+	}
+	var lines []string
+	line := words[0]
+	for _, word := range words[1:] {
+		if len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+		line += " " + word
+	}
+	return append(lines, line)
+}
+
+// pdfEscape makes text safe inside a PDF string literal. Characters outside
+// printable ASCII are replaced, as the standard fonts are not embedded.
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// pdfColor converts a #rrggbb color to PDF RGB components.
+func pdfColor(hex string) string {
+	value, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil {
+		return "0 0 0"
+	}
+	return fmt.Sprintf("%.3f %.3f %.3f", float64(value>>16&0xff)/255, float64(value>>8&0xff)/255, float64(value&0xff)/255)
+}
@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reportRescan re-evaluates CCC.C06 after the test evaluation log, and adds a
+// control that needs review.
+const reportRescan = `metadata:
+  id: EVAL-2025-02
+  description: Rescan
+  author: {id: scanner, name: Scanner, type: Software}
+evaluations:
+  - name: Prevent Deployment in Restricted Regions
+    result: Passed
+    end: 2025-02-01T00:00:00Z
+    control: {reference-id: FINOS-CCC, entry-id: CCC.C06}
+  - name: "Review <script>alert(1)</script>"
+    result: Needs Review
+    control: {reference-id: OTHER, entry-id: OTH.C01}
+`
+
+// reportOverdue records a critical finding whose 7 day SLA ended long ago.
+const reportOverdue = `metadata:
+  id: EVAL-2025-03
+  description: Audit logging scan
+  author: {id: scanner, name: Scanner, type: Software}
+evaluations:
+  - name: Audit Logging
+    result: Failed
+    end: 2025-03-01T00:00:00Z
+    control: {reference-id: FINOS-CCC, entry-id: CCC.C10}
+    assessment-logs:
+      - requirement: {reference-id: FINOS-CCC, entry-id: CCC.C10.TR01}
+        result: Failed
+        severity: critical
+        description: Audit logging disabled
+`
+
+func TestGenerateComplianceReport(t *testing.T) {
+	logs := append(loadTestArtifacts(t, "evaluation-log.yaml", "good-ccc.yaml"), ArtifactInput{Name: "rescan.yaml", Content: reportRescan})
+
+	tests := []struct {
+		name           string
+		input          InputGenerateComplianceReport
+		errContains    string
+		validateOutput func(t *testing.T, output OutputGenerateComplianceReport)
+	}{
+		{
+			name:  "html",
+			input: InputGenerateComplianceReport{Artifacts: logs, Title: "Q1 Audit"},
+			validateOutput: func(t *testing.T, output OutputGenerateComplianceReport) {
+				assert.Equal(t, reportFormatHTML, output.Format)
+				assert.Equal(t, 3, output.Controls, "each control should be reported once")
+				assert.Equal(t, map[string]int{"Passed": 2, "Needs Review": 1}, output.Summary,
+					"the latest evaluation of a control should win")
+				assert.Empty(t, output.PDF)
+				assert.True(t, strings.HasPrefix(output.HTML, "<!DOCTYPE html>"), "should be a complete page")
+				assert.Contains(t, output.HTML, "<title>Q1 Audit</title>")
+				assert.Contains(t, output.HTML, "<svg", "should chart the results")
+				assert.Contains(t, output.HTML, `id="FINOS-CCC-CCC.C01"`, "should have a section per control")
+				assert.Contains(t, output.HTML, "TLS 1.2 enforced on all listeners", "should include assessment logs")
+				assert.NotContains(t, output.HTML, "<script>", "artifact content should be escaped")
+				assert.NotContains(t, output.HTML, "good-ccc.yaml", "only evaluation logs should be sources")
+				assert.Zero(t, output.OverdueFindings)
+				assert.Contains(t, output.HTML, "No findings are past their remediation due date")
+			},
+		},
+		{
+			name:  "pdf",
+			input: InputGenerateComplianceReport{Artifacts: logs, Format: reportFormatPDF},
+			validateOutput: func(t *testing.T, output OutputGenerateComplianceReport) {
+				assert.Empty(t, output.HTML)
+				require.True(t, bytes.HasPrefix(output.PDF, []byte("%PDF-1.4")), "should be a PDF document")
+				assert.True(t, bytes.HasSuffix(output.PDF, []byte("%%EOF\n")), "should end with a trailer")
+				assert.Contains(t, string(output.PDF), "(Compliance Report) Tj", "should default the title")
+				assert.Contains(t, string(output.PDF), "(OTH.C01: Review <script>alert\\(1\\)</script>) Tj", "should escape parentheses")
+			},
+		},
+		{
+			name:  "overdue findings",
+			input: InputGenerateComplianceReport{Artifacts: append(logs, ArtifactInput{Name: "audit.yaml", Content: reportOverdue})},
+			validateOutput: func(t *testing.T, output OutputGenerateComplianceReport) {
+				assert.Equal(t, 1, output.OverdueFindings)
+				assert.Contains(t, output.Message, "1 overdue finding(s)")
+				assert.Contains(t, output.HTML, "<td>CCC.C10.TR01</td><td>Failed</td><td>critical</td><td>2025-03-08T00:00:00Z</td>",
+					"the due date should follow the SLA of the severity")
+				assert.NotContains(t, output.HTML, "No findings are past their remediation due date")
+			},
+		},
+		{
+			name:  "overdue findings pdf",
+			input: InputGenerateComplianceReport{Artifacts: append(logs, ArtifactInput{Name: "audit.yaml", Content: reportOverdue}), Format: reportFormatPDF},
+			validateOutput: func(t *testing.T, output OutputGenerateComplianceReport) {
+				assert.Contains(t, string(output.PDF), "(CCC.C10 / CCC.C10.TR01 - Failed, critical severity, due 2025-03-08T00:00:00Z")
+			},
+		},
+		{
+			name:        "unsupported format",
+			input:       InputGenerateComplianceReport{Artifacts: logs, Format: "docx"},
+			errContains: "unsupported format",
+		},
+		{
+			name:        "no evaluation logs",
+			input:       InputGenerateComplianceReport{Artifacts: loadTestArtifacts(t, "good-ccc.yaml")},
+			errContains: "no evaluation logs found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := GenerateComplianceReport(context.Background(), nil, tt.input)
+			if tt.errContains != "" {
+				require.Error(t, err, "should return error")
+				assert.Contains(t, err.Error(), tt.errContains, "error should contain expected message")
+				return
+			}
+			require.NoError(t, err, "should not return error")
+			tt.validateOutput(t, output)
+		})
+	}
+}
+
+func TestRenderReportPDFPagination(t *testing.T) {
+	report := complianceReport{Title: "Large", Results: []reportResult{{Result: "Passed", Count: 200, Color: reportColors["Passed"]}}}
+	for i := 0; i < 200; i++ {
+		report.Controls = append(report.Controls, reportControl{Control: "C", Result: "Passed", Color: reportColors["Passed"]})
+	}
+	pdf := string(renderReportPDF(report))
+	assert.Greater(t, strings.Count(pdf, "/Type /Page "), 1, "long reports should span several pages")
+	assert.Contains(t, pdf, "/Count "+strconv.Itoa(strings.Count(pdf, "/Type /Page ")), "page tree should count every page")
+}
+
+func TestWrapText(t *testing.T) {
+	assert.Equal(t, []string{"one two", "three"}, wrapText("one two three", 8))
+	assert.Nil(t, wrapText("  ", 8))
+}
@@ -0,0 +1,312 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/sync/singleflight"
+)
+
+// Statuses of a node of a resolved dependency tree.
+const (
+	RefResolved   = "resolved"
+	RefUnresolved = "unresolved"
+	RefCycle      = "cycle"
+	RefRepeated   = "repeated"
+	RefTooDeep    = "too-deep"
+)
+
+const (
+	defaultResolveDepth = 10
+	maxResolveDepth     = 25
+	resolveCacheTTL     = 15 * time.Minute
+	maxNodeErrors       = 5
+)
+
+// referenceCache keeps referenced artifacts by URI, so that resolving shared
+// dependencies, or resolving again soon after, does not fetch them again.
+type referenceCache struct {
+	sync.Mutex
+	entries map[string]resolvedArtifact
+	flight  singleflight.Group
+}
+
+var resolveCache = newReferenceCache()
+
+func newReferenceCache() *referenceCache {
+	return &referenceCache{entries: map[string]resolvedArtifact{}}
+}
+
+// workspaceReferenceCache returns the reference cache of the tenant of ctx,
+// or resolveCache. Tenants do not share file:// references, which were only
+// checked against the workspace root of the tenant that fetched them.
+func workspaceReferenceCache(ctx context.Context) *referenceCache {
+	if t := requestTenant(ctx); t != nil {
+		return t.references
+	}
+	return resolveCache
+}
+
+type resolvedArtifact struct {
+	content []byte
+	fetched time.Time
+}
+
+// fetchReference reads a referenced artifact: oci:// and https git:: sources as
+// federation sources are, and everything else as an artifact_uri.
+func fetchReference(ctx context.Context, uri string) ([]byte, error) {
+	cache := workspaceReferenceCache(ctx)
+	cache.Lock()
+	cached, ok := cache.entries[uri]
+	cache.Unlock()
+	if ok && time.Since(cached.fetched) < resolveCacheTTL {
+		return cached.content, nil
+	}
+
+	// Concurrent resolutions of a shared dependency fetch it once
+	content, _, err := sharedCall(ctx, &cache.flight, uri, func(ctx context.Context) ([]byte, error) {
+		var content []byte
+		var err error
+		switch {
+		case strings.HasPrefix(uri, ociSourceScheme):
+			content, err = fetchOCISource(ctx, strings.TrimPrefix(uri, ociSourceScheme))
+		case strings.HasPrefix(uri, gitSourcePrefix):
+			// Unlike federation sources, references come from tool input
+			// and artifact content, so only remote repositories are cloned
+			spec := strings.TrimPrefix(uri, gitSourcePrefix)
+			if err = validateGitRemote(spec); err == nil {
+				content, err = fetchGitSource(ctx, spec)
+			}
+		default:
+			content, err = readArtifactURI(ctx, uri)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := checkArtifactLimits(ctx, formatYAML, string(content)); err != nil {
+			return nil, err
+		}
+
+		cache.Lock()
+		cache.entries[uri] = resolvedArtifact{content: content, fetched: time.Now()}
+		cache.Unlock()
+		return content, nil
+	})
+	return content, err
+}
+
+// referenceURI resolves a reference against the URI of the artifact that makes
+// it. Relative references from inline artifacts resolve in the workspace root.
+func referenceURI(ctx context.Context, base, ref string) (string, error) {
+	if strings.HasPrefix(ref, gitSourcePrefix) || strings.Contains(ref, "://") {
+		return ref, nil
+	}
+	if base != "" && !strings.HasPrefix(base, ociSourceScheme) && !strings.HasPrefix(base, gitSourcePrefix) {
+		b, err := url.Parse(base)
+		if err == nil && (b.Scheme == "file" || b.Scheme == "https") {
+			r, err := url.Parse(ref)
+			if err != nil {
+				return "", fmt.Errorf("invalid reference %q: %w", ref, err)
+			}
+			return b.ResolveReference(r).String(), nil
+		}
+	}
+	root := workspaceRoot(ctx)
+	if root == "" {
+		return "", fmt.Errorf("relative reference %q needs a workspace root or a file:// or https:// parent", ref)
+	}
+	return "file://" + filepath.ToSlash(filepath.Join(root, filepath.FromSlash(ref))), nil
+}
+
+// MetadataResolveArtifactRefs describes the ResolveArtifactRefs tool.
+var MetadataResolveArtifactRefs = &mcp.Tool{
+	Name: "resolve_artifact_refs",
+	Description: "Resolve the artifacts a Gemara artifact references through the url of its metadata mapping-references, " +
+		"recursively, and return the dependency tree as a depth-first list of nodes with the index of their parent, " +
+		"with each node's kind, id, and schema validation status. " +
+		"References may be file:// (within the workspace root), https://, gemara://, oci://<reference>//<path>, " +
+		"git::https://<repository>//<path>?ref=<ref> (needs git), or relative to the referencing artifact. " +
+		"Cycles are detected and fetched artifacts are cached.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"artifact_content": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML content of the artifact whose references to resolve",
+			},
+			"artifact_uri": map[string]interface{}{
+				"type":        "string",
+				"description": "URI of the artifact instead of inline content; relative references resolve against it",
+			},
+			"max_depth": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"maximum":     maxResolveDepth,
+				"description": fmt.Sprintf("Levels of references to follow (default: %d)", defaultResolveDepth),
+			},
+		},
+	},
+}
+
+// InputResolveArtifactRefs is the input for the ResolveArtifactRefs tool.
+type InputResolveArtifactRefs struct {
+	ArtifactContent string `json:"artifact_content,omitempty"`
+	ArtifactURI     string `json:"artifact_uri,omitempty"`
+	MaxDepth        int    `json:"max_depth,omitempty"`
+}
+
+// RefNode is an artifact in a dependency tree. Nodes are listed depth first,
+// so every node follows its parent.
+type RefNode struct {
+	// Parent is the index of the node that references this one; -1 for the root.
+	Parent int `json:"parent"`
+	Depth  int `json:"depth"`
+	// Reference is the mapping-reference id the parent refers to this artifact by.
+	Reference string `json:"reference,omitempty"`
+	URI       string `json:"uri,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	ID        string `json:"id,omitempty"`
+	Status    string `json:"status"`
+	Valid     *bool  `json:"valid,omitempty"`
+	// Errors are the first schema validation errors of the artifact.
+	Errors []string `json:"errors,omitempty"`
+	// Error is why the artifact could not be resolved.
+	Error string `json:"error,omitempty"`
+}
+
+// OutputResolveArtifactRefs is the output for the ResolveArtifactRefs tool.
+type OutputResolveArtifactRefs struct {
+	Nodes      []RefNode `json:"nodes"`
+	Artifacts  int       `json:"artifacts"`
+	Invalid    int       `json:"invalid"`
+	Unresolved int       `json:"unresolved"`
+	Cycles     int       `json:"cycles"`
+	Message    string    `json:"message"`
+}
+
+// refResolver walks the references of an artifact depth first.
+type refResolver struct {
+	schema   *gemaraSchema
+	maxDepth int
+	// visiting holds the URIs on the current path, to detect cycles.
+	visiting map[string]bool
+	visited  map[string]bool
+	output   *OutputResolveArtifactRefs
+}
+
+// ResolveArtifactRefs returns the dependency tree of an artifact.
+func ResolveArtifactRefs(ctx context.Context, _ *mcp.CallToolRequest, input InputResolveArtifactRefs) (*mcp.CallToolResult, OutputResolveArtifactRefs, error) {
+	content, err := artifactInputContent(ctx, input.ArtifactContent, input.ArtifactURI)
+	if err != nil {
+		return nil, OutputResolveArtifactRefs{}, err
+	}
+	maxDepth := input.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = defaultResolveDepth
+	}
+	if maxDepth < 1 || maxDepth > maxResolveDepth {
+		return nil, OutputResolveArtifactRefs{}, fmt.Errorf("max_depth must be between 1 and %d", maxResolveDepth)
+	}
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputResolveArtifactRefs{}, err
+	}
+
+	output := OutputResolveArtifactRefs{Nodes: []RefNode{{Parent: -1, URI: input.ArtifactURI}}}
+	r := &refResolver{schema: schema, maxDepth: maxDepth, visiting: map[string]bool{}, visited: map[string]bool{}, output: &output}
+	r.resolve(ctx, 0, []byte(content))
+	if output.Nodes[0].Status == RefUnresolved {
+		return nil, OutputResolveArtifactRefs{}, fmt.Errorf("%s", output.Nodes[0].Error)
+	}
+	output.Message = fmt.Sprintf("Resolved %d artifact(s): %d invalid, %d unresolved, %d cycle(s)",
+		output.Artifacts, output.Invalid, output.Unresolved, output.Cycles)
+	return nil, output, nil
+}
+
+// resolve fills in the node at index from its content and appends the nodes
+// of its references.
+func (r *refResolver) resolve(ctx context.Context, index int, content []byte) {
+	node := &r.output.Nodes[index]
+	doc, err := parseArtifact(ctx, string(content))
+	if err != nil {
+		r.unresolved(node, err)
+		return
+	}
+	node.Status = RefResolved
+	node.Kind = artifactKind(doc)
+	metadata, _ := doc["metadata"].(map[string]interface{})
+	node.ID = stringField(metadata, "id")
+	r.output.Artifacts++
+
+	if node.Kind != "" {
+		validation, err := validateAgainstSchema(r.schema, normalizeDefinition(node.Kind), string(content))
+		if err == nil {
+			valid := validation.Valid
+			node.Valid = &valid
+			if len(validation.Errors) > maxNodeErrors {
+				validation.Errors = validation.Errors[:maxNodeErrors]
+			}
+			node.Errors = validation.Errors
+			if !valid {
+				r.output.Invalid++
+			}
+		}
+	}
+
+	base, depth := node.URI, node.Depth
+	if base != "" {
+		r.visiting[base] = true
+		r.visited[base] = true
+		defer delete(r.visiting, base)
+	}
+	for _, ref := range mapList(metadata["mapping-references"]) {
+		target := stringField(ref, "url")
+		if target == "" {
+			continue
+		}
+		// Appending may move the nodes, so children are addressed by index
+		r.output.Nodes = append(r.output.Nodes, RefNode{Parent: index, Depth: depth + 1, Reference: stringField(ref, "id")})
+		childIndex := len(r.output.Nodes) - 1
+		child := &r.output.Nodes[childIndex]
+
+		uri, err := referenceURI(ctx, base, target)
+		if err != nil {
+			r.unresolved(child, err)
+			continue
+		}
+		child.URI = uri
+		switch {
+		case r.visiting[uri]:
+			child.Status = RefCycle
+			r.output.Cycles++
+			continue
+		case r.visited[uri]:
+			// Shared dependencies are expanded once
+			child.Status = RefRepeated
+			continue
+		case depth+1 > r.maxDepth:
+			child.Status = RefTooDeep
+			continue
+		}
+		childContent, err := fetchReference(ctx, uri)
+		if err != nil {
+			r.unresolved(child, err)
+			continue
+		}
+		r.resolve(ctx, childIndex, childContent)
+	}
+}
+
+func (r *refResolver) unresolved(node *RefNode, err error) {
+	node.Status = RefUnresolved
+	node.Error = err.Error()
+	r.output.Unresolved++
+}
@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// refCatalog is a catalog that references the given URLs. Catalogs with
+// references do not validate against the test schema, whose metadata has no
+// mapping-references.
+func refCatalog(id string, urls ...string) string {
+	content := fmt.Sprintf("metadata:\n  id: %s\n  description: test\n  author:\n    id: a\n    name: A\n    type: Human\n", id)
+	if len(urls) > 0 {
+		content += "  mapping-references:\n"
+		for i, u := range urls {
+			content += fmt.Sprintf("    - id: REF%d\n      url: %s\n", i, u)
+		}
+	}
+	return content + "title: " + id + "\ncontrols: []\n"
+}
+
+func TestResolveArtifactRefs(t *testing.T) {
+	useTestSchema(t)
+	root := t.TempDir()
+	t.Cleanup(func() { resolveCache.entries = map[string]resolvedArtifact{} })
+
+	writeTestFile(t, root, "a.yaml", refCatalog("A", "deps/b.yaml", "deps/c.yaml"))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "deps"), 0o755))
+	writeTestFile(t, filepath.Join(root, "deps"), "b.yaml", refCatalog("B", "c.yaml", "../a.yaml"))
+	writeTestFile(t, filepath.Join(root, "deps"), "c.yaml", refCatalog("C"))
+	ctx := withTestConfig(func(c *Config) { c.WorkspaceRoot = root })
+	aURI := "file://" + filepath.ToSlash(filepath.Join(root, "a.yaml"))
+
+	_, output, err := ResolveArtifactRefs(ctx, nil, InputResolveArtifactRefs{ArtifactURI: aURI})
+	require.NoError(t, err)
+	assert.Equal(t, 3, output.Artifacts)
+	assert.Equal(t, 1, output.Cycles)
+	assert.Zero(t, output.Unresolved)
+
+	// a, b under a, c and the cycle to a under b, then c under a
+	nodes := output.Nodes
+	require.Len(t, nodes, 5)
+	a, b, leaf, cycle, c := nodes[0], nodes[1], nodes[2], nodes[3], nodes[4]
+	assert.Equal(t, "A", a.ID)
+	assert.Equal(t, "ControlCatalog", a.Kind)
+	assert.Equal(t, -1, a.Parent)
+	assert.Equal(t, "REF0", b.Reference)
+	assert.Equal(t, RefResolved, b.Status)
+	assert.Equal(t, 0, b.Parent)
+	assert.Equal(t, RefResolved, leaf.Status, "c is resolved first under b")
+	assert.Equal(t, 1, leaf.Parent)
+	assert.Equal(t, 2, leaf.Depth)
+	assert.Equal(t, RefCycle, cycle.Status, "b references a, which is being resolved")
+	assert.Equal(t, 1, cycle.Parent)
+	assert.Equal(t, RefRepeated, c.Status, "c was already expanded under b")
+	assert.Equal(t, 0, c.Parent)
+
+	assert.Equal(t, "C", leaf.ID)
+	require.NotNil(t, leaf.Valid)
+	assert.True(t, *leaf.Valid)
+	require.NotNil(t, a.Valid)
+	assert.False(t, *a.Valid, "mapping-references are not in the test schema")
+
+	_, shallow, err := ResolveArtifactRefs(ctx, nil, InputResolveArtifactRefs{ArtifactURI: aURI, MaxDepth: 1})
+	require.NoError(t, err)
+	require.Len(t, shallow.Nodes, 5)
+	assert.Equal(t, RefTooDeep, shallow.Nodes[2].Status)
+
+	_, missing, err := ResolveArtifactRefs(ctx, nil, InputResolveArtifactRefs{ArtifactContent: refCatalog("X", "missing.yaml", "https://127.0.0.1:1/none.yaml")})
+	require.NoError(t, err)
+	assert.Equal(t, 2, missing.Unresolved)
+	assert.Equal(t, RefUnresolved, missing.Nodes[1].Status)
+	assert.Contains(t, missing.Nodes[1].Error, "missing.yaml")
+
+	_, _, err = ResolveArtifactRefs(ctx, nil, InputResolveArtifactRefs{ArtifactURI: aURI, MaxDepth: maxResolveDepth + 1})
+	assert.ErrorContains(t, err, "max_depth")
+}
+
+func TestReferenceURI(t *testing.T) {
+	tests := []struct {
+		name        string
+		base        string
+		ref         string
+		want        string
+		errContains string
+	}{
+		{name: "absolute", base: "file:///w/a.yaml", ref: "https://example.com/c.yaml", want: "https://example.com/c.yaml"},
+		{name: "oci", ref: "oci://ghcr.io/org/catalogs:v1//c.yaml", want: "oci://ghcr.io/org/catalogs:v1//c.yaml"},
+		{name: "relative to file", base: "file:///w/sub/a.yaml", ref: "../c.yaml", want: "file:///w/c.yaml"},
+		{name: "relative to https", base: "https://example.com/x/a.yaml", ref: "c.yaml", want: "https://example.com/x/c.yaml"},
+		{name: "relative without base", ref: "c.yaml", errContains: "needs a workspace root"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := referenceURI(context.Background(), tt.base, tt.ref)
+			if tt.errContains != "" {
+				assert.ErrorContains(t, err, tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
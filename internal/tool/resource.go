@@ -6,7 +6,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -38,19 +37,14 @@ var MetadataLexiconResourceAlias = &mcp.Resource{
 // HandleLexiconResource reads the cached Lexicon resource.
 func HandleLexiconResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
 	// Ensure lexicon is loaded by fetching if cache is empty or expired
-	if len(lexiconCache) == 0 || lexiconCacheTime.IsZero() || time.Since(lexiconCacheTime) >= lexiconCacheTTL {
-		entries, err := fetchLexiconFromURL(ctx, lexiconURL)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch lexicon: %w", err)
-		}
-
-		// Update cache
-		lexiconCache = entries
-		lexiconCacheTime = time.Now()
+	state, cache, err := lexiconService(ctx).load(ctx)
+	if err != nil {
+		return nil, err
 	}
+	recordLexicon(ctx, state.revision, cache)
 
 	// Marshal lexicon to JSON
-	lexiconJSON, err := json.Marshal(lexiconCache)
+	lexiconJSON, err := json.Marshal(state.entries)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal lexicon: %w", err)
 	}
@@ -0,0 +1,423 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// subjectPrefix starts the message line that names what an evaluation or an
+// assessment log evaluated. Like evidence, subjects have no schema field and
+// are kept in the free-text message, one per line.
+const subjectPrefix = "Subject: "
+
+// SBOM formats link_evaluation_subjects reads.
+const (
+	sbomSPDX      = "spdx"
+	sbomCycloneDX = "cyclonedx"
+)
+
+// Statuses of an evaluation subject against an SBOM.
+const (
+	subjectPresent        = "present"
+	subjectVersionChanged = "version_changed"
+	subjectMissing        = "missing"
+)
+
+// MetadataLinkEvaluationSubjects describes the LinkEvaluationSubjects tool.
+var MetadataLinkEvaluationSubjects = &mcp.Tool{
+	Name: "link_evaluation_subjects",
+	Description: "Cross-reference the subjects of an EvaluationLog with an SPDX or CycloneDX SBOM. Subjects are the " +
+		"'Subject: <name>[@version] [purl]' lines in the message of an evaluation or assessment log. Each subject is " +
+		"matched to an SBOM component by purl or name; subjects found in the SBOM are enriched with the component's " +
+		"purl, and subjects whose component is no longer in the SBOM, or is there at another version, are flagged. " +
+		"CycloneDX vulnerability analyses (VEX) of a matched component are reported with it. The enriched log is " +
+		"validated against the schema; nothing is written to disk.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": func() map[string]interface{} {
+			properties := artifactSourceProperties("link")
+			properties["sbom_content"] = map[string]interface{}{
+				"type":        "string",
+				"description": "SBOM as SPDX JSON, SPDX tag-value, or CycloneDX JSON or YAML",
+			}
+			properties["sbom_uri"] = map[string]interface{}{
+				"type":        "string",
+				"description": "URI of the SBOM instead of inline content: file:// (within the workspace root) or https://",
+			}
+			return properties
+		}(),
+	},
+}
+
+// InputLinkEvaluationSubjects is the input for the LinkEvaluationSubjects tool.
+type InputLinkEvaluationSubjects struct {
+	ArtifactContent string `json:"artifact_content,omitempty"`
+	ArtifactURI     string `json:"artifact_uri,omitempty"`
+	SBOMContent     string `json:"sbom_content,omitempty"`
+	SBOMURI         string `json:"sbom_uri,omitempty"`
+}
+
+// SBOMComponent is the identity of a package or component in an SBOM.
+type SBOMComponent struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PURL    string `json:"purl,omitempty"`
+	// Ref is the SPDXID or CycloneDX bom-ref of the component.
+	Ref string `json:"ref,omitempty"`
+	// Vulnerabilities lists the VEX analyses of the component, as
+	// <id> (<state>).
+	Vulnerabilities []string `json:"vulnerabilities,omitempty"`
+}
+
+// EvaluationSubject is a subject of an evaluation log and its SBOM component.
+type EvaluationSubject struct {
+	Control     string `json:"control"`
+	Requirement string `json:"requirement,omitempty"`
+	// Path locates the evaluation or assessment log naming the subject.
+	Path      string         `json:"path"`
+	Subject   string         `json:"subject"`
+	Status    string         `json:"status"`
+	Component *SBOMComponent `json:"component,omitempty"`
+	// Enriched is set when the component's purl was added to the subject.
+	Enriched bool `json:"enriched,omitempty"`
+}
+
+// OutputLinkEvaluationSubjects is the output for the LinkEvaluationSubjects tool.
+type OutputLinkEvaluationSubjects struct {
+	Format     string              `json:"format"`
+	Components int                 `json:"components"`
+	Subjects   []EvaluationSubject `json:"subjects"`
+	Missing    int                 `json:"missing"`
+	// Content is the enriched evaluation log, set when a subject was enriched.
+	Content string   `json:"content,omitempty"`
+	Valid   bool     `json:"valid,omitempty"`
+	Errors  []string `json:"errors,omitempty"`
+	Message string   `json:"message"`
+}
+
+// LinkEvaluationSubjects matches the subjects of an evaluation log to the components of an SBOM.
+func LinkEvaluationSubjects(ctx context.Context, _ *mcp.CallToolRequest, input InputLinkEvaluationSubjects) (*mcp.CallToolResult, OutputLinkEvaluationSubjects, error) {
+	content, err := artifactInputContent(ctx, input.ArtifactContent, input.ArtifactURI)
+	if err != nil {
+		return nil, OutputLinkEvaluationSubjects{}, err
+	}
+	doc, err := parseArtifact(ctx, content)
+	if err != nil {
+		return nil, OutputLinkEvaluationSubjects{}, err
+	}
+	if kind := artifactKind(doc); kind != "EvaluationLog" {
+		if kind == "" {
+			kind = "unknown"
+		}
+		return nil, OutputLinkEvaluationSubjects{}, fmt.Errorf("expected an EvaluationLog, got %s", kind)
+	}
+
+	var sbomContent string
+	switch {
+	case input.SBOMContent == "" && input.SBOMURI == "":
+		return nil, OutputLinkEvaluationSubjects{}, fmt.Errorf("sbom_content or sbom_uri is required")
+	case input.SBOMContent != "" && input.SBOMURI != "":
+		return nil, OutputLinkEvaluationSubjects{}, fmt.Errorf("sbom_content and sbom_uri are mutually exclusive")
+	case input.SBOMURI != "":
+		raw, err := readArtifactURI(ctx, input.SBOMURI)
+		if err != nil {
+			return nil, OutputLinkEvaluationSubjects{}, err
+		}
+		sbomContent = string(raw)
+	default:
+		sbomContent = input.SBOMContent
+	}
+	format, components, err := parseSBOM(sbomContent)
+	if err != nil {
+		return nil, OutputLinkEvaluationSubjects{}, err
+	}
+	index := newComponentIndex(components)
+
+	var tree yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions([]byte(content), &tree, yaml.UseOrderedMap()); err != nil {
+		return nil, OutputLinkEvaluationSubjects{}, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	output := OutputLinkEvaluationSubjects{Format: format, Components: len(components), Subjects: []EvaluationSubject{}}
+	var enriched int
+	link := func(m yaml.MapSlice, subject EvaluationSubject) {
+		message, _ := mapValue(m, "message").(string)
+		lines := strings.Split(message, "\n")
+		changed := false
+		for i, line := range lines {
+			value, ok := strings.CutPrefix(strings.TrimSpace(line), subjectPrefix)
+			if !ok || strings.TrimSpace(value) == "" {
+				continue
+			}
+			s := subject
+			s.Subject = strings.TrimSpace(value)
+			name, version, purl := parseSubject(s.Subject)
+			component, status := index.match(name, version, purl)
+			s.Status = status
+			s.Component = component
+			if status == subjectPresent && purl == "" && component.PURL != "" {
+				lines[i] = subjectPrefix + s.Subject + " " + component.PURL
+				s.Enriched, changed = true, true
+				enriched++
+			}
+			if status == subjectMissing {
+				output.Missing++
+			}
+			output.Subjects = append(output.Subjects, s)
+		}
+		if changed {
+			setMapValue(m, "message", strings.Join(lines, "\n"))
+		}
+	}
+	for i, e := range mapValueList(tree, "evaluations") {
+		evaluation, ok := e.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+		control := orderedEntryID(mapValue(evaluation, "control"))
+		link(evaluation, EvaluationSubject{Control: control, Path: fmt.Sprintf("$.evaluations[%d]", i)})
+		for j, l := range mapValueList(evaluation, "assessment-logs") {
+			if log, ok := l.(yaml.MapSlice); ok {
+				link(log, EvaluationSubject{
+					Control:     control,
+					Requirement: orderedEntryID(mapValue(log, "requirement")),
+					Path:        fmt.Sprintf("$.evaluations[%d].assessment-logs[%d]", i, j),
+				})
+			}
+		}
+	}
+
+	if len(output.Subjects) == 0 {
+		output.Message = fmt.Sprintf("The evaluation log names no subjects; add a '%s<name>[@version]' line to the "+
+			"message of the evaluations or assessment logs to link", subjectPrefix)
+		return nil, output, nil
+	}
+	output.Message = fmt.Sprintf("Linked %d of %d subject(s) to the %d component(s) of the %s SBOM; %d missing from the SBOM",
+		len(output.Subjects)-output.Missing, len(output.Subjects), len(components), format, output.Missing)
+	if enriched == 0 {
+		return nil, output, nil
+	}
+
+	out, err := yaml.MarshalWithOptions(tree, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return nil, OutputLinkEvaluationSubjects{}, fmt.Errorf("failed to encode evaluation log: %w", err)
+	}
+	output.Content = string(out)
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputLinkEvaluationSubjects{}, err
+	}
+	validation, err := validateAgainstSchema(schema, "#EvaluationLog", output.Content)
+	if err != nil {
+		return nil, OutputLinkEvaluationSubjects{}, err
+	}
+	output.Valid = validation.Valid
+	output.Errors = validation.Errors
+	output.Message += fmt.Sprintf("; enriched %d with their purl; %s", enriched, strings.ToLower(validation.Message))
+	return nil, output, nil
+}
+
+// parseSubject splits a subject into its name, version, and purl. The
+// version is taken from name@version, or from the purl.
+func parseSubject(subject string) (name, version, purl string) {
+	var words []string
+	for _, field := range strings.Fields(subject) {
+		if strings.HasPrefix(field, "pkg:") && purl == "" {
+			purl = field
+			continue
+		}
+		words = append(words, field)
+	}
+	name = strings.Join(words, " ")
+	if i := strings.LastIndex(name, "@"); i > 0 {
+		name, version = name[:i], name[i+1:]
+	}
+	if base, purlVersion := splitPURL(purl); base != "" {
+		if version == "" {
+			version = purlVersion
+		}
+		if name == "" {
+			name = base[strings.LastIndex(base, "/")+1:]
+		}
+	}
+	return name, version, purl
+}
+
+// splitPURL returns a package URL without its version, qualifiers, and
+// subpath, and its version.
+func splitPURL(purl string) (base, version string) {
+	if !strings.HasPrefix(purl, "pkg:") {
+		return "", ""
+	}
+	purl, _, _ = strings.Cut(purl, "#")
+	purl, _, _ = strings.Cut(purl, "?")
+	if i := strings.LastIndex(purl, "@"); i > strings.LastIndex(purl, "/") {
+		purl, version = purl[:i], purl[i+1:]
+	}
+	return strings.ToLower(purl), version
+}
+
+// componentIndex looks up SBOM components by purl and by name.
+type componentIndex struct {
+	byPURL map[string][]*SBOMComponent
+	byName map[string][]*SBOMComponent
+}
+
+func newComponentIndex(components []*SBOMComponent) componentIndex {
+	index := componentIndex{byPURL: map[string][]*SBOMComponent{}, byName: map[string][]*SBOMComponent{}}
+	for _, c := range components {
+		if base, _ := splitPURL(c.PURL); base != "" {
+			index.byPURL[base] = append(index.byPURL[base], c)
+		}
+		name := strings.ToLower(c.Name)
+		index.byName[name] = append(index.byName[name], c)
+	}
+	return index
+}
+
+// match finds the component of a subject, by purl when it has one, and
+// reports whether it is present at the subject's version.
+func (x componentIndex) match(name, version, purl string) (*SBOMComponent, string) {
+	candidates := x.byName[strings.ToLower(name)]
+	if base, _ := splitPURL(purl); base != "" {
+		candidates = x.byPURL[base]
+	}
+	if len(candidates) == 0 {
+		return nil, subjectMissing
+	}
+	if version == "" {
+		return candidates[0], subjectPresent
+	}
+	for _, c := range candidates {
+		if componentVersion(c) == version {
+			return c, subjectPresent
+		}
+	}
+	return candidates[0], subjectVersionChanged
+}
+
+// componentVersion returns the version of a component, falling back to the
+// version of its purl.
+func componentVersion(c *SBOMComponent) string {
+	if c.Version != "" {
+		return c.Version
+	}
+	_, version := splitPURL(c.PURL)
+	return version
+}
+
+// parseSBOM reads the components of an SPDX or CycloneDX SBOM.
+func parseSBOM(content string) (string, []*SBOMComponent, error) {
+	content = strings.TrimPrefix(content, "\ufeff")
+	if strings.HasPrefix(strings.TrimSpace(content), "SPDXVersion:") {
+		return sbomSPDX, parseSPDXTagValue(content), nil
+	}
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil || doc == nil {
+		return "", nil, fmt.Errorf("failed to parse SBOM: expected SPDX or CycloneDX JSON or YAML")
+	}
+	switch {
+	case doc["spdxVersion"] != nil:
+		return sbomSPDX, parseSPDXJSON(doc), nil
+	case strings.EqualFold(fmt.Sprint(doc["bomFormat"]), "CycloneDX"):
+		return sbomCycloneDX, parseCycloneDX(doc), nil
+	}
+	return "", nil, fmt.Errorf("unsupported SBOM: expected an SPDX document (spdxVersion) or a CycloneDX BOM (bomFormat)")
+}
+
+// parseSPDXJSON reads the packages of an SPDX JSON document.
+func parseSPDXJSON(doc map[string]interface{}) []*SBOMComponent {
+	var components []*SBOMComponent
+	for _, p := range mapList(doc["packages"]) {
+		c := &SBOMComponent{Name: stringField(p, "name"), Version: stringField(p, "versionInfo"), Ref: stringField(p, "SPDXID")}
+		for _, ref := range mapList(p["externalRefs"]) {
+			if stringField(ref, "referenceType") == "purl" {
+				c.PURL = stringField(ref, "referenceLocator")
+				break
+			}
+		}
+		components = append(components, c)
+	}
+	return components
+}
+
+// parseSPDXTagValue reads the packages of an SPDX tag-value document.
+func parseSPDXTagValue(content string) []*SBOMComponent {
+	var components []*SBOMComponent
+	var current *SBOMComponent
+	for _, line := range strings.Split(content, "\n") {
+		tag, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch tag {
+		case "PackageName":
+			current = &SBOMComponent{Name: value}
+			components = append(components, current)
+		case "SPDXID":
+			if current != nil {
+				current.Ref = value
+			}
+		case "PackageVersion":
+			if current != nil {
+				current.Version = value
+			}
+		case "ExternalRef":
+			// ExternalRef: <category> <type> <locator>
+			if fields := strings.Fields(value); current != nil && current.PURL == "" && len(fields) == 3 && fields[1] == "purl" {
+				current.PURL = fields[2]
+			}
+		}
+	}
+	return components
+}
+
+// parseCycloneDX reads the components of a CycloneDX BOM, including nested
+// components, with the VEX analyses that affect them.
+func parseCycloneDX(doc map[string]interface{}) []*SBOMComponent {
+	var components []*SBOMComponent
+	refs := map[string]*SBOMComponent{}
+	var collect func(list interface{})
+	collect = func(list interface{}) {
+		for _, m := range mapList(list) {
+			c := &SBOMComponent{Name: stringField(m, "name"), Version: stringField(m, "version"), PURL: stringField(m, "purl"), Ref: stringField(m, "bom-ref")}
+			// VEX statements affect components by bom-ref, which is often the purl
+			for _, ref := range []string{c.Ref, c.PURL} {
+				if ref != "" {
+					refs[ref] = c
+				}
+			}
+			components = append(components, c)
+			collect(m["components"])
+		}
+	}
+	if metadata, ok := doc["metadata"].(map[string]interface{}); ok {
+		collect([]interface{}{metadata["component"]})
+	}
+	collect(doc["components"])
+
+	for _, v := range mapList(doc["vulnerabilities"]) {
+		id := stringField(v, "id")
+		state := "unknown"
+		if analysis, ok := v["analysis"].(map[string]interface{}); ok && stringField(analysis, "state") != "" {
+			state = stringField(analysis, "state")
+		}
+		for _, affects := range mapList(v["affects"]) {
+			if c := refs[stringField(affects, "ref")]; c != nil && id != "" {
+				c.Vulnerabilities = append(c.Vulnerabilities, fmt.Sprintf("%s (%s)", id, state))
+			}
+		}
+	}
+	for _, c := range components {
+		sort.Strings(c.Vulnerabilities)
+	}
+	return components
+}
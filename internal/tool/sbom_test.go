@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const subjectLog = `metadata:
+  id: EVAL-SBOM
+  description: Evaluation of the storage service build.
+  author:
+    id: scanner
+    name: Scanner
+    type: Software
+evaluations:
+  - name: Prevent Unencrypted Requests
+    result: Passed
+    message: |-
+      Subject: openssl@3.0.13
+      Subject: pkg:golang/github.com/acme/storage@v1.4.0
+    control:
+      reference-id: FINOS-CCC
+      entry-id: CCC.C01
+    assessment-logs:
+      - requirement:
+          reference-id: FINOS-CCC
+          entry-id: CCC.C01.TR01
+        description: TLS enforced
+        result: Passed
+        message: "Subject: zlib@1.2.13"
+  - name: Prevent Deployment in Restricted Regions
+    result: Failed
+    message: "Subject: legacy-agent"
+    control:
+      reference-id: FINOS-CCC
+      entry-id: CCC.C06
+    assessment-logs: []
+`
+
+const cycloneDXSBOM = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "components": [
+    {"bom-ref": "openssl", "name": "openssl", "version": "3.0.13", "purl": "pkg:generic/openssl@3.0.13"},
+    {"name": "storage", "group": "github.com/acme", "version": "v1.4.0", "purl": "pkg:golang/github.com/acme/storage@v1.4.0",
+     "components": [{"name": "zlib", "version": "1.3.1", "purl": "pkg:generic/zlib@1.3.1"}]}
+  ],
+  "vulnerabilities": [
+    {"id": "CVE-2024-0001", "analysis": {"state": "not_affected"}, "affects": [{"ref": "openssl"}]}
+  ]
+}`
+
+const spdxTagValueSBOM = `SPDXVersion: SPDX-2.3
+DataLicense: CC0-1.0
+SPDXID: SPDXRef-DOCUMENT
+
+PackageName: openssl
+SPDXID: SPDXRef-openssl
+PackageVersion: 3.0.13
+ExternalRef: PACKAGE-MANAGER purl pkg:generic/openssl@3.0.13
+
+PackageName: legacy-agent
+SPDXID: SPDXRef-agent
+PackageVersion: 0.9
+`
+
+func TestLinkEvaluationSubjects(t *testing.T) {
+	useTestSchema(t)
+
+	tests := []struct {
+		name           string
+		input          InputLinkEvaluationSubjects
+		wantErr        string
+		wantFormat     string
+		wantStatuses   map[string]string
+		wantMissing    int
+		wantContains   []string
+		wantVulnerable string
+	}{
+		{
+			name:       "cyclonedx",
+			input:      InputLinkEvaluationSubjects{ArtifactContent: subjectLog, SBOMContent: cycloneDXSBOM},
+			wantFormat: sbomCycloneDX,
+			wantStatuses: map[string]string{
+				"openssl@3.0.13": subjectPresent,
+				"pkg:golang/github.com/acme/storage@v1.4.0": subjectPresent,
+				"zlib@1.2.13":  subjectVersionChanged,
+				"legacy-agent": subjectMissing,
+			},
+			wantMissing:    1,
+			wantContains:   []string{"Subject: openssl@3.0.13 pkg:generic/openssl@3.0.13", "message: \"Subject: zlib@1.2.13\""},
+			wantVulnerable: "CVE-2024-0001 (not_affected)",
+		},
+		{
+			name:       "spdx json",
+			input:      InputLinkEvaluationSubjects{ArtifactContent: subjectLog, SBOMContent: `{"spdxVersion": "SPDX-2.3", "packages": [{"SPDXID": "SPDXRef-zlib", "name": "zlib", "versionInfo": "1.2.13", "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:generic/zlib@1.2.13"}]}]}`},
+			wantFormat: sbomSPDX,
+			wantStatuses: map[string]string{
+				"openssl@3.0.13": subjectMissing,
+				"pkg:golang/github.com/acme/storage@v1.4.0": subjectMissing,
+				"zlib@1.2.13":  subjectPresent,
+				"legacy-agent": subjectMissing,
+			},
+			wantMissing:  3,
+			wantContains: []string{"Subject: zlib@1.2.13 pkg:generic/zlib@1.2.13"},
+		},
+		{
+			name:       "spdx tag-value",
+			input:      InputLinkEvaluationSubjects{ArtifactContent: subjectLog, SBOMContent: spdxTagValueSBOM},
+			wantFormat: sbomSPDX,
+			wantStatuses: map[string]string{
+				"openssl@3.0.13": subjectPresent,
+				"pkg:golang/github.com/acme/storage@v1.4.0": subjectMissing,
+				"zlib@1.2.13":  subjectMissing,
+				"legacy-agent": subjectPresent,
+			},
+			wantMissing:  2,
+			wantContains: []string{"Subject: openssl@3.0.13 pkg:generic/openssl@3.0.13", `message: "Subject: legacy-agent"`},
+		},
+		{
+			name:    "sbom required",
+			input:   InputLinkEvaluationSubjects{ArtifactContent: subjectLog},
+			wantErr: "sbom_content or sbom_uri is required",
+		},
+		{
+			name:    "unsupported sbom",
+			input:   InputLinkEvaluationSubjects{ArtifactContent: subjectLog, SBOMContent: `{"kind": "List"}`},
+			wantErr: "unsupported SBOM",
+		},
+		{
+			name:    "not an evaluation log",
+			input:   InputLinkEvaluationSubjects{ArtifactContent: baselineCatalog, SBOMContent: cycloneDXSBOM},
+			wantErr: "expected an EvaluationLog",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := LinkEvaluationSubjects(context.Background(), nil, tt.input)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFormat, output.Format)
+			assert.Equal(t, tt.wantMissing, output.Missing)
+
+			statuses := map[string]string{}
+			for _, s := range output.Subjects {
+				statuses[s.Subject] = s.Status
+				if tt.wantVulnerable != "" && s.Subject == "openssl@3.0.13" {
+					assert.Contains(t, s.Component.Vulnerabilities, tt.wantVulnerable)
+				}
+			}
+			assert.Equal(t, tt.wantStatuses, statuses)
+
+			if len(tt.wantContains) == 0 {
+				assert.Empty(t, output.Content, "nothing to enrich")
+				return
+			}
+			assert.True(t, output.Valid, output.Errors)
+			for _, want := range tt.wantContains {
+				assert.Contains(t, output.Content, want)
+			}
+		})
+	}
+}
+
+func TestParseSubject(t *testing.T) {
+	tests := []struct {
+		subject                         string
+		wantName, wantVersion, wantPURL string
+	}{
+		{subject: "openssl", wantName: "openssl"},
+		{subject: "openssl@3.0.13", wantName: "openssl", wantVersion: "3.0.13"},
+		{subject: "pkg:npm/%40angular/core@17.0.0?arch=x64", wantName: "core", wantVersion: "17.0.0", wantPURL: "pkg:npm/%40angular/core@17.0.0?arch=x64"},
+		{subject: "api gateway pkg:oci/gateway@sha256%3Aabc", wantName: "api gateway", wantVersion: "sha256%3Aabc", wantPURL: "pkg:oci/gateway@sha256%3Aabc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			name, version, purl := parseSubject(tt.subject)
+			assert.Equal(t, tt.wantName, name)
+			assert.Equal(t, tt.wantVersion, version)
+			assert.Equal(t, tt.wantPURL, purl)
+		})
+	}
+}
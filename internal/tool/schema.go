@@ -0,0 +1,386 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/load"
+	"cuelang.org/go/mod/modconfig"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// GemaraModule is the CUE module that defines the Gemara schema.
+	GemaraModule     = "github.com/gemaraproj/gemara"
+	gemaraModulePath = GemaraModule + "@latest"
+	unknownVersion   = "unknown"
+	// schemaResolutionTTL is how long a resolved Gemara module is reused before
+	// the registry is asked for the latest version again.
+	schemaResolutionTTL = time.Hour
+	// schemaFailureTTL is how long a failed resolution is remembered, serving
+	// the embedded snapshot without retrying the registry.
+	schemaFailureTTL = time.Minute
+)
+
+var (
+	schemaResolutionMu sync.Mutex
+	// resolvedSchemaRoot and resolvedSchemaVersion locate the module source the
+	// registry last resolved, within the local CUE module cache.
+	resolvedSchemaRoot    string
+	resolvedSchemaVersion string
+	resolvedSchemaTime    time.Time
+	// schemaFailure is the error of the last resolution, if it failed at schemaFailureTime.
+	schemaFailure     error
+	schemaFailureTime time.Time
+	// schemaFlight shares one registry resolution among concurrent loads.
+	schemaFlight singleflight.Group
+)
+
+// maxIdleSchemas bounds the compiled schemas cached for each module root and
+// version.
+const maxIdleSchemas = 4
+
+// schemaKey identifies the compiled schemas of a module root and version.
+type schemaKey struct {
+	root    string
+	version string
+}
+
+var (
+	schemaCacheMu sync.Mutex
+	// schemaCache holds compiled schemas no call is using. CUE values are not
+	// safe for concurrent use, so a cached schema is lent to one tool call at
+	// a time and returned to the cache when the call ends.
+	schemaCache = map[schemaKey][]*gemaraSchema{}
+)
+
+// gemaraSchema is a compiled Gemara CUE module.
+type gemaraSchema struct {
+	ctx     *cue.Context
+	value   cue.Value
+	version string
+	// digest is the sha256 digest of the module source.
+	digest string
+	// root is the directory holding the module source, when loaded from disk.
+	root string
+	// snapshot is set when the schema is the embedded fallback.
+	snapshot bool
+}
+
+// schemaSnapshot is a copy of the Gemara module used when the registry cannot
+// be reached. Refresh it with `make update-schema-snapshot`.
+//
+//go:embed all:schema_snapshot
+var schemaSnapshot embed.FS
+
+var (
+	schemaSnapshotOnce sync.Once
+	schemaSnapshotDir  string
+	schemaSnapshotErr  error
+)
+
+// schemaLoader loads the Gemara schema. It is a variable so tests can supply a local schema.
+var schemaLoader = loadGemaraSchema
+
+// loadGemaraSchema resolves the Gemara module from the CUE registry and builds it.
+// A module resolved within schemaResolutionTTL is rebuilt from its local copy
+// without contacting the registry, and within schemaFailureTTL of a failed
+// resolution the embedded snapshot is served without contacting it either.
+func loadGemaraSchema(ctx context.Context) (*gemaraSchema, error) {
+	schemaResolutionMu.Lock()
+	root, version, resolved := resolvedSchemaRoot, resolvedSchemaVersion, resolvedSchemaTime
+	failure, failed := schemaFailure, schemaFailureTime
+	schemaResolutionMu.Unlock()
+	if root != "" && time.Since(resolved) < schemaResolutionTTL {
+		if schema, err := loadSchemaDir(root, version); err == nil {
+			emitSchemaLoaded(ctx, schema.version)
+			return schema, nil
+		}
+	}
+	if failure != nil && time.Since(failed) < schemaFailureTTL {
+		snapshot, err := loadSchemaSnapshot()
+		if err != nil {
+			return nil, failure
+		}
+		emitSchemaLoaded(ctx, snapshot.version)
+		return snapshot, nil
+	}
+
+	// Concurrent cold loads share one resolution from the registry
+	schema, shared, err := sharedCall(ctx, &schemaFlight, gemaraModulePath, resolveGemaraSchema)
+	if err != nil {
+		return nil, err
+	}
+	if shared && schema.root != "" {
+		// CUE values are not safe for concurrent use, so every caller borrows its own
+		fallback := schema.snapshot
+		if schema, err = loadSchemaDir(schema.root, schema.version); err != nil {
+			return nil, err
+		}
+		schema.snapshot = fallback
+	}
+	emitSchemaLoaded(ctx, schema.version)
+	return schema, nil
+}
+
+// resolveGemaraSchema resolves the Gemara module from the CUE registry and
+// records where it was resolved to, falling back to the embedded snapshot.
+func resolveGemaraSchema(ctx context.Context) (*gemaraSchema, error) {
+	schema, err := loadGemaraModule(ctx, gemaraModulePath)
+	if err != nil {
+		schemaResolutionMu.Lock()
+		schemaFailure, schemaFailureTime = err, time.Now()
+		schemaResolutionMu.Unlock()
+		snapshot, snapshotErr := loadSchemaSnapshot()
+		if snapshotErr != nil {
+			return nil, err
+		}
+		emitStaleCache(ctx, "schema", gemaraModulePath, err)
+		return snapshot, nil
+	}
+	schemaResolutionMu.Lock()
+	resolvedSchemaRoot, resolvedSchemaVersion, resolvedSchemaTime = schema.root, schema.version, time.Now()
+	schemaFailure = nil
+	schemaResolutionMu.Unlock()
+	return schema, nil
+}
+
+// loadGemaraModule resolves a version of the Gemara module (e.g.,
+// github.com/gemaraproj/gemara@v0.7.0) from the CUE registry and builds it.
+func loadGemaraModule(ctx context.Context, modulePath string) (*gemaraSchema, error) {
+	// Create registry for module access
+	reg, err := modconfig.NewRegistry(serverConfig(ctx).Registry.cueConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CUE registry: %w", err)
+	}
+
+	// Load the Gemara module from registry
+	// Pass the module path as an argument to load it from the registry
+	buildInstances := load.Instances([]string{modulePath}, &load.Config{
+		Registry: reg,
+	})
+
+	if len(buildInstances) == 0 {
+		return nil, fmt.Errorf("failed to load module: no instances returned")
+	}
+
+	schema, err := buildSchema(buildInstances[0], moduleVersion(buildInstances[0].Dir))
+	if err != nil {
+		emitUpstreamFailure(ctx, modulePath, err)
+		return nil, err
+	}
+	return schema, nil
+}
+
+// loadSchemaSnapshot builds the embedded copy of the Gemara module. It is
+// extracted to a temporary directory on first use, since CUE loads modules
+// from disk. The registry is tried again on the next load.
+func loadSchemaSnapshot() (*gemaraSchema, error) {
+	schemaSnapshotOnce.Do(func() {
+		schemaSnapshotDir, schemaSnapshotErr = extractSchemaSnapshot()
+	})
+	if schemaSnapshotErr != nil {
+		return nil, schemaSnapshotErr
+	}
+	version, err := schemaSnapshot.ReadFile("schema_snapshot/VERSION")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema snapshot version: %w", err)
+	}
+	schema, err := loadSchemaDir(schemaSnapshotDir, strings.TrimSpace(string(version)))
+	if err != nil {
+		return nil, err
+	}
+	schema.snapshot = true
+	return schema, nil
+}
+
+// extractSchemaSnapshot writes the embedded module to a temporary directory.
+func extractSchemaSnapshot() (string, error) {
+	dir, err := os.MkdirTemp("", "gemara-schema-snapshot-")
+	if err != nil {
+		return "", fmt.Errorf("failed to extract schema snapshot: %w", err)
+	}
+	snapshot, err := fs.Sub(schemaSnapshot, "schema_snapshot")
+	if err != nil {
+		return "", fmt.Errorf("failed to extract schema snapshot: %w", err)
+	}
+	if err := os.CopyFS(dir, snapshot); err != nil {
+		return "", fmt.Errorf("failed to extract schema snapshot: %w", err)
+	}
+	return dir, nil
+}
+
+// loadSchemaDir builds a Gemara schema snapshot from a local directory without
+// contacting the registry, or takes one built earlier from the cache.
+func loadSchemaDir(dir, version string) (*gemaraSchema, error) {
+	if schema := cachedSchema(dir, version); schema != nil {
+		return schema, nil
+	}
+	buildInstances := load.Instances([]string{"."}, &load.Config{Dir: dir})
+	if len(buildInstances) == 0 {
+		return nil, fmt.Errorf("failed to load schema from %s: no instances returned", dir)
+	}
+	return buildSchema(buildInstances[0], version)
+}
+
+// cachedSchema takes a compiled schema of a module root and version from the
+// cache, or returns nil when none is idle.
+func cachedSchema(root, version string) *gemaraSchema {
+	schemaCacheMu.Lock()
+	defer schemaCacheMu.Unlock()
+	key := schemaKey{root: root, version: version}
+	idle := schemaCache[key]
+	if len(idle) == 0 {
+		return nil
+	}
+	schema := idle[len(idle)-1]
+	schemaCache[key] = idle[:len(idle)-1]
+	return schema
+}
+
+// releaseSchema returns a schema to the cache once its borrower is done with
+// it. Schemas not built from a module on disk are not cached.
+func releaseSchema(schema *gemaraSchema) {
+	if schema.root == "" {
+		return
+	}
+	schemaCacheMu.Lock()
+	defer schemaCacheMu.Unlock()
+	key := schemaKey{root: schema.root, version: schema.version}
+	idle := schemaCache[key]
+	if len(idle) >= maxIdleSchemas || slices.Contains(idle, schema) {
+		return
+	}
+	schemaCache[key] = append(idle, schema)
+}
+
+// schemaLease records the schemas a tool call borrowed.
+type schemaLease struct {
+	mu      sync.Mutex
+	schemas []*gemaraSchema
+	// abandoned is set when an evaluation is left running after the call, so
+	// its schemas must not be lent again.
+	abandoned bool
+}
+
+type schemaLeaseKey struct{}
+
+// withSchemaLease returns a context whose loaded schemas are returned to the
+// cache by release.
+func withSchemaLease(ctx context.Context) (context.Context, func()) {
+	lease := &schemaLease{}
+	release := func() {
+		lease.mu.Lock()
+		defer lease.mu.Unlock()
+		if !lease.abandoned {
+			for _, schema := range lease.schemas {
+				releaseSchema(schema)
+			}
+		}
+		lease.schemas = nil
+	}
+	return context.WithValue(ctx, schemaLeaseKey{}, lease), release
+}
+
+// borrowSchema records a schema loaded under ctx, to be returned to the cache
+// when its lease is released. Without a lease the schema is not cached again.
+func borrowSchema(ctx context.Context, schema *gemaraSchema) {
+	if lease, ok := ctx.Value(schemaLeaseKey{}).(*schemaLease); ok {
+		lease.mu.Lock()
+		lease.schemas = append(lease.schemas, schema)
+		lease.mu.Unlock()
+	}
+}
+
+// abandonSchemas keeps the schemas borrowed under ctx out of the cache, for
+// evaluations that are left to finish in the background.
+func abandonSchemas(ctx context.Context) {
+	if lease, ok := ctx.Value(schemaLeaseKey{}).(*schemaLease); ok {
+		lease.mu.Lock()
+		lease.abandoned = true
+		lease.mu.Unlock()
+	}
+}
+
+// schemaHandler returns the schemas a tool call loaded to the cache when the
+// handler returns.
+func schemaHandler[In, Out any](h mcp.ToolHandlerFor[In, Out]) mcp.ToolHandlerFor[In, Out] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		ctx, release := withSchemaLease(ctx)
+		defer release()
+		return h(ctx, req, input)
+	}
+}
+
+// buildSchema compiles a loaded instance of the Gemara module.
+func buildSchema(inst *build.Instance, version string) (*gemaraSchema, error) {
+	if err := inst.Err; err != nil {
+		return nil, fmt.Errorf("failed to load module: %w", err)
+	}
+
+	// Build the schema instance
+	cueCtx := cuecontext.New()
+	schema := cueCtx.BuildInstance(inst)
+	if err := schema.Err(); err != nil {
+		return nil, fmt.Errorf("failed to build schema: %w", err)
+	}
+
+	digest, err := instanceDigest(inst)
+	if err != nil {
+		return nil, err
+	}
+
+	root := inst.Root
+	if root == "" {
+		root = inst.Dir
+	}
+	return &gemaraSchema{
+		ctx:     cueCtx,
+		value:   schema,
+		version: version,
+		digest:  digest,
+		root:    root,
+	}, nil
+}
+
+// lookupDefinition returns the named definition from the schema.
+func (s *gemaraSchema) lookupDefinition(definition string) (cue.Value, error) {
+	entrypoint := s.value.LookupPath(cue.ParsePath(definition))
+	if !entrypoint.Exists() {
+		return cue.Value{}, fmt.Errorf("definition %s not found in schema", definition)
+	}
+	return entrypoint, nil
+}
+
+// normalizeDefinition ensures a definition name starts with #.
+func normalizeDefinition(definition string) string {
+	if !strings.HasPrefix(definition, "#") {
+		return "#" + definition
+	}
+	return definition
+}
+
+// moduleVersion extracts the module version from the directory the registry
+// extracted it into (e.g., .../github.com/gemaraproj/gemara@v0.7.0/...).
+func moduleVersion(dir string) string {
+	for _, elem := range strings.Split(filepath.ToSlash(dir), "/") {
+		if i := strings.LastIndex(elem, "@v"); i >= 0 {
+			return elem[i+1:]
+		}
+	}
+	return unknownVersion
+}
@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/format"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	schemaResourcePrefix      = "gemara://schema/"
+	schemaResourceURITemplate = schemaResourcePrefix + "{definition}{?format}"
+
+	schemaFormatCUE        = "cue"
+	schemaFormatJSONSchema = "jsonschema"
+)
+
+// MetadataSchemaResourceTemplate describes the per-definition schema resource template.
+var MetadataSchemaResourceTemplate = &mcp.ResourceTemplate{
+	Name:        "schema",
+	URITemplate: schemaResourceURITemplate,
+	Title:       "Gemara Definition Schema",
+	Description: "Schema of a Gemara definition (e.g., gemara://schema/ControlCatalog). Returns CUE source by default; " +
+		"add ?format=jsonschema for JSON Schema.",
+	MIMEType: "text/plain",
+}
+
+// HandleSchemaResource serves the CUE source or JSON Schema of a Gemara definition.
+func HandleSchemaResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	definition, schemaFormat, err := parseSchemaURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := schema.lookupDefinition(definition); err != nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	var text, mimeType string
+	switch schemaFormat {
+	case schemaFormatJSONSchema:
+		generated, _, err := definitionJSONSchema(schema, definition)
+		if err != nil {
+			return nil, err
+		}
+		raw, err := json.MarshalIndent(generated, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal JSON Schema: %w", err)
+		}
+		text, mimeType = string(raw), "application/schema+json"
+	default:
+		text, err = definitionSource(schema, definition)
+		if err != nil {
+			return nil, err
+		}
+		mimeType = "text/plain"
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      uri,
+				MIMEType: mimeType,
+				Text:     text,
+			},
+		},
+	}, nil
+}
+
+// definitionSource renders the CUE source of a definition, keeping references
+// to other definitions rather than inlining them.
+func definitionSource(schema *gemaraSchema, definition string) (string, error) {
+	entrypoint, err := schema.lookupDefinition(definition)
+	if err != nil {
+		return "", err
+	}
+
+	src, err := format.Node(entrypoint.Syntax(cue.Raw(), cue.Docs(true), cue.Definitions(true)))
+	if err != nil {
+		return "", fmt.Errorf("failed to format %s: %w", definition, err)
+	}
+
+	version := fmt.Sprintf("// %s from %s (%s)\n", definition, strings.TrimSuffix(gemaraModulePath, "@latest"), schema.version)
+	return version + definition + ": " + string(src) + "\n", nil
+}
+
+// parseSchemaURI splits gemara://schema/{definition}{?format} into its parts.
+func parseSchemaURI(uri string) (string, string, error) {
+	u, err := url.Parse(uri)
+	if err != nil || !strings.HasPrefix(uri, schemaResourcePrefix) {
+		return "", "", fmt.Errorf("invalid schema URI %q", uri)
+	}
+
+	name := strings.Trim(u.Path, "/")
+	if name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid schema URI %q: expected %s", uri, schemaResourceURITemplate)
+	}
+
+	schemaFormat := u.Query().Get("format")
+	switch schemaFormat {
+	case "", schemaFormatCUE:
+		schemaFormat = schemaFormatCUE
+	case schemaFormatJSONSchema:
+	default:
+		return "", "", fmt.Errorf("unsupported schema format %q: must be %q or %q", schemaFormat, schemaFormatCUE, schemaFormatJSONSchema)
+	}
+
+	return normalizeDefinition(name), schemaFormat, nil
+}
+
+// SchemaSummary describes the loaded Gemara module and the definitions it declares.
+type SchemaSummary struct {
+	ModuleVersion string `json:"module_version"`
+	// Fallback is set when the registry could not be reached and the schema
+	// embedded in the binary was loaded instead.
+	Fallback    bool     `json:"fallback,omitempty"`
+	Definitions []string `json:"definitions"`
+}
+
+// ListSchemaDefinitions loads the Gemara schema as validation does and lists
+// its struct definitions, for use outside a tool call.
+func ListSchemaDefinitions(ctx context.Context) (SchemaSummary, error) {
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return SchemaSummary{}, err
+	}
+	definitions := schemaDefinitions(schema)
+	if definitions == nil {
+		definitions = []string{}
+	}
+	return SchemaSummary{
+		ModuleVersion: schema.version,
+		Fallback:      schema.snapshot,
+		Definitions:   definitions,
+	}, nil
+}
+
+// DefinitionSource returns the CUE source of a definition as served by
+// gemara://schema/{definition}, for use outside a tool call.
+func DefinitionSource(ctx context.Context, definition string) (string, error) {
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return "", err
+	}
+	return definitionSource(schema, normalizeDefinition(definition))
+}
@@ -0,0 +1,122 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleSchemaResource(t *testing.T) {
+	useTestSchema(t)
+
+	tests := []struct {
+		name         string
+		uri          string
+		wantErr      bool
+		errContains  string
+		wantMIMEType string
+		validateText func(t *testing.T, text string)
+	}{
+		{
+			name:         "cue source by default",
+			uri:          "gemara://schema/Policy",
+			wantMIMEType: "text/plain",
+			validateText: func(t *testing.T, text string) {
+				assert.Contains(t, text, "#Policy: {", "should be labelled with the definition")
+				assert.Contains(t, text, "metadata: #Metadata", "references should be preserved")
+			},
+		},
+		{
+			name:         "json schema format",
+			uri:          "gemara://schema/ControlCatalog?format=jsonschema",
+			wantMIMEType: "application/schema+json",
+			validateText: func(t *testing.T, text string) {
+				var schema map[string]interface{}
+				require.NoError(t, json.Unmarshal([]byte(text), &schema), "should be valid JSON")
+				assert.Equal(t, "https://json-schema.org/draft/2020-12/schema", schema["$schema"])
+			},
+		},
+		{
+			name:        "unknown definition",
+			uri:         "gemara://schema/Unknown",
+			wantErr:     true,
+			errContains: "not found",
+		},
+		{
+			name:        "unsupported format",
+			uri:         "gemara://schema/Policy?format=xml",
+			wantErr:     true,
+			errContains: "unsupported schema format",
+		},
+		{
+			name:        "nested path",
+			uri:         "gemara://schema/Policy/extra",
+			wantErr:     true,
+			errContains: "invalid schema URI",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: tt.uri}}
+			result, err := HandleSchemaResource(context.Background(), req)
+
+			if tt.wantErr {
+				require.Error(t, err, "should return error")
+				assert.Contains(t, err.Error(), tt.errContains, "error should contain expected message")
+				return
+			}
+
+			require.NoError(t, err, "should not return error")
+			require.Len(t, result.Contents, 1, "should return one content item")
+			assert.Equal(t, tt.wantMIMEType, result.Contents[0].MIMEType, "MIME type should match format")
+			tt.validateText(t, result.Contents[0].Text)
+		})
+	}
+}
+
+func TestSchemaResourceTemplateMatching(t *testing.T) {
+	useTestSchema(t)
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	server.AddResourceTemplate(MetadataSchemaResourceTemplate, HandleSchemaResource)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err, "server should connect")
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err, "client should connect")
+	defer session.Close()
+
+	for _, uri := range []string{"gemara://schema/Policy", "gemara://schema/Policy?format=jsonschema"} {
+		result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+		require.NoError(t, err, "template should match %s", uri)
+		assert.NotEmpty(t, result.Contents[0].Text, "should return schema content")
+	}
+}
+
+func TestListSchemaDefinitions(t *testing.T) {
+	useTestSchema(t)
+
+	summary, err := ListSchemaDefinitions(context.Background())
+	require.NoError(t, err)
+	assert.NotEmpty(t, summary.ModuleVersion)
+	assert.Contains(t, summary.Definitions, "#ControlCatalog")
+	assert.Contains(t, summary.Definitions, "#Policy")
+
+	source, err := DefinitionSource(context.Background(), "Policy")
+	require.NoError(t, err)
+	assert.Contains(t, source, "#Policy: {")
+
+	_, err = DefinitionSource(context.Background(), "Unknown")
+	assert.ErrorContains(t, err, "not found")
+}
@@ -0,0 +1,4 @@
+module: "github.com/gemaraproj/gemara@v0"
+language: {
+	version: "v0.9.0"
+}
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useUnreachableRegistry forgets any module resolved earlier and returns a
+// context that resolves modules from a registry that refuses connections.
+func useUnreachableRegistry(t *testing.T) context.Context {
+	t.Helper()
+	return useRegistry(t, "127.0.0.1:1+insecure")
+}
+
+// useRegistry forgets any module resolved or failed earlier and returns a
+// context that resolves modules from registry.
+func useRegistry(t *testing.T, registry string) context.Context {
+	t.Helper()
+	originalRoot, originalVersion, originalTime := resolvedSchemaRoot, resolvedSchemaVersion, resolvedSchemaTime
+	originalFailure, originalFailureTime := schemaFailure, schemaFailureTime
+	t.Cleanup(func() {
+		resolvedSchemaRoot, resolvedSchemaVersion, resolvedSchemaTime = originalRoot, originalVersion, originalTime
+		schemaFailure, schemaFailureTime = originalFailure, originalFailureTime
+	})
+	resolvedSchemaRoot, resolvedSchemaVersion, resolvedSchemaTime = "", "", time.Time{}
+	schemaFailure, schemaFailureTime = nil, time.Time{}
+	t.Setenv("CUE_CACHE_DIR", t.TempDir())
+	return withTestConfig(func(c *Config) { c.Registry = RegistryConfig{Registry: registry} })
+}
+
+func TestLoadGemaraSchemaSnapshotFallback(t *testing.T) {
+	ctx := useUnreachableRegistry(t)
+
+	schema, err := loadGemaraSchema(ctx)
+	require.NoError(t, err, "should fall back to the embedded schema")
+	assert.True(t, schema.snapshot, "should mark the schema as the fallback")
+
+	version, err := os.ReadFile(filepath.Join("schema_snapshot", "VERSION"))
+	require.NoError(t, err, "should read the snapshot version")
+	assert.Equal(t, string(version[:len(version)-1]), schema.version, "should report the embedded version")
+	_, err = schema.lookupDefinition("#ControlCatalog")
+	assert.NoError(t, err, "should compile the embedded schema")
+	assert.Empty(t, resolvedSchemaRoot, "should not remember the fallback as a resolution")
+}
+
+func TestLoadGemaraSchemaCachesFailure(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+	ctx := useRegistry(t, strings.TrimPrefix(server.URL, "http://")+"+insecure")
+
+	schema, err := loadGemaraSchema(ctx)
+	require.NoError(t, err, "should fall back to the embedded schema")
+	assert.True(t, schema.snapshot)
+	contacted := requests.Load()
+	require.NotZero(t, contacted, "should ask the registry first")
+
+	schema, err = loadGemaraSchema(ctx)
+	require.NoError(t, err)
+	assert.True(t, schema.snapshot, "should serve the embedded schema again")
+	assert.Equal(t, contacted, requests.Load(), "should not retry the registry while the failure is recent")
+
+	schemaFailureTime = time.Now().Add(-schemaFailureTTL)
+	_, err = loadGemaraSchema(ctx)
+	require.NoError(t, err)
+	assert.Greater(t, requests.Load(), contacted, "should retry the registry once the failure expires")
+}
+
+func TestValidateGemaraArtifactSnapshotFallback(t *testing.T) {
+	ctx := useUnreachableRegistry(t)
+	content, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err, "should read test catalog")
+
+	_, output, err := ValidateGemaraArtifact(ctx, nil, InputValidateGemaraArtifact{
+		ArtifactContent: string(content),
+		Definition:      "#ControlCatalog",
+	})
+	require.NoError(t, err, "should validate against the embedded schema")
+	assert.True(t, output.Valid, "catalog should be valid: %v", output.Errors)
+	assert.True(t, output.SchemaFallback, "should flag the fallback")
+	assert.NotEmpty(t, output.SchemaVersion, "should report the embedded version")
+	assert.Contains(t, output.Message, "embedded schema", "should explain the fallback")
+}
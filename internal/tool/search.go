@@ -0,0 +1,544 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Embedding providers for semantic search.
+const (
+	// EmbeddingHashing hashes the words of a text in process, needing no
+	// model download or network access. It is a bag-of-words match rather
+	// than a semantic embedding.
+	EmbeddingHashing = "hashing"
+	// EmbeddingOpenAI calls an OpenAI-compatible /embeddings endpoint, which
+	// also covers local model servers such as Ollama and llama.cpp.
+	EmbeddingOpenAI = "openai"
+)
+
+const (
+	// DefaultEmbeddingAPIURL is the API root of the openai provider.
+	DefaultEmbeddingAPIURL = "https://api.openai.com/v1"
+	// DefaultEmbeddingModel is the model requested from the openai provider.
+	DefaultEmbeddingModel = "text-embedding-3-small"
+	// DefaultSearchIndexName is the file name 'gemara-mcp index' writes under
+	// the .gemara directory of the indexed directory.
+	DefaultSearchIndexName = "controls-index.json"
+
+	searchIndexVersion     = 1
+	hashingEmbeddingDims   = 512
+	embeddingBatchSize     = 64
+	defaultSearchLimit     = 10
+	maxSearchLimit         = 50
+	searchObjectiveExcerpt = 240
+)
+
+// SemanticSearchConfig configures the semantic control search. The
+// search_controls tool is only registered when Provider is set.
+type SemanticSearchConfig struct {
+	// Provider is EmbeddingHashing or EmbeddingOpenAI.
+	Provider string
+	// Model is the embedding model requested from the openai provider.
+	Model string
+	// APIURL is the API root of the openai provider.
+	APIURL string
+	// APIKey authenticates to the openai provider, if it needs it.
+	APIKey string
+	// IndexPath is an index file built by 'gemara-mcp index'. The server
+	// reads it as its starting index but never writes it.
+	IndexPath string
+}
+
+// Validate checks the semantic search configuration.
+func (c SemanticSearchConfig) Validate() error {
+	switch c.Provider {
+	case "":
+		return nil
+	case EmbeddingHashing:
+	case EmbeddingOpenAI:
+		if c.APIURL == "" || c.Model == "" {
+			return fmt.Errorf("the openai embedding provider needs an API URL and a model")
+		}
+	default:
+		return fmt.Errorf("unknown embedding provider %q (available: %s, %s)", c.Provider, EmbeddingHashing, EmbeddingOpenAI)
+	}
+	return nil
+}
+
+// ControlSearchIndex holds the embedding of every control of the indexed catalogs.
+type ControlSearchIndex struct {
+	Version  int    `json:"version"`
+	Provider string `json:"provider"`
+	Model    string `json:"model,omitempty"`
+	Built    string `json:"built"`
+	// Sources maps each indexed file to the fingerprint it had when indexed.
+	Sources map[string]string `json:"sources"`
+	Entries []IndexedControl  `json:"entries"`
+}
+
+// IndexedControl is a control and the embedding of its text.
+type IndexedControl struct {
+	Catalog   string    `json:"catalog"`
+	Control   string    `json:"control"`
+	Title     string    `json:"title"`
+	Objective string    `json:"objective,omitempty"`
+	Source    string    `json:"source"`
+	Vector    []float32 `json:"vector"`
+}
+
+// embeddingModel names the model of the configured provider, as recorded in the index.
+func embeddingModel(ctx context.Context) string {
+	config := serverConfig(ctx).SemanticSearch
+	if config.Provider == EmbeddingHashing {
+		return fmt.Sprintf("hashing-%d", hashingEmbeddingDims)
+	}
+	return config.Model
+}
+
+// BuildControlIndex indexes the controls of the catalogs under dir, reusing
+// the entries of previous for files that have not changed since.
+func BuildControlIndex(ctx context.Context, dir string, previous *ControlSearchIndex) (ControlSearchIndex, error) {
+	emb, err := newEmbedder(ctx)
+	if err != nil {
+		return ControlSearchIndex{}, err
+	}
+	files, err := findArtifactFiles(dir)
+	if err != nil {
+		return ControlSearchIndex{}, err
+	}
+
+	index := ControlSearchIndex{
+		Version:  searchIndexVersion,
+		Provider: serverConfig(ctx).SemanticSearch.Provider,
+		Model:    embeddingModel(ctx),
+		Built:    time.Now().UTC().Format(time.RFC3339),
+		Sources:  map[string]string{},
+		Entries:  []IndexedControl{},
+	}
+	reusable := previous != nil && previous.Version == index.Version && previous.Provider == index.Provider && previous.Model == index.Model
+
+	var pending []IndexedControl
+	var texts []string
+	for _, file := range files {
+		fingerprint, err := fileFingerprint([]string{file})
+		if err != nil {
+			return ControlSearchIndex{}, err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			rel = file
+		}
+		rel = filepath.ToSlash(rel)
+
+		if reusable && previous.Sources[rel] == fingerprint {
+			index.Sources[rel] = fingerprint
+			for _, e := range previous.Entries {
+				if e.Source == rel {
+					index.Entries = append(index.Entries, e)
+				}
+			}
+			continue
+		}
+
+		content, err := readArtifactFile(ctx, file)
+		if err != nil {
+			continue
+		}
+		doc, err := parseArtifact(ctx, string(content))
+		if err != nil || artifactKind(doc) != "ControlCatalog" {
+			continue
+		}
+		index.Sources[rel] = fingerprint
+		metadata, _ := doc["metadata"].(map[string]interface{})
+		for _, c := range mapList(doc["controls"]) {
+			control := IndexedControl{
+				Catalog:   stringField(metadata, "id"),
+				Control:   stringField(c, "id"),
+				Title:     strings.TrimSpace(stringField(c, "title")),
+				Objective: strings.TrimSpace(stringField(c, "objective")),
+				Source:    rel,
+			}
+			pending = append(pending, control)
+			texts = append(texts, controlSearchText(c))
+		}
+	}
+
+	for start := 0; start < len(texts); start += embeddingBatchSize {
+		end := min(start+embeddingBatchSize, len(texts))
+		vectors, err := emb.embed(ctx, texts[start:end])
+		if err != nil {
+			return ControlSearchIndex{}, err
+		}
+		if len(vectors) != end-start {
+			return ControlSearchIndex{}, fmt.Errorf("embedding provider returned %d vectors for %d texts", len(vectors), end-start)
+		}
+		for i, v := range vectors {
+			pending[start+i].Vector = normalizeVector(v)
+		}
+	}
+	index.Entries = append(index.Entries, pending...)
+	sort.SliceStable(index.Entries, func(i, j int) bool {
+		if index.Entries[i].Source != index.Entries[j].Source {
+			return index.Entries[i].Source < index.Entries[j].Source
+		}
+		return index.Entries[i].Control < index.Entries[j].Control
+	})
+	return index, nil
+}
+
+// controlSearchText is the text embedded for a control: its title, objective,
+// and assessment requirements.
+func controlSearchText(control map[string]interface{}) string {
+	parts := []string{stringField(control, "title"), stringField(control, "objective")}
+	for _, r := range mapList(control["assessment-requirements"]) {
+		parts = append(parts, stringField(r, "text"))
+	}
+	return strings.Join(parts, "\n")
+}
+
+// LoadControlIndex reads an index file.
+func LoadControlIndex(path string) (*ControlSearchIndex, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var index ControlSearchIndex
+	if err := json.Unmarshal(raw, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse search index %s: %w", path, err)
+	}
+	return &index, nil
+}
+
+// SaveControlIndex writes an index file, creating its directory.
+func SaveControlIndex(path string, index ControlSearchIndex) error {
+	raw, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to encode search index: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create search index directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+var (
+	searchIndexMu sync.Mutex
+	searchIndex   *ControlSearchIndex
+)
+
+// currentControlIndex returns the index, refreshing it from the workspace
+// when any catalog in it changed since it was built. Tenants have their own
+// index of their workspace root.
+func currentControlIndex(ctx context.Context) (*ControlSearchIndex, bool, error) {
+	if t := requestTenant(ctx); t != nil {
+		t.searchMu.Lock()
+		defer t.searchMu.Unlock()
+		return refreshControlIndex(ctx, &t.search, t.WorkspaceRoot, "")
+	}
+	config := serverConfig(ctx)
+	searchIndexMu.Lock()
+	defer searchIndexMu.Unlock()
+	return refreshControlIndex(ctx, &searchIndex, config.WorkspaceRoot, config.SemanticSearch.IndexPath)
+}
+
+// refreshControlIndex rebuilds the index of the workspace at root when it is
+// stale. The index is kept in memory, and in the configured storage so that a
+// restart does not embed every control again; it starts from the stored
+// index, or else the index file at path. The workspace is never written to.
+// The caller holds the lock guarding index.
+func refreshControlIndex(ctx context.Context, index **ControlSearchIndex, root, path string) (*ControlSearchIndex, bool, error) {
+	if *index == nil && root != "" {
+		*index = loadStoredControlIndex(ctx, root)
+	}
+	if *index == nil && path != "" {
+		loaded, err := LoadControlIndex(path)
+		switch {
+		case err == nil:
+			*index = loaded
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, false, err
+		}
+	}
+	if root == "" {
+		if *index == nil {
+			return nil, false, fmt.Errorf("no search index; set --workspace-root, or --search-index to an index built with 'gemara-mcp index'")
+		}
+		return *index, false, nil
+	}
+	if *index != nil && !controlIndexStale(ctx, *index, root) {
+		return *index, false, nil
+	}
+
+	rebuilt, err := BuildControlIndex(ctx, root, *index)
+	if err != nil {
+		return nil, false, err
+	}
+	storeControlIndex(ctx, root, rebuilt)
+	*index = &rebuilt
+	return *index, true, nil
+}
+
+// controlIndexKey returns the storage key of the search index of a workspace.
+func controlIndexKey(root string) string {
+	sum := sha256.Sum256([]byte(root))
+	return "search/controls/" + hex.EncodeToString(sum[:]) + ".json"
+}
+
+// loadStoredControlIndex returns the index of the workspace at root from the
+// configured storage, or nil.
+func loadStoredControlIndex(ctx context.Context, root string) *ControlSearchIndex {
+	storage := serverConfig(ctx).Storage
+	if storage == nil {
+		return nil
+	}
+	value, err := storage.Get(ctx, controlIndexKey(root))
+	if err != nil {
+		if !errors.Is(err, ErrStorageNotFound) {
+			emitUpstreamFailure(ctx, "search index storage", err)
+		}
+		return nil
+	}
+	var index ControlSearchIndex
+	if err := json.Unmarshal(value, &index); err != nil {
+		return nil
+	}
+	return &index
+}
+
+// storeControlIndex keeps the index of the workspace at root in the
+// configured storage, if any.
+func storeControlIndex(ctx context.Context, root string, index ControlSearchIndex) {
+	storage := serverConfig(ctx).Storage
+	if storage == nil {
+		return
+	}
+	value, err := json.Marshal(index)
+	if err == nil {
+		err = storage.Put(ctx, controlIndexKey(root), value)
+	}
+	if err != nil {
+		emitUpstreamFailure(ctx, "search index storage", err)
+	}
+}
+
+// controlIndexStale reports whether an index was built with another provider,
+// or from other files of the workspace at root than those present now.
+func controlIndexStale(ctx context.Context, index *ControlSearchIndex, root string) bool {
+	if index.Version != searchIndexVersion || index.Provider != serverConfig(ctx).SemanticSearch.Provider || index.Model != embeddingModel(ctx) {
+		return true
+	}
+	files, err := findArtifactFiles(root)
+	if err != nil {
+		return true
+	}
+	seen := 0
+	for _, file := range files {
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return true
+		}
+		fingerprint, err := fileFingerprint([]string{file})
+		if err != nil {
+			return true
+		}
+		previous, indexed := index.Sources[filepath.ToSlash(rel)]
+		if indexed {
+			seen++
+			if previous != fingerprint {
+				return true
+			}
+			continue
+		}
+		// A file that is not yet indexed is only relevant if it is a catalog
+		if content, err := readArtifactFile(ctx, file); err == nil {
+			if doc, err := parseArtifact(ctx, string(content)); err == nil && artifactKind(doc) == "ControlCatalog" {
+				return true
+			}
+		}
+	}
+	return seen != len(index.Sources)
+}
+
+// MetadataSearchControls describes the SearchControls tool.
+var MetadataSearchControls = &mcp.Tool{
+	Name: "search_controls",
+	Description: "Find the controls most relevant to a natural-language query, such as 'encrypt data at rest', by " +
+		"semantic similarity of their title, objective, and assessment requirements across the indexed catalogs.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"query"},
+		"properties": withPagination(map[string]interface{}{
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": "What the controls should be about, in natural language",
+			},
+			"catalogs": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Only search the catalogs with these metadata IDs",
+			},
+			"limit": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"maximum":     maxSearchLimit,
+				"description": fmt.Sprintf("Maximum number of controls to return (default: %d)", defaultSearchLimit),
+			},
+			"min_score": map[string]interface{}{
+				"type":        "number",
+				"description": "Leave out controls with a lower cosine similarity, from -1 to 1",
+			},
+		}, "controls"),
+	},
+}
+
+// InputSearchControls is the input for the SearchControls tool. Its Limit
+// defaults to defaultSearchLimit rather than to all controls.
+type InputSearchControls struct {
+	PageInput
+	Query    string   `json:"query"`
+	Catalogs []string `json:"catalogs,omitempty"`
+	MinScore float64  `json:"min_score,omitempty"`
+}
+
+// ControlMatch is a control found by a search.
+type ControlMatch struct {
+	Catalog   string  `json:"catalog"`
+	Control   string  `json:"control"`
+	Title     string  `json:"title"`
+	Objective string  `json:"objective,omitempty"`
+	Source    string  `json:"source"`
+	Score     float64 `json:"score"`
+}
+
+// OutputSearchControls is the output for the SearchControls tool.
+type OutputSearchControls struct {
+	Results  []ControlMatch `json:"results"`
+	Indexed  int            `json:"indexed"`
+	Provider string         `json:"provider"`
+	// Reindexed is set when the index was rebuilt for this search.
+	Reindexed bool `json:"reindexed,omitempty"`
+	// Page describes which controls were returned.
+	Page    *PageInfo `json:"page,omitempty"`
+	Message string    `json:"message"`
+}
+
+// SearchControls returns the indexed controls most similar to a query.
+func SearchControls(ctx context.Context, _ *mcp.CallToolRequest, input InputSearchControls) (*mcp.CallToolResult, OutputSearchControls, error) {
+	if strings.TrimSpace(input.Query) == "" {
+		return nil, OutputSearchControls{}, fmt.Errorf("query is required")
+	}
+	if input.Limit == 0 {
+		input.Limit = defaultSearchLimit
+	}
+	if input.Limit > maxSearchLimit {
+		return nil, OutputSearchControls{}, fmt.Errorf("limit must be at most %d", maxSearchLimit)
+	}
+
+	index, reindexed, err := currentControlIndex(ctx)
+	if err != nil {
+		return nil, OutputSearchControls{}, err
+	}
+	emb, err := newEmbedder(ctx)
+	if err != nil {
+		return nil, OutputSearchControls{}, err
+	}
+	vectors, err := emb.embed(ctx, []string{input.Query})
+	if err != nil {
+		return nil, OutputSearchControls{}, err
+	}
+	if len(vectors) != 1 {
+		return nil, OutputSearchControls{}, fmt.Errorf("embedding provider returned %d vectors for the query", len(vectors))
+	}
+	query := normalizeVector(vectors[0])
+
+	output := OutputSearchControls{Results: []ControlMatch{}, Indexed: len(index.Entries), Provider: index.Provider, Reindexed: reindexed}
+	for _, e := range index.Entries {
+		if len(input.Catalogs) > 0 && !slices.Contains(input.Catalogs, e.Catalog) {
+			continue
+		}
+		score := vectorSimilarity(query, e.Vector)
+		if input.MinScore != 0 && score < input.MinScore {
+			continue
+		}
+		output.Results = append(output.Results, ControlMatch{
+			Catalog:   e.Catalog,
+			Control:   e.Control,
+			Title:     e.Title,
+			Objective: excerpt(e.Objective, searchObjectiveExcerpt),
+			Source:    e.Source,
+			Score:     math.Round(score*1000) / 1000,
+		})
+	}
+	sort.SliceStable(output.Results, func(i, j int) bool { return output.Results[i].Score > output.Results[j].Score })
+
+	output.Message = fmt.Sprintf("%d of %d indexed control(s) match %q", len(output.Results), len(index.Entries), input.Query)
+	page, info, err := paginate(output.Results, input.PageInput)
+	if err != nil {
+		return nil, OutputSearchControls{}, err
+	}
+	output.Results, output.Page = page, &info
+	return nil, output, nil
+}
+
+// normalizeVector scales v to unit length so that dot products are cosine similarities.
+func normalizeVector(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := float32(math.Sqrt(sum))
+	out := make([]float32, len(v))
+	for i, x := range v {
+		out[i] = x / norm
+	}
+	return out
+}
+
+// vectorSimilarity is the cosine similarity of two unit vectors; vectors of different lengths score 0.
+func vectorSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
+
+// excerpt shortens text to at most n bytes at a word boundary.
+func excerpt(text string, n int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if len(text) <= n {
+		return text
+	}
+	cut := strings.LastIndexByte(text[:n], ' ')
+	if cut <= 0 {
+		cut = n
+	}
+	return text[:cut] + "…"
+}
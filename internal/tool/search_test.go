@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const searchCatalog = `metadata:
+  id: SEC
+title: Security Catalog
+controls:
+  - id: SEC.C01
+    title: Encrypt data at rest
+    objective: Stored data is encrypted with managed keys.
+    assessment-requirements:
+      - id: SEC.C01.TR01
+        text: Storage volumes use encryption at rest.
+  - id: SEC.C02
+    title: Require multi-factor authentication
+    objective: Users authenticate with a second factor before accessing the console.
+  - id: SEC.C03
+    title: Retain audit logs
+    objective: Audit logs are kept for one year.
+`
+
+const opsCatalog = `metadata:
+  id: OPS
+title: Operations Catalog
+controls:
+  - id: OPS.C01
+    title: Back up databases
+    objective: Databases are backed up daily and backups are encrypted.
+`
+
+// useSemanticSearch returns a context that enables semantic search with
+// config, indexing root.
+func useSemanticSearch(t *testing.T, config SemanticSearchConfig, root string) context.Context {
+	t.Helper()
+	originalIndex := searchIndex
+	t.Cleanup(func() { searchIndex = originalIndex })
+	searchIndex = nil
+	return withTestConfig(func(c *Config) { c.SemanticSearch, c.WorkspaceRoot = config, root })
+}
+
+func TestHashingEmbedder(t *testing.T) {
+	vectors, err := hashingEmbedder{dims: hashingEmbeddingDims}.embed(context.Background(), []string{
+		"encryption of stored data",
+		"Encrypt data at rest",
+		"Retain audit logs",
+	})
+	require.NoError(t, err)
+	require.Len(t, vectors, 3)
+	query, related, unrelated := normalizeVector(vectors[0]), normalizeVector(vectors[1]), normalizeVector(vectors[2])
+	assert.Greater(t, vectorSimilarity(query, related), vectorSimilarity(query, unrelated))
+	assert.InDelta(t, 1, vectorSimilarity(related, related), 1e-6)
+}
+
+func TestSearchControls(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, root, "security.yaml", searchCatalog)
+	writeTestFile(t, root, "ops.yaml", opsCatalog)
+	ctx := useSemanticSearch(t, SemanticSearchConfig{Provider: EmbeddingHashing}, root)
+	storage, err := OpenStorage(t.TempDir())
+	require.NoError(t, err)
+	serverConfig(ctx).Storage = storage
+	serverConfig(ctx).DryRun = true
+
+	_, output, err := SearchControls(ctx, nil, InputSearchControls{Query: "is stored data encrypted?", PageInput: PageInput{Limit: 2}})
+	require.NoError(t, err)
+	assert.True(t, output.Reindexed, "the first search should build the index")
+	assert.Equal(t, 4, output.Indexed)
+	require.Len(t, output.Results, 2)
+	assert.Equal(t, "SEC.C01", output.Results[0].Control)
+	assert.GreaterOrEqual(t, output.Results[0].Score, output.Results[1].Score)
+	require.NotNil(t, output.Page)
+	assert.Equal(t, 4, output.Page.Total)
+
+	_, next, err := SearchControls(ctx, nil, InputSearchControls{Query: "is stored data encrypted?", PageInput: PageInput{Cursor: output.Page.NextCursor}})
+	require.NoError(t, err)
+	require.Len(t, next.Results, 2, "the next page continues after the first")
+	assert.NotContains(t, next.Results, output.Results[0])
+	assert.Empty(t, next.Page.NextCursor, "the last page has no cursor")
+
+	_, err = os.Stat(filepath.Join(root, ".gemara"))
+	assert.True(t, os.IsNotExist(err), "the index should not be written to the workspace")
+	stored := loadStoredControlIndex(ctx, root)
+	require.NotNil(t, stored, "the index should be kept in storage")
+	assert.Len(t, stored.Entries, 4)
+	assert.Equal(t, "hashing-512", stored.Model)
+
+	// A restart starts from the stored index instead of embedding every control again
+	searchIndex = nil
+	_, output, err = SearchControls(ctx, nil, InputSearchControls{Query: "is stored data encrypted?"})
+	require.NoError(t, err)
+	assert.False(t, output.Reindexed, "the stored index should be reused")
+
+	_, output, err = SearchControls(ctx, nil, InputSearchControls{Query: "encrypted backups", Catalogs: []string{"OPS"}})
+	require.NoError(t, err)
+	assert.False(t, output.Reindexed, "an unchanged workspace should reuse the index")
+	require.Len(t, output.Results, 1)
+	assert.Equal(t, "OPS.C01", output.Results[0].Control)
+
+	writeTestFile(t, root, "ops.yaml", opsCatalog+`  - id: OPS.C02
+    title: Patch hosts
+    objective: Operating system patches are applied within a week.
+`)
+	_, output, err = SearchControls(ctx, nil, InputSearchControls{Query: "apply patches", MinScore: 0.2})
+	require.NoError(t, err)
+	assert.True(t, output.Reindexed, "a changed catalog should be reindexed")
+	assert.Equal(t, 5, output.Indexed)
+	require.NotEmpty(t, output.Results)
+	assert.Equal(t, "OPS.C02", output.Results[0].Control)
+	for _, r := range output.Results {
+		assert.GreaterOrEqual(t, r.Score, 0.2)
+	}
+}
+
+func TestSearchControlsErrors(t *testing.T) {
+	ctx := useSemanticSearch(t, SemanticSearchConfig{Provider: EmbeddingHashing}, "")
+
+	tests := []struct {
+		name        string
+		input       InputSearchControls
+		errContains string
+	}{
+		{name: "empty query", input: InputSearchControls{Query: " "}, errContains: "query is required"},
+		{name: "limit too large", input: InputSearchControls{Query: "x", PageInput: PageInput{Limit: maxSearchLimit + 1}}, errContains: "at most"},
+		{name: "no index or workspace", input: InputSearchControls{Query: "x"}, errContains: "gemara-mcp index"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := SearchControls(ctx, nil, tt.input)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+}
+
+func TestAPIEmbedder(t *testing.T) {
+	require.NoError(t, useHTTPConfig(t, HTTPConfig{}))
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		if r.URL.Path != "/v1/embeddings" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"message":"unknown path"}}`))
+			return
+		}
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		data := make([]map[string]interface{}, len(req.Input))
+		// Answer in reverse order to check that vectors follow the index field
+		for i := range req.Input {
+			j := len(req.Input) - 1 - i
+			data[i] = map[string]interface{}{"index": j, "embedding": []float32{float32(j), 1}}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	t.Cleanup(server.Close)
+
+	e := &apiEmbedder{config: SemanticSearchConfig{APIURL: server.URL + "/v1/", Model: "m", APIKey: "secret"}, http: newHTTPClient()}
+	vectors, err := e.embed(context.Background(), []string{"a", "b", "c"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{0, 1}, {1, 1}, {2, 1}}, vectors)
+	assert.Equal(t, "Bearer secret", gotAuth)
+
+	e.config.APIURL = server.URL
+	_, err = e.embed(context.Background(), []string{"a"})
+	assert.ErrorContains(t, err, "unknown path")
+}
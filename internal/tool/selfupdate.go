@@ -0,0 +1,291 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+const (
+	// DefaultReleaseRepo is the GitHub repository gemara-mcp releases are published to.
+	DefaultReleaseRepo = "gemaraproj/gemara-mcp"
+	// releaseChecksums lists the sha256 digest of every release asset, in
+	// sha256sum format, and releaseChecksumsBundle is its cosign signature.
+	releaseChecksums       = "checksums.txt"
+	releaseChecksumsBundle = releaseChecksums + ".sigstore.json"
+	// releaseOIDCIssuer issues the certificates of release workflows.
+	releaseOIDCIssuer = "https://token.actions.githubusercontent.com"
+	// releaseWorkflow is the GitHub Actions workflow that signs release checksums.
+	releaseWorkflow = ".github/workflows/release.yml"
+	// maxReleaseAssetSize caps the size of a downloaded release asset.
+	maxReleaseAssetSize = 256 << 20
+)
+
+// SelfUpdateOptions configures SelfUpdate.
+type SelfUpdateOptions struct {
+	// Repo is the owner/name of the GitHub repository to update from.
+	Repo string
+	// Version is the release tag to install; the latest release when empty.
+	Version string
+	// Current is the version of the running binary.
+	Current string
+	// Executable is the binary to replace; the running executable when empty.
+	Executable string
+	// Check reports whether an update is available without installing it.
+	Check bool
+	// Force installs the release even when it is not newer than Current.
+	Force bool
+	// SkipSignature trusts checksums.txt without verifying its cosign
+	// signature. The downloaded binary is still checked against it.
+	SkipSignature bool
+}
+
+// SelfUpdateResult describes what SelfUpdate found and did.
+type SelfUpdateResult struct {
+	Current  string `json:"current"`
+	Release  string `json:"release"`
+	Asset    string `json:"asset,omitempty"`
+	Digest   string `json:"digest,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Newer    bool   `json:"newer"`
+	Updated  bool   `json:"updated"`
+	Verified bool   `json:"signature_verified"`
+}
+
+// githubRelease is the part of a GitHub release SelfUpdate uses.
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// releaseAssetName is the binary asset for the running platform.
+func releaseAssetName() string {
+	name := fmt.Sprintf("gemara-mcp_%s_%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// SelfUpdate replaces the running binary with a GitHub release of gemara-mcp.
+// The release's checksums.txt must carry a cosign signature from the
+// repository's release workflow (or from the configured Cosign.PublicKey), and the
+// downloaded binary must match its digest there, before anything is replaced.
+func SelfUpdate(ctx context.Context, opts SelfUpdateOptions) (SelfUpdateResult, error) {
+	if opts.Repo == "" {
+		opts.Repo = DefaultReleaseRepo
+	}
+	if !githubRepoName.MatchString(opts.Repo) {
+		return SelfUpdateResult{}, fmt.Errorf("repo must be owner/name, got %q", opts.Repo)
+	}
+
+	client := &githubClient{http: newHTTPClient(), config: serverConfig(ctx).GitHub}
+	releasePath := "repos/" + opts.Repo + "/releases/latest"
+	if opts.Version != "" {
+		releasePath = "repos/" + opts.Repo + "/releases/tags/" + url.PathEscape(opts.Version)
+	}
+	var release githubRelease
+	if err := client.getJSON(ctx, releasePath, nil, &release); err != nil {
+		return SelfUpdateResult{}, fmt.Errorf("failed to find release: %w", err)
+	}
+
+	result := SelfUpdateResult{
+		Current: opts.Current,
+		Release: release.TagName,
+		Newer:   compareSemver(release.TagName, opts.Current) > 0,
+	}
+	if opts.Check || !result.Newer && !opts.Force {
+		return result, nil
+	}
+
+	assets := map[string]string{}
+	for _, a := range release.Assets {
+		assets[a.Name] = a.URL
+	}
+	result.Asset = releaseAssetName()
+	for _, name := range []string{result.Asset, releaseChecksums} {
+		if assets[name] == "" {
+			return result, fmt.Errorf("release %s has no %s asset", release.TagName, name)
+		}
+	}
+
+	checksums, err := downloadReleaseAsset(ctx, assets[releaseChecksums])
+	if err != nil {
+		return result, err
+	}
+	if !opts.SkipSignature {
+		if assets[releaseChecksumsBundle] == "" {
+			return result, fmt.Errorf("release %s has no %s signature; pass --skip-signature to trust its checksums unverified", release.TagName, releaseChecksumsBundle)
+		}
+		bundle, err := downloadReleaseAsset(ctx, assets[releaseChecksumsBundle])
+		if err != nil {
+			return result, err
+		}
+		if err := verifyReleaseChecksums(ctx, opts.Repo, release.TagName, checksums, bundle); err != nil {
+			return result, err
+		}
+		result.Verified = true
+	}
+
+	want, err := releaseChecksum(checksums, result.Asset)
+	if err != nil {
+		return result, err
+	}
+	binary, err := downloadReleaseAsset(ctx, assets[result.Asset])
+	if err != nil {
+		return result, err
+	}
+	sum := sha256.Sum256(binary)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return result, fmt.Errorf("%s has sha256 %s, but %s lists %s", result.Asset, got, releaseChecksums, want)
+	}
+	result.Digest = "sha256:" + want
+
+	path := opts.Executable
+	if path == "" {
+		if path, err = os.Executable(); err != nil {
+			return result, fmt.Errorf("failed to locate the running binary: %w", err)
+		}
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if err := replaceExecutable(path, binary); err != nil {
+		return result, err
+	}
+	result.Path = path
+	result.Updated = true
+	return result, nil
+}
+
+// downloadReleaseAsset fetches a release asset by its download URL.
+func downloadReleaseAsset(ctx context.Context, assetURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, assetURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := newHTTPClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", assetURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: status %d", assetURL, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxReleaseAssetSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", assetURL, err)
+	}
+	if len(body) > maxReleaseAssetSize {
+		return nil, fmt.Errorf("%s exceeds the %d byte limit", assetURL, maxReleaseAssetSize)
+	}
+	return body, nil
+}
+
+// releaseSignerIdentity matches the certificate identity of the release
+// workflow of repo run for tag, and nothing else: not other workflows, nor
+// the release workflow run for a branch or another tag.
+func releaseSignerIdentity(repo, tag string) string {
+	return "^" + regexp.QuoteMeta("https://github.com/"+repo+"/"+releaseWorkflow+"@refs/tags/"+tag) + "$"
+}
+
+// verifyReleaseChecksums checks the cosign bundle of checksums.txt: against
+// Cosign.PublicKey when set, or else keylessly against the release workflow
+// of repo run for tag.
+func verifyReleaseChecksums(ctx context.Context, repo, tag string, checksums, bundle []byte) error {
+	dir, err := os.MkdirTemp("", "gemara-self-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to stage checksums for verification: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	checksumsPath := filepath.Join(dir, releaseChecksums)
+	bundlePath := filepath.Join(dir, releaseChecksumsBundle)
+	if err := os.WriteFile(checksumsPath, checksums, 0o600); err != nil {
+		return fmt.Errorf("failed to stage checksums for verification: %w", err)
+	}
+	if err := os.WriteFile(bundlePath, bundle, 0o600); err != nil {
+		return fmt.Errorf("failed to stage signature for verification: %w", err)
+	}
+
+	args := []string{"verify-blob", "--bundle", bundlePath}
+	cosign := serverConfig(ctx).Cosign
+	if cosign.PublicKey != "" {
+		args = append(args, "--key", cosign.PublicKey)
+	} else {
+		args = append(args, "--certificate-identity-regexp="+releaseSignerIdentity(repo, tag), "--certificate-oidc-issuer="+releaseOIDCIssuer)
+	}
+	if _, err := cosign.run(ctx, append(args, "--", checksumsPath)...); err != nil {
+		return fmt.Errorf("failed to verify the signature of %s (requires cosign; pass --skip-signature to trust it unverified): %w", releaseChecksums, err)
+	}
+	return nil
+}
+
+// releaseChecksum returns the hex sha256 digest listed for asset in a
+// sha256sum-format checksums file.
+func releaseChecksum(checksums []byte, asset string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// Binary-mode entries mark the file name with a leading *
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == asset {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s does not list %s", releaseChecksums, asset)
+}
+
+// replaceExecutable writes binary next to path and renames it over path, so a
+// failed download or write never leaves a partial binary behind. The old
+// binary is moved aside first, since Windows cannot overwrite a running
+// executable.
+func replaceExecutable(path string, binary []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the installed binary: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".new-*")
+	if err != nil {
+		return fmt.Errorf("failed to write update next to %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(binary)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write update next to %s: %w", path, err)
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()|0o111); err != nil {
+		return fmt.Errorf("failed to make the update executable: %w", err)
+	}
+
+	old := path + ".old"
+	_ = os.Remove(old)
+	if err := os.Rename(path, old); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		// Put the previous binary back so the installation keeps working
+		_ = os.Rename(old, path)
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	// Removing the old binary fails on Windows while it runs; it is removed next time
+	_ = os.Remove(old)
+	return nil
+}
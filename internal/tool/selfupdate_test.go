@@ -0,0 +1,165 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfUpdate(t *testing.T) {
+	require.NoError(t, useHTTPConfig(t, HTTPConfig{}))
+
+	binary := []byte("#!/bin/sh\necho v0.3.0\n")
+	sum := sha256.Sum256(binary)
+	checksums := fmt.Sprintf("%s  %s\n0000  other.tar.gz\n", hex.EncodeToString(sum[:]), releaseAssetName())
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		asset := func(name string) map[string]string {
+			return map[string]string{"name": name, "browser_download_url": server.URL + "/download/" + name}
+		}
+		switch r.URL.Path {
+		case "/repos/acme/gemara-mcp/releases/latest", "/repos/acme/gemara-mcp/releases/tags/v0.2.0":
+			tag := "v0.3.0"
+			if r.URL.Path != "/repos/acme/gemara-mcp/releases/latest" {
+				tag = "v0.2.0"
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"tag_name": tag,
+				"assets":   []map[string]string{asset(releaseAssetName()), asset(releaseChecksums)},
+			})
+		case "/repos/acme/tampered/releases/latest":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"tag_name": "v0.3.0",
+				"assets": []map[string]string{
+					{"name": releaseAssetName(), "browser_download_url": server.URL + "/download/tampered"},
+					asset(releaseChecksums),
+				},
+			})
+		case "/download/" + releaseAssetName():
+			_, _ = w.Write(binary)
+		case "/download/tampered":
+			_, _ = w.Write([]byte("not the release"))
+		case "/download/" + releaseChecksums:
+			_, _ = w.Write([]byte(checksums))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Not Found"}`))
+		}
+	}))
+	defer server.Close()
+	ctx := useGitHub(GitHubConfig{APIURL: server.URL})
+
+	tests := []struct {
+		name        string
+		opts        SelfUpdateOptions
+		wantErr     bool
+		errContains string
+		wantRelease string
+		wantUpdated bool
+	}{
+		{
+			name:        "installs a newer release",
+			opts:        SelfUpdateOptions{Repo: "acme/gemara-mcp", Current: "v0.2.0", SkipSignature: true},
+			wantRelease: "v0.3.0",
+			wantUpdated: true,
+		},
+		{
+			name:        "up to date",
+			opts:        SelfUpdateOptions{Repo: "acme/gemara-mcp", Current: "v0.3.0", SkipSignature: true},
+			wantRelease: "v0.3.0",
+		},
+		{
+			name:        "check only",
+			opts:        SelfUpdateOptions{Repo: "acme/gemara-mcp", Current: "v0.2.0", Check: true},
+			wantRelease: "v0.3.0",
+		},
+		{
+			name:        "force an older tag",
+			opts:        SelfUpdateOptions{Repo: "acme/gemara-mcp", Version: "v0.2.0", Current: "v0.3.0", Force: true, SkipSignature: true},
+			wantRelease: "v0.2.0",
+			wantUpdated: true,
+		},
+		{
+			name:        "unsigned release",
+			opts:        SelfUpdateOptions{Repo: "acme/gemara-mcp", Current: "v0.2.0"},
+			wantErr:     true,
+			errContains: "--skip-signature",
+		},
+		{
+			name:        "checksum mismatch",
+			opts:        SelfUpdateOptions{Repo: "acme/tampered", Current: "v0.2.0", SkipSignature: true},
+			wantErr:     true,
+			errContains: "but checksums.txt lists",
+		},
+		{
+			name:        "invalid repo",
+			opts:        SelfUpdateOptions{Repo: "https://github.com/acme/gemara-mcp"},
+			wantErr:     true,
+			errContains: "owner/name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executable := filepath.Join(t.TempDir(), "gemara-mcp")
+			require.NoError(t, os.WriteFile(executable, []byte("old"), 0o755))
+			tt.opts.Executable = executable
+
+			result, err := SelfUpdate(ctx, tt.opts)
+			content, readErr := os.ReadFile(executable)
+			require.NoError(t, readErr)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				assert.Equal(t, "old", string(content), "a failed update must leave the binary in place")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRelease, result.Release)
+			assert.Equal(t, tt.wantUpdated, result.Updated)
+			if !tt.wantUpdated {
+				assert.Equal(t, "old", string(content))
+				return
+			}
+			assert.Equal(t, binary, content)
+			info, err := os.Stat(executable)
+			require.NoError(t, err)
+			assert.NotZero(t, info.Mode().Perm()&0o111, "the update should be executable")
+			assert.NoFileExists(t, executable+".old")
+		})
+	}
+}
+
+func TestReleaseSignerIdentity(t *testing.T) {
+	identity := regexp.MustCompile(releaseSignerIdentity("acme/gemara-mcp", "v0.3.0"))
+	tests := []struct {
+		name      string
+		signer    string
+		wantMatch bool
+	}{
+		{name: "release workflow for the tag", signer: "https://github.com/acme/gemara-mcp/.github/workflows/release.yml@refs/tags/v0.3.0", wantMatch: true},
+		{name: "other workflow", signer: "https://github.com/acme/gemara-mcp/.github/workflows/ci.yml@refs/tags/v0.3.0"},
+		{name: "branch", signer: "https://github.com/acme/gemara-mcp/.github/workflows/release.yml@refs/heads/main"},
+		{name: "other tag", signer: "https://github.com/acme/gemara-mcp/.github/workflows/release.yml@refs/tags/v0.3.0-rc1"},
+		{name: "similar repository", signer: "https://github.com/acme/gemara-mcpx/.github/workflows/release.yml@refs/tags/v0.3.0"},
+		{name: "dots are literal", signer: "https://githubXcom/acme/gemara-mcp/.github/workflows/release.yml@refs/tags/v0.3.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantMatch, identity.MatchString(tt.signer))
+		})
+	}
+}
@@ -0,0 +1,283 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	signingModeKey     = "key"
+	signingModeKeyless = "keyless"
+)
+
+// CosignConfig configures signing and verification of artifacts with cosign.
+// Keyless signing uses the Sigstore OIDC flow; in unattended deployments set
+// SIGSTORE_ID_TOKEN. COSIGN_PASSWORD unlocks an encrypted Key.
+type CosignConfig struct {
+	// Binary is the cosign executable to run.
+	Binary string
+	// Key is the private key used to sign (a file or KMS URI such as
+	// awskms:///alias/gemara). Signing is keyless when it is empty.
+	Key string
+	// PublicKey verifies key-based signatures. Verification is keyless, against
+	// the certificate identity supplied by the caller, when it is empty.
+	PublicKey string
+}
+
+// artifactSourceProperties are the input properties for an artifact given
+// inline or by URI.
+func artifactSourceProperties(action string) map[string]interface{} {
+	return map[string]interface{}{
+		"artifact_content": map[string]interface{}{
+			"type":        "string",
+			"description": fmt.Sprintf("YAML content of the Gemara artifact to %s", action),
+		},
+		"artifact_uri": map[string]interface{}{
+			"type": "string",
+			"description": fmt.Sprintf("URI of the artifact to %s instead of inline content: file:// (within the workspace root), ", action) +
+				"https://, or gemara://examples/{definition}/{n}",
+		},
+	}
+}
+
+// MetadataSignGemaraArtifact describes the SignGemaraArtifact tool.
+var MetadataSignGemaraArtifact = &mcp.Tool{
+	Name: "sign_gemara_artifact",
+	Description: "Sign a Gemara artifact with cosign, keyless through Sigstore or with the server's configured key, and " +
+		"return a detached Sigstore bundle (signature, certificate, and transparency log entry) to publish alongside it.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": func() map[string]interface{} {
+			properties := artifactSourceProperties("sign")
+			properties["dry_run"] = dryRunProperty
+			return properties
+		}(),
+	},
+}
+
+// InputSignGemaraArtifact is the input for the SignGemaraArtifact tool.
+type InputSignGemaraArtifact struct {
+	ArtifactContent string `json:"artifact_content,omitempty"`
+	ArtifactURI     string `json:"artifact_uri,omitempty"`
+	DryRun          bool   `json:"dry_run,omitempty"`
+}
+
+// OutputSignGemaraArtifact is the output for the SignGemaraArtifact tool.
+type OutputSignGemaraArtifact struct {
+	// Digest is the SHA-256 digest of the signed bytes.
+	Digest string `json:"digest"`
+	Mode   string `json:"mode"`
+	// Bundle is the Sigstore bundle JSON to pass to verify_gemara_artifact_signature.
+	Bundle string `json:"bundle"`
+	DryRun bool   `json:"dry_run,omitempty"`
+	// Changes lists the changes made, or in a dry run, those that would be.
+	Changes []PlannedChange `json:"changes,omitempty"`
+	Message string          `json:"message"`
+}
+
+// SignGemaraArtifact signs an artifact and returns a detached bundle.
+func SignGemaraArtifact(ctx context.Context, req *mcp.CallToolRequest, input InputSignGemaraArtifact) (*mcp.CallToolResult, OutputSignGemaraArtifact, error) {
+	content, err := artifactSource(ctx, input.ArtifactContent, input.ArtifactURI)
+	if err != nil {
+		return nil, OutputSignGemaraArtifact{}, err
+	}
+	mode := signingModeKeyless
+	cosign := serverConfig(ctx).Cosign
+	if cosign.Key != "" {
+		mode = signingModeKey
+	}
+	changes := []PlannedChange{{Action: "sign", Target: contentDigest(content), Detail: mode}}
+	if dryRun(ctx, input.DryRun) {
+		output := OutputSignGemaraArtifact{Digest: contentDigest(content), Mode: mode, DryRun: true, Changes: changes}
+		output.Message = fmt.Sprintf("Dry run: would sign %s (%s)", output.Digest, mode)
+		return nil, output, nil
+	}
+	summary := "sign_gemara_artifact will sign the artifact with cosign."
+	if mode == signingModeKeyless {
+		summary += " Keyless signing publishes the signer's identity in the public Sigstore transparency log."
+	}
+	if err := approveChanges(ctx, req, summary, changes); err != nil {
+		return nil, OutputSignGemaraArtifact{}, err
+	}
+
+	dir, err := os.MkdirTemp("", "gemara-sign-*")
+	if err != nil {
+		return nil, OutputSignGemaraArtifact{}, fmt.Errorf("failed to stage artifact for signing: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	artifact := filepath.Join(dir, "artifact.yaml")
+	bundle := filepath.Join(dir, "artifact.sigstore.json")
+	if err := os.WriteFile(artifact, content, 0o600); err != nil {
+		return nil, OutputSignGemaraArtifact{}, fmt.Errorf("failed to stage artifact for signing: %w", err)
+	}
+
+	args := []string{"sign-blob", "--yes", "--bundle", bundle}
+	if cosign.Key != "" {
+		args = append(args, "--key", cosign.Key)
+	}
+	if _, err := cosign.run(ctx, append(args, "--", artifact)...); err != nil {
+		return nil, OutputSignGemaraArtifact{}, fmt.Errorf("failed to sign artifact: %w", err)
+	}
+	signed, err := os.ReadFile(bundle)
+	if err != nil {
+		return nil, OutputSignGemaraArtifact{}, fmt.Errorf("cosign did not write a bundle: %w", err)
+	}
+
+	output := OutputSignGemaraArtifact{Digest: contentDigest(content), Mode: mode, Bundle: string(signed), Changes: changes}
+	output.Message = fmt.Sprintf("Signed %s (%s); publish the bundle alongside the artifact", output.Digest, mode)
+	return nil, output, nil
+}
+
+// MetadataVerifyGemaraArtifactSignature describes the VerifyGemaraArtifactSignature tool.
+var MetadataVerifyGemaraArtifactSignature = &mcp.Tool{
+	Name: "verify_gemara_artifact_signature",
+	Description: "Verify a Gemara artifact against a detached Sigstore bundle with cosign. Key-based signatures are " +
+		"checked with the server's configured public key; keyless signatures require the expected signer identity and OIDC issuer.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"bundle"},
+		"properties": func() map[string]interface{} {
+			properties := artifactSourceProperties("verify")
+			properties["bundle"] = map[string]interface{}{
+				"type":        "string",
+				"description": "Sigstore bundle JSON produced when the artifact was signed",
+			}
+			properties["certificate_identity"] = map[string]interface{}{
+				"type":        "string",
+				"description": "Expected signer identity for keyless signatures (e.g., an email or workflow URL)",
+			}
+			properties["certificate_oidc_issuer"] = map[string]interface{}{
+				"type":        "string",
+				"description": "Expected OIDC issuer for keyless signatures (e.g., 'https://token.actions.githubusercontent.com')",
+			}
+			return properties
+		}(),
+	},
+}
+
+// InputVerifyGemaraArtifactSignature is the input for the VerifyGemaraArtifactSignature tool.
+type InputVerifyGemaraArtifactSignature struct {
+	ArtifactContent       string `json:"artifact_content,omitempty"`
+	ArtifactURI           string `json:"artifact_uri,omitempty"`
+	Bundle                string `json:"bundle"`
+	CertificateIdentity   string `json:"certificate_identity,omitempty"`
+	CertificateOIDCIssuer string `json:"certificate_oidc_issuer,omitempty"`
+}
+
+// OutputVerifyGemaraArtifactSignature is the output for the VerifyGemaraArtifactSignature tool.
+type OutputVerifyGemaraArtifactSignature struct {
+	Digest   string `json:"digest"`
+	Mode     string `json:"mode"`
+	Verified bool   `json:"verified"`
+	Message  string `json:"message"`
+}
+
+// VerifyGemaraArtifactSignature checks an artifact against its detached bundle.
+func VerifyGemaraArtifactSignature(ctx context.Context, _ *mcp.CallToolRequest, input InputVerifyGemaraArtifactSignature) (*mcp.CallToolResult, OutputVerifyGemaraArtifactSignature, error) {
+	if strings.TrimSpace(input.Bundle) == "" {
+		return nil, OutputVerifyGemaraArtifactSignature{}, fmt.Errorf("bundle is required")
+	}
+	mode := signingModeKeyless
+	cosign := serverConfig(ctx).Cosign
+	if cosign.PublicKey != "" {
+		mode = signingModeKey
+	} else if input.CertificateIdentity == "" || input.CertificateOIDCIssuer == "" {
+		return nil, OutputVerifyGemaraArtifactSignature{}, fmt.Errorf("certificate_identity and certificate_oidc_issuer are required to verify keyless signatures")
+	}
+	content, err := artifactSource(ctx, input.ArtifactContent, input.ArtifactURI)
+	if err != nil {
+		return nil, OutputVerifyGemaraArtifactSignature{}, err
+	}
+
+	dir, err := os.MkdirTemp("", "gemara-verify-*")
+	if err != nil {
+		return nil, OutputVerifyGemaraArtifactSignature{}, fmt.Errorf("failed to stage artifact for verification: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	artifact := filepath.Join(dir, "artifact.yaml")
+	bundle := filepath.Join(dir, "artifact.sigstore.json")
+	if err := os.WriteFile(artifact, content, 0o600); err != nil {
+		return nil, OutputVerifyGemaraArtifactSignature{}, fmt.Errorf("failed to stage artifact for verification: %w", err)
+	}
+	if err := os.WriteFile(bundle, []byte(input.Bundle), 0o600); err != nil {
+		return nil, OutputVerifyGemaraArtifactSignature{}, fmt.Errorf("failed to stage bundle for verification: %w", err)
+	}
+
+	args := []string{"verify-blob", "--bundle", bundle}
+	if mode == signingModeKey {
+		args = append(args, "--key", cosign.PublicKey)
+	} else {
+		args = append(args, "--certificate-identity="+input.CertificateIdentity, "--certificate-oidc-issuer="+input.CertificateOIDCIssuer)
+	}
+
+	output := OutputVerifyGemaraArtifactSignature{Digest: contentDigest(content), Mode: mode}
+	_, err = cosign.run(ctx, append(args, "--", artifact)...)
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		output.Verified = true
+		output.Message = fmt.Sprintf("Signature verified for %s", output.Digest)
+	case errors.As(err, &exitErr):
+		// cosign exits non-zero when the signature does not match
+		output.Message = fmt.Sprintf("Signature verification failed for %s: %v", output.Digest, err)
+	default:
+		return nil, OutputVerifyGemaraArtifactSignature{}, fmt.Errorf("failed to run cosign: %w", err)
+	}
+	return nil, output, nil
+}
+
+// artifactSource returns artifact content given inline or by URI, rejecting
+// content over the artifact limits.
+func artifactSource(ctx context.Context, content, uri string) ([]byte, error) {
+	switch {
+	case content == "" && uri == "":
+		return nil, fmt.Errorf("artifact_content or artifact_uri is required")
+	case content != "" && uri != "":
+		return nil, fmt.Errorf("artifact_content and artifact_uri are mutually exclusive")
+	case uri != "":
+		raw, err := readArtifactURI(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		content = string(raw)
+	}
+	if err := checkArtifactLimits(ctx, formatYAML, content); err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
+
+func (c CosignConfig) run(ctx context.Context, args ...string) ([]byte, error) {
+	binary := c.Binary
+	if binary == "" {
+		binary = "cosign"
+	}
+	if len(args) > 0 && strings.HasPrefix(args[0], "sign") {
+		if err := checkWritable(ctx, "Sigstore signature"); err != nil {
+			return nil, err
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, binary, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w", msg, err)
+		}
+		return nil, err
+	}
+	return out, nil
+}
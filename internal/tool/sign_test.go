@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCosign installs a stand-in cosign executable. sign-blob writes a bundle
+// naming the signing arguments, and a signature when asked for one;
+// verify-blob accepts bundles containing "good".
+func fakeCosign(t *testing.T, config CosignConfig) CosignConfig {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake cosign requires a POSIX shell")
+	}
+
+	script := `#!/bin/sh
+cmd=$1; shift
+bundle=""; signature=""; args="$*"
+while [ $# -gt 1 ]; do
+  if [ "$1" = "--bundle" ]; then bundle=$2; fi
+  if [ "$1" = "--output-signature" ]; then signature=$2; fi
+  shift
+done
+case "$cmd" in
+sign-blob)
+  printf '{"good":true,"args":"%s"}' "$args" > "$bundle"
+  if [ -n "$signature" ]; then printf 'c2lnbmF0dXJl\n' > "$signature"; fi ;;
+verify-blob) grep -q good "$bundle" || { echo "invalid signature" >&2; exit 1; } ;;
+esac
+`
+	bin := filepath.Join(t.TempDir(), "cosign")
+	require.NoError(t, os.WriteFile(bin, []byte(script), 0o755), "should write fake cosign")
+
+	config.Binary = bin
+	return config
+}
+
+func TestSignGemaraArtifact(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      CosignConfig
+		input       InputSignGemaraArtifact
+		wantErr     bool
+		errContains string
+		wantMode    string
+		wantArgs    string
+	}{
+		{
+			name:        "missing artifact",
+			wantErr:     true,
+			errContains: "artifact_content or artifact_uri is required",
+		},
+		{
+			name:     "keyless",
+			input:    InputSignGemaraArtifact{ArtifactContent: "title: Signed\n"},
+			wantMode: signingModeKeyless,
+			wantArgs: "--yes",
+		},
+		{
+			name:     "key",
+			config:   CosignConfig{Key: "awskms:///alias/gemara"},
+			input:    InputSignGemaraArtifact{ArtifactContent: "title: Signed\n"},
+			wantMode: signingModeKey,
+			wantArgs: "--key awskms:///alias/gemara",
+		},
+		{
+			name:     "dry run",
+			input:    InputSignGemaraArtifact{ArtifactContent: "title: Signed\n", DryRun: true},
+			wantMode: signingModeKeyless,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := withTestConfig(func(c *Config) { c.Cosign = fakeCosign(t, tt.config) })
+			_, output, err := SignGemaraArtifact(ctx, nil, tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantMode, output.Mode)
+			assert.Equal(t, contentDigest([]byte(tt.input.ArtifactContent)), output.Digest)
+			assert.Equal(t, tt.input.DryRun, output.DryRun)
+			if tt.input.DryRun {
+				assert.Empty(t, output.Bundle, "a dry run should not sign")
+				return
+			}
+			assert.Contains(t, output.Bundle, tt.wantArgs)
+		})
+	}
+}
+
+func TestVerifyGemaraArtifactSignature(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       CosignConfig
+		input        InputVerifyGemaraArtifactSignature
+		wantErr      bool
+		errContains  string
+		wantVerified bool
+	}{
+		{
+			name:        "missing bundle",
+			input:       InputVerifyGemaraArtifactSignature{ArtifactContent: "title: Signed\n"},
+			wantErr:     true,
+			errContains: "bundle is required",
+		},
+		{
+			name:        "keyless without identity",
+			input:       InputVerifyGemaraArtifactSignature{ArtifactContent: "title: Signed\n", Bundle: `{"good":true}`},
+			wantErr:     true,
+			errContains: "certificate_identity and certificate_oidc_issuer are required",
+		},
+		{
+			name: "keyless verified",
+			input: InputVerifyGemaraArtifactSignature{
+				ArtifactContent:       "title: Signed\n",
+				Bundle:                `{"good":true}`,
+				CertificateIdentity:   "release@example.com",
+				CertificateOIDCIssuer: "https://accounts.example.com",
+			},
+			wantVerified: true,
+		},
+		{
+			name:         "key verified",
+			config:       CosignConfig{PublicKey: "cosign.pub"},
+			input:        InputVerifyGemaraArtifactSignature{ArtifactContent: "title: Signed\n", Bundle: `{"good":true}`},
+			wantVerified: true,
+		},
+		{
+			name:         "signature mismatch",
+			config:       CosignConfig{PublicKey: "cosign.pub"},
+			input:        InputVerifyGemaraArtifactSignature{ArtifactContent: "title: Tampered\n", Bundle: `{"bad":true}`},
+			wantVerified: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := withTestConfig(func(c *Config) { c.Cosign = fakeCosign(t, tt.config) })
+			_, output, err := VerifyGemaraArtifactSignature(ctx, nil, tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantVerified, output.Verified, output.Message)
+			if !tt.wantVerified {
+				assert.Contains(t, output.Message, "invalid signature")
+			}
+		})
+	}
+}
+
+func TestVerifyGemaraArtifactSignatureMissingCosign(t *testing.T) {
+	ctx := withTestConfig(func(c *Config) {
+		c.Cosign = CosignConfig{Binary: filepath.Join(t.TempDir(), "missing"), PublicKey: "cosign.pub"}
+	})
+
+	_, _, err := VerifyGemaraArtifactSignature(ctx, nil,
+		InputVerifyGemaraArtifactSignature{ArtifactContent: "title: Signed\n", Bundle: "{}"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to run cosign")
+}
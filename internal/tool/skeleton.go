@@ -0,0 +1,294 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultSkeletonCatalogID = "DRAFT-CATALOG"
+	defaultSkeletonCategory  = "default"
+	// skeletonTitleWords caps the length of titles derived from requirements.
+	skeletonTitleWords  = 8
+	skeletonPlaceholder = "TODO"
+)
+
+// draftAuthor is the metadata author of generated drafts; the placeholders
+// returned with a draft ask the user to replace it.
+var draftAuthor = yaml.MapSlice{
+	{Key: "id", Value: "gemara-mcp"},
+	{Key: "name", Value: "Gemara MCP draft generator"},
+	{Key: "type", Value: "Software"},
+}
+
+// skeletonFamily groups requirements about a topic into a control family.
+type skeletonFamily struct {
+	Title    string
+	Keywords []string
+}
+
+// skeletonFamilies are tried in order; the first family with a keyword in a
+// requirement claims it.
+var skeletonFamilies = []skeletonFamily{
+	{Title: "Identity and Access Management", Keywords: []string{"access", "identity", "authenticat", "authoriz", "mfa", "multi-factor", "password", "credential", "privilege", "role", "permission", "account"}},
+	{Title: "Data Protection", Keywords: []string{"encrypt", "data", "key", "secret", "pii", "privacy", "retention", "classif"}},
+	{Title: "Logging and Monitoring", Keywords: []string{"log", "audit", "monitor", "alert", "detect", "siem", "trace"}},
+	{Title: "Network Security", Keywords: []string{"network", "firewall", "tls", "traffic", "ingress", "egress", "port", "dns", "vpn"}},
+	{Title: "Vulnerability Management", Keywords: []string{"vulnerab", "patch", "scan", "cve", "dependenc", "update"}},
+	{Title: "Configuration Management", Keywords: []string{"config", "baseline", "harden", "image", "infrastructure", "deploy", "change"}},
+	{Title: "Resilience", Keywords: []string{"backup", "recover", "availability", "disaster", "restore", "redundan", "failover"}},
+	{Title: "Incident Response", Keywords: []string{"incident", "respond", "response", "breach", "escalat"}},
+}
+
+// skeletonFamilyPatterns match the keywords of each skeleton family at word starts.
+var skeletonFamilyPatterns = func() []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, len(skeletonFamilies))
+	for i, family := range skeletonFamilies {
+		keywords := make([]string, len(family.Keywords))
+		for j, keyword := range family.Keywords {
+			keywords[j] = regexp.QuoteMeta(keyword)
+		}
+		patterns[i] = regexp.MustCompile(`(?i)\b(?:` + strings.Join(keywords, "|") + `)`)
+	}
+	return patterns
+}()
+
+// skeletonGeneralFamily holds requirements that match no other family.
+const skeletonGeneralFamily = "General"
+
+// requirementPreamble matches openings that add nothing to a control title,
+// such as "Ensure that" or "The system shall".
+var requirementPreamble = regexp.MustCompile(`(?i)^(?:(?:ensure|verify)\s+(?:that\s+)?|the\s+(?:system|service|platform|application|organization)\s+(?:must|shall|should|will)\s+)`)
+
+// MetadataGenerateControlCatalogSkeleton describes the GenerateControlCatalogSkeleton tool.
+var MetadataGenerateControlCatalogSkeleton = &mcp.Tool{
+	Name: "generate_control_catalog_skeleton",
+	Description: "Turn a list of natural-language requirement statements into a draft ControlCatalog with generated IDs, " +
+		"families, and placeholder assessment requirements, validated against the schema before it is returned.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"requirements"},
+		"properties": map[string]interface{}{
+			"requirements": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"minItems":    1,
+				"description": "Requirement statements, one control is drafted per statement",
+			},
+			"catalog_id": map[string]interface{}{
+				"type":        "string",
+				"description": fmt.Sprintf("Metadata id of the catalog (default: %s)", defaultSkeletonCatalogID),
+			},
+			"title": map[string]interface{}{
+				"type":        "string",
+				"description": "Title of the catalog (default: Draft Control Catalog)",
+			},
+			"id_prefix": map[string]interface{}{
+				"type":        "string",
+				"description": "Prefix for family, control, and assessment requirement IDs (default: derived from catalog_id)",
+			},
+			"applicability": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": fmt.Sprintf("Applicability category IDs for the placeholder assessment requirements (default: [%s])", defaultSkeletonCategory),
+			},
+		},
+	},
+}
+
+// InputGenerateControlCatalogSkeleton is the input for the GenerateControlCatalogSkeleton tool.
+type InputGenerateControlCatalogSkeleton struct {
+	Requirements  []string `json:"requirements"`
+	CatalogID     string   `json:"catalog_id,omitempty"`
+	Title         string   `json:"title,omitempty"`
+	IDPrefix      string   `json:"id_prefix,omitempty"`
+	Applicability []string `json:"applicability,omitempty"`
+}
+
+// SkeletonControl summarizes a drafted control.
+type SkeletonControl struct {
+	ID          string `json:"id"`
+	Family      string `json:"family"`
+	Title       string `json:"title"`
+	Requirement string `json:"requirement"`
+}
+
+// OutputGenerateControlCatalogSkeleton is the output for the GenerateControlCatalogSkeleton tool.
+type OutputGenerateControlCatalogSkeleton struct {
+	Content  string            `json:"content"`
+	Controls []SkeletonControl `json:"controls"`
+	Valid    bool              `json:"valid"`
+	Errors   []string          `json:"errors,omitempty"`
+	// Placeholders lists the paths left for the author to complete.
+	Placeholders []string `json:"placeholders"`
+	Message      string   `json:"message"`
+}
+
+// GenerateControlCatalogSkeleton drafts a ControlCatalog from requirement statements.
+func GenerateControlCatalogSkeleton(ctx context.Context, _ *mcp.CallToolRequest, input InputGenerateControlCatalogSkeleton) (*mcp.CallToolResult, OutputGenerateControlCatalogSkeleton, error) {
+	var requirements []string
+	for _, r := range input.Requirements {
+		if r = strings.Join(strings.Fields(r), " "); r != "" {
+			requirements = append(requirements, r)
+		}
+	}
+	if len(requirements) == 0 {
+		return nil, OutputGenerateControlCatalogSkeleton{}, fmt.Errorf("requirements is required")
+	}
+
+	catalogID := input.CatalogID
+	if catalogID == "" {
+		catalogID = defaultSkeletonCatalogID
+	}
+	title := input.Title
+	if title == "" {
+		title = "Draft Control Catalog"
+	}
+	prefix := input.IDPrefix
+	if prefix == "" {
+		prefix = skeletonPrefix(catalogID)
+	}
+	applicability := input.Applicability
+	if len(applicability) == 0 {
+		applicability = []string{defaultSkeletonCategory}
+	}
+
+	output := OutputGenerateControlCatalogSkeleton{Controls: []SkeletonControl{}}
+
+	// Families are numbered in order of first use
+	familyIDs := map[string]string{}
+	var families []interface{}
+	var controls []interface{}
+	for i, requirement := range requirements {
+		familyTitle := skeletonFamilyFor(requirement)
+		familyID, ok := familyIDs[familyTitle]
+		if !ok {
+			familyID = fmt.Sprintf("%s.F%02d", prefix, len(familyIDs)+1)
+			familyIDs[familyTitle] = familyID
+			families = append(families, yaml.MapSlice{
+				{Key: "id", Value: familyID},
+				{Key: "title", Value: familyTitle},
+				{Key: "description", Value: fmt.Sprintf("Controls for %s.", strings.ToLower(familyTitle))},
+			})
+		}
+
+		controlID := fmt.Sprintf("%s.C%02d", prefix, i+1)
+		controlTitle := skeletonTitle(requirement)
+		path := fmt.Sprintf("$.controls[%d]", i)
+		output.Placeholders = append(output.Placeholders,
+			path+`["assessment-requirements"][0].text`,
+			path+`["assessment-requirements"][0].recommendation`)
+
+		controls = append(controls, yaml.MapSlice{
+			{Key: "id", Value: controlID},
+			{Key: "family", Value: familyID},
+			{Key: "title", Value: controlTitle},
+			{Key: "objective", Value: requirement},
+			{Key: "assessment-requirements", Value: []interface{}{
+				yaml.MapSlice{
+					{Key: "id", Value: controlID + ".TR01"},
+					{Key: "text", Value: fmt.Sprintf("%s: describe how to verify that %s", skeletonPlaceholder, lowerFirst(strings.TrimSuffix(requirement, ".")))},
+					{Key: "applicability", Value: applicability},
+					{Key: "recommendation", Value: fmt.Sprintf("%s: describe how to implement this control.", skeletonPlaceholder)},
+				},
+			}},
+		})
+		output.Controls = append(output.Controls, SkeletonControl{ID: controlID, Family: familyID, Title: controlTitle, Requirement: requirement})
+	}
+
+	var categories []interface{}
+	for _, id := range applicability {
+		categories = append(categories, yaml.MapSlice{
+			{Key: "id", Value: id},
+			{Key: "title", Value: id},
+			{Key: "description", Value: fmt.Sprintf("%s: describe when the %s category applies.", skeletonPlaceholder, id)},
+		})
+	}
+	output.Placeholders = append([]string{"$.metadata.description", "$.metadata.author"}, output.Placeholders...)
+
+	catalog := yaml.MapSlice{
+		{Key: "metadata", Value: yaml.MapSlice{
+			{Key: "id", Value: catalogID},
+			{Key: "description", Value: fmt.Sprintf("%s: describe the scope of this catalog. Drafted from %d requirement(s).", skeletonPlaceholder, len(requirements))},
+			{Key: "version", Value: "0.1.0"},
+			{Key: "author", Value: draftAuthor},
+			{Key: "applicability-categories", Value: categories},
+		}},
+		{Key: "title", Value: title},
+		{Key: "families", Value: families},
+		{Key: "controls", Value: controls},
+	}
+
+	out, err := yaml.MarshalWithOptions(catalog, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return nil, OutputGenerateControlCatalogSkeleton{}, fmt.Errorf("failed to encode catalog: %w", err)
+	}
+	output.Content = string(out)
+
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputGenerateControlCatalogSkeleton{}, err
+	}
+	validation, err := validateAgainstSchema(schema, "#ControlCatalog", output.Content)
+	if err != nil {
+		return nil, OutputGenerateControlCatalogSkeleton{}, err
+	}
+	output.Valid = validation.Valid
+	output.Errors = validation.Errors
+	output.Message = fmt.Sprintf("Drafted %d control(s) in %d family(ies); %s; complete the %d %s placeholder(s) before publishing",
+		len(output.Controls), len(families), strings.ToLower(validation.Message), len(output.Placeholders), skeletonPlaceholder)
+	return nil, output, nil
+}
+
+// skeletonFamilyFor returns the family a requirement belongs to.
+func skeletonFamilyFor(requirement string) string {
+	for i, pattern := range skeletonFamilyPatterns {
+		if pattern.MatchString(requirement) {
+			return skeletonFamilies[i].Title
+		}
+	}
+	return skeletonGeneralFamily
+}
+
+// skeletonPrefix derives an ID prefix from a catalog id, e.g. "acme-cloud" -> "ACME".
+func skeletonPrefix(catalogID string) string {
+	first := strings.FieldsFunc(catalogID, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	if len(first) == 0 {
+		return "DRAFT"
+	}
+	return strings.ToUpper(first[0])
+}
+
+// skeletonTitle shortens a requirement statement into a control title.
+func skeletonTitle(requirement string) string {
+	statement := strings.TrimSuffix(requirement, ".")
+	if trimmed := requirementPreamble.ReplaceAllString(statement, ""); trimmed != "" {
+		statement = trimmed
+	}
+	words := strings.Fields(statement)
+	if len(words) > skeletonTitleWords {
+		words = words[:skeletonTitleWords]
+	}
+	title := strings.TrimRight(strings.Join(words, " "), ",;:")
+	if title == "" {
+		return requirement
+	}
+	return strings.ToUpper(title[:1]) + title[1:]
+}
+
+// lowerFirst lower-cases the first letter of s unless it starts an acronym.
+func lowerFirst(s string) string {
+	if s == "" || len(s) > 1 && strings.ToUpper(s[:2]) == s[:2] {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
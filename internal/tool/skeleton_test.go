@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateControlCatalogSkeleton(t *testing.T) {
+	useTestSchema(t)
+
+	tests := []struct {
+		name         string
+		input        InputGenerateControlCatalogSkeleton
+		wantErr      bool
+		errContains  string
+		wantControls []SkeletonControl
+		wantContains []string
+	}{
+		{
+			name:        "no requirements",
+			input:       InputGenerateControlCatalogSkeleton{Requirements: []string{"  ", ""}},
+			wantErr:     true,
+			errContains: "requirements is required",
+		},
+		{
+			name: "families by keyword",
+			input: InputGenerateControlCatalogSkeleton{
+				CatalogID: "acme-cloud",
+				Requirements: []string{
+					"All storage buckets must be encrypted at rest.",
+					"Administrative access must require multi-factor authentication.",
+					"Encryption keys shall be rotated every 90 days.",
+				},
+			},
+			wantControls: []SkeletonControl{
+				{ID: "ACME.C01", Family: "ACME.F01", Title: "All storage buckets must be encrypted at rest", Requirement: "All storage buckets must be encrypted at rest."},
+				{ID: "ACME.C02", Family: "ACME.F02", Title: "Administrative access must require multi-factor authentication", Requirement: "Administrative access must require multi-factor authentication."},
+				{ID: "ACME.C03", Family: "ACME.F01", Title: "Encryption keys shall be rotated every 90 days", Requirement: "Encryption keys shall be rotated every 90 days."},
+			},
+			wantContains: []string{
+				"id: acme-cloud",
+				"title: Data Protection",
+				"title: Identity and Access Management",
+				"id: ACME.C01.TR01",
+			},
+		},
+		{
+			name: "explicit prefix and applicability",
+			input: InputGenerateControlCatalogSkeleton{
+				IDPrefix:      "OPS",
+				Title:         "Operations Baseline",
+				Applicability: []string{"production", "staging"},
+				Requirements:  []string{"Ensure that the on-call rotation is documented"},
+			},
+			wantControls: []SkeletonControl{
+				{ID: "OPS.C01", Family: "OPS.F01", Title: "The on-call rotation is documented", Requirement: "Ensure that the on-call rotation is documented"},
+			},
+			wantContains: []string{
+				"id: DRAFT-CATALOG",
+				"title: Operations Baseline",
+				"title: General",
+				"- production",
+				"- staging",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := GenerateControlCatalogSkeleton(context.Background(), nil, tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, output.Valid, "skeleton should validate: %v", output.Errors)
+			assert.Equal(t, tt.wantControls, output.Controls)
+			assert.Len(t, output.Placeholders, 2+2*len(tt.wantControls))
+			for _, want := range tt.wantContains {
+				assert.Contains(t, output.Content, want)
+			}
+		})
+	}
+}
+
+func TestSkeletonTitle(t *testing.T) {
+	tests := []struct {
+		requirement string
+		want        string
+	}{
+		{"The system shall log all administrative actions.", "Log all administrative actions"},
+		{"Every production workload must be scanned for vulnerabilities weekly and before each deployment.", "Every production workload must be scanned for vulnerabilities"},
+		{"Verify backups are restorable", "Backups are restorable"},
+		{"rotate credentials quarterly", "Rotate credentials quarterly"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.requirement, func(t *testing.T) {
+			assert.Equal(t, tt.want, skeletonTitle(tt.requirement))
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// SOPSConfig configures decryption and encryption of SOPS-encrypted artifact files.
+type SOPSConfig struct {
+	// Binary is the sops executable to run.
+	Binary string
+	// AgeKeyFile is the age identity file used for decryption (SOPS_AGE_KEY_FILE).
+	AgeKeyFile string
+	// AgeRecipients are the age public keys new files are encrypted to.
+	AgeRecipients []string
+	// KMSKeys are the AWS KMS key ARNs new files are encrypted to.
+	KMSKeys []string
+}
+
+// sopsMetadata is the top-level key sops adds to encrypted YAML documents.
+type sopsMetadata struct {
+	SOPS *struct {
+		MAC          string `yaml:"mac"`
+		LastModified string `yaml:"lastmodified"`
+	} `yaml:"sops"`
+}
+
+// isSOPSEncrypted reports whether content is a SOPS-encrypted YAML document.
+func isSOPSEncrypted(content []byte) bool {
+	var meta sopsMetadata
+	if err := yaml.Unmarshal(content, &meta); err != nil {
+		return false
+	}
+	return meta.SOPS != nil && meta.SOPS.MAC != ""
+}
+
+// readArtifactFile reads a workspace artifact, decrypting it when it is SOPS-encrypted.
+func readArtifactFile(ctx context.Context, path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if !isSOPSEncrypted(content) {
+		return content, nil
+	}
+	plain, err := serverConfig(ctx).SOPS.run(ctx, "--decrypt", "--input-type", "yaml", "--output-type", "yaml", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", path, err)
+	}
+	return plain, nil
+}
+
+// writeArtifactFile writes a workspace artifact, re-encrypting it with SOPS when the
+// file it replaces was encrypted. Advisory tools return the artifacts they
+// produce instead of writing them; a mode that writes the workspace must write
+// through here so that encrypted files stay encrypted.
+func writeArtifactFile(ctx context.Context, path string, content []byte) error {
+	if err := checkWritable(ctx, path); err != nil {
+		return err
+	}
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err == nil && isSOPSEncrypted(existing) {
+		content, err = serverConfig(ctx).SOPS.encrypt(ctx, path, content)
+		if err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// encrypt encrypts plaintext YAML destined for path. The plaintext is staged in a
+// private temporary file next to path so sops can apply any .sops.yaml creation rules.
+func (c SOPSConfig) encrypt(ctx context.Context, path string, plain []byte) ([]byte, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".gemara-sops-*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to stage %s for encryption: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(plain)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stage %s for encryption: %w", path, err)
+	}
+
+	args := []string{"--encrypt", "--input-type", "yaml", "--output-type", "yaml"}
+	if len(c.AgeRecipients) > 0 {
+		args = append(args, "--age", strings.Join(c.AgeRecipients, ","))
+	}
+	if len(c.KMSKeys) > 0 {
+		args = append(args, "--kms", strings.Join(c.KMSKeys, ","))
+	}
+	args = append(args, tmp.Name())
+
+	out, err := c.run(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt %s: %w", path, err)
+	}
+	return out, nil
+}
+
+func (c SOPSConfig) run(ctx context.Context, args ...string) ([]byte, error) {
+	binary := c.Binary
+	if binary == "" {
+		binary = "sops"
+	}
+
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Env = os.Environ()
+	if c.AgeKeyFile != "" {
+		cmd.Env = append(cmd.Env, "SOPS_AGE_KEY_FILE="+c.AgeKeyFile)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %w", msg, err)
+		}
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const encryptedArtifact = `title: ENC[AES256_GCM,data:abc,type:str]
+sops:
+  age:
+    - recipient: age1test
+  lastmodified: "2024-01-01T00:00:00Z"
+  mac: ENC[AES256_GCM,data:def,type:str]
+  version: 3.9.0
+`
+
+// fakeSOPS installs a stand-in sops executable that "decrypts" to a fixed document
+// and "encrypts" by prefixing the plaintext with SOPS metadata, and returns a
+// context that uses it.
+func fakeSOPS(t *testing.T) context.Context {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake sops requires a POSIX shell")
+	}
+
+	script := `#!/bin/sh
+case "$1" in
+--decrypt) printf 'title: Decrypted\n' ;;
+--encrypt) for last; do :; done; printf 'sops:\n  mac: fake\n'; cat "$last" ;;
+esac
+`
+	bin := filepath.Join(t.TempDir(), "sops")
+	require.NoError(t, os.WriteFile(bin, []byte(script), 0o755), "should write fake sops")
+
+	return withTestConfig(func(c *Config) { c.SOPS = SOPSConfig{Binary: bin, AgeRecipients: []string{"age1test"}} })
+}
+
+func TestIsSOPSEncrypted(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{name: "encrypted", content: encryptedArtifact, want: true},
+		{name: "plain", content: "title: Plain\n", want: false},
+		{name: "sops key without mac", content: "sops:\n  version: 3.9.0\n", want: false},
+		{name: "invalid YAML", content: "invalid: yaml: [unclosed", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isSOPSEncrypted([]byte(tt.content)))
+		})
+	}
+}
+
+func TestReadArtifactFile(t *testing.T) {
+	ctx := fakeSOPS(t)
+	dir := t.TempDir()
+
+	writeTestFile(t, dir, "plain.yaml", "title: Plain\n")
+	plain := filepath.Join(dir, "plain.yaml")
+	content, err := readArtifactFile(ctx, plain)
+	require.NoError(t, err, "should read plain file")
+	assert.Equal(t, "title: Plain\n", string(content), "plain files are returned unchanged")
+
+	writeTestFile(t, dir, "secret.yaml", encryptedArtifact)
+	encrypted := filepath.Join(dir, "secret.yaml")
+	content, err = readArtifactFile(ctx, encrypted)
+	require.NoError(t, err, "should decrypt file")
+	assert.Equal(t, "title: Decrypted\n", string(content), "encrypted files are decrypted")
+
+	ctx = withTestConfig(func(c *Config) { c.SOPS.Binary = filepath.Join(dir, "missing-sops") })
+	_, err = readArtifactFile(ctx, encrypted)
+	assert.ErrorContains(t, err, "failed to decrypt", "missing sops should fail decryption")
+}
+
+func TestWriteArtifactFile(t *testing.T) {
+	ctx := fakeSOPS(t)
+	dir := t.TempDir()
+
+	writeTestFile(t, dir, "plain.yaml", "title: Plain\n")
+	plain := filepath.Join(dir, "plain.yaml")
+	require.NoError(t, writeArtifactFile(ctx, plain, []byte("title: Updated\n")))
+	content, err := os.ReadFile(plain)
+	require.NoError(t, err)
+	assert.Equal(t, "title: Updated\n", string(content), "plain files stay plain")
+
+	writeTestFile(t, dir, "secret.yaml", encryptedArtifact)
+	encrypted := filepath.Join(dir, "secret.yaml")
+	require.NoError(t, writeArtifactFile(ctx, encrypted, []byte("title: Updated\n")))
+	content, err = os.ReadFile(encrypted)
+	require.NoError(t, err)
+	assert.True(t, isSOPSEncrypted(content), "encrypted files are re-encrypted")
+	assert.Contains(t, string(content), "title: Updated", "new content should be encrypted")
+
+	staged, err := filepath.Glob(filepath.Join(dir, ".gemara-sops-*"))
+	require.NoError(t, err)
+	assert.Empty(t, staged, "staged plaintext should be removed")
+}
@@ -0,0 +1,547 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// stagedResourcePrefix starts the URI by which tools read a staged artifact.
+const stagedResourcePrefix = "gemara://staged/"
+
+// MaxStagedArtifacts is the number of drafts a session may stage at once.
+var MaxStagedArtifacts = 50
+
+// MaxStagingSessions is the number of sessions whose drafts the server holds
+// at once; staging in one more drops the drafts of the session idle longest.
+var MaxStagingSessions = 1000
+
+// StagedArtifactTTL is how long the drafts of an idle session are kept.
+var StagedArtifactTTL = time.Hour
+
+// stagedName matches the names drafts can be staged under.
+var stagedName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,127}$`)
+
+// stagedArtifact is a draft held in server memory for one session.
+type stagedArtifact struct {
+	content  string
+	kind     string
+	revision int
+	updated  time.Time
+}
+
+// stagingStore holds the drafts of every session. Drafts are dropped when
+// their session closes, after StagedArtifactTTL without use, or when more
+// than MaxStagingSessions sessions have drafts.
+type stagingStore struct {
+	mu       sync.Mutex
+	sessions map[*mcp.ServerSession]map[string]*stagedArtifact
+	// used is when each session last staged or read a draft.
+	used map[*mcp.ServerSession]time.Time
+	// watched holds the sessions whose close is awaited to drop their drafts.
+	watched map[*mcp.ServerSession]bool
+}
+
+func newStagingStore() *stagingStore {
+	return &stagingStore{
+		sessions: map[*mcp.ServerSession]map[string]*stagedArtifact{},
+		used:     map[*mcp.ServerSession]time.Time{},
+		watched:  map[*mcp.ServerSession]bool{},
+	}
+}
+
+var staging = newStagingStore()
+
+type sessionKey struct{}
+
+// sessionHandler puts the calling session on the context of a tool handler,
+// so that reading a gemara://staged/ URI finds the drafts of that session.
+func sessionHandler[In, Out any](h mcp.ToolHandlerFor[In, Out]) mcp.ToolHandlerFor[In, Out] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		if req != nil && req.Session != nil {
+			staging.watch(req.Session)
+			ctx = context.WithValue(ctx, sessionKey{}, req.Session)
+		}
+		return h(ctx, req, input)
+	}
+}
+
+// callingSession returns the session of the tool call running under ctx, or
+// nil outside of a session.
+func callingSession(ctx context.Context) *mcp.ServerSession {
+	session, _ := ctx.Value(sessionKey{}).(*mcp.ServerSession)
+	return session
+}
+
+// watch drops the drafts of a session once it closes. Every server, including
+// the server of each tenant, reaches its sessions through here.
+func (s *stagingStore) watch(session *mcp.ServerSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.watched[session] {
+		return
+	}
+	s.watched[session] = true
+	go func() {
+		_ = session.Wait()
+		s.drop(session)
+	}()
+}
+
+// drop forgets a closed session and its drafts.
+func (s *stagingStore) drop(session *mcp.ServerSession) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, session)
+	delete(s.used, session)
+	delete(s.watched, session)
+}
+
+// drafts returns the drafts of a session, creating the map if asked to.
+func (s *stagingStore) drafts(session *mcp.ServerSession, create bool) map[string]*stagedArtifact {
+	now := time.Now()
+	s.prune(now)
+	drafts, ok := s.sessions[session]
+	if !ok && create {
+		if len(s.sessions) >= MaxStagingSessions {
+			s.evict()
+		}
+		drafts = map[string]*stagedArtifact{}
+		s.sessions[session] = drafts
+	}
+	if drafts != nil {
+		s.used[session] = now
+	}
+	return drafts
+}
+
+// prune drops the drafts of sessions idle for longer than StagedArtifactTTL.
+func (s *stagingStore) prune(now time.Time) {
+	for session, used := range s.used {
+		if now.Sub(used) > StagedArtifactTTL {
+			delete(s.sessions, session)
+			delete(s.used, session)
+		}
+	}
+}
+
+// evict drops the drafts of the session idle longest.
+func (s *stagingStore) evict() {
+	var oldest *mcp.ServerSession
+	var oldestUsed time.Time
+	found := false
+	for session, used := range s.used {
+		if !found || used.Before(oldestUsed) {
+			oldest, oldestUsed, found = session, used, true
+		}
+	}
+	if found {
+		delete(s.sessions, oldest)
+		delete(s.used, oldest)
+	}
+}
+
+// readStagedArtifact reads a draft of the calling session by its gemara://staged/ URI.
+func readStagedArtifact(ctx context.Context, uri string) ([]byte, error) {
+	name := strings.TrimPrefix(uri, stagedResourcePrefix)
+	staging.mu.Lock()
+	defer staging.mu.Unlock()
+	draft, ok := staging.drafts(callingSession(ctx), false)[name]
+	if !ok {
+		return nil, fmt.Errorf("no artifact is staged as %q in this session", name)
+	}
+	return []byte(draft.content), nil
+}
+
+// MetadataStageArtifact describes the StageArtifact tool.
+var MetadataStageArtifact = &mcp.Tool{
+	Name: "stage_artifact",
+	Description: "Stage a draft artifact in server memory for the current session, or edit a staged draft in place by " +
+		"setting the value at a YAML path, so multi-step edits do not send the full artifact on every call. " +
+		"Other tools read a draft with artifact_uri gemara://staged/{name}.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{
+				"type":        "string",
+				"description": "Name of the draft: letters, digits, '.', '_', and '-'",
+			},
+			"artifact_content": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML content to stage, replacing any draft of the same name",
+			},
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML path within the staged draft to set, such as $.controls[0].title; an index one past the end of a list appends",
+			},
+			"value": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML value to set at path",
+			},
+			"delete": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Remove the node at path instead of setting it, or the whole draft when no path is given",
+			},
+		},
+		"required": []string{"name"},
+	},
+}
+
+// InputStageArtifact is the input for the StageArtifact tool.
+type InputStageArtifact struct {
+	Name            string `json:"name"`
+	ArtifactContent string `json:"artifact_content,omitempty"`
+	Path            string `json:"path,omitempty"`
+	Value           string `json:"value,omitempty"`
+	Delete          bool   `json:"delete,omitempty"`
+}
+
+// StagedArtifactInfo describes a staged draft.
+type StagedArtifactInfo struct {
+	Name     string `json:"name"`
+	URI      string `json:"uri"`
+	Kind     string `json:"kind,omitempty"`
+	Size     int    `json:"size"`
+	Revision int    `json:"revision"`
+	Updated  string `json:"updated"`
+}
+
+// OutputStageArtifact is the output for the StageArtifact tool.
+type OutputStageArtifact struct {
+	StagedArtifactInfo
+	Message string `json:"message"`
+}
+
+// StageArtifact stages, edits, or removes a draft of the calling session.
+func StageArtifact(ctx context.Context, _ *mcp.CallToolRequest, input InputStageArtifact) (*mcp.CallToolResult, OutputStageArtifact, error) {
+	if !stagedName.MatchString(input.Name) {
+		return nil, OutputStageArtifact{}, fmt.Errorf("invalid name %q: use up to 128 letters, digits, '.', '_', and '-'", input.Name)
+	}
+	if input.ArtifactContent != "" && (input.Path != "" || input.Delete) {
+		return nil, OutputStageArtifact{}, fmt.Errorf("artifact_content replaces the whole draft and cannot be combined with path or delete")
+	}
+
+	staging.mu.Lock()
+	defer staging.mu.Unlock()
+	drafts := staging.drafts(callingSession(ctx), true)
+	draft := drafts[input.Name]
+
+	if input.Delete && input.Path == "" {
+		if draft == nil {
+			return nil, OutputStageArtifact{}, fmt.Errorf("no artifact is staged as %q in this session", input.Name)
+		}
+		delete(drafts, input.Name)
+		output := OutputStageArtifact{StagedArtifactInfo: draft.info(input.Name)}
+		output.Message = fmt.Sprintf("Removed staged artifact %s", input.Name)
+		return nil, output, nil
+	}
+
+	var content, action string
+	switch {
+	case input.ArtifactContent != "":
+		content, action = input.ArtifactContent, "Staged"
+		if draft != nil {
+			action = "Replaced"
+		}
+	case input.Path != "":
+		if draft == nil {
+			return nil, OutputStageArtifact{}, fmt.Errorf("no artifact is staged as %q in this session; stage it with artifact_content first", input.Name)
+		}
+		edited, err := editStagedContent(draft.content, input.Path, input.Value, input.Delete)
+		if err != nil {
+			return nil, OutputStageArtifact{}, err
+		}
+		content, action = edited, "Edited"
+	default:
+		return nil, OutputStageArtifact{}, fmt.Errorf("artifact_content, or path with value or delete, is required")
+	}
+	doc, err := parseArtifact(ctx, content)
+	if err != nil {
+		return nil, OutputStageArtifact{}, err
+	}
+	if draft == nil {
+		if len(drafts) >= MaxStagedArtifacts {
+			return nil, OutputStageArtifact{}, fmt.Errorf("this session already has %d staged artifacts; delete one first", MaxStagedArtifacts)
+		}
+		draft = &stagedArtifact{}
+		drafts[input.Name] = draft
+	}
+	draft.content = content
+	draft.kind = artifactKind(doc)
+	draft.revision++
+	draft.updated = time.Now().UTC()
+
+	output := OutputStageArtifact{StagedArtifactInfo: draft.info(input.Name)}
+	output.Message = fmt.Sprintf("%s %s (revision %d); read it with artifact_uri %s", action, input.Name, draft.revision, output.URI)
+	if input.Path != "" {
+		output.Message = fmt.Sprintf("%s %s at %s (revision %d)", action, input.Name, input.Path, draft.revision)
+	}
+	return nil, output, nil
+}
+
+func (d *stagedArtifact) info(name string) StagedArtifactInfo {
+	return StagedArtifactInfo{
+		Name:     name,
+		URI:      stagedResourcePrefix + name,
+		Kind:     d.kind,
+		Size:     len(d.content),
+		Revision: d.revision,
+		Updated:  d.updated.Format(time.RFC3339),
+	}
+}
+
+// editStagedContent sets, or deletes, the node at a YAML path, keeping the
+// order of mapping keys.
+func editStagedContent(content, path, value string, remove bool) (string, error) {
+	segments, err := parseArtifactPath(path)
+	if err != nil {
+		return "", err
+	}
+	if len(segments) == 0 {
+		return "", fmt.Errorf("path must name a node within the artifact; use artifact_content to replace it")
+	}
+	var tree interface{}
+	if err := yaml.UnmarshalWithOptions([]byte(content), &tree, yaml.UseOrderedMap()); err != nil {
+		return "", fmt.Errorf("failed to parse staged artifact: %w", err)
+	}
+	var node interface{}
+	if !remove {
+		if err := yaml.UnmarshalWithOptions([]byte(value), &node, yaml.UseOrderedMap()); err != nil {
+			return "", fmt.Errorf("failed to parse value: %w", err)
+		}
+	}
+	tree, err = setStagedNode(tree, segments, node, remove)
+	if err != nil {
+		return "", fmt.Errorf("cannot edit %s: %w", path, err)
+	}
+	out, err := yaml.MarshalWithOptions(tree, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return "", fmt.Errorf("failed to encode staged artifact: %w", err)
+	}
+	return string(out), nil
+}
+
+// setStagedNode returns tree with the node at segments replaced by value, or
+// removed. Missing mapping keys are added; an index one past the end of a
+// list appends to it.
+func setStagedNode(tree interface{}, segments []pathSegment, value interface{}, remove bool) (interface{}, error) {
+	seg, rest := segments[0], segments[1:]
+	if seg.IsIndex {
+		list, ok := tree.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("no list at [%d]", seg.Index)
+		}
+		switch {
+		case seg.Index < len(list) && len(rest) == 0 && remove:
+			return append(list[:seg.Index:seg.Index], list[seg.Index+1:]...), nil
+		case seg.Index < len(list) && len(rest) == 0:
+			list[seg.Index] = value
+		case seg.Index < len(list):
+			child, err := setStagedNode(list[seg.Index], rest, value, remove)
+			if err != nil {
+				return nil, err
+			}
+			list[seg.Index] = child
+		case seg.Index == len(list) && len(rest) == 0 && !remove:
+			list = append(list, value)
+		default:
+			return nil, fmt.Errorf("no list element at [%d]", seg.Index)
+		}
+		return list, nil
+	}
+
+	m, ok := tree.(yaml.MapSlice)
+	if !ok && tree != nil {
+		return nil, fmt.Errorf("no mapping at %q", seg.Key)
+	}
+	for i, item := range m {
+		if fmt.Sprint(item.Key) != seg.Key {
+			continue
+		}
+		switch {
+		case len(rest) == 0 && remove:
+			return append(m[:i:i], m[i+1:]...), nil
+		case len(rest) == 0:
+			m[i].Value = value
+		default:
+			child, err := setStagedNode(item.Value, rest, value, remove)
+			if err != nil {
+				return nil, err
+			}
+			m[i].Value = child
+		}
+		return m, nil
+	}
+	if remove {
+		return nil, fmt.Errorf("no field %q", seg.Key)
+	}
+	if len(rest) == 0 {
+		return append(m, yaml.MapItem{Key: seg.Key, Value: value}), nil
+	}
+	// Intermediate mappings are created; lists must already exist
+	if rest[0].IsIndex {
+		return nil, fmt.Errorf("no list %q", seg.Key)
+	}
+	child, err := setStagedNode(nil, rest, value, remove)
+	if err != nil {
+		return nil, err
+	}
+	return append(m, yaml.MapItem{Key: seg.Key, Value: child}), nil
+}
+
+// MetadataGetStagedArtifact describes the GetStagedArtifact tool.
+var MetadataGetStagedArtifact = &mcp.Tool{
+	Name:        "get_staged_artifact",
+	Description: "Get the content of a draft staged with stage_artifact in the current session.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{
+				"type":        "string",
+				"description": "Name of the staged draft",
+			},
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML path of the part of the draft to return, such as $.controls[0] (default: the whole draft)",
+			},
+			"fields": fieldsProperty,
+		},
+		"required": []string{"name"},
+	},
+}
+
+// InputGetStagedArtifact is the input for the GetStagedArtifact tool.
+type InputGetStagedArtifact struct {
+	Name   string   `json:"name"`
+	Path   string   `json:"path,omitempty"`
+	Fields []string `json:"fields,omitempty"`
+}
+
+// OutputGetStagedArtifact is the output for the GetStagedArtifact tool.
+type OutputGetStagedArtifact struct {
+	StagedArtifactInfo
+	Path    string `json:"path,omitempty"`
+	Content string `json:"content"`
+}
+
+// GetStagedArtifact returns a draft of the calling session, or part of it.
+func GetStagedArtifact(ctx context.Context, _ *mcp.CallToolRequest, input InputGetStagedArtifact) (*mcp.CallToolResult, OutputGetStagedArtifact, error) {
+	staging.mu.Lock()
+	draft, ok := staging.drafts(callingSession(ctx), false)[input.Name]
+	var copied stagedArtifact
+	if ok {
+		copied = *draft
+	}
+	staging.mu.Unlock()
+	if !ok {
+		return nil, OutputGetStagedArtifact{}, fmt.Errorf("no artifact is staged as %q in this session", input.Name)
+	}
+
+	output := OutputGetStagedArtifact{StagedArtifactInfo: copied.info(input.Name), Path: input.Path, Content: copied.content}
+	if input.Path != "" {
+		segments, err := parseArtifactPath(input.Path)
+		if err != nil {
+			return nil, OutputGetStagedArtifact{}, err
+		}
+		var tree interface{}
+		if err := yaml.UnmarshalWithOptions([]byte(copied.content), &tree, yaml.UseOrderedMap()); err != nil {
+			return nil, OutputGetStagedArtifact{}, fmt.Errorf("failed to parse staged artifact: %w", err)
+		}
+		node, err := stagedNodeAt(tree, segments)
+		if err != nil {
+			return nil, OutputGetStagedArtifact{}, fmt.Errorf("cannot read %s: %w", input.Path, err)
+		}
+		out, err := yaml.MarshalWithOptions(node, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+		if err != nil {
+			return nil, OutputGetStagedArtifact{}, err
+		}
+		output.Content = string(out)
+	}
+	if len(input.Fields) > 0 {
+		projected, err := projectArtifact(output.Content, input.Fields)
+		if err != nil {
+			return nil, OutputGetStagedArtifact{}, err
+		}
+		output.Content = projected
+	}
+	return nil, output, nil
+}
+
+// stagedNodeAt returns the node at a YAML path of an ordered tree.
+func stagedNodeAt(tree interface{}, segments []pathSegment) (interface{}, error) {
+	node := tree
+	for _, seg := range segments {
+		if seg.IsIndex {
+			list, ok := node.([]interface{})
+			if !ok || seg.Index >= len(list) {
+				return nil, fmt.Errorf("no list element at [%d]", seg.Index)
+			}
+			node = list[seg.Index]
+			continue
+		}
+		m, _ := node.(yaml.MapSlice)
+		found := false
+		for _, item := range m {
+			if fmt.Sprint(item.Key) == seg.Key {
+				node, found = item.Value, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no field %q", seg.Key)
+		}
+	}
+	return node, nil
+}
+
+// MetadataListStagedArtifacts describes the ListStagedArtifacts tool.
+var MetadataListStagedArtifacts = &mcp.Tool{
+	Name:        "list_staged_artifacts",
+	Description: "List the drafts staged with stage_artifact in the current session.",
+	InputSchema: map[string]interface{}{
+		"type":       "object",
+		"properties": paginationProperties("drafts"),
+	},
+}
+
+// InputListStagedArtifacts is the input for the ListStagedArtifacts tool.
+type InputListStagedArtifacts struct {
+	PageInput
+}
+
+// OutputListStagedArtifacts is the output for the ListStagedArtifacts tool.
+type OutputListStagedArtifacts struct {
+	Artifacts []StagedArtifactInfo `json:"artifacts"`
+	// Page describes which drafts were returned.
+	Page    *PageInfo `json:"page,omitempty"`
+	Message string    `json:"message"`
+}
+
+// ListStagedArtifacts lists the drafts of the calling session.
+func ListStagedArtifacts(ctx context.Context, _ *mcp.CallToolRequest, input InputListStagedArtifacts) (*mcp.CallToolResult, OutputListStagedArtifacts, error) {
+	staging.mu.Lock()
+	output := OutputListStagedArtifacts{Artifacts: []StagedArtifactInfo{}}
+	for name, draft := range staging.drafts(callingSession(ctx), false) {
+		output.Artifacts = append(output.Artifacts, draft.info(name))
+	}
+	staging.mu.Unlock()
+
+	sort.Slice(output.Artifacts, func(i, j int) bool { return output.Artifacts[i].Name < output.Artifacts[j].Name })
+	output.Message = fmt.Sprintf("%d artifact(s) staged in this session", len(output.Artifacts))
+
+	page, info, err := paginate(output.Artifacts, input.PageInput)
+	if err != nil {
+		return nil, OutputListStagedArtifacts{}, err
+	}
+	output.Artifacts, output.Page = page, &info
+	return nil, output, nil
+}
@@ -0,0 +1,239 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const stagedCatalog = `metadata:
+  id: DRAFT
+title: Draft Catalog
+controls:
+  - id: DRAFT.C01
+    title: First
+`
+
+// useStaging gives the test an empty staging store.
+func useStaging(t *testing.T) {
+	t.Helper()
+	original := staging
+	t.Cleanup(func() { staging = original })
+	staging = newStagingStore()
+}
+
+func TestStageArtifact(t *testing.T) {
+	useStaging(t)
+	ctx := context.Background()
+
+	_, output, err := StageArtifact(ctx, nil, InputStageArtifact{Name: "catalog", ArtifactContent: stagedCatalog})
+	require.NoError(t, err)
+	assert.Equal(t, 1, output.Revision)
+	assert.Equal(t, "gemara://staged/catalog", output.URI)
+
+	edits := []struct {
+		name        string
+		input       InputStageArtifact
+		wantContent []string
+		errContains string
+	}{
+		{
+			name:        "set scalar",
+			input:       InputStageArtifact{Path: "$.title", Value: "Edited Catalog"},
+			wantContent: []string{"title: Edited Catalog"},
+		},
+		{
+			name:        "append to list",
+			input:       InputStageArtifact{Path: "$.controls[1]", Value: "{id: DRAFT.C02, title: Second}"},
+			wantContent: []string{"id: DRAFT.C02", "title: Second"},
+		},
+		{
+			name:        "add nested field",
+			input:       InputStageArtifact{Path: "$.metadata.author.name", Value: "Security Team"},
+			wantContent: []string{"author:\n    name: Security Team"},
+		},
+		{
+			name:  "delete field",
+			input: InputStageArtifact{Path: "$.controls[0].title", Delete: true},
+		},
+		{
+			name:        "index past the end",
+			input:       InputStageArtifact{Path: "$.controls[5]", Value: "{}"},
+			errContains: "no list element at [5]",
+		},
+		{
+			name:        "missing list",
+			input:       InputStageArtifact{Path: "$.families[0]", Value: "{}"},
+			errContains: "no list",
+		},
+	}
+	for _, tt := range edits {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input.Name = "catalog"
+			_, _, err := StageArtifact(ctx, nil, tt.input)
+			if tt.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			content, err := readArtifactURI(ctx, "gemara://staged/catalog")
+			require.NoError(t, err, "staged artifact should be readable by URI")
+			for _, want := range tt.wantContent {
+				assert.Contains(t, string(content), want)
+			}
+		})
+	}
+
+	_, got, err := GetStagedArtifact(ctx, nil, InputGetStagedArtifact{Name: "catalog"})
+	require.NoError(t, err)
+	assert.Equal(t, 5, got.Revision, "every successful edit should bump the revision")
+	assert.NotContains(t, got.Content, "title: First")
+	assert.Less(t, strings.Index(got.Content, "metadata:"), strings.Index(got.Content, "title: Edited Catalog"), "key order should be kept")
+
+	_, part, err := GetStagedArtifact(ctx, nil, InputGetStagedArtifact{Name: "catalog", Path: "$.controls[1]"})
+	require.NoError(t, err)
+	assert.Equal(t, "id: DRAFT.C02\ntitle: Second\n", part.Content)
+
+	_, removed, err := StageArtifact(ctx, nil, InputStageArtifact{Name: "catalog", Delete: true})
+	require.NoError(t, err)
+	assert.Contains(t, removed.Message, "Removed")
+	_, _, err = GetStagedArtifact(ctx, nil, InputGetStagedArtifact{Name: "catalog"})
+	assert.ErrorContains(t, err, "no artifact is staged")
+}
+
+func TestStageArtifactErrors(t *testing.T) {
+	useStaging(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		input       InputStageArtifact
+		errContains string
+	}{
+		{name: "bad name", input: InputStageArtifact{Name: "../etc", ArtifactContent: stagedCatalog}, errContains: "invalid name"},
+		{name: "nothing to do", input: InputStageArtifact{Name: "catalog"}, errContains: "is required"},
+		{name: "edit before staging", input: InputStageArtifact{Name: "catalog", Path: "$.title", Value: "x"}, errContains: "stage it with artifact_content first"},
+		{name: "content with path", input: InputStageArtifact{Name: "catalog", ArtifactContent: stagedCatalog, Path: "$.title"}, errContains: "cannot be combined"},
+		{name: "invalid YAML", input: InputStageArtifact{Name: "catalog", ArtifactContent: "key: [unclosed"}, errContains: "parse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := StageArtifact(ctx, nil, tt.input)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errContains)
+		})
+	}
+
+	original := MaxStagedArtifacts
+	t.Cleanup(func() { MaxStagedArtifacts = original })
+	MaxStagedArtifacts = 1
+	_, _, err := StageArtifact(ctx, nil, InputStageArtifact{Name: "one", ArtifactContent: stagedCatalog})
+	require.NoError(t, err)
+	_, _, err = StageArtifact(ctx, nil, InputStageArtifact{Name: "two", ArtifactContent: stagedCatalog})
+	assert.ErrorContains(t, err, "already has 1 staged artifacts")
+}
+
+func TestStagedArtifactsAreSessionScoped(t *testing.T) {
+	useStaging(t)
+	alice := context.WithValue(context.Background(), sessionKey{}, &mcp.ServerSession{})
+	bob := context.WithValue(context.Background(), sessionKey{}, &mcp.ServerSession{})
+
+	_, _, err := StageArtifact(alice, nil, InputStageArtifact{Name: "catalog", ArtifactContent: stagedCatalog})
+	require.NoError(t, err)
+
+	_, list, err := ListStagedArtifacts(alice, nil, InputListStagedArtifacts{})
+	require.NoError(t, err)
+	require.Len(t, list.Artifacts, 1)
+	assert.Equal(t, "ControlCatalog", list.Artifacts[0].Kind)
+	assert.Equal(t, 1, list.Page.Total)
+
+	_, list, err = ListStagedArtifacts(bob, nil, InputListStagedArtifacts{})
+	require.NoError(t, err)
+	assert.Empty(t, list.Artifacts, "other sessions should not see the draft")
+	_, err = readArtifactURI(bob, "gemara://staged/catalog")
+	assert.ErrorContains(t, err, "no artifact is staged")
+}
+
+func TestStagedArtifactsExpire(t *testing.T) {
+	useStaging(t)
+	originalTTL, originalMax := StagedArtifactTTL, MaxStagingSessions
+	t.Cleanup(func() { StagedArtifactTTL, MaxStagingSessions = originalTTL, originalMax })
+	alice := context.WithValue(context.Background(), sessionKey{}, &mcp.ServerSession{})
+	bob := context.WithValue(context.Background(), sessionKey{}, &mcp.ServerSession{})
+	carol := context.WithValue(context.Background(), sessionKey{}, &mcp.ServerSession{})
+	stage := func(ctx context.Context) {
+		t.Helper()
+		_, _, err := StageArtifact(ctx, nil, InputStageArtifact{Name: "catalog", ArtifactContent: stagedCatalog})
+		require.NoError(t, err)
+	}
+
+	// The session idle longest gives way when the store is full
+	MaxStagingSessions = 2
+	stage(alice)
+	stage(bob)
+	staging.used[callingSession(alice)] = time.Now().Add(-time.Minute)
+	stage(carol)
+	_, err := readArtifactURI(alice, "gemara://staged/catalog")
+	assert.ErrorContains(t, err, "no artifact is staged", "the idle session should be evicted")
+	_, err = readArtifactURI(bob, "gemara://staged/catalog")
+	require.NoError(t, err)
+
+	// Idle sessions are dropped after the TTL
+	StagedArtifactTTL = time.Minute
+	staging.used[callingSession(bob)] = time.Now().Add(-2 * time.Minute)
+	_, err = readArtifactURI(carol, "gemara://staged/catalog")
+	require.NoError(t, err)
+	_, err = readArtifactURI(bob, "gemara://staged/catalog")
+	assert.ErrorContains(t, err, "no artifact is staged", "the idle session should expire")
+	assert.Len(t, staging.sessions, 1)
+}
+
+func TestStagedArtifactsDroppedWithTenantSession(t *testing.T) {
+	useStaging(t)
+	dir := t.TempDir()
+	writeTestFile(t, dir, "tenants.yaml", "tenants:\n  - id: payments\n    workspace-root: .\n    tokens: [payments-token]\n")
+	tenants, err := LoadTenants(filepath.Join(dir, "tenants.yaml"))
+	require.NoError(t, err)
+	handler := TenantHandler(tenants, "", func(tenant *Tenant) *mcp.Server {
+		server := mcp.NewServer(&mcp.Implementation{Name: "test-server"}, nil)
+		AdvisoryMode{Tenant: tenant}.Register(server)
+		return server
+	})
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(httpServer.Close)
+
+	transport := &mcp.StreamableClientTransport{
+		Endpoint:   httpServer.URL,
+		HTTPClient: &http.Client{Transport: tenantAuth{"Authorization": "Bearer payments-token"}},
+		MaxRetries: -1,
+	}
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(context.Background(), transport, nil)
+	require.NoError(t, err)
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "stage_artifact",
+		Arguments: map[string]interface{}{"name": "catalog", "artifact_content": stagedCatalog},
+	})
+	require.NoError(t, err)
+	require.False(t, result.IsError, "staging should succeed")
+	staging.mu.Lock()
+	assert.Len(t, staging.sessions, 1)
+	staging.mu.Unlock()
+
+	require.NoError(t, session.Close())
+	assert.Eventually(t, func() bool {
+		staging.mu.Lock()
+		defer staging.mu.Unlock()
+		return len(staging.sessions) == 0 && len(staging.watched) == 0
+	}, 5*time.Second, 10*time.Millisecond, "the drafts of a closed tenant session should be dropped")
+}
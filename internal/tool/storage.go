@@ -0,0 +1,286 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	// Registers the pure-Go "sqlite" database/sql driver
+	_ "modernc.org/sqlite"
+)
+
+// ErrStorageNotFound is returned by Storage.Get for keys that do not exist.
+var ErrStorageNotFound = errors.New("storage key not found")
+
+// Storage is a key/value blob store for server state that should outlive the
+// process, so deployments can keep it on local disk, in a database, or in an
+// object store. Keys are slash-separated relative paths.
+type Storage interface {
+	// Get returns the value stored under key, or ErrStorageNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Put stores value under key, replacing any previous value.
+	Put(ctx context.Context, key string, value []byte) error
+	// Delete removes key; deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// List returns the keys that start with prefix, in lexical order.
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// OpenStorage opens the storage at location:
+//
+//	/var/lib/gemara or file:///var/lib/gemara       a local directory
+//	sqlite:///var/lib/gemara/state.db               a SQLite database
+//	s3://bucket/prefix?region=eu-west-1             an S3 bucket
+//	s3://bucket/prefix?endpoint=http://minio:9000   an S3-compatible object store
+//
+// An empty location returns nil.
+func OpenStorage(location string) (Storage, error) {
+	if location == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(location)
+	if err != nil || u.Scheme == "" || len(u.Scheme) == 1 {
+		// Plain paths, including Windows drive letters
+		return newFileStorage(location)
+	}
+	switch u.Scheme {
+	case "file":
+		return newFileStorage(u.Path)
+	case "sqlite":
+		return openSQLiteStorage(u.Host + u.Path)
+	case "s3":
+		return newS3Storage(u)
+	default:
+		return nil, fmt.Errorf("unsupported storage %q (use a path, file://, sqlite://, or s3://)", location)
+	}
+}
+
+// validStorageKey rejects keys that could escape a storage root.
+func validStorageKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") {
+		return fmt.Errorf("invalid storage key %q", key)
+	}
+	return nil
+}
+
+// fileStorage keeps each key in a file below a root directory.
+type fileStorage struct {
+	root string
+}
+
+func newFileStorage(root string) (*fileStorage, error) {
+	if root == "" {
+		return nil, fmt.Errorf("storage directory is required")
+	}
+	if err := os.MkdirAll(root, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &fileStorage{root: root}, nil
+}
+
+func (s *fileStorage) Get(_ context.Context, key string) ([]byte, error) {
+	if err := validStorageKey(key); err != nil {
+		return nil, err
+	}
+	value, err := os.ReadFile(filepath.Join(s.root, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrStorageNotFound
+	}
+	return value, err
+}
+
+func (s *fileStorage) Put(_ context.Context, key string, value []byte) error {
+	if err := validStorageKey(key); err != nil {
+		return err
+	}
+	target := filepath.Join(s.root, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+		return err
+	}
+	// Write then rename so readers never see a partial value
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(value); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (s *fileStorage) Delete(_ context.Context, key string) error {
+	if err := validStorageKey(key); err != nil {
+		return err
+	}
+	err := os.Remove(filepath.Join(s.root, filepath.FromSlash(key)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *fileStorage) List(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(s.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	return keys, err
+}
+
+// sqliteDriver is the database/sql driver name used for sqlite:// storage,
+// registered by modernc.org/sqlite.
+const sqliteDriver = "sqlite"
+
+// sqlStorage keeps keys in a single database table.
+type sqlStorage struct {
+	db *sql.DB
+}
+
+func openSQLiteStorage(dsn string) (*sqlStorage, error) {
+	if dsn == "" {
+		return nil, fmt.Errorf("sqlite storage requires a database path")
+	}
+	db, err := sql.Open(sqliteDriver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite storage: %w", err)
+	}
+	// SQLite allows a single writer; one connection serializes writes instead
+	// of failing them with SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+	storage, err := newSQLStorage(db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open sqlite storage %s: %w", dsn, err)
+	}
+	return storage, nil
+}
+
+func newSQLStorage(db *sql.DB) (*sqlStorage, error) {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS gemara_storage (key TEXT PRIMARY KEY, value BLOB NOT NULL)`); err != nil {
+		return nil, fmt.Errorf("failed to initialize storage table: %w", err)
+	}
+	return &sqlStorage{db: db}, nil
+}
+
+func (s *sqlStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+	err := s.db.QueryRowContext(ctx, `SELECT value FROM gemara_storage WHERE key = ?`, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrStorageNotFound
+	}
+	return value, err
+}
+
+func (s *sqlStorage) Put(ctx context.Context, key string, value []byte) error {
+	if err := validStorageKey(key); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO gemara_storage (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value`, key, value)
+	return err
+}
+
+func (s *sqlStorage) Delete(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM gemara_storage WHERE key = ?`, key)
+	return err
+}
+
+func (s *sqlStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT key FROM gemara_storage WHERE substr(key, 1, length(?)) = ? ORDER BY key`, prefix, prefix)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// persistedFetch is a fetched document kept in the configured Storage.
+type persistedFetch struct {
+	URL     string    `json:"url"`
+	ETag    string    `json:"etag,omitempty"`
+	Fetched time.Time `json:"fetched"`
+	Body    []byte    `json:"body"`
+}
+
+// persistedFetchKey returns the storage key of a fetched URL.
+func persistedFetchKey(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	return "cache/fetch/" + hex.EncodeToString(sum[:]) + ".json"
+}
+
+// persistFetch saves a fetched document to the configured storage. Failures
+// only cost a later cold start, so they are reported as events rather than
+// errors.
+func persistFetch(ctx context.Context, rawURL string, body []byte, etag string) {
+	storage := serverConfig(ctx).Storage
+	if storage == nil {
+		return
+	}
+	value, err := json.Marshal(persistedFetch{URL: rawURL, ETag: etag, Fetched: time.Now().UTC(), Body: body})
+	if err == nil {
+		err = storage.Put(ctx, persistedFetchKey(rawURL), value)
+	}
+	if err != nil {
+		emitUpstreamFailure(ctx, "cache storage", err)
+	}
+}
+
+// loadPersistedFetch returns the last document fetched from rawURL, if stored.
+func loadPersistedFetch(ctx context.Context, rawURL string) (persistedFetch, bool) {
+	storage := serverConfig(ctx).Storage
+	if storage == nil {
+		return persistedFetch{}, false
+	}
+	value, err := storage.Get(ctx, persistedFetchKey(rawURL))
+	if err != nil {
+		if !errors.Is(err, ErrStorageNotFound) {
+			emitUpstreamFailure(ctx, "cache storage", err)
+		}
+		return persistedFetch{}, false
+	}
+	var fetched persistedFetch
+	if err := json.Unmarshal(value, &fetched); err != nil || fetched.URL != rawURL {
+		return persistedFetch{}, false
+	}
+	return fetched, true
+}
@@ -0,0 +1,318 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultSuggestionLimit     = 5
+	defaultStaleEvaluationDays = 30
+)
+
+// Suggestion scores order recommendations; higher scores are more urgent.
+const (
+	scoreInvalidArtifact = 100
+	scoreOverdueFindings = 90
+	scoreLintErrors      = 70
+	scoreStaleEvaluation = 60
+	scoreMissingArtifact = 50
+	scoreLintWarnings    = 20
+)
+
+// MetadataSuggestNextAction describes the SuggestNextAction tool.
+var MetadataSuggestNextAction = &mcp.Tool{
+	Name: "suggest_next_action",
+	Description: "Inspect the artifacts in a workspace directory (missing artifacts, failing validations, lint findings, " +
+		"stale evaluations, overdue findings) and return a ranked list of recommended tool invocations with prefilled arguments.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"directory": map[string]interface{}{
+				"type":        "string",
+				"description": "Directory of artifacts to inspect, searched recursively (default: the workspace root)",
+			},
+			"limit": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"description": fmt.Sprintf("Maximum number of suggestions (default: %d)", defaultSuggestionLimit),
+			},
+			"stale_after_days": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"description": fmt.Sprintf("Age in days after which an evaluation log is stale (default: %d)", defaultStaleEvaluationDays),
+			},
+			"as_of": map[string]interface{}{
+				"type":        "string",
+				"description": "Date to judge staleness and due dates against, RFC 3339 or YYYY-MM-DD (default: now)",
+			},
+		},
+	},
+}
+
+// InputSuggestNextAction is the input for the SuggestNextAction tool.
+type InputSuggestNextAction struct {
+	Directory      string `json:"directory,omitempty"`
+	Limit          int    `json:"limit,omitempty"`
+	StaleAfterDays int    `json:"stale_after_days,omitempty"`
+	AsOf           string `json:"as_of,omitempty"`
+}
+
+// SuggestedAction is a recommended tool invocation.
+type SuggestedAction struct {
+	Rank      int                    `json:"rank"`
+	Tool      string                 `json:"tool"`
+	Arguments map[string]interface{} `json:"arguments"`
+	Reason    string                 `json:"reason"`
+	Artifact  string                 `json:"artifact,omitempty"`
+
+	score int
+}
+
+// OutputSuggestNextAction is the output for the SuggestNextAction tool.
+type OutputSuggestNextAction struct {
+	Directory   string            `json:"directory"`
+	Artifacts   int               `json:"artifacts"`
+	Suggestions []SuggestedAction `json:"suggestions"`
+	// Omitted counts suggestions beyond the limit.
+	Omitted int    `json:"omitted,omitempty"`
+	Message string `json:"message"`
+}
+
+// SuggestNextAction recommends what to do next in a workspace.
+func SuggestNextAction(ctx context.Context, _ *mcp.CallToolRequest, input InputSuggestNextAction) (*mcp.CallToolResult, OutputSuggestNextAction, error) {
+	dir, err := workspaceDirectory(ctx, input.Directory)
+	if err != nil {
+		return nil, OutputSuggestNextAction{}, err
+	}
+	if dir == "" {
+		return nil, OutputSuggestNextAction{}, fmt.Errorf("directory is required when no workspace root is configured")
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, OutputSuggestNextAction{}, fmt.Errorf("failed to read directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, OutputSuggestNextAction{}, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultSuggestionLimit
+	}
+	staleAfter := input.StaleAfterDays
+	if staleAfter <= 0 {
+		staleAfter = defaultStaleEvaluationDays
+	}
+	asOf := time.Now().UTC()
+	if input.AsOf != "" {
+		t, ok := parseFindingTime(input.AsOf)
+		if !ok {
+			return nil, OutputSuggestNextAction{}, fmt.Errorf("invalid as_of %q: expected RFC 3339 or YYYY-MM-DD", input.AsOf)
+		}
+		asOf = t
+	}
+
+	files, err := findArtifactFiles(dir)
+	if err != nil {
+		return nil, OutputSuggestNextAction{}, err
+	}
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputSuggestNextAction{}, err
+	}
+
+	var suggestions []SuggestedAction
+	kinds := map[string]int{}
+	var evaluationLogs []ArtifactInput
+	for _, file := range files {
+		result, err := checkConformance(ctx, schema, dir, file)
+		if err != nil {
+			return nil, OutputSuggestNextAction{}, err
+		}
+		content, err := readArtifactFile(ctx, file)
+		if err != nil {
+			return nil, OutputSuggestNextAction{}, err
+		}
+		kinds[result.Definition]++
+		suggestions = append(suggestions, artifactSuggestions(file, result, string(content))...)
+
+		if result.Definition == "#EvaluationLog" {
+			artifact := ArtifactInput{Name: result.Path, Content: string(content)}
+			evaluationLogs = append(evaluationLogs, artifact)
+			if s, ok := staleEvaluationSuggestion(ctx, artifact, asOf, staleAfter); ok {
+				suggestions = append(suggestions, s)
+			}
+		}
+	}
+
+	if len(evaluationLogs) > 0 {
+		if s, ok := overdueFindingsSuggestion(ctx, evaluationLogs, asOf, serverConfig(ctx).FindingSLA); ok {
+			suggestions = append(suggestions, s)
+		}
+	}
+	suggestions = append(suggestions, missingArtifactSuggestions(kinds)...)
+
+	sort.SliceStable(suggestions, func(i, j int) bool { return suggestions[i].score > suggestions[j].score })
+	output := OutputSuggestNextAction{Directory: dir, Artifacts: len(files), Suggestions: []SuggestedAction{}}
+	if len(suggestions) > limit {
+		output.Omitted = len(suggestions) - limit
+		suggestions = suggestions[:limit]
+	}
+	for i := range suggestions {
+		suggestions[i].Rank = i + 1
+	}
+	output.Suggestions = append(output.Suggestions, suggestions...)
+
+	switch {
+	case len(output.Suggestions) == 0:
+		output.Message = fmt.Sprintf("Nothing to do: %d artifact(s) are valid, lint-clean, and current", len(files))
+	default:
+		output.Message = fmt.Sprintf("%d suggestion(s) for %d artifact(s); start with %s", len(output.Suggestions)+output.Omitted, len(files), output.Suggestions[0].Tool)
+	}
+	return nil, output, nil
+}
+
+// artifactSuggestions recommends repairs for an artifact that fails validation or lint.
+func artifactSuggestions(file string, result ConformanceResult, content string) []SuggestedAction {
+	switch {
+	case result.Definition == "":
+		return []SuggestedAction{{
+			Tool:      MetadataDetectGemaraArtifactType.Name,
+			Arguments: map[string]interface{}{"artifact_content": content},
+			Reason:    "The artifact type could not be determined from its top-level fields",
+			Artifact:  result.Path,
+			score:     scoreInvalidArtifact,
+		}}
+	case !result.SchemaValid:
+		return []SuggestedAction{{
+			Tool:      MetadataFixGemaraArtifact.Name,
+			Arguments: map[string]interface{}{"artifact_content": content, "definition": result.Definition},
+			Reason:    fmt.Sprintf("Fails %s validation with %d error(s); apply safe repairs, then revalidate", result.Definition, len(result.SchemaErrors)),
+			Artifact:  result.Path,
+			score:     scoreInvalidArtifact,
+		}, {
+			Tool:      MetadataValidateGemaraArtifact.Name,
+			Arguments: map[string]interface{}{"artifact_uri": fileURL(absPath(file)), "definition": result.Definition},
+			Reason:    "Review the remaining validation errors with their locations",
+			Artifact:  result.Path,
+			score:     scoreInvalidArtifact - 1,
+		}}
+	}
+
+	errors, warnings := 0, 0
+	for _, f := range result.Findings {
+		switch f.Severity {
+		case severityError:
+			errors++
+		case severityWarning:
+			warnings++
+		}
+	}
+	s := SuggestedAction{
+		Tool:      MetadataLintGemaraArtifact.Name,
+		Arguments: map[string]interface{}{"artifact_content": content},
+		Artifact:  result.Path,
+	}
+	switch {
+	case errors > 0:
+		s.Reason = fmt.Sprintf("Schema-valid but has %d lint error(s); apply the suggested fixes", errors)
+		s.score = scoreLintErrors
+	case warnings > 0:
+		s.Reason = fmt.Sprintf("Has %d lint warning(s)", warnings)
+		s.score = scoreLintWarnings
+	default:
+		return nil
+	}
+	return []SuggestedAction{s}
+}
+
+// staleEvaluationSuggestion flags an evaluation log whose latest assessment is
+// older than staleAfter days.
+func staleEvaluationSuggestion(ctx context.Context, artifact ArtifactInput, asOf time.Time, staleAfter int) (SuggestedAction, bool) {
+	doc, err := parseArtifact(ctx, artifact.Content)
+	if err != nil {
+		return SuggestedAction{}, false
+	}
+	var latest time.Time
+	walkArtifact(doc, "$", func(_ string, value interface{}) {
+		if node, ok := value.(map[string]interface{}); ok {
+			if t, ok := findingTime(node); ok && t.After(latest) {
+				latest = t
+			}
+		}
+	})
+
+	reason := "Evaluation log records no assessment times, so its currency cannot be judged; re-run the evaluation"
+	if !latest.IsZero() {
+		age := int(asOf.Sub(latest).Hours() / 24)
+		if age <= staleAfter {
+			return SuggestedAction{}, false
+		}
+		reason = fmt.Sprintf("Last assessed %d day(s) ago (%s), beyond %d day(s); re-run the evaluation and review open findings",
+			age, latest.Format(time.DateOnly), staleAfter)
+	}
+	return SuggestedAction{
+		Tool:      MetadataListOverdueFindings.Name,
+		Arguments: map[string]interface{}{"artifacts": []ArtifactInput{artifact}, "as_of": asOf.Format(time.DateOnly)},
+		Reason:    reason,
+		Artifact:  artifact.Name,
+		score:     scoreStaleEvaluation,
+	}, true
+}
+
+// overdueFindingsSuggestion recommends reviewing findings past their SLA.
+func overdueFindingsSuggestion(ctx context.Context, logs []ArtifactInput, asOf time.Time, sla map[string]time.Duration) (SuggestedAction, bool) {
+	findings, err := collectFindings(ctx, logs, sla)
+	if err != nil {
+		return SuggestedAction{}, false
+	}
+	overdue := len(overdueFindings(findings, asOf))
+	if overdue == 0 {
+		return SuggestedAction{}, false
+	}
+	return SuggestedAction{
+		Tool:      MetadataListOverdueFindings.Name,
+		Arguments: map[string]interface{}{"artifacts": logs, "as_of": asOf.Format(time.DateOnly)},
+		Reason:    fmt.Sprintf("%d finding(s) are past their remediation due date", overdue),
+		score:     scoreOverdueFindings,
+	}, true
+}
+
+// missingArtifactSuggestions recommends templates for the next artifact in the
+// Gemara workflow: a catalog, then a policy that adopts it, then evaluations.
+func missingArtifactSuggestions(kinds map[string]int) []SuggestedAction {
+	template := func(definition, reason string) SuggestedAction {
+		return SuggestedAction{
+			Tool:      MetadataListTemplates.Name,
+			Arguments: map[string]interface{}{"definition": definition},
+			Reason:    reason,
+			score:     scoreMissingArtifact,
+		}
+	}
+	switch {
+	case kinds["#ControlCatalog"] == 0 && kinds["#Policy"] == 0:
+		return []SuggestedAction{template("#ControlCatalog", "The workspace has no control catalog; start from a template")}
+	case kinds["#Policy"] == 0:
+		return []SuggestedAction{template("#Policy", "Controls are cataloged but no policy adopts them")}
+	case kinds["#EvaluationLog"] == 0:
+		return []SuggestedAction{template("#EvaluationLog", "A policy exists but nothing records evaluations against it")}
+	}
+	return nil
+}
+
+// absPath returns path made absolute, or path itself if that fails.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,175 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// suggestionTools returns the tool of each suggestion in rank order.
+func suggestionTools(output OutputSuggestNextAction) []string {
+	tools := make([]string, 0, len(output.Suggestions))
+	for _, s := range output.Suggestions {
+		tools = append(tools, s.Tool)
+	}
+	return tools
+}
+
+func TestSuggestNextAction(t *testing.T) {
+	useTestSchema(t)
+
+	catalog, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err)
+	policy, err := os.ReadFile(filepath.Join("test-data", "policy.yaml"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		files          map[string]string
+		input          InputSuggestNextAction
+		wantErr        string
+		validateOutput func(t *testing.T, output OutputSuggestNextAction)
+	}{
+		{
+			name: "empty workspace",
+			validateOutput: func(t *testing.T, output OutputSuggestNextAction) {
+				require.Len(t, output.Suggestions, 1)
+				assert.Equal(t, "list_templates", output.Suggestions[0].Tool)
+				assert.Equal(t, "#ControlCatalog", output.Suggestions[0].Arguments["definition"])
+			},
+		},
+		{
+			name:  "catalog without policy",
+			files: map[string]string{"catalog.yaml": string(catalog)},
+			validateOutput: func(t *testing.T, output OutputSuggestNextAction) {
+				assert.Contains(t, suggestionTools(output), "list_templates")
+				for _, s := range output.Suggestions {
+					if s.Tool == "list_templates" {
+						assert.Equal(t, "#Policy", s.Arguments["definition"])
+					}
+				}
+			},
+		},
+		{
+			name: "invalid artifact ranks first",
+			files: map[string]string{
+				"catalog.yaml": "metadata:\n  id: BROKEN\ntitle: Broken\ncontrols: []\n",
+				"notes.yaml":   "hello: world\n",
+			},
+			validateOutput: func(t *testing.T, output OutputSuggestNextAction) {
+				tools := suggestionTools(output)
+				require.GreaterOrEqual(t, len(tools), 3)
+				assert.ElementsMatch(t, []string{"fix_gemara_artifact", "detect_gemara_artifact_type"}, tools[:2])
+				assert.Equal(t, 1, output.Suggestions[0].Rank)
+
+				for _, s := range output.Suggestions {
+					if s.Tool == "validate_gemara_artifact" {
+						assert.Contains(t, s.Arguments["artifact_uri"], "file://")
+						assert.Equal(t, "#ControlCatalog", s.Arguments["definition"])
+					}
+					if s.Tool == "fix_gemara_artifact" {
+						assert.Equal(t, "catalog.yaml", s.Artifact)
+						assert.Contains(t, s.Arguments["artifact_content"], "BROKEN", "arguments should be prefilled")
+					}
+				}
+			},
+		},
+		{
+			name: "stale evaluations and overdue findings",
+			files: map[string]string{
+				"catalog.yaml": string(catalog),
+				"policy.yaml":  string(policy),
+				"eval.yaml":    findingsTestLog,
+			},
+			input: InputSuggestNextAction{AsOf: "2025-06-01", Limit: 10},
+			validateOutput: func(t *testing.T, output OutputSuggestNextAction) {
+				tools := suggestionTools(output)
+				require.NotEmpty(t, tools)
+				var overdue *SuggestedAction
+				for i := range output.Suggestions {
+					if output.Suggestions[i].Tool == "list_overdue_findings" && output.Suggestions[i].Artifact == "" {
+						overdue = &output.Suggestions[i]
+					}
+				}
+				require.NotNil(t, overdue, "overdue findings should be reported")
+				assert.Contains(t, overdue.Reason, "past their remediation due date")
+				assert.Len(t, overdue.Arguments["artifacts"], 1)
+
+				var stale *SuggestedAction
+				for i := range output.Suggestions {
+					if output.Suggestions[i].Artifact == "eval.yaml" && output.Suggestions[i].Tool == "list_overdue_findings" {
+						stale = &output.Suggestions[i]
+					}
+				}
+				require.NotNil(t, stale, "the evaluation log should be flagged as stale")
+				assert.Contains(t, stale.Reason, "Last assessed 83 day(s) ago (2025-03-10)")
+				assert.NotContains(t, tools, "list_templates", "no artifact type is missing")
+			},
+		},
+		{
+			name:  "recent evaluations are current",
+			files: map[string]string{"eval.yaml": findingsTestLog},
+			input: InputSuggestNextAction{AsOf: "2025-03-20", StaleAfterDays: 30},
+			validateOutput: func(t *testing.T, output OutputSuggestNextAction) {
+				for _, s := range output.Suggestions {
+					assert.False(t, s.Artifact == "eval.yaml" && s.Tool == "list_overdue_findings", "an evaluation 10 days old should not be stale")
+				}
+			},
+		},
+		{
+			name: "limit",
+			files: map[string]string{
+				"a.yaml": "hello: a\n",
+				"b.yaml": "hello: b\n",
+				"c.yaml": "hello: c\n",
+			},
+			input: InputSuggestNextAction{Limit: 2},
+			validateOutput: func(t *testing.T, output OutputSuggestNextAction) {
+				assert.Len(t, output.Suggestions, 2)
+				assert.Equal(t, 2, output.Omitted)
+				assert.Equal(t, 2, output.Suggestions[1].Rank)
+			},
+		},
+		{
+			name:    "invalid as_of",
+			input:   InputSuggestNextAction{AsOf: "soon"},
+			wantErr: "invalid as_of",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeTestFile(t, dir, name, content)
+			}
+			tt.input.Directory = dir
+
+			_, output, err := SuggestNextAction(context.Background(), nil, tt.input)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			tt.validateOutput(t, output)
+		})
+	}
+}
+
+func TestSuggestNextActionWorkspaceRoot(t *testing.T) {
+	useTestSchema(t)
+	_, _, err := SuggestNextAction(context.Background(), nil, InputSuggestNextAction{})
+	assert.ErrorContains(t, err, "no workspace root")
+
+	root := t.TempDir()
+	ctx := withTestConfig(func(c *Config) { c.WorkspaceRoot = root })
+	_, output, err := SuggestNextAction(ctx, nil, InputSuggestNextAction{})
+	require.NoError(t, err)
+	assert.Equal(t, root, output.Directory, "should default to the workspace root")
+}
@@ -0,0 +1,275 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// maxSyntheticControls bounds catalogs returned through the tool; the CLI has no limit.
+	maxSyntheticControls = 10000
+	maxSyntheticMappings = 100
+)
+
+// syntheticFrameworks are the guideline references synthetic mappings point at.
+var syntheticFrameworks = []string{"SYN-CSF", "SYN-CCM", "SYN-800-53"}
+
+// syntheticCategories are the applicability categories of synthetic catalogs.
+var syntheticCategories = []string{"tier-1", "tier-2", "tier-3"}
+
+// syntheticWords is the vocabulary for generated titles and text.
+var syntheticWords = []string{
+	"access", "audit", "backup", "boundary", "certificate", "configuration", "credential", "data",
+	"encryption", "endpoint", "identity", "incident", "integrity", "inventory", "key", "logging",
+	"monitoring", "network", "patch", "privilege", "recovery", "retention", "secret", "session",
+	"storage", "supply", "token", "traffic", "vulnerability", "workload",
+}
+
+// SyntheticCatalogOptions sizes a generated catalog.
+type SyntheticCatalogOptions struct {
+	// Controls is the number of controls.
+	Controls int
+	// Mappings is the number of guideline mapping entries per control.
+	Mappings int
+	// Requirements is the number of assessment requirements per control.
+	Requirements int
+	// Families is the number of control families; zero picks one per 25 controls.
+	Families int
+	// Seed makes generation reproducible.
+	Seed uint64
+}
+
+// SyntheticCatalogStats counts what a generated catalog contains.
+type SyntheticCatalogStats struct {
+	Controls     int `json:"controls"`
+	Families     int `json:"families"`
+	Mappings     int `json:"mappings"`
+	Requirements int `json:"requirements"`
+	Bytes        int `json:"bytes"`
+}
+
+// GenerateSyntheticCatalog produces a deterministic ControlCatalog of the
+// requested size for load testing.
+func GenerateSyntheticCatalog(opts SyntheticCatalogOptions) (string, SyntheticCatalogStats, error) {
+	if opts.Controls < 1 {
+		return "", SyntheticCatalogStats{}, fmt.Errorf("controls must be at least 1")
+	}
+	if opts.Mappings < 0 || opts.Requirements < 0 || opts.Families < 0 {
+		return "", SyntheticCatalogStats{}, fmt.Errorf("mappings, requirements, and families must not be negative")
+	}
+	families := opts.Families
+	if families == 0 {
+		families = (opts.Controls + 24) / 25
+	}
+	families = min(families, opts.Controls)
+
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x9e3779b97f4a7c15))
+	var b strings.Builder
+	stats := SyntheticCatalogStats{Controls: opts.Controls, Families: families}
+
+	fmt.Fprintf(&b, "metadata:\n")
+	fmt.Fprintf(&b, "  id: SYN-CATALOG\n")
+	fmt.Fprintf(&b, "  description: Synthetic catalog of %d controls generated for load testing (seed %d).\n", opts.Controls, opts.Seed)
+	fmt.Fprintf(&b, "  version: 1.0.0\n")
+	fmt.Fprintf(&b, "  author:\n    id: gemara-mcp\n    name: Gemara MCP synthetic generator\n    type: Software\n")
+	fmt.Fprintf(&b, "  applicability-categories:\n")
+	for _, c := range syntheticCategories {
+		fmt.Fprintf(&b, "    - id: %s\n      title: %s\n      description: Synthetic applicability category %s.\n", c, syntheticTitle(strings.ReplaceAll(c, "-", " ")), c)
+	}
+	fmt.Fprintf(&b, "title: Synthetic Control Catalog\n")
+
+	fmt.Fprintf(&b, "families:\n")
+	for f := 1; f <= families; f++ {
+		fmt.Fprintf(&b, "  - id: SYN.F%02d\n    title: %s\n    description: %s\n", f, syntheticPhrase(rng, 2), syntheticSentence(rng, 12))
+	}
+
+	fmt.Fprintf(&b, "controls:\n")
+	for c := 1; c <= opts.Controls; c++ {
+		id := fmt.Sprintf("SYN.C%05d", c)
+		fmt.Fprintf(&b, "  - id: %s\n", id)
+		fmt.Fprintf(&b, "    family: SYN.F%02d\n", (c-1)%families+1)
+		fmt.Fprintf(&b, "    title: %s\n", syntheticPhrase(rng, 4))
+		fmt.Fprintf(&b, "    objective: %s\n", syntheticSentence(rng, 16))
+
+		if opts.Mappings > 0 {
+			fmt.Fprintf(&b, "    guideline-mappings:\n")
+			for fi, framework := range syntheticFrameworks {
+				// Spread entries across frameworks, earlier frameworks taking the remainder
+				n := opts.Mappings / len(syntheticFrameworks)
+				if fi < opts.Mappings%len(syntheticFrameworks) {
+					n++
+				}
+				if n == 0 {
+					continue
+				}
+				fmt.Fprintf(&b, "      - reference-id: %s\n        entries:\n", framework)
+				for e := 0; e < n; e++ {
+					fmt.Fprintf(&b, "          - reference-id: %s-%d.%d\n            strength: %d\n", framework, rng.IntN(20)+1, rng.IntN(50)+1, rng.IntN(10)+1)
+				}
+				stats.Mappings += n
+			}
+		}
+
+		fmt.Fprintf(&b, "    assessment-requirements:")
+		if opts.Requirements == 0 {
+			fmt.Fprintf(&b, " []\n")
+		} else {
+			fmt.Fprintf(&b, "\n")
+		}
+		for r := 1; r <= opts.Requirements; r++ {
+			fmt.Fprintf(&b, "      - id: %s.TR%02d\n", id, r)
+			fmt.Fprintf(&b, "        text: %s\n", syntheticSentence(rng, 20))
+			fmt.Fprintf(&b, "        applicability:\n")
+			for _, category := range syntheticCategories[:rng.IntN(len(syntheticCategories))+1] {
+				fmt.Fprintf(&b, "          - %s\n", category)
+			}
+		}
+		stats.Requirements += opts.Requirements
+	}
+
+	stats.Bytes = b.Len()
+	return b.String(), stats, nil
+}
+
+// syntheticPhrase returns n capitalized words.
+func syntheticPhrase(rng *rand.Rand, n int) string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = syntheticTitle(syntheticWords[rng.IntN(len(syntheticWords))])
+	}
+	return strings.Join(words, " ")
+}
+
+// syntheticSentence returns a sentence of n words.
+func syntheticSentence(rng *rand.Rand, n int) string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = syntheticWords[rng.IntN(len(syntheticWords))]
+	}
+	return syntheticTitle(strings.Join(words, " ")) + "."
+}
+
+// syntheticTitle capitalizes the first letter of s.
+func syntheticTitle(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// MetadataGenerateSyntheticCatalog describes the GenerateSyntheticCatalog tool.
+var MetadataGenerateSyntheticCatalog = &mcp.Tool{
+	Name: "generate_synthetic_catalog",
+	Description: "Generate a deterministic, schema-valid ControlCatalog of configurable size (controls, mappings, " +
+		"assessment requirements) for load-testing validation, search, and rendering.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"controls": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"maximum":     maxSyntheticControls,
+				"description": "Number of controls (default: 100)",
+			},
+			"mappings_per_control": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     0,
+				"maximum":     maxSyntheticMappings,
+				"description": "Guideline mapping entries per control (default: 3)",
+			},
+			"requirements_per_control": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     0,
+				"maximum":     maxSyntheticMappings,
+				"description": "Assessment requirements per control (default: 2)",
+			},
+			"families": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     0,
+				"description": "Number of control families (default: one per 25 controls)",
+			},
+			"seed": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     0,
+				"description": "Seed for reproducible output (default: 1)",
+			},
+			"validate": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Validate the generated catalog against the schema (default: false)",
+			},
+		},
+	},
+}
+
+// InputGenerateSyntheticCatalog is the input for the GenerateSyntheticCatalog tool.
+type InputGenerateSyntheticCatalog struct {
+	Controls     *int    `json:"controls,omitempty"`
+	Mappings     *int    `json:"mappings_per_control,omitempty"`
+	Requirements *int    `json:"requirements_per_control,omitempty"`
+	Families     int     `json:"families,omitempty"`
+	Seed         *uint64 `json:"seed,omitempty"`
+	Validate     bool    `json:"validate,omitempty"`
+}
+
+// OutputGenerateSyntheticCatalog is the output for the GenerateSyntheticCatalog tool.
+type OutputGenerateSyntheticCatalog struct {
+	Content string                `json:"content"`
+	Stats   SyntheticCatalogStats `json:"stats"`
+	Valid   *bool                 `json:"valid,omitempty"`
+	Errors  []string              `json:"errors,omitempty"`
+	Message string                `json:"message"`
+}
+
+// GenerateSyntheticCatalogTool generates a synthetic catalog through MCP.
+func GenerateSyntheticCatalogTool(ctx context.Context, _ *mcp.CallToolRequest, input InputGenerateSyntheticCatalog) (*mcp.CallToolResult, OutputGenerateSyntheticCatalog, error) {
+	opts := SyntheticCatalogOptions{Controls: 100, Mappings: 3, Requirements: 2, Families: input.Families, Seed: 1}
+	if input.Controls != nil {
+		opts.Controls = *input.Controls
+	}
+	if input.Mappings != nil {
+		opts.Mappings = *input.Mappings
+	}
+	if input.Requirements != nil {
+		opts.Requirements = *input.Requirements
+	}
+	if input.Seed != nil {
+		opts.Seed = *input.Seed
+	}
+	if opts.Controls > maxSyntheticControls {
+		return nil, OutputGenerateSyntheticCatalog{}, fmt.Errorf("controls must be at most %d; use 'gemara-mcp generate catalog' for larger catalogs", maxSyntheticControls)
+	}
+	if opts.Mappings > maxSyntheticMappings || opts.Requirements > maxSyntheticMappings {
+		return nil, OutputGenerateSyntheticCatalog{}, fmt.Errorf("mappings and requirements per control must be at most %d", maxSyntheticMappings)
+	}
+
+	content, stats, err := GenerateSyntheticCatalog(opts)
+	if err != nil {
+		return nil, OutputGenerateSyntheticCatalog{}, err
+	}
+
+	output := OutputGenerateSyntheticCatalog{
+		Content: content,
+		Stats:   stats,
+		Message: fmt.Sprintf("Generated %d control(s) with %d mapping(s) and %d requirement(s) (%d bytes)", stats.Controls, stats.Mappings, stats.Requirements, stats.Bytes),
+	}
+	if input.Validate {
+		schema, err := loadSchema(ctx)
+		if err != nil {
+			return nil, OutputGenerateSyntheticCatalog{}, err
+		}
+		validation, err := validateAgainstSchema(schema, "#ControlCatalog", content)
+		if err != nil {
+			return nil, OutputGenerateSyntheticCatalog{}, err
+		}
+		output.Valid = &validation.Valid
+		output.Errors = validation.Errors
+		output.Message += "; " + strings.ToLower(validation.Message)
+	}
+	return nil, output, nil
+}
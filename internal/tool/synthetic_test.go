@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSyntheticCatalog(t *testing.T) {
+	useTestSchema(t)
+	schema, err := loadSchema(context.Background())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		opts        SyntheticCatalogOptions
+		wantErr     bool
+		errContains string
+		want        SyntheticCatalogStats
+	}{
+		{
+			name:        "no controls",
+			opts:        SyntheticCatalogOptions{},
+			wantErr:     true,
+			errContains: "at least 1",
+		},
+		{
+			name:        "negative mappings",
+			opts:        SyntheticCatalogOptions{Controls: 1, Mappings: -1},
+			wantErr:     true,
+			errContains: "must not be negative",
+		},
+		{
+			name: "default families",
+			opts: SyntheticCatalogOptions{Controls: 60, Mappings: 4, Requirements: 2},
+			want: SyntheticCatalogStats{Controls: 60, Families: 3, Mappings: 240, Requirements: 120},
+		},
+		{
+			name: "no mappings or requirements",
+			opts: SyntheticCatalogOptions{Controls: 5, Families: 2},
+			want: SyntheticCatalogStats{Controls: 5, Families: 2},
+		},
+		{
+			name: "families capped at controls",
+			opts: SyntheticCatalogOptions{Controls: 2, Mappings: 1, Requirements: 1, Families: 10},
+			want: SyntheticCatalogStats{Controls: 2, Families: 2, Mappings: 2, Requirements: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, stats, err := GenerateSyntheticCatalog(tt.opts)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+
+			tt.want.Bytes = len(content)
+			assert.Equal(t, tt.want, stats)
+
+			validation, err := validateAgainstSchema(schema, "#ControlCatalog", content)
+			require.NoError(t, err)
+			assert.True(t, validation.Valid, "generated catalog should be valid: %v", validation.Errors)
+		})
+	}
+}
+
+func TestGenerateSyntheticCatalogDeterministic(t *testing.T) {
+	opts := SyntheticCatalogOptions{Controls: 20, Mappings: 3, Requirements: 2, Seed: 7}
+	first, _, err := GenerateSyntheticCatalog(opts)
+	require.NoError(t, err)
+	second, _, err := GenerateSyntheticCatalog(opts)
+	require.NoError(t, err)
+	assert.Equal(t, first, second, "the same seed should produce the same catalog")
+
+	opts.Seed = 8
+	other, _, err := GenerateSyntheticCatalog(opts)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, other, "a different seed should produce a different catalog")
+}
+
+func TestGenerateSyntheticCatalogTool(t *testing.T) {
+	useTestSchema(t)
+	controls := 10
+
+	_, output, err := GenerateSyntheticCatalogTool(context.Background(), nil, InputGenerateSyntheticCatalog{Controls: &controls, Validate: true})
+	require.NoError(t, err)
+	assert.Equal(t, 10, output.Stats.Controls)
+	assert.Equal(t, 30, output.Stats.Mappings, "should default to three mappings per control")
+	assert.Equal(t, 20, output.Stats.Requirements, "should default to two requirements per control")
+	require.NotNil(t, output.Valid)
+	assert.True(t, *output.Valid, "generated catalog should be valid: %v", output.Errors)
+
+	tooMany := maxSyntheticControls + 1
+	_, _, err = GenerateSyntheticCatalogTool(context.Background(), nil, InputGenerateSyntheticCatalog{Controls: &tooMany})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "gemara-mcp generate catalog")
+}
+
+// syntheticBenchmarkSizes are the catalog sizes used for the performance
+// numbers published in the README.
+var syntheticBenchmarkSizes = []int{100, 1000, 5000}
+
+// benchmarkSyntheticCatalogs runs fn against synthetic catalogs of each size.
+func benchmarkSyntheticCatalogs(b *testing.B, fn func(b *testing.B, content string)) {
+	for _, controls := range syntheticBenchmarkSizes {
+		content, stats, err := GenerateSyntheticCatalog(SyntheticCatalogOptions{Controls: controls, Mappings: 6, Requirements: 2, Seed: 1})
+		require.NoError(b, err)
+		b.Run(fmt.Sprintf("controls=%d", controls), func(b *testing.B) {
+			b.SetBytes(int64(stats.Bytes))
+			for b.Loop() {
+				fn(b, content)
+			}
+		})
+	}
+}
+
+func BenchmarkValidateSyntheticCatalog(b *testing.B) {
+	useTestSchema(b)
+	schema, err := loadSchema(context.Background())
+	require.NoError(b, err)
+
+	benchmarkSyntheticCatalogs(b, func(b *testing.B, content string) {
+		validation, err := validateAgainstSchema(schema, "#ControlCatalog", content)
+		if err != nil || !validation.Valid {
+			b.Fatalf("validation failed: %v %v", err, validation.Errors)
+		}
+	})
+}
+
+func BenchmarkLintSyntheticCatalog(b *testing.B) {
+	benchmarkSyntheticCatalogs(b, func(b *testing.B, content string) {
+		if _, _, err := LintGemaraArtifact(context.Background(), nil, InputLintGemaraArtifact{ArtifactContent: content}); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
+
+func BenchmarkRenderSyntheticCatalog(b *testing.B) {
+	benchmarkSyntheticCatalogs(b, func(b *testing.B, content string) {
+		doc, err := parseArtifact(context.Background(), content)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := renderOutput(AudienceHuman, doc); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
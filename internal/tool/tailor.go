@@ -0,0 +1,392 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// parameterPlaceholder matches a parameter in control text, written as
+// {{ name }} or, as in OSCAL, {{ insert: param, name }}.
+var parameterPlaceholder = regexp.MustCompile(`\{\{\s*(?:insert:\s*param,\s*)?([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// MetadataTailorCatalog describes the TailorCatalog tool.
+var MetadataTailorCatalog = &mcp.Tool{
+	Name: "tailor_catalog",
+	Description: "Tailor a baseline ControlCatalog the way an OSCAL profile does: select controls by id or family, " +
+		"exclude controls, set the values of {{ parameter }} placeholders in control text, and scope assessment " +
+		"requirements to applicability categories. Returns the tailored catalog, validated against the schema, and a " +
+		"tailoring record of every decision to keep next to it. Nothing is written to disk.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"artifact_content": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML content of the baseline ControlCatalog",
+			},
+			"artifact_uri": map[string]interface{}{
+				"type": "string",
+				"description": "URI of the baseline catalog instead of inline content: file:// (within the workspace root), " +
+					"https://, or gemara://examples/{definition}/{n}",
+			},
+			"include_controls": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "IDs of the controls to keep (default: every control, unless include_families is set)",
+			},
+			"include_families": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "IDs of the families whose controls are kept",
+			},
+			"exclude_controls": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "IDs of controls to leave out even if included",
+			},
+			"parameters": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": map[string]interface{}{"type": "string"},
+				"description":          "Values of the {{ name }} placeholders in the text of the selected controls",
+			},
+			"scope": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Applicability categories in scope; assessment requirements that apply to none of them are removed, and so are controls left without requirements",
+			},
+			"annotations": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": map[string]interface{}{"type": "string"},
+				"description":          "Scope notes or rationale per selected control id, recorded in the tailoring record",
+			},
+			"id": map[string]interface{}{
+				"type":        "string",
+				"description": "metadata.id of the tailored catalog (default: <baseline id>-tailored)",
+			},
+			"title": map[string]interface{}{
+				"type":        "string",
+				"description": "Title of the tailored catalog (default: that of the baseline)",
+			},
+		},
+	},
+}
+
+// InputTailorCatalog is the input for the TailorCatalog tool.
+type InputTailorCatalog struct {
+	ArtifactContent string            `json:"artifact_content,omitempty"`
+	ArtifactURI     string            `json:"artifact_uri,omitempty"`
+	IncludeControls []string          `json:"include_controls,omitempty"`
+	IncludeFamilies []string          `json:"include_families,omitempty"`
+	ExcludeControls []string          `json:"exclude_controls,omitempty"`
+	Parameters      map[string]string `json:"parameters,omitempty"`
+	Scope           []string          `json:"scope,omitempty"`
+	Annotations     map[string]string `json:"annotations,omitempty"`
+	ID              string            `json:"id,omitempty"`
+	Title           string            `json:"title,omitempty"`
+}
+
+// TailoredExclusion is a baseline control left out of a tailored catalog.
+type TailoredExclusion struct {
+	Control string `json:"control"`
+	Reason  string `json:"reason"`
+}
+
+// TailoredParameter is a parameter value set in a tailored catalog.
+type TailoredParameter struct {
+	Name     string   `json:"name"`
+	Value    string   `json:"value"`
+	Controls []string `json:"controls"`
+}
+
+// TailoringRecord documents how a catalog was tailored from its baseline.
+type TailoringRecord struct {
+	Baseline        string              `json:"baseline" yaml:"baseline"`
+	BaselineVersion string              `json:"baseline_version,omitempty" yaml:"baseline-version,omitempty"`
+	Catalog         string              `json:"catalog" yaml:"catalog"`
+	Tailored        string              `json:"tailored" yaml:"tailored"`
+	Included        []string            `json:"included" yaml:"included"`
+	Excluded        []TailoredExclusion `json:"excluded" yaml:"excluded"`
+	Parameters      []TailoredParameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	// Unresolved lists placeholders left in the selected controls without a value.
+	Unresolved []string `json:"unresolved,omitempty" yaml:"unresolved,omitempty"`
+	Scope      []string `json:"scope,omitempty" yaml:"scope,omitempty"`
+	// RemovedRequirements are the assessment requirements out of scope.
+	RemovedRequirements []string          `json:"removed_requirements,omitempty" yaml:"removed-requirements,omitempty"`
+	Annotations         map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+// OutputTailorCatalog is the output for the TailorCatalog tool.
+type OutputTailorCatalog struct {
+	TailoredContent string          `json:"tailored_content"`
+	Record          TailoringRecord `json:"record"`
+	// RecordContent is the record as YAML, to commit next to the tailored catalog.
+	RecordContent string   `json:"record_content"`
+	Valid         bool     `json:"valid"`
+	Errors        []string `json:"errors,omitempty"`
+	Message       string   `json:"message"`
+}
+
+// TailorCatalog applies a tailoring spec to a baseline catalog.
+func TailorCatalog(ctx context.Context, _ *mcp.CallToolRequest, input InputTailorCatalog) (*mcp.CallToolResult, OutputTailorCatalog, error) {
+	content, err := artifactInputContent(ctx, input.ArtifactContent, input.ArtifactURI)
+	if err != nil {
+		return nil, OutputTailorCatalog{}, err
+	}
+	parsed, err := parseArtifact(ctx, content)
+	if err != nil {
+		return nil, OutputTailorCatalog{}, err
+	}
+	if artifactKind(parsed) != "ControlCatalog" {
+		return nil, OutputTailorCatalog{}, fmt.Errorf("tailoring requires a ControlCatalog")
+	}
+	var doc yaml.MapSlice
+	if err := yaml.UnmarshalWithOptions([]byte(content), &doc, yaml.UseOrderedMap()); err != nil {
+		return nil, OutputTailorCatalog{}, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	metadata, _ := parsed["metadata"].(map[string]interface{})
+	record := TailoringRecord{
+		Baseline:        stringField(metadata, "id"),
+		BaselineVersion: stringField(metadata, "version"),
+		Catalog:         input.ID,
+		Tailored:        time.Now().UTC().Format(time.RFC3339),
+		Included:        []string{},
+		Excluded:        []TailoredExclusion{},
+		Scope:           input.Scope,
+		Annotations:     input.Annotations,
+	}
+	if record.Catalog == "" {
+		record.Catalog = record.Baseline + "-tailored"
+	}
+
+	controls := mapValueList(doc, "controls")
+	if err := checkTailoringIDs(controls, mapValueList(doc, "families"), input); err != nil {
+		return nil, OutputTailorCatalog{}, err
+	}
+
+	kept := []interface{}{}
+	usedFamilies := map[string]bool{}
+	parameterControls := map[string][]string{}
+	unresolved := map[string]bool{}
+	for _, c := range controls {
+		control, ok := c.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+		id := orderedID(control)
+		family := fmt.Sprint(mapValue(control, "family"))
+		if reason := tailoringExclusion(id, family, input); reason != "" {
+			record.Excluded = append(record.Excluded, TailoredExclusion{Control: id, Reason: reason})
+			continue
+		}
+
+		if len(input.Scope) > 0 {
+			var removed []string
+			control, removed = scopeRequirements(control, input.Scope)
+			record.RemovedRequirements = append(record.RemovedRequirements, removed...)
+			if requirements, _ := mapValue(control, "assessment-requirements").([]interface{}); len(requirements) == 0 {
+				record.Excluded = append(record.Excluded, TailoredExclusion{Control: id, Reason: "no assessment requirement in scope"})
+				continue
+			}
+		}
+
+		used := map[string]bool{}
+		control = substituteParameters(control, input.Parameters, used, unresolved).(yaml.MapSlice)
+		for name := range used {
+			parameterControls[name] = append(parameterControls[name], id)
+		}
+		kept = append(kept, control)
+		usedFamilies[family] = true
+		record.Included = append(record.Included, id)
+	}
+	if len(kept) == 0 {
+		return nil, OutputTailorCatalog{}, fmt.Errorf("the tailoring spec leaves no controls in the catalog")
+	}
+	for id := range input.Annotations {
+		if !slices.Contains(record.Included, id) {
+			return nil, OutputTailorCatalog{}, fmt.Errorf("annotation for control %s, which is not in the tailored catalog", id)
+		}
+	}
+
+	names := make([]string, 0, len(input.Parameters))
+	for name := range input.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		record.Parameters = append(record.Parameters, TailoredParameter{Name: name, Value: input.Parameters[name], Controls: parameterControls[name]})
+	}
+	for name := range unresolved {
+		record.Unresolved = append(record.Unresolved, name)
+	}
+	sort.Strings(record.Unresolved)
+
+	doc = setMapValue(doc, "controls", kept)
+	if families := mapValueList(doc, "families"); families != nil {
+		keptFamilies := []interface{}{}
+		for _, f := range families {
+			if usedFamilies[orderedID(f)] {
+				keptFamilies = append(keptFamilies, f)
+			}
+		}
+		doc = setMapValue(doc, "families", keptFamilies)
+	}
+	if m, ok := mapValue(doc, "metadata").(yaml.MapSlice); ok {
+		doc = setMapValue(doc, "metadata", setMapValue(m, "id", record.Catalog))
+	}
+	if input.Title != "" {
+		doc = setMapValue(doc, "title", input.Title)
+	}
+
+	out, err := yaml.MarshalWithOptions(doc, yaml.Indent(2), yaml.IndentSequence(true), yaml.UseLiteralStyleIfMultiline(true))
+	if err != nil {
+		return nil, OutputTailorCatalog{}, fmt.Errorf("failed to encode tailored catalog: %w", err)
+	}
+	recordOut, err := yaml.MarshalWithOptions(record, yaml.Indent(2), yaml.IndentSequence(true))
+	if err != nil {
+		return nil, OutputTailorCatalog{}, fmt.Errorf("failed to encode tailoring record: %w", err)
+	}
+
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return nil, OutputTailorCatalog{}, err
+	}
+	validation, err := validateAgainstSchema(schema, "#ControlCatalog", string(out))
+	if err != nil {
+		return nil, OutputTailorCatalog{}, err
+	}
+
+	output := OutputTailorCatalog{
+		TailoredContent: string(out),
+		Record:          record,
+		RecordContent:   string(recordOut),
+		Valid:           validation.Valid,
+		Errors:          validation.Errors,
+		Message: fmt.Sprintf("Tailored %s into %s: %d control(s) kept, %d excluded; %s",
+			record.Baseline, record.Catalog, len(record.Included), len(record.Excluded), strings.ToLower(validation.Message)),
+	}
+	if len(record.Unresolved) > 0 {
+		output.Message += fmt.Sprintf("; %d parameter(s) have no value: %s", len(record.Unresolved), strings.Join(record.Unresolved, ", "))
+	}
+	return nil, output, nil
+}
+
+// checkTailoringIDs rejects a spec that names controls or families the
+// baseline does not have, since a typo would silently change the selection.
+func checkTailoringIDs(controls, families []interface{}, input InputTailorCatalog) error {
+	controlIDs := map[string]bool{}
+	familyIDs := map[string]bool{}
+	for _, c := range controls {
+		controlIDs[orderedID(c)] = true
+		if m, ok := c.(yaml.MapSlice); ok {
+			familyIDs[fmt.Sprint(mapValue(m, "family"))] = true
+		}
+	}
+	for _, f := range families {
+		familyIDs[orderedID(f)] = true
+	}
+
+	var unknown []string
+	for _, id := range append(slices.Clone(input.IncludeControls), input.ExcludeControls...) {
+		if !controlIDs[id] {
+			unknown = append(unknown, "control "+id)
+		}
+	}
+	for _, id := range input.IncludeFamilies {
+		if !familyIDs[id] {
+			unknown = append(unknown, "family "+id)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("the baseline has no %s", strings.Join(unknown, ", no "))
+	}
+	return nil
+}
+
+// tailoringExclusion returns why a control is left out, or an empty string
+// when it is selected. Exclusions take precedence over inclusions, as in OSCAL.
+func tailoringExclusion(id, family string, input InputTailorCatalog) string {
+	if slices.Contains(input.ExcludeControls, id) {
+		return "excluded by exclude_controls"
+	}
+	if len(input.IncludeControls) == 0 && len(input.IncludeFamilies) == 0 {
+		return ""
+	}
+	if slices.Contains(input.IncludeControls, id) || slices.Contains(input.IncludeFamilies, family) {
+		return ""
+	}
+	return "not selected by include_controls or include_families"
+}
+
+// scopeRequirements removes the assessment requirements of a control whose
+// applicability names none of the categories in scope. Requirements that
+// declare no applicability always apply.
+func scopeRequirements(control yaml.MapSlice, scope []string) (yaml.MapSlice, []string) {
+	requirements, _ := mapValue(control, "assessment-requirements").([]interface{})
+	kept := []interface{}{}
+	var removed []string
+	for _, r := range requirements {
+		m, _ := r.(yaml.MapSlice)
+		applicability, _ := mapValue(m, "applicability").([]interface{})
+		inScope := len(applicability) == 0
+		for _, a := range applicability {
+			if slices.Contains(scope, fmt.Sprint(a)) {
+				inScope = true
+				break
+			}
+		}
+		if inScope {
+			kept = append(kept, r)
+		} else {
+			removed = append(removed, orderedID(r))
+		}
+	}
+	control = append(yaml.MapSlice{}, control...)
+	return setMapValue(control, "assessment-requirements", kept), removed
+}
+
+// substituteParameters replaces the placeholders in every string under node
+// with their values, recording the parameters used and those without value.
+func substituteParameters(node interface{}, values map[string]string, used, unresolved map[string]bool) interface{} {
+	switch v := node.(type) {
+	case yaml.MapSlice:
+		out := make(yaml.MapSlice, len(v))
+		for i, item := range v {
+			out[i] = yaml.MapItem{Key: item.Key, Value: substituteParameters(item.Value, values, used, unresolved)}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, elem := range v {
+			out[i] = substituteParameters(elem, values, used, unresolved)
+		}
+		return out
+	case string:
+		return parameterPlaceholder.ReplaceAllStringFunc(v, func(match string) string {
+			name := parameterPlaceholder.FindStringSubmatch(match)[1]
+			value, ok := values[name]
+			if !ok {
+				unresolved[name] = true
+				return match
+			}
+			used[name] = true
+			return value
+		})
+	}
+	return node
+}
+
+// mapValueList returns the list value of key in an ordered mapping, or nil.
+func mapValueList(m yaml.MapSlice, key string) []interface{} {
+	list, _ := mapValue(m, key).([]interface{})
+	return list
+}
@@ -0,0 +1,150 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const baselineCatalog = `metadata:
+  id: BASE
+  description: Baseline controls
+  version: 1.2.0
+  author:
+    id: sec
+    name: Security
+    type: Human
+title: Baseline
+families:
+  - id: access
+    title: Access
+    description: Access.
+  - id: data
+    title: Data
+    description: Data.
+controls:
+  - id: AC.C01
+    family: access
+    title: Rotate credentials
+    objective: Credentials are rotated every {{ rotation_days }} days.
+    assessment-requirements:
+      - id: AC.C01.TR01
+        text: "Keys older than {{ insert: param, rotation_days }} days are revoked."
+        applicability: [prod]
+      - id: AC.C01.TR02
+        text: Development keys are rotated.
+        applicability: [dev]
+  - id: AC.C02
+    family: access
+    title: Review access
+    objective: Access is reviewed by {{ reviewer }}.
+    assessment-requirements:
+      - id: AC.C02.TR01
+        text: Reviews are recorded.
+        applicability: [dev]
+  - id: DA.C01
+    family: data
+    title: Encrypt data
+    objective: Data is encrypted.
+    assessment-requirements:
+      - id: DA.C01.TR01
+        text: Volumes are encrypted.
+        applicability: [prod, dev]
+`
+
+func TestTailorCatalog(t *testing.T) {
+	useTestSchema(t)
+	ctx := context.Background()
+
+	_, output, err := TailorCatalog(ctx, nil, InputTailorCatalog{
+		ArtifactContent: baselineCatalog,
+		IncludeFamilies: []string{"access"},
+		Parameters:      map[string]string{"rotation_days": "90"},
+		Scope:           []string{"prod"},
+		Annotations:     map[string]string{"AC.C01": "Applies to production service accounts only"},
+		ID:              "ORG-PROD",
+	})
+	require.NoError(t, err)
+	assert.True(t, output.Valid, "tailored catalog should be valid: %v", output.Errors)
+
+	record := output.Record
+	assert.Equal(t, "BASE", record.Baseline)
+	assert.Equal(t, "1.2.0", record.BaselineVersion)
+	assert.Equal(t, "ORG-PROD", record.Catalog)
+	assert.Equal(t, []string{"AC.C01"}, record.Included)
+	assert.Equal(t, []TailoredExclusion{
+		{Control: "AC.C02", Reason: "no assessment requirement in scope"},
+		{Control: "DA.C01", Reason: "not selected by include_controls or include_families"},
+	}, record.Excluded)
+	assert.Equal(t, []TailoredParameter{{Name: "rotation_days", Value: "90", Controls: []string{"AC.C01"}}}, record.Parameters)
+	assert.Equal(t, []string{"AC.C01.TR02", "AC.C02.TR01"}, record.RemovedRequirements)
+	assert.Empty(t, record.Unresolved, "placeholders of excluded controls should not count")
+
+	assert.Contains(t, output.TailoredContent, "id: ORG-PROD")
+	assert.Contains(t, output.TailoredContent, "Credentials are rotated every 90 days.")
+	assert.Contains(t, output.TailoredContent, "Keys older than 90 days are revoked.")
+	assert.NotContains(t, output.TailoredContent, "AC.C01.TR02")
+	assert.NotContains(t, output.TailoredContent, "id: data", "families without controls should be dropped")
+	assert.Contains(t, output.RecordContent, "baseline: BASE")
+	assert.Contains(t, output.RecordContent, "Applies to production service accounts only")
+}
+
+func TestTailorCatalogSpec(t *testing.T) {
+	useTestSchema(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		input          InputTailorCatalog
+		wantIncluded   []string
+		wantUnresolved []string
+		errContains    string
+	}{
+		{
+			name:           "everything by default",
+			input:          InputTailorCatalog{},
+			wantIncluded:   []string{"AC.C01", "AC.C02", "DA.C01"},
+			wantUnresolved: []string{"reviewer", "rotation_days"},
+		},
+		{
+			name:           "exclusions win over inclusions",
+			input:          InputTailorCatalog{IncludeControls: []string{"AC.C02", "DA.C01"}, ExcludeControls: []string{"DA.C01"}},
+			wantIncluded:   []string{"AC.C02"},
+			wantUnresolved: []string{"reviewer"},
+		},
+		{
+			name:        "unknown control",
+			input:       InputTailorCatalog{IncludeControls: []string{"AC.C09"}, IncludeFamilies: []string{"ops"}},
+			errContains: "the baseline has no control AC.C09, no family ops",
+		},
+		{
+			name:        "nothing left",
+			input:       InputTailorCatalog{ExcludeControls: []string{"AC.C01", "AC.C02", "DA.C01"}},
+			errContains: "leaves no controls",
+		},
+		{
+			name:        "annotation for excluded control",
+			input:       InputTailorCatalog{ExcludeControls: []string{"DA.C01"}, Annotations: map[string]string{"DA.C01": "n/a"}},
+			errContains: "not in the tailored catalog",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input.ArtifactContent = baselineCatalog
+			_, output, err := TailorCatalog(ctx, nil, tt.input)
+			if tt.errContains != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantIncluded, output.Record.Included)
+			assert.Equal(t, tt.wantUnresolved, output.Record.Unresolved)
+			assert.Equal(t, "BASE-tailored", output.Record.Catalog)
+		})
+	}
+}
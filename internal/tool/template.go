@@ -0,0 +1,252 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// DefaultTemplateIndexURL is the community index of vetted artifact templates.
+	DefaultTemplateIndexURL = "https://raw.githubusercontent.com/gemaraproj/gemara-templates/main/index.yaml"
+	templateIndexCacheTTL   = time.Hour
+)
+
+var (
+	templateIndexMu        sync.Mutex
+	templateIndexCache     *TemplateIndex
+	templateIndexCacheURL  string
+	templateIndexCacheTime time.Time
+)
+
+// TemplateIndex is the catalog of templates published by an index.
+type TemplateIndex struct {
+	Templates []TemplateEntry `json:"templates" yaml:"templates"`
+}
+
+// TemplateEntry describes a single artifact template in the index.
+type TemplateEntry struct {
+	ID          string   `json:"id" yaml:"id"`
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description" yaml:"description"`
+	Definition  string   `json:"definition" yaml:"definition"`
+	Tags        []string `json:"tags,omitempty" yaml:"tags"`
+	URL         string   `json:"url" yaml:"url"`
+}
+
+// MetadataListTemplates describes the ListTemplates tool.
+var MetadataListTemplates = &mcp.Tool{
+	Name:        "list_templates",
+	Description: "List vetted Gemara artifact templates (e.g., SaaS policy, OSS project catalog) from the configured template index.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": withPagination(map[string]interface{}{
+			"definition": map[string]interface{}{
+				"type":        "string",
+				"description": "Only list templates for this CUE definition (e.g., '#Policy')",
+			},
+			"tag": map[string]interface{}{
+				"type":        "string",
+				"description": "Only list templates with this tag",
+			},
+			"refresh": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Force refresh of the template index cache (default: false)",
+			},
+		}, "templates"),
+	},
+}
+
+// InputListTemplates is the input for the ListTemplates tool.
+type InputListTemplates struct {
+	PageInput
+	Definition string `json:"definition,omitempty"`
+	Tag        string `json:"tag,omitempty"`
+	Refresh    bool   `json:"refresh,omitempty"`
+}
+
+// OutputListTemplates is the output for the ListTemplates tool.
+type OutputListTemplates struct {
+	Templates []TemplateEntry `json:"templates"`
+	Source    string          `json:"source"`
+	Cached    bool            `json:"cached"`
+	// Page describes which templates were returned.
+	Page *PageInfo `json:"page,omitempty"`
+}
+
+// MetadataFetchTemplate describes the FetchTemplate tool.
+var MetadataFetchTemplate = &mcp.Tool{
+	Name:        "fetch_template",
+	Description: "Fetch the content of a Gemara artifact template from the configured template index to use as a starting point.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"id"},
+		"properties": map[string]interface{}{
+			"id": map[string]interface{}{
+				"type":        "string",
+				"description": "ID of the template as returned by list_templates",
+			},
+		},
+	},
+}
+
+// InputFetchTemplate is the input for the FetchTemplate tool.
+type InputFetchTemplate struct {
+	ID string `json:"id"`
+}
+
+// OutputFetchTemplate is the output for the FetchTemplate tool.
+type OutputFetchTemplate struct {
+	Template TemplateEntry `json:"template"`
+	Content  string        `json:"content"`
+}
+
+// ListTemplates lists the templates in the configured index.
+func ListTemplates(ctx context.Context, _ *mcp.CallToolRequest, input InputListTemplates) (*mcp.CallToolResult, OutputListTemplates, error) {
+	return listTemplatesWithURL(ctx, input, serverConfig(ctx).TemplateIndexURL)
+}
+
+// FetchTemplate retrieves a single template from the configured index.
+func FetchTemplate(ctx context.Context, _ *mcp.CallToolRequest, input InputFetchTemplate) (*mcp.CallToolResult, OutputFetchTemplate, error) {
+	return fetchTemplateWithURL(ctx, input, serverConfig(ctx).TemplateIndexURL)
+}
+
+// listTemplatesWithURL lists templates from the index at the given URL.
+func listTemplatesWithURL(ctx context.Context, input InputListTemplates, indexURL string) (*mcp.CallToolResult, OutputListTemplates, error) {
+	index, cached, err := loadTemplateIndex(ctx, indexURL, input.Refresh)
+	if err != nil {
+		return nil, OutputListTemplates{}, err
+	}
+
+	definition := ""
+	if input.Definition != "" {
+		definition = normalizeDefinition(input.Definition)
+	}
+
+	templates := []TemplateEntry{}
+	for _, entry := range index.Templates {
+		if definition != "" && normalizeDefinition(entry.Definition) != definition {
+			continue
+		}
+		if input.Tag != "" && !containsFold(entry.Tags, input.Tag) {
+			continue
+		}
+		templates = append(templates, entry)
+	}
+
+	page, info, err := paginate(templates, input.PageInput)
+	if err != nil {
+		return nil, OutputListTemplates{}, err
+	}
+	output := OutputListTemplates{
+		Templates: page,
+		Source:    indexURL,
+		Cached:    cached,
+		Page:      &info,
+	}
+	return nil, output, nil
+}
+
+// fetchTemplateWithURL fetches a template listed in the index at the given URL.
+func fetchTemplateWithURL(ctx context.Context, input InputFetchTemplate, indexURL string) (*mcp.CallToolResult, OutputFetchTemplate, error) {
+	if input.ID == "" {
+		return nil, OutputFetchTemplate{}, fmt.Errorf("id is required")
+	}
+
+	index, _, err := loadTemplateIndex(ctx, indexURL, false)
+	if err != nil {
+		return nil, OutputFetchTemplate{}, err
+	}
+
+	for _, entry := range index.Templates {
+		if entry.ID != input.ID {
+			continue
+		}
+
+		templateURL, err := resolveTemplateURL(indexURL, entry.URL)
+		if err != nil {
+			return nil, OutputFetchTemplate{}, err
+		}
+
+		content, err := fetchURL(ctx, templateURL)
+		if err != nil {
+			return nil, OutputFetchTemplate{}, fmt.Errorf("failed to fetch template %s: %w", entry.ID, err)
+		}
+
+		output := OutputFetchTemplate{
+			Template: entry,
+			Content:  string(content),
+		}
+		return nil, output, nil
+	}
+
+	return nil, OutputFetchTemplate{}, fmt.Errorf("template %s not found in index %s", input.ID, indexURL)
+}
+
+// loadTemplateIndex returns the index at the given URL, using the cache when possible.
+func loadTemplateIndex(ctx context.Context, indexURL string, refresh bool) (*TemplateIndex, bool, error) {
+	templateIndexMu.Lock()
+	defer templateIndexMu.Unlock()
+
+	if !refresh && templateIndexCache != nil && templateIndexCacheURL == indexURL &&
+		time.Since(templateIndexCacheTime) < templateIndexCacheTTL {
+		return templateIndexCache, true, nil
+	}
+
+	if !refresh {
+		emitCacheMiss(ctx, "template index", indexURL)
+	}
+	body, err := fetchURL(ctx, indexURL)
+	if err != nil {
+		// Keep answering from the previous index while upstream is down
+		if templateIndexCache != nil && templateIndexCacheURL == indexURL {
+			emitStaleCache(ctx, "template index", indexURL, err)
+			return templateIndexCache, true, nil
+		}
+		return nil, false, fmt.Errorf("failed to fetch template index: %w", err)
+	}
+
+	var index TemplateIndex
+	if err := yaml.Unmarshal(body, &index); err != nil {
+		return nil, false, fmt.Errorf("failed to parse template index: %w", err)
+	}
+
+	templateIndexCache = &index
+	templateIndexCacheURL = indexURL
+	templateIndexCacheTime = time.Now()
+	return &index, false, nil
+}
+
+// resolveTemplateURL resolves a template location relative to the index URL.
+func resolveTemplateURL(indexURL, templateURL string) (string, error) {
+	if templateURL == "" {
+		return "", fmt.Errorf("template has no url")
+	}
+	base, err := url.Parse(indexURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid index URL %q: %w", indexURL, err)
+	}
+	ref, err := url.Parse(templateURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid template URL %q: %w", templateURL, err)
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// containsFold reports whether list contains s, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
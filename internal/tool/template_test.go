@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTemplateIndex = `templates:
+  - id: saas-policy
+    name: SaaS Policy
+    description: Starting point for a SaaS organization policy
+    definition: "#Policy"
+    tags: [saas]
+    url: templates/saas-policy.yaml
+  - id: oss-catalog
+    name: OSS Project Catalog
+    description: Control catalog for an open source project
+    definition: ControlCatalog
+    tags: [oss, project]
+    url: templates/oss-catalog.yaml
+  - id: broken
+    name: Broken
+    definition: "#Policy"
+    url: templates/missing.yaml`
+
+func newTemplateServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/index.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testTemplateIndex))
+	})
+	mux.HandleFunc("/templates/saas-policy.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("title: SaaS Policy\n"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestListTemplates(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   InputListTemplates
+		wantIDs []string
+	}{
+		{
+			name:    "all templates",
+			input:   InputListTemplates{},
+			wantIDs: []string{"saas-policy", "oss-catalog", "broken"},
+		},
+		{
+			name:    "filter by definition without hash",
+			input:   InputListTemplates{Definition: "Policy"},
+			wantIDs: []string{"saas-policy", "broken"},
+		},
+		{
+			name:    "filter by definition normalizes index entries",
+			input:   InputListTemplates{Definition: "#ControlCatalog"},
+			wantIDs: []string{"oss-catalog"},
+		},
+		{
+			name:    "filter by tag",
+			input:   InputListTemplates{Tag: "OSS"},
+			wantIDs: []string{"oss-catalog"},
+		},
+		{
+			name:    "no matches",
+			input:   InputListTemplates{Tag: "none"},
+			wantIDs: []string{},
+		},
+		{
+			name:    "page of templates",
+			input:   InputListTemplates{PageInput: PageInput{Offset: 1, Limit: 1}},
+			wantIDs: []string{"oss-catalog"},
+		},
+	}
+
+	server := newTemplateServer(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templateIndexCache = nil
+
+			_, output, err := listTemplatesWithURL(context.Background(), tt.input, server.URL+"/index.yaml")
+			require.NoError(t, err, "should not return error")
+
+			ids := []string{}
+			for _, entry := range output.Templates {
+				ids = append(ids, entry.ID)
+			}
+			assert.Equal(t, tt.wantIDs, ids, "template IDs should match")
+		})
+	}
+}
+
+func TestListTemplatesCache(t *testing.T) {
+	templateIndexCache = nil
+	server := newTemplateServer(t)
+	indexURL := server.URL + "/index.yaml"
+
+	_, first, err := listTemplatesWithURL(context.Background(), InputListTemplates{}, indexURL)
+	require.NoError(t, err, "first call should not error")
+	assert.False(t, first.Cached, "first call should not be cached")
+
+	_, second, err := listTemplatesWithURL(context.Background(), InputListTemplates{}, indexURL)
+	require.NoError(t, err, "second call should not error")
+	assert.True(t, second.Cached, "second call should be cached")
+
+	_, third, err := listTemplatesWithURL(context.Background(), InputListTemplates{Refresh: true}, indexURL)
+	require.NoError(t, err, "refresh call should not error")
+	assert.False(t, third.Cached, "refresh should bypass the cache")
+}
+
+func TestFetchTemplate(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       InputFetchTemplate
+		wantErr     bool
+		errContains string
+		wantContent string
+	}{
+		{
+			name:        "missing id",
+			input:       InputFetchTemplate{},
+			wantErr:     true,
+			errContains: "id is required",
+		},
+		{
+			name:        "unknown template",
+			input:       InputFetchTemplate{ID: "unknown"},
+			wantErr:     true,
+			errContains: "not found in index",
+		},
+		{
+			name:        "template content unavailable",
+			input:       InputFetchTemplate{ID: "broken"},
+			wantErr:     true,
+			errContains: "failed to fetch template broken",
+		},
+		{
+			name:        "relative template URL",
+			input:       InputFetchTemplate{ID: "saas-policy"},
+			wantContent: "title: SaaS Policy\n",
+		},
+	}
+
+	server := newTemplateServer(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templateIndexCache = nil
+
+			_, output, err := fetchTemplateWithURL(context.Background(), tt.input, server.URL+"/index.yaml")
+			if tt.wantErr {
+				require.Error(t, err, "should return error")
+				assert.Contains(t, err.Error(), tt.errContains, "error should contain expected message")
+				return
+			}
+
+			require.NoError(t, err, "should not return error")
+			assert.Equal(t, tt.wantContent, output.Content, "content should match")
+			assert.Equal(t, tt.input.ID, output.Template.ID, "template entry should be returned")
+		})
+	}
+}
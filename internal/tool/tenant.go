@@ -0,0 +1,266 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MCPPath is where TenantHandler is served.
+const MCPPath = "/mcp"
+
+var tenantIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Tenant is a team served by a shared deployment. Each tenant has its own
+// workspace root, caches of the artifacts derived from it, and rate limit.
+type Tenant struct {
+	ID            string `yaml:"id"`
+	WorkspaceRoot string `yaml:"workspace-root"`
+	// Tokens are the bearer tokens that identify the tenant.
+	Tokens []string `yaml:"tokens,omitempty"`
+	// RateLimit is how many HTTP requests per second the tenant may make;
+	// 0 is unlimited.
+	RateLimit float64 `yaml:"rate-limit,omitempty"`
+	// Burst is how many requests may be made at once before the rate limit
+	// applies (default: the rate limit, at least 1).
+	Burst int `yaml:"burst,omitempty"`
+
+	// server receives the events of the requests made for the tenant.
+	server     *mcp.Server
+	limiter    *rateLimiter
+	index      *fullTextIndex
+	references *referenceCache
+	posture    *postureCache
+
+	searchMu sync.Mutex
+	search   *ControlSearchIndex
+}
+
+// TenantConfig is the file format read by LoadTenants.
+type TenantConfig struct {
+	Tenants []*Tenant `yaml:"tenants"`
+}
+
+// LoadTenants reads tenant declarations from a YAML file. Relative workspace
+// roots are resolved against the directory of the file.
+func LoadTenants(path string) ([]*Tenant, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tenants config: %w", err)
+	}
+	var config TenantConfig
+	if err := yaml.Unmarshal(raw, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse tenants config: %w", err)
+	}
+	if len(config.Tenants) == 0 {
+		return nil, fmt.Errorf("tenants config %s declares no tenants", path)
+	}
+
+	ids := map[string]bool{}
+	roots := map[string]string{}
+	tokens := map[string]string{}
+	for _, t := range config.Tenants {
+		switch {
+		case t == nil || t.ID == "":
+			return nil, fmt.Errorf("tenant is missing an id")
+		case !tenantIDPattern.MatchString(t.ID):
+			return nil, fmt.Errorf("tenant id %q may only contain letters, digits, '.', '_', and '-'", t.ID)
+		case ids[t.ID]:
+			return nil, fmt.Errorf("tenant %s is declared twice", t.ID)
+		case t.WorkspaceRoot == "":
+			return nil, fmt.Errorf("tenant %s has no workspace-root", t.ID)
+		case t.RateLimit < 0 || t.Burst < 0:
+			return nil, fmt.Errorf("tenant %s: rate-limit and burst must not be negative", t.ID)
+		}
+		ids[t.ID] = true
+
+		root := t.WorkspaceRoot
+		if !filepath.IsAbs(root) {
+			root = filepath.Join(filepath.Dir(path), root)
+		}
+		root, err := filepath.Abs(root)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %s: invalid workspace root: %w", t.ID, err)
+		}
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("tenant %s: workspace root %s is not a directory", t.ID, root)
+		}
+		if other, ok := roots[root]; ok {
+			return nil, fmt.Errorf("tenant %s: workspace root %s is also the workspace of tenant %s", t.ID, root, other)
+		}
+		roots[root] = t.ID
+		t.WorkspaceRoot = root
+
+		for _, token := range t.Tokens {
+			if token == "" {
+				return nil, fmt.Errorf("tenant %s has an empty token", t.ID)
+			}
+			if other, ok := tokens[token]; ok {
+				return nil, fmt.Errorf("tenant %s shares a token with tenant %s", t.ID, other)
+			}
+			tokens[token] = t.ID
+		}
+
+		t.limiter = newRateLimiter(t.RateLimit, t.Burst)
+		t.index = newFullTextIndex()
+		t.references = newReferenceCache()
+		t.posture = &postureCache{}
+	}
+	return config.Tenants, nil
+}
+
+type tenantKey struct{}
+
+// withTenant returns a context whose handlers serve the given tenant.
+func withTenant(ctx context.Context, t *Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, t)
+}
+
+// requestTenant returns the tenant of ctx, or nil when the server is not
+// shared.
+func requestTenant(ctx context.Context) *Tenant {
+	t, _ := ctx.Value(tenantKey{}).(*Tenant)
+	return t
+}
+
+// tenantMiddleware makes t the tenant of every request to a server.
+func tenantMiddleware(t *Tenant) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			return next(withTenant(ctx, t), method, req)
+		}
+	}
+}
+
+// workspaceRoot returns the workspace root of the tenant of ctx, or that of
+// the configuration of ctx.
+func workspaceRoot(ctx context.Context) string {
+	if t := requestTenant(ctx); t != nil {
+		return t.WorkspaceRoot
+	}
+	return serverConfig(ctx).WorkspaceRoot
+}
+
+// workspaceDirectory returns the directory a tool should read: dir, or the
+// workspace root when dir is empty. Tenants may only read directories within
+// their own workspace.
+func workspaceDirectory(ctx context.Context, dir string) (string, error) {
+	root := workspaceRoot(ctx)
+	if dir == "" {
+		return root, nil
+	}
+	if requestTenant(ctx) != nil {
+		if _, err := resolveWithin(root, dir); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// TenantHandler serves MCP over streamable HTTP to several tenants. Every
+// tenant has its own server, created once by newServer, and its own sessions.
+// A request is identified by a bearer token of a tenant or, when header is
+// set, by the tenant ID in that header, which an authenticating proxy in front
+// of the server must set. Requests over a tenant's rate limit are rejected
+// with 429 Too Many Requests.
+func TenantHandler(tenants []*Tenant, header string, newServer func(*Tenant) *mcp.Server) http.Handler {
+	handlers := make(map[*Tenant]http.Handler, len(tenants))
+	for _, t := range tenants {
+		server := newServer(t)
+		// Each tenant has its own handler so sessions cannot be resumed across tenants
+		handlers[t] = mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t := identifyTenant(r, tenants, header)
+		if t == nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unknown tenant", http.StatusUnauthorized)
+			return
+		}
+		if ok, wait := t.limiter.allow(time.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, fmt.Sprintf("tenant %s exceeded its rate limit", t.ID), http.StatusTooManyRequests)
+			return
+		}
+		handlers[t].ServeHTTP(w, r)
+	})
+}
+
+// identifyTenant returns the tenant a request is made for, or nil. A bearer
+// token that matches no tenant is rejected rather than falling back to the
+// header.
+func identifyTenant(r *http.Request, tenants []*Tenant, header string) *Tenant {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		for _, t := range tenants {
+			for _, want := range t.Tokens {
+				if subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1 {
+					return t
+				}
+			}
+		}
+		return nil
+	}
+	if header == "" {
+		return nil
+	}
+	id := r.Header.Get(header)
+	for _, t := range tenants {
+		if id != "" && t.ID == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// rateLimiter is a token bucket. A nil rateLimiter allows every request.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	b := float64(burst)
+	if b == 0 {
+		b = math.Max(rate, 1)
+	}
+	return &rateLimiter{rate: rate, burst: b, tokens: b}
+}
+
+// allow takes a token at now, or reports how long until one is available.
+func (l *rateLimiter) allow(now time.Time) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.last.IsZero() {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	if l.tokens < 1 {
+		return false, time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	}
+	l.tokens--
+	return true, 0
+}
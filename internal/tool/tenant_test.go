@@ -0,0 +1,222 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadTenants(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "payments"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "platform"), 0o755))
+
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			config: `tenants:
+  - id: payments
+    workspace-root: payments
+    tokens: [payments-token]
+    rate-limit: 2
+  - id: platform
+    workspace-root: ` + filepath.Join(dir, "platform") + `
+`,
+		},
+		{name: "no tenants", config: "tenants: []\n", wantErr: "declares no tenants"},
+		{name: "missing id", config: "tenants:\n  - workspace-root: payments\n", wantErr: "missing an id"},
+		{name: "invalid id", config: "tenants:\n  - id: ../payments\n    workspace-root: payments\n", wantErr: "may only contain"},
+		{name: "missing root", config: "tenants:\n  - id: payments\n", wantErr: "has no workspace-root"},
+		{name: "root is not a directory", config: "tenants:\n  - id: payments\n    workspace-root: missing\n", wantErr: "is not a directory"},
+		{name: "negative rate limit", config: "tenants:\n  - id: payments\n    workspace-root: payments\n    rate-limit: -1\n", wantErr: "must not be negative"},
+		{
+			name:    "duplicate id",
+			config:  "tenants:\n  - id: payments\n    workspace-root: payments\n  - id: payments\n    workspace-root: platform\n",
+			wantErr: "declared twice",
+		},
+		{
+			name:    "shared root",
+			config:  "tenants:\n  - id: payments\n    workspace-root: payments\n  - id: platform\n    workspace-root: ./payments\n",
+			wantErr: "also the workspace of tenant payments",
+		},
+		{
+			name:    "shared token",
+			config:  "tenants:\n  - id: payments\n    workspace-root: payments\n    tokens: [t]\n  - id: platform\n    workspace-root: platform\n    tokens: [t]\n",
+			wantErr: "shares a token with tenant payments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeTestFile(t, dir, "tenants.yaml", tt.config)
+			tenants, err := LoadTenants(filepath.Join(dir, "tenants.yaml"))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, tenants, 2)
+			assert.Equal(t, filepath.Join(dir, "payments"), tenants[0].WorkspaceRoot, "relative roots resolve against the config file")
+			assert.NotNil(t, tenants[0].limiter)
+			assert.Nil(t, tenants[1].limiter, "tenants without a rate limit are unlimited")
+		})
+	}
+}
+
+// tenantAuth sets the tenant headers on every request of a client.
+type tenantAuth map[string]string
+
+func (h tenantAuth) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	for k, v := range h {
+		r.Header.Set(k, v)
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestTenantHandler(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "payments/payments-baseline.yaml", baselineCatalog)
+	writeTestFile(t, dir, "platform/platform-baseline.yaml", baselineCatalog)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "ci"), 0o755))
+	writeTestFile(t, dir, "tenants.yaml", `tenants:
+  - id: payments
+    workspace-root: payments
+    tokens: [payments-token]
+  - id: platform
+    workspace-root: platform
+  - id: ci
+    workspace-root: ci
+    tokens: [ci-token]
+    rate-limit: 0.01
+    burst: 1
+`)
+	tenants, err := LoadTenants(filepath.Join(dir, "tenants.yaml"))
+	require.NoError(t, err)
+
+	handler := TenantHandler(tenants, "X-Gemara-Tenant", func(tenant *Tenant) *mcp.Server {
+		server := mcp.NewServer(&mcp.Implementation{Name: "test-server"}, nil)
+		AdvisoryMode{Tenant: tenant}.Register(server)
+		return server
+	})
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(httpServer.Close)
+
+	search := func(t *testing.T, headers tenantAuth) []string {
+		t.Helper()
+		transport := &mcp.StreamableClientTransport{Endpoint: httpServer.URL, HTTPClient: &http.Client{Transport: headers}, MaxRetries: -1}
+		session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(context.Background(), transport, nil)
+		require.NoError(t, err, "client should connect")
+		defer session.Close()
+		result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "search_artifacts", Arguments: map[string]interface{}{"query": "baseline"}})
+		require.NoError(t, err)
+		require.False(t, result.IsError, "search should succeed")
+		raw, err := json.Marshal(result.StructuredContent)
+		require.NoError(t, err)
+		var output OutputSearchArtifacts
+		require.NoError(t, json.Unmarshal(raw, &output))
+		var paths []string
+		for _, r := range output.Results {
+			paths = append(paths, r.Path)
+		}
+		return paths
+	}
+
+	// Each tenant only searches its own workspace
+	assert.Equal(t, []string{"payments-baseline.yaml"}, search(t, tenantAuth{"Authorization": "Bearer payments-token"}))
+	assert.Equal(t, []string{"platform-baseline.yaml"}, search(t, tenantAuth{"X-Gemara-Tenant": "platform"}))
+
+	post := func(headers map[string]string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, httpServer.URL, strings.NewReader("{}"))
+		require.NoError(t, err)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp
+	}
+	assert.Equal(t, http.StatusUnauthorized, post(nil).StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, post(map[string]string{"X-Gemara-Tenant": "billing"}).StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, post(map[string]string{"Authorization": "Bearer wrong", "X-Gemara-Tenant": "payments"}).StatusCode,
+		"an unknown token should not fall back to the header")
+
+	ci := map[string]string{"Authorization": "Bearer ci-token"}
+	assert.NotEqual(t, http.StatusTooManyRequests, post(ci).StatusCode, "the burst should be allowed")
+	resp := post(ci)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+}
+
+func TestTenantWorkspaceIsolation(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "payments/catalog.yaml", baselineCatalog)
+	writeTestFile(t, dir, "platform/catalog.yaml", baselineCatalog)
+	other := filepath.Join(dir, "platform", "catalog.yaml")
+	tenant := &Tenant{ID: "payments", WorkspaceRoot: filepath.Join(dir, "payments"), references: newReferenceCache()}
+	serverCtx := withTestConfig(func(c *Config) { c.WorkspaceRoot = dir })
+	ctx := withTenant(serverCtx, tenant)
+
+	_, err := readArtifactURI(ctx, fileURL(other))
+	require.Error(t, err, "tenants should not read other workspaces")
+	assert.Contains(t, err.Error(), "outside the workspace root")
+	_, err = readArtifactURI(serverCtx, fileURL(other))
+	assert.NoError(t, err, "the server workspace contains both tenants")
+
+	got, err := workspaceDirectory(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, tenant.WorkspaceRoot, got)
+	_, err = workspaceDirectory(ctx, filepath.Join(dir, "platform"))
+	assert.Error(t, err)
+	_, _, err = CheckReferenceIntegrity(ctx, nil, InputCheckReferenceIntegrity{Directory: dir})
+	assert.Error(t, err, "tenants should not name directories outside their workspace")
+
+	uri, err := referenceURI(ctx, "", "catalog.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, fileURL(filepath.Join(tenant.WorkspaceRoot, "catalog.yaml")), uri)
+	_, err = fetchReference(ctx, uri)
+	require.NoError(t, err)
+	assert.Len(t, tenant.references.entries, 1, "the tenant should cache its own references")
+	assert.NotContains(t, resolveCache.entries, uri)
+}
+
+func TestRateLimiter(t *testing.T) {
+	assert.Nil(t, newRateLimiter(0, 5), "a zero rate is unlimited")
+	var unlimited *rateLimiter
+	ok, _ := unlimited.allow(time.Now())
+	assert.True(t, ok)
+
+	limiter := newRateLimiter(2, 2)
+	now := time.Unix(0, 0)
+	for i := 0; i < 2; i++ {
+		ok, _ := limiter.allow(now)
+		assert.True(t, ok, "the burst should be allowed")
+	}
+	ok, wait := limiter.allow(now)
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, wait)
+
+	ok, _ = limiter.allow(now.Add(500 * time.Millisecond))
+	assert.True(t, ok, "a token should be refilled")
+	ok, _ = limiter.allow(now.Add(time.Hour))
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, limiter.tokens, "refills should not exceed the burst")
+}
@@ -0,0 +1,138 @@
+// Minimal stand-in for the Gemara CUE module used by offline tests.
+package gemara
+
+#Metadata: {
+	id:          string
+	description: string
+	version?:    string
+	author:      #Actor
+	"applicability-categories"?: [...#Category]
+}
+
+#Actor: {
+	id:   string
+	name: string
+	type: "Human" | "Software"
+}
+
+#Category: {
+	id:          string
+	title:       string
+	description: string
+}
+
+#Mapping: {
+	"reference-id": string
+	entries: [...#MappingEntry]
+}
+
+#MappingEntry: {
+	"reference-id": string
+	strength?:      int & >=0 & <=10
+	remarks?:       string
+}
+
+#EntryMapping: {
+	"reference-id": string
+	"entry-id":     string
+}
+
+#ControlCatalog: {
+	metadata: #Metadata
+	title:    string
+	families?: [...#Category]
+	controls: [...#Control]
+}
+
+#Control: {
+	id:        string
+	family:    string
+	title:     string
+	objective: string
+	"threat-mappings"?: [...#Mapping]
+	"guideline-mappings"?: [...#Mapping]
+	"assessment-requirements": [...#AssessmentRequirement]
+}
+
+#AssessmentRequirement: {
+	id:   string
+	text: string
+	applicability: [...string]
+	recommendation?: string
+}
+
+#Policy: {
+	metadata: #Metadata
+	title:    string
+	imports?: catalogs?: [...{"reference-id": string}]
+	adherence?: "assessment-plans"?: [...#AssessmentPlan]
+}
+
+#AssessmentPlan: {
+	id:               string
+	"requirement-id": string
+	frequency:        string
+	"evaluation-methods": [...{type: "automated" | "manual"}]
+}
+
+#Result: "Not Run" | "Passed" | "Failed" | "Needs Review" | "Not Applicable" | "Unknown"
+
+#EvaluationLog: {
+	metadata: #Metadata
+	evaluations: [...#ControlEvaluation]
+}
+
+#ControlEvaluation: {
+	name:    string
+	result:  #Result
+	message?: string
+	control: #EntryMapping
+	"assessment-logs": [...#AssessmentLog]
+}
+
+#AssessmentLog: {
+	requirement:  #EntryMapping
+	plan?:        #EntryMapping
+	description:  string
+	result:       #Result
+	message?:     string
+}
+
+#EvaluationPlan: {
+	metadata: #Metadata
+	title:    string
+	plans: [...#ControlPlan]
+}
+
+#ControlPlan: {
+	control: #EntryMapping
+	assessments: [...#RequirementAssessment]
+}
+
+#RequirementAssessment: {
+	requirement: #EntryMapping
+	frequency:   string
+	procedures: [...#AssessmentProcedure]
+}
+
+#AssessmentProcedure: {
+	id:          string
+	name:        string
+	description: string
+	"evaluation-method"?: "automated" | "manual"
+}
+
+#GuidanceDocument: {
+	metadata: #Metadata
+	title:    string
+	categories: [...{
+		id:          string
+		title:       string
+		description: string
+		guidelines: [...{
+			id:        string
+			title:     string
+			objective: string
+		}]
+	}]
+}
@@ -0,0 +1,225 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MetadataAnalyzeThreatCoverage describes the AnalyzeThreatCoverage tool.
+var MetadataAnalyzeThreatCoverage = &mcp.Tool{
+	Name: "analyze_threat_coverage",
+	Description: "Cross-reference the threats declared in a catalog (and any threat catalogs it maps to) against the " +
+		"threat-mappings of its controls. Reports threats no control mitigates, controls that mitigate no threat, and " +
+		"mapping entries that reference undeclared threats.",
+	InputSchema: map[string]interface{}{
+		"type":     "object",
+		"required": []string{"catalog_content"},
+		"properties": map[string]interface{}{
+			"catalog_content": map[string]interface{}{
+				"type":        "string",
+				"description": "YAML content of the ControlCatalog; threats it declares under 'threats' are included",
+			},
+			"threat_catalogs": map[string]interface{}{
+				"type":        "array",
+				"description": "Threat catalogs the controls map to, matched by their metadata id",
+				"items":       artifactInputSchema["items"],
+			},
+		},
+	},
+}
+
+// InputAnalyzeThreatCoverage is the input for the AnalyzeThreatCoverage tool.
+type InputAnalyzeThreatCoverage struct {
+	CatalogContent string          `json:"catalog_content"`
+	ThreatCatalogs []ArtifactInput `json:"threat_catalogs,omitempty"`
+}
+
+// ThreatCoverage records the controls that mitigate a declared threat.
+type ThreatCoverage struct {
+	ID    string `json:"id"`
+	Title string `json:"title,omitempty"`
+	// Source is the metadata id of the catalog that declares the threat.
+	Source   string   `json:"source"`
+	Controls []string `json:"controls"`
+	// MaxStrength is the strongest mapping to the threat, when strengths are given.
+	MaxStrength int `json:"max_strength,omitempty"`
+}
+
+// OrphanThreatReference is a threat mapping entry that names an undeclared threat.
+type OrphanThreatReference struct {
+	Control  string `json:"control"`
+	Path     string `json:"path"`
+	Source   string `json:"source"`
+	ThreatID string `json:"threat_id"`
+}
+
+// OutputAnalyzeThreatCoverage is the output for the AnalyzeThreatCoverage tool.
+type OutputAnalyzeThreatCoverage struct {
+	Threats              []ThreatCoverage        `json:"threats"`
+	UncoveredThreats     []string                `json:"uncovered_threats"`
+	UnmitigatingControls []string                `json:"unmitigating_controls"`
+	OrphanReferences     []OrphanThreatReference `json:"orphan_references"`
+	// ExternalSources are mapped threat catalogs that were not supplied, so
+	// their references could not be checked.
+	ExternalSources []string `json:"external_sources,omitempty"`
+	Message         string   `json:"message"`
+}
+
+// AnalyzeThreatCoverage reports gaps between declared threats and the controls that mitigate them.
+func AnalyzeThreatCoverage(ctx context.Context, _ *mcp.CallToolRequest, input InputAnalyzeThreatCoverage) (*mcp.CallToolResult, OutputAnalyzeThreatCoverage, error) {
+	if input.CatalogContent == "" {
+		return nil, OutputAnalyzeThreatCoverage{}, fmt.Errorf("catalog_content is required")
+	}
+	catalog, err := parseArtifact(ctx, input.CatalogContent)
+	if err != nil {
+		return nil, OutputAnalyzeThreatCoverage{}, err
+	}
+	controls, ok := catalog["controls"].([]interface{})
+	if !ok {
+		return nil, OutputAnalyzeThreatCoverage{}, fmt.Errorf("catalog has no controls")
+	}
+
+	// Index declared threats by source catalog and ID
+	var threats []*ThreatCoverage
+	declared := map[string]map[string]*ThreatCoverage{}
+	declare := func(doc map[string]interface{}, source string) {
+		if declared[source] == nil {
+			declared[source] = map[string]*ThreatCoverage{}
+		}
+		items, _ := doc["threats"].([]interface{})
+		for _, item := range items {
+			id := entityID(item)
+			if id == "" || declared[source][id] != nil {
+				continue
+			}
+			title, _ := item.(map[string]interface{})["title"].(string)
+			threat := &ThreatCoverage{ID: id, Title: title, Source: source, Controls: []string{}}
+			declared[source][id] = threat
+			threats = append(threats, threat)
+		}
+	}
+	declare(catalog, metadataID(catalog))
+	for i, a := range input.ThreatCatalogs {
+		doc, err := parseArtifact(ctx, a.Content)
+		if err != nil {
+			return nil, OutputAnalyzeThreatCoverage{}, &artifactError{Name: artifactName(a, i), Err: err}
+		}
+		source := metadataID(doc)
+		if source == "" {
+			return nil, OutputAnalyzeThreatCoverage{}, fmt.Errorf("%s: threat catalog has no metadata id", artifactName(a, i))
+		}
+		declare(doc, source)
+	}
+
+	output := OutputAnalyzeThreatCoverage{
+		Threats:              []ThreatCoverage{},
+		UncoveredThreats:     []string{},
+		UnmitigatingControls: []string{},
+		OrphanReferences:     []OrphanThreatReference{},
+	}
+	external := map[string]bool{}
+	for i, item := range controls {
+		control, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		controlID := entityID(control)
+		mitigates := false
+		mappings, _ := control["threat-mappings"].([]interface{})
+		for j, m := range mappings {
+			mapping, ok := m.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			source, _ := mapping["reference-id"].(string)
+			entries, _ := mapping["entries"].([]interface{})
+			for k, e := range entries {
+				entry, ok := e.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				threatID, _ := entry["reference-id"].(string)
+				if threatID == "" {
+					continue
+				}
+				mitigates = true
+				known, ok := declared[source]
+				if !ok {
+					external[source] = true
+					continue
+				}
+				threat := known[threatID]
+				if threat == nil {
+					output.OrphanReferences = append(output.OrphanReferences, OrphanThreatReference{
+						Control:  controlID,
+						Path:     fmt.Sprintf("$.controls[%d].'threat-mappings'[%d].entries[%d]", i, j, k),
+						Source:   source,
+						ThreatID: threatID,
+					})
+					continue
+				}
+				if len(threat.Controls) == 0 || threat.Controls[len(threat.Controls)-1] != controlID {
+					threat.Controls = append(threat.Controls, controlID)
+				}
+				if strength, ok := intValue(entry["strength"]); ok && strength > threat.MaxStrength {
+					threat.MaxStrength = strength
+				}
+			}
+		}
+		if !mitigates && controlID != "" {
+			output.UnmitigatingControls = append(output.UnmitigatingControls, controlID)
+		}
+	}
+
+	covered := 0
+	for _, threat := range threats {
+		output.Threats = append(output.Threats, *threat)
+		if len(threat.Controls) == 0 {
+			output.UncoveredThreats = append(output.UncoveredThreats, threat.ID)
+		} else {
+			covered++
+		}
+	}
+	for source := range external {
+		output.ExternalSources = append(output.ExternalSources, source)
+	}
+	sort.Strings(output.ExternalSources)
+
+	if len(threats) == 0 {
+		output.Message = fmt.Sprintf("No threats are declared; %d of %d control(s) map to no threat", len(output.UnmitigatingControls), len(controls))
+	} else {
+		output.Message = fmt.Sprintf("%d of %d threat(s) mitigated; %d control(s) mitigate nothing; %d orphan reference(s)",
+			covered, len(threats), len(output.UnmitigatingControls), len(output.OrphanReferences))
+	}
+	if len(output.ExternalSources) > 0 {
+		output.Message += fmt.Sprintf("; supply threat_catalogs for %d unchecked source(s)", len(output.ExternalSources))
+	}
+	return nil, output, nil
+}
+
+// metadataID returns the metadata id of a parsed artifact.
+func metadataID(doc map[string]interface{}) string {
+	metadata, _ := doc["metadata"].(map[string]interface{})
+	id, _ := metadata["id"].(string)
+	return id
+}
+
+// intValue converts a decoded YAML number to an int.
+func intValue(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case uint64:
+		return int(n), true
+	case int64:
+		return int(n), true
+	case int:
+		return n, true
+	case float64:
+		return int(n), n == float64(int(n))
+	}
+	return 0, false
+}
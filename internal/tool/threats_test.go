@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const threatTestCatalog = `metadata:
+  id: STORAGE
+title: Storage
+threats:
+  - id: ST.TH01
+    title: Data Exfiltration
+  - id: ST.TH02
+    title: Ransomware
+controls:
+  - id: ST.C01
+    title: Encrypt Buckets
+    threat-mappings:
+      - reference-id: STORAGE
+        entries:
+          - reference-id: ST.TH01
+            strength: 6
+          - reference-id: ST.TH09
+      - reference-id: SHARED
+        entries:
+          - reference-id: SH.TH01
+            strength: 9
+      - reference-id: MITRE
+        entries:
+          - reference-id: T1530
+  - id: ST.C02
+    title: Block Public Access
+    threat-mappings:
+      - reference-id: STORAGE
+        entries:
+          - reference-id: ST.TH01
+            strength: 8
+  - id: ST.C03
+    title: Tag Buckets
+`
+
+const threatTestShared = `metadata:
+  id: SHARED
+title: Shared Threats
+threats:
+  - id: SH.TH01
+    title: Credential Theft
+  - id: SH.TH02
+    title: Insider Threat
+`
+
+func TestAnalyzeThreatCoverage(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         InputAnalyzeThreatCoverage
+		wantErr       bool
+		errContains   string
+		wantThreats   []ThreatCoverage
+		wantUncovered []string
+		wantOrphans   []OrphanThreatReference
+		wantExternal  []string
+	}{
+		{
+			name:        "missing catalog",
+			wantErr:     true,
+			errContains: "catalog_content is required",
+		},
+		{
+			name:        "threat catalog without id",
+			input:       InputAnalyzeThreatCoverage{CatalogContent: threatTestCatalog, ThreatCatalogs: []ArtifactInput{{Name: "t.yaml", Content: "threats: []\n"}}},
+			wantErr:     true,
+			errContains: "t.yaml: threat catalog has no metadata id",
+		},
+		{
+			name:  "catalog threats only",
+			input: InputAnalyzeThreatCoverage{CatalogContent: threatTestCatalog},
+			wantThreats: []ThreatCoverage{
+				{ID: "ST.TH01", Title: "Data Exfiltration", Source: "STORAGE", Controls: []string{"ST.C01", "ST.C02"}, MaxStrength: 8},
+				{ID: "ST.TH02", Title: "Ransomware", Source: "STORAGE", Controls: []string{}},
+			},
+			wantUncovered: []string{"ST.TH02"},
+			wantOrphans: []OrphanThreatReference{
+				{Control: "ST.C01", Path: "$.controls[0].'threat-mappings'[0].entries[1]", Source: "STORAGE", ThreatID: "ST.TH09"},
+			},
+			wantExternal: []string{"MITRE", "SHARED"},
+		},
+		{
+			name: "with threat catalog",
+			input: InputAnalyzeThreatCoverage{
+				CatalogContent: threatTestCatalog,
+				ThreatCatalogs: []ArtifactInput{{Name: "shared.yaml", Content: threatTestShared}},
+			},
+			wantThreats: []ThreatCoverage{
+				{ID: "ST.TH01", Title: "Data Exfiltration", Source: "STORAGE", Controls: []string{"ST.C01", "ST.C02"}, MaxStrength: 8},
+				{ID: "ST.TH02", Title: "Ransomware", Source: "STORAGE", Controls: []string{}},
+				{ID: "SH.TH01", Title: "Credential Theft", Source: "SHARED", Controls: []string{"ST.C01"}, MaxStrength: 9},
+				{ID: "SH.TH02", Title: "Insider Threat", Source: "SHARED", Controls: []string{}},
+			},
+			wantUncovered: []string{"ST.TH02", "SH.TH02"},
+			wantOrphans: []OrphanThreatReference{
+				{Control: "ST.C01", Path: "$.controls[0].'threat-mappings'[0].entries[1]", Source: "STORAGE", ThreatID: "ST.TH09"},
+			},
+			wantExternal: []string{"MITRE"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := AnalyzeThreatCoverage(context.Background(), nil, tt.input)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errContains)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantThreats, output.Threats)
+			assert.Equal(t, tt.wantUncovered, output.UncoveredThreats)
+			assert.Equal(t, []string{"ST.C03"}, output.UnmitigatingControls)
+			assert.Equal(t, tt.wantOrphans, output.OrphanReferences)
+			assert.Equal(t, tt.wantExternal, output.ExternalSources)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultToolTimeout is the default limit on how long a tool call may run.
+const DefaultToolTimeout = 2 * time.Minute
+
+// timeoutHandler runs a tool handler with the configured ToolTimeout deadline on its
+// context. Work that does not observe the context, such as CUE evaluation, is
+// abandoned when the deadline passes so the session is never blocked by it.
+// Time spent waiting for the user to approve changes does not count.
+func timeoutHandler[In, Out any](name string, h mcp.ToolHandlerFor[In, Out]) mcp.ToolHandlerFor[In, Out] {
+	return func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		timeout := serverConfig(ctx).ToolTimeout
+		if timeout <= 0 {
+			return h(ctx, req, input)
+		}
+		ctx, cancel := context.WithCancelCause(ctx)
+		defer cancel(nil)
+		deadline := startToolDeadline(timeout, func() { cancel(toolTimeoutError(name, timeout)) })
+		defer deadline.stop()
+		ctx = context.WithValue(ctx, toolDeadlineKey{}, deadline)
+
+		type result struct {
+			result *mcp.CallToolResult
+			output Out
+			err    error
+		}
+		done := make(chan result, 1)
+		go func() {
+			r, output, err := h(ctx, req, input)
+			done <- result{r, output, err}
+		}()
+
+		var zero Out
+		select {
+		case r := <-done:
+			if r.err != nil && errors.Is(context.Cause(ctx), context.DeadlineExceeded) {
+				return nil, zero, toolTimeoutError(name, timeout)
+			}
+			return r.result, r.output, r.err
+		case <-ctx.Done():
+			if errors.Is(context.Cause(ctx), context.DeadlineExceeded) {
+				return nil, zero, toolTimeoutError(name, timeout)
+			}
+			return nil, zero, ctx.Err()
+		}
+	}
+}
+
+type toolDeadlineKey struct{}
+
+// toolDeadline is the ToolTimeout of a tool call, which stops running while
+// the call is paused.
+type toolDeadline struct {
+	mu        sync.Mutex
+	timer     *time.Timer
+	remaining time.Duration
+	started   time.Time
+	paused    int
+	expired   bool
+}
+
+// startToolDeadline calls expire once timeout has run.
+func startToolDeadline(timeout time.Duration, expire func()) *toolDeadline {
+	d := &toolDeadline{remaining: timeout, started: time.Now()}
+	d.timer = time.AfterFunc(timeout, func() {
+		d.mu.Lock()
+		d.expired = true
+		d.mu.Unlock()
+		expire()
+	})
+	return d
+}
+
+// pause stops the deadline until the returned function is called.
+func (d *toolDeadline) pause() func() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.paused++
+	if d.paused == 1 && d.timer.Stop() {
+		d.remaining -= time.Since(d.started)
+	}
+	var once sync.Once
+	return func() { once.Do(d.resume) }
+}
+
+func (d *toolDeadline) resume() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.paused--
+	if d.paused == 0 && !d.expired {
+		d.started = time.Now()
+		d.timer.Reset(max(d.remaining, 0))
+	}
+}
+
+func (d *toolDeadline) stop() {
+	d.timer.Stop()
+}
+
+// pauseToolTimeout stops the ToolTimeout of the call of ctx, if it has one,
+// until the returned function is called. Calls waiting on the user, such as
+// for approval of their changes, pause it so that the user is not hurried.
+func pauseToolTimeout(ctx context.Context) func() {
+	if d, ok := ctx.Value(toolDeadlineKey{}).(*toolDeadline); ok {
+		return d.pause()
+	}
+	return func() {}
+}
+
+// toolTimeoutError reports that a tool call exceeded ToolTimeout.
+func toolTimeoutError(name string, timeout time.Duration) error {
+	return fmt.Errorf("%s timed out after %s (limit set by --tool-timeout): %w", name, timeout, context.DeadlineExceeded)
+}
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeoutHandler(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	tests := []struct {
+		name        string
+		timeout     time.Duration
+		handler     mcp.ToolHandlerFor[struct{}, string]
+		want        string
+		errContains string
+	}{
+		{
+			name:    "finishes in time",
+			timeout: time.Second,
+			handler: func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, string, error) {
+				return nil, "done", nil
+			},
+			want: "done",
+		},
+		{
+			name:    "observes the deadline",
+			timeout: 10 * time.Millisecond,
+			handler: func(ctx context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, string, error) {
+				<-ctx.Done()
+				return nil, "", ctx.Err()
+			},
+			errContains: "slow_tool timed out after 10ms",
+		},
+		{
+			name:    "ignores the deadline",
+			timeout: 10 * time.Millisecond,
+			handler: func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, string, error) {
+				<-release
+				return nil, "late", nil
+			},
+			errContains: "--tool-timeout",
+		},
+		{
+			name:    "paused while waiting on the user",
+			timeout: 50 * time.Millisecond,
+			handler: func(ctx context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, string, error) {
+				resume := pauseToolTimeout(ctx)
+				time.Sleep(150 * time.Millisecond)
+				resume()
+				if err := ctx.Err(); err != nil {
+					return nil, "", err
+				}
+				return nil, "approved", nil
+			},
+			want: "approved",
+		},
+		{
+			name:    "runs again after the pause",
+			timeout: 50 * time.Millisecond,
+			handler: func(ctx context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, string, error) {
+				pauseToolTimeout(ctx)()
+				<-ctx.Done()
+				return nil, "", ctx.Err()
+			},
+			errContains: "slow_tool timed out after 50ms",
+		},
+		{
+			name:    "disabled",
+			timeout: 0,
+			handler: func(ctx context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, string, error) {
+				_, ok := ctx.Deadline()
+				assert.False(t, ok, "should not set a deadline")
+				return nil, "done", nil
+			},
+			want: "done",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := withTestConfig(func(c *Config) { c.ToolTimeout = tt.timeout })
+			_, output, err := timeoutHandler("slow_tool", tt.handler)(ctx, nil, struct{}{})
+			if tt.errContains != "" {
+				require.Error(t, err, "should fail the call")
+				assert.Contains(t, err.Error(), tt.errContains, "error should contain expected message")
+				assert.ErrorIs(t, err, context.DeadlineExceeded, "error should wrap the deadline")
+				return
+			}
+			require.NoError(t, err, "should not return error")
+			assert.Equal(t, tt.want, output)
+		})
+	}
+}
@@ -0,0 +1,352 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MetadataGenerateTraceabilityMatrix describes the GenerateTraceabilityMatrix tool.
+var MetadataGenerateTraceabilityMatrix = &mcp.Tool{
+	Name: "generate_traceability_matrix",
+	Description: "Link guidance items to the catalog controls that map to them, the policy statements that adopt " +
+		"those controls, and the evaluation results recorded for them, across a set of artifacts. Returns the " +
+		"matrix as JSON rows and CSV, and lists the guidance items that lack any evaluation evidence.",
+	InputSchema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"artifacts": artifactInputSchema,
+			"directory": map[string]interface{}{
+				"type":        "string",
+				"description": "Directory of artifacts to trace when artifacts is omitted (default: the workspace root)",
+			},
+		},
+	},
+}
+
+// InputGenerateTraceabilityMatrix is the input for the GenerateTraceabilityMatrix tool.
+type InputGenerateTraceabilityMatrix struct {
+	Artifacts []ArtifactInput `json:"artifacts,omitempty"`
+	Directory string          `json:"directory,omitempty"`
+}
+
+// TraceStatement is a policy statement that adopts a control.
+type TraceStatement struct {
+	Artifact  string `json:"artifact"`
+	Statement string `json:"statement"`
+}
+
+// TraceEvaluation is an evaluation result recorded for a control.
+type TraceEvaluation struct {
+	Artifact   string `json:"artifact"`
+	Evaluation string `json:"evaluation,omitempty"`
+	Result     string `json:"result"`
+}
+
+// TraceRow links a guidance item to one control and what follows from it.
+// Controls no guidance maps to get a row without a guideline, and guidance
+// no control maps to gets a row without a control.
+type TraceRow struct {
+	GuidanceArtifact string            `json:"guidance_artifact,omitempty"`
+	Guideline        string            `json:"guideline,omitempty"`
+	CatalogArtifact  string            `json:"catalog_artifact,omitempty"`
+	Control          string            `json:"control,omitempty"`
+	Statements       []TraceStatement  `json:"policy_statements"`
+	Evaluations      []TraceEvaluation `json:"evaluations"`
+}
+
+// OutputGenerateTraceabilityMatrix is the output for the GenerateTraceabilityMatrix tool.
+type OutputGenerateTraceabilityMatrix struct {
+	Rows []TraceRow `json:"rows"`
+	CSV  string     `json:"csv"`
+	// UnevaluatedGuidance lists the guidance items without evaluation evidence
+	// through any control.
+	UnevaluatedGuidance []string `json:"unevaluated_guidance"`
+	Message             string   `json:"message"`
+}
+
+// traceColumns are the CSV columns of a traceability matrix.
+var traceColumns = []string{"guidance_artifact", "guideline", "catalog_artifact", "control", "policy_statements", "evaluations"}
+
+// GenerateTraceabilityMatrix traces guidance through controls and policies to
+// evaluation results.
+func GenerateTraceabilityMatrix(ctx context.Context, _ *mcp.CallToolRequest, input InputGenerateTraceabilityMatrix) (*mcp.CallToolResult, OutputGenerateTraceabilityMatrix, error) {
+	artifacts := input.Artifacts
+	if len(artifacts) == 0 {
+		var err error
+		artifacts, err = directoryArtifacts(ctx, input.Directory)
+		if err != nil {
+			return nil, OutputGenerateTraceabilityMatrix{}, err
+		}
+	}
+
+	graph, err := buildArtifactGraph(ctx, artifacts)
+	if err != nil {
+		return nil, OutputGenerateTraceabilityMatrix{}, err
+	}
+	evaluations, err := controlEvaluations(ctx, artifacts)
+	if err != nil {
+		return nil, OutputGenerateTraceabilityMatrix{}, err
+	}
+
+	rows := traceRows(graph, evaluations)
+	csvContent, err := traceCSV(rows)
+	if err != nil {
+		return nil, OutputGenerateTraceabilityMatrix{}, err
+	}
+
+	evaluated := map[string]bool{}
+	var guidelines []string
+	for _, row := range rows {
+		if row.Guideline == "" {
+			continue
+		}
+		if _, seen := evaluated[row.Guideline]; !seen {
+			guidelines = append(guidelines, row.Guideline)
+		}
+		evaluated[row.Guideline] = evaluated[row.Guideline] || len(row.Evaluations) > 0
+	}
+	unevaluated := []string{}
+	for _, id := range guidelines {
+		if !evaluated[id] {
+			unevaluated = append(unevaluated, id)
+		}
+	}
+
+	output := OutputGenerateTraceabilityMatrix{
+		Rows:                rows,
+		CSV:                 csvContent,
+		UnevaluatedGuidance: unevaluated,
+		Message: fmt.Sprintf("Traced %d guidance item(s) across %d row(s); %d lack evaluation evidence",
+			len(guidelines), len(rows), len(unevaluated)),
+	}
+	return nil, output, nil
+}
+
+// directoryArtifacts reads the artifact files under dir, defaulting to the
+// workspace root, named by their path relative to it.
+func directoryArtifacts(ctx context.Context, dir string) ([]ArtifactInput, error) {
+	dir, err := workspaceDirectory(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		return nil, fmt.Errorf("artifacts or directory is required when no workspace root is configured")
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	files, err := findArtifactFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	artifacts := make([]ArtifactInput, 0, len(files))
+	for _, file := range files {
+		content, err := readArtifactFile(ctx, file)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			rel = file
+		}
+		artifacts = append(artifacts, ArtifactInput{Name: filepath.ToSlash(rel), Content: string(content)})
+	}
+	return artifacts, nil
+}
+
+// controlEvaluations collects the evaluation results recorded for each control
+// ID in the evaluation logs among the artifacts.
+func controlEvaluations(ctx context.Context, artifacts []ArtifactInput) (map[string][]TraceEvaluation, error) {
+	evaluations := map[string][]TraceEvaluation{}
+	for i, a := range artifacts {
+		doc, err := parseArtifact(ctx, a.Content)
+		if err != nil {
+			return nil, &artifactError{Name: artifactName(a, i), Err: err}
+		}
+		if artifactKind(doc) != "EvaluationLog" {
+			continue
+		}
+		entries, _ := doc["evaluations"].([]interface{})
+		for _, e := range entries {
+			evaluation, ok := e.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			control := mappingEntryID(evaluation["control"])
+			result, _ := evaluation["result"].(string)
+			if control == "" || result == "" {
+				continue
+			}
+			name, _ := evaluation["name"].(string)
+			evaluations[control] = append(evaluations[control], TraceEvaluation{
+				Artifact:   artifactName(a, i),
+				Evaluation: name,
+				Result:     result,
+			})
+		}
+	}
+	return evaluations, nil
+}
+
+// traceRows builds the matrix rows: one per guideline and control mapped to
+// it, then one per control that no guideline maps to.
+func traceRows(graph *artifactGraph, evaluations map[string][]TraceEvaluation) []TraceRow {
+	guidelines := graphEntitiesOf(graph, "GuidanceDocument", "guidelines")
+	controls := graphEntitiesOf(graph, "ControlCatalog", "controls")
+
+	rows := []TraceRow{}
+	traced := map[string]bool{}
+	for _, guideline := range guidelines {
+		var mapped []graphEntity
+		seen := map[string]bool{}
+		for _, ref := range graph.references[guideline.ID] {
+			if ref.Kind != "ControlCatalog" {
+				continue
+			}
+			control, ok := enclosingEntity(graph, ref, "controls")
+			if !ok || seen[control.ID] {
+				continue
+			}
+			seen[control.ID] = true
+			mapped = append(mapped, control)
+		}
+
+		if len(mapped) == 0 {
+			rows = append(rows, TraceRow{
+				GuidanceArtifact: guideline.Artifact,
+				Guideline:        guideline.ID,
+				Statements:       []TraceStatement{},
+				Evaluations:      []TraceEvaluation{},
+			})
+			continue
+		}
+		for _, control := range mapped {
+			traced[control.Artifact+"|"+control.ID] = true
+			row := controlTraceRow(graph, control, evaluations)
+			row.GuidanceArtifact, row.Guideline = guideline.Artifact, guideline.ID
+			rows = append(rows, row)
+		}
+	}
+
+	for _, control := range controls {
+		if !traced[control.Artifact+"|"+control.ID] {
+			rows = append(rows, controlTraceRow(graph, control, evaluations))
+		}
+	}
+	return rows
+}
+
+// controlTraceRow traces a control to the policy statements that reference it
+// or its assessment requirements, and to its evaluation results.
+func controlTraceRow(graph *artifactGraph, control graphEntity, evaluations map[string][]TraceEvaluation) TraceRow {
+	row := TraceRow{
+		CatalogArtifact: control.Artifact,
+		Control:         control.ID,
+		Statements:      []TraceStatement{},
+		Evaluations:     []TraceEvaluation{},
+	}
+	if list := evaluations[control.ID]; len(list) > 0 {
+		row.Evaluations = list
+	}
+
+	ids := append([]string{control.ID}, graph.descendants(control)...)
+	sort.Strings(ids[1:])
+	seen := map[string]bool{}
+	for _, id := range ids {
+		for _, ref := range graph.references[id] {
+			if ref.Kind != "Policy" {
+				continue
+			}
+			statement := ref.Owner
+			if statement == "" {
+				statement = ref.Path
+			}
+			key := ref.Artifact + "|" + statement
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			row.Statements = append(row.Statements, TraceStatement{Artifact: ref.Artifact, Statement: statement})
+		}
+	}
+	return row
+}
+
+// graphEntitiesOf returns the entities of the given artifact kind listed
+// directly under a section such as "controls", ordered by artifact and path.
+func graphEntitiesOf(graph *artifactGraph, kind, section string) []graphEntity {
+	var entities []graphEntity
+	for _, list := range graph.entities {
+		for _, e := range list {
+			if e.Kind == kind && lastPathKey(e.Path) == section && strings.HasSuffix(e.Path, "]") {
+				entities = append(entities, e)
+			}
+		}
+	}
+	sort.Slice(entities, func(i, j int) bool {
+		if entities[i].Artifact != entities[j].Artifact {
+			return entities[i].Artifact < entities[j].Artifact
+		}
+		return entities[i].Path < entities[j].Path
+	})
+	return entities
+}
+
+// enclosingEntity finds the entity listed under section that contains a reference.
+func enclosingEntity(graph *artifactGraph, ref graphReference, section string) (graphEntity, bool) {
+	var best graphEntity
+	found := false
+	for _, list := range graph.entities {
+		for _, e := range list {
+			if e.Artifact != ref.Artifact || lastPathKey(e.Path) != section || !strings.HasPrefix(ref.Path, e.Path+".") {
+				continue
+			}
+			if !found || len(e.Path) > len(best.Path) {
+				best, found = e, true
+			}
+		}
+	}
+	return best, found
+}
+
+// traceCSV renders the matrix rows as CSV, joining multiple statements or
+// evaluations in a cell with "; ".
+func traceCSV(rows []TraceRow) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(traceColumns); err != nil {
+		return "", err
+	}
+	for _, row := range rows {
+		statements := make([]string, 0, len(row.Statements))
+		for _, s := range row.Statements {
+			statements = append(statements, s.Artifact+":"+s.Statement)
+		}
+		results := make([]string, 0, len(row.Evaluations))
+		for _, e := range row.Evaluations {
+			results = append(results, fmt.Sprintf("%s:%s=%s", e.Artifact, e.Evaluation, e.Result))
+		}
+		record := []string{row.GuidanceArtifact, row.Guideline, row.CatalogArtifact, row.Control,
+			strings.Join(statements, "; "), strings.Join(results, "; ")}
+		if err := w.Write(record); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return buf.String(), w.Error()
+}
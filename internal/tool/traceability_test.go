@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const traceGuidance = `metadata:
+  id: TEST-GUIDE
+  description: Test guidance
+  author: {id: test, name: Test, type: Human}
+title: Test Guidance
+categories:
+  - id: data
+    title: Data
+    description: Data guidelines
+    guidelines:
+      - id: PR.DS-02
+        title: Data in transit
+      - id: DSI-06
+        title: Data location
+      - id: PR.DS-5
+        title: Data leaks
+      - id: GD.UNMAPPED
+        title: Nobody maps this
+`
+
+func TestGenerateTraceabilityMatrix(t *testing.T) {
+	artifacts := append([]ArtifactInput{{Name: "guidance.yaml", Content: traceGuidance}},
+		loadTestArtifacts(t, "good-ccc.yaml", "policy.yaml", "evaluation-log.yaml")...)
+
+	_, output, err := GenerateTraceabilityMatrix(context.Background(), nil, InputGenerateTraceabilityMatrix{Artifacts: artifacts})
+	require.NoError(t, err, "should not return error")
+
+	rows := map[string]TraceRow{}
+	for _, row := range output.Rows {
+		rows[row.Guideline+"|"+row.Control] = row
+	}
+
+	transit, ok := rows["PR.DS-02|CCC.C01"]
+	require.True(t, ok, "guideline should trace to the control mapping it")
+	assert.Equal(t, "guidance.yaml", transit.GuidanceArtifact)
+	assert.Equal(t, "good-ccc.yaml", transit.CatalogArtifact)
+	assert.Equal(t, []TraceStatement{{Artifact: "policy.yaml", Statement: "AP-C01-TR01"}}, transit.Statements,
+		"policy statements adopting the control's requirements should be traced")
+	require.NotEmpty(t, transit.Evaluations, "evaluation results should be traced")
+	assert.Equal(t, "Passed", transit.Evaluations[0].Result)
+
+	location, ok := rows["DSI-06|CCC.C06"]
+	require.True(t, ok, "guideline should trace to the control mapping it")
+	assert.Equal(t, "Failed", location.Evaluations[0].Result, "failed results are still evidence")
+
+	leaks, ok := rows["PR.DS-5|CCC.C08"]
+	require.True(t, ok, "guideline should trace to the control mapping it")
+	assert.Empty(t, leaks.Evaluations, "control without evaluations should have none")
+
+	unmapped, ok := rows["GD.UNMAPPED|"]
+	require.True(t, ok, "unmapped guideline should get a row")
+	assert.Empty(t, unmapped.Control)
+
+	_, ok = rows["|CCC.C09"]
+	assert.True(t, ok, "controls without guidance should get a row")
+
+	assert.Equal(t, []string{"PR.DS-5", "GD.UNMAPPED"}, output.UnevaluatedGuidance,
+		"guidance without evaluation evidence should be listed")
+
+	lines := strings.Split(strings.TrimSpace(output.CSV), "\n")
+	assert.Equal(t, strings.Join(traceColumns, ","), lines[0], "CSV should start with a header")
+	assert.Len(t, lines, len(output.Rows)+1, "CSV should have a line per row")
+	assert.Contains(t, output.CSV, "guidance.yaml,PR.DS-02,good-ccc.yaml,CCC.C01,policy.yaml:AP-C01-TR01,")
+}
+
+func TestGenerateTraceabilityMatrixDirectory(t *testing.T) {
+	_, _, err := GenerateTraceabilityMatrix(context.Background(), nil, InputGenerateTraceabilityMatrix{})
+	require.Error(t, err, "should require artifacts without a workspace")
+	assert.Contains(t, err.Error(), "artifacts or directory is required")
+
+	root := t.TempDir()
+	writeTestFile(t, root, "guidance/guide.yaml", traceGuidance)
+	for _, a := range loadTestArtifacts(t, "good-ccc.yaml", "evaluation-log.yaml") {
+		writeTestFile(t, root, a.Name, a.Content)
+	}
+
+	ctx := withTestConfig(func(c *Config) { c.WorkspaceRoot = root })
+	_, output, err := GenerateTraceabilityMatrix(ctx, nil, InputGenerateTraceabilityMatrix{})
+	require.NoError(t, err, "should trace the workspace")
+	assert.Equal(t, "guidance/guide.yaml", output.Rows[0].GuidanceArtifact, "artifacts should be named relative to the directory")
+	assert.Equal(t, []string{"PR.DS-5", "GD.UNMAPPED"}, output.UnevaluatedGuidance)
+}
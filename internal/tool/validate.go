@@ -8,32 +8,42 @@ import (
 	"strings"
 
 	"cuelang.org/go/cue"
-	"cuelang.org/go/cue/cuecontext"
-	"cuelang.org/go/cue/load"
-	"cuelang.org/go/encoding/yaml"
-	"cuelang.org/go/mod/modconfig"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-const (
-	gemaraModulePath = "github.com/gemaraproj/gemara@latest"
-)
-
 // MetadataValidateGemaraArtifact describes the ValidateGemaraArtifact tool.
 var MetadataValidateGemaraArtifact = &mcp.Tool{
-	Name:        "validate_gemara_artifact",
-	Description: "Validate a Gemara artifact YAML content against the Gemara CUE schema using the CUE registry module.",
+	Name: "validate_gemara_artifact",
+	Description: "Validate a Gemara artifact YAML content against the Gemara CUE schema using the CUE registry module. " +
+		"Supply the artifact inline with artifact_content or by reference with artifact_uri, as YAML, JSON, or CUE. " +
+		"Multi-document YAML streams are validated document by document, with a result for each.",
 	InputSchema: map[string]interface{}{
-		"type":     "object",
-		"required": []string{"artifact_content", "definition"},
+		"type": "object",
 		"properties": map[string]interface{}{
 			"artifact_content": map[string]interface{}{
 				"type":        "string",
 				"description": "YAML content of the Gemara artifact to validate",
 			},
-			"definition": map[string]interface{}{
+			"artifact_uri": map[string]interface{}{
+				"type": "string",
+				"description": "URI of the artifact to validate instead of inline content: file:// (within the workspace root), " +
+					"https://, or gemara://examples/{definition}/{n}",
+			},
+			"artifact_format": map[string]interface{}{
 				"type":        "string",
-				"description": "CUE definition name to validate against (e.g., '#ControlCatalog', '#GuidanceDocument', '#Policy', '#EvaluationLog')",
+				"enum":        artifactFormats,
+				"description": "Encoding of the artifact: yaml (default), json, or cue",
+			},
+			"definition": map[string]interface{}{
+				"type": "string",
+				"description": "CUE definition name to validate against (e.g., '#ControlCatalog', '#GuidanceDocument', '#Policy', '#EvaluationLog'), " +
+					"or 'auto' to detect it from the content. When omitted, or when detection is ambiguous, clients that " +
+					"support elicitation are asked to choose",
+			},
+			"path": map[string]interface{}{
+				"type": "string",
+				"description": "Only validate the subtree at this YAML path (e.g., '$.controls[0]') against the " +
+					"corresponding part of the definition",
 			},
 		},
 	},
@@ -41,81 +51,362 @@ var MetadataValidateGemaraArtifact = &mcp.Tool{
 
 // InputValidateGemaraArtifact is the input for the ValidateGemaraArtifact tool.
 type InputValidateGemaraArtifact struct {
-	ArtifactContent string `json:"artifact_content"`
-	Definition      string `json:"definition"`
+	ArtifactContent string `json:"artifact_content,omitempty"`
+	ArtifactURI     string `json:"artifact_uri,omitempty"`
+	ArtifactFormat  string `json:"artifact_format,omitempty"`
+	Definition      string `json:"definition,omitempty"`
+	Path            string `json:"path,omitempty"`
 }
 
 // OutputValidateGemaraArtifact is the output for the ValidateGemaraArtifact tool.
 type OutputValidateGemaraArtifact struct {
-	Valid   bool     `json:"valid"`
-	Errors  []string `json:"errors,omitempty"`
-	Message string   `json:"message"`
+	Definition  string                 `json:"definition,omitempty"`
+	Path        string                 `json:"path,omitempty"`
+	Valid       bool                   `json:"valid"`
+	Errors      []string               `json:"errors,omitempty"`
+	Diagnostics []ValidationDiagnostic `json:"diagnostics,omitempty"`
+	Message     string                 `json:"message"`
+	// Documents holds the result of each document of a multi-document YAML stream.
+	Documents []DocumentValidation `json:"documents,omitempty"`
+	// SchemaFallback is set when the registry was unreachable and the artifact
+	// was validated against the schema embedded in the binary, whose version
+	// is SchemaVersion.
+	SchemaFallback bool   `json:"schema_fallback,omitempty"`
+	SchemaVersion  string `json:"schema_version,omitempty"`
+}
+
+// DocumentValidation is the validation result of one document in a multi-document YAML stream.
+type DocumentValidation struct {
+	// Index is the zero-based position of the document in the stream.
+	Index int `json:"index"`
+	// Line is the line of the stream on which the document starts.
+	Line        int                    `json:"line"`
+	Definition  string                 `json:"definition"`
+	Valid       bool                   `json:"valid"`
+	Errors      []string               `json:"errors,omitempty"`
+	Diagnostics []ValidationDiagnostic `json:"diagnostics,omitempty"`
+	Message     string                 `json:"message"`
 }
 
 // ValidateGemaraArtifact validates a Gemara artifact using the CUE Go SDK with the registry module.
-func ValidateGemaraArtifact(ctx context.Context, _ *mcp.CallToolRequest, input InputValidateGemaraArtifact) (*mcp.CallToolResult, OutputValidateGemaraArtifact, error) {
+func ValidateGemaraArtifact(ctx context.Context, req *mcp.CallToolRequest, input InputValidateGemaraArtifact) (*mcp.CallToolResult, OutputValidateGemaraArtifact, error) {
 	// Validate inputs
-	if input.ArtifactContent == "" {
+	if input.ArtifactContent == "" && input.ArtifactURI == "" {
 		return nil, OutputValidateGemaraArtifact{}, fmt.Errorf("artifact_content is required")
 	}
-	if input.Definition == "" {
+	if input.ArtifactContent != "" && input.ArtifactURI != "" {
+		return nil, OutputValidateGemaraArtifact{}, fmt.Errorf("artifact_content and artifact_uri are mutually exclusive")
+	}
+	// Without elicitation there is nobody to ask for an omitted definition
+	if input.Definition == "" && !elicitationSupported(req) {
 		return nil, OutputValidateGemaraArtifact{}, fmt.Errorf("definition is required")
 	}
+	format, err := normalizeFormat(input.ArtifactFormat)
+	if err != nil {
+		return nil, OutputValidateGemaraArtifact{}, err
+	}
 
-	// Ensure definition starts with #
-	definition := input.Definition
-	if !strings.HasPrefix(definition, "#") {
-		definition = "#" + definition
+	content := input.ArtifactContent
+	if input.ArtifactURI != "" {
+		raw, err := readArtifactURI(ctx, input.ArtifactURI)
+		if err != nil {
+			return nil, OutputValidateGemaraArtifact{}, err
+		}
+		content = string(raw)
+	}
+	if err := checkArtifactLimits(ctx, format, content); err != nil {
+		return nil, OutputValidateGemaraArtifact{}, err
 	}
 
-	// Create registry for module access
-	reg, err := modconfig.NewRegistry(nil)
+	schema, err := loadSchema(ctx)
 	if err != nil {
-		return nil, OutputValidateGemaraArtifact{}, fmt.Errorf("failed to create CUE registry: %w", err)
+		return nil, OutputValidateGemaraArtifact{}, err
 	}
 
-	// Load the Gemara module from registry
-	// Pass the module path as an argument to load it from the registry
-	buildInstances := load.Instances([]string{gemaraModulePath}, &load.Config{
-		Registry: reg,
+	requested := input.Definition
+	if requested == "" || requested == definitionAuto {
+		input.Definition, err = chooseDefinition(ctx, req, schema, requested, format, content)
+		if err != nil {
+			return nil, OutputValidateGemaraArtifact{}, err
+		}
+	}
+
+	output, err := withValidationTimeout(ctx, func() (OutputValidateGemaraArtifact, error) {
+		// Only YAML has document separators
+		if format == formatYAML {
+			if documents := splitYAMLDocuments(content); len(documents) > 1 {
+				return validateDocuments(ctx, schema, input, documents)
+			}
+		}
+		return validateDocument(ctx, schema, input, format, content)
 	})
+	if err != nil {
+		return nil, OutputValidateGemaraArtifact{}, err
+	}
+	if input.Definition != requested && input.Definition != definitionAuto {
+		output.Definition = normalizeDefinition(input.Definition)
+	}
+	if schema.snapshot {
+		output.SchemaFallback = true
+		output.SchemaVersion = schema.version
+		output.Message += fmt.Sprintf(" (validated against the embedded schema %s because the registry was unreachable)", schema.version)
+	}
+	definition := output.Definition
+	if definition == "" {
+		definition = normalizeDefinition(input.Definition)
+	}
+	emitValidationWebhook(ctx, definition, input.ArtifactURI, output)
+	return nil, output, nil
+}
 
-	if len(buildInstances) == 0 {
-		return nil, OutputValidateGemaraArtifact{}, fmt.Errorf("failed to load module: no instances returned")
+// validateDocument validates a single document encoded in format against the
+// definition requested in input, detecting it first when the definition is auto.
+func validateDocument(ctx context.Context, schema *gemaraSchema, input InputValidateGemaraArtifact, format, content string) (OutputValidateGemaraArtifact, error) {
+	// Ensure definition starts with #
+	definition := normalizeDefinition(input.Definition)
+	if input.Definition == definitionAuto {
+		matches, err := detectFormatDefinition(ctx, schema, format, content)
+		if err != nil {
+			return OutputValidateGemaraArtifact{}, err
+		}
+		definition = matches[0].Definition
+	}
+
+	var output OutputValidateGemaraArtifact
+	var err error
+	if input.Path != "" {
+		output, err = validateSubtree(ctx, schema, definition, format, content, input.Path)
+	} else {
+		output, err = validateFormatAgainstSchema(schema, definition, format, content)
+	}
+	if err != nil {
+		return OutputValidateGemaraArtifact{}, err
 	}
+	if input.Definition == definitionAuto {
+		output.Definition = definition
+	}
+	return output, nil
+}
+
+// validateDocuments validates each document of a multi-document YAML stream
+// and reports the stream as valid only when every document is.
+func validateDocuments(ctx context.Context, schema *gemaraSchema, input InputValidateGemaraArtifact, documents []yamlDocument) (OutputValidateGemaraArtifact, error) {
+	output := OutputValidateGemaraArtifact{
+		Path:      input.Path,
+		Valid:     true,
+		Errors:    []string{},
+		Documents: make([]DocumentValidation, 0, len(documents)),
+	}
+	valid := 0
+	for i, doc := range documents {
+		result, err := validateDocument(ctx, schema, input, formatYAML, doc.Content)
+		if err != nil {
+			return OutputValidateGemaraArtifact{}, fmt.Errorf("document %d (line %d): %w", i, doc.Line, err)
+		}
 
-	if err := buildInstances[0].Err; err != nil {
-		return nil, OutputValidateGemaraArtifact{}, fmt.Errorf("failed to load module: %w", err)
+		// Report positions within the stream rather than within the document
+		for j := range result.Diagnostics {
+			if result.Diagnostics[j].Line > 0 {
+				result.Diagnostics[j].Line += doc.Line - 1
+			}
+		}
+		definition := result.Definition
+		if definition == "" {
+			definition = normalizeDefinition(input.Definition)
+		}
+		output.Documents = append(output.Documents, DocumentValidation{
+			Index:       i,
+			Line:        doc.Line,
+			Definition:  definition,
+			Valid:       result.Valid,
+			Errors:      result.Errors,
+			Diagnostics: result.Diagnostics,
+			Message:     result.Message,
+		})
+
+		if result.Valid {
+			valid++
+			continue
+		}
+		output.Valid = false
+		for _, e := range result.Errors {
+			output.Errors = append(output.Errors, fmt.Sprintf("document %d: %s", i, e))
+		}
 	}
 
-	// Build the schema instance
-	cueCtx := cuecontext.New()
-	schema := cueCtx.BuildInstance(buildInstances[0])
-	if err := schema.Err(); err != nil {
-		return nil, OutputValidateGemaraArtifact{}, fmt.Errorf("failed to build schema: %w", err)
+	if input.Definition != definitionAuto {
+		output.Definition = normalizeDefinition(input.Definition)
+	}
+	if output.Valid {
+		output.Message = fmt.Sprintf("All %d documents are valid", len(documents))
+	} else {
+		output.Message = fmt.Sprintf("Validation failed: %d of %d documents are valid", valid, len(documents))
 	}
+	return output, nil
+}
+
+// yamlDocument is one document of a YAML stream.
+type yamlDocument struct {
+	Content string
+	// Line is the line of the stream on which the document starts.
+	Line int
+}
 
+// splitYAMLDocuments splits a YAML stream on its "---" and "..." document
+// markers. Documents holding nothing but comments and blank lines are dropped,
+// so a leading or trailing marker does not produce an empty document. Content
+// after a "---" on the same line is kept, with the marker blanked out so that
+// columns stay the same.
+func splitYAMLDocuments(content string) []yamlDocument {
+	var documents []yamlDocument
+	var current []string
+	start := 1
+	flush := func() {
+		for _, line := range current {
+			trimmed := strings.TrimSpace(line)
+			if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+				documents = append(documents, yamlDocument{Content: strings.Join(current, "\n") + "\n", Line: start})
+				break
+			}
+		}
+		current = nil
+	}
+
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		switch {
+		case line == "---" || strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "---\t"):
+			flush()
+			start = i + 1
+			current = []string{"   " + line[3:]}
+		case line == "..." || strings.HasPrefix(line, "... "):
+			flush()
+			start = i + 2
+		default:
+			current = append(current, line)
+		}
+	}
+	flush()
+	return documents
+}
+
+// validateSubtree validates the node at a YAML path against the matching part of a definition.
+func validateSubtree(ctx context.Context, schema *gemaraSchema, definition, format, content, path string) (OutputValidateGemaraArtifact, error) {
+	segments, err := parseArtifactPath(path)
+	if err != nil {
+		return OutputValidateGemaraArtifact{}, err
+	}
+
+	entrypoint, err := schema.lookupDefinition(definition)
+	if err != nil {
+		return OutputValidateGemaraArtifact{}, err
+	}
+	subschema, err := schemaAt(entrypoint, segments)
+	if err != nil {
+		return OutputValidateGemaraArtifact{}, fmt.Errorf("path %s: %w", path, err)
+	}
+
+	doc, err := decodeArtifact(ctx, schema, format, content)
+	if err != nil {
+		output := OutputValidateGemaraArtifact{
+			Path:    path,
+			Valid:   false,
+			Errors:  []string{err.Error()},
+			Message: fmt.Sprintf("Validation failed: invalid %s: %v", strings.ToUpper(format), err),
+		}
+		return output, nil
+	}
+	node, err := dataAt(doc, segments)
+	if err != nil {
+		return OutputValidateGemaraArtifact{}, fmt.Errorf("path %s: %w", path, err)
+	}
+
+	data := schema.ctx.Encode(node)
+	if err := data.Err(); err != nil {
+		return OutputValidateGemaraArtifact{}, fmt.Errorf("failed to build data instance: %w", err)
+	}
+
+	output := validateValue(subschema, data, segments)
+	output.Path = path
+	return output, nil
+}
+
+// schemaAt returns the part of a schema that describes the node at a YAML path.
+func schemaAt(schema cue.Value, segments []pathSegment) (cue.Value, error) {
+	for _, seg := range segments {
+		if seg.IsIndex {
+			schema = schema.LookupPath(cue.MakePath(cue.AnyIndex))
+			if !schema.Exists() {
+				return cue.Value{}, fmt.Errorf("schema does not allow a list at [%d]", seg.Index)
+			}
+			continue
+		}
+		next := schema.LookupPath(cue.MakePath(cue.Str(seg.Key)))
+		if !next.Exists() {
+			next = schema.LookupPath(cue.MakePath(cue.Str(seg.Key).Optional()))
+		}
+		if !next.Exists() {
+			return cue.Value{}, fmt.Errorf("field %q is not defined by the schema", seg.Key)
+		}
+		schema = next
+	}
+	return schema, nil
+}
+
+// dataAt returns the node at a YAML path in a parsed artifact.
+func dataAt(doc interface{}, segments []pathSegment) (interface{}, error) {
+	node := doc
+	for _, seg := range segments {
+		if seg.IsIndex {
+			list, ok := node.([]interface{})
+			if !ok || seg.Index >= len(list) {
+				return nil, fmt.Errorf("no list element at [%d]", seg.Index)
+			}
+			node = list[seg.Index]
+			continue
+		}
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("no field %q", seg.Key)
+		}
+		value, ok := m[seg.Key]
+		if !ok {
+			return nil, fmt.Errorf("no field %q", seg.Key)
+		}
+		node = value
+	}
+	return node, nil
+}
+
+// validateAgainstSchema validates YAML content against a definition of an already loaded schema.
+func validateAgainstSchema(schema *gemaraSchema, definition, content string) (OutputValidateGemaraArtifact, error) {
+	return validateFormatAgainstSchema(schema, definition, formatYAML, content)
+}
+
+// validateFormatAgainstSchema validates content encoded in format against a
+// definition of an already loaded schema.
+func validateFormatAgainstSchema(schema *gemaraSchema, definition, format, content string) (OutputValidateGemaraArtifact, error) {
 	// Look up the definition in the schema
-	entrypointPath := cue.ParsePath(definition)
-	entrypoint := schema.LookupPath(entrypointPath)
-	if !entrypoint.Exists() {
-		return nil, OutputValidateGemaraArtifact{}, fmt.Errorf("definition %s not found in schema", definition)
+	entrypoint, err := schema.lookupDefinition(definition)
+	if err != nil {
+		return OutputValidateGemaraArtifact{}, err
 	}
 
-	// Extract YAML content to CUE
-	yamlFile, err := yaml.Extract("artifact.yaml", input.ArtifactContent)
+	// Extract the content to CUE
+	file, err := extractArtifact(format, content)
 	if err != nil {
-		// Invalid YAML should result in validation failure, not a function error
+		// Invalid input should result in validation failure, not a function error
+		name := strings.ToUpper(format)
 		output := OutputValidateGemaraArtifact{
 			Valid:   false,
-			Errors:  []string{fmt.Sprintf("Failed to parse YAML: %v", err)},
-			Message: fmt.Sprintf("Validation failed: invalid YAML: %v", err),
+			Errors:  []string{fmt.Sprintf("Failed to parse %s: %v", name, err)},
+			Message: fmt.Sprintf("Validation failed: invalid %s: %v", name, err),
 		}
-		return nil, output, nil
+		return output, nil
 	}
 
-	// Build the data instance from YAML
-	data := cueCtx.BuildFile(yamlFile)
+	// Build the data instance
+	data := schema.ctx.BuildFile(file)
 	if err := data.Err(); err != nil {
 		// Data build errors should result in validation failure
 		output := OutputValidateGemaraArtifact{
@@ -123,9 +414,15 @@ func ValidateGemaraArtifact(ctx context.Context, _ *mcp.CallToolRequest, input I
 			Errors:  []string{fmt.Sprintf("Failed to build data instance: %v", err)},
 			Message: fmt.Sprintf("Validation failed: %v", err),
 		}
-		return nil, output, nil
+		return output, nil
 	}
 
+	return validateValue(entrypoint, data, nil), nil
+}
+
+// validateValue unifies data with a schema and reports whether it is concrete and valid.
+// prefix is the path of data within the whole artifact.
+func validateValue(entrypoint, data cue.Value, prefix []pathSegment) OutputValidateGemaraArtifact {
 	// Unify schema definition with data
 	unified := entrypoint.Unify(data)
 
@@ -142,19 +439,17 @@ func ValidateGemaraArtifact(ctx context.Context, _ *mcp.CallToolRequest, input I
 			}
 		}
 
-		output := OutputValidateGemaraArtifact{
-			Valid:   false,
-			Errors:  errors,
-			Message: fmt.Sprintf("Validation failed: %v", err),
+		return OutputValidateGemaraArtifact{
+			Valid:       false,
+			Errors:      errors,
+			Diagnostics: validationDiagnostics(err, entrypoint, data, prefix),
+			Message:     fmt.Sprintf("Validation failed: %v", err),
 		}
-		return nil, output, nil
 	}
 
-	output := OutputValidateGemaraArtifact{
+	return OutputValidateGemaraArtifact{
 		Valid:   true,
 		Errors:  []string{},
 		Message: "Artifact is valid",
 	}
-
-	return nil, output, nil
 }
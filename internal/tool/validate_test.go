@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -166,3 +167,318 @@ metadata:
 func boolPtr(b bool) *bool {
 	return &b
 }
+
+func TestValidateGemaraArtifactPath(t *testing.T) {
+	useTestSchema(t)
+
+	// The metadata is incomplete, so only subtree validation can pass
+	content := `metadata:
+  id: TEST
+controls:
+  - id: TST.C01
+    family: fam
+    title: Valid control
+    objective: Passes on its own
+    assessment-requirements:
+      - id: TST.C01.TR01
+        text: Requirement text
+        applicability: [all]
+  - id: TST.C02
+    family: fam
+    title: Missing objective
+    assessment-requirements: []
+`
+
+	tests := []struct {
+		name        string
+		path        string
+		wantValid   bool
+		errContains string
+	}{
+		{name: "valid control", path: "$.controls[0]", wantValid: true},
+		{name: "path without root", path: "controls[0].assessment-requirements[0]", wantValid: true},
+		{name: "quoted key", path: "$.controls[0].'assessment-requirements'", wantValid: true},
+		{name: "invalid control", path: "$.controls[1]", wantValid: false},
+		{name: "invalid metadata", path: "$.metadata", wantValid: false},
+		{name: "missing element", path: "$.controls[5]", errContains: "no list element"},
+		{name: "unknown field", path: "$.nope", errContains: "not defined by the schema"},
+		{name: "bad index", path: "$.controls[x]", errContains: "bad index"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := ValidateGemaraArtifact(context.Background(), nil, InputValidateGemaraArtifact{
+				ArtifactContent: content,
+				Definition:      "#ControlCatalog",
+				Path:            tt.path,
+			})
+			if tt.errContains != "" {
+				require.Error(t, err, "should return error")
+				assert.Contains(t, err.Error(), tt.errContains, "error should contain expected message")
+				return
+			}
+			require.NoError(t, err, "should not return error")
+			assert.Equal(t, tt.wantValid, output.Valid, "valid status should match: %v", output.Errors)
+			assert.Equal(t, tt.path, output.Path, "output should echo the path")
+		})
+	}
+}
+
+func TestValidateGemaraArtifactDiagnostics(t *testing.T) {
+	useTestSchema(t)
+
+	content := `metadata:
+  id: 5
+  description: Test
+  author: {id: a, name: b, type: Robot}
+title: Test
+controls:
+  - id: TST.C01
+    family: fam
+    title: Control
+    objective: Objective
+    threat-mappings:
+      - reference-id: THREATS
+        entries:
+          - reference-id: T01
+            strength: 11
+    assessment-requirements: []
+`
+	_, output, err := ValidateGemaraArtifact(context.Background(), nil, InputValidateGemaraArtifact{
+		ArtifactContent: content,
+		Definition:      "#ControlCatalog",
+	})
+	require.NoError(t, err, "should not return error")
+	require.False(t, output.Valid, "artifact should be invalid")
+
+	byPointer := map[string]ValidationDiagnostic{}
+	for _, d := range output.Diagnostics {
+		byPointer[d.Pointer] = d
+	}
+
+	id, ok := byPointer["/metadata/id"]
+	require.True(t, ok, "should report metadata id: %v", output.Diagnostics)
+	assert.Equal(t, 2, id.Line, "should report the line")
+	assert.Equal(t, 7, id.Column, "should report the column")
+	assert.Equal(t, "string", id.Expected, "should report the expected constraint")
+	assert.Equal(t, "5", id.Actual, "should report the actual value")
+
+	authorType, ok := byPointer["/metadata/author/type"]
+	require.True(t, ok, "should report author type")
+	assert.Equal(t, 4, authorType.Line, "should report the line")
+	assert.Contains(t, authorType.Expected, `"Human" | "Software"`, "should report the allowed values")
+	assert.Equal(t, `"Robot"`, authorType.Actual, "should report the actual value")
+
+	strength, ok := byPointer["/controls/0/threat-mappings/0/entries/0/strength"]
+	require.True(t, ok, "should report strength")
+	assert.Equal(t, 15, strength.Line, "should report the line")
+	assert.Equal(t, "11", strength.Actual, "should report the actual value")
+}
+
+func TestJSONPointer(t *testing.T) {
+	segments := []pathSegment{{Key: "a/b"}, {Index: 2, IsIndex: true}, {Key: "c~d"}}
+	assert.Equal(t, "/a~1b/2/c~0d", jsonPointer(segments))
+	assert.Equal(t, "", jsonPointer(nil), "root pointer is empty")
+}
+
+func TestValidateGemaraArtifactMultiDocument(t *testing.T) {
+	useTestSchema(t)
+	catalog, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err, "should read test catalog")
+	policy, err := os.ReadFile(filepath.Join("test-data", "policy.yaml"))
+	require.NoError(t, err, "should read test policy")
+
+	invalid := "metadata:\n  id: 5\ncontrols: []\n"
+
+	tests := []struct {
+		name        string
+		content     string
+		definition  string
+		wantValid   bool
+		wantDocs    []DocumentValidation
+		errContains string
+	}{
+		{
+			name:       "single document with leading marker",
+			content:    "---\n" + string(catalog),
+			definition: "#ControlCatalog",
+			wantValid:  true,
+		},
+		{
+			name:       "all documents valid",
+			content:    string(catalog) + "---\n" + string(catalog) + "...\n",
+			definition: "#ControlCatalog",
+			wantValid:  true,
+			wantDocs: []DocumentValidation{
+				{Index: 0, Line: 1, Definition: "#ControlCatalog", Valid: true},
+				{Index: 1, Line: strings.Count(string(catalog), "\n") + 1, Definition: "#ControlCatalog", Valid: true},
+			},
+		},
+		{
+			name:       "one invalid document",
+			content:    "# stream\n---\n" + string(catalog) + "--- # second\n" + invalid,
+			definition: "ControlCatalog",
+			wantValid:  false,
+			wantDocs: []DocumentValidation{
+				{Index: 0, Line: 2, Definition: "#ControlCatalog", Valid: true},
+				{Index: 1, Line: strings.Count(string(catalog), "\n") + 3, Definition: "#ControlCatalog", Valid: false},
+			},
+		},
+		{
+			name:       "detected definition per document",
+			content:    string(catalog) + "---\n" + string(policy),
+			definition: definitionAuto,
+			wantValid:  true,
+			wantDocs: []DocumentValidation{
+				{Index: 0, Line: 1, Definition: "#ControlCatalog", Valid: true},
+				{Index: 1, Line: strings.Count(string(catalog), "\n") + 1, Definition: "#Policy", Valid: true},
+			},
+		},
+		{
+			name:        "unknown definition",
+			content:     string(catalog) + "---\n" + string(catalog),
+			definition:  "#Nope",
+			errContains: "document 0 (line 1)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := ValidateGemaraArtifact(context.Background(), nil, InputValidateGemaraArtifact{
+				ArtifactContent: tt.content,
+				Definition:      tt.definition,
+			})
+			if tt.errContains != "" {
+				require.Error(t, err, "should return error")
+				assert.Contains(t, err.Error(), tt.errContains, "error should contain expected message")
+				return
+			}
+			require.NoError(t, err, "should not return error")
+			assert.Equal(t, tt.wantValid, output.Valid, "valid status should match: %v", output.Errors)
+			require.Len(t, output.Documents, len(tt.wantDocs), "should report each document")
+			for i, want := range tt.wantDocs {
+				got := output.Documents[i]
+				assert.Equal(t, want.Index, got.Index, "document index should match")
+				assert.Equal(t, want.Line, got.Line, "document line should match")
+				assert.Equal(t, want.Definition, got.Definition, "document definition should match")
+				assert.Equal(t, want.Valid, got.Valid, "document %d valid status should match: %v", i, got.Errors)
+			}
+		})
+	}
+}
+
+func TestValidateGemaraArtifactMultiDocumentDiagnostics(t *testing.T) {
+	useTestSchema(t)
+
+	content := "metadata:\n  id: FIRST\ncontrols: []\n---\nmetadata:\n  id: 5\ncontrols: []\n"
+	_, output, err := ValidateGemaraArtifact(context.Background(), nil, InputValidateGemaraArtifact{
+		ArtifactContent: content,
+		Definition:      "#ControlCatalog",
+	})
+	require.NoError(t, err, "should not return error")
+	require.Len(t, output.Documents, 2, "should report each document")
+
+	for _, d := range output.Documents[1].Diagnostics {
+		if d.Pointer == "/metadata/id" {
+			assert.Equal(t, 6, d.Line, "should report the line within the stream")
+			return
+		}
+	}
+	t.Fatalf("should report metadata id: %v", output.Documents[1].Diagnostics)
+}
+
+func TestValidateGemaraArtifactFormat(t *testing.T) {
+	useTestSchema(t)
+
+	tests := []struct {
+		name        string
+		format      string
+		content     string
+		definition  string
+		path        string
+		wantValid   bool
+		wantLine    int
+		errContains string
+	}{
+		{
+			name:       "valid JSON",
+			format:     "json",
+			content:    `{"id": "TST.C01.TR01", "text": "Requirement text", "applicability": ["all"]}`,
+			definition: "#AssessmentRequirement",
+			wantValid:  true,
+		},
+		{
+			name:       "invalid JSON value",
+			format:     "JSON",
+			content:    "{\n  \"id\": 5,\n  \"text\": \"Requirement text\",\n  \"applicability\": [\"all\"]\n}",
+			definition: "#AssessmentRequirement",
+			wantValid:  false,
+			wantLine:   2,
+		},
+		{
+			name:       "malformed JSON",
+			format:     "json",
+			content:    `{"id": `,
+			definition: "#AssessmentRequirement",
+			wantValid:  false,
+		},
+		{
+			name:       "valid CUE",
+			format:     "cue",
+			content:    "id:            \"TST.C01.TR01\"\ntext:          \"Requirement text\"\napplicability: [\"all\"]\n",
+			definition: "#AssessmentRequirement",
+			wantValid:  true,
+		},
+		{
+			name:       "incomplete CUE",
+			format:     "cue",
+			content:    "id:            string\ntext:          \"Requirement text\"\napplicability: [\"all\"]\n",
+			definition: "#AssessmentRequirement",
+			wantValid:  false,
+		},
+		{
+			name:       "CUE subtree",
+			format:     "cue",
+			content:    "metadata: id: \"TEST\"\ncontrols: [{id: \"TST.C01\", family: \"fam\", title: \"Control\", objective: \"Objective\", \"assessment-requirements\": []}]\n",
+			definition: "#ControlCatalog",
+			path:       "$.controls[0]",
+			wantValid:  true,
+		},
+		{
+			name:       "detected from CUE",
+			format:     "cue",
+			content:    "id:            \"TST.C01.TR01\"\ntext:          \"Requirement text\"\napplicability: [\"all\"]\n",
+			definition: definitionAuto,
+			wantValid:  true,
+		},
+		{
+			name:        "unsupported format",
+			format:      "toml",
+			content:     "id = 1",
+			definition:  "#AssessmentRequirement",
+			errContains: "unsupported artifact_format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, output, err := ValidateGemaraArtifact(context.Background(), nil, InputValidateGemaraArtifact{
+				ArtifactContent: tt.content,
+				ArtifactFormat:  tt.format,
+				Definition:      tt.definition,
+				Path:            tt.path,
+			})
+			if tt.errContains != "" {
+				require.Error(t, err, "should return error")
+				assert.Contains(t, err.Error(), tt.errContains, "error should contain expected message")
+				return
+			}
+			require.NoError(t, err, "should not return error")
+			assert.Equal(t, tt.wantValid, output.Valid, "valid status should match: %v", output.Errors)
+			if tt.wantLine > 0 {
+				require.NotEmpty(t, output.Diagnostics, "should report diagnostics")
+				assert.Equal(t, tt.wantLine, output.Diagnostics[0].Line, "should report the line")
+			}
+		})
+	}
+}
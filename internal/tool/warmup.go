@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WarmupReport describes what Warmup loaded and how long each step took.
+type WarmupReport struct {
+	SchemaVersion  string
+	SchemaDuration time.Duration
+	// SchemaFallback is set when the registry could not be reached and the
+	// schema embedded in the binary was loaded instead.
+	SchemaFallback  bool
+	LexiconSource   string
+	LexiconEntries  int
+	LexiconDuration time.Duration
+	// LexiconStale is set when the lexicon could not be fetched and a stale
+	// copy or the embedded snapshot was loaded instead.
+	LexiconStale bool
+}
+
+// Warmup resolves the Gemara module, compiles the schema, and fetches the
+// lexicon so that the first tool call of a session does not pay for them.
+// The compiled schema is cached for the first call to borrow. Resolved modules
+// stay in the local CUE module cache and fetched documents are persisted to
+// the configured Storage, if any, so later processes benefit as well.
+func Warmup(ctx context.Context) (WarmupReport, error) {
+	var report WarmupReport
+	ctx, release := withSchemaLease(ctx)
+	defer release()
+
+	start := time.Now()
+	schema, err := loadSchema(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to load Gemara schema: %w", err)
+	}
+	report.SchemaVersion = schema.version
+	report.SchemaDuration = time.Since(start)
+	report.SchemaFallback = schema.snapshot
+
+	start = time.Now()
+	_, lexicon, err := GetLexicon(ctx, nil, InputGetLexicon{})
+	if err != nil {
+		return report, fmt.Errorf("failed to load lexicon: %w", err)
+	}
+	report.LexiconSource = lexicon.Source
+	report.LexiconEntries = len(lexicon.Entries)
+	report.LexiconDuration = time.Since(start)
+	report.LexiconStale = lexicon.Stale
+	return report, nil
+}
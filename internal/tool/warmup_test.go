@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarmup(t *testing.T) {
+	useTestSchema(t)
+	lexicon := useTestLexicon(t, "- term: Control\n  definition: A safeguard.\n- term: Policy\n  definition: Rules.\n")
+
+	report, err := Warmup(context.Background())
+	require.NoError(t, err, "should warm up")
+	assert.Equal(t, testSchemaVersion, report.SchemaVersion, "should report the schema version")
+	assert.Equal(t, 2, report.LexiconEntries, "should report the lexicon size")
+	assert.False(t, report.LexiconStale, "lexicon should be fresh")
+	assert.True(t, lexicon.fresh(), "lexicon should be cached for the first call")
+}
+
+func TestLoadGemaraSchemaReusesResolution(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("test-data", "schema.cue"))
+	require.NoError(t, err, "should read test schema")
+	dir := t.TempDir()
+	writeTestFile(t, dir, "schema.cue", string(content))
+
+	originalRoot, originalVersion, originalTime := resolvedSchemaRoot, resolvedSchemaVersion, resolvedSchemaTime
+	t.Cleanup(func() {
+		resolvedSchemaRoot, resolvedSchemaVersion, resolvedSchemaTime = originalRoot, originalVersion, originalTime
+	})
+	resolvedSchemaRoot, resolvedSchemaVersion, resolvedSchemaTime = dir, "v9.9.9", time.Now()
+
+	schema, err := loadGemaraSchema(context.Background())
+	require.NoError(t, err, "should build the resolved module without the registry")
+	assert.Equal(t, "v9.9.9", schema.version, "should keep the resolved version")
+	_, err = schema.lookupDefinition("#ControlCatalog")
+	assert.NoError(t, err, "should compile the schema")
+}
+
+func TestSchemaCacheLendsSchemas(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("test-data", "schema.cue"))
+	require.NoError(t, err, "should read test schema")
+	dir := t.TempDir()
+	writeTestFile(t, dir, "schema.cue", string(content))
+
+	originalRoot, originalVersion, originalTime := resolvedSchemaRoot, resolvedSchemaVersion, resolvedSchemaTime
+	t.Cleanup(func() {
+		resolvedSchemaRoot, resolvedSchemaVersion, resolvedSchemaTime = originalRoot, originalVersion, originalTime
+	})
+	resolvedSchemaRoot, resolvedSchemaVersion, resolvedSchemaTime = dir, "v9.9.9", time.Now()
+	useTestLexicon(t, "- term: Control\n  definition: A safeguard.\n")
+
+	_, err = Warmup(context.Background())
+	require.NoError(t, err, "should warm up")
+	warmed := cachedSchema(dir, "v9.9.9")
+	require.NotNil(t, warmed, "warmup should cache the compiled schema")
+	releaseSchema(warmed)
+
+	ctx, release := withSchemaLease(context.Background())
+	first, err := loadSchema(ctx)
+	require.NoError(t, err)
+	assert.Same(t, warmed, first, "the first call should borrow the warmed schema")
+	second, err := loadSchema(ctx)
+	require.NoError(t, err)
+	assert.NotSame(t, first, second, "a borrowed schema should not be lent again")
+	release()
+
+	ctx, release = withSchemaLease(context.Background())
+	again, err := loadSchema(ctx)
+	require.NoError(t, err)
+	assert.True(t, again == first || again == second, "released schemas should be lent again")
+	abandonSchemas(ctx)
+	release()
+	for schema := cachedSchema(dir, "v9.9.9"); schema != nil; schema = cachedSchema(dir, "v9.9.9") {
+		assert.NotSame(t, again, schema, "an abandoned schema should not be cached")
+	}
+}
@@ -0,0 +1,294 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultWatchInterval is how often the workspace is re-scanned for changes
+// that no file system event reported.
+const DefaultWatchInterval = 30 * time.Second
+
+// watchSettleDelay is how long the watcher waits after a file system event
+// for further events before scanning, so that a burst of writes is scanned once.
+var watchSettleDelay = 200 * time.Millisecond
+
+// eventWorkspaceChanged is the server event reported when workspace artifacts change.
+const eventWorkspaceChanged = "workspace_changed"
+
+// WorkspaceWatcher exposes the Gemara artifacts under the workspace root, or under
+// the workspace root of a tenant, as resources and notifies clients when they
+// change. File system events trigger a scan, which compares files by size and
+// modification time; the workspace is also re-scanned on an interval for
+// network mounts and other file systems that deliver no events.
+type WorkspaceWatcher struct {
+	server *mcp.Server
+	// tenant is nil when the server is not shared.
+	tenant *Tenant
+	files  map[string]watchedFile
+}
+
+type watchedFile struct {
+	size     int64
+	modified time.Time
+	// artifact is the detected kind, or empty for YAML that is not a Gemara artifact.
+	artifact string
+}
+
+// NewWorkspaceWatcher creates a watcher that registers workspace resources on
+// server. With a tenant, the server is the tenant's and the tenant's workspace
+// is watched.
+func NewWorkspaceWatcher(server *mcp.Server, t *Tenant) *WorkspaceWatcher {
+	return &WorkspaceWatcher{server: server, tenant: t, files: map[string]watchedFile{}}
+}
+
+// context returns ctx serving the tenant of the watcher.
+func (w *WorkspaceWatcher) context(ctx context.Context) context.Context {
+	if w.tenant != nil {
+		return withTenant(ctx, w.tenant)
+	}
+	return ctx
+}
+
+// Run scans the workspace when a file system event reports a change and
+// every interval until ctx is cancelled. The first scan registers a resource
+// for every artifact already present.
+func (w *WorkspaceWatcher) Run(ctx context.Context, interval time.Duration) {
+	ctx = w.context(ctx)
+	// Directories are watched before the first scan so no change falls between them
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	notifier, err := fsnotify.NewWatcher()
+	if err != nil {
+		emitEvent(ctx, "warning", eventWorkspaceChanged, "file system events are unavailable; re-scanning on the watch interval only", map[string]interface{}{"error": err.Error()})
+	} else {
+		defer notifier.Close()
+		watchDirectories(notifier, workspaceRoot(ctx))
+		events, errs = notifier.Events, notifier.Errors
+	}
+
+	// Posture webhooks report changes relative to the posture at startup
+	emitPostureWebhook(ctx)
+	w.Scan(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	settle := time.NewTimer(watchSettleDelay)
+	settle.Stop()
+	defer settle.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Scan(ctx)
+		case <-settle.C:
+			w.Scan(ctx)
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			// New directories are watched too, as events are not recursive
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() && !skipDirectory(info.Name()) {
+					watchDirectories(notifier, event.Name)
+				}
+			}
+			settle.Reset(watchSettleDelay)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			emitEvent(ctx, "warning", eventWorkspaceChanged, "file system events were lost; changes are found by the next scan", map[string]interface{}{"error": err.Error()})
+		}
+	}
+}
+
+// watchDirectories adds dir and the directories below it that can hold
+// artifacts to notifier. Directories that cannot be watched are left to the
+// interval scan.
+func watchDirectories(notifier *fsnotify.Watcher, dir string) {
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != dir && skipDirectory(d.Name()) {
+			return filepath.SkipDir
+		}
+		_ = notifier.Add(path)
+		return nil
+	})
+}
+
+// Scan compares the workspace with the previous scan. New and removed
+// artifacts are added to and removed from the resource list, which notifies
+// clients that the list changed; subscribers of a modified artifact, and of the
+// posture resource, are sent resources/updated.
+func (w *WorkspaceWatcher) Scan(ctx context.Context) {
+	ctx = w.context(ctx)
+	root := workspaceRoot(ctx)
+	paths, err := findArtifactFiles(root)
+	if err != nil {
+		emitEvent(ctx, "warning", eventWorkspaceChanged, "failed to scan workspace", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	var added, removed, updated []string
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		seen[path] = true
+		previous, known := w.files[path]
+		if known && previous.size == info.Size() && previous.modified.Equal(info.ModTime()) {
+			continue
+		}
+
+		file := watchedFile{size: info.Size(), modified: info.ModTime(), artifact: w.detect(ctx, path)}
+		w.files[path] = file
+		switch {
+		case file.artifact != "" && (!known || previous.artifact == ""):
+			added = append(added, path)
+		case file.artifact == "" && known && previous.artifact != "":
+			removed = append(removed, path)
+		case file.artifact != "":
+			updated = append(updated, path)
+		}
+	}
+	for path, file := range w.files {
+		if !seen[path] {
+			delete(w.files, path)
+			if file.artifact != "" {
+				removed = append(removed, path)
+			}
+		}
+	}
+	if len(added)+len(removed)+len(updated) == 0 {
+		return
+	}
+	sort.Strings(removed)
+
+	for _, path := range added {
+		w.server.AddResource(workspaceResource(root, path, w.files[path].artifact), HandleWorkspaceResource)
+	}
+	if len(removed) > 0 {
+		uris := make([]string, 0, len(removed))
+		for _, path := range removed {
+			uris = append(uris, fileURL(path))
+		}
+		w.server.RemoveResources(uris...)
+	}
+	for _, path := range updated {
+		_ = w.server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: fileURL(path)})
+	}
+	_ = w.server.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: PostureResourceURI})
+	emitPostureWebhook(ctx)
+
+	emitEvent(ctx, "debug", eventWorkspaceChanged, fmt.Sprintf("workspace artifacts changed: %d added, %d removed, %d modified", len(added), len(removed), len(updated)), map[string]interface{}{
+		"added":    len(added),
+		"removed":  len(removed),
+		"modified": len(updated),
+	})
+}
+
+// detect returns the artifact kind of a workspace file, or an empty string
+// when it cannot be read or is not a Gemara artifact.
+func (w *WorkspaceWatcher) detect(ctx context.Context, path string) string {
+	content, err := readArtifactFile(ctx, path)
+	if err != nil {
+		return ""
+	}
+	doc, err := parseArtifact(ctx, string(content))
+	if err != nil {
+		return ""
+	}
+	return artifactKind(doc)
+}
+
+// workspaceResource describes an artifact of the workspace at root.
+func workspaceResource(root, path, kind string) *mcp.Resource {
+	name, err := filepath.Rel(root, path)
+	if err != nil {
+		name = path
+	}
+	return &mcp.Resource{
+		Name:        filepath.ToSlash(name),
+		URI:         fileURL(path),
+		Title:       fmt.Sprintf("%s %s", kind, filepath.Base(path)),
+		Description: fmt.Sprintf("Gemara %s in the workspace", kind),
+		MIMEType:    "application/yaml",
+	}
+}
+
+// HandleWorkspaceResource reads a workspace artifact.
+func HandleWorkspaceResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	u, err := url.Parse(req.Params.URI)
+	if err != nil || u.Scheme != "file" {
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
+	content, err := readWorkspaceFile(ctx, u.Path)
+	if err != nil {
+		return nil, err
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{
+				URI:      req.Params.URI,
+				MIMEType: "application/yaml",
+				Text:     string(content),
+			},
+		},
+	}, nil
+}
+
+// subscriptions counts the subscriptions to each resource URI, so that
+// background work can be limited to resources someone is watching.
+var subscriptions = struct {
+	sync.Mutex
+	uris map[string]int
+}{uris: map[string]int{}}
+
+// SubscribeResource accepts subscriptions to resource updates. Updates are
+// sent for resources the WorkspaceWatcher tracks and for federated catalogs
+// and the lexicon when the Refresher finds that they changed.
+func SubscribeResource(_ context.Context, req *mcp.SubscribeRequest) error {
+	subscriptions.Lock()
+	defer subscriptions.Unlock()
+	subscriptions.uris[req.Params.URI]++
+	return nil
+}
+
+// UnsubscribeResource accepts the cancellation of a resource subscription.
+func UnsubscribeResource(_ context.Context, req *mcp.UnsubscribeRequest) error {
+	subscriptions.Lock()
+	defer subscriptions.Unlock()
+	if subscriptions.uris[req.Params.URI] <= 1 {
+		delete(subscriptions.uris, req.Params.URI)
+	} else {
+		subscriptions.uris[req.Params.URI]--
+	}
+	return nil
+}
+
+// subscribed reports whether any client is subscribed to uri.
+func subscribed(uri string) bool {
+	subscriptions.Lock()
+	defer subscriptions.Unlock()
+	return subscriptions.uris[uri] > 0
+}
@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listResourceURIs returns the URIs of the resources the server lists.
+func listResourceURIs(t *testing.T, session *mcp.ClientSession) []string {
+	t.Helper()
+	result, err := session.ListResources(context.Background(), nil)
+	require.NoError(t, err, "should list resources")
+	var uris []string
+	for _, r := range result.Resources {
+		uris = append(uris, r.URI)
+	}
+	return uris
+}
+
+// receiveUpdates collects the URIs of resources/updated notifications until none arrive for a moment.
+func receiveUpdates(updates <-chan string) []string {
+	var uris []string
+	for {
+		select {
+		case uri := <-updates:
+			uris = append(uris, uri)
+		case <-time.After(100 * time.Millisecond):
+			return uris
+		}
+	}
+}
+
+func TestWorkspaceWatcher(t *testing.T) {
+	dir := t.TempDir()
+	ctx := withTestConfig(func(c *Config) { c.WorkspaceRoot = dir })
+
+	catalog, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err, "should read test catalog")
+	log, err := os.ReadFile(filepath.Join("test-data", "evaluation-log.yaml"))
+	require.NoError(t, err, "should read test evaluation log")
+	writeTestFile(t, dir, "catalog.yaml", string(catalog))
+	writeTestFile(t, dir, "notes.yaml", "owner: security\n")
+	writeTestFile(t, dir, "vendor/catalog.yaml", string(catalog))
+	writeTestFile(t, dir, ".git/catalog.yaml", string(catalog))
+	catalogURI := fileURL(filepath.Join(dir, "catalog.yaml"))
+	logURI := fileURL(filepath.Join(dir, "logs", "eval.yaml"))
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server"}, &mcp.ServerOptions{
+		SubscribeHandler:   SubscribeResource,
+		UnsubscribeHandler: UnsubscribeResource,
+	})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err = server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err, "server should connect")
+
+	updates := make(chan string, 10)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, &mcp.ClientOptions{
+		ResourceUpdatedHandler: func(_ context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			updates <- req.Params.URI
+		},
+	})
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err, "client should connect")
+	t.Cleanup(func() { _ = session.Close() })
+
+	watcher := NewWorkspaceWatcher(server, nil)
+	watcher.Scan(ctx)
+	assert.Equal(t, []string{catalogURI}, listResourceURIs(t, session), "only Gemara artifacts outside vendored and hidden directories should be listed")
+
+	read, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: catalogURI})
+	require.NoError(t, err, "should read workspace artifact")
+	assert.Equal(t, string(catalog), read.Contents[0].Text)
+
+	require.NoError(t, session.Subscribe(ctx, &mcp.SubscribeParams{URI: catalogURI}))
+	require.NoError(t, session.Subscribe(ctx, &mcp.SubscribeParams{URI: PostureResourceURI}))
+
+	// An unchanged workspace sends nothing
+	watcher.Scan(ctx)
+	assert.Empty(t, receiveUpdates(updates))
+
+	// A new evaluation log is listed and updates the posture
+	writeTestFile(t, dir, "logs/eval.yaml", string(log))
+	watcher.Scan(ctx)
+	assert.ElementsMatch(t, []string{catalogURI, logURI}, listResourceURIs(t, session))
+	assert.Equal(t, []string{PostureResourceURI}, receiveUpdates(updates))
+
+	// Modifying the subscribed catalog notifies its subscribers
+	writeTestFile(t, dir, "catalog.yaml", string(catalog)+"# reviewed\n")
+	watcher.Scan(ctx)
+	assert.ElementsMatch(t, []string{catalogURI, PostureResourceURI}, receiveUpdates(updates))
+
+	// Removed artifacts leave the list
+	require.NoError(t, os.Remove(filepath.Join(dir, "logs", "eval.yaml")))
+	watcher.Scan(ctx)
+	assert.Equal(t, []string{catalogURI}, listResourceURIs(t, session))
+	assert.Equal(t, []string{PostureResourceURI}, receiveUpdates(updates))
+}
+
+func TestWorkspaceWatcherEvents(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(withTestConfig(func(c *Config) { c.WorkspaceRoot = dir }))
+	t.Cleanup(cancel)
+	catalog, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err, "should read test catalog")
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server"}, nil)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	_, err = server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err, "server should connect")
+	session, err := mcp.NewClient(&mcp.Implementation{Name: "test-client"}, nil).Connect(ctx, clientTransport, nil)
+	require.NoError(t, err, "client should connect")
+	t.Cleanup(func() { _ = session.Close() })
+
+	writeTestFile(t, dir, "catalog.yaml", string(catalog))
+	go NewWorkspaceWatcher(server, nil).Run(ctx, time.Hour)
+	require.Eventually(t, func() bool { return len(listResourceURIs(t, session)) == 1 }, 5*time.Second, 10*time.Millisecond, "the first scan should list the catalog")
+
+	// The interval is too long to find the change, so only an event can
+	writeTestFile(t, dir, "controls/catalog.yaml", string(catalog))
+	uri := fileURL(filepath.Join(dir, "controls", "catalog.yaml"))
+	assert.Eventually(t, func() bool {
+		return slices.Contains(listResourceURIs(t, session), uri)
+	}, 5*time.Second, 20*time.Millisecond, "a file written to a new directory should be found without waiting for the interval")
+}
+
+func TestHandleWorkspaceResourceOutsideWorkspace(t *testing.T) {
+	ctx := withTestConfig(func(c *Config) { c.WorkspaceRoot = t.TempDir() })
+
+	outside := filepath.Join(t.TempDir(), "catalog.yaml")
+	require.NoError(t, os.WriteFile(outside, []byte("controls: []\n"), 0o600))
+	_, err := HandleWorkspaceResource(ctx, &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: fileURL(outside)}})
+	require.Error(t, err)
+}
@@ -0,0 +1,381 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Webhook event types, sent in the "event" field of the payload and the
+// X-Gemara-Event header.
+const (
+	WebhookArtifactValidated = "artifact.validated"
+	WebhookValidationFailed  = "artifact.validation_failed"
+	WebhookPostureChanged    = "posture.changed"
+)
+
+// DefaultWebhookTimeout bounds each webhook delivery attempt by default.
+const DefaultWebhookTimeout = 10 * time.Second
+
+const (
+	// eventWebhookFailed is the server event reported when a delivery gives up.
+	eventWebhookFailed     = "webhook_failed"
+	webhookSignatureHeader = "X-Gemara-Signature-256"
+	webhookEventHeader     = "X-Gemara-Event"
+	webhookDeliveryHeader  = "X-Gemara-Delivery"
+	webhookAttempts        = 3
+)
+
+// webhookRetryDelay is the delay before the first retry; it grows linearly.
+var webhookRetryDelay = time.Second
+
+// WebhookEvents lists the supported webhook event types.
+var WebhookEvents = []string{WebhookArtifactValidated, WebhookValidationFailed, WebhookPostureChanged}
+
+// WebhookConfig configures where server events are POSTed.
+type WebhookConfig struct {
+	// URLs receive every subscribed event. Webhooks are disabled when empty.
+	URLs []string
+	// Events are the subscribed event types; empty subscribes to all of them.
+	Events []string
+	// Secret signs each payload with HMAC-SHA256, sent as
+	// "sha256=<hex>" in the X-Gemara-Signature-256 header.
+	Secret string
+	// Timeout bounds each delivery attempt.
+	Timeout time.Duration
+}
+
+// webhookDeliveries tracks deliveries in flight so shutdown can wait for them.
+var webhookDeliveries sync.WaitGroup
+
+// webhookPayload is the JSON body of a webhook request.
+type webhookPayload struct {
+	ID    string                 `json:"id"`
+	Event string                 `json:"event"`
+	Time  string                 `json:"time"`
+	Data  map[string]interface{} `json:"data"`
+}
+
+// Validate checks the webhook URLs and event types.
+func (c WebhookConfig) Validate() error {
+	for _, raw := range c.URLs {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook URL %q: must be an http or https URL", raw)
+		}
+	}
+	for _, event := range c.Events {
+		if !slices.Contains(WebhookEvents, event) {
+			return fmt.Errorf("unknown webhook event %q (available: %s)", event, strings.Join(WebhookEvents, ", "))
+		}
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("webhook timeout must be positive")
+	}
+	return nil
+}
+
+// subscribed reports whether any webhook receives event.
+func (c WebhookConfig) subscribed(event string) bool {
+	return len(c.URLs) > 0 && (len(c.Events) == 0 || slices.Contains(c.Events, event))
+}
+
+// emitWebhook POSTs event to every webhook in the background. Deliveries are
+// best effort: failures are retried a few times and then reported as a server
+// event, never to the caller.
+func emitWebhook(ctx context.Context, event string, data map[string]interface{}) {
+	config := serverConfig(ctx).Webhooks
+	if !config.subscribed(event) {
+		return
+	}
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	payload := webhookPayload{
+		ID:    hex.EncodeToString(id),
+		Event: event,
+		Time:  time.Now().UTC().Format(time.RFC3339),
+		Data:  data,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+
+	client := newHTTPClient()
+	// Deliveries outlive the request that triggered them
+	ctx = context.WithoutCancel(ctx)
+	for _, target := range config.URLs {
+		webhookDeliveries.Add(1)
+		go func(target string) {
+			defer webhookDeliveries.Done()
+			if err := deliverWebhook(ctx, client, config, target, payload, body); err != nil {
+				emitEvent(ctx, "warning", eventWebhookFailed, fmt.Sprintf("failed to deliver %s webhook", event), map[string]interface{}{
+					"url":   target,
+					"error": err.Error(),
+				})
+			}
+		}(target)
+	}
+}
+
+// deliverWebhook sends one payload, retrying network errors and 5xx and 429
+// responses.
+func deliverWebhook(ctx context.Context, client *http.Client, config WebhookConfig, target string, payload webhookPayload, body []byte) error {
+	var err error
+	for attempt := 0; attempt < webhookAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(webhookRetryDelay * time.Duration(attempt))
+		}
+		var retry bool
+		retry, err = postWebhook(ctx, client, config, target, payload, body)
+		if err == nil || !retry {
+			return err
+		}
+	}
+	return err
+}
+
+func postWebhook(ctx context.Context, client *http.Client, config WebhookConfig, target string, payload webhookPayload, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gemara-mcp")
+	req.Header.Set(webhookEventHeader, payload.Event)
+	req.Header.Set(webhookDeliveryHeader, payload.ID)
+	if config.Secret != "" {
+		req.Header.Set(webhookSignatureHeader, signWebhook(config.Secret, body))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return retry, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return false, nil
+}
+
+// signWebhook returns the signature header value of body, the hex HMAC-SHA256
+// of the exact request body, like GitHub's X-Hub-Signature-256.
+func signWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WaitWebhooks waits for webhook deliveries in flight, returning ctx.Err()
+// if ctx is done first.
+func WaitWebhooks(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		webhookDeliveries.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// emitValidationWebhook reports the outcome of validating an artifact against definition.
+func emitValidationWebhook(ctx context.Context, definition, uri string, output OutputValidateGemaraArtifact) {
+	event := WebhookArtifactValidated
+	if !output.Valid {
+		event = WebhookValidationFailed
+	}
+	data := map[string]interface{}{
+		"definition": definition,
+		"valid":      output.Valid,
+		"errors":     output.Errors,
+		"message":    output.Message,
+	}
+	if uri != "" {
+		data["artifact_uri"] = uri
+	}
+	emitWebhook(ctx, event, data)
+}
+
+var (
+	webhookPostureMu sync.Mutex
+	// webhookPostureSeen is the last posture reported for each workspace root.
+	webhookPostureSeen map[string]map[string]CatalogPosture
+)
+
+// emitPostureWebhook recomputes the posture of the workspace of ctx and
+// reports every catalog whose results changed since the previous call for
+// that workspace. The first call only records the baseline.
+func emitPostureWebhook(ctx context.Context) {
+	root := workspaceRoot(ctx)
+	if !serverConfig(ctx).Webhooks.subscribed(WebhookPostureChanged) || root == "" {
+		return
+	}
+	files, err := findArtifactFiles(root)
+	if err != nil {
+		return
+	}
+	posture := computePosture(ctx, root, files)
+	current := make(map[string]CatalogPosture, len(posture.Catalogs))
+	for _, c := range posture.Catalogs {
+		current[c.Catalog] = c
+	}
+
+	webhookPostureMu.Lock()
+	previous := webhookPostureSeen[root]
+	if previous == nil {
+		previous = loadPostureHistory(ctx, root)
+	}
+	if webhookPostureSeen == nil {
+		webhookPostureSeen = map[string]map[string]CatalogPosture{}
+	}
+	webhookPostureSeen[root] = current
+	webhookPostureMu.Unlock()
+	if previous == nil {
+		savePostureHistory(ctx, root, current)
+		return
+	}
+
+	// With privacy enabled, changed catalogs are reported as the posture
+	// resource exports them: noisy compliance of the catalogs evaluated by
+	// enough logs, without failing controls, previous values, or the path
+	// of the workspace
+	privacy := serverConfig(ctx).Privacy
+	var changes []map[string]interface{}
+	suppressed := 0
+	for _, c := range posture.Catalogs {
+		before, known := previous[c.Catalog]
+		if known && postureSummaryEqual(before, c) {
+			continue
+		}
+		if privacy.enabled() {
+			exported, ok := privacy.catalog(c)
+			if !ok {
+				suppressed++
+				continue
+			}
+			changes = append(changes, map[string]interface{}{
+				"catalog":            exported.Catalog,
+				"compliance_percent": exported.CompliancePercent,
+			})
+			continue
+		}
+		change := map[string]interface{}{
+			"catalog":            c.Catalog,
+			"compliance_percent": c.CompliancePercent,
+			"failing_controls":   postureControlIDs(c.FailingControls),
+		}
+		if known {
+			change["previous_compliance_percent"] = before.CompliancePercent
+			change["previous_failing_controls"] = postureControlIDs(before.FailingControls)
+		}
+		changes = append(changes, change)
+	}
+	for catalog, before := range previous {
+		// Removed catalogs can no longer be checked against the cohort
+		if _, ok := current[catalog]; !ok && !privacy.enabled() {
+			changes = append(changes, map[string]interface{}{
+				"catalog":                     catalog,
+				"removed":                     true,
+				"previous_compliance_percent": before.CompliancePercent,
+			})
+		}
+	}
+	if len(changes) == 0 {
+		return
+	}
+	savePostureHistory(ctx, root, current)
+	data := map[string]interface{}{
+		"catalogs": changes,
+	}
+	if privacy.enabled() {
+		if suppressed > 0 {
+			data["suppressed_catalogs"] = suppressed
+		}
+	} else {
+		data["workspace"] = posture.Workspace
+	}
+	if t := requestTenant(ctx); t != nil {
+		data["tenant"] = t.ID
+	}
+	emitWebhook(ctx, WebhookPostureChanged, data)
+}
+
+// postureHistoryKey returns the storage key of the last posture reported for
+// a workspace.
+func postureHistoryKey(root string) string {
+	sum := sha256.Sum256([]byte(root))
+	return "history/posture/" + hex.EncodeToString(sum[:]) + ".json"
+}
+
+// loadPostureHistory returns the last posture reported for root from the
+// configured storage, so changes made while the server was down are still
+// reported after a restart.
+func loadPostureHistory(ctx context.Context, root string) map[string]CatalogPosture {
+	storage := serverConfig(ctx).Storage
+	if storage == nil {
+		return nil
+	}
+	value, err := storage.Get(ctx, postureHistoryKey(root))
+	if err != nil {
+		if !errors.Is(err, ErrStorageNotFound) {
+			emitUpstreamFailure(ctx, "history storage", err)
+		}
+		return nil
+	}
+	var seen map[string]CatalogPosture
+	if err := json.Unmarshal(value, &seen); err != nil {
+		return nil
+	}
+	return seen
+}
+
+// savePostureHistory records the posture reported for root in the configured storage.
+func savePostureHistory(ctx context.Context, root string, seen map[string]CatalogPosture) {
+	storage := serverConfig(ctx).Storage
+	if storage == nil {
+		return
+	}
+	value, err := json.Marshal(seen)
+	if err == nil {
+		err = storage.Put(ctx, postureHistoryKey(root), value)
+	}
+	if err != nil {
+		emitUpstreamFailure(ctx, "history storage", err)
+	}
+}
+
+// postureSummaryEqual reports whether two catalog postures have the same results.
+func postureSummaryEqual(a, b CatalogPosture) bool {
+	return a.Passed == b.Passed && a.Failed == b.Failed && a.NeedsReview == b.NeedsReview &&
+		a.NotApplicable == b.NotApplicable && slices.Equal(postureControlIDs(a.FailingControls), postureControlIDs(b.FailingControls))
+}
+
+func postureControlIDs(controls []ControlPosture) []string {
+	ids := make([]string, 0, len(controls))
+	for _, c := range controls {
+		ids = append(ids, c.Control)
+	}
+	return ids
+}
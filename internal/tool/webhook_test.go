@@ -0,0 +1,250 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// webhookRecorder is a webhook endpoint that records what it receives.
+type webhookRecorder struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	payloads []webhookPayload
+	bodies   [][]byte
+	// failures is how many requests to answer with a 500 first
+	failures int
+}
+
+func (r *webhookRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failures > 0 {
+		r.failures--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	var payload webhookPayload
+	_ = json.Unmarshal(body, &payload)
+	r.requests = append(r.requests, req)
+	r.payloads = append(r.payloads, payload)
+	r.bodies = append(r.bodies, body)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// useWebhooks returns a context whose webhooks are delivered to a recording
+// server for the duration of the test.
+func useWebhooks(t *testing.T, config WebhookConfig) (context.Context, *webhookRecorder) {
+	t.Helper()
+	require.NoError(t, useHTTPConfig(t, HTTPConfig{}))
+	recorder := &webhookRecorder{}
+	server := httptest.NewServer(recorder)
+	t.Cleanup(server.Close)
+
+	originalDelay := webhookRetryDelay
+	t.Cleanup(func() { webhookRetryDelay = originalDelay })
+	config.URLs = append(config.URLs, server.URL)
+	if config.Timeout == 0 {
+		config.Timeout = DefaultWebhookTimeout
+	}
+	webhookRetryDelay = time.Millisecond
+	return withTestConfig(func(c *Config) { c.Webhooks = config }), recorder
+}
+
+func waitWebhooks(t *testing.T) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, WaitWebhooks(ctx), "webhook deliveries should finish")
+}
+
+func TestValidateWebhooks(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  WebhookConfig
+		wantErr string
+	}{
+		{name: "disabled", config: WebhookConfig{Timeout: time.Second}},
+		{name: "valid", config: WebhookConfig{URLs: []string{"https://hooks.example.com/gemara"}, Events: []string{WebhookPostureChanged}, Timeout: time.Second}},
+		{name: "not http", config: WebhookConfig{URLs: []string{"ftp://hooks.example.com"}, Timeout: time.Second}, wantErr: "invalid webhook URL"},
+		{name: "unknown event", config: WebhookConfig{Events: []string{"artifact.deleted"}, Timeout: time.Second}, wantErr: "unknown webhook event"},
+		{name: "no timeout", config: WebhookConfig{}, wantErr: "timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEmitWebhook(t *testing.T) {
+	ctx, recorder := useWebhooks(t, WebhookConfig{Secret: "s3cret", Events: []string{WebhookValidationFailed}})
+	recorder.failures = 1
+
+	emitWebhook(ctx, WebhookArtifactValidated, map[string]interface{}{"valid": true})
+	emitWebhook(ctx, WebhookValidationFailed, map[string]interface{}{"valid": false})
+	waitWebhooks(t)
+
+	require.Len(t, recorder.payloads, 1, "only subscribed events should be delivered, after retrying the failure")
+	req, payload := recorder.requests[0], recorder.payloads[0]
+	assert.Equal(t, WebhookValidationFailed, payload.Event)
+	assert.Equal(t, false, payload.Data["valid"])
+	assert.NotEmpty(t, payload.ID)
+	assert.Equal(t, payload.ID, req.Header.Get(webhookDeliveryHeader))
+	assert.Equal(t, WebhookValidationFailed, req.Header.Get(webhookEventHeader))
+	assert.Equal(t, signWebhook("s3cret", recorder.bodies[0]), req.Header.Get(webhookSignatureHeader), "payload should be signed")
+}
+
+func TestValidateGemaraArtifactWebhook(t *testing.T) {
+	useTestSchema(t)
+	ctx, recorder := useWebhooks(t, WebhookConfig{})
+
+	content, err := os.ReadFile(filepath.Join("test-data", "good-ccc.yaml"))
+	require.NoError(t, err)
+	for _, c := range []string{string(content), "title: Broken\n"} {
+		_, _, err := ValidateGemaraArtifact(ctx, nil, InputValidateGemaraArtifact{
+			ArtifactContent: c,
+			Definition:      "ControlCatalog",
+		})
+		require.NoError(t, err)
+	}
+	waitWebhooks(t)
+
+	require.Len(t, recorder.payloads, 2)
+	events := map[string]webhookPayload{}
+	for _, p := range recorder.payloads {
+		events[p.Event] = p
+	}
+	require.Contains(t, events, WebhookArtifactValidated)
+	require.Contains(t, events, WebhookValidationFailed)
+	assert.Equal(t, "#ControlCatalog", events[WebhookArtifactValidated].Data["definition"])
+	assert.NotEmpty(t, events[WebhookValidationFailed].Data["errors"])
+}
+
+func TestEmitPostureWebhook(t *testing.T) {
+	ctx, recorder := useWebhooks(t, WebhookConfig{Events: []string{WebhookPostureChanged}})
+	root := t.TempDir()
+	serverConfig(ctx).WorkspaceRoot = root
+	t.Cleanup(func() { webhookPostureSeen = nil })
+	webhookPostureSeen = nil
+
+	writeTestFile(t, root, "old.yaml", postureOldLog)
+	emitPostureWebhook(ctx)
+	emitPostureWebhook(ctx)
+	waitWebhooks(t)
+	assert.Empty(t, recorder.payloads, "the baseline and an unchanged posture should not be reported")
+
+	writeTestFile(t, root, "new.yaml", postureNewLog)
+	emitPostureWebhook(ctx)
+	waitWebhooks(t)
+
+	require.Len(t, recorder.payloads, 1)
+	catalogs, _ := recorder.payloads[0].Data["catalogs"].([]interface{})
+	changes := map[string]map[string]interface{}{}
+	for _, c := range catalogs {
+		change, _ := c.(map[string]interface{})
+		changes[change["catalog"].(string)] = change
+	}
+	require.Contains(t, changes, "FINOS-CCC")
+	assert.Equal(t, []interface{}{"CCC.C01"}, changes["FINOS-CCC"]["previous_failing_controls"])
+	assert.Equal(t, []interface{}{"CCC.C06"}, changes["FINOS-CCC"]["failing_controls"])
+	require.Contains(t, changes, "ORG-POL", "newly evaluated catalogs should be reported")
+	assert.NotContains(t, changes["ORG-POL"], "previous_compliance_percent")
+}
+
+func TestEmitPostureWebhookPrivacy(t *testing.T) {
+	ctx, recorder := useWebhooks(t, WebhookConfig{Events: []string{WebhookPostureChanged}})
+	root := t.TempDir()
+	serverConfig(ctx).WorkspaceRoot = root
+	serverConfig(ctx).Privacy = PrivacyConfig{MinCohort: 2}
+	t.Cleanup(func() { webhookPostureSeen = nil })
+	webhookPostureSeen = nil
+
+	writeTestFile(t, root, "logs/2025-01.yaml", postureOldLog)
+	emitPostureWebhook(ctx)
+	writeTestFile(t, root, "logs/2025-02.yaml", postureNewLog)
+	writeTestFile(t, root, "logs/other.yaml", privacyLog)
+	emitPostureWebhook(ctx)
+	waitWebhooks(t)
+
+	require.Len(t, recorder.payloads, 1)
+	data := recorder.payloads[0].Data
+	assert.NotContains(t, data, "workspace", "the workspace path should not be exported")
+	assert.EqualValues(t, 1, data["suppressed_catalogs"], "ORG-POL is evaluated by a single log")
+	catalogs, _ := data["catalogs"].([]interface{})
+	require.Len(t, catalogs, 1)
+	change, _ := catalogs[0].(map[string]interface{})
+	assert.Equal(t, "FINOS-CCC", change["catalog"])
+	assert.Contains(t, change, "compliance_percent")
+	assert.NotContains(t, change, "failing_controls")
+	assert.NotContains(t, change, "previous_compliance_percent")
+	assert.NotContains(t, string(recorder.bodies[0]), "CCC.C06", "no control should be named")
+}
+
+func TestEmitPostureWebhookHistory(t *testing.T) {
+	ctx, recorder := useWebhooks(t, WebhookConfig{Events: []string{WebhookPostureChanged}})
+	root := t.TempDir()
+	storage, err := OpenStorage(t.TempDir())
+	require.NoError(t, err)
+	serverConfig(ctx).WorkspaceRoot = root
+	serverConfig(ctx).Storage = storage
+	t.Cleanup(func() { webhookPostureSeen = nil })
+	webhookPostureSeen = nil
+
+	writeTestFile(t, root, "old.yaml", postureOldLog)
+	emitPostureWebhook(ctx)
+	_, err = storage.Get(ctx, postureHistoryKey(root))
+	require.NoError(t, err, "the baseline should be stored")
+
+	// A restart forgets the baseline in memory but not in storage
+	webhookPostureSeen = nil
+	writeTestFile(t, root, "new.yaml", postureNewLog)
+	emitPostureWebhook(ctx)
+	waitWebhooks(t)
+	require.Len(t, recorder.payloads, 1, "changes made while the server was down should be reported")
+	catalogs, _ := recorder.payloads[0].Data["catalogs"].([]interface{})
+	assert.Len(t, catalogs, 2)
+}
+
+func TestEmitPostureWebhookTenants(t *testing.T) {
+	ctx, recorder := useWebhooks(t, WebhookConfig{Events: []string{WebhookPostureChanged}})
+	t.Cleanup(func() { webhookPostureSeen = nil })
+	webhookPostureSeen = nil
+	alpha := &Tenant{ID: "alpha", WorkspaceRoot: t.TempDir()}
+	beta := &Tenant{ID: "beta", WorkspaceRoot: t.TempDir()}
+	writeTestFile(t, alpha.WorkspaceRoot, "old.yaml", postureOldLog)
+	writeTestFile(t, beta.WorkspaceRoot, "new.yaml", postureNewLog)
+
+	// Each tenant's workspace has its own baseline
+	emitPostureWebhook(withTenant(ctx, alpha))
+	emitPostureWebhook(withTenant(ctx, beta))
+	waitWebhooks(t)
+	assert.Empty(t, recorder.payloads, "a tenant's posture should not be compared with another's")
+
+	writeTestFile(t, alpha.WorkspaceRoot, "new.yaml", postureNewLog)
+	emitPostureWebhook(withTenant(ctx, alpha))
+	emitPostureWebhook(withTenant(ctx, beta))
+	waitWebhooks(t)
+	require.Len(t, recorder.payloads, 1)
+	assert.Equal(t, "alpha", recorder.payloads[0].Data["tenant"])
+}
@@ -0,0 +1,227 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package tool
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxXLSXColumns is the number of columns of a worksheet, A through XFD.
+	maxXLSXColumns = 16384
+	// maxXLSXCells bounds the cells read from a sheet, counting the empty
+	// cells that pad each row out to its last referenced column.
+	maxXLSXCells = 1 << 20
+)
+
+// xlsxWorkbook is the part of xl/workbook.xml listing the sheets.
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// xlsxRelationships maps relationship IDs to the parts they target.
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is rich or plain text, as in shared strings and inline strings.
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+// xlsxSharedStrings is xl/sharedStrings.xml.
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+// xlsxSheet is the cell data of a worksheet.
+type xlsxSheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSXRows reads the cell text of a sheet of an XLSX workbook, by name or
+// the first sheet when name is empty. Only the cell values are read; formulas
+// contribute their cached result. Each part of the workbook may decompress to
+// at most limit bytes.
+func readXLSXRows(content []byte, name string, limit int64) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open XLSX workbook: %w", err)
+	}
+	parts := map[string]*zip.File{}
+	for _, f := range archive.File {
+		parts[f.Name] = f
+	}
+
+	var workbook xlsxWorkbook
+	if err := readXLSXPart(parts, "xl/workbook.xml", &workbook, limit); err != nil {
+		return nil, err
+	}
+	var rels xlsxRelationships
+	if err := readXLSXPart(parts, "xl/_rels/workbook.xml.rels", &rels, limit); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, fmt.Errorf("XLSX workbook has no sheets")
+	}
+
+	sheet := workbook.Sheets[0]
+	if name != "" {
+		found := false
+		var names []string
+		for _, s := range workbook.Sheets {
+			names = append(names, s.Name)
+			if s.Name == name {
+				sheet, found = s, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("sheet %q not found (available: %s)", name, strings.Join(names, ", "))
+		}
+	}
+	var target string
+	for _, r := range rels.Relationships {
+		if r.ID == sheet.RID {
+			target = r.Target
+		}
+	}
+	if target == "" {
+		return nil, fmt.Errorf("XLSX workbook has no part for sheet %q", sheet.Name)
+	}
+	if strings.HasPrefix(target, "/") {
+		target = strings.TrimPrefix(target, "/")
+	} else {
+		target = path.Join("xl", target)
+	}
+
+	var shared xlsxSharedStrings
+	if _, ok := parts["xl/sharedStrings.xml"]; ok {
+		if err := readXLSXPart(parts, "xl/sharedStrings.xml", &shared, limit); err != nil {
+			return nil, err
+		}
+	}
+	var data xlsxSheet
+	if err := readXLSXPart(parts, target, &data, limit); err != nil {
+		return nil, err
+	}
+
+	rows := make([][]string, 0, len(data.Rows))
+	cells := 0
+	for _, r := range data.Rows {
+		var row []string
+		for i, c := range r.Cells {
+			column, err := xlsxColumn(c.Ref)
+			if err != nil {
+				return nil, err
+			}
+			if column < 0 {
+				column = i
+			}
+			if column >= maxXLSXColumns {
+				return nil, fmt.Errorf("row has more than %d cells", maxXLSXColumns)
+			}
+			var value string
+			switch c.Type {
+			case "s":
+				index, err := strconv.Atoi(c.Value)
+				if err != nil || index < 0 || index >= len(shared.Items) {
+					return nil, fmt.Errorf("cell %s refers to missing shared string %q", c.Ref, c.Value)
+				}
+				value = shared.Items[index].String()
+			case "inlineStr":
+				value = c.Inline.String()
+			default:
+				value = c.Value
+			}
+			if column >= len(row) {
+				// Checked before padding, so sparse references cannot
+				// allocate far more cells than the sheet holds
+				if cells += column + 1 - len(row); cells > maxXLSXCells {
+					return nil, fmt.Errorf("sheet has more than %d cells", maxXLSXCells)
+				}
+				row = append(row, make([]string, column+1-len(row))...)
+			}
+			row[column] = value
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// readXLSXPart decodes an XML part of the workbook of at most limit bytes.
+func readXLSXPart(parts map[string]*zip.File, name string, v interface{}, limit int64) error {
+	f, ok := parts[name]
+	if !ok {
+		return fmt.Errorf("XLSX workbook is missing %s", name)
+	}
+	r, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	defer r.Close()
+	// Decompressed parts are limited too, so a small archive cannot expand without bound
+	limited := io.LimitReader(r, limit+1)
+	data, err := io.ReadAll(limited)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if int64(len(data)) > limit {
+		return fmt.Errorf("%s exceeds the %d byte limit", name, limit)
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return nil
+}
+
+// xlsxColumn returns the 0-based column of a cell reference such as "AB12",
+// or -1 when the reference names no column. References past column XFD are
+// an error.
+func xlsxColumn(ref string) (int, error) {
+	column := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		column = column*26 + int(r-'A'+1)
+		if column > maxXLSXColumns {
+			return 0, fmt.Errorf("cell %.16s is past the last column XFD", ref)
+		}
+	}
+	return column - 1, nil
+}
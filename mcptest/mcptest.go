@@ -0,0 +1,170 @@
+// SPDX-License-Identifier: Apache-2.0
+
+// Package mcptest runs the Gemara MCP server in process, connected to a client
+// over the MCP in-memory transport, so integrations and integration tests can
+// drive real tool calls, resources, and sessions without spawning a process.
+//
+// The server uses the same configuration as gemara-mcp serve with its
+// defaults; WithBundle makes it work offline.
+package mcptest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Harness is a server and a client session connected to it.
+type Harness struct {
+	Server  *mcp.Server
+	Session *mcp.ClientSession
+}
+
+type config struct {
+	modes         []string
+	bundle        string
+	clientOptions *mcp.ClientOptions
+}
+
+// Option configures a Harness.
+type Option func(*config)
+
+// WithModes registers only the named modes instead of every mode.
+func WithModes(names ...string) Option {
+	return func(c *config) { c.modes = names }
+}
+
+// WithBundle serves the schema, lexicon, templates, and catalogs from an
+// offline bundle built with gemara-mcp bundle build, for hermetic tests. The bundle
+// stays in use by later harnesses of the process.
+func WithBundle(dir string) Option {
+	return func(c *config) { c.bundle = dir }
+}
+
+// WithClientOptions sets the options of the client, such as handlers for
+// elicitation or notifications.
+func WithClientOptions(options *mcp.ClientOptions) Option {
+	return func(c *config) { c.clientOptions = options }
+}
+
+// New starts a server with every mode registered and connects a client to it.
+func New(ctx context.Context, opts ...Option) (*Harness, error) {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	serverConfig := tool.NewConfig()
+	if cfg.bundle != "" {
+		if _, err := tool.UseBundle(serverConfig, cfg.bundle); err != nil {
+			return nil, err
+		}
+	}
+	modes := tool.Modes()
+	if len(cfg.modes) > 0 {
+		modes = nil
+		for _, name := range cfg.modes {
+			mode, err := tool.LookupMode(name)
+			if err != nil {
+				return nil, err
+			}
+			modes = append(modes, mode)
+		}
+	}
+	for i, mode := range modes {
+		if advisory, ok := mode.(tool.AdvisoryMode); ok {
+			advisory.Config = serverConfig
+			modes[i] = advisory
+		}
+	}
+
+	var instructions []string
+	for _, mode := range modes {
+		instructions = append(instructions, mode.Instructions())
+	}
+	server := mcp.NewServer(&mcp.Implementation{Name: "gemara-mcp", Title: "Gemara MCP", Version: "test"}, &mcp.ServerOptions{
+		Instructions:       strings.Join(instructions, "\n"),
+		CompletionHandler:  tool.HandleCompletion,
+		SubscribeHandler:   tool.SubscribeResource,
+		UnsubscribeHandler: tool.UnsubscribeResource,
+	})
+	for _, mode := range modes {
+		mode.Register(server)
+	}
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		return nil, fmt.Errorf("failed to connect server: %w", err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "mcptest"}, cfg.clientOptions)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect client: %w", err)
+	}
+	return &Harness{Server: server, Session: session}, nil
+}
+
+// Start is New for tests: it fails the test when the harness cannot start and
+// closes the harness when the test ends.
+func Start(t testing.TB, opts ...Option) *Harness {
+	t.Helper()
+	h, err := New(context.Background(), opts...)
+	if err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	t.Cleanup(func() { _ = h.Close() })
+	return h
+}
+
+// Close ends the client session.
+func (h *Harness) Close() error {
+	return h.Session.Close()
+}
+
+// ToolError is a tool result flagged as an error.
+type ToolError struct {
+	Tool    string
+	Message string
+}
+
+func (e *ToolError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Tool, e.Message)
+}
+
+// CallTool calls a tool with input marshaled as its arguments and decodes the
+// structured result into output, when output is not nil. A result flagged as
+// an error is returned as a *ToolError.
+func (h *Harness) CallTool(ctx context.Context, name string, input, output any) error {
+	arguments, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("failed to marshal arguments: %w", err)
+	}
+	result, err := h.Session.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: json.RawMessage(arguments)})
+	if err != nil {
+		return err
+	}
+	if result.IsError {
+		var messages []string
+		for _, content := range result.Content {
+			if text, ok := content.(*mcp.TextContent); ok {
+				messages = append(messages, text.Text)
+			}
+		}
+		return &ToolError{Tool: name, Message: strings.Join(messages, "\n")}
+	}
+	if output == nil {
+		return nil
+	}
+	structured, err := json.Marshal(result.StructuredContent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal structured content: %w", err)
+	}
+	if err := json.Unmarshal(structured, output); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: Apache-2.0
+
+package mcptest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gemaraproj/gemara-mcp/internal/tool"
+	"github.com/gemaraproj/gemara-mcp/mcptest"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHarness(t *testing.T) {
+	ctx := context.Background()
+	h := mcptest.Start(t)
+
+	tools, err := h.Session.ListTools(ctx, nil)
+	require.NoError(t, err, "should list tools")
+	var names []string
+	for _, listed := range tools.Tools {
+		names = append(names, listed.Name)
+	}
+	for _, mode := range tool.Modes() {
+		for _, registered := range mode.Tools() {
+			assert.Contains(t, names, registered.Name, "every mode's tools should be registered")
+		}
+	}
+	assert.NotEmpty(t, h.Session.InitializeResult().Instructions)
+
+	var staged tool.OutputStageArtifact
+	require.NoError(t, h.CallTool(ctx, tool.MetadataStageArtifact.Name, tool.InputStageArtifact{
+		Name:            "draft",
+		ArtifactContent: "title: Draft\n",
+	}, &staged))
+	assert.Equal(t, "draft", staged.Name)
+
+	// Staged drafts belong to the session, so the harness keeps one across calls
+	var fetched tool.OutputGetStagedArtifact
+	require.NoError(t, h.CallTool(ctx, tool.MetadataGetStagedArtifact.Name, tool.InputGetStagedArtifact{Name: "draft"}, &fetched))
+	assert.Equal(t, "title: Draft\n", fetched.Content)
+
+	err = h.CallTool(ctx, tool.MetadataStageArtifact.Name, tool.InputStageArtifact{Name: "not valid!"}, nil)
+	var toolErr *mcptest.ToolError
+	require.True(t, errors.As(err, &toolErr), "a failing tool should return a ToolError, got %v", err)
+	assert.Contains(t, toolErr.Message, "invalid name")
+}
+
+func TestHarnessModes(t *testing.T) {
+	_, err := mcptest.New(context.Background(), mcptest.WithModes("unknown"))
+	assert.ErrorContains(t, err, "unknown mode")
+
+	h := mcptest.Start(t, mcptest.WithModes("advisory"), mcptest.WithClientOptions(&mcp.ClientOptions{}))
+	tools, err := h.Session.ListTools(context.Background(), nil)
+	require.NoError(t, err, "should list tools")
+	assert.Len(t, tools.Tools, len(tool.AdvisoryMode{}.Tools()))
+}